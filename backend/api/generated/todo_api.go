@@ -4,13 +4,20 @@
 package generated
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"path"
+	"strings"
 	"time"
 
+	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/go-chi/chi/v5"
 	"github.com/oapi-codegen/runtime"
 	openapi_types "github.com/oapi-codegen/runtime/types"
@@ -18,6 +25,7 @@ import (
 
 const (
 	BearerAuthScopes = "bearerAuth.Scopes"
+	FeedTokenScopes  = "feedToken.Scopes"
 )
 
 // Defines values for BridgeConnectionStatus.
@@ -27,6 +35,13 @@ const (
 	NotConnected BridgeConnectionStatus = "not_connected"
 )
 
+// Defines values for EmailSecurity.
+const (
+	None     EmailSecurity = "none"
+	Starttls EmailSecurity = "starttls"
+	Tls      EmailSecurity = "tls"
+)
+
 // Defines values for LoginStepCompleteType.
 const (
 	Complete LoginStepCompleteType = "complete"
@@ -47,6 +62,22 @@ const (
 	UserInput LoginStepUserInputType = "user_input"
 )
 
+// Defines values for TodoItemPriority.
+const (
+	High   TodoItemPriority = "high"
+	Low    TodoItemPriority = "low"
+	Medium TodoItemPriority = "medium"
+)
+
+// Defines values for TodoListEventType.
+const (
+	ItemCreated  TodoListEventType = "item.created"
+	ItemDeleted  TodoListEventType = "item.deleted"
+	ItemUpdated  TodoListEventType = "item.updated"
+	ItemsUpdated TodoListEventType = "items.updated"
+	ListDeleted  TodoListEventType = "list.deleted"
+)
+
 // Defines values for BridgeSubmitLoginStepParamsAction.
 const (
 	BridgeSubmitLoginStepParamsActionCookies        BridgeSubmitLoginStepParamsAction = "cookies"
@@ -60,6 +91,25 @@ const (
 	Before GetCalendarEventsParamsDirection = "before"
 )
 
+// Defines values for ExportTodoListParamsFormat.
+const (
+	Csv  ExportTodoListParamsFormat = "csv"
+	Json ExportTodoListParamsFormat = "json"
+)
+
+// Defines values for GetTodoItemsByListIdParamsSort.
+const (
+	Position GetTodoItemsByListIdParamsSort = "position"
+	Priority GetTodoItemsByListIdParamsSort = "priority"
+)
+
+// Defines values for GetTodoItemsByListIdParamsDue.
+const (
+	Overdue  GetTodoItemsByListIdParamsDue = "overdue"
+	Today    GetTodoItemsByListIdParamsDue = "today"
+	Tomorrow GetTodoItemsByListIdParamsDue = "tomorrow"
+)
+
 // BridgeAccount defines model for BridgeAccount.
 type BridgeAccount struct {
 	DisplayName *string `json:"displayName,omitempty"`
@@ -204,58 +254,372 @@ type CollaboratorDetail struct {
 	Username string `json:"username"`
 }
 
+// DeleteUserRequest defines model for DeleteUserRequest.
+type DeleteUserRequest struct {
+	// ConfirmMatrixId The caller's own Matrix ID, confirming the deletion
+	ConfirmMatrixId string `json:"confirm_matrix_id"`
+}
+
+// EmailAccount defines model for EmailAccount.
+type EmailAccount struct {
+	CreatedAt      time.Time           `json:"createdAt"`
+	DefaultMailbox string              `json:"defaultMailbox"`
+	Email          openapi_types.Email `json:"email"`
+	Host           string              `json:"host"`
+	Id             openapi_types.UUID  `json:"id"`
+	Port           int32               `json:"port"`
+
+	// Security How to secure the IMAP connection: implicit TLS (usually port 993), STARTTLS upgrade of a plain connection (usually port 143), or none. none sends the password in the clear and is refused unless the server sets IMAP_ALLOW_PLAINTEXT.
+	Security EmailSecurity `json:"security"`
+}
+
+// EmailAccountRequest defines model for EmailAccountRequest.
+type EmailAccountRequest struct {
+	// AccountId Registered account to log in with instead of sending credentials
+	AccountId   *openapi_types.UUID `json:"accountId,omitempty"`
+	AppPassword string              `json:"appPassword,omitempty"`
+
+	// DefaultMailbox Mailbox used when a request names none; defaults to INBOX
+	DefaultMailbox *string             `json:"defaultMailbox,omitempty"`
+	Email          openapi_types.Email `json:"email,omitempty"`
+	Host           string              `json:"host,omitempty"`
+	Port           int32               `json:"port,omitempty"`
+
+	// Security How to secure the IMAP connection: implicit TLS (usually port 993), STARTTLS upgrade of a plain connection (usually port 143), or none. none sends the password in the clear and is refused unless the server sets IMAP_ALLOW_PLAINTEXT.
+	Security *EmailSecurity `json:"security,omitempty"`
+}
+
+// EmailAccountsResponse defines model for EmailAccountsResponse.
+type EmailAccountsResponse struct {
+	Accounts []EmailAccount `json:"accounts"`
+}
+
+// EmailAttachmentRequest defines model for EmailAttachmentRequest.
+type EmailAttachmentRequest struct {
+	// AccountId Registered account to log in with instead of sending credentials
+	AccountId   *openapi_types.UUID `json:"accountId,omitempty"`
+	AppPassword string              `json:"appPassword,omitempty"`
+	Email       openapi_types.Email `json:"email,omitempty"`
+
+	// Filename Attachment filename, used when part is omitted
+	Filename *string `json:"filename,omitempty"`
+	Host     string  `json:"host,omitempty"`
+
+	// Mailbox Mailbox name to select (defaults to INBOX when omitted)
+	Mailbox *string `json:"mailbox,omitempty"`
+
+	// Part IMAP section of the part (e.g. "2" or "1.2")
+	Part *string `json:"part,omitempty"`
+	Port int32   `json:"port,omitempty"`
+
+	// Security How to secure the IMAP connection: implicit TLS (usually port 993), STARTTLS upgrade of a plain connection (usually port 143), or none. none sends the password in the clear and is refused unless the server sets IMAP_ALLOW_PLAINTEXT.
+	Security *EmailSecurity `json:"security,omitempty"`
+
+	// Uid UID of the message holding the attachment
+	Uid int64 `json:"uid"`
+}
+
+// EmailBodyRequest defines model for EmailBodyRequest.
+type EmailBodyRequest struct {
+	// AccountId Registered account to log in with instead of sending credentials
+	AccountId *openapi_types.UUID `json:"accountId,omitempty"`
+
+	// AllowRemoteContent Keep images loaded from remote servers
+	AllowRemoteContent *bool               `json:"allowRemoteContent,omitempty"`
+	AppPassword        string              `json:"appPassword,omitempty"`
+	Email              openapi_types.Email `json:"email,omitempty"`
+	Host               string              `json:"host,omitempty"`
+
+	// Mailbox Mailbox name to select (defaults to INBOX when omitted)
+	Mailbox *string `json:"mailbox,omitempty"`
+	Port    int32   `json:"port,omitempty"`
+
+	// Security How to secure the IMAP connection: implicit TLS (usually port 993), STARTTLS upgrade of a plain connection (usually port 143), or none. none sends the password in the clear and is refused unless the server sets IMAP_ALLOW_PLAINTEXT.
+	Security *EmailSecurity `json:"security,omitempty"`
+
+	// Uid UID of the message
+	Uid int64 `json:"uid"`
+}
+
+// EmailBodyResponse defines model for EmailBodyResponse.
+type EmailBodyResponse struct {
+	// Html Sanitized HTML; omitted when the message has no HTML part
+	Html *string `json:"html,omitempty"`
+
+	// RemoteContentBlocked True when remote images were removed from html
+	RemoteContentBlocked bool `json:"remoteContentBlocked"`
+
+	// Text Plain-text body, derived from the HTML when there is no text part
+	Text string `json:"text"`
+}
+
+// EmailCountsRequest defines model for EmailCountsRequest.
+type EmailCountsRequest struct {
+	// AccountId Registered account to log in with instead of sending credentials
+	AccountId   *openapi_types.UUID `json:"accountId,omitempty"`
+	AppPassword string              `json:"appPassword,omitempty"`
+	Email       openapi_types.Email `json:"email,omitempty"`
+	Host        string              `json:"host,omitempty"`
+
+	// Mailboxes Mailboxes to count; omit to count every selectable one
+	Mailboxes *[]string `json:"mailboxes,omitempty"`
+	Port      int32     `json:"port,omitempty"`
+
+	// Security How to secure the IMAP connection: implicit TLS (usually port 993), STARTTLS upgrade of a plain connection (usually port 143), or none. none sends the password in the clear and is refused unless the server sets IMAP_ALLOW_PLAINTEXT.
+	Security *EmailSecurity `json:"security,omitempty"`
+}
+
+// EmailCountsResponse defines model for EmailCountsResponse.
+type EmailCountsResponse struct {
+	Counts []EmailMailboxCount `json:"counts"`
+}
+
+// EmailDraftRequest defines model for EmailDraftRequest.
+type EmailDraftRequest struct {
+	// AccountId Registered account to log in with instead of sending credentials
+	AccountId   *openapi_types.UUID `json:"accountId,omitempty"`
+	AppPassword string              `json:"appPassword,omitempty"`
+
+	// Body Plain-text message body
+	Body  *string             `json:"body,omitempty"`
+	Cc    *[]string           `json:"cc,omitempty"`
+	Email openapi_types.Email `json:"email,omitempty"`
+	Host  string              `json:"host,omitempty"`
+	Port  int32               `json:"port,omitempty"`
+
+	// Security How to secure the IMAP connection: implicit TLS (usually port 993), STARTTLS upgrade of a plain connection (usually port 143), or none. none sends the password in the clear and is refused unless the server sets IMAP_ALLOW_PLAINTEXT.
+	Security *EmailSecurity `json:"security,omitempty"`
+	Subject  *string        `json:"subject,omitempty"`
+
+	// To Recipient addresses, either bare or as "Name <addr>"
+	To *[]string `json:"to,omitempty"`
+}
+
+// EmailDraftResponse defines model for EmailDraftResponse.
+type EmailDraftResponse struct {
+	// Mailbox Mailbox the draft was appended to
+	Mailbox string `json:"mailbox"`
+
+	// Uid UID of the draft in mailbox; absent when the server does not support UIDPLUS
+	Uid *int64 `json:"uid,omitempty"`
+
+	// UidValidity UIDVALIDITY the uid belongs to; absent together with uid
+	UidValidity *int64 `json:"uidValidity,omitempty"`
+}
+
+// EmailHeadersRequest defines model for EmailHeadersRequest.
+type EmailHeadersRequest struct {
+	// AccountId Registered account to log in with instead of sending credentials
+	AccountId   *openapi_types.UUID `json:"accountId,omitempty"`
+	AppPassword string              `json:"appPassword,omitempty"`
+	Email       openapi_types.Email `json:"email,omitempty"`
+	Host        string              `json:"host,omitempty"`
+
+	// Limit Most recent messages to read from each mailbox
+	Limit *int32 `json:"limit,omitempty"`
+
+	// Mailboxes Mailboxes to read, in order of preference when the same message is filed in several. Defaults to INBOX, [Gmail]/All Mail, [Gmail]/Sent Mail, Sent and Sent Items; default mailboxes the account does not have are skipped, but naming one that cannot be opened fails with 404. Empty mailboxes contribute nothing.
+	Mailboxes *[]string `json:"mailboxes,omitempty"`
+	Port      int32     `json:"port,omitempty"`
+
+	// Security How to secure the IMAP connection: implicit TLS (usually port 993), STARTTLS upgrade of a plain connection (usually port 143), or none. none sends the password in the clear and is refused unless the server sets IMAP_ALLOW_PLAINTEXT.
+	Security *EmailSecurity `json:"security,omitempty"`
+
+	// SyncToken syncToken from a previous response for the same account. Mailboxes whose UIDVALIDITY, UIDNEXT and message count are unchanged since then are not fetched again and are listed in unchangedMailboxes instead.
+	SyncToken *string `json:"syncToken,omitempty"`
+}
+
 // EmailListRequest defines model for EmailListRequest.
 type EmailListRequest struct {
-	AppPassword string              `json:"appPassword"`
-	Email       openapi_types.Email `json:"email"`
-	Host        string              `json:"host"`
+	// AccountId Registered account to log in with instead of sending credentials
+	AccountId   *openapi_types.UUID `json:"accountId,omitempty"`
+	AppPassword string              `json:"appPassword,omitempty"`
+	Email       openapi_types.Email `json:"email,omitempty"`
+	Host        string              `json:"host,omitempty"`
 
 	// Mailbox Mailbox name to select (defaults to INBOX when omitted)
 	Mailbox *string `json:"mailbox,omitempty"`
-	Port    int32   `json:"port"`
+
+	// Mailboxes Search these mailboxes instead of a single one and merge the results: each message is listed once (by Message-ID, from the first mailbox it is found in), newest first, at most 25. Mailboxes that cannot be selected are skipped. Cannot be combined with mailbox or sinceUid.
+	Mailboxes *[]string `json:"mailboxes,omitempty"`
+	Port      int32     `json:"port,omitempty"`
 
 	// SearchFlags Optional IMAP flags to filter on (e.g. ["\\Flagged"])
 	SearchFlags *[]string `json:"searchFlags,omitempty"`
+
+	// Security How to secure the IMAP connection: implicit TLS (usually port 993), STARTTLS upgrade of a plain connection (usually port 143), or none. none sends the password in the clear and is refused unless the server sets IMAP_ALLOW_PLAINTEXT.
+	Security *EmailSecurity `json:"security,omitempty"`
+
+	// SinceUid Return only messages with a UID above this one, oldest first and at most 25 at a time (hasMore tells whether to continue from the last returned UID), instead of the 25 latest messages
+	SinceUid *int64 `json:"sinceUid,omitempty"`
+
+	// UidValidity UIDVALIDITY the client stored along with sinceUid; when the mailbox's value differs, no messages are returned and fullResyncRequired is set
+	UidValidity *int64 `json:"uidValidity,omitempty"`
 }
 
-// EmailLoginRequest defines model for EmailLoginRequest.
+// EmailLoginRequest Either the IMAP login (host, port, email and appPassword, plus optionally security) or the accountId of an account registered with POST /email/accounts, but not both.
 type EmailLoginRequest struct {
-	AppPassword string              `json:"appPassword"`
-	Email       openapi_types.Email `json:"email"`
-	Host        string              `json:"host"`
-	Port        int32               `json:"port"`
+	// AccountId Registered account to log in with instead of sending credentials
+	AccountId   *openapi_types.UUID `json:"accountId,omitempty"`
+	AppPassword string              `json:"appPassword,omitempty"`
+	Email       openapi_types.Email `json:"email,omitempty"`
+	Host        string              `json:"host,omitempty"`
+	Port        int32               `json:"port,omitempty"`
+
+	// Security How to secure the IMAP connection: implicit TLS (usually port 993), STARTTLS upgrade of a plain connection (usually port 143), or none. none sends the password in the clear and is refused unless the server sets IMAP_ALLOW_PLAINTEXT.
+	Security *EmailSecurity `json:"security,omitempty"`
+}
+
+// EmailMailbox defines model for EmailMailbox.
+type EmailMailbox struct {
+	// Attributes Mailbox attributes as reported by the server, e.g. \Noselect, \HasChildren, \Sent, \Drafts, \Trash
+	Attributes []string `json:"attributes"`
+
+	// Delimiter Hierarchy delimiter; empty when the server has no hierarchy
+	Delimiter string `json:"delimiter"`
+
+	// Name Full mailbox name, usable as mailbox in /email/list
+	Name string `json:"name"`
+}
+
+// EmailMailboxCount defines model for EmailMailboxCount.
+type EmailMailboxCount struct {
+	Mailbox string `json:"mailbox"`
+
+	// Total Number of messages in the mailbox
+	Total int64 `json:"total"`
+
+	// Unread Number of messages without the \Seen flag
+	Unread int64 `json:"unread"`
+}
+
+// EmailMailboxesResponse defines model for EmailMailboxesResponse.
+type EmailMailboxesResponse struct {
+	Mailboxes []EmailMailbox `json:"mailboxes"`
 }
 
 // EmailMessageHeader defines model for EmailMessageHeader.
 type EmailMessageHeader struct {
-	Date    *time.Time `json:"date,omitempty"`
-	From    *string    `json:"from,omitempty"`
-	Subject *string    `json:"subject,omitempty"`
+	// Cc Cc recipients, each formatted like from
+	Cc   *[]string  `json:"cc,omitempty"`
+	Date *time.Time `json:"date,omitempty"`
+	From *string    `json:"from,omitempty"`
+
+	// Mailbox Mailbox the message was found in; set when several mailboxes were searched
+	Mailbox   *string `json:"mailbox,omitempty"`
+	MessageId *string `json:"messageId,omitempty"`
+
+	// ReplyTo Reply-To addresses, each formatted like from
+	ReplyTo *[]string `json:"replyTo,omitempty"`
+	Subject *string   `json:"subject,omitempty"`
+
+	// To To recipients, each formatted like from
+	To  *[]string `json:"to,omitempty"`
+	Uid *int64    `json:"uid,omitempty"`
 }
 
 // EmailMessagesResponse defines model for EmailMessagesResponse.
 type EmailMessagesResponse struct {
-	Messages    *[]EmailMessageHeader `json:"messages,omitempty"`
-	UnreadCount *int32                `json:"unreadCount,omitempty"`
+	// FullResyncRequired Set with sinceUid: the mailbox's UIDVALIDITY changed, so previously seen UIDs are invalid and the client must list it again from scratch
+	FullResyncRequired *bool `json:"fullResyncRequired,omitempty"`
+
+	// HasMore Set with sinceUid; more messages follow the last one returned
+	HasMore  *bool                 `json:"hasMore,omitempty"`
+	Messages *[]EmailMessageHeader `json:"messages,omitempty"`
+
+	// UidValidity Current UIDVALIDITY of the mailbox; store it with the highest UID seen to sync incrementally
+	UidValidity *int64 `json:"uidValidity,omitempty"`
+	UnreadCount *int32 `json:"unreadCount,omitempty"`
+}
+
+// EmailMoveRequest defines model for EmailMoveRequest.
+type EmailMoveRequest struct {
+	// AccountId Registered account to log in with instead of sending credentials
+	AccountId   *openapi_types.UUID `json:"accountId,omitempty"`
+	AppPassword string              `json:"appPassword,omitempty"`
+
+	// Destination Mailbox to move the messages into
+	Destination string              `json:"destination"`
+	Email       openapi_types.Email `json:"email,omitempty"`
+	Host        string              `json:"host,omitempty"`
+
+	// Mailbox Mailbox the messages are currently in
+	Mailbox string `json:"mailbox"`
+	Port    int32  `json:"port,omitempty"`
+
+	// Security How to secure the IMAP connection: implicit TLS (usually port 993), STARTTLS upgrade of a plain connection (usually port 143), or none. none sends the password in the clear and is refused unless the server sets IMAP_ALLOW_PLAINTEXT.
+	Security *EmailSecurity `json:"security,omitempty"`
+	Uids     []int64        `json:"uids"`
+}
+
+// EmailMoveResponse defines model for EmailMoveResponse.
+type EmailMoveResponse struct {
+	// Moved UIDs (in the source mailbox) that were moved
+	Moved []int64 `json:"moved"`
 }
 
 // EmailRichHeader defines model for EmailRichHeader.
 type EmailRichHeader struct {
+	// Cc Cc recipients, each formatted like from
+	Cc         *[]string  `json:"cc,omitempty"`
 	Date       *time.Time `json:"date,omitempty"`
 	From       *string    `json:"from,omitempty"`
 	InReplyTo  *string    `json:"inReplyTo,omitempty"`
+	Mailbox    *string    `json:"mailbox,omitempty"`
 	MessageId  *string    `json:"messageId,omitempty"`
 	References *[]string  `json:"references,omitempty"`
-	Subject    *string    `json:"subject,omitempty"`
+
+	// ReplyTo Reply-To addresses, each formatted like from
+	ReplyTo *[]string `json:"replyTo,omitempty"`
+	Subject *string   `json:"subject,omitempty"`
+
+	// To To recipients, each formatted like from
+	To  *[]string `json:"to,omitempty"`
+	Uid *int64    `json:"uid,omitempty"`
 }
 
 // EmailRichHeadersResponse defines model for EmailRichHeadersResponse.
 type EmailRichHeadersResponse struct {
+	// Messages Flat list of headers, newest first. Empty when thread=true.
 	Messages []EmailRichHeader `json:"messages"`
+
+	// SyncToken Opaque mailbox state to send back as syncToken on the next request
+	SyncToken *string `json:"syncToken,omitempty"`
+
+	// Threads Conversation threads, most recently active first. Only set when thread=true.
+	Threads *[]EmailThread `json:"threads,omitempty"`
+
+	// UnchangedMailboxes Mailboxes skipped because they have not changed since the request's syncToken; their messages are not in this response, so keep the copies from the earlier one.
+	UnchangedMailboxes *[]string `json:"unchangedMailboxes,omitempty"`
+}
+
+// EmailSecurity How to secure the IMAP connection: implicit TLS (usually port 993), STARTTLS upgrade of a plain connection (usually port 143), or none. none sends the password in the clear and is refused unless the server sets IMAP_ALLOW_PLAINTEXT.
+type EmailSecurity string
+
+// EmailThread defines model for EmailThread.
+type EmailThread struct {
+	// Replies Remaining messages of the thread, oldest first
+	Replies []EmailRichHeader `json:"replies"`
+	Root    EmailRichHeader   `json:"root"`
 }
 
 // Error defines model for Error.
 type Error struct {
+	// Code Machine-readable error code derived from the failure, e.g. VALIDATION_ERROR, UNAUTHORIZED, FORBIDDEN, NOT_FOUND, CONFLICT, GONE, PAYLOAD_TOO_LARGE, INTERNAL_ERROR, BAD_GATEWAY or GATEWAY_TIMEOUT
+	Code string `json:"code"`
+
+	// Details Per-field details, set for validation failures
+	Details *[]FieldError `json:"details,omitempty"`
+	Message string        `json:"message"`
+
+	// RequestId ID of the request that failed, also sent as the X-Request-Id response header; quote it when reporting a problem so the matching server log lines can be found
+	RequestId *string `json:"requestId,omitempty"`
+}
+
+// FieldError defines model for FieldError.
+type FieldError struct {
+	// Field JSON pointer into the body, or the name of the offending parameter
+	Field   string `json:"field"`
 	Message string `json:"message"`
 }
 
@@ -339,10 +703,30 @@ type MatrixOpenIDRequest struct {
 	// AccessToken Matrix OpenID access token
 	AccessToken string `json:"access_token"`
 
+	// ClientAccessToken Optional Matrix client-server access token of the same account. It is checked against the homeserver's whoami, stored encrypted, and used by POST /matrix/send; the OpenID token cannot send messages.
+	ClientAccessToken *string `json:"client_access_token,omitempty"`
+
 	// MatrixServerName Matrix homeserver name
 	MatrixServerName string `json:"matrix_server_name"`
 }
 
+// MatrixSendRequest defines model for MatrixSendRequest.
+type MatrixSendRequest struct {
+	// Body Plain-text message body, sent as an m.text m.room.message
+	Body string `json:"body"`
+
+	// RoomId Room ID (not alias) to send to
+	RoomId string `json:"room_id"`
+
+	// TxnId Transaction ID; resend with the same value to retry without duplicating the message. Generated when omitted.
+	TxnId *string `json:"txn_id,omitempty"`
+}
+
+// MatrixSendResponse defines model for MatrixSendResponse.
+type MatrixSendResponse struct {
+	EventId string `json:"event_id"`
+}
+
 // NewCalendarLinkSource defines model for NewCalendarLinkSource.
 type NewCalendarLinkSource struct {
 	Category    string  `json:"category"`
@@ -362,7 +746,13 @@ type NewTodoItem struct {
 	DueDate     *time.Time         `json:"due_date,omitempty"`
 	ListId      openapi_types.UUID `json:"list_id"`
 	Position    string             `json:"position"`
-	Title       string             `json:"title"`
+
+	// Priority How urgent the item is. Items are created with medium unless told otherwise.
+	Priority *TodoItemPriority `json:"priority,omitempty"`
+
+	// Tags Labels on the item, stored trimmed, lowercased and without duplicates.
+	Tags  *TodoItemTags `json:"tags,omitempty"`
+	Title string        `json:"title"`
 }
 
 // NewTodoList defines model for NewTodoList.
@@ -371,29 +761,153 @@ type NewTodoList struct {
 	Title       string `json:"title"`
 }
 
+// NewTodoListInvite defines model for NewTodoListInvite.
+type NewTodoListInvite struct {
+	// ExpiresInHours How long the invite can be accepted
+	ExpiresInHours *int `json:"expires_in_hours,omitempty"`
+
+	// SingleUse Whether the invite stops working after the first person joins
+	SingleUse *bool `json:"single_use,omitempty"`
+}
+
+// SharedTodoList defines model for SharedTodoList.
+type SharedTodoList struct {
+	// CompletedCount How many of item_count are completed. Only set by getTodoListById.
+	CompletedCount *int64             `json:"completed_count,omitempty"`
+	CreatedAt      *time.Time         `json:"created_at,omitempty"`
+	Description    string             `json:"description"`
+	Id             openapi_types.UUID `json:"id"`
+
+	// ItemCount Items in the list, excluding the trash. Only set by getTodoListById.
+	ItemCount *int64             `json:"item_count,omitempty"`
+	OwnerId   openapi_types.UUID `json:"owner_id"`
+
+	// Shared Always true; marks the list as owned by someone else
+	Shared bool `json:"shared"`
+
+	// SharedAt When the caller was added as a collaborator
+	SharedAt  time.Time  `json:"shared_at"`
+	Title     string     `json:"title"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// TodoFeedToken defines model for TodoFeedToken.
+type TodoFeedToken struct {
+	// Token Secret for the `token` query parameter of GET /todo-items.ics
+	Token string `json:"token"`
+}
+
 // TodoItem defines model for TodoItem.
 type TodoItem struct {
-	Completed   bool               `json:"completed"`
-	CreatedAt   *time.Time         `json:"created_at,omitempty"`
-	Description string             `json:"description"`
-	DueDate     *time.Time         `json:"due_date,omitempty"`
-	Id          openapi_types.UUID `json:"id"`
-	ListId      openapi_types.UUID `json:"list_id"`
+	Completed bool       `json:"completed"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+
+	// CreatedBy User who created the item.
+	CreatedBy *openapi_types.UUID `json:"created_by,omitempty"`
+
+	// CreatedByUsername Username of the creator; absent if they have not set one.
+	CreatedByUsername *string            `json:"created_by_username,omitempty"`
+	Description       string             `json:"description"`
+	DueDate           *time.Time         `json:"due_date,omitempty"`
+	Id                openapi_types.UUID `json:"id"`
+	ListId            openapi_types.UUID `json:"list_id"`
 
 	// Position Fractional index for ordering todo items within a list.
-	Position  string     `json:"position"`
-	Title     string     `json:"title"`
-	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	Position string `json:"position"`
+
+	// Priority How urgent the item is. Items are created with medium unless told otherwise.
+	Priority *TodoItemPriority `json:"priority,omitempty"`
+
+	// Tags Labels on the item, stored trimmed, lowercased and without duplicates.
+	Tags      *TodoItemTags `json:"tags,omitempty"`
+	Title     string        `json:"title"`
+	UpdatedAt *time.Time    `json:"updated_at,omitempty"`
+
+	// Version Incremented on every update; send it back when updating the item.
+	Version int64 `json:"version"`
+}
+
+// TodoItemPage One page of todo items, shaped like TodoListPage.
+type TodoItemPage struct {
+	Items      []TodoItem `json:"items"`
+	NextCursor *string    `json:"nextCursor"`
+	Total      int        `json:"total"`
+}
+
+// TodoItemPriority How urgent the item is. Items are created with medium unless told otherwise.
+type TodoItemPriority string
+
+// TodoItemTags Labels on the item, stored trimmed, lowercased and without duplicates.
+type TodoItemTags = []string
+
+// TodoItemsUpdated defines model for TodoItemsUpdated.
+type TodoItemsUpdated struct {
+	// Updated Number of items changed
+	Updated int64 `json:"updated"`
 }
 
 // TodoList defines model for TodoList.
 type TodoList struct {
-	CreatedAt   *time.Time         `json:"created_at,omitempty"`
-	Description string             `json:"description"`
-	Id          openapi_types.UUID `json:"id"`
-	OwnerId     openapi_types.UUID `json:"owner_id"`
-	Title       string             `json:"title"`
-	UpdatedAt   *time.Time         `json:"updated_at,omitempty"`
+	// CompletedCount How many of item_count are completed. Only set by getTodoListById.
+	CompletedCount *int64             `json:"completed_count,omitempty"`
+	CreatedAt      *time.Time         `json:"created_at,omitempty"`
+	Description    string             `json:"description"`
+	Id             openapi_types.UUID `json:"id"`
+
+	// ItemCount Items in the list, excluding the trash. Only set by getTodoListById.
+	ItemCount *int64             `json:"item_count,omitempty"`
+	OwnerId   openapi_types.UUID `json:"owner_id"`
+	Title     string             `json:"title"`
+	UpdatedAt *time.Time         `json:"updated_at,omitempty"`
+}
+
+// TodoListEvent defines model for TodoListEvent.
+type TodoListEvent struct {
+	// ActorId User whose request caused the change.
+	ActorId openapi_types.UUID `json:"actor_id"`
+	Item    *TodoItem          `json:"item,omitempty"`
+
+	// ItemId Set for item events.
+	ItemId *openapi_types.UUID `json:"item_id,omitempty"`
+	ListId openapi_types.UUID  `json:"list_id"`
+
+	// Type items.updated reports a bulk change to several items, such as completeAllTodoItems, and carries neither item_id nor item; refetch the list.
+	Type TodoListEventType `json:"type"`
+}
+
+// TodoListEventType items.updated reports a bulk change to several items, such as completeAllTodoItems, and carries neither item_id nor item; refetch the list.
+type TodoListEventType string
+
+// TodoListInvite defines model for TodoListInvite.
+type TodoListInvite struct {
+	ExpiresAt  time.Time          `json:"expires_at"`
+	SingleUse  bool               `json:"single_use"`
+	TodoListId openapi_types.UUID `json:"todo_list_id"`
+
+	// Token Secret to share; shown only once
+	Token string `json:"token"`
+}
+
+// TodoListPage One page of todo lists, returned with paginated=true. Every paged collection uses this shape: items holds the page, total counts the whole collection when the page was read, and nextCursor is sent back as after to get the following page; it is null on the last page.
+type TodoListPage struct {
+	Items      []TodoList `json:"items"`
+	NextCursor *string    `json:"nextCursor"`
+	Total      int        `json:"total"`
+}
+
+// TodoListWithItems defines model for TodoListWithItems.
+type TodoListWithItems struct {
+	Items []TodoItem `json:"items"`
+	List  TodoList   `json:"list"`
+}
+
+// TransferTodoList defines model for TransferTodoList.
+type TransferTodoList struct {
+	// KeepAsCollaborator Whether the previous owner stays on the list as a collaborator
+	KeepAsCollaborator *bool `json:"keep_as_collaborator,omitempty"`
+
+	// NewOwnerId A current collaborator who becomes the owner
+	NewOwnerId openapi_types.UUID `json:"new_owner_id"`
 }
 
 // UpdateCalendarSource defines model for UpdateCalendarSource.
@@ -408,7 +922,16 @@ type UpdateTodoItem struct {
 	Description string     `json:"description"`
 	DueDate     *time.Time `json:"due_date,omitempty"`
 	Position    string     `json:"position"`
-	Title       string     `json:"title"`
+
+	// Priority New priority; omit to keep the current one.
+	Priority *TodoItemPriority `json:"priority,omitempty"`
+
+	// Tags Replaces the item's tags; omit to keep them unchanged.
+	Tags  *TodoItemTags `json:"tags,omitempty"`
+	Title string        `json:"title"`
+
+	// Version The item version the edit is based on. The update is rejected with 409 when the item has been changed since.
+	Version int64 `json:"version"`
 }
 
 // UpdateTodoList defines model for UpdateTodoList.
@@ -417,6 +940,15 @@ type UpdateTodoList struct {
 	Title       string `json:"title"`
 }
 
+// UpdateUserRequest defines model for UpdateUserRequest.
+type UpdateUserRequest struct {
+	// Timezone IANA timezone such as Europe/Berlin, used to decide which deadlines fall on the user's today and tomorrow. An empty string resets it to UTC.
+	Timezone *string `json:"timezone,omitempty"`
+
+	// Username New username; unique ignoring case
+	Username *string `json:"username,omitempty"`
+}
+
 // User defines model for User.
 type User struct {
 	// CreatedAt Timestamp when the user was created
@@ -431,8 +963,14 @@ type User struct {
 	// MatrixId Matrix ID of the user
 	MatrixId string `json:"matrix_id"`
 
+	// Timezone IANA timezone of the user; UTC until they set one
+	Timezone *string `json:"timezone,omitempty"`
+
 	// UpdatedAt Timestamp when the user was last updated
 	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+
+	// Username Display username chosen by the user; absent until set
+	Username *string `json:"username,omitempty"`
 }
 
 // BridgeGetLoginFlowsParams defines parameters for BridgeGetLoginFlows.
@@ -502,10 +1040,82 @@ type GetUpcomingCalendarEventsParams struct {
 	Limit *int32 `form:"limit,omitempty" json:"limit,omitempty"`
 }
 
+// EmailHeadersParams defines parameters for EmailHeaders.
+type EmailHeadersParams struct {
+	// Thread Group the messages into conversation threads instead of returning a flat list
+	Thread *bool `form:"thread,omitempty" json:"thread,omitempty"`
+}
+
+// GetTodoItemsByTagParams defines parameters for GetTodoItemsByTag.
+type GetTodoItemsByTagParams struct {
+	// Tag Tag to filter by
+	Tag string `form:"tag" json:"tag"`
+}
+
 // GetTodoListsByUserIdParams defines parameters for GetTodoListsByUserId.
 type GetTodoListsByUserIdParams struct {
 	// UserId ID of the user to retrieve todo lists for
 	UserId openapi_types.UUID `form:"userId" json:"userId"`
+
+	// Limit Return at most this many lists per page. Without limit or after the whole collection is returned at once.
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// After Opaque cursor from the Next-Cursor header of the previous page. Pages seek past the last list seen rather than skipping a count of rows, so lists created or deleted while paging are never returned twice and never push others out of view.
+	After *string `form:"after,omitempty" json:"after,omitempty"`
+
+	// Paginated Wrap the response in a TodoListPage envelope carrying the page, the total number of lists and the cursor of the next page. Without limit the first page holds 100 lists.
+	Paginated *bool `form:"paginated,omitempty" json:"paginated,omitempty"`
+}
+
+// ExportTodoListParams defines parameters for ExportTodoList.
+type ExportTodoListParams struct {
+	// Format Export format
+	Format *ExportTodoListParamsFormat `form:"format,omitempty" json:"format,omitempty"`
+}
+
+// ExportTodoListParamsFormat defines parameters for ExportTodoList.
+type ExportTodoListParamsFormat string
+
+// GetTodoItemsByListIdParams defines parameters for GetTodoItemsByListId.
+type GetTodoItemsByListIdParams struct {
+	// Sort Order of the items: by position (the default), or by priority from high to low with position breaking ties.
+	Sort *GetTodoItemsByListIdParamsSort `form:"sort,omitempty" json:"sort,omitempty"`
+
+	// Due Only return items due in this window. Today and tomorrow are calendar days in the caller's profile timezone (UTC when unset); overdue lists incomplete items whose deadline has passed.
+	Due *GetTodoItemsByListIdParamsDue `form:"due,omitempty" json:"due,omitempty"`
+
+	// Limit Return at most this many items per page. Without limit or after the whole list is returned at once. Paging only works in position order without a due filter.
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// After Opaque cursor from the Next-Cursor header of the previous page. Pages seek past the last item seen rather than skipping a count of rows, so items created or deleted while paging are never returned twice and never push others out of view.
+	After *string `form:"after,omitempty" json:"after,omitempty"`
+
+	// Paginated Wrap the response in a TodoItemPage envelope carrying the page, the total number of items and the cursor of the next page. Without limit the first page holds 100 items.
+	Paginated *bool `form:"paginated,omitempty" json:"paginated,omitempty"`
+}
+
+// GetTodoItemsByListIdParamsSort defines parameters for GetTodoItemsByListId.
+type GetTodoItemsByListIdParamsSort string
+
+// GetTodoItemsByListIdParamsDue defines parameters for GetTodoItemsByListId.
+type GetTodoItemsByListIdParamsDue string
+
+// CreateTodoItemsBatchJSONBody defines parameters for CreateTodoItemsBatch.
+type CreateTodoItemsBatchJSONBody = []NewTodoItem
+
+// CompleteAllTodoItemsParams defines parameters for CompleteAllTodoItems.
+type CompleteAllTodoItemsParams struct {
+	// Tag Only change items carrying this tag, matched like getTodoItemsByTag
+	Tag *string `form:"tag,omitempty" json:"tag,omitempty"`
+}
+
+// ReorderTodoItemsJSONBody defines parameters for ReorderTodoItems.
+type ReorderTodoItemsJSONBody = []openapi_types.UUID
+
+// UncompleteAllTodoItemsParams defines parameters for UncompleteAllTodoItems.
+type UncompleteAllTodoItemsParams struct {
+	// Tag Only change items carrying this tag, matched like getTodoItemsByTag
+	Tag *string `form:"tag,omitempty" json:"tag,omitempty"`
 }
 
 // GetUserByMatrixIdParams defines parameters for GetUserByMatrixId.
@@ -529,8 +1139,23 @@ type CreateLinkedCalendarSourceJSONRequestBody = NewCalendarLinkSource
 // UpdateCalendarSourceJSONRequestBody defines body for UpdateCalendarSource for application/json ContentType.
 type UpdateCalendarSourceJSONRequestBody = UpdateCalendarSource
 
+// CreateEmailAccountJSONRequestBody defines body for CreateEmailAccount for application/json ContentType.
+type CreateEmailAccountJSONRequestBody = EmailAccountRequest
+
+// EmailAttachmentJSONRequestBody defines body for EmailAttachment for application/json ContentType.
+type EmailAttachmentJSONRequestBody = EmailAttachmentRequest
+
+// EmailBodyJSONRequestBody defines body for EmailBody for application/json ContentType.
+type EmailBodyJSONRequestBody = EmailBodyRequest
+
+// EmailCountsJSONRequestBody defines body for EmailCounts for application/json ContentType.
+type EmailCountsJSONRequestBody = EmailCountsRequest
+
+// EmailDraftJSONRequestBody defines body for EmailDraft for application/json ContentType.
+type EmailDraftJSONRequestBody = EmailDraftRequest
+
 // EmailHeadersJSONRequestBody defines body for EmailHeaders for application/json ContentType.
-type EmailHeadersJSONRequestBody = EmailLoginRequest
+type EmailHeadersJSONRequestBody = EmailHeadersRequest
 
 // EmailImportantJSONRequestBody defines body for EmailImportant for application/json ContentType.
 type EmailImportantJSONRequestBody = EmailLoginRequest
@@ -544,9 +1169,18 @@ type EmailListJSONRequestBody = EmailListRequest
 // EmailLoginTestJSONRequestBody defines body for EmailLoginTest for application/json ContentType.
 type EmailLoginTestJSONRequestBody = EmailLoginRequest
 
+// EmailMailboxesJSONRequestBody defines body for EmailMailboxes for application/json ContentType.
+type EmailMailboxesJSONRequestBody = EmailLoginRequest
+
+// EmailMoveJSONRequestBody defines body for EmailMove for application/json ContentType.
+type EmailMoveJSONRequestBody = EmailMoveRequest
+
 // EmailThreadsJSONRequestBody defines body for EmailThreads for application/json ContentType.
 type EmailThreadsJSONRequestBody = EmailLoginRequest
 
+// SendMatrixMessageJSONRequestBody defines body for SendMatrixMessage for application/json ContentType.
+type SendMatrixMessageJSONRequestBody = MatrixSendRequest
+
 // CreateTodoListJSONRequestBody defines body for CreateTodoList for application/json ContentType.
 type CreateTodoListJSONRequestBody = NewTodoList
 
@@ -556,12 +1190,30 @@ type UpdateTodoListJSONRequestBody = UpdateTodoList
 // AddCollaboratorJSONRequestBody defines body for AddCollaborator for application/json ContentType.
 type AddCollaboratorJSONRequestBody = NewCollaborator
 
+// CreateTodoListInviteJSONRequestBody defines body for CreateTodoListInvite for application/json ContentType.
+type CreateTodoListInviteJSONRequestBody = NewTodoListInvite
+
 // CreateTodoItemJSONRequestBody defines body for CreateTodoItem for application/json ContentType.
 type CreateTodoItemJSONRequestBody = NewTodoItem
 
+// CreateTodoItemsBatchJSONRequestBody defines body for CreateTodoItemsBatch for application/json ContentType.
+type CreateTodoItemsBatchJSONRequestBody = CreateTodoItemsBatchJSONBody
+
+// ReorderTodoItemsJSONRequestBody defines body for ReorderTodoItems for application/json ContentType.
+type ReorderTodoItemsJSONRequestBody = ReorderTodoItemsJSONBody
+
 // UpdateTodoItemJSONRequestBody defines body for UpdateTodoItem for application/json ContentType.
 type UpdateTodoItemJSONRequestBody = UpdateTodoItem
 
+// TransferTodoListJSONRequestBody defines body for TransferTodoList for application/json ContentType.
+type TransferTodoListJSONRequestBody = TransferTodoList
+
+// DeleteCurrentUserJSONRequestBody defines body for DeleteCurrentUser for application/json ContentType.
+type DeleteCurrentUserJSONRequestBody = DeleteUserRequest
+
+// UpdateCurrentUserJSONRequestBody defines body for UpdateCurrentUser for application/json ContentType.
+type UpdateCurrentUserJSONRequestBody = UpdateUserRequest
+
 // AsLoginStepDisplayAndWait returns the union data inside the BridgeLoginStep as a LoginStepDisplayAndWait
 func (t BridgeLoginStep) AsLoginStepDisplayAndWait() (LoginStepDisplayAndWait, error) {
 	var body LoginStepDisplayAndWait
@@ -767,9 +1419,30 @@ type ServerInterface interface {
 	// List bridge connections for current user
 	// (GET /connections)
 	GetConnections(w http.ResponseWriter, r *http.Request)
+	// List the caller's registered email accounts
+	// (GET /email/accounts)
+	ListEmailAccounts(w http.ResponseWriter, r *http.Request)
+	// Register an email account
+	// (POST /email/accounts)
+	CreateEmailAccount(w http.ResponseWriter, r *http.Request)
+	// Remove a registered email account
+	// (DELETE /email/accounts/{accountId})
+	DeleteEmailAccount(w http.ResponseWriter, r *http.Request, accountId openapi_types.UUID)
+	// Download a single MIME part of a message
+	// (POST /email/attachment)
+	EmailAttachment(w http.ResponseWriter, r *http.Request)
+	// Fetch a message body with sanitized HTML and a plain-text fallback
+	// (POST /email/body)
+	EmailBody(w http.ResponseWriter, r *http.Request)
+	// Count the messages of mailboxes
+	// (POST /email/counts)
+	EmailCounts(w http.ResponseWriter, r *http.Request)
+	// Save a message draft to the Drafts mailbox
+	// (POST /email/draft)
+	EmailDraft(w http.ResponseWriter, r *http.Request)
 	// List recent email headers with threading metadata
 	// (POST /email/headers)
-	EmailHeaders(w http.ResponseWriter, r *http.Request)
+	EmailHeaders(w http.ResponseWriter, r *http.Request, params EmailHeadersParams)
 	// List recent important message headers (deprecated)
 	// (POST /email/important)
 	EmailImportant(w http.ResponseWriter, r *http.Request)
@@ -782,15 +1455,36 @@ type ServerInterface interface {
 	// Test email login and fetch recent message headers
 	// (POST /email/login-test)
 	EmailLoginTest(w http.ResponseWriter, r *http.Request)
+	// List the account's mailboxes
+	// (POST /email/mailboxes)
+	EmailMailboxes(w http.ResponseWriter, r *http.Request)
+	// Move messages to another mailbox
+	// (POST /email/move)
+	EmailMove(w http.ResponseWriter, r *http.Request)
 	// List recent email threads
 	// (POST /email/threads)
 	EmailThreads(w http.ResponseWriter, r *http.Request)
+	// Send a text message to a Matrix room
+	// (POST /matrix/send)
+	SendMatrixMessage(w http.ResponseWriter, r *http.Request)
+	// Find todo items by tag across the caller's lists
+	// (GET /todo-items)
+	GetTodoItemsByTag(w http.ResponseWriter, r *http.Request, params GetTodoItemsByTagParams)
+	// Calendar feed of todo deadlines
+	// (GET /todo-items.ics)
+	GetTodoItemsCalendar(w http.ResponseWriter, r *http.Request)
 	// Get todo lists by owner ID
 	// (GET /todolists)
 	GetTodoListsByUserId(w http.ResponseWriter, r *http.Request, params GetTodoListsByUserIdParams)
 	// Create a new todo list
 	// (POST /todolists)
 	CreateTodoList(w http.ResponseWriter, r *http.Request)
+	// Join a todo list through an invite
+	// (POST /todolists/invites/{token}/accept)
+	AcceptTodoListInvite(w http.ResponseWriter, r *http.Request, token string)
+	// Get todo lists other users have shared with the caller
+	// (GET /todolists/shared)
+	GetSharedTodoLists(w http.ResponseWriter, r *http.Request)
 	// Delete a todo list
 	// (DELETE /todolists/{listId})
 	DeleteTodoList(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID)
@@ -800,21 +1494,48 @@ type ServerInterface interface {
 	// Update a todo list
 	// (PUT /todolists/{listId})
 	UpdateTodoList(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID)
+	// Copy a todo list into a new list owned by the caller
+	// (POST /todolists/{listId}/clone)
+	CloneTodoList(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID)
 	// Get collaborators for a todo list
 	// (GET /todolists/{listId}/collaborators)
 	GetCollaborators(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID)
 	// Add a collaborator to a todo list
 	// (POST /todolists/{listId}/collaborators)
 	AddCollaborator(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID)
+	// Leave a todo list shared with the caller
+	// (DELETE /todolists/{listId}/collaborators/me)
+	LeaveTodoList(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID)
 	// Remove a collaborator from a todo list
 	// (DELETE /todolists/{listId}/collaborators/{userId})
 	RemoveCollaborator(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID, userId openapi_types.UUID)
+	// Stream item changes in a todo list over a WebSocket
+	// (GET /todolists/{listId}/events)
+	GetTodoListEvents(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID)
+	// Download a todo list as CSV or JSON
+	// (GET /todolists/{listId}/export)
+	ExportTodoList(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID, params ExportTodoListParams)
+	// Create an invite link for a todo list
+	// (POST /todolists/{listId}/invites)
+	CreateTodoListInvite(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID)
 	// Get todo items by list ID
 	// (GET /todolists/{listId}/items)
-	GetTodoItemsByListId(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID)
+	GetTodoItemsByListId(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID, params GetTodoItemsByListIdParams)
 	// Create a new todo item in a list
 	// (POST /todolists/{listId}/items)
 	CreateTodoItem(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID)
+	// Create multiple todo items in a list
+	// (POST /todolists/{listId}/items/batch)
+	CreateTodoItemsBatch(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID)
+	// Complete all items of a list
+	// (POST /todolists/{listId}/items/complete-all)
+	CompleteAllTodoItems(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID, params CompleteAllTodoItemsParams)
+	// Reorder all items of a list
+	// (PUT /todolists/{listId}/items/order)
+	ReorderTodoItems(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID)
+	// Mark all items of a list as not completed
+	// (POST /todolists/{listId}/items/uncomplete-all)
+	UncompleteAllTodoItems(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID, params UncompleteAllTodoItemsParams)
 	// Delete a todo item
 	// (DELETE /todolists/{listId}/items/{itemId})
 	DeleteTodoItem(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID, itemId openapi_types.UUID)
@@ -824,9 +1545,30 @@ type ServerInterface interface {
 	// Update a todo item
 	// (PUT /todolists/{listId}/items/{itemId})
 	UpdateTodoItem(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID, itemId openapi_types.UUID)
+	// Restore a deleted todo item
+	// (POST /todolists/{listId}/items/{itemId}/restore)
+	RestoreTodoItem(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID, itemId openapi_types.UUID)
+	// Transfer ownership of a todo list to one of its collaborators
+	// (POST /todolists/{listId}/transfer)
+	TransferTodoList(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID)
 	// Get user by Matrix ID
 	// (GET /users/by-matrix-id)
 	GetUserByMatrixId(w http.ResponseWriter, r *http.Request, params GetUserByMatrixIdParams)
+	// Delete the caller's account and data
+	// (DELETE /users/me)
+	DeleteCurrentUser(w http.ResponseWriter, r *http.Request)
+	// Get the caller's profile
+	// (GET /users/me)
+	GetCurrentUser(w http.ResponseWriter, r *http.Request)
+	// Update the caller's profile
+	// (PATCH /users/me)
+	UpdateCurrentUser(w http.ResponseWriter, r *http.Request)
+	// Revoke the todo calendar feed token
+	// (DELETE /users/me/todo-feed-token)
+	DeleteTodoFeedToken(w http.ResponseWriter, r *http.Request)
+	// Issue a token for the todo calendar feed
+	// (POST /users/me/todo-feed-token)
+	CreateTodoFeedToken(w http.ResponseWriter, r *http.Request)
 }
 
 // Unimplemented server implementation that returns http.StatusNotImplemented for each endpoint.
@@ -941,9 +1683,51 @@ func (_ Unimplemented) GetConnections(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNotImplemented)
 }
 
+// List the caller's registered email accounts
+// (GET /email/accounts)
+func (_ Unimplemented) ListEmailAccounts(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Register an email account
+// (POST /email/accounts)
+func (_ Unimplemented) CreateEmailAccount(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Remove a registered email account
+// (DELETE /email/accounts/{accountId})
+func (_ Unimplemented) DeleteEmailAccount(w http.ResponseWriter, r *http.Request, accountId openapi_types.UUID) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Download a single MIME part of a message
+// (POST /email/attachment)
+func (_ Unimplemented) EmailAttachment(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Fetch a message body with sanitized HTML and a plain-text fallback
+// (POST /email/body)
+func (_ Unimplemented) EmailBody(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Count the messages of mailboxes
+// (POST /email/counts)
+func (_ Unimplemented) EmailCounts(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Save a message draft to the Drafts mailbox
+// (POST /email/draft)
+func (_ Unimplemented) EmailDraft(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
 // List recent email headers with threading metadata
 // (POST /email/headers)
-func (_ Unimplemented) EmailHeaders(w http.ResponseWriter, r *http.Request) {
+func (_ Unimplemented) EmailHeaders(w http.ResponseWriter, r *http.Request, params EmailHeadersParams) {
 	w.WriteHeader(http.StatusNotImplemented)
 }
 
@@ -971,12 +1755,42 @@ func (_ Unimplemented) EmailLoginTest(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNotImplemented)
 }
 
+// List the account's mailboxes
+// (POST /email/mailboxes)
+func (_ Unimplemented) EmailMailboxes(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Move messages to another mailbox
+// (POST /email/move)
+func (_ Unimplemented) EmailMove(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
 // List recent email threads
 // (POST /email/threads)
 func (_ Unimplemented) EmailThreads(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNotImplemented)
 }
 
+// Send a text message to a Matrix room
+// (POST /matrix/send)
+func (_ Unimplemented) SendMatrixMessage(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Find todo items by tag across the caller's lists
+// (GET /todo-items)
+func (_ Unimplemented) GetTodoItemsByTag(w http.ResponseWriter, r *http.Request, params GetTodoItemsByTagParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Calendar feed of todo deadlines
+// (GET /todo-items.ics)
+func (_ Unimplemented) GetTodoItemsCalendar(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
 // Get todo lists by owner ID
 // (GET /todolists)
 func (_ Unimplemented) GetTodoListsByUserId(w http.ResponseWriter, r *http.Request, params GetTodoListsByUserIdParams) {
@@ -989,8 +1803,20 @@ func (_ Unimplemented) CreateTodoList(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNotImplemented)
 }
 
-// Delete a todo list
-// (DELETE /todolists/{listId})
+// Join a todo list through an invite
+// (POST /todolists/invites/{token}/accept)
+func (_ Unimplemented) AcceptTodoListInvite(w http.ResponseWriter, r *http.Request, token string) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Get todo lists other users have shared with the caller
+// (GET /todolists/shared)
+func (_ Unimplemented) GetSharedTodoLists(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Delete a todo list
+// (DELETE /todolists/{listId})
 func (_ Unimplemented) DeleteTodoList(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID) {
 	w.WriteHeader(http.StatusNotImplemented)
 }
@@ -1007,6 +1833,12 @@ func (_ Unimplemented) UpdateTodoList(w http.ResponseWriter, r *http.Request, li
 	w.WriteHeader(http.StatusNotImplemented)
 }
 
+// Copy a todo list into a new list owned by the caller
+// (POST /todolists/{listId}/clone)
+func (_ Unimplemented) CloneTodoList(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
 // Get collaborators for a todo list
 // (GET /todolists/{listId}/collaborators)
 func (_ Unimplemented) GetCollaborators(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID) {
@@ -1019,15 +1851,39 @@ func (_ Unimplemented) AddCollaborator(w http.ResponseWriter, r *http.Request, l
 	w.WriteHeader(http.StatusNotImplemented)
 }
 
+// Leave a todo list shared with the caller
+// (DELETE /todolists/{listId}/collaborators/me)
+func (_ Unimplemented) LeaveTodoList(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
 // Remove a collaborator from a todo list
 // (DELETE /todolists/{listId}/collaborators/{userId})
 func (_ Unimplemented) RemoveCollaborator(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID, userId openapi_types.UUID) {
 	w.WriteHeader(http.StatusNotImplemented)
 }
 
+// Stream item changes in a todo list over a WebSocket
+// (GET /todolists/{listId}/events)
+func (_ Unimplemented) GetTodoListEvents(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Download a todo list as CSV or JSON
+// (GET /todolists/{listId}/export)
+func (_ Unimplemented) ExportTodoList(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID, params ExportTodoListParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Create an invite link for a todo list
+// (POST /todolists/{listId}/invites)
+func (_ Unimplemented) CreateTodoListInvite(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
 // Get todo items by list ID
 // (GET /todolists/{listId}/items)
-func (_ Unimplemented) GetTodoItemsByListId(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID) {
+func (_ Unimplemented) GetTodoItemsByListId(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID, params GetTodoItemsByListIdParams) {
 	w.WriteHeader(http.StatusNotImplemented)
 }
 
@@ -1037,6 +1893,30 @@ func (_ Unimplemented) CreateTodoItem(w http.ResponseWriter, r *http.Request, li
 	w.WriteHeader(http.StatusNotImplemented)
 }
 
+// Create multiple todo items in a list
+// (POST /todolists/{listId}/items/batch)
+func (_ Unimplemented) CreateTodoItemsBatch(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Complete all items of a list
+// (POST /todolists/{listId}/items/complete-all)
+func (_ Unimplemented) CompleteAllTodoItems(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID, params CompleteAllTodoItemsParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Reorder all items of a list
+// (PUT /todolists/{listId}/items/order)
+func (_ Unimplemented) ReorderTodoItems(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Mark all items of a list as not completed
+// (POST /todolists/{listId}/items/uncomplete-all)
+func (_ Unimplemented) UncompleteAllTodoItems(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID, params UncompleteAllTodoItemsParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
 // Delete a todo item
 // (DELETE /todolists/{listId}/items/{itemId})
 func (_ Unimplemented) DeleteTodoItem(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID, itemId openapi_types.UUID) {
@@ -1055,12 +1935,54 @@ func (_ Unimplemented) UpdateTodoItem(w http.ResponseWriter, r *http.Request, li
 	w.WriteHeader(http.StatusNotImplemented)
 }
 
+// Restore a deleted todo item
+// (POST /todolists/{listId}/items/{itemId}/restore)
+func (_ Unimplemented) RestoreTodoItem(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID, itemId openapi_types.UUID) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Transfer ownership of a todo list to one of its collaborators
+// (POST /todolists/{listId}/transfer)
+func (_ Unimplemented) TransferTodoList(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
 // Get user by Matrix ID
 // (GET /users/by-matrix-id)
 func (_ Unimplemented) GetUserByMatrixId(w http.ResponseWriter, r *http.Request, params GetUserByMatrixIdParams) {
 	w.WriteHeader(http.StatusNotImplemented)
 }
 
+// Delete the caller's account and data
+// (DELETE /users/me)
+func (_ Unimplemented) DeleteCurrentUser(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Get the caller's profile
+// (GET /users/me)
+func (_ Unimplemented) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Update the caller's profile
+// (PATCH /users/me)
+func (_ Unimplemented) UpdateCurrentUser(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Revoke the todo calendar feed token
+// (DELETE /users/me/todo-feed-token)
+func (_ Unimplemented) DeleteTodoFeedToken(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Issue a token for the todo calendar feed
+// (POST /users/me/todo-feed-token)
+func (_ Unimplemented) CreateTodoFeedToken(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
 // ServerInterfaceWrapper converts contexts to parameters.
 type ServerInterfaceWrapper struct {
 	Handler            ServerInterface
@@ -1710,11 +2632,11 @@ func (siw *ServerInterfaceWrapper) GetConnections(w http.ResponseWriter, r *http
 	handler.ServeHTTP(w, r)
 }
 
-// EmailHeaders operation middleware
-func (siw *ServerInterfaceWrapper) EmailHeaders(w http.ResponseWriter, r *http.Request) {
+// ListEmailAccounts operation middleware
+func (siw *ServerInterfaceWrapper) ListEmailAccounts(w http.ResponseWriter, r *http.Request) {
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.EmailHeaders(w, r)
+		siw.Handler.ListEmailAccounts(w, r)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -1724,11 +2646,11 @@ func (siw *ServerInterfaceWrapper) EmailHeaders(w http.ResponseWriter, r *http.R
 	handler.ServeHTTP(w, r)
 }
 
-// EmailImportant operation middleware
-func (siw *ServerInterfaceWrapper) EmailImportant(w http.ResponseWriter, r *http.Request) {
+// CreateEmailAccount operation middleware
+func (siw *ServerInterfaceWrapper) CreateEmailAccount(w http.ResponseWriter, r *http.Request) {
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.EmailImportant(w, r)
+		siw.Handler.CreateEmailAccount(w, r)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -1738,11 +2660,22 @@ func (siw *ServerInterfaceWrapper) EmailImportant(w http.ResponseWriter, r *http
 	handler.ServeHTTP(w, r)
 }
 
-// EmailInbox operation middleware
-func (siw *ServerInterfaceWrapper) EmailInbox(w http.ResponseWriter, r *http.Request) {
+// DeleteEmailAccount operation middleware
+func (siw *ServerInterfaceWrapper) DeleteEmailAccount(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "accountId" -------------
+	var accountId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "accountId", chi.URLParam(r, "accountId"), &accountId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "accountId", Err: err})
+		return
+	}
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.EmailInbox(w, r)
+		siw.Handler.DeleteEmailAccount(w, r, accountId)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -1752,11 +2685,11 @@ func (siw *ServerInterfaceWrapper) EmailInbox(w http.ResponseWriter, r *http.Req
 	handler.ServeHTTP(w, r)
 }
 
-// EmailList operation middleware
-func (siw *ServerInterfaceWrapper) EmailList(w http.ResponseWriter, r *http.Request) {
+// EmailAttachment operation middleware
+func (siw *ServerInterfaceWrapper) EmailAttachment(w http.ResponseWriter, r *http.Request) {
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.EmailList(w, r)
+		siw.Handler.EmailAttachment(w, r)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -1766,11 +2699,11 @@ func (siw *ServerInterfaceWrapper) EmailList(w http.ResponseWriter, r *http.Requ
 	handler.ServeHTTP(w, r)
 }
 
-// EmailLoginTest operation middleware
-func (siw *ServerInterfaceWrapper) EmailLoginTest(w http.ResponseWriter, r *http.Request) {
+// EmailBody operation middleware
+func (siw *ServerInterfaceWrapper) EmailBody(w http.ResponseWriter, r *http.Request) {
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.EmailLoginTest(w, r)
+		siw.Handler.EmailBody(w, r)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -1780,11 +2713,11 @@ func (siw *ServerInterfaceWrapper) EmailLoginTest(w http.ResponseWriter, r *http
 	handler.ServeHTTP(w, r)
 }
 
-// EmailThreads operation middleware
-func (siw *ServerInterfaceWrapper) EmailThreads(w http.ResponseWriter, r *http.Request) {
+// EmailCounts operation middleware
+func (siw *ServerInterfaceWrapper) EmailCounts(w http.ResponseWriter, r *http.Request) {
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.EmailThreads(w, r)
+		siw.Handler.EmailCounts(w, r)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -1794,37 +2727,38 @@ func (siw *ServerInterfaceWrapper) EmailThreads(w http.ResponseWriter, r *http.R
 	handler.ServeHTTP(w, r)
 }
 
-// GetTodoListsByUserId operation middleware
-func (siw *ServerInterfaceWrapper) GetTodoListsByUserId(w http.ResponseWriter, r *http.Request) {
-
-	var err error
+// EmailDraft operation middleware
+func (siw *ServerInterfaceWrapper) EmailDraft(w http.ResponseWriter, r *http.Request) {
 
-	ctx := r.Context()
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.EmailDraft(w, r)
+	}))
 
-	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
 
-	r = r.WithContext(ctx)
+	handler.ServeHTTP(w, r)
+}
 
-	// Parameter object where we will unmarshal all parameters from the context
-	var params GetTodoListsByUserIdParams
+// EmailHeaders operation middleware
+func (siw *ServerInterfaceWrapper) EmailHeaders(w http.ResponseWriter, r *http.Request) {
 
-	// ------------- Required query parameter "userId" -------------
+	var err error
 
-	if paramValue := r.URL.Query().Get("userId"); paramValue != "" {
+	// Parameter object where we will unmarshal all parameters from the context
+	var params EmailHeadersParams
 
-	} else {
-		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "userId"})
-		return
-	}
+	// ------------- Optional query parameter "thread" -------------
 
-	err = runtime.BindQueryParameter("form", true, true, "userId", r.URL.Query(), &params.UserId)
+	err = runtime.BindQueryParameter("form", true, false, "thread", r.URL.Query(), &params.Thread)
 	if err != nil {
-		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "userId", Err: err})
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "thread", Err: err})
 		return
 	}
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.GetTodoListsByUserId(w, r, params)
+		siw.Handler.EmailHeaders(w, r, params)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -1834,17 +2768,25 @@ func (siw *ServerInterfaceWrapper) GetTodoListsByUserId(w http.ResponseWriter, r
 	handler.ServeHTTP(w, r)
 }
 
-// CreateTodoList operation middleware
-func (siw *ServerInterfaceWrapper) CreateTodoList(w http.ResponseWriter, r *http.Request) {
+// EmailImportant operation middleware
+func (siw *ServerInterfaceWrapper) EmailImportant(w http.ResponseWriter, r *http.Request) {
 
-	ctx := r.Context()
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.EmailImportant(w, r)
+	}))
 
-	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
 
-	r = r.WithContext(ctx)
+	handler.ServeHTTP(w, r)
+}
+
+// EmailInbox operation middleware
+func (siw *ServerInterfaceWrapper) EmailInbox(w http.ResponseWriter, r *http.Request) {
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.CreateTodoList(w, r)
+		siw.Handler.EmailInbox(w, r)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -1854,28 +2796,25 @@ func (siw *ServerInterfaceWrapper) CreateTodoList(w http.ResponseWriter, r *http
 	handler.ServeHTTP(w, r)
 }
 
-// DeleteTodoList operation middleware
-func (siw *ServerInterfaceWrapper) DeleteTodoList(w http.ResponseWriter, r *http.Request) {
-
-	var err error
+// EmailList operation middleware
+func (siw *ServerInterfaceWrapper) EmailList(w http.ResponseWriter, r *http.Request) {
 
-	// ------------- Path parameter "listId" -------------
-	var listId openapi_types.UUID
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.EmailList(w, r)
+	}))
 
-	err = runtime.BindStyledParameterWithOptions("simple", "listId", chi.URLParam(r, "listId"), &listId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
-	if err != nil {
-		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "listId", Err: err})
-		return
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
 	}
 
-	ctx := r.Context()
-
-	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+	handler.ServeHTTP(w, r)
+}
 
-	r = r.WithContext(ctx)
+// EmailLoginTest operation middleware
+func (siw *ServerInterfaceWrapper) EmailLoginTest(w http.ResponseWriter, r *http.Request) {
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.DeleteTodoList(w, r, listId)
+		siw.Handler.EmailLoginTest(w, r)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -1885,28 +2824,25 @@ func (siw *ServerInterfaceWrapper) DeleteTodoList(w http.ResponseWriter, r *http
 	handler.ServeHTTP(w, r)
 }
 
-// GetTodoListById operation middleware
-func (siw *ServerInterfaceWrapper) GetTodoListById(w http.ResponseWriter, r *http.Request) {
-
-	var err error
+// EmailMailboxes operation middleware
+func (siw *ServerInterfaceWrapper) EmailMailboxes(w http.ResponseWriter, r *http.Request) {
 
-	// ------------- Path parameter "listId" -------------
-	var listId openapi_types.UUID
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.EmailMailboxes(w, r)
+	}))
 
-	err = runtime.BindStyledParameterWithOptions("simple", "listId", chi.URLParam(r, "listId"), &listId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
-	if err != nil {
-		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "listId", Err: err})
-		return
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
 	}
 
-	ctx := r.Context()
-
-	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+	handler.ServeHTTP(w, r)
+}
 
-	r = r.WithContext(ctx)
+// EmailMove operation middleware
+func (siw *ServerInterfaceWrapper) EmailMove(w http.ResponseWriter, r *http.Request) {
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.GetTodoListById(w, r, listId)
+		siw.Handler.EmailMove(w, r)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -1916,20 +2852,23 @@ func (siw *ServerInterfaceWrapper) GetTodoListById(w http.ResponseWriter, r *htt
 	handler.ServeHTTP(w, r)
 }
 
-// UpdateTodoList operation middleware
-func (siw *ServerInterfaceWrapper) UpdateTodoList(w http.ResponseWriter, r *http.Request) {
-
-	var err error
+// EmailThreads operation middleware
+func (siw *ServerInterfaceWrapper) EmailThreads(w http.ResponseWriter, r *http.Request) {
 
-	// ------------- Path parameter "listId" -------------
-	var listId openapi_types.UUID
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.EmailThreads(w, r)
+	}))
 
-	err = runtime.BindStyledParameterWithOptions("simple", "listId", chi.URLParam(r, "listId"), &listId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
-	if err != nil {
-		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "listId", Err: err})
-		return
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
 	}
 
+	handler.ServeHTTP(w, r)
+}
+
+// SendMatrixMessage operation middleware
+func (siw *ServerInterfaceWrapper) SendMatrixMessage(w http.ResponseWriter, r *http.Request) {
+
 	ctx := r.Context()
 
 	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
@@ -1937,7 +2876,7 @@ func (siw *ServerInterfaceWrapper) UpdateTodoList(w http.ResponseWriter, r *http
 	r = r.WithContext(ctx)
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.UpdateTodoList(w, r, listId)
+		siw.Handler.SendMatrixMessage(w, r)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -1947,28 +2886,37 @@ func (siw *ServerInterfaceWrapper) UpdateTodoList(w http.ResponseWriter, r *http
 	handler.ServeHTTP(w, r)
 }
 
-// GetCollaborators operation middleware
-func (siw *ServerInterfaceWrapper) GetCollaborators(w http.ResponseWriter, r *http.Request) {
+// GetTodoItemsByTag operation middleware
+func (siw *ServerInterfaceWrapper) GetTodoItemsByTag(w http.ResponseWriter, r *http.Request) {
 
 	var err error
 
-	// ------------- Path parameter "listId" -------------
-	var listId openapi_types.UUID
-
-	err = runtime.BindStyledParameterWithOptions("simple", "listId", chi.URLParam(r, "listId"), &listId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
-	if err != nil {
-		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "listId", Err: err})
-		return
-	}
-
 	ctx := r.Context()
 
 	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
 
 	r = r.WithContext(ctx)
 
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetTodoItemsByTagParams
+
+	// ------------- Required query parameter "tag" -------------
+
+	if paramValue := r.URL.Query().Get("tag"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "tag"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "tag", r.URL.Query(), &params.Tag)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "tag", Err: err})
+		return
+	}
+
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.GetCollaborators(w, r, listId)
+		siw.Handler.GetTodoItemsByTag(w, r, params)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -1978,28 +2926,19 @@ func (siw *ServerInterfaceWrapper) GetCollaborators(w http.ResponseWriter, r *ht
 	handler.ServeHTTP(w, r)
 }
 
-// AddCollaborator operation middleware
-func (siw *ServerInterfaceWrapper) AddCollaborator(w http.ResponseWriter, r *http.Request) {
-
-	var err error
-
-	// ------------- Path parameter "listId" -------------
-	var listId openapi_types.UUID
-
-	err = runtime.BindStyledParameterWithOptions("simple", "listId", chi.URLParam(r, "listId"), &listId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
-	if err != nil {
-		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "listId", Err: err})
-		return
-	}
+// GetTodoItemsCalendar operation middleware
+func (siw *ServerInterfaceWrapper) GetTodoItemsCalendar(w http.ResponseWriter, r *http.Request) {
 
 	ctx := r.Context()
 
 	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
 
+	ctx = context.WithValue(ctx, FeedTokenScopes, []string{})
+
 	r = r.WithContext(ctx)
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.AddCollaborator(w, r, listId)
+		siw.Handler.GetTodoItemsCalendar(w, r)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -2009,25 +2948,377 @@ func (siw *ServerInterfaceWrapper) AddCollaborator(w http.ResponseWriter, r *htt
 	handler.ServeHTTP(w, r)
 }
 
-// RemoveCollaborator operation middleware
-func (siw *ServerInterfaceWrapper) RemoveCollaborator(w http.ResponseWriter, r *http.Request) {
+// GetTodoListsByUserId operation middleware
+func (siw *ServerInterfaceWrapper) GetTodoListsByUserId(w http.ResponseWriter, r *http.Request) {
 
 	var err error
 
-	// ------------- Path parameter "listId" -------------
-	var listId openapi_types.UUID
+	ctx := r.Context()
 
-	err = runtime.BindStyledParameterWithOptions("simple", "listId", chi.URLParam(r, "listId"), &listId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
-	if err != nil {
-		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "listId", Err: err})
-		return
-	}
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
 
-	// ------------- Path parameter "userId" -------------
-	var userId openapi_types.UUID
+	r = r.WithContext(ctx)
 
-	err = runtime.BindStyledParameterWithOptions("simple", "userId", chi.URLParam(r, "userId"), &userId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
-	if err != nil {
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetTodoListsByUserIdParams
+
+	// ------------- Required query parameter "userId" -------------
+
+	if paramValue := r.URL.Query().Get("userId"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "userId"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "userId", r.URL.Query(), &params.UserId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "userId", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "after" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "after", r.URL.Query(), &params.After)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "after", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "paginated" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "paginated", r.URL.Query(), &params.Paginated)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "paginated", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetTodoListsByUserId(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// CreateTodoList operation middleware
+func (siw *ServerInterfaceWrapper) CreateTodoList(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.CreateTodoList(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// AcceptTodoListInvite operation middleware
+func (siw *ServerInterfaceWrapper) AcceptTodoListInvite(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "token" -------------
+	var token string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "token", chi.URLParam(r, "token"), &token, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "token", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.AcceptTodoListInvite(w, r, token)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetSharedTodoLists operation middleware
+func (siw *ServerInterfaceWrapper) GetSharedTodoLists(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetSharedTodoLists(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// DeleteTodoList operation middleware
+func (siw *ServerInterfaceWrapper) DeleteTodoList(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "listId" -------------
+	var listId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "listId", chi.URLParam(r, "listId"), &listId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "listId", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.DeleteTodoList(w, r, listId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetTodoListById operation middleware
+func (siw *ServerInterfaceWrapper) GetTodoListById(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "listId" -------------
+	var listId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "listId", chi.URLParam(r, "listId"), &listId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "listId", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetTodoListById(w, r, listId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// UpdateTodoList operation middleware
+func (siw *ServerInterfaceWrapper) UpdateTodoList(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "listId" -------------
+	var listId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "listId", chi.URLParam(r, "listId"), &listId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "listId", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.UpdateTodoList(w, r, listId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// CloneTodoList operation middleware
+func (siw *ServerInterfaceWrapper) CloneTodoList(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "listId" -------------
+	var listId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "listId", chi.URLParam(r, "listId"), &listId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "listId", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.CloneTodoList(w, r, listId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetCollaborators operation middleware
+func (siw *ServerInterfaceWrapper) GetCollaborators(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "listId" -------------
+	var listId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "listId", chi.URLParam(r, "listId"), &listId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "listId", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetCollaborators(w, r, listId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// AddCollaborator operation middleware
+func (siw *ServerInterfaceWrapper) AddCollaborator(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "listId" -------------
+	var listId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "listId", chi.URLParam(r, "listId"), &listId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "listId", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.AddCollaborator(w, r, listId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// LeaveTodoList operation middleware
+func (siw *ServerInterfaceWrapper) LeaveTodoList(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "listId" -------------
+	var listId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "listId", chi.URLParam(r, "listId"), &listId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "listId", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.LeaveTodoList(w, r, listId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// RemoveCollaborator operation middleware
+func (siw *ServerInterfaceWrapper) RemoveCollaborator(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "listId" -------------
+	var listId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "listId", chi.URLParam(r, "listId"), &listId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "listId", Err: err})
+		return
+	}
+
+	// ------------- Path parameter "userId" -------------
+	var userId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userId", chi.URLParam(r, "userId"), &userId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
 		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "userId", Err: err})
 		return
 	}
@@ -2039,7 +3330,289 @@ func (siw *ServerInterfaceWrapper) RemoveCollaborator(w http.ResponseWriter, r *
 	r = r.WithContext(ctx)
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.RemoveCollaborator(w, r, listId, userId)
+		siw.Handler.RemoveCollaborator(w, r, listId, userId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetTodoListEvents operation middleware
+func (siw *ServerInterfaceWrapper) GetTodoListEvents(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "listId" -------------
+	var listId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "listId", chi.URLParam(r, "listId"), &listId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "listId", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetTodoListEvents(w, r, listId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// ExportTodoList operation middleware
+func (siw *ServerInterfaceWrapper) ExportTodoList(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "listId" -------------
+	var listId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "listId", chi.URLParam(r, "listId"), &listId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "listId", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ExportTodoListParams
+
+	// ------------- Optional query parameter "format" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "format", r.URL.Query(), &params.Format)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "format", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ExportTodoList(w, r, listId, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// CreateTodoListInvite operation middleware
+func (siw *ServerInterfaceWrapper) CreateTodoListInvite(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "listId" -------------
+	var listId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "listId", chi.URLParam(r, "listId"), &listId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "listId", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.CreateTodoListInvite(w, r, listId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetTodoItemsByListId operation middleware
+func (siw *ServerInterfaceWrapper) GetTodoItemsByListId(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "listId" -------------
+	var listId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "listId", chi.URLParam(r, "listId"), &listId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "listId", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetTodoItemsByListIdParams
+
+	// ------------- Optional query parameter "sort" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sort", r.URL.Query(), &params.Sort)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "sort", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "due" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "due", r.URL.Query(), &params.Due)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "due", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "after" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "after", r.URL.Query(), &params.After)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "after", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "paginated" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "paginated", r.URL.Query(), &params.Paginated)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "paginated", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetTodoItemsByListId(w, r, listId, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// CreateTodoItem operation middleware
+func (siw *ServerInterfaceWrapper) CreateTodoItem(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "listId" -------------
+	var listId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "listId", chi.URLParam(r, "listId"), &listId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "listId", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.CreateTodoItem(w, r, listId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// CreateTodoItemsBatch operation middleware
+func (siw *ServerInterfaceWrapper) CreateTodoItemsBatch(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "listId" -------------
+	var listId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "listId", chi.URLParam(r, "listId"), &listId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "listId", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.CreateTodoItemsBatch(w, r, listId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// CompleteAllTodoItems operation middleware
+func (siw *ServerInterfaceWrapper) CompleteAllTodoItems(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "listId" -------------
+	var listId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "listId", chi.URLParam(r, "listId"), &listId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "listId", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params CompleteAllTodoItemsParams
+
+	// ------------- Optional query parameter "tag" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "tag", r.URL.Query(), &params.Tag)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "tag", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.CompleteAllTodoItems(w, r, listId, params)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -2049,8 +3622,8 @@ func (siw *ServerInterfaceWrapper) RemoveCollaborator(w http.ResponseWriter, r *
 	handler.ServeHTTP(w, r)
 }
 
-// GetTodoItemsByListId operation middleware
-func (siw *ServerInterfaceWrapper) GetTodoItemsByListId(w http.ResponseWriter, r *http.Request) {
+// ReorderTodoItems operation middleware
+func (siw *ServerInterfaceWrapper) ReorderTodoItems(w http.ResponseWriter, r *http.Request) {
 
 	var err error
 
@@ -2070,7 +3643,7 @@ func (siw *ServerInterfaceWrapper) GetTodoItemsByListId(w http.ResponseWriter, r
 	r = r.WithContext(ctx)
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.GetTodoItemsByListId(w, r, listId)
+		siw.Handler.ReorderTodoItems(w, r, listId)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -2080,8 +3653,8 @@ func (siw *ServerInterfaceWrapper) GetTodoItemsByListId(w http.ResponseWriter, r
 	handler.ServeHTTP(w, r)
 }
 
-// CreateTodoItem operation middleware
-func (siw *ServerInterfaceWrapper) CreateTodoItem(w http.ResponseWriter, r *http.Request) {
+// UncompleteAllTodoItems operation middleware
+func (siw *ServerInterfaceWrapper) UncompleteAllTodoItems(w http.ResponseWriter, r *http.Request) {
 
 	var err error
 
@@ -2100,8 +3673,19 @@ func (siw *ServerInterfaceWrapper) CreateTodoItem(w http.ResponseWriter, r *http
 
 	r = r.WithContext(ctx)
 
+	// Parameter object where we will unmarshal all parameters from the context
+	var params UncompleteAllTodoItemsParams
+
+	// ------------- Optional query parameter "tag" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "tag", r.URL.Query(), &params.Tag)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "tag", Err: err})
+		return
+	}
+
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.CreateTodoItem(w, r, listId)
+		siw.Handler.UncompleteAllTodoItems(w, r, listId, params)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -2231,6 +3815,77 @@ func (siw *ServerInterfaceWrapper) UpdateTodoItem(w http.ResponseWriter, r *http
 	handler.ServeHTTP(w, r)
 }
 
+// RestoreTodoItem operation middleware
+func (siw *ServerInterfaceWrapper) RestoreTodoItem(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "listId" -------------
+	var listId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "listId", chi.URLParam(r, "listId"), &listId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "listId", Err: err})
+		return
+	}
+
+	// ------------- Path parameter "itemId" -------------
+	var itemId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "itemId", chi.URLParam(r, "itemId"), &itemId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "itemId", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.RestoreTodoItem(w, r, listId, itemId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// TransferTodoList operation middleware
+func (siw *ServerInterfaceWrapper) TransferTodoList(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "listId" -------------
+	var listId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "listId", chi.URLParam(r, "listId"), &listId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "listId", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.TransferTodoList(w, r, listId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
 // GetUserByMatrixId operation middleware
 func (siw *ServerInterfaceWrapper) GetUserByMatrixId(w http.ResponseWriter, r *http.Request) {
 
@@ -2271,6 +3926,106 @@ func (siw *ServerInterfaceWrapper) GetUserByMatrixId(w http.ResponseWriter, r *h
 	handler.ServeHTTP(w, r)
 }
 
+// DeleteCurrentUser operation middleware
+func (siw *ServerInterfaceWrapper) DeleteCurrentUser(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.DeleteCurrentUser(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetCurrentUser operation middleware
+func (siw *ServerInterfaceWrapper) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetCurrentUser(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// UpdateCurrentUser operation middleware
+func (siw *ServerInterfaceWrapper) UpdateCurrentUser(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.UpdateCurrentUser(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// DeleteTodoFeedToken operation middleware
+func (siw *ServerInterfaceWrapper) DeleteTodoFeedToken(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.DeleteTodoFeedToken(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// CreateTodoFeedToken operation middleware
+func (siw *ServerInterfaceWrapper) CreateTodoFeedToken(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.CreateTodoFeedToken(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
 type UnescapedCookieParamError struct {
 	ParamName string
 	Err       error
@@ -2438,6 +4193,27 @@ func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handl
 	r.Group(func(r chi.Router) {
 		r.Get(options.BaseURL+"/connections", wrapper.GetConnections)
 	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/email/accounts", wrapper.ListEmailAccounts)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/email/accounts", wrapper.CreateEmailAccount)
+	})
+	r.Group(func(r chi.Router) {
+		r.Delete(options.BaseURL+"/email/accounts/{accountId}", wrapper.DeleteEmailAccount)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/email/attachment", wrapper.EmailAttachment)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/email/body", wrapper.EmailBody)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/email/counts", wrapper.EmailCounts)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/email/draft", wrapper.EmailDraft)
+	})
 	r.Group(func(r chi.Router) {
 		r.Post(options.BaseURL+"/email/headers", wrapper.EmailHeaders)
 	})
@@ -2453,15 +4229,36 @@ func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handl
 	r.Group(func(r chi.Router) {
 		r.Post(options.BaseURL+"/email/login-test", wrapper.EmailLoginTest)
 	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/email/mailboxes", wrapper.EmailMailboxes)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/email/move", wrapper.EmailMove)
+	})
 	r.Group(func(r chi.Router) {
 		r.Post(options.BaseURL+"/email/threads", wrapper.EmailThreads)
 	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/matrix/send", wrapper.SendMatrixMessage)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/todo-items", wrapper.GetTodoItemsByTag)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/todo-items.ics", wrapper.GetTodoItemsCalendar)
+	})
 	r.Group(func(r chi.Router) {
 		r.Get(options.BaseURL+"/todolists", wrapper.GetTodoListsByUserId)
 	})
 	r.Group(func(r chi.Router) {
 		r.Post(options.BaseURL+"/todolists", wrapper.CreateTodoList)
 	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/todolists/invites/{token}/accept", wrapper.AcceptTodoListInvite)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/todolists/shared", wrapper.GetSharedTodoLists)
+	})
 	r.Group(func(r chi.Router) {
 		r.Delete(options.BaseURL+"/todolists/{listId}", wrapper.DeleteTodoList)
 	})
@@ -2471,21 +4268,48 @@ func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handl
 	r.Group(func(r chi.Router) {
 		r.Put(options.BaseURL+"/todolists/{listId}", wrapper.UpdateTodoList)
 	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/todolists/{listId}/clone", wrapper.CloneTodoList)
+	})
 	r.Group(func(r chi.Router) {
 		r.Get(options.BaseURL+"/todolists/{listId}/collaborators", wrapper.GetCollaborators)
 	})
 	r.Group(func(r chi.Router) {
 		r.Post(options.BaseURL+"/todolists/{listId}/collaborators", wrapper.AddCollaborator)
 	})
+	r.Group(func(r chi.Router) {
+		r.Delete(options.BaseURL+"/todolists/{listId}/collaborators/me", wrapper.LeaveTodoList)
+	})
 	r.Group(func(r chi.Router) {
 		r.Delete(options.BaseURL+"/todolists/{listId}/collaborators/{userId}", wrapper.RemoveCollaborator)
 	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/todolists/{listId}/events", wrapper.GetTodoListEvents)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/todolists/{listId}/export", wrapper.ExportTodoList)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/todolists/{listId}/invites", wrapper.CreateTodoListInvite)
+	})
 	r.Group(func(r chi.Router) {
 		r.Get(options.BaseURL+"/todolists/{listId}/items", wrapper.GetTodoItemsByListId)
 	})
 	r.Group(func(r chi.Router) {
 		r.Post(options.BaseURL+"/todolists/{listId}/items", wrapper.CreateTodoItem)
 	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/todolists/{listId}/items/batch", wrapper.CreateTodoItemsBatch)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/todolists/{listId}/items/complete-all", wrapper.CompleteAllTodoItems)
+	})
+	r.Group(func(r chi.Router) {
+		r.Put(options.BaseURL+"/todolists/{listId}/items/order", wrapper.ReorderTodoItems)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/todolists/{listId}/items/uncomplete-all", wrapper.UncompleteAllTodoItems)
+	})
 	r.Group(func(r chi.Router) {
 		r.Delete(options.BaseURL+"/todolists/{listId}/items/{itemId}", wrapper.DeleteTodoItem)
 	})
@@ -2495,9 +4319,359 @@ func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handl
 	r.Group(func(r chi.Router) {
 		r.Put(options.BaseURL+"/todolists/{listId}/items/{itemId}", wrapper.UpdateTodoItem)
 	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/todolists/{listId}/items/{itemId}/restore", wrapper.RestoreTodoItem)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/todolists/{listId}/transfer", wrapper.TransferTodoList)
+	})
 	r.Group(func(r chi.Router) {
 		r.Get(options.BaseURL+"/users/by-matrix-id", wrapper.GetUserByMatrixId)
 	})
+	r.Group(func(r chi.Router) {
+		r.Delete(options.BaseURL+"/users/me", wrapper.DeleteCurrentUser)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/users/me", wrapper.GetCurrentUser)
+	})
+	r.Group(func(r chi.Router) {
+		r.Patch(options.BaseURL+"/users/me", wrapper.UpdateCurrentUser)
+	})
+	r.Group(func(r chi.Router) {
+		r.Delete(options.BaseURL+"/users/me/todo-feed-token", wrapper.DeleteTodoFeedToken)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/users/me/todo-feed-token", wrapper.CreateTodoFeedToken)
+	})
 
 	return r
 }
+
+// Base64 encoded, gzipped, json marshaled Swagger object
+var swaggerSpec = []string{
+
+	"H4sIAAAAAAAC/+y9eXfbOLIo/lXw02/OSXIvvWXpmSTnnncd251Wj2Pn2vJ0923neSASkjChAA4A2lH3",
+	"yXd/p6oALhIpUY63dOefxDZJLIWqQu31ey/W00wroZztvfq9Z+OJmHL88Y2RyVjsxrHOlYM/ZEZnwjgp",
+	"8HEibZby2RGfCvhVfOLTLBW9V73/3GEvXrxgO0+fsecvvvtrL+q5WQYPrDNSjXufo5745IRRPO0n9U93",
+	"Xrx4sfP0GXz233bzasKd5Vm2qYRbHOVz8Rc9/JeIHYxLS97TSonYSa0WV83L7fzFiFHvVe//3yohsOW3",
+	"v1Xf++eol8qpJAjxJJEwNk/fV0Z2JhdRT+VpyoepCL8vLDAz+lImwtS3HTbaBCrruMtxYqHyae/Vrz2l",
+	"3UVMWxRJL+r5n+H94heR9D40QcyIf+fSiATGKdZSTPKhFaSHeizV96m+wpMXNjYyIwD3dlkKD9ko1VfM",
+	"TbhjMVdsKFhuRcKcZlaOFZPKaeYmghkx1U4wJdyVNh83e9E8WlUHrwLpUI+ZVGw4YzbmSkk1Zpz9zwmL",
+	"dSKaACfncOvfpukttYC+rUPOgU8mPf95VFt0ByDaE2EzraxYxE+AIv4gnZjabmhaHk5JE9wYPltGJPjR",
+	"qROZp+XYyKlU3GnEzSnPMtj0K+IPqXCibQ3FQHvhRcBC/RE3tPITei8K3OSCq+Tiiku38tN9+mBXJT/B",
+	"61Evt8JcSJXlq789s8L08c3PBfp5Rkbg+hz1tBLHo96rX5cfQNtyPkcdv6supeMnAWhrfOAP5vOH4vgD",
+	"267Tcl+NNONDnTuk1SG+mgRiXaDVoRCZMBf02gUhWpWUYj3dpHc2l7E4f/aLpPgTfLTb/JFf04WM5xnF",
+	"9FP8amvL/74Z6+kWH8Y7T58tHSXpzpHDN7lJ6x9NnMvsq62tq6ur8u6K9XQlK6kCoD7+3D5rC25nNCda",
+	"T9+VFFw/NOTWfsMLe6OH4SQWHmdGjITBVRdPh1qngqvr3W5G66lfy0ibKXdwftwZ+ekiPGr4ymY8FvjC",
+	"8g9bruPV92E5RAGtdmj/NNF8KpHaFinqnVRyylMmS8ricBsm8lImOU/p8lygLJksDnWm5L9zQR+w/j5L",
+	"xEgqkcCNWBLrsjuuPtwP+ZSrjZGRQiXpjMFLTI9wqLCmhvPXI5niYPOwXSocrhAAO0h2IKE0bOI4I1GM",
+	"4XOW8qFI2UibZdtovcdXHXH11q4v471HHTYVjifcccZVwuLcGKEcCEKGFmMXWSjxzqF2jXCK9XQKVyIQ",
+	"nvzU+MpET4UV5lKYxseEwDcsVvhh1x2xSikNY4ZrptNYiFqNqLLHU6ESbg4uRZPewtP0IuGzZg4WG8Gd",
+	"SC64q3GWhDux4eS0kbzmJNaF50Ildq0BA3Fc5C1ceo5h5nkzm0x1zFtXZQShZywubD6dcjNrouqFz6zO",
+	"TSwugrjWelP49zqu1Dpu3HpAKvWihUfwyW9aiZaHLm1+kmfJmmffxEnKjc8dZJi6jjCVU6qCocSaqEDY",
+	"Ys+VHTYfyIclVNGfZtq4dgVE4nORXAggn4tCWy7gIZV79rSEhVROjIUpz3wV+YaFnNLb80D0g0TNC1m2",
+	"s9Ni+vqOYu7EWJtZXSr5iQTaRY57HQ4wRw31WVhYYNOnwvFxJ8LrSEkEtYupTuZWkmep5o2ffJRqTvqV",
+	"sb3Ae76JqXCLw8uRFEl3EOFnRoyMsJML7pyYZm4tGNcGEMZo0wls+JmdqXjNI1XiU3W93T8M3xQCSwWs",
+	"HqN77Xy1VaeIPQ5tVvWakRDJpoztalH3OtwtqNRdEK+JE4avPYbNkUlU0mUda+dB2EjyGnarDXfa7AvH",
+	"ZdpA9pV3Lprk6f5+kHerr6K0hry7MEo+fSbAIrkh/vZyuLHzNHm2wZ+/+G7j+dPvvtt5vvPX59vb271o",
+	"NWnOc4ml4nhtSfAFu5oIxfgll3TO1RXupjIWXZAgldatgIXTiWbwXpcteY2racR3+Ig1A7m2+v/msPxX",
+	"U2GtFJtwHaYTbV0bQjaDb2/+CD2SrX2MyxE7ADBaQK/K4qpwacLefZEKJ8DycyL+nQvrmpBXjaSZXiwB",
+	"8ABgytNUmEeW6SvFCohHzH8ONlIAfQITkohRATus9xVNUOUqK2GwuLamTR5MuUxbnQf+lt1dS8we8Tx1",
+	"77hMh7pZFRJTzwmKEekvUZPWZN2XyNbAr7qKRSBpSzdbJRghxE7Dy40s1dMFTh4VmysmWABSVAH0qkOq",
+	"4CJP0w62T/wYlbnw6edo/pwXD22eS+AD8hUQh2OGRkOuZ5nSSrxmfhwL/oT+0Zvjn4HIpDoUauwmvVc7",
+	"HbxDH+b2u8QI751E3dXb6riNRvjqSRajt5+IczyeTMUtHQqIds1ctJyYhZeiytlk3DgmLdNT6VrkmOmq",
+	"k8a7DLxCIhWxY48XTpam8lM8aaQ9btziBP13u++ZJb9fuHNwwY/F5niTnfeenveYNuy8t7P59LwHI2fc",
+	"OWHg4//7687Gyw+/bm+8/PAfj8/PNyu/PvmPvzReRY0muvK6g+uMjwWb6DQJfJgX4K1erlK5754TPstp",
+	"Pq1ic8FF5lAob2S6BYa/0cnsVjCHp6m+OkEP3p5WzttX/BH2Xo14asWcQaT3dyEyJqd8LCwDFUQkbGT0",
+	"NDgCyXRle1GDNeYukKnjOd7FgbWxo4mbpotrPOVKOvmbSNgPg3eHr8Mmacc1DOTARfEtJIhmraVypm9S",
+	"HX8UTSKHyb0c6g/PH+uVMOTYvQyHi0tuOlInPjXQ7vuUS7UBz9hQJ7OIJcLIYjDYDK4+bM0I4EJKM/yi",
+	"eU9zB4DztuyzlQ/v+XviFijJo7awrcgtEIvxqqDDLX5l4lKYmUd6kPcZmYKKq2rpzQhk9alPrz7d3l7t",
+	"Mf4wD442PL3Opek3u9fp5lx1b+4bPrqdKxOwcinaBmLDF5vMSnENLgvP523hNqetNb6rF5dyImKZSbi4",
+	"eZIYYa2wERMSiIUNuRFw83HLzntgN2fn+fb2sxjexJ/Eea+KPisWtwRD/AG0IchKfo7KCozBrrhlPMuE",
+	"SjCIpKa5/PoWBvqwhbPZ6zB1mkMq5lf0mvGhBeAVzJOuJZZoFEEds3kGUjc76++/Pzw7bboOFiX/XCb/",
+	"4KlMvPC/sJx/7B729/uDX3DGXCZsKFKtxkD4xYqcHgs8xSvpJoy0kZVTz9FNgHsr4fwgeCLM7XA6jJyq",
+	"iQk728B25jBAW8eMiIUqaAn5nxHc3wKCx5NwXr1oUfGa8k90G7/A4ZddzlFn9gvTR4Ao2iTCAPqQC1yo",
+	"WFSQBWgqcABpUXZO4CsLnJqnm2x/XjCJWMDi3TRlMGf5l1MAAv0JfwRfIv6AXLtQhti0XCkIl6RWlCg7",
+	"4ZeCAenbjzLLRBKxYY5aFYijWokiWAteHgqmM6HgyuUytYRtz7efb7KDaeZmlblirZyRw9wJmGUi1Xjz",
+	"XF3z9sFj8r/tNDDBmYoH+qNo8KsXjwg5OJzLpdS5Zcazn8INjKfjobPJyuO9mmgrWIUMI/jl6ODnAUI8",
+	"nCdBFeCYq3jC1VgkzEo4f4f6qkE4sJFw8UQkjI+5VDgAPAF7EaFC8XG5AKmsEzwh8HXXXw+ldbcpk9yq",
+	"uL2E8E4FN/EEoGoFm85DCWiPA+DHJO/4MzJj4cMKLazklWcTJS36E9BwYI+HM/aOHm2AmayQLkfS2IKe",
+	"mEQld6RzBSf3JGJKXAnr6K2IccemwK2evqhi0xwxEYhEUiXATbZXPI/1dIjRG0hoYWZtCLXOZLK5xo0c",
+	"9SzC7vuUj+2SIAlUkUfwEpzYSKYOeJryGvKv573z8/NzGGQskvPehyfrLcEvvEk2cblRTKt0VvJ23DcH",
+	"ioOwmEs4RbAsKBExnSYFuImSCojDj5w5ORXs8YTbd9oI5kQKDGtC1yQKycpJlYvyfMELxAwuQyQw55Oo",
+	"ilfwytMXLOUO5g1LbFT5wiXz/OnL5y+/++vTly8qV832FwsBcYoynHXaAO6ANECQCtB9XdHvCGkeWXbJ",
+	"01ywRI5GwtgIFKMCzNyIcuMAy1GepicC2OeJlxAA2a1wN7LdZWyrynwWAHFAoirsC7GUopweg8EzYiB5",
+	"RQzNnYQPWfaeW3ulTRKxLM0t0x7F0xkL1tAnzPN/z/r7xEJU+J0ZMZbWCROI8P3x6YBt4Sxb/h3r70yg",
+	"We0mi9HKxdhNWF8MH2Z0GvYFtwFOWMFAKxRaiWIjEqGc5Knt4n+pAKJmjM7CH5dbSaPep42x3oA/bgCP",
+	"2ghg3Mg0HKkhR9JSy/rNjB/M8TczWqtxvsDn7168ePZiqZDYfbbr2/ebhfGKsXwO2ZwXvNqlVla+A+qe",
+	"EeRxDjGCpNVEjCyi50earqmInZ//wO3eRKaJEQp+BXkT/icdC34aGG4na90IiUDZX5jF5f4ghYEba8aK",
+	"l14zgZLmvArmzVeT8EVNFdzqHu/4fZ6mxUUbLNtoPeG2+LtUgQN4F+ii1hnk9ZUmpyI3IEAhqp7ghxXH",
+	"v9fsOKvIaOXSgkdk8US04w2mw6N8OiRtprgnZO1O6ajeKiN40ml44HchqhyQC+T2lI+/QJcNmyuWsQqg",
+	"wq40TIjrGa9W2q3K4dsXSZAiRXxxgXG8COa9mJlg9bERCb4ETaD4VH4kAWg9kvVBK91csjh807CdDD1B",
+	"SL/ipbD9mlnhrTBed65oAmhmJkm3xflEI/aTlojHLJ0NGm1nWTrbGOia6ewmoLmuEW+gb/5E8zmH9hIy",
+	"W4qZy3KWFkTKJuXO1QXZV3MybFUS9ppyxKwu9HqU7ISC90iqleoSxGoUCCui8zS3DpU+UONIGUc1wMaG",
+	"u3jS6JTwmkSHVb9mU21EyddGOqW0N69jaFWK241ThS/XZDQ17tB8yu1Kxp6PQ6+CWI+q8H9NGgeTfrvw",
+	"aCLHE2HxK4I8KP4zFTOpYiOmQjkQt9e5KfY6x5K2I6O+FLcUqGCdVEW4dAvX0mxKqqqo3pxonl5p9erO",
+	"EQm/ffZAOmNSrR4/l0kdp9byVi61w7VewDhnVAPdEh8nHV3rDQy+w0b92LLHXjihGMmAs0/I5IKXAn0d",
+	"Ldn94o6XbxIHbL2tT2Q8+YNc1UAUxb24DG3Xvm29mdyu5/j6dktf65YuMXKZmFu5fOaUo5T7W1OP2ITG",
+	"qds9gyPAq2fAz//LmVzU7JQruXC5zMaTaLf3H2cckt+CnkbpXmiKVgkb8vgjaHHF90wTx1DgmvXxZI36",
+	"Ee7DNoWTKohKQabmd2sjNi2dVOmM8djJSxGgc6zSWSm8XhtAA/ywEUUWHAjLfFfe5MyGIua5xStrRh4h",
+	"MGYt+DECkB5VgPgaHkhTv5Xga2THsvS0oJz2UYjMx/lmUlgSuuB3wU0q0c4s5hxFq/3MNZYckLeVK59W",
+	"DDGFs7HnUtub9zb+oK8IeeLciNLoGBeFI14xOc1SGUvHBoen7HFuczQuog/45ctnTyJ2Otg9GcDDPBsb",
+	"nghyUGQQElAZaO7TnefwqTYY1riJ/yIKWx+uRma7oI3HqeAGBVyE9ggj8XKVCmurFhIrnMUNXOweHh7/",
+	"dPH+cLd/NDj4eQCoF6pGEBgww4h+hLkbikREvSoeLrAQYM9NlRp6J2LKJVZlKPAlRJRPyItaNevfINMw",
+	"Wru1h5nDLRwjKjbXiGEh5WQ+5iURTXQYT6QSG7BxNDFhwgrWlVgMbQKHa26Et8qhhL476B8fXRycnByf",
+	"ROzsaPds8MPxSf9/D/Yj9v3xyZv+/v7BUcSOjgcX3x+fHe1HbO/46PvD/t4gYm+Pjw4i9n73l8Pj3f2L",
+	"wfHxxeHuyduDiAFKnBztHoZh3+zuX7zdHRz8tPsLIKT/8WLQf3dwfDaomb6KiZrjsh14jBuiY4TZGEmR",
+	"Jsy/EiF/BLcsam7EXP3ubVeM+B5GpMNoQAaPe3Xz2KmeCnRUsyuM8TAarckNIgvywP7S/Aj/EgmfsHjQ",
+	"U3lq8SpycAvBWz9veFVjo5+UHmm6WF+zf+cYPOdCLB2wBqpnkhk9TMUUGCqpZy7GhXtKB+9BKpWwocYK",
+	"2k1qZ8UzuQHW9K1no//99PLj/zwd7m9sb29vP3/aIbA/Eb0Shk1UUIH+ohkAni2C7sfT4yPmbeZlGRgK",
+	"8/MemmruuR6NvC8k44ZPhZvLxtkKWZRt8mj97L3Ww+g1lqIKBex0ZyU4aD/L4bFYY6OBQ7Q9wQSpJeUY",
+	"moS9Ja+jbyoW1rY9tk5kbc+K4h3+uihWvbKMED6Nmj5oBJMvDLMIpZYHmAWwjgpxv1CjXXQH2vz7DTCb",
+	"Ky3TVoirUjpn4Q3ueOP6MX43JB0uI6gHhJkL2+0K7CUfNkC9LMyzXgWVG9xppaLRh9bszOYlIu+qk01T",
+	"gZHWXOCGPNqhaMYSK2IjXFM5hSYsWUWrzUfXCIlyUMp8283dZInu+2lJliKMz/r718yPi3quWWn98acB",
+	"cxSkpg3juZsI5WSR7l/OJWY/ToZvY3ksf+yf/dbfOZJ921cnL+K9/nf9j9nP/9j78eXm5uaKHN02kQV3",
+	"J1WZ3gnSBGWM3nSW6/zxIVwiAn651vYzPM6E6u+3pkXyGGmrBdz+MGkMRu+ysIRypz5vsTrWRUt5KPIp",
+	"XCyftgiv8vPTRxteZKsuIxxIPSKxj+Fm8URAMgK5LCx5SsvSLo8wXJFPZRRig4SKzSxzKH2qhJK0hjMf",
+	"xEJb3ALNErX4ABNahY9Sg6eFsrZZA5GduYtffvqU/fL07IIP40SMxhP5r4/pVOnsYpvvDJ/GS9KBackt",
+	"ec4eSOXW2EKm7jVyUmsn1LiQdpw7FSppxbi1Av+jQgfgik036fmm0Xq6WaYRlfv8QaSpJj3wHSY/rzbz",
+	"V+plzanfWk8h2foxnCxPJbdPCvPYXPj8/+dPtCtv+6Sa848NV5aTkaO//5oZgZMV/iNEcopMw0BqZ2ZF",
+	"GECSg3GFu5AY56Gzyd4KJQwv0ph8JGkdOV8On47+Gu+Ije/4y+HG89F3YuNvo+fPN54mf413+LPkpdhZ",
+	"nchdVvjCE16FHW23CtUmma8e95dfzq5OZHIo4vw6CdbFoE2rOhJXoZ7IoVQfuxQ9WVmJYPFSMfVgr9zI",
+	"lavOsVxdMW/b2qtVAJo1ousUnFh2sxyJq4FONLi32rWzpCmRcdF9u6rWU5KLi/UcM5WSDB2Szq1snToz",
+	"UncJQwuweB/eBxr3ccNdvhvAu9VCSqvysJvrJwQ1vtjTfF2k8mSWHCrEwjfoOytO6VpLb6retGJlfXUp",
+	"mxR/8SmTRtgLqS4mOje2nh3z3d+azNWp9rxS4qDBAAQXX0a52EVw41+frsx/oTj6i9yK2txUNqQ++U8h",
+	"sLqc2zqdWQa12tBqNXL+MUVsZ8JYrdi/NNW766IVnE64EUn1QLt59osvFh36FodsSHFPr/jMMtjpazbl",
+	"5iMZ7ND3xbGQBglSVk+FVoKJ1IrGSA6awNfTWQCZN+BjfQ5KZksSkO4s4/OVUK5RacxvrrqIZs87AOh7",
+	"AaD1omsdSC0S7SmqdEUCzT/xtX+yf+fCzEqzHEizbw8GbAt0ig3UM30xoi5KQRPpdGTTN1O2L3wzbArL",
+	"t3BqE838S4T8Tkw3u0RllyNftBesOfNPivI48JE2Rd6fHM357KzA8KLN65QgXP9a6lpi8Jq315z32ZAc",
+	"iXVSE/EJMQ8z7lBABJUV0QvlR6kYR3JthMTDvAWvV34LPNCN8OqHCCxMbPLp4DTDaxL5pSOnOErR+CSI",
+	"2gtY3DHuFs908fouCXPJVR42sozm33tL45xurQTL+JiopMCDiNkJz0KwRbgEYITFDI3C/tXJuxRW01ye",
+	"9JPby43tWHmuiL1eBVhcV3i/Ns1SeFUQvXR2T0Ui82mjvzs3Y8wn9jjApN2khFKKNvOMjtLRcJTC06zT",
+	"hGk3EeZKWlH1KUNt2KicEyIGGy2VNaJZOONDMC3aELIBayuMHM7I6VQkEUv1lTAxtz6JaV6PJPtFccJT",
+	"/inQ4nfPo/USUudPPazdnhEFNygt5YO2OHjiXj7q4hrEF6ZoQ4hmMbigzbKK5yJaTLmahSVelLmuxbeV",
+	"yJbhjI2FC/O9mfWTzW7hn7dRVbfjpVNuq4GN4ql4wyiwtoiJT3GaF/V6HKS83AQAQKo0Xe/J27tSmlh6",
+	"sbSos4YTANBWYDluq7gYxCpbutExOskHcCN5dJKwpBcSu7JyxALZEpYOwga8wNDuYjstYB25J7h16jOT",
+	"vOzP0McAgHIwzNOPHhRkwKMsiHDv5fEElIhAnrtpWjAoMgTH3BgpLFO++IffOlN+l2Cow1z1AuspKivw",
+	"dJQPPMF6nhpWGX7FcoLFr7byGIcLj7v65UqhosCcZVi3Sqleq4h0TQ1e1CtA5LhY66yXKlRwnqCwvWZ2",
+	"oq98RrRWsejsSKktqLb+qAqAZfDrKGjBJDYqc4dRMMj4WCo4a4psZAeXpBGORYJarY95yy0mxEtLctor",
+	"f/9BKbQQ6DYWEUOJh+or0J+vJjoV1YGKXMAsJApRKBkgeiknUQKzckUcqLdIaODW+DmlaVBQyVi89hn+",
+	"IMIFsQOzN+AhUcOXypDeKPGAZUhY4U/STfot/uEbE5tTL5t0A1mDvbCQ7Bq3Al6IkTDtUhAEpl5wexHP",
+	"WZ47G72KyiJ4VTLrwH6kVc1stGDUWeQlSlxdVOWA+S5aoVtEdSC0QQxFrKe+yAsOsLYbtjZ1ExRJtl2n",
+	"kPq6PoXmjjcLpaDbF3dt29DN20Y6m+G7GzHrpogP8+h4JK5YGLgsBleGXHvUQfNQxZCx3vxk0vgQNeRd",
+	"8NjjHxDiI8tggsV1TMsiN5vrCLKtdo6Bn5H5N3AJIiHuPURtUKtNBq+RDMIwTvpfVHnFlzB6Wd4iOBbk",
+	"kw8FuMGrQfDXMYk093NosYgss4GUGP4A/Rm0uLlq1VOpqt0Pd+ZvzGoLjjmla/dol4XHhTx7kMPnW2+E",
+	"SaWKitaBiYhlAnKBjCcsETyh4NcRLy/u3GJ0hNMJn1EuqJ5qY/TVJttVvqQAQQA91M4yQtqzwV7drVxb",
+	"ArlTqkaENWqRA7WGp69ZTm2b5FhpXAVYMWoTc1+9vTLhs6c1q8Wzeqna3Y3/5Ru/bW+83LzY+PCff+nW",
+	"GhMOsLUOd6MXYyCnwjo+zUoCyq33ZpQKQjeWWdQPmav6An+uhinVAKPEFfztv+u+89W1vVdHQ910bfuF",
+	"pa8VPNaRVipzvQb0ZblyMiUvgXcOLEXoFQaE7oePsnKp9HXvJdFMLj6+tSAZFoOFQIWaJbRf7xWhLVO1",
+	"okUK6mDuCEizrHh9pabLKdyRodkhN8JAkGH52/dh6z/+NOhF1DwXxQ98Wq5o4lyGWZhVV5yEzaNPLTQg",
+	"e1XqefQdz+TfBcRJYqLmiHI0idmjEM/eydhoH87Hdt/3KxfNq97O5vbmNkyrM6F4Jnuves/wT8hOJrir",
+	"LYhKDPFi8B7he+br8QCvwHBFSIrovdfWlbGWvSJj4o0PkorLmtA88xE+Wm39y9K9RQLHKl2gKRDwc/0s",
+	"feGdkFeBG3m6vX3DS6jFk+IKGrlAPawTbrRYWDvKU4D88xtclU96WVxI31dCkKGl6fPtnduf9UzBzrXB",
+	"EtQbIfiRIgwvhZGjABFKkqF1vbz9de0q9FUURbd4agRPZsEoIXwdsbk0LGnL/hnsMczHsTRa9dZ+Ant4",
+	"cTcnSo3DQt6P8C9GvaJXW2+3xDtgkrDIWgAsvr5F/QW3sLclsIWty2dbGL++VbQEHIsGUqcme2+FK5sW",
+	"I9vwvn+LWkUTB6t20awRbFQBSpfmoJ8/3CKFtzZkbjiM731lTwJYSV7t5FC7QhBS1cvj1w+fP1QP8q1w",
+	"ZV+fSjNtS1HjrIDoigPF3M6t3+HTz+08nHZ+Cu8ehtajDacKF0R5qCPy9HU50KY2258jP+rXjivYL7sJ",
+	"RTDwiY7OOpFRxKtKhNmacJWk4hbQBo+QcT+rTztZG2VEtvV7mbLyeet3n6Dyeet3islYjUr5cCpdCZ4u",
+	"+FTOuPTo29CoPphf8Q2MRDteOlBrFlItSSVamgl2J8RwPcGMJ4mkSJyqel+zThdS8ufP90t0R+JTleZu",
+	"g8QQtRmvzbKEonTutn4P2WErCecQP+hEL2HMjrjB0/QBMeG5aA89BqObJkn16fbzVa/c8Jke6jHDLtzM",
+	"ZiIGKdWfLjDONG0/X8q/WSEwUYfjP56kNNcAu4Ea6Q0Spwl8tyQqBbBtFOdHJ0P2Xt+XunqKRuvpxpTa",
+	"4bcLvG+FW2ie/9WJvGv04q5ssyEvc+F44XUWgIhSBkE3YQDeStx01R+BZrFbIGFpnZ8eZ/d1laWqL7C+",
+	"CkCI0ER1iyI9luFCrYl4RzzwVZ/K4+gWk9NmCbqxoahuWj9pHrDNg7jgoVTxRBvmCsOgh7HVZoN8MTB4",
+	"kqcihAlIrTAsr2FJ9N21dtignPnIHTYUI+1b3RQ5CTRT2zoSaUQQ+haFPBqvF/VwuN6HDut5R0kYTBWx",
+	"f35tlAOXG7UIN1iT9CGMDWukBirV9RWZHk9XdTq5G45SI5Yu3CR84IHTkUfAS89v3/hSLI7ohrp7YJ2V",
+	"te+q0PGZxfUNF6kVK3nU1u/4fz/53JlbQVxiJ6nSj7z02lrFJm5T9JhDq1VodPcIgtN+CX7wOcSACzRY",
+	"7gpEIDTsdFud+lfvkuhDH/81qD7s6HYExHhumi7E5l/dIoJt19z6+Hxu68vU7WmeOpmBYQ4oaSNUYilh",
+	"fZNpu9izv0q0Q6m4mXWodJSuiMLp4n/ZuXHCJ2gv0zrmeXXBcEs3TDq7d0fMTWE3waPKNfy2qQcWZPr4",
+	"Dqv9vVPsQtaC5qlUH9uRfA+d+5BdLpI1UP36AG3OaX+wSEeQYfGfCvd2kwTz7tRHj15z22/BtN+D8vGZ",
+	"FhMqodUxjjriL+DaahGmotrcpAzTYJSa5zS0labD/npEVAJ7Az/BkqPOlihdyundRJDOMugtHeDNC6FV",
+	"prT0ML5GPWURA7wgCkfo4sniiTdGDN/tgd/8PdS4qTuOPVmNb2c+Zyhuwrv7uWm+Hmw/ERhixte+vraM",
+	"GBlhJ+1i0wm98HBuse0HIJB7qAXzzTf0XIWeCK5S0lqOpnkW6ykc/xLbwJl/5zoW7UXT4+rWLQ/T4hig",
+	"MG+JuyUbRDiYa1kAi+LxVZtPU0tVy34TRoO9G7sSlR8yoRxmgWbCFA6zTfbe/+S71fqe5ucKjRQbIWCO",
+	"XGjsSqZpMFnjC1kqKmU4yhpv/wwT/PNcYb23CNsgZWUMHuXULYqMlY3eneOrnLUL3hz6nhhhj6x6OvcR",
+	"anl9V1ll5S3+sXrv01amgrnf8OpuePMWr57aRMtunsV+q7bec+B10V/Bt7IQEFpZdOu659hOmP0OUOng",
+	"3W7/8GJ3b+/47Ghw8feDXzARVkPaoRrJcW4ChtVRqCxm9chWG+f6nrwBDyg5roFf7UF1U19pCz211TKn",
+	"2HvDQwM0Tyz+YZl0UclpeJYVx1etfWo1tm02oaAAVcbHGjj/yrEJnC0bAG/2okZTVxXHbsnEVZ1iraj2",
+	"nVtZQmPg9EJf5PuR2eA+Q5SYaEveFQ7Z22E5d0Aku/WQ/jKA/RuDyE2AxNPbXwu0MwpQwBaC8QRjpHX1",
+	"GoYCAmnCir77cbnE53e7xETSMoiCqYuPnIo5fhruKbDX19hn0x289bv/qZMBdY6PrVY8i8Fv335achfq",
+	"rXdXitlBFcRVtewbNTde9yd4Poy33vI1NHWOx5OpX3+zWYSQsnzxNu/XYpYbSxzTsRNuwzoj+LS+mtVO",
+	"1oXT2BexTkTCMm4cCxN+u2Pnk8Tugm37qu3a0GHU+MLznWe3v4L3MK34FAvha+L4oC5W0hSz8jfxEBjV",
+	"g7xH9/WVAm83RHJjGST2rv/ugI4TW/GFkvsVfhWq+TcrKcGoUqlI/8iyHwbvDmlU0D+wVpnR+XgC9zcS",
+	"zQYWgbFcSSd/E4Y9pjFt5GN6KAPI2IhZN0uFRR0HuIcNl+GTwuGWlY0FYErqkAW/LXSNw2UVudl+uVjc",
+	"gloLAh93gmGHH9J3gS9hT0hf1xEXT6/t0dnC5YCXP6hVmAyMTcnC4IkW1h/MpeApGZGkK+oyvWamOtqb",
+	"VGNHCyfS1FZbdF/54jrQ6guTK897eJD0dWCM5z1YzpU2bnI1kaloMiIh18d75BZvFRjxnhKRK/MvyUOu",
+	"dKD4dpvcy23iu9LqolnqPVwogCYsa7tVvl0lS64SCiLntV4uvu+/5+oJ8Vtg07zKpKESDVSdq14ypfV0",
+	"9TVDJfBgWGqRX4kbr7ZRxR7TvvdxhLyWlnc62B2cnbLH7w5OT3ffHpyys6PTg4OjJ8C/IQ0ePRCWWZFS",
+	"SSRt2IiymStth3jGhMIbDWPUyQE1AsupYUOewBJgsk32k6+6Ow1dhiNfeprGR9XcP2OH/dNBUdUS5gGg",
+	"iCSCE6Cy5wZcEs0cfa+0Kt8ST9/z5uT74+p7Kw3a9Ab6UYqj98VqEXjB5PnNTnc/XH+3PIAC7QsRSXzy",
+	"FSDvm+F+M9Nd705A6qvK1siIC9ZXZfiJ4aNVBpB9fOcWORpOcJ+eBb+Adn6GL4AjR6hEVBqFnZ/Tk1HK",
+	"x9842X1wMiigGCykpEEyPBIb8P2b5NjCJU452koLDRkR2be4ngdhyTCoD7hdwTJ+8G8tGPLri35rdJ7V",
+	"GRV22Y61glJghFDUgx+eWAfSox55xzdp+KOUOxZq7TYlo+LntUCgVb3SbitGswqa+5TfTmQ8KZbRzvRO",
+	"K+HhQfZmBspZBiT4xvHuRXYLEhtgOFJ3cbXPCXEoxlDH1KFAq9jD8AU/SH6IwSJGxEI57zXyaB6ue+Aj",
+	"wHOmwnFMhquwRYqV43VnUiIyI2LuAmE3Koz94stbZDpYjGYNlvN85w4Q5EAlmZbKsRJOm+zMCuZhirZp",
+	"z/U3lxxWAfvSlOwP7nE58pPaaSm41ZZfYX185wGdyY1fA974uv4dgOD7dgk80ECbB89cCX3maLVKnqHt",
+	"whLqPCSB7/aIU1r3VdLmN6r8RpXXosr5u5OqMU5L9xDYOKiPao1W4RbbcGI1xcKLA2Hdtzu1iW4X2OGf",
+	"k34HE/LDBDwuOmL4PrMJEDdPLdQ5Tij8+mL3bPDDxfe7/cOD/Sd/ErP1PJhqBmq0YouEPUbo7B0fHR3s",
+	"DQKAIoTk4PAUzvp0sHsygJ8hzsNO+EfhOab/dnB4Wn6nQx9t4Ae1CUmx9N9A1N6b45/rB/IguR8wI6/p",
+	"+cB+lRA9tvDEKt8rrekrnaXkaPQfIAwL5CYfI7BadDqe98577Lz3H+c9nz4gnWUTKQw38WTGEoFJbT7V",
+	"gDtn5DB3wrLHUoXej1hZkKcbuRVMK2GLNiXn56dCuSiYrCmU5/x8YLidPMGoGQpxoSwEqgDMeHCmOiMo",
+	"tT6T0Kes2A0svSK1tThF3xXA+kPz/rDL5ZX3/UuhJ0YRmvpNYHtQAts35+MXyJQVxH5kmz2PED24QmR8",
+	"B6/cIseA8e+VYeD8K6PjLKukHXzjEHdrbadSCKXJ/U+WhFGlf59zXfbnfXf8jwNsznvW339/eHaKUkTN",
+	"m3cljGCpGDlgFtiL7wFzLiDHculOM+7bsTS4Qb1TcgUDG/i3vmm8DRovgfB+Fd4/t5izwv0VcBzR3rfZ",
+	"skIl7RrPqVCQoF7oTUBDVOaa20oeduR7/sBfQicqRBDfggnbwo0NV06AmsOsHKsNqdhj0lAu6OULfPnJ",
+	"Jvuey9SWLTOxk7hOBHu3Ozjp/3wxOP77wdHFu/7paf/obZF2YASlk1HGdkIzU3fmFSMd/Py+f3KwX4zE",
+	"JnoqauYKy6R7jY+qg8N8gAQskTbmJhEJ9XwskwvsBEU92C4LeeaLGhUAmaDmSe9Wm5nBbPfayowWsDqF",
+	"wN5bQto91dKASZ/djamphuEj6nBaydx5LDbHm3jB+hhfIPknd9Y17Uiz3KLi1MBM7lBSwrmBQfrQ9Xqu",
+	"KjV9xYh2AOSdmgor59doKdSmuJHW6jEjMKcAswlqLN/DAtCAbg+nE71RFLpZWhYI32IxN2YW7gjHx5Q8",
+	"Rpa0tF7VA/o3WNKZQ/dxYZlWERtDeBm1dsBvOIl++DPF8LOBH16ClkpySVl/H0sEKW2mPJW/0cWNBwQR",
+	"oMj8+dinp3HIb3vsjJxOPVsHHcnE3IrkSUsJodC72r6ZDfh4VajcgI8BtiOZwuqGs7ZoNxypPR9+rjPw",
+	"8qbLd1IOq73/fiONxZgYAvhEmHLnLB8gvBaVfC9VUlkwYCNgHI+NtrZenQYw085TzKaM26lGFsXbEh3n",
+	"mHmL4otWgv3j4B8HRwNMwoCBKOdxgh3/k1ywhDsRhWWsRVmb7ICHzhWPLGh/QD8LaZ44aX8fTcthlTzL",
+	"bGh47quJBc3wNTs9e/du9+QXLyj5RUuXCvZYOssqOy+FL3ouLbXLpmzUvd3Bwdvjk/7BKb5Cu4L3Ntle",
+	"bSEIER8gZ4mZ0Ul6iQ2yZmkW/BV39v74dMC2ciuM3ZoKOqeREMkGveMF3X/ib/8kfykrKLsMZ1rGEMIi",
+	"V9emAtZblNSrI/nKjP4BnTOBA3ZwZ3LMO2lB/o+Y9DSlDeTlaiwaWPr4VpJZ9Hu1K/E83e1V94bWdqDB",
+	"ohN7SWZEdUvKEIbu9vbNDFqRN5UHXtax2/dPMVJcCloEzgi+kxYunodZrl/YJGq+X0Gdmmqkcrzy1Mwv",
+	"Bqvt8bEoU+XQ11RvSHM10alAbuBt09IWRchgZK1in+u8ZjOYFyubwSzs5zjj0JueutGUTAc6623s0R/J",
+	"tBCOIjPiUurc+m2+RyuPFeIjkDmxPewOTinxQihmOJp/3IQrZj9K7M8E5Vcx2QDC0PWVpTJeCELfWB5A",
+	"FipsY/Y39RQaNxRuY+5K+pLV9CDL7YSh0clipzc9YpdSXLXDFM+mt6Jd5Fy3M8Mp5j7wFtQ1WUBygAsT",
+	"6lKkOhN1OQwAF1VyP8uETwJAoc4S+D3cFVUEWEQteIjV7fApm+g0sWxne5tGa9+zb9EkrhPU/wXSjFbi",
+	"eITE3lmuOfSJdHW5Jur24XssAPGhMQI89aUdS24SMSWuinqBvahXSdQ4APlyQXQ4UE46kkX8SRUIAUnE",
+	"r+lGlI5BkjDgSH+0caSV2EApjJgasm/uxHIM7FWIsqG+Xg1bRhrcC4BxhG3YOh9klpGr9NLXqqRXRB60",
+	"24BMAFc50dvSNcGqnjXVejrSwCATOZICLDEqFjgTgJCN5aVQC5BYv8Jq5Q4YzkDIEiZULG81qzGuWD8R",
+	"00w7oeLZxt/FLPA3p9kUQjfojrHM8pF4hVWQMsHdXMXTj2KGwCryxKViT5+zic5N4OXW5+lKILO0xIrH",
+	"OFKxCLdxIqCni0heYeWNJ9UMHeSFyPTQiHWuWsoXFlRya905Sjq828TC+rxzoldAgOLOeCh9N17eYe7x",
+	"HGbOYzeYShwU8qUW0WMjLGl7T+/IajK/IKgVU+nIn/gQyURCLRihXNjXegyB6IBx4N8lZ5iTTrekupQO",
+	"irqjXvEZiuyJbEnI5S4+DzjYx69XSq34VlXXiWs0WozSUI0PP1puebhVU8O1KbFUdtFid8V4RdvVprhp",
+	"/E1+Jz5nfxC5+qj0lYqY+JRJQ4JlFf/ujGIrQArzt1gGClgV4YJkePNfTXi9skspQFZhbtcjoB81yq8F",
+	"6VSLXBHZzBOTnXBE0XaN7xTfKPS+OynxXZ+za4HvqgVp4TSGuSszAvWVelBi4Y2KYPfkgblv7+01pU4K",
+	"a0AbFptAGjhRRHkhEz7N083v8F+naq4Vsa6joaRCvtqrz803Da3h9ou+ljLainZZbZ99aWOriiwQrTRN",
+	"NTet6gTsYJq6Q3Bv37GUTcfwh2Z+t4GK1F6rRJaysVbu2tpqfSHlk2vgdlHxtppvradp3jUN5L711kPR",
+	"NG8DYekc6ryz+QrbilOtRHskE2lllnGPmdKlIoH8jHx7+1mMv+KPgu3pbHbeq+iyGDD6qO6uokiiTFLY",
+	"v3TBzf0YHDhRxR2XGalhq/gF+JefhJgKW1aegzmBPdAY1nHjmFRwHnB1wOOKME1mZwxFgI/I5e3lRYW1",
+	"FjEMr4iZqGyCNad07AHo1qfzQOKxzmZ3eNfcvEUHrNh98j0360qgyZcBB+Gw7yxiZ88rA+TXxNO9cxX2",
+	"i0kZyKp2+UiFoSUFbMFsiqEdq+XVrbp2uawZee3FL5On6jpt1dv34CSsbn3LKtvZF47LtIuOWvoq5lT8",
+	"+0TEr0txW8CjeeWgxQSYJNUjuwY2f21iGPTiru64u8V/joFWBmE8Sb4WqUmTTj9XwHn75eI3Z5YMnsEw",
+	"Vzd6fpHxjj0eHO8fX7zb/fli7/jwcPfN8cnu4Pjk9Ml1enZXR6bQwg6SXXU1W1NRt1bMB0VAatVcFJa+",
+	"UvWJpwJ2aicyI+mJPHb+fk0FGFDcREiD63pN4evT3DrmDFd2JEwZEsBkWdKowSl2CIN9oQqFC7pn20lF",
+	"xNQs1Wos2k3FFbmodZTCiFkZpMU034n9NxLFYC7wrRx8rbaFguoqlmeynnFtDn9/p1igpTY3wuKHyuyj",
+	"LtFR1LjH1uiueUU3EBvVrWl+lQPQApuugQa8PVYpSaUFnwgbJLtrmyC0hK1XP+mIyTUm5ed3EzG1Ir0U",
+	"dt3WvnQ+9XWgi7ALR/btattiWM+yseGJT7ljP4nhKTTfcBSdCRFRqIcHrohtc4twc4j5ENR8keJFZckU",
+	"sHY7+dejYP6ICmsunirl50SgRkAoXHV7m+wNxHcJY8sIUYpZ2vUuACTAIshMVddeZEL++NOATfmsCI0Y",
+	"CmodIpIQKWrzYWa007FOWcalYef+KKAIRGFwAPcq/ijOe6+rNSQwo4iK2NvKp3RR+Xcgbi2E+D7bZlbE",
+	"GvO0VMLiVFt//1mCug4mR88K6YW6eSCgZRXSHq5Lgt2L07uuZnWF5s67utl2bsF04Hs+N2RIXskitB03",
+	"XpJBwI7XvpWCx3y5QBR3JpeCjbpKqDkRcBn/0MIYv9dmKJNEqA5M75pmg1PsdkasIJ5wRSWFa9exRnZR",
+	"Lr+dbX3KtHGtbIuSHQMJPAomPW7Z3uk/2GOtBASIluH3dP9Ll4qoahuMWDDcJWgIvPCGQG0lPa6ZBK2Y",
+	"ylinWm1YASTkRDATgtiOUxCe/xecdVSR4qumKFgkrC/kDBC3KONBLTaIKpt5UaHZMg64pdQLwusLpNe7",
+	"E0FoqR5ULWGmxcOGGNNebC97UU8oCFf+1f+G1PXhfvxfVZtk5NMC7OU1MgII6UUSDqTiMvO9sTb2pQ3Y",
+	"uUgVFaxBbOSQsiQApIsJIqVpfaWv7JvhtLP/uOwtV/I8z5S0YT+eHh+1cjwfZdbuFjkUPuCEpMupVOQh",
+	"pKxtDmxmppXwMm8iAtujpJlV4WzMafYvXZXgMFmpbpq48jHkEBvlA9Olt3v0931CX0i31iCJF8H2E2FE",
+	"TXb6KERmy17bE24nmyviVDtG0v0hbGlze76n+Nnq7I1hcl7Ev0+r3B3wpgalUvr9Pxy7+nXiXot9pFJ9",
+	"XLRut3GqTrnEHNkdQ+cE6Hj+/qZEFEYxsq9YFTqfNlQCECoSEIv+k8IX20c9LkLxKSTsMT2Esg8o6qVS",
+	"icjrdjP8Fu+R4s5LuONDbsVrYFlMogzCKPmSm7HwuS/sNExY0Bel4jClMRaEKlVwRT6FimhGCTWwclbI",
+	"p7R6JrhJZ6uTkQ8DT/oi5xfB7jadXosZYaaS7YXzv4JDD7IKe4zwJxSgepbDWSld4wlNJLWSS/UVXVzF",
+	"x0Mj+Ee8b6RYkptktWkTGcNQFbmx8qewjt6HLjst7zUP6CQXZPqQll1JleirTUBP7gMJ9FQbUEa4qeR8",
+	"Jnxmg72kMH5nRoPAhvWRfgMkf3w22KPUmlxZ4Z68RgUK5kNUrQQe+KVcTbQVRZ4lug2o+2w71JK8LgEG",
+	"+PiZ4PBhL/g/7aQTmFpTHmmha6Q8BiPHQrIjpBECVqCkcaXNR4RogTXU7C6ILJR3TSn8X32aJLKY9dIk",
+	"Cexff5okMMprpUn6C+WG0iSpOsAfIE2yufxD1O3DIk0y6jVd5OsJnLSSlRmXtLNvOZbjP0aMa704B472",
+	"J07HRBr40yi4JcXfrWrbxmkGARcfXGro2t71b2mk95pGGtyhvIMmvTVELrs6HBrgCB/QwFaqcSoozIVj",
+	"RZDXzNeGJW8jraEm8ymtBONGQBfvoF4XjVVLuTvODQKjlDW9pkIXBAnWpAPPfP3EDZv76lyF/C0tk2Ol",
+	"DSoffwK+bd94F+kfgXt3kh9rbByVoz59ttMUm3qzPP7G65sNSkHkoXH/m3Pnfrsf7vF+mOapk1kqqjJv",
+	"1zsi2Hk2eJq2XxXvOBhBKuy/mv6BEV6FtxvT671Ze77sI5g4vQPR3zJn7/d3BwfFteEhVAzHeCg1zlOt",
+	"hI+WoBgASpV5ZMHQasO9oGIjpkJh18nTfAh7gCjPaozLIxuK1I0xMsYvczPkUuFD8stT6V4jqH9M4SRv",
+	"4th+xbtpWrDtB+0sR2sjAXLhmCbS0lmF6pmp/CgAWgvVLdurVbZrkLftOccFUtJY0hjoMme58dh0nwEu",
+	"AXsqopgedaNflJmQcPMGun2fu4ZoFi904ade9AJ0N+LKSCeqdP7IlmKX0/AA0MZmPBYJu+RpLlqFRjQO",
+	"cpYYPt7gKtlIjM6YETSpRJYxlc6VZldcQ5jNskQjNMdg4fbBxsg6KF5KXwpyC6OshnHZgH5tLEp84rFL",
+	"ZziPZyLLmUcDiZ/Q2h8keX+BQDa/aFsF3BzKSIOaCOFcVIpKK5Z3HaHtnovSDiaifed3JbHtKkZVWElb",
+	"8eb1EDU/FEAW1ZvtKxXqBoFKwatEQHeBsjHvAf0BEcOC5KhoJknNxugr7aNWiY5Z6dYNhfaq59rsN1c3",
+	"I0BRGsSNClG1Ib8aQepMxd9EqW+i1LVFKSC0JjpeILIVhP07/Ne5LM5DM69HKyZHFrSiJg8B4I5q8uCC",
+	"KB3HcxhnuF3hIsKPtLnByjzSiwfLKvPAWV+zMs+9n/fyskC3cuLbd+xhqcgut4k3lTI6OFzXMjpfK6dY",
+	"VsPnpvDmNmv4dHcJ3jXCfi01fNqo5g61BEq54baEWaEDFLKrb1YUzMScSuF8SckhuhQ6SQtbPvuwXRHY",
+	"99FRRZmfWVgyXnhoF362TWF8mMvHFTX18V3HktyQYMldERO46x1s3JUBqlluQLLPhJlygHBzkOgJDfuV",
+	"MaYQYVaezh/3OisOvlOW8moBe38ediUpV7JnPWqhRu4L1a6rUdM4vOGw2kgpFFZoL4A88G88xHS0W7rB",
+	"Frb8gOrQHV8pqqBR1MQwd5iyEYpyUe6Eb822qtDz/SR1BPB8hZlnAf+YLg4bdepagoBWIpTAW6z57Nsq",
+	"DWcb1G50Qy6t0gxVbN7MqNncaiWL3guZYi1mlGk5WDt93yXrhz02ViKGbcwrMLdc/HihttDXVEULz304",
+	"C70J+/tVjFteJeh9KRn5O4rMzrxs6isSmsDpscD4pyLooFJ2GSUufaXYYx8CLik13D6J/G9lpSHLtKrW",
+	"aX4U2rwU6RtUo9FGbGhkMhbMiFgbX9YhS7liucVo8oNP0jqqLPBRKMus0xmmKkjMW4hFtVs/k5aNtRKb",
+	"bJf+4AtEK41JHFfaJEVti6LouBpJQ7Gz5H0o0zILYEM1DVxlqsdSWTYRaVG8z69fOivSUVGuMtVjkEp1",
+	"7l57q70NxmGYuPplqsc6d0yoJNNS+RY4i5mcJM/sUWAZ0tXt3MM0D0ywVuPcBgnMn0EQjO5H1WO6OGOc",
+	"pHQrTb/VXu9uNqxlWQVqA1pNuOPLDInzCHvHF82gkdF9O/UueQUNiXWwhywE284F2fp6Jc1XyyOL/2Ho",
+	"AlfJljZFjt4mw078xPk9ly74qEikg+bIrwLS2aLBeGiU4bn0cSZUfz9qYPghY8nrl9QeHgvQYOdOSEkS",
+	"ZrG+Q8n9F313aDa5fV5M86zNi+9AfvNmqZKY/kRty1/ejbBKtOJ93I4X3cC/Dg7iLYvNTKQqus73oO3m",
+	"h/y+6JzaRRKBt31dDd+p9Z7QZz3LEqy0lMJr3W7L9vCrKsCD5cCK2AhK1rc+xMEX63p7MGBz3ZpDZbxq",
+	"12PGLdvimdy63Jl7+//gQv5rodBbxAwkBsQwDyQ5FIm5+M51Kp1wLHFC6veyQidLUONmk6DKiZoiBsTV",
+	"3EE9cHTrW5uLohzOyNfHW8Q8mpVOhgwVuUl7r3oT57JXW1upjnk60da9+tv237Y9zkCG6/8bADJO0AXw",
+	"aAEA",
+}
+
+// GetSwagger returns the content of the embedded swagger specification file
+// or error if failed to decode
+func decodeSpec() ([]byte, error) {
+	zipped, err := base64.StdEncoding.DecodeString(strings.Join(swaggerSpec, ""))
+	if err != nil {
+		return nil, fmt.Errorf("error base64 decoding spec: %w", err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(zipped))
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing spec: %w", err)
+	}
+	var buf bytes.Buffer
+	_, err = buf.ReadFrom(zr)
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing spec: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+var rawSpec = decodeSpecCached()
+
+// a naive cached of a decoded swagger spec
+func decodeSpecCached() func() ([]byte, error) {
+	data, err := decodeSpec()
+	return func() ([]byte, error) {
+		return data, err
+	}
+}
+
+// Constructs a synthetic filesystem for resolving external references when loading openapi specifications.
+func PathToRawSpec(pathToFile string) map[string]func() ([]byte, error) {
+	res := make(map[string]func() ([]byte, error))
+	if len(pathToFile) > 0 {
+		res[pathToFile] = rawSpec
+	}
+
+	return res
+}
+
+// GetSwagger returns the Swagger specification corresponding to the generated code
+// in this file. The external references of Swagger specification are resolved.
+// The logic of resolving external references is tightly connected to "import-mapping" feature.
+// Externally referenced files must be embedded in the corresponding golang packages.
+// Urls can be supported but this task was out of the scope.
+func GetSwagger() (swagger *openapi3.T, err error) {
+	resolvePath := PathToRawSpec("")
+
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	loader.ReadFromURIFunc = func(loader *openapi3.Loader, url *url.URL) ([]byte, error) {
+		pathToFile := url.String()
+		pathToFile = path.Clean(pathToFile)
+		getSpec, ok := resolvePath[pathToFile]
+		if !ok {
+			err1 := fmt.Errorf("path not found: %s", pathToFile)
+			return nil, err1
+		}
+		return getSpec()
+	}
+	var specData []byte
+	specData, err = rawSpec()
+	if err != nil {
+		return
+	}
+	swagger, err = loader.LoadFromData(specData)
+	if err != nil {
+		return
+	}
+	return
+}
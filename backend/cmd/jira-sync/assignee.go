@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// assigneeLookup is a cached user search outcome; err is kept so a failed or
+// ambiguous name is reported once per push rather than re-queried per issue.
+type assigneeLookup struct {
+	accountID string
+	err       error
+}
+
+// assigneeField returns the assignee value push sends for issue, and false
+// when the assignee should be left alone. unassign: true clears the assignee
+// and wins over any assignee fields, which pull leaves in place.
+func assigneeField(ctx context.Context, client *jiraClient, issue issueRecord) (interface{}, bool) {
+	if issue.Unassign {
+		if strings.TrimSpace(issue.AssigneeAccountID) != "" || strings.TrimSpace(issue.AssigneeEmail) != "" {
+			fmt.Printf("Warning: %s sets both unassign and an assignee; unassigning.\n", issueLabel(issue))
+		}
+		return map[string]interface{}{"accountId": nil}, true
+	}
+	if id := resolveAssignee(ctx, client, issue); id != "" {
+		return map[string]string{"accountId": id}, true
+	}
+	return nil, false
+}
+
+// issueLabel names issue in warnings, including new issues without a key.
+func issueLabel(issue issueRecord) string {
+	if issue.Key != "" {
+		return issue.Key
+	}
+	return fmt.Sprintf("new issue %q", strings.TrimSpace(issue.Summary))
+}
+
+// resolveAssignee picks the accountId to assign issue to, or "" to leave the
+// assignee alone. assigneeEmail wins because pull never writes it, so it is
+// always a deliberate edit; otherwise assigneeAccountId is used as is, and
+// assigneeDisplayName is looked up only when there is no accountId.
+func resolveAssignee(ctx context.Context, client *jiraClient, issue issueRecord) string {
+	label := issueLabel(issue)
+
+	if email := strings.TrimSpace(issue.AssigneeEmail); email != "" {
+		id, err := client.findAccountID(ctx, email, true)
+		if err != nil {
+			fmt.Printf("Warning: cannot assign %s to %s: %v; leaving assignee unchanged.\n", label, email, err)
+			return ""
+		}
+		return id
+	}
+	if id := strings.TrimSpace(issue.AssigneeAccountID); id != "" {
+		return id
+	}
+	if name := strings.TrimSpace(issue.AssigneeDisplayName); name != "" {
+		id, err := client.findAccountID(ctx, name, false)
+		if err != nil {
+			fmt.Printf("Warning: cannot assign %s to %q: %v; leaving assignee unchanged.\n", label, name, err)
+			return ""
+		}
+		return id
+	}
+	return ""
+}
+
+// findAccountID looks up a single Jira user by email or display name.
+func (c *jiraClient) findAccountID(ctx context.Context, query string, byEmail bool) (string, error) {
+	cacheKey := "name:" + strings.ToLower(query)
+	if byEmail {
+		cacheKey = "email:" + strings.ToLower(query)
+	}
+
+	c.assigneeMu.Lock()
+	cached, ok := c.assigneeCache[cacheKey]
+	c.assigneeMu.Unlock()
+	if ok {
+		return cached.accountID, cached.err
+	}
+
+	users, err := c.searchUsers(ctx, query)
+	if err != nil {
+		// Not cached: the next issue may succeed if this was transient.
+		return "", err
+	}
+
+	var matches []jiraUser
+	for _, user := range users {
+		if !user.Active {
+			continue
+		}
+		if byEmail {
+			// Jira hides most addresses, so trust its match unless the
+			// address is visible and different.
+			if user.EmailAddress == "" || strings.EqualFold(user.EmailAddress, query) {
+				matches = append(matches, user)
+			}
+		} else if strings.EqualFold(user.DisplayName, query) {
+			matches = append(matches, user)
+		}
+	}
+
+	var lookup assigneeLookup
+	switch len(matches) {
+	case 0:
+		lookup.err = errors.New("no matching active user")
+	case 1:
+		lookup.accountID = matches[0].AccountID
+	default:
+		names := make([]string, len(matches))
+		for i, user := range matches {
+			names[i] = user.DisplayName + " (" + user.AccountID + ")"
+		}
+		lookup.err = fmt.Errorf("ambiguous, matches %s", strings.Join(names, ", "))
+	}
+
+	c.assigneeMu.Lock()
+	if c.assigneeCache == nil {
+		c.assigneeCache = make(map[string]assigneeLookup)
+	}
+	c.assigneeCache[cacheKey] = lookup
+	c.assigneeMu.Unlock()
+	return lookup.accountID, lookup.err
+}
+
+type jiraUser struct {
+	AccountID    string `json:"accountId"`
+	DisplayName  string `json:"displayName"`
+	EmailAddress string `json:"emailAddress"`
+	Active       bool   `json:"active"`
+}
+
+func (c *jiraClient) searchUsers(ctx context.Context, query string) ([]jiraUser, error) {
+	params := url.Values{}
+	params.Set("query", query)
+	req, err := c.newRequest(ctx, http.MethodGet, c.apiPrefix+"/user/search", params, nil)
+	if err != nil {
+		return nil, err
+	}
+	var users []jiraUser
+	if err := c.do(req, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type jiraAttachment struct {
+	ID       string `json:"id"`
+	Filename string `json:"filename"`
+	Size     int64  `json:"size"`
+	Content  string `json:"content"`
+}
+
+func attachmentNames(attachments []jiraAttachment) []string {
+	if len(attachments) == 0 {
+		return nil
+	}
+	names := make([]string, len(attachments))
+	for i, attachment := range attachments {
+		names[i] = attachment.Filename
+	}
+	return names
+}
+
+// attachmentDir is where the files of the issue key are kept.
+func attachmentDir(cfg config, key string) string {
+	return filepath.Join(cfg.AttachmentsDir, key)
+}
+
+// safeFilename returns name when it can be used as is inside an issue's
+// attachment directory.
+func safeFilename(name string) (string, bool) {
+	if name == "" || name == "." || name == ".." || name != filepath.Base(name) || strings.ContainsAny(name, `/\`) {
+		return "", false
+	}
+	return name, true
+}
+
+// downloadAttachments saves the attachments of issue to its directory.
+// Files already there with the same size are skipped, as are attachments
+// larger than cfg.MaxAttachmentSize. The content URLs come from Jira, so
+// credentials are only sent to those on the configured site.
+func downloadAttachments(ctx context.Context, client *jiraClient, cfg config, issue jiraIssue) error {
+	for _, attachment := range issue.Fields.Attachments {
+		name, ok := safeFilename(attachment.Filename)
+		if !ok {
+			fmt.Printf("Warning: skipping attachment %q on %s: unusable file name.\n", attachment.Filename, issue.Key)
+			continue
+		}
+		if !strings.HasPrefix(attachment.Content, client.baseURL+"/") {
+			fmt.Printf("Warning: skipping attachment %q on %s: content is not on %s.\n", name, issue.Key, client.baseURL)
+			continue
+		}
+		if attachment.Size > cfg.MaxAttachmentSize {
+			fmt.Printf("Warning: skipping attachment %q on %s: %d bytes is over the limit.\n", name, issue.Key, attachment.Size)
+			continue
+		}
+		path := filepath.Join(attachmentDir(cfg, issue.Key), name)
+		if info, err := os.Stat(path); err == nil && info.Size() == attachment.Size {
+			continue
+		}
+		if err := client.downloadAttachment(ctx, attachment, path, cfg.MaxAttachmentSize); err != nil {
+			return fmt.Errorf("download attachment %q of %s: %w", name, issue.Key, err)
+		}
+	}
+	return nil
+}
+
+// downloadAttachment streams the attachment's content to path, failing
+// rather than writing more than limit bytes.
+func (c *jiraClient) downloadAttachment(ctx context.Context, attachment jiraAttachment, path string, limit int64) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, attachment.Content, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", c.authHeader)
+	resp, err := c.send(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	n, err := io.Copy(tmp, io.LimitReader(resp.Body, limit+1))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if n > limit {
+		return fmt.Errorf("larger than the %d byte limit", limit)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// pushAttachments uploads the files in the issue's attachment directory that
+// are neither in the attachments the last pull recorded nor on Jira now, so
+// files removed in Jira are not uploaded again. Files over
+// cfg.MaxAttachmentSize are skipped with a warning.
+func pushAttachments(ctx context.Context, client *jiraClient, cfg config, key string, issue issueRecord) error {
+	entries, err := os.ReadDir(attachmentDir(cfg, key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read attachments: %w", err)
+	}
+
+	known := make(map[string]bool, len(issue.Attachments))
+	for _, name := range issue.Attachments {
+		known[name] = true
+	}
+	var candidates []os.DirEntry
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && !known[entry.Name()] && !strings.HasPrefix(entry.Name(), ".") {
+			candidates = append(candidates, entry)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	current, err := client.issueAttachments(ctx, key)
+	if err != nil {
+		return fmt.Errorf("list attachments: %w", err)
+	}
+	for _, attachment := range current {
+		known[attachment.Filename] = true
+	}
+	for _, entry := range candidates {
+		name := entry.Name()
+		if known[name] {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if info.Size() > cfg.MaxAttachmentSize {
+			fmt.Printf("Warning: not attaching %q to %s: %d bytes is over the limit.\n", name, key, info.Size())
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(attachmentDir(cfg, key), name))
+		if err != nil {
+			return err
+		}
+		if err := client.uploadAttachment(ctx, key, name, content); err != nil {
+			return fmt.Errorf("attach %q: %w", name, err)
+		}
+		fmt.Printf("Attached %q to %s\n", name, key)
+	}
+	return nil
+}
+
+func (c *jiraClient) issueAttachments(ctx context.Context, key string) ([]jiraAttachment, error) {
+	query := url.Values{}
+	query.Set("fields", "attachment")
+	req, err := c.newRequest(ctx, http.MethodGet, c.apiPrefix+"/issue/"+key, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	var payload struct {
+		Fields struct {
+			Attachments []jiraAttachment `json:"attachment"`
+		} `json:"fields"`
+	}
+	if err := c.do(req, &payload); err != nil {
+		return nil, err
+	}
+	return payload.Fields.Attachments, nil
+}
+
+// uploadAttachment attaches content to the issue as name. The body is built
+// in memory so a rate-limited request can be resent.
+func (c *jiraClient) uploadAttachment(ctx context.Context, key, name string, content []byte) error {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", name)
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write(content); err != nil {
+		return err
+	}
+	if err := form.Close(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+c.apiPrefix+"/issue/"+key+"/attachments", bytes.NewReader(body.Bytes()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", c.authHeader)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	// Jira rejects uploads without it as a possible XSRF attack.
+	req.Header.Set("X-Atlassian-Token", "no-check")
+	return c.do(req, nil)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileConfig is the optional jira-sync.yaml. It holds the non-secret settings
+// so they can be checked in per project; the API token is only read from the
+// environment. Every value is overridden by the matching JIRA_* variable.
+type fileConfig struct {
+	BaseURL          string            `yaml:"baseURL"`
+	Email            string            `yaml:"email"`
+	ProjectKey       string            `yaml:"projectKey"`
+	DefaultIssueType string            `yaml:"defaultIssueType"`
+	JQL              string            `yaml:"jql"`
+	YAMLPath         string            `yaml:"yamlPath"`
+	OutputFormat     string            `yaml:"outputFormat"`
+	MarkdownDir      string            `yaml:"markdownDir"`
+	MaxResults       int               `yaml:"maxResults"`
+	PushWorkers      int               `yaml:"pushWorkers"`
+	EpicLinkField    string            `yaml:"epicLinkField"`
+	Fields           []string          `yaml:"fields"`
+	Timeout          string            `yaml:"timeout"`
+	APIVersion       int               `yaml:"apiVersion"`
+	PruneLinks       bool              `yaml:"pruneLinks"`
+	AttachmentsDir   string            `yaml:"attachmentsDir"`
+	MaxAttachmentMB  int               `yaml:"maxAttachmentMB"`
+	CustomFields     map[string]string `yaml:"customFields"` // alias -> customfield_ ID
+}
+
+// loadConfigFile reads the config file at path. An empty path means no file
+// was given and yields an empty config.
+func loadConfigFile(path string) (fileConfig, error) {
+	var cfg fileConfig
+	if path == "" {
+		return cfg, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("read config file: %w", err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	// Reject unknown keys so a typo (or an apiToken) is not silently ignored.
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return cfg, fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// setting returns the environment variable name when set, otherwise the
+// value from the config file.
+func setting(name, fromFile string) string {
+	if value := strings.TrimSpace(os.Getenv(name)); value != "" {
+		return value
+	}
+	return strings.TrimSpace(fromFile)
+}
+
+// positiveSetting is setting for positive integers; zero means unset.
+func positiveSetting(name string, fromFile, fallback int) (int, error) {
+	if raw := strings.TrimSpace(os.Getenv(name)); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			return 0, fmt.Errorf("invalid %s: %s", name, raw)
+		}
+		return parsed, nil
+	}
+	switch {
+	case fromFile < 0:
+		return 0, fmt.Errorf("invalid %s in config file: %d", name, fromFile)
+	case fromFile > 0:
+		return fromFile, nil
+	}
+	return fallback, nil
+}
+
+// boolSetting is setting for booleans.
+func boolSetting(name string, fromFile bool) (bool, error) {
+	if raw := strings.TrimSpace(os.Getenv(name)); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return false, fmt.Errorf("invalid %s: %s", name, raw)
+		}
+		return parsed, nil
+	}
+	return fromFile, nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// jiraTimeLayout is how Jira formats timestamps such as updated.
+const jiraTimeLayout = "2006-01-02T15:04:05.000-0700"
+
+// errConflict marks an issue push skipped because Jira changed it after the
+// last pull.
+var errConflict = errors.New("changed in Jira since the last pull")
+
+// changedSince reports whether current, the issue's updated timestamp in Jira,
+// is newer than recorded, the one pull stored. Timestamps that do not parse
+// are compared as strings.
+func changedSince(recorded, current string) bool {
+	if recorded == "" || current == "" || recorded == current {
+		return false
+	}
+	recordedAt, err1 := time.Parse(jiraTimeLayout, recorded)
+	currentAt, err2 := time.Parse(jiraTimeLayout, current)
+	if err1 != nil || err2 != nil {
+		return true
+	}
+	return currentAt.After(recordedAt)
+}
+
+// checkConflict returns errConflict when the issue has a recorded updated
+// timestamp and Jira's is newer. cfg.Force skips the check.
+func checkConflict(ctx context.Context, client *jiraClient, cfg config, issue issueRecord) error {
+	recorded := strings.TrimSpace(issue.Updated)
+	if cfg.Force || recorded == "" {
+		return nil
+	}
+	current, err := client.issueUpdated(ctx, strings.TrimSpace(issue.Key))
+	if err != nil {
+		return fmt.Errorf("check for conflicts: %w", err)
+	}
+	if changedSince(recorded, current) {
+		return fmt.Errorf("%w (pulled %s, now %s)", errConflict, recorded, current)
+	}
+	return nil
+}
+
+func (c *jiraClient) issueUpdated(ctx context.Context, key string) (string, error) {
+	query := url.Values{}
+	query.Set("fields", "updated")
+	req, err := c.newRequest(ctx, http.MethodGet, c.apiPrefix+"/issue/"+key, query, nil)
+	if err != nil {
+		return "", err
+	}
+	var payload struct {
+		Fields struct {
+			Updated string `json:"updated"`
+		} `json:"fields"`
+	}
+	if err := c.do(req, &payload); err != nil {
+		return "", err
+	}
+	return payload.Fields.Updated, nil
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const customFieldPrefix = "customfield_"
+
+// UnmarshalJSON decodes the fields push and pull know about and keeps every
+// non-null customfield_* value as is in Custom.
+func (f *jiraFields) UnmarshalJSON(data []byte) error {
+	type known jiraFields
+	if err := json.Unmarshal(data, (*known)(f)); err != nil {
+		return err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+	for name, raw := range all {
+		if !strings.HasPrefix(name, customFieldPrefix) || string(raw) == "null" {
+			continue
+		}
+		if f.Custom == nil {
+			f.Custom = make(map[string]json.RawMessage)
+		}
+		f.Custom[name] = raw
+	}
+	return nil
+}
+
+// parseCustomFieldAliases reads JIRA_CUSTOM_FIELDS, a comma-separated list of
+// alias=customfield_NNNNN pairs.
+func parseCustomFieldAliases(raw string) (map[string]string, error) {
+	aliases := make(map[string]string)
+	for _, pair := range strings.Split(raw, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		alias, id, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid JIRA_CUSTOM_FIELDS entry %q (want alias=customfield_NNNNN)", strings.TrimSpace(pair))
+		}
+		aliases[strings.TrimSpace(alias)] = strings.TrimSpace(id)
+	}
+	return aliases, nil
+}
+
+// validateCustomFieldAliases checks that every alias names a custom field
+// and that no two aliases share one.
+func validateCustomFieldAliases(aliases map[string]string) error {
+	seen := make(map[string]string, len(aliases))
+	names := make([]string, 0, len(aliases))
+	for alias := range aliases {
+		names = append(names, alias)
+	}
+	sort.Strings(names)
+	for _, alias := range names {
+		id := aliases[alias]
+		switch {
+		case alias == "" || strings.HasPrefix(alias, customFieldPrefix):
+			return fmt.Errorf("invalid custom field alias %q", alias)
+		case !strings.HasPrefix(id, customFieldPrefix):
+			return fmt.Errorf("custom field alias %s: %q is not a customfield_ ID", alias, id)
+		case seen[id] != "":
+			return fmt.Errorf("custom field %s has two aliases, %s and %s", id, seen[id], alias)
+		}
+		seen[id] = alias
+	}
+	return nil
+}
+
+// customFieldValues turns the custom field values of an issue into the
+// record's customFields, named by alias where one is configured.
+func customFieldValues(custom map[string]json.RawMessage, aliases map[string]string) (map[string]interface{}, error) {
+	if len(custom) == 0 {
+		return nil, nil
+	}
+	names := make(map[string]string, len(aliases))
+	for alias, id := range aliases {
+		names[id] = alias
+	}
+	values := make(map[string]interface{}, len(custom))
+	for id, raw := range custom {
+		var value interface{}
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", id, err)
+		}
+		name := id
+		if alias, ok := names[id]; ok {
+			name = alias
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+// setCustomFields copies the record's custom field values into fields under
+// their field IDs, unchanged.
+func setCustomFields(fields map[string]interface{}, issue issueRecord, aliases map[string]string) error {
+	for name, value := range issue.CustomFields {
+		id := name
+		if !strings.HasPrefix(name, customFieldPrefix) {
+			var ok bool
+			if id, ok = aliases[name]; !ok {
+				return fmt.Errorf("unknown custom field %q: use a customfield_ ID or configure an alias", name)
+			}
+		}
+		fields[id] = value
+	}
+	return nil
+}
+
+// customFieldChanges lists the custom fields whose local value differs from
+// Jira's. Values are compared as JSON so YAML and JSON numbers match.
+func customFieldChanges(remote, local map[string]interface{}) []string {
+	names := make([]string, 0, len(local))
+	for name := range local {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var changes []string
+	for _, name := range names {
+		from, _ := json.Marshal(remote[name])
+		to, err := json.Marshal(local[name])
+		if err != nil {
+			to = []byte(fmt.Sprint(local[name]))
+		}
+		if string(from) != string(to) {
+			changes = append(changes, fmt.Sprintf("%s: %s -> %s", name, abbreviate(string(from)), abbreviate(string(to))))
+		}
+	}
+	return changes
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// runDiff compares the local YAML against the issues Jira currently returns
+// for the configured JQL and prints what a push would change. It mutates
+// nothing and fails when changes are pending, so it can gate a push.
+func runDiff(ctx context.Context, client *jiraClient, cfg config) error {
+	data, err := readIssues(cfg)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Fetching issues from Jira...")
+	remote := make(map[string]issueRecord)
+	err = forEachIssuePage(ctx, client, cfg.JQL, cfg.MaxResults, func(issues []jiraIssue, total int) error {
+		for _, issue := range issues {
+			record, err := issueToRecord(issue, cfg)
+			if err != nil {
+				return err
+			}
+			remote[issue.Key] = record
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	pending := 0
+	for _, item := range flattenIssues(data.Issues) {
+		local := item.record
+		key := strings.TrimSpace(local.Key)
+		switch {
+		case key == "":
+			if !local.Delete {
+				fmt.Printf("+ new: %q\n", strings.TrimSpace(local.Summary))
+				pending++
+			}
+		case local.Delete:
+			fmt.Printf("- %s: delete\n", key)
+			pending++
+		default:
+			current, ok := remote[key]
+			if !ok {
+				fmt.Printf("? %s: not returned by the JQL query; push would still update it\n", key)
+				pending++
+				continue
+			}
+			if changes := diffIssue(current, local, cfg.PruneLinks); len(changes) > 0 {
+				fmt.Printf("~ %s\n", key)
+				if changedSince(strings.TrimSpace(local.Updated), current.Updated) {
+					fmt.Printf("    conflict: changed in Jira since the last pull; push skips it without -force\n")
+				}
+				for _, change := range changes {
+					fmt.Printf("    %s\n", change)
+				}
+				pending++
+			}
+		}
+	}
+
+	if pending == 0 {
+		fmt.Println("No differences.")
+		return nil
+	}
+	return fmt.Errorf("%d issue(s) differ from Jira", pending)
+}
+
+// diffIssue lists the fields push would change on remote. Fields the YAML
+// leaves empty are not pushed, so they never count as a change; links it
+// lacks only do when pruneLinks is set.
+func diffIssue(remote, local issueRecord, pruneLinks bool) []string {
+	var changes []string
+	changed := func(field, from, to string) {
+		changes = append(changes, fmt.Sprintf("%s: %q -> %q", field, from, to))
+	}
+
+	if summary := strings.TrimSpace(local.Summary); summary != remote.Summary {
+		changed("summary", remote.Summary, summary)
+	}
+	if description := strings.TrimSpace(local.Description); description != remote.Description {
+		if from, to := abbreviate(remote.Description), abbreviate(description); from != to {
+			changed("description", from, to)
+		} else {
+			changes = append(changes, "description: changed")
+		}
+	}
+	if labels, ok := pushedLabels(remote.Labels, local); ok && !sameLabels(labels, remote.Labels) {
+		changes = append(changes, fmt.Sprintf("labels: %v -> %v", sortedLabels(remote.Labels), sortedLabels(labels)))
+	}
+	if status := strings.TrimSpace(local.Status); status != "" && status != remote.Status {
+		changed("status", remote.Status, status)
+	}
+	if priority := strings.TrimSpace(local.Priority); priority != "" && priority != remote.Priority {
+		changed("priority", remote.Priority, priority)
+	}
+	if local.Components != nil && !sameLabels(local.Components, remote.Components) {
+		changes = append(changes, fmt.Sprintf("components: %v -> %v", sortedLabels(remote.Components), sortedLabels(local.Components)))
+	}
+	if local.FixVersions != nil && !sameLabels(local.FixVersions, remote.FixVersions) {
+		changes = append(changes, fmt.Sprintf("fixVersions: %v -> %v", sortedLabels(remote.FixVersions), sortedLabels(local.FixVersions)))
+	}
+	if due := strings.TrimSpace(local.DueDate); due != "" && due != remote.DueDate {
+		changed("dueDate", remote.DueDate, due)
+	}
+	changes = append(changes, customFieldChanges(remote.CustomFields, local.CustomFields)...)
+	if count := newWorklogs(local); count > 0 {
+		changes = append(changes, fmt.Sprintf("worklogs: %d to add", count))
+	}
+	add, remove := linkChanges(remote.Links, local.Links, pruneLinks)
+	for _, link := range add {
+		changes = append(changes, fmt.Sprintf("link: + %s", link))
+	}
+	for _, link := range remove {
+		changes = append(changes, fmt.Sprintf("link: - %s", link))
+	}
+	if local.Unassign {
+		if remote.AssigneeAccountID != "" {
+			changed("assignee", describeAssignee(remote), "")
+		}
+	} else if assignee := strings.TrimSpace(local.AssigneeAccountID); assignee != "" && assignee != remote.AssigneeAccountID {
+		changed("assignee", describeAssignee(remote), describeAssignee(local))
+	}
+	return changes
+}
+
+// pushedLabels returns the label set the issue would have after a push, and
+// false when push leaves labels untouched.
+func pushedLabels(current []string, local issueRecord) ([]string, bool) {
+	if len(labelOperations(local)) > 0 {
+		merged := local
+		merged.Labels = current
+		return createLabels(merged), true
+	}
+	if local.Labels != nil {
+		return local.Labels, true
+	}
+	return nil, false
+}
+
+func sameLabels(a, b []string) bool {
+	a, b = sortedLabels(a), sortedLabels(b)
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedLabels(labels []string) []string {
+	sorted := append([]string{}, labels...)
+	sort.Strings(sorted)
+	return sorted
+}
+
+func describeAssignee(record issueRecord) string {
+	if record.AssigneeDisplayName != "" {
+		return record.AssigneeDisplayName + " (" + record.AssigneeAccountID + ")"
+	}
+	return record.AssigneeAccountID
+}
+
+// abbreviate shortens long text to its first line for diff output.
+func abbreviate(text string) string {
+	const limit = 60
+	line, _, multiline := strings.Cut(text, "\n")
+	if len(line) > limit {
+		return line[:limit] + "..."
+	}
+	if multiline {
+		return line + " ..."
+	}
+	return line
+}
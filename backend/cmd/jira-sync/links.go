@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// linkRecord is an issue link seen from the issue that lists it: "outward"
+// reads "<this issue> <outward description> <key>" (e.g. blocks PROJ-2),
+// "inward" reads "<this issue> <inward description> <key>" (e.g. is blocked
+// by PROJ-2).
+type linkRecord struct {
+	Type      string `yaml:"type"`
+	Direction string `yaml:"direction"`
+	Key       string `yaml:"key"`
+}
+
+type jiraIssueLink struct {
+	ID   string `json:"id"`
+	Type struct {
+		Name string `json:"name"`
+	} `json:"type"`
+	InwardIssue *struct {
+		Key string `json:"key"`
+	} `json:"inwardIssue"`
+	OutwardIssue *struct {
+		Key string `json:"key"`
+	} `json:"outwardIssue"`
+}
+
+// record converts the link as listed on an issue.
+func (l jiraIssueLink) record() (linkRecord, bool) {
+	switch {
+	case l.OutwardIssue != nil:
+		return linkRecord{Type: l.Type.Name, Direction: "outward", Key: l.OutwardIssue.Key}, true
+	case l.InwardIssue != nil:
+		return linkRecord{Type: l.Type.Name, Direction: "inward", Key: l.InwardIssue.Key}, true
+	}
+	return linkRecord{}, false
+}
+
+func linkRecords(links []jiraIssueLink) []linkRecord {
+	var records []linkRecord
+	for _, link := range links {
+		if record, ok := link.record(); ok {
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+// identity compares links regardless of how the YAML spells them.
+func (l linkRecord) identity() string {
+	return normalizeIssueTypeName(l.Type) + "|" + strings.ToLower(strings.TrimSpace(l.Direction)) + "|" + strings.ToUpper(strings.TrimSpace(l.Key))
+}
+
+func (l linkRecord) String() string {
+	return fmt.Sprintf("%s %s %s", l.Type, l.Direction, l.Key)
+}
+
+func (l linkRecord) validate() error {
+	if strings.TrimSpace(l.Type) == "" || strings.TrimSpace(l.Key) == "" {
+		return fmt.Errorf("link %q: type and key are required", l)
+	}
+	switch strings.ToLower(strings.TrimSpace(l.Direction)) {
+	case "inward", "outward":
+		return nil
+	}
+	return fmt.Errorf("link %q: direction must be inward or outward", l)
+}
+
+// linkChanges compares the links in the YAML with those on Jira. Links the
+// YAML lacks are only returned for removal when prune is set.
+func linkChanges(remote, local []linkRecord, prune bool) (add, remove []linkRecord) {
+	remoteIDs := make(map[string]bool, len(remote))
+	for _, link := range remote {
+		remoteIDs[link.identity()] = true
+	}
+	localIDs := make(map[string]bool, len(local))
+	for _, link := range local {
+		if id := link.identity(); !localIDs[id] {
+			localIDs[id] = true
+			if !remoteIDs[id] {
+				add = append(add, link)
+			}
+		}
+	}
+	if prune {
+		for _, link := range remote {
+			if !localIDs[link.identity()] {
+				remove = append(remove, link)
+			}
+		}
+	}
+	return add, remove
+}
+
+// pushLinks creates the links of issue that Jira does not have yet and, with
+// JIRA_PRUNE_LINKS, deletes the ones the YAML no longer lists. The issue's
+// current links are only fetched when there is something to compare.
+func pushLinks(ctx context.Context, client *jiraClient, cfg config, key string, issue issueRecord) error {
+	if len(issue.Links) == 0 && !cfg.PruneLinks {
+		return nil
+	}
+	for _, link := range issue.Links {
+		if err := link.validate(); err != nil {
+			return err
+		}
+	}
+
+	current, err := client.issueLinks(ctx, key)
+	if err != nil {
+		return fmt.Errorf("list links: %w", err)
+	}
+	remote := make([]linkRecord, 0, len(current))
+	linkIDs := make(map[string]string, len(current))
+	for _, link := range current {
+		if record, ok := link.record(); ok {
+			remote = append(remote, record)
+			linkIDs[record.identity()] = link.ID
+		}
+	}
+
+	add, remove := linkChanges(remote, issue.Links, cfg.PruneLinks)
+	for _, link := range add {
+		if err := client.createLink(ctx, key, link); err != nil {
+			return fmt.Errorf("link %q: %w", link, err)
+		}
+		fmt.Printf("Linked %s: %s\n", key, link)
+	}
+	for _, link := range remove {
+		if err := client.deleteLink(ctx, linkIDs[link.identity()]); err != nil {
+			return fmt.Errorf("unlink %q: %w", link, err)
+		}
+		fmt.Printf("Unlinked %s: %s\n", key, link)
+	}
+	return nil
+}
+
+func (c *jiraClient) issueLinks(ctx context.Context, key string) ([]jiraIssueLink, error) {
+	query := url.Values{}
+	query.Set("fields", "issuelinks")
+	req, err := c.newRequest(ctx, http.MethodGet, c.apiPrefix+"/issue/"+key, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	var payload struct {
+		Fields struct {
+			IssueLinks []jiraIssueLink `json:"issuelinks"`
+		} `json:"fields"`
+	}
+	if err := c.do(req, &payload); err != nil {
+		return nil, err
+	}
+	return payload.Fields.IssueLinks, nil
+}
+
+// createLink links key as described by link. Jira's request names the issue
+// the outward description applies to "inwardIssue", so an outward link from
+// key puts key there.
+func (c *jiraClient) createLink(ctx context.Context, key string, link linkRecord) error {
+	typeID, err := c.linkTypeID(ctx, link.Type)
+	if err != nil {
+		return err
+	}
+	from, to := key, strings.TrimSpace(link.Key)
+	if strings.EqualFold(strings.TrimSpace(link.Direction), "inward") {
+		from, to = to, from
+	}
+	body := map[string]interface{}{
+		"type":         map[string]string{"id": typeID},
+		"inwardIssue":  map[string]string{"key": from},
+		"outwardIssue": map[string]string{"key": to},
+	}
+	req, err := c.newRequest(ctx, http.MethodPost, c.apiPrefix+"/issueLink", nil, body)
+	if err != nil {
+		return err
+	}
+	return c.do(req, nil)
+}
+
+func (c *jiraClient) deleteLink(ctx context.Context, id string) error {
+	req, err := c.newRequest(ctx, http.MethodDelete, c.apiPrefix+"/issueLink/"+id, nil, nil)
+	if err != nil {
+		return err
+	}
+	return c.do(req, nil)
+}
+
+// linkTypeID resolves a link type name to its ID. The types are fetched once
+// per run and cached like issue types.
+func (c *jiraClient) linkTypeID(ctx context.Context, name string) (string, error) {
+	normalized := normalizeIssueTypeName(name)
+
+	c.linkTypeMu.Lock()
+	defer c.linkTypeMu.Unlock()
+	if c.linkTypeCache == nil {
+		req, err := c.newRequest(ctx, http.MethodGet, c.apiPrefix+"/issueLinkType", nil, nil)
+		if err != nil {
+			return "", err
+		}
+		var payload struct {
+			IssueLinkTypes []struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+			} `json:"issueLinkTypes"`
+		}
+		if err := c.do(req, &payload); err != nil {
+			return "", fmt.Errorf("fetch link types: %w", err)
+		}
+		c.linkTypeCache = make(map[string]string, len(payload.IssueLinkTypes))
+		for _, item := range payload.IssueLinkTypes {
+			c.linkTypeCache[normalizeIssueTypeName(item.Name)] = item.ID
+		}
+	}
+
+	if id, ok := c.linkTypeCache[normalized]; ok {
+		return id, nil
+	}
+	return "", errors.New("unknown link type " + name)
+}
@@ -6,18 +6,24 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
+	"messenger/backend/pkg/adf"
+
 	"github.com/joho/godotenv"
 	"golang.org/x/sync/errgroup"
 	"gopkg.in/yaml.v3"
@@ -26,42 +32,97 @@ import (
 const (
 	defaultIssueTypeValue = "Task"
 	defaultYAMLFile       = "jira-tasks.yaml"
+	defaultMarkdownDir    = "jira-tasks"
+	defaultAttachmentsDir = "jira-attachments"
+	defaultMaxAttachMB    = 10
 	defaultMaxResults     = 50
 	defaultPushWorkers    = 10
-	jiraAPIPrefix         = "/rest/api/3"
+	defaultAPIVersion     = 3
 )
 
+// baseSearchFields are always fetched because pull and push depend on them.
+var baseSearchFields = []string{"summary", "description", "labels", "issuetype", "status", "assignee", "priority", "parent", "worklog", "issuelinks", "updated", "attachment"}
+
 func main() {
-	ctx := context.Background()
+	// Ctrl-C cancels in-flight requests; push still records what it applied.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 	if err := run(ctx); err != nil {
+		stop()
+		if errors.Is(err, context.Canceled) {
+			fmt.Fprintln(os.Stderr, "Interrupted.")
+		} else if errors.Is(err, context.DeadlineExceeded) {
+			fmt.Fprintln(os.Stderr, "JIRA_TIMEOUT reached.")
+		}
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
 func run(ctx context.Context) error {
-	if len(os.Args) < 2 {
+	flags := flag.NewFlagSet("jira-sync", flag.ContinueOnError)
+	flags.Usage = printUsage
+	configPath := flags.String("config", "", "path to a jira-sync.yaml config file")
+	force := flags.Bool("force", false, "push over issues changed in Jira since the last pull")
+	attachments := flags.Bool("attachments", false, "download issue attachments on pull")
+	nested := flags.Bool("nested", false, "list subtasks under their parents on pull")
+	if err := flags.Parse(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if flags.NArg() < 1 {
 		printUsage()
 		return errors.New("missing command")
 	}
 
-	command := os.Args[1]
+	command := flags.Arg(0)
 
-	if command == "help" || command == "--help" || command == "-h" {
+	if command == "help" {
 		printUsage()
 		return nil
 	}
 
+	// Flags may also follow the command.
+	if err := flags.Parse(flags.Args()[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if flags.NArg() > 0 {
+		printUsage()
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(flags.Args(), " "))
+	}
+
 	if err := maybeLoadDotEnv(); err != nil {
 		return err
 	}
 
-	cfg, err := loadConfig()
+	file, err := loadConfigFile(*configPath)
 	if err != nil {
 		return err
 	}
 
-	client := newJiraClient(cfg)
+	cfg, err := loadConfig(file)
+	if err != nil {
+		return err
+	}
+	cfg.Force = *force
+	cfg.DownloadAttachments = *attachments
+	cfg.Nested = *nested
+	if cfg.Nested && cfg.OutputFormat == outputMarkdown {
+		return errors.New("-nested only applies to the yaml output format")
+	}
+
+	if cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+		defer cancel()
+	}
+
+	client := newJiraClient(cfg, nil)
 
 	switch command {
 	case "pull":
@@ -72,6 +133,8 @@ func run(ctx context.Context) error {
 		}
 		fmt.Println("Refreshing local YAML from Jira...")
 		return runPull(ctx, client, cfg)
+	case "diff":
+		return runDiff(ctx, client, cfg)
 	case "fields":
 		return runListFields(ctx, client)
 	default:
@@ -81,25 +144,44 @@ func run(ctx context.Context) error {
 }
 
 func printUsage() {
-	fmt.Println("Usage: go run ./backend/cmd/jira-sync <pull|push|fields>")
+	fmt.Println("Usage: go run ./backend/cmd/jira-sync [-config jira-sync.yaml] [-force] [-attachments] [-nested] <pull|push|diff|fields>")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  -config  YAML file with non-secret settings; JIRA_* environment variables override it")
+	fmt.Println("  -force   push issues even if they changed in Jira since the last pull")
+	fmt.Println("  -attachments  download issue attachments into JIRA_ATTACHMENTS_DIR on pull")
+	fmt.Println("  -nested  pull subtasks into a subtasks list under their parent; push reads either layout")
 	fmt.Println()
 	fmt.Println("Commands:")
 	fmt.Println("  pull   Fetch issues from Jira and write them to the YAML file")
 	fmt.Println("  push   Read the YAML file and update/create issues in Jira")
+	fmt.Println("  diff   Show how the YAML file differs from Jira; exits non-zero if a push would change anything")
 	fmt.Println("  fields List available Jira fields (helps locate the Epic Link custom field)")
 }
 
 type config struct {
-	BaseURL          string
-	Email            string
-	APIToken         string
-	ProjectKey       string
-	DefaultIssueType string
-	JQL              string
-	YAMLPath         string
-	MaxResults       int
-	PushWorkers      int
-	EpicLinkField    string
+	BaseURL             string
+	Email               string
+	APIToken            string
+	ProjectKey          string
+	DefaultIssueType    string
+	JQL                 string
+	YAMLPath            string
+	OutputFormat        string // outputYAML or outputMarkdown
+	MarkdownDir         string // where outputMarkdown keeps one <key>.md per issue
+	MaxResults          int
+	PushWorkers         int
+	EpicLinkField       string
+	Fields              []string
+	Timeout             time.Duration     // overall deadline for the run; zero means none
+	APIVersion          int               // 3 for Jira Cloud, 2 for Server/Data Center
+	PruneLinks          bool              // push deletes issue links the YAML no longer lists
+	Force               bool              // push overwrites issues changed in Jira since the last pull
+	AttachmentsDir      string            // holds a directory of attachments per issue key
+	MaxAttachmentSize   int64             // bytes; larger attachments are neither downloaded nor uploaded
+	DownloadAttachments bool              // pull downloads attachments into AttachmentsDir
+	Nested              bool              // pull lists subtasks under their parents
+	CustomFieldAliases  map[string]string // YAML name -> customfield_ ID
 }
 
 func maybeLoadDotEnv() error {
@@ -114,84 +196,178 @@ func maybeLoadDotEnv() error {
 	return nil
 }
 
-func loadConfig() (config, error) {
-	baseURL := strings.TrimSpace(os.Getenv("JIRA_BASE_URL"))
+// loadConfig builds the configuration from, in order of precedence, JIRA_*
+// environment variables (including those loaded from .env), the optional
+// config file, and built-in defaults. Every problem is reported at once, joined
+// into one error.
+func loadConfig(file fileConfig) (config, error) {
+	var errs []error
+
+	baseURL := strings.TrimSuffix(setting("JIRA_BASE_URL", file.BaseURL), "/")
 	if baseURL == "" {
-		return config{}, errors.New("JIRA_BASE_URL is required")
-	}
-	baseURL = strings.TrimSuffix(baseURL, "/")
-	if _, err := url.ParseRequestURI(baseURL); err != nil {
-		return config{}, fmt.Errorf("invalid JIRA_BASE_URL: %w", err)
+		errs = append(errs, errors.New("JIRA_BASE_URL (or baseURL in the config file) is required"))
+	} else if _, err := url.ParseRequestURI(baseURL); err != nil {
+		errs = append(errs, fmt.Errorf("invalid JIRA_BASE_URL: %w", err))
 	}
 
-	email := strings.TrimSpace(os.Getenv("JIRA_EMAIL"))
+	email := setting("JIRA_EMAIL", file.Email)
 	if email == "" {
-		return config{}, errors.New("JIRA_EMAIL is required")
+		errs = append(errs, errors.New("JIRA_EMAIL (or email in the config file) is required"))
 	}
 
 	token := strings.TrimSpace(os.Getenv("JIRA_API_TOKEN"))
 	if token == "" {
-		return config{}, errors.New("JIRA_API_TOKEN is required")
+		errs = append(errs, errors.New("JIRA_API_TOKEN is required"))
 	}
 
-	projectKey := strings.TrimSpace(os.Getenv("JIRA_PROJECT_KEY"))
+	projectKey := setting("JIRA_PROJECT_KEY", file.ProjectKey)
 	if projectKey == "" {
-		return config{}, errors.New("JIRA_PROJECT_KEY is required")
+		errs = append(errs, errors.New("JIRA_PROJECT_KEY (or projectKey in the config file) is required"))
 	}
 
-	defaultIssueType := strings.TrimSpace(os.Getenv("JIRA_DEFAULT_ISSUE_TYPE"))
+	defaultIssueType := setting("JIRA_DEFAULT_ISSUE_TYPE", file.DefaultIssueType)
 	if defaultIssueType == "" {
 		defaultIssueType = defaultIssueTypeValue
 	}
 
-	jql := strings.TrimSpace(os.Getenv("JIRA_JQL"))
+	jql := setting("JIRA_JQL", file.JQL)
 	if jql == "" {
 		jql = fmt.Sprintf("project = %s ORDER BY created DESC", projectKey)
 	}
 
-	yamlPath := strings.TrimSpace(os.Getenv("JIRA_YAML_PATH"))
+	yamlPath := setting("JIRA_YAML_PATH", file.YAMLPath)
 	if yamlPath == "" {
 		yamlPath = defaultYAMLFile
 	}
 	yamlPath, err := resolveYAMLPath(yamlPath)
 	if err != nil {
-		return config{}, err
+		errs = append(errs, err)
 	}
 
-	maxResults := defaultMaxResults
-	if raw := strings.TrimSpace(os.Getenv("JIRA_MAX_RESULTS")); raw != "" {
-		parsed, err := strconv.Atoi(raw)
-		if err != nil || parsed <= 0 {
-			return config{}, fmt.Errorf("invalid JIRA_MAX_RESULTS: %s", raw)
-		}
-		maxResults = parsed
+	outputFormat := strings.ToLower(setting("JIRA_OUTPUT_FORMAT", file.OutputFormat))
+	switch outputFormat {
+	case "":
+		outputFormat = outputYAML
+	case outputYAML, outputMarkdown:
+	default:
+		errs = append(errs, fmt.Errorf("invalid JIRA_OUTPUT_FORMAT: %s (want yaml or markdown)", outputFormat))
+	}
+
+	markdownDir := setting("JIRA_MARKDOWN_DIR", file.MarkdownDir)
+	if markdownDir == "" {
+		markdownDir = defaultMarkdownDir
+	}
+	markdownDir, err = resolveYAMLPath(markdownDir)
+	if err != nil {
+		errs = append(errs, err)
 	}
 
-	pushWorkers := defaultPushWorkers
-	if raw := strings.TrimSpace(os.Getenv("JIRA_PUSH_WORKERS")); raw != "" {
-		parsed, err := strconv.Atoi(raw)
+	attachmentsDir := setting("JIRA_ATTACHMENTS_DIR", file.AttachmentsDir)
+	if attachmentsDir == "" {
+		attachmentsDir = defaultAttachmentsDir
+	}
+	attachmentsDir, err = resolveYAMLPath(attachmentsDir)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	maxAttachMB, err := positiveSetting("JIRA_MAX_ATTACHMENT_MB", file.MaxAttachmentMB, defaultMaxAttachMB)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	maxResults, err := positiveSetting("JIRA_MAX_RESULTS", file.MaxResults, defaultMaxResults)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	pushWorkers, err := positiveSetting("JIRA_PUSH_WORKERS", file.PushWorkers, defaultPushWorkers)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	apiVersion, err := positiveSetting("JIRA_API_VERSION", file.APIVersion, defaultAPIVersion)
+	if err != nil {
+		errs = append(errs, err)
+	} else if apiVersion != 2 && apiVersion != 3 {
+		errs = append(errs, fmt.Errorf("invalid JIRA_API_VERSION: %d (want 2 or 3)", apiVersion))
+	}
+
+	pruneLinks, err := boolSetting("JIRA_PRUNE_LINKS", file.PruneLinks)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	var timeout time.Duration
+	if raw := setting("JIRA_TIMEOUT", file.Timeout); raw != "" {
+		parsed, err := time.ParseDuration(raw)
 		if err != nil || parsed <= 0 {
-			return config{}, fmt.Errorf("invalid JIRA_PUSH_WORKERS: %s", raw)
+			errs = append(errs, fmt.Errorf("invalid JIRA_TIMEOUT: %s", raw))
 		}
-		pushWorkers = parsed
+		timeout = parsed
 	}
 
-	epicField := strings.TrimSpace(os.Getenv("JIRA_EPIC_LINK_FIELD"))
+	epicField := setting("JIRA_EPIC_LINK_FIELD", file.EpicLinkField)
 	if epicField == "" {
 		epicField = "customfield_10014"
 	}
 
+	aliases := file.CustomFields
+	if raw := strings.TrimSpace(os.Getenv("JIRA_CUSTOM_FIELDS")); raw != "" {
+		if aliases, err = parseCustomFieldAliases(raw); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := validateCustomFieldAliases(aliases); err != nil {
+		errs = append(errs, err)
+	}
+
+	// Jira leaves field IDs it does not recognise out of the response rather
+	// than failing the search, so unknown entries are harmless.
+	extraFields := file.Fields
+	if raw := strings.TrimSpace(os.Getenv("JIRA_FIELDS")); raw != "" {
+		extraFields = strings.Split(raw, ",")
+	}
+	// Aliased custom fields are always fetched.
+	for _, id := range aliases {
+		extraFields = append(extraFields, id)
+	}
+	fields := append([]string(nil), baseSearchFields...)
+	seen := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		seen[field] = true
+	}
+	for _, field := range extraFields {
+		field = strings.TrimSpace(field)
+		if field != "" && !seen[field] {
+			seen[field] = true
+			fields = append(fields, field)
+		}
+	}
+
+	if len(errs) > 0 {
+		return config{}, errors.Join(errs...)
+	}
 	return config{
-		BaseURL:          baseURL,
-		Email:            email,
-		APIToken:         token,
-		ProjectKey:       projectKey,
-		DefaultIssueType: defaultIssueType,
-		JQL:              jql,
-		YAMLPath:         yamlPath,
-		MaxResults:       maxResults,
-		PushWorkers:      pushWorkers,
-		EpicLinkField:    epicField,
+		BaseURL:            baseURL,
+		Email:              email,
+		APIToken:           token,
+		ProjectKey:         projectKey,
+		DefaultIssueType:   defaultIssueType,
+		JQL:                jql,
+		YAMLPath:           yamlPath,
+		OutputFormat:       outputFormat,
+		MarkdownDir:        markdownDir,
+		AttachmentsDir:     attachmentsDir,
+		MaxAttachmentSize:  int64(maxAttachMB) << 20,
+		MaxResults:         maxResults,
+		PushWorkers:        pushWorkers,
+		EpicLinkField:      epicField,
+		Fields:             fields,
+		Timeout:            timeout,
+		APIVersion:         apiVersion,
+		PruneLinks:         pruneLinks,
+		CustomFieldAliases: aliases,
 	}, nil
 }
 
@@ -240,24 +416,44 @@ func findRepoRoot() (string, error) {
 type jiraClient struct {
 	httpClient             *http.Client
 	baseURL                string
+	apiPrefix              string
 	authHeader             string
 	projectKey             string
+	searchFields           string
 	issueTypeMu            sync.Mutex
 	issueTypeCache         map[string]string
 	issueTypeProjectLoaded bool
 	issueTypeGlobalLoaded  bool
+	assigneeMu             sync.Mutex
+	assigneeCache          map[string]assigneeLookup
+	linkTypeMu             sync.Mutex
+	linkTypeCache          map[string]string
+	// retryDelay is the first back-off after a 429 without Retry-After; it
+	// doubles on each further attempt.
+	retryDelay time.Duration
 }
 
-func newJiraClient(cfg config) *jiraClient {
+// newJiraClient builds a client for cfg. transport is used for every request
+// when non-nil, which lets tests point the client at an httptest.Server;
+// nil means http.DefaultTransport.
+func newJiraClient(cfg config, transport http.RoundTripper) *jiraClient {
 	credentials := base64.StdEncoding.EncodeToString([]byte(cfg.Email + ":" + cfg.APIToken))
 	return &jiraClient{
-		httpClient: &http.Client{Timeout: 30 * time.Second},
-		baseURL:    cfg.BaseURL,
-		authHeader: "Basic " + credentials,
-		projectKey: cfg.ProjectKey,
+		httpClient:   &http.Client{Timeout: 30 * time.Second, Transport: transport},
+		retryDelay:   time.Second,
+		baseURL:      cfg.BaseURL,
+		apiPrefix:    apiPrefix(cfg.APIVersion),
+		authHeader:   "Basic " + credentials,
+		projectKey:   cfg.ProjectKey,
+		searchFields: strings.Join(cfg.Fields, ","),
 	}
 }
 
+// apiPrefix is the REST path for a Jira API version.
+func apiPrefix(version int) string {
+	return "/rest/api/" + strconv.Itoa(version)
+}
+
 func (c *jiraClient) newRequest(ctx context.Context, method, path string, query url.Values, body interface{}) (*http.Request, error) {
 	var buf io.ReadWriter
 	if body != nil {
@@ -286,28 +482,75 @@ func (c *jiraClient) newRequest(ctx context.Context, method, path string, query
 	return req, nil
 }
 
+// maxRateLimitRetries is how many times a request rejected with 429 Too Many
+// Requests is retried before the error is returned.
+const maxRateLimitRetries = 4
+
 func (c *jiraClient) do(req *http.Request, v interface{}) error {
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.send(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
+	if v == nil {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// send performs req, retrying when rate limited, and turns error statuses into
+// errors. The caller closes the body of the returned response.
+func (c *jiraClient) send(req *http.Request) (*http.Response, error) {
+	resp, err := c.httpClient.Do(req)
+	for attempt := 0; err == nil && resp.StatusCode == http.StatusTooManyRequests && attempt < maxRateLimitRetries; attempt++ {
+		wait := retryAfter(resp, c.retryDelay<<attempt)
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		if req.GetBody != nil {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return nil, bodyErr
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+		resp, err = c.httpClient.Do(req)
+	}
+	if err != nil {
+		return nil, err
+	}
+
 	if resp.StatusCode >= http.StatusBadRequest {
+		defer resp.Body.Close()
 		b, _ := io.ReadAll(resp.Body)
 		msg := strings.TrimSpace(string(b))
 		if msg == "" {
 			msg = resp.Status
 		}
-		return fmt.Errorf("jira API error: %s", msg)
+		return nil, fmt.Errorf("jira API error: %s", msg)
 	}
+	return resp, nil
+}
 
-	if v == nil {
-		io.Copy(io.Discard, resp.Body)
-		return nil
+// retryAfter honours a Retry-After header given in seconds and otherwise
+// falls back to the caller's back-off.
+func retryAfter(resp *http.Response, fallback time.Duration) time.Duration {
+	if seconds, err := strconv.Atoi(strings.TrimSpace(resp.Header.Get("Retry-After"))); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
 	}
-
-	return json.NewDecoder(resp.Body).Decode(v)
+	return fallback
 }
 
 func (c *jiraClient) searchIssues(ctx context.Context, jql string, startAt, maxResults int) (jiraSearchResponse, error) {
@@ -315,9 +558,9 @@ func (c *jiraClient) searchIssues(ctx context.Context, jql string, startAt, maxR
 	query.Set("jql", jql)
 	query.Set("startAt", strconv.Itoa(startAt))
 	query.Set("maxResults", strconv.Itoa(maxResults))
-	query.Set("fields", "summary,description,labels,issuetype,status,assignee,priority,parent")
+	query.Set("fields", c.searchFields)
 
-	req, err := c.newRequest(ctx, http.MethodGet, jiraAPIPrefix+"/search", query, nil)
+	req, err := c.newRequest(ctx, http.MethodGet, c.apiPrefix+"/search", query, nil)
 	if err != nil {
 		return jiraSearchResponse{}, err
 	}
@@ -329,9 +572,14 @@ func (c *jiraClient) searchIssues(ctx context.Context, jql string, startAt, maxR
 	return payload, nil
 }
 
-func (c *jiraClient) updateIssue(ctx context.Context, key string, fields map[string]interface{}) error {
+// updateIssue sets fields on an issue and applies update, Jira's per-field
+// operation list (e.g. {"labels": [{"add": "x"}]}); update may be nil.
+func (c *jiraClient) updateIssue(ctx context.Context, key string, fields map[string]interface{}, update map[string]interface{}) error {
 	body := map[string]interface{}{"fields": fields}
-	req, err := c.newRequest(ctx, http.MethodPut, jiraAPIPrefix+"/issue/"+key, nil, body)
+	if len(update) > 0 {
+		body["update"] = update
+	}
+	req, err := c.newRequest(ctx, http.MethodPut, c.apiPrefix+"/issue/"+key, nil, body)
 	if err != nil {
 		return err
 	}
@@ -340,7 +588,7 @@ func (c *jiraClient) updateIssue(ctx context.Context, key string, fields map[str
 
 func (c *jiraClient) createIssue(ctx context.Context, fields map[string]interface{}) (string, error) {
 	body := map[string]interface{}{"fields": fields}
-	req, err := c.newRequest(ctx, http.MethodPost, jiraAPIPrefix+"/issue", nil, body)
+	req, err := c.newRequest(ctx, http.MethodPost, c.apiPrefix+"/issue", nil, body)
 	if err != nil {
 		return "", err
 	}
@@ -357,7 +605,7 @@ func (c *jiraClient) createIssue(ctx context.Context, fields map[string]interfac
 }
 
 func (c *jiraClient) listFields(ctx context.Context) ([]jiraField, error) {
-	req, err := c.newRequest(ctx, http.MethodGet, jiraAPIPrefix+"/field", nil, nil)
+	req, err := c.newRequest(ctx, http.MethodGet, c.apiPrefix+"/field", nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -395,7 +643,8 @@ type jiraFields struct {
 	Description json.RawMessage `json:"description"`
 	Labels      []string        `json:"labels"`
 	IssueType   struct {
-		Name string `json:"name"`
+		Name    string `json:"name"`
+		Subtask bool   `json:"subtask"`
 	} `json:"issuetype"`
 	Status struct {
 		Name string `json:"name"`
@@ -410,161 +659,394 @@ type jiraFields struct {
 	Parent *struct {
 		Key string `json:"key"`
 	} `json:"parent"`
+	Components  []jiraNamed      `json:"components"`
+	FixVersions []jiraNamed      `json:"fixVersions"`
+	DueDate     string           `json:"duedate"`
+	Worklog     *jiraWorklogPage `json:"worklog"`
+	IssueLinks  []jiraIssueLink  `json:"issuelinks"`
+	Updated     string           `json:"updated"`
+	Attachments []jiraAttachment `json:"attachment"`
+	// Custom holds the customfield_* values; see UnmarshalJSON.
+	Custom map[string]json.RawMessage `json:"-"`
+}
+
+type jiraNamed struct {
+	Name string `json:"name"`
 }
 
 type issueRecord struct {
-	Key                 string   `yaml:"key,omitempty"`
-	Summary             string   `yaml:"summary"`
-	Description         string   `yaml:"description,omitempty"`
-	Labels              []string `yaml:"labels,omitempty"`
-	IssueType           string   `yaml:"issueType,omitempty"`
-	ForceIssueType      bool     `yaml:"forceIssueType,omitempty"`
-	Status              string   `yaml:"status,omitempty"`
-	Priority            string   `yaml:"priority,omitempty"`
-	ParentKey           string   `yaml:"parent,omitempty"`
-	AssigneeAccountID   string   `yaml:"assigneeAccountId,omitempty"`
-	AssigneeDisplayName string   `yaml:"assigneeDisplayName,omitempty"`
-	Delete              bool     `yaml:"delete,omitempty"`
+	Key                 string                 `yaml:"key,omitempty"`
+	Summary             string                 `yaml:"summary"`
+	Description         string                 `yaml:"description,omitempty"`
+	Labels              []string               `yaml:"labels,omitempty"`
+	AddLabels           []string               `yaml:"addLabels,omitempty"`
+	RemoveLabels        []string               `yaml:"removeLabels,omitempty"`
+	IssueType           string                 `yaml:"issueType,omitempty"`
+	ForceIssueType      bool                   `yaml:"forceIssueType,omitempty"`
+	Status              string                 `yaml:"status,omitempty"`
+	Priority            string                 `yaml:"priority,omitempty"`
+	ParentKey           string                 `yaml:"parent,omitempty"`
+	AssigneeAccountID   string                 `yaml:"assigneeAccountId,omitempty"`
+	AssigneeDisplayName string                 `yaml:"assigneeDisplayName,omitempty"`
+	AssigneeEmail       string                 `yaml:"assigneeEmail,omitempty"`
+	Unassign            bool                   `yaml:"unassign,omitempty"`
+	Components          []string               `yaml:"components,omitempty"`
+	FixVersions         []string               `yaml:"fixVersions,omitempty"`
+	DueDate             string                 `yaml:"dueDate,omitempty"`
+	Updated             string                 `yaml:"updated,omitempty"` // as of the last pull; push checks it for conflicts
+	Worklogs            []worklogRecord        `yaml:"worklogs,omitempty"`
+	Links               []linkRecord           `yaml:"links,omitempty"`
+	Attachments         []string               `yaml:"attachments,omitempty"`
+	Subtasks            []issueRecord          `yaml:"subtasks,omitempty"`
+	CustomFields        map[string]interface{} `yaml:"customFields,omitempty"`
+	Delete              bool                   `yaml:"delete,omitempty"`
 }
 
 type issueFile struct {
 	Issues []issueRecord `yaml:"issues"`
 }
 
+// runPull merges the issues matching the JQL into the YAML file. Existing
+// entries keep their place and comments; issues Jira no longer returns are
+// dropped and new ones are appended. The file is only replaced once every
+// page has arrived.
 func runPull(ctx context.Context, client *jiraClient, cfg config) error {
+	if cfg.OutputFormat == outputMarkdown {
+		return runPullMarkdown(ctx, client, cfg)
+	}
+	doc, err := loadIssueDocument(cfg.YAMLPath)
+	if err != nil {
+		return err
+	}
+
 	fmt.Println("Fetching issues from Jira...")
-	var allIssues []jiraIssue
+	fetched := 0
+	// Nesting needs every issue first, so those records are held back.
+	var held []issueRecord
+	subtasks := make(map[string]bool)
+	err = forEachIssuePage(ctx, client, cfg.JQL, cfg.MaxResults, func(issues []jiraIssue, total int) error {
+		for _, issue := range issues {
+			record, err := issueToRecord(issue, cfg)
+			if err != nil {
+				return err
+			}
+			if cfg.DownloadAttachments {
+				if err := downloadAttachments(ctx, client, cfg, issue); err != nil {
+					return err
+				}
+			}
+			if cfg.Nested {
+				held = append(held, record)
+				subtasks[issue.Key] = issue.Fields.IssueType.Subtask
+				continue
+			}
+			if err := doc.merge(record); err != nil {
+				return err
+			}
+		}
+		fetched += len(issues)
+		fmt.Printf("Fetched %d / %d\n", fetched, total)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, record := range nestSubtasks(held, subtasks) {
+		if err := doc.merge(record); err != nil {
+			return err
+		}
+	}
+
+	doc.keepOnlyMerged()
+	if err := doc.save(cfg.YAMLPath); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %d issue(s) to %s\n", fetched, cfg.YAMLPath)
+	return nil
+}
+
+// forEachIssuePage runs the search page by page, handing each page to fn so
+// callers never hold the whole result set.
+func forEachIssuePage(ctx context.Context, client *jiraClient, jql string, pageSize int, fn func(issues []jiraIssue, total int) error) error {
 	startAt := 0
 	for {
-		resp, err := client.searchIssues(ctx, cfg.JQL, startAt, cfg.MaxResults)
+		resp, err := client.searchIssues(ctx, jql, startAt, pageSize)
 		if err != nil {
 			return fmt.Errorf("search issues: %w", err)
 		}
-		allIssues = append(allIssues, resp.Issues...)
-		startAt += len(resp.Issues)
-		if startAt >= resp.Total || len(resp.Issues) == 0 {
-			break
+		// A server that ignores startAt would hand back the same page forever.
+		if resp.StartAt != startAt {
+			return fmt.Errorf("search issues: requested results from %d but Jira returned them from %d", startAt, resp.StartAt)
 		}
-	}
-
-	records := make([]issueRecord, 0, len(allIssues))
-	for _, issue := range allIssues {
-		description, err := adfToPlainText(issue.Fields.Description)
-		if err != nil {
-			return fmt.Errorf("parse description for %s: %w", issue.Key, err)
+		// Jira silently caps maxResults (100 on Cloud); ask for what it will
+		// actually return from now on.
+		if resp.MaxResults > 0 && resp.MaxResults < pageSize {
+			pageSize = resp.MaxResults
 		}
-		record := issueRecord{
-			Key:         issue.Key,
-			Summary:     issue.Fields.Summary,
-			Description: description,
-			Labels:      append([]string(nil), issue.Fields.Labels...),
-			IssueType:   issue.Fields.IssueType.Name,
-			Status:      issue.Fields.Status.Name,
+		// Total is only an estimate while issues change, so an empty page is
+		// the end regardless of what it claims.
+		if len(resp.Issues) == 0 {
+			return nil
 		}
-		if issue.Fields.Priority != nil {
-			record.Priority = issue.Fields.Priority.Name
+		for i := range resp.Issues {
+			if err := client.completeWorklogs(ctx, &resp.Issues[i]); err != nil {
+				return err
+			}
 		}
-		if issue.Fields.Parent != nil {
-			record.ParentKey = issue.Fields.Parent.Key
+		if err := fn(resp.Issues, resp.Total); err != nil {
+			return err
 		}
-		if issue.Fields.Assignee != nil {
-			record.AssigneeAccountID = issue.Fields.Assignee.AccountID
-			record.AssigneeDisplayName = issue.Fields.Assignee.DisplayName
+		startAt += len(resp.Issues)
+		if startAt >= resp.Total {
+			return nil
 		}
-		records = append(records, record)
 	}
+}
 
-	fileData := issueFile{Issues: records}
-	if fileData.Issues == nil {
-		fileData.Issues = []issueRecord{}
-	}
+func issueToRecord(issue jiraIssue, cfg config) (issueRecord, error) {
+	description, err := richText(issue.Fields.Description, cfg.APIVersion)
+	if err != nil {
+		return issueRecord{}, fmt.Errorf("parse description for %s: %w", issue.Key, err)
+	}
+	record := issueRecord{
+		Key:         issue.Key,
+		Summary:     issue.Fields.Summary,
+		Description: description,
+		Labels:      append([]string(nil), issue.Fields.Labels...),
+		IssueType:   issue.Fields.IssueType.Name,
+		Status:      issue.Fields.Status.Name,
+	}
+	if issue.Fields.Priority != nil {
+		record.Priority = issue.Fields.Priority.Name
+	}
+	if issue.Fields.Parent != nil {
+		record.ParentKey = issue.Fields.Parent.Key
+	}
+	if issue.Fields.Assignee != nil {
+		record.AssigneeAccountID = issue.Fields.Assignee.AccountID
+		record.AssigneeDisplayName = issue.Fields.Assignee.DisplayName
+	}
+	record.Components = namesOf(issue.Fields.Components)
+	record.FixVersions = namesOf(issue.Fields.FixVersions)
+	record.DueDate = issue.Fields.DueDate
+	record.Updated = issue.Fields.Updated
+	record.Links = linkRecords(issue.Fields.IssueLinks)
+	record.Attachments = attachmentNames(issue.Fields.Attachments)
+	if record.Worklogs, err = worklogRecords(issue.Fields.Worklog, cfg.APIVersion); err != nil {
+		return issueRecord{}, fmt.Errorf("parse worklogs for %s: %w", issue.Key, err)
+	}
+	if record.CustomFields, err = customFieldValues(issue.Fields.Custom, cfg.CustomFieldAliases); err != nil {
+		return issueRecord{}, fmt.Errorf("parse custom fields for %s: %w", issue.Key, err)
+	}
+	return record, nil
+}
 
-	if err := writeIssueFile(cfg.YAMLPath, fileData); err != nil {
-		return err
+// descriptionText reads an issue description. API v3 returns it as an
+// Atlassian Document; v2 returns the wiki markup as a plain string, which is
+// kept as is.
+func richText(raw json.RawMessage, apiVersion int) (string, error) {
+	if apiVersion >= 3 {
+		return adf.ToPlainText(raw)
+	}
+	var text *string
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &text); err != nil {
+			return "", err
+		}
 	}
+	if text == nil {
+		return "", nil
+	}
+	return *text, nil
+}
 
-	fmt.Printf("Wrote %d issue(s) to %s\n", len(records), cfg.YAMLPath)
-	return nil
+// descriptionField is the description value to send for text: an Atlassian
+// Document for API v3, the string itself for v2.
+func richTextField(text string, apiVersion int) interface{} {
+	if apiVersion >= 3 {
+		return adf.FromPlainText(text)
+	}
+	return text
 }
 
 func runPush(ctx context.Context, client *jiraClient, cfg config) error {
-	data, err := readIssueFile(cfg.YAMLPath)
+	data, err := readIssues(cfg)
 	if err != nil {
 		return err
 	}
 	if len(data.Issues) == 0 {
-		fmt.Println("No issues found in YAML file; nothing to push.")
+		fmt.Println("No issues found locally; nothing to push.")
 		return nil
 	}
 
-	results := make([]bool, len(data.Issues))
-	for i := range results {
-		results[i] = true
+	issues := flattenIssues(data.Issues)
+	results := pushResults{
+		paths: make([][]int, len(issues)),
+		kept:  make([]bool, len(issues)),
+		// createdKeys records the keys Jira assigned to new entries so they
+		// are written back even if the run is cut short before the
+		// refreshing pull.
+		createdKeys: make([]string, len(issues)),
+		// worklogIDs does the same for the IDs of added worklogs.
+		worklogIDs: make([][]string, len(issues)),
 	}
+	for i, issue := range issues {
+		results.paths[i] = issue.path
+		results.kept[i] = true
+	}
+
+	// conflicts counts issues skipped because Jira changed them after the pull.
+	var conflicts atomic.Int32
 
-	group, groupCtx := errgroup.WithContext(ctx)
 	workers := cfg.PushWorkers
 	if workers <= 0 {
 		workers = 1
 	}
-	group.SetLimit(workers)
-
-	for idx, issue := range data.Issues {
-		idx := idx
-		issue := issue
-		group.Go(func() error {
-			if issue.Delete {
-				key := strings.TrimSpace(issue.Key)
-				if key == "" {
-					fmt.Println("Skipping delete flag on issue without a key.")
-					results[idx] = true
-					return nil
-				}
-				if err := client.deleteIssue(groupCtx, key); err != nil {
-					return fmt.Errorf("delete %s: %w", key, err)
-				}
-				fmt.Printf("Deleted %s\n", key)
-				results[idx] = false
+	push := func(groupCtx context.Context, idx int, issue issueRecord) error {
+		// Workers still queued when the run is cancelled do nothing.
+		if err := groupCtx.Err(); err != nil {
+			return err
+		}
+		if strings.TrimSpace(issue.Key) != "" {
+			if err := checkConflict(groupCtx, client, cfg, issue); errors.Is(err, errConflict) {
+				fmt.Printf("Conflict: %s %v; skipped.\n", issue.Key, err)
+				conflicts.Add(1)
 				return nil
+			} else if err != nil {
+				return fmt.Errorf("%s: %w", issue.Key, err)
 			}
-
-			if strings.TrimSpace(issue.Key) == "" {
-				key, err := createIssue(groupCtx, client, cfg, issue)
-				if err != nil {
-					return fmt.Errorf("create issue: %w", err)
-				}
-				fmt.Printf("Created %s\n", key)
-				results[idx] = false
+		}
+		if issue.Delete {
+			key := strings.TrimSpace(issue.Key)
+			if key == "" {
+				fmt.Println("Skipping delete flag on issue without a key.")
 				return nil
 			}
+			if err := client.deleteIssue(groupCtx, key); err != nil {
+				return fmt.Errorf("delete %s: %w", key, err)
+			}
+			fmt.Printf("Deleted %s\n", key)
+			results.kept[idx] = false
+			return nil
+		}
 
+		key := strings.TrimSpace(issue.Key)
+		if key == "" {
+			var err error
+			key, err = createIssue(groupCtx, client, cfg, issue)
+			if err != nil {
+				return fmt.Errorf("create issue: %w", err)
+			}
+			fmt.Printf("Created %s\n", key)
+			results.createdKeys[idx] = key
+		} else {
 			if err := updateIssue(groupCtx, client, cfg, issue); err != nil {
 				return fmt.Errorf("update %s: %w", issue.Key, err)
 			}
 			fmt.Printf("Updated %s\n", issue.Key)
-			results[idx] = true
-			return nil
-		})
+		}
+		results.worklogIDs[idx] = make([]string, len(issue.Worklogs))
+		if err := pushWorklogs(groupCtx, client, cfg, key, issue, results.worklogIDs[idx]); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		if err := pushLinks(groupCtx, client, cfg, key, issue); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		if err := pushAttachments(groupCtx, client, cfg, key, issue); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		return nil
 	}
 
-	if err := group.Wait(); err != nil {
+	// Nested subtasks go in a second round, once the parents they may need
+	// the new keys of exist.
+	var pushErr error
+	for _, nested := range []bool{false, true} {
+		group, groupCtx := errgroup.WithContext(ctx)
+		group.SetLimit(workers)
+		for idx, item := range issues {
+			if (item.parent >= 0) != nested {
+				continue
+			}
+			idx, issue := idx, item.record
+			if nested && issue.ParentKey == "" {
+				issue.ParentKey = results.createdKeys[item.parent]
+			}
+			group.Go(func() error { return push(groupCtx, idx, issue) })
+		}
+		if pushErr = group.Wait(); pushErr != nil {
+			break
+		}
+	}
+
+	// On failure or cancellation the file is still rewritten, so deleted and
+	// created issues are not pushed a second time by the next run.
+	record, target := recordPushResults, cfg.YAMLPath
+	if cfg.OutputFormat == outputMarkdown {
+		record, target = recordMarkdownPushResults, cfg.MarkdownDir
+	}
+	if err := record(target, results); err != nil {
+		if pushErr != nil {
+			return fmt.Errorf("%w (and recording pushed changes failed: %v)", pushErr, err)
+		}
 		return err
 	}
+	if pushErr == nil && conflicts.Load() > 0 {
+		// Failing also stops the refreshing pull from overwriting the local
+		// edits that were not pushed.
+		return fmt.Errorf("skipped %d issue(s) changed in Jira since the last pull; pull and redo the edits, or push with -force", conflicts.Load())
+	}
+	return pushErr
+}
 
-	var remaining []issueRecord
-	for idx, keep := range results {
-		if keep {
-			remaining = append(remaining, data.Issues[idx])
+// pushResults is what push did to each local issue, indexed like the
+// flattened issue list.
+type pushResults struct {
+	paths       [][]int // flatIssue.path of each issue
+	kept        []bool  // false once the issue was deleted
+	createdKeys []string
+	worklogIDs  [][]string
+}
+
+// recordPushResults drops deleted issues from the YAML file and fills in the
+// keys of created issues and the IDs of added worklogs. It leaves the file
+// alone when none of that happened.
+func recordPushResults(path string, results pushResults) error {
+	changed := false
+	for idx, keep := range results.kept {
+		if !keep || results.createdKeys[idx] != "" {
+			changed = true
+			break
+		}
+		for _, id := range results.worklogIDs[idx] {
+			changed = changed || id != ""
 		}
 	}
+	if !changed {
+		return nil
+	}
 
-	if len(remaining) != len(data.Issues) {
-		data.Issues = remaining
-		if err := writeIssueFile(cfg.YAMLPath, data); err != nil {
-			return err
+	// Rewrite through the node tree so comments on the remaining issues survive.
+	doc, err := loadIssueDocument(path)
+	if err != nil {
+		return err
+	}
+	var deleted [][]int
+	for idx, issuePath := range results.paths {
+		if key := results.createdKeys[idx]; key != "" {
+			doc.setKey(issuePath, key)
+		}
+		for n, id := range results.worklogIDs[idx] {
+			if id != "" {
+				doc.setWorklogID(issuePath, n, id)
+			}
+		}
+		if !results.kept[idx] {
+			deleted = append(deleted, issuePath)
 		}
 	}
-	return nil
+	doc.dropIssues(deleted)
+	return doc.save(path)
 }
 
 func runListFields(ctx context.Context, client *jiraClient) error {
@@ -617,18 +1099,22 @@ func createIssue(ctx context.Context, client *jiraClient, cfg config, issue issu
 	}
 
 	if desc := strings.TrimSpace(issue.Description); desc != "" {
-		fields["description"] = plainTextToADF(desc)
+		fields["description"] = richTextField(desc, cfg.APIVersion)
 	}
-	if issue.Labels != nil {
-		fields["labels"] = issue.Labels
+	if labels := createLabels(issue); labels != nil {
+		fields["labels"] = labels
 	}
-	if id := strings.TrimSpace(issue.AssigneeAccountID); id != "" {
-		fields["assignee"] = map[string]string{"accountId": id}
+	if assignee, ok := assigneeField(ctx, client, issue); ok {
+		fields["assignee"] = assignee
 	}
 	priority := strings.TrimSpace(issue.Priority)
 	if priority != "" {
 		fields["priority"] = map[string]string{"name": priority}
 	}
+	setOptionalFields(fields, issue)
+	if err := setCustomFields(fields, issue, cfg.CustomFieldAliases); err != nil {
+		return "", err
+	}
 	parent := strings.TrimSpace(issue.ParentKey)
 	epicField := strings.TrimSpace(cfg.EpicLinkField)
 	useEpicFallback := parent != "" && epicField != ""
@@ -676,6 +1162,12 @@ func createIssue(ctx context.Context, client *jiraClient, cfg config, issue issu
 			fmt.Printf("Warning: Jira rejected priority for new issue %q; created without priority.\n", summary)
 		}
 	}
+	if err != nil {
+		if dropped := dropRejectedOptionalFields(err, fields); len(dropped) > 0 {
+			fmt.Printf("Warning: Jira rejected %s for new issue %q; retrying without them.\n", strings.Join(dropped, ", "), summary)
+			key, err = client.createIssue(ctx, fields)
+		}
+	}
 	return key, err
 }
 
@@ -687,7 +1179,7 @@ func updateIssue(ctx context.Context, client *jiraClient, cfg config, issue issu
 
 	fields := map[string]interface{}{
 		"summary":     summary,
-		"description": plainTextToADF(strings.TrimSpace(issue.Description)),
+		"description": richTextField(strings.TrimSpace(issue.Description), cfg.APIVersion),
 	}
 
 	issueType := strings.TrimSpace(issue.IssueType)
@@ -701,16 +1193,25 @@ func updateIssue(ctx context.Context, client *jiraClient, cfg config, issue issu
 		fields["issuetype"] = issueTypeField
 	}
 
-	if issue.Labels != nil {
+	var update map[string]interface{}
+	if ops := labelOperations(issue); len(ops) > 0 {
+		// Jira rejects a field that appears in both fields and update, and
+		// the point of add/remove is to leave other labels alone.
+		update = map[string]interface{}{"labels": ops}
+	} else if issue.Labels != nil {
 		fields["labels"] = issue.Labels
 	}
-	if id := strings.TrimSpace(issue.AssigneeAccountID); id != "" {
-		fields["assignee"] = map[string]string{"accountId": id}
+	if assignee, ok := assigneeField(ctx, client, issue); ok {
+		fields["assignee"] = assignee
 	}
 	priority := strings.TrimSpace(issue.Priority)
 	if priority != "" {
 		fields["priority"] = map[string]string{"name": priority}
 	}
+	setOptionalFields(fields, issue)
+	if err := setCustomFields(fields, issue, cfg.CustomFieldAliases); err != nil {
+		return err
+	}
 	parent := strings.TrimSpace(issue.ParentKey)
 	epicField := strings.TrimSpace(cfg.EpicLinkField)
 	useEpicFallback := parent != "" && epicField != ""
@@ -718,14 +1219,14 @@ func updateIssue(ctx context.Context, client *jiraClient, cfg config, issue issu
 		fields["parent"] = map[string]string{"key": parent}
 	}
 
-	err := client.updateIssue(ctx, issue.Key, fields)
+	err := client.updateIssue(ctx, issue.Key, fields, update)
 	if err != nil && issueTypeField != nil && isInvalidIssueTypeError(err) {
 		client.invalidateIssueTypeCache()
 		if refreshed, refreshErr := client.issueTypeField(ctx, issueType); refreshErr == nil {
 			issueTypeField = refreshed
 			fields["issuetype"] = issueTypeField
 			fmt.Printf("Warning: Jira refreshed issue type mapping for %s; retrying.\n", issue.Key)
-			err = client.updateIssue(ctx, issue.Key, fields)
+			err = client.updateIssue(ctx, issue.Key, fields, update)
 		}
 	}
 	if err != nil && issueTypeField != nil && isInvalidIssueTypeError(err) {
@@ -733,7 +1234,7 @@ func updateIssue(ctx context.Context, client *jiraClient, cfg config, issue issu
 			delete(issueTypeField, "id")
 			issueTypeField["name"] = issueType
 			fmt.Printf("Warning: Jira rejected issue type id for %s; retrying with name.\n", issue.Key)
-			err = client.updateIssue(ctx, issue.Key, fields)
+			err = client.updateIssue(ctx, issue.Key, fields, update)
 		}
 	}
 	if err != nil && parent != "" && isParentError(err) {
@@ -744,26 +1245,125 @@ func updateIssue(ctx context.Context, client *jiraClient, cfg config, issue issu
 		} else {
 			fmt.Printf("Warning: Jira rejected parent update %s for %s; retrying without parent.\n", parent, issue.Key)
 		}
-		err = client.updateIssue(ctx, issue.Key, fields)
+		err = client.updateIssue(ctx, issue.Key, fields, update)
 	}
 	if err != nil && useEpicFallback && isEpicLinkError(err) {
 		delete(fields, epicField)
 		fmt.Printf("Warning: Jira rejected epic link update %s for %s; retrying without epic link.\n", parent, issue.Key)
-		err = client.updateIssue(ctx, issue.Key, fields)
+		err = client.updateIssue(ctx, issue.Key, fields, update)
 	}
 	if err != nil && priority != "" && isPriorityError(err) {
 		delete(fields, "priority")
 		fmt.Printf("Warning: Jira rejected priority update for %s; retrying without priority.\n", issue.Key)
-		err = client.updateIssue(ctx, issue.Key, fields)
+		err = client.updateIssue(ctx, issue.Key, fields, update)
 		if err == nil {
 			fmt.Printf("Warning: Jira rejected priority update for %s; left existing priority unchanged.\n", issue.Key)
 		}
 	}
+	if err != nil {
+		if dropped := dropRejectedOptionalFields(err, fields); len(dropped) > 0 {
+			fmt.Printf("Warning: Jira rejected %s for %s; retrying without them.\n", strings.Join(dropped, ", "), issue.Key)
+			err = client.updateIssue(ctx, issue.Key, fields, update)
+		}
+	}
 	return err
 }
 
+// optionalFields are the Jira IDs of the fields push sends only when set.
+var optionalFields = []string{"components", "fixVersions", "duedate"}
+
+// setOptionalFields adds the optional fields the record sets. A nil list
+// leaves the Jira value alone; an explicit empty list clears it.
+func setOptionalFields(fields map[string]interface{}, issue issueRecord) {
+	if issue.Components != nil {
+		fields["components"] = namedValues(issue.Components)
+	}
+	if issue.FixVersions != nil {
+		fields["fixVersions"] = namedValues(issue.FixVersions)
+	}
+	if due := strings.TrimSpace(issue.DueDate); due != "" {
+		fields["duedate"] = due
+	}
+}
+
+// dropRejectedOptionalFields removes the optional fields err complains about
+// (typically because they are not on the project's screen) and returns their
+// names.
+func dropRejectedOptionalFields(err error, fields map[string]interface{}) []string {
+	msg := strings.ToLower(err.Error())
+	var dropped []string
+	for _, field := range optionalFields {
+		if _, ok := fields[field]; ok && strings.Contains(msg, strings.ToLower(field)) {
+			delete(fields, field)
+			dropped = append(dropped, field)
+		}
+	}
+	return dropped
+}
+
+func namedValues(names []string) []map[string]string {
+	values := make([]map[string]string, 0, len(names))
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			values = append(values, map[string]string{"name": name})
+		}
+	}
+	return values
+}
+
+func namesOf(values []jiraNamed) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	names := make([]string, len(values))
+	for i, value := range values {
+		names[i] = value.Name
+	}
+	return names
+}
+
+// labelOperations translates addLabels/removeLabels into Jira update
+// operations. Blank names are skipped.
+func labelOperations(issue issueRecord) []map[string]string {
+	var ops []map[string]string
+	for _, label := range issue.AddLabels {
+		if label = strings.TrimSpace(label); label != "" {
+			ops = append(ops, map[string]string{"add": label})
+		}
+	}
+	for _, label := range issue.RemoveLabels {
+		if label = strings.TrimSpace(label); label != "" {
+			ops = append(ops, map[string]string{"remove": label})
+		}
+	}
+	return ops
+}
+
+// createLabels is the label set for a new issue: labels plus addLabels, minus
+// removeLabels. It returns nil when none of them are set.
+func createLabels(issue issueRecord) []string {
+	if issue.Labels == nil && issue.AddLabels == nil && issue.RemoveLabels == nil {
+		return nil
+	}
+	removed := make(map[string]bool, len(issue.RemoveLabels))
+	for _, label := range issue.RemoveLabels {
+		removed[strings.TrimSpace(label)] = true
+	}
+	seen := make(map[string]bool)
+	labels := []string{}
+	for _, label := range append(append([]string(nil), issue.Labels...), issue.AddLabels...) {
+		label = strings.TrimSpace(label)
+		if label == "" || removed[label] || seen[label] {
+			continue
+		}
+		seen[label] = true
+		labels = append(labels, label)
+	}
+	return labels
+}
+
 func (c *jiraClient) deleteIssue(ctx context.Context, key string) error {
-	req, err := c.newRequest(ctx, http.MethodDelete, jiraAPIPrefix+"/issue/"+key, nil, nil)
+	req, err := c.newRequest(ctx, http.MethodDelete, c.apiPrefix+"/issue/"+key, nil, nil)
 	if err != nil {
 		return err
 	}
@@ -855,7 +1455,7 @@ func (c *jiraClient) fetchProjectIssueTypeIDs(ctx context.Context) error {
 
 	query := url.Values{}
 	query.Set("projectKeys", key)
-	req, err := c.newRequest(ctx, http.MethodGet, jiraAPIPrefix+"/issue/createmeta", query, nil)
+	req, err := c.newRequest(ctx, http.MethodGet, c.apiPrefix+"/issue/createmeta", query, nil)
 	if err != nil {
 		return err
 	}
@@ -911,7 +1511,7 @@ func (c *jiraClient) fetchProjectIssueTypeIDs(ctx context.Context) error {
 }
 
 func (c *jiraClient) fetchGlobalIssueTypeIDs(ctx context.Context) error {
-	req, err := c.newRequest(ctx, http.MethodGet, jiraAPIPrefix+"/issuetype", nil, nil)
+	req, err := c.newRequest(ctx, http.MethodGet, c.apiPrefix+"/issuetype", nil, nil)
 	if err != nil {
 		return err
 	}
@@ -989,28 +1589,6 @@ func isPriorityError(err error) bool {
 	return strings.Contains(msg, "priority")
 }
 
-func writeIssueFile(path string, data issueFile) error {
-	if data.Issues == nil {
-		data.Issues = []issueRecord{}
-	}
-	output, err := yaml.Marshal(data)
-	if err != nil {
-		return fmt.Errorf("marshal yaml: %w", err)
-	}
-
-	dir := filepath.Dir(path)
-	if dir != "." && dir != "" {
-		if err := os.MkdirAll(dir, 0o755); err != nil {
-			return fmt.Errorf("create directory %s: %w", dir, err)
-		}
-	}
-
-	if err := os.WriteFile(path, output, 0o644); err != nil {
-		return fmt.Errorf("write yaml: %w", err)
-	}
-	return nil
-}
-
 func readIssueFile(path string) (issueFile, error) {
 	content, err := os.ReadFile(path)
 	if err != nil {
@@ -1022,176 +1600,3 @@ func readIssueFile(path string) (issueFile, error) {
 	}
 	return data, nil
 }
-
-type adfNode struct {
-	Type    string    `json:"type"`
-	Text    string    `json:"text,omitempty"`
-	Content []adfNode `json:"content,omitempty"`
-}
-
-func adfToPlainText(raw json.RawMessage) (string, error) {
-	if len(raw) == 0 || string(raw) == "null" {
-		return "", nil
-	}
-	var node adfNode
-	if err := json.Unmarshal(raw, &node); err != nil {
-		return "", err
-	}
-	var sb strings.Builder
-	ctx := &adfContext{}
-	appendADFNode(&sb, node, ctx)
-	text := strings.TrimRight(sb.String(), "\n")
-	return text, nil
-}
-
-type adfContext struct {
-	listStack          []listState
-	pendingPrefix      string
-	continuationPrefix string
-}
-
-type listState struct {
-	ordered bool
-	counter int
-}
-
-func (ctx *adfContext) pushList(ordered bool) {
-	ctx.listStack = append(ctx.listStack, listState{ordered: ordered})
-}
-
-func (ctx *adfContext) popList() {
-	if len(ctx.listStack) == 0 {
-		return
-	}
-	ctx.listStack = ctx.listStack[:len(ctx.listStack)-1]
-}
-
-func (ctx *adfContext) nextListPrefix() string {
-	if len(ctx.listStack) == 0 {
-		return ""
-	}
-	indent := strings.Repeat("  ", len(ctx.listStack)-1)
-	idx := len(ctx.listStack) - 1
-	state := ctx.listStack[idx]
-	if state.ordered {
-		state.counter++
-		ctx.listStack[idx] = state
-		return fmt.Sprintf("%s%d. ", indent, state.counter)
-	}
-	return fmt.Sprintf("%s- ", indent)
-}
-
-func (ctx *adfContext) startLine(prefix string) {
-	ctx.pendingPrefix = prefix
-	if prefix != "" {
-		ctx.continuationPrefix = strings.Repeat(" ", len(prefix))
-	}
-}
-
-func (ctx *adfContext) ensurePrefix(sb *strings.Builder) {
-	if ctx.pendingPrefix != "" {
-		sb.WriteString(ctx.pendingPrefix)
-		ctx.pendingPrefix = ""
-	}
-}
-
-func (ctx *adfContext) newline(sb *strings.Builder) {
-	sb.WriteString("\n")
-	if ctx.continuationPrefix != "" {
-		ctx.pendingPrefix = ctx.continuationPrefix
-	}
-}
-
-func (ctx *adfContext) clearContinuation() {
-	ctx.continuationPrefix = ""
-	ctx.pendingPrefix = ""
-}
-
-func appendADFNode(sb *strings.Builder, node adfNode, ctx *adfContext) {
-	switch node.Type {
-	case "doc":
-		for _, child := range node.Content {
-			appendADFNode(sb, child, ctx)
-		}
-	case "paragraph", "heading":
-		ctx.ensurePrefix(sb)
-		for _, child := range node.Content {
-			appendADFNode(sb, child, ctx)
-		}
-		ctx.newline(sb)
-		ctx.clearContinuation()
-	case "text":
-		ctx.ensurePrefix(sb)
-		sb.WriteString(node.Text)
-	case "hardBreak":
-		ctx.newline(sb)
-	case "bulletList":
-		ctx.pushList(false)
-		for _, child := range node.Content {
-			appendADFNode(sb, child, ctx)
-		}
-		ctx.popList()
-		ctx.clearContinuation()
-	case "orderedList":
-		ctx.pushList(true)
-		for _, child := range node.Content {
-			appendADFNode(sb, child, ctx)
-		}
-		ctx.popList()
-		ctx.clearContinuation()
-	case "listItem":
-		prefix := ctx.nextListPrefix()
-		ctx.startLine(prefix)
-		for _, child := range node.Content {
-			appendADFNode(sb, child, ctx)
-		}
-		ctx.newline(sb)
-		ctx.clearContinuation()
-	case "blockquote":
-		ctx.startLine("> ")
-		for _, child := range node.Content {
-			appendADFNode(sb, child, ctx)
-		}
-		ctx.newline(sb)
-		ctx.clearContinuation()
-	default:
-		for _, child := range node.Content {
-			appendADFNode(sb, child, ctx)
-		}
-	}
-}
-
-func plainTextToADF(input string) map[string]interface{} {
-	normalized := strings.ReplaceAll(input, "\r\n", "\n")
-	sections := strings.Split(normalized, "\n\n")
-	content := make([]map[string]interface{}, 0, len(sections))
-	for _, section := range sections {
-		lines := strings.Split(section, "\n")
-		var nodes []map[string]interface{}
-		for i, line := range lines {
-			trimmed := strings.TrimRight(line, " ")
-			if trimmed != "" {
-				nodes = append(nodes, map[string]interface{}{
-					"type": "text",
-					"text": trimmed,
-				})
-			}
-			if i < len(lines)-1 {
-				nodes = append(nodes, map[string]interface{}{"type": "hardBreak"})
-			}
-		}
-		paragraph := map[string]interface{}{"type": "paragraph"}
-		if len(nodes) > 0 {
-			paragraph["content"] = nodes
-		}
-		content = append(content, paragraph)
-	}
-	if len(content) == 0 {
-		content = append(content, map[string]interface{}{"type": "paragraph"})
-	}
-	return map[string]interface{}{
-		"type":    "doc",
-		"version": 1,
-		"content": content,
-	}
-}
@@ -0,0 +1,913 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestClient points a jiraClient at handler through an httptest.Server.
+func newTestClient(t *testing.T, handler http.Handler) (*jiraClient, config) {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	cfg := config{
+		BaseURL:           srv.URL,
+		Email:             "bot@example.com",
+		APIToken:          "token",
+		ProjectKey:        "PROJ",
+		DefaultIssueType:  defaultIssueTypeValue,
+		JQL:               "project = PROJ",
+		YAMLPath:          filepath.Join(t.TempDir(), "jira-tasks.yaml"),
+		MaxResults:        2,
+		PushWorkers:       1,
+		EpicLinkField:     "customfield_10014",
+		Fields:            baseSearchFields,
+		APIVersion:        defaultAPIVersion,
+		AttachmentsDir:    filepath.Join(t.TempDir(), "attachments"),
+		MaxAttachmentSize: 1 << 20,
+	}
+	client := newJiraClient(cfg, srv.Client().Transport)
+	client.retryDelay = time.Millisecond
+	return client, cfg
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// fakeSearch serves total issues named PROJ-1..PROJ-total, capping the page
+// size at maxPage like Jira Cloud does.
+func fakeSearch(total, maxPage int, requests *[]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != apiPrefix(defaultAPIVersion)+"/search" {
+			http.NotFound(w, r)
+			return
+		}
+		startAt, _ := strconv.Atoi(r.URL.Query().Get("startAt"))
+		maxResults, _ := strconv.Atoi(r.URL.Query().Get("maxResults"))
+		*requests = append(*requests, fmt.Sprintf("%d+%d", startAt, maxResults))
+		if maxResults > maxPage {
+			maxResults = maxPage
+		}
+
+		var issues []map[string]interface{}
+		for i := startAt; i < total && len(issues) < maxResults; i++ {
+			issues = append(issues, map[string]interface{}{
+				"key": fmt.Sprintf("PROJ-%d", i+1),
+				"fields": map[string]interface{}{
+					"summary":   fmt.Sprintf("Issue %d", i+1),
+					"issuetype": map[string]string{"name": "Task"},
+					"status":    map[string]string{"name": "To Do"},
+				},
+			})
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"startAt":    startAt,
+			"maxResults": maxResults,
+			"total":      total,
+			"issues":     issues,
+		})
+	}
+}
+
+func TestRunPullPagination(t *testing.T) {
+	tests := []struct {
+		name         string
+		total        int
+		pageSize     int
+		maxPage      int
+		wantRequests []string
+	}{
+		{name: "single page", total: 2, pageSize: 5, maxPage: 100, wantRequests: []string{"0+5"}},
+		{name: "exact pages", total: 4, pageSize: 2, maxPage: 100, wantRequests: []string{"0+2", "2+2"}},
+		{name: "partial last page", total: 5, pageSize: 2, maxPage: 100, wantRequests: []string{"0+2", "2+2", "4+2"}},
+		{name: "server caps page size", total: 5, pageSize: 50, maxPage: 2, wantRequests: []string{"0+50", "2+2", "4+2"}},
+		{name: "no issues", total: 0, pageSize: 2, maxPage: 100, wantRequests: []string{"0+2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []string
+			client, cfg := newTestClient(t, fakeSearch(tt.total, tt.maxPage, &requests))
+			cfg.MaxResults = tt.pageSize
+
+			if err := runPull(context.Background(), client, cfg); err != nil {
+				t.Fatalf("runPull: %v", err)
+			}
+			if got := strings.Join(requests, ","); got != strings.Join(tt.wantRequests, ",") {
+				t.Fatalf("requests = %s, want %s", got, strings.Join(tt.wantRequests, ","))
+			}
+
+			data, err := readIssueFile(cfg.YAMLPath)
+			if err != nil {
+				t.Fatalf("readIssueFile: %v", err)
+			}
+			if len(data.Issues) != tt.total {
+				t.Fatalf("wrote %d issues, want %d", len(data.Issues), tt.total)
+			}
+			for i, issue := range data.Issues {
+				if want := fmt.Sprintf("PROJ-%d", i+1); issue.Key != want {
+					t.Fatalf("issue %d key = %s, want %s", i, issue.Key, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRunPullRejectsIgnoredStartAt(t *testing.T) {
+	client, cfg := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"startAt": 0, "maxResults": 2, "total": 4,
+			"issues": []map[string]interface{}{{"key": "PROJ-1"}, {"key": "PROJ-2"}},
+		})
+	}))
+
+	if err := runPull(context.Background(), client, cfg); err == nil {
+		t.Fatal("expected an error when Jira ignores startAt")
+	}
+}
+
+// fakeUpdate accepts PUT /issue/PROJ-1 unless the body contains a field named
+// in reject, in which case it answers 400 naming that field as Jira does.
+func fakeUpdate(reject []string, attempts *[]map[string]interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != apiPrefix(defaultAPIVersion)+"/issue/PROJ-1" {
+			http.NotFound(w, r)
+			return
+		}
+		var body struct {
+			Fields map[string]interface{} `json:"fields"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		*attempts = append(*attempts, body.Fields)
+		for _, field := range reject {
+			if _, ok := body.Fields[field]; ok {
+				writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+					"errors": map[string]string{field: "Field '" + field + "' cannot be set."},
+				})
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestUpdateIssueFallbacks(t *testing.T) {
+	tests := []struct {
+		name         string
+		issue        issueRecord
+		reject       []string
+		wantAttempts int
+		wantFields   []string
+		wantAbsent   []string
+		wantErr      bool
+	}{
+		{
+			name:         "accepted as is",
+			issue:        issueRecord{Key: "PROJ-1", Summary: "s", Priority: "High", ParentKey: "PROJ-9"},
+			wantAttempts: 1,
+			wantFields:   []string{"priority", "parent"},
+		},
+		{
+			name:         "parent falls back to epic link",
+			issue:        issueRecord{Key: "PROJ-1", Summary: "s", ParentKey: "PROJ-9"},
+			reject:       []string{"parent"},
+			wantAttempts: 2,
+			wantFields:   []string{"customfield_10014"},
+			wantAbsent:   []string{"parent"},
+		},
+		{
+			name:         "parent and epic link both rejected",
+			issue:        issueRecord{Key: "PROJ-1", Summary: "s", ParentKey: "PROJ-9"},
+			reject:       []string{"parent", "customfield_10014"},
+			wantAttempts: 3,
+			wantAbsent:   []string{"parent", "customfield_10014"},
+		},
+		{
+			name:         "priority dropped",
+			issue:        issueRecord{Key: "PROJ-1", Summary: "s", Priority: "Urgent"},
+			reject:       []string{"priority"},
+			wantAttempts: 2,
+			wantAbsent:   []string{"priority"},
+		},
+		{
+			name:         "unrelated error is returned",
+			issue:        issueRecord{Key: "PROJ-1", Summary: "s"},
+			reject:       []string{"summary"},
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts []map[string]interface{}
+			client, cfg := newTestClient(t, fakeUpdate(tt.reject, &attempts))
+
+			err := updateIssue(context.Background(), client, cfg, tt.issue)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("updateIssue error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if len(attempts) != tt.wantAttempts {
+				t.Fatalf("made %d attempts, want %d", len(attempts), tt.wantAttempts)
+			}
+			last := attempts[len(attempts)-1]
+			for _, field := range tt.wantFields {
+				if _, ok := last[field]; !ok {
+					t.Errorf("last attempt is missing %s: %v", field, last)
+				}
+			}
+			for _, field := range tt.wantAbsent {
+				if _, ok := last[field]; ok {
+					t.Errorf("last attempt still sends %s: %v", field, last)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateIssueParentFallback(t *testing.T) {
+	var attempts []map[string]interface{}
+	client, cfg := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case apiPrefix(defaultAPIVersion) + "/issue/createmeta":
+			writeJSON(w, http.StatusOK, map[string]interface{}{
+				"projects": []map[string]interface{}{{
+					"key":        "PROJ",
+					"issuetypes": []map[string]string{{"id": "10001", "name": "Task"}},
+				}},
+			})
+		case apiPrefix(defaultAPIVersion) + "/issue":
+			var body struct {
+				Fields map[string]interface{} `json:"fields"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			attempts = append(attempts, body.Fields)
+			if _, ok := body.Fields["parent"]; ok {
+				writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+					"errors": map[string]string{"parent": "Given parent work item does not belong to appropriate hierarchy."},
+				})
+				return
+			}
+			writeJSON(w, http.StatusCreated, map[string]string{"key": "PROJ-7"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	key, err := createIssue(context.Background(), client, cfg, issueRecord{Summary: "New", ParentKey: "PROJ-1"})
+	if err != nil {
+		t.Fatalf("createIssue: %v", err)
+	}
+	if key != "PROJ-7" {
+		t.Fatalf("key = %s, want PROJ-7", key)
+	}
+	if len(attempts) != 2 {
+		t.Fatalf("made %d attempts, want 2", len(attempts))
+	}
+	if got := attempts[1]["customfield_10014"]; got != "PROJ-1" {
+		t.Fatalf("retry epic link = %v, want PROJ-1", got)
+	}
+	if got := attempts[0]["issuetype"]; fmt.Sprint(got) != "map[id:10001]" {
+		t.Fatalf("issuetype = %v, want the id from createmeta", got)
+	}
+}
+
+func TestRateLimitRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		limited   int
+		wantCalls int
+		wantErr   bool
+	}{
+		{name: "no rate limit", limited: 0, wantCalls: 1},
+		{name: "recovers after retries", limited: 2, wantCalls: 3},
+		{name: "gives up", limited: maxRateLimitRetries + 1, wantCalls: maxRateLimitRetries + 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			var bodies []string
+			client, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				b, _ := io.ReadAll(r.Body)
+				bodies = append(bodies, string(b))
+				if calls <= tt.limited {
+					w.Header().Set("Retry-After", "0")
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+				w.WriteHeader(http.StatusNoContent)
+			}))
+
+			err := client.updateIssue(context.Background(), "PROJ-1", map[string]interface{}{"summary": "s"}, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("updateIssue error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Fatalf("server saw %d calls, want %d", calls, tt.wantCalls)
+			}
+			for i, body := range bodies {
+				if !strings.Contains(body, `"summary":"s"`) {
+					t.Fatalf("call %d lost the request body: %q", i+1, body)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimitRetryStopsOnCancel(t *testing.T) {
+	client, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := client.deleteIssue(ctx, "PROJ-1"); err == nil {
+		t.Fatal("expected an error once the context is done")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("waited %s despite cancellation", elapsed)
+	}
+}
+
+func TestUpdateIssueAssignee(t *testing.T) {
+	tests := []struct {
+		name  string
+		issue issueRecord
+		want  string // fmt.Sprint of the assignee sent, "" when absent
+	}{
+		{name: "empty leaves assignee alone", issue: issueRecord{Key: "PROJ-1", Summary: "s"}},
+		{name: "account id assigns", issue: issueRecord{Key: "PROJ-1", Summary: "s", AssigneeAccountID: "abc"}, want: "map[accountId:abc]"},
+		{name: "unassign clears", issue: issueRecord{Key: "PROJ-1", Summary: "s", Unassign: true}, want: "map[accountId:<nil>]"},
+		{name: "unassign wins over account id", issue: issueRecord{Key: "PROJ-1", Summary: "s", Unassign: true, AssigneeAccountID: "abc"}, want: "map[accountId:<nil>]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts []map[string]interface{}
+			client, cfg := newTestClient(t, fakeUpdate(nil, &attempts))
+
+			if err := updateIssue(context.Background(), client, cfg, tt.issue); err != nil {
+				t.Fatalf("updateIssue: %v", err)
+			}
+			assignee, ok := attempts[0]["assignee"]
+			got := ""
+			if ok {
+				got = fmt.Sprint(assignee)
+			}
+			if got != tt.want {
+				t.Fatalf("assignee = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServerAPIPlainDescriptions(t *testing.T) {
+	var sent interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/rest/api/2/issue/PROJ-1" {
+			http.NotFound(w, r)
+			return
+		}
+		var body struct {
+			Fields map[string]interface{} `json:"fields"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		sent = body.Fields["description"]
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+	cfg := config{BaseURL: srv.URL, ProjectKey: "PROJ", APIVersion: 2}
+	client := newJiraClient(cfg, srv.Client().Transport)
+
+	if err := updateIssue(context.Background(), client, cfg, issueRecord{Key: "PROJ-1", Summary: "s", Description: "h1. Plan\n* step"}); err != nil {
+		t.Fatalf("updateIssue: %v", err)
+	}
+	if sent != "h1. Plan\n* step" {
+		t.Fatalf("description sent = %#v, want the plain string", sent)
+	}
+
+	for raw, want := range map[string]string{`"h1. Plan"`: "h1. Plan", `null`: "", ``: ""} {
+		record, err := issueToRecord(jiraIssue{Key: "PROJ-1", Fields: jiraFields{Description: json.RawMessage(raw)}}, config{APIVersion: 2})
+		if err != nil || record.Description != want {
+			t.Fatalf("issueToRecord(%q) description = %q, %v; want %q", raw, record.Description, err, want)
+		}
+	}
+}
+
+func TestRunPullFetchesTruncatedWorklogs(t *testing.T) {
+	worklog := func(id string) map[string]interface{} {
+		return map[string]interface{}{
+			"id": id, "timeSpentSeconds": 3600, "started": "2024-05-01T09:00:00.000+0000",
+			"author": map[string]string{"displayName": "Ada"},
+		}
+	}
+	client, cfg := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case apiPrefix(defaultAPIVersion) + "/search":
+			writeJSON(w, http.StatusOK, map[string]interface{}{
+				"startAt": 0, "maxResults": 2, "total": 1,
+				"issues": []map[string]interface{}{{
+					"key": "PROJ-1",
+					"fields": map[string]interface{}{
+						"summary": "s",
+						"worklog": map[string]interface{}{"total": 2, "worklogs": []interface{}{worklog("1")}},
+					},
+				}},
+			})
+		case apiPrefix(defaultAPIVersion) + "/issue/PROJ-1/worklog":
+			writeJSON(w, http.StatusOK, map[string]interface{}{
+				"startAt": 0, "total": 2, "worklogs": []interface{}{worklog("1"), worklog("2")},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	if err := runPull(context.Background(), client, cfg); err != nil {
+		t.Fatalf("runPull: %v", err)
+	}
+	data, err := readIssueFile(cfg.YAMLPath)
+	if err != nil {
+		t.Fatalf("readIssueFile: %v", err)
+	}
+	worklogs := data.Issues[0].Worklogs
+	if len(worklogs) != 2 || worklogs[1].ID != "2" || worklogs[1].Author != "Ada" || worklogs[1].TimeSpentSeconds != 3600 {
+		t.Fatalf("worklogs = %+v, want both entries from the worklog endpoint", worklogs)
+	}
+}
+
+func TestRunPushAddsNewWorklogs(t *testing.T) {
+	var added []map[string]interface{}
+	client, cfg := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == apiPrefix(defaultAPIVersion)+"/issue/PROJ-1":
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodPost && r.URL.Path == apiPrefix(defaultAPIVersion)+"/issue/PROJ-1/worklog":
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			added = append(added, body)
+			writeJSON(w, http.StatusCreated, map[string]string{"id": "77"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	yamlDoc := `issues:
+    - key: PROJ-1
+      summary: s
+      worklogs:
+        - id: "10"
+          timeSpentSeconds: 60
+        # pairing session
+        - timeSpentSeconds: 1800
+          comment: Pairing
+`
+	if err := os.WriteFile(cfg.YAMLPath, []byte(yamlDoc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runPush(context.Background(), client, cfg); err != nil {
+		t.Fatalf("runPush: %v", err)
+	}
+	if len(added) != 1 || added[0]["timeSpentSeconds"] != float64(1800) || added[0]["comment"] == nil {
+		t.Fatalf("added worklogs = %v, want only the entry without an id", added)
+	}
+	content, err := os.ReadFile(cfg.YAMLPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "# pairing session\n        - id: \"77\"") {
+		t.Fatalf("YAML does not record the new worklog id:\n%s", content)
+	}
+}
+
+func TestPushLinks(t *testing.T) {
+	var calls []string
+	client, cfg := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefix := apiPrefix(defaultAPIVersion)
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == prefix+"/issue/PROJ-1":
+			writeJSON(w, http.StatusOK, map[string]interface{}{"fields": map[string]interface{}{"issuelinks": []map[string]interface{}{
+				{"id": "100", "type": map[string]string{"name": "Blocks"}, "outwardIssue": map[string]string{"key": "PROJ-2"}},
+				{"id": "101", "type": map[string]string{"name": "Relates"}, "inwardIssue": map[string]string{"key": "PROJ-3"}},
+			}}})
+		case r.Method == http.MethodGet && r.URL.Path == prefix+"/issueLinkType":
+			calls = append(calls, "types")
+			writeJSON(w, http.StatusOK, map[string]interface{}{"issueLinkTypes": []map[string]string{
+				{"id": "1", "name": "Blocks"}, {"id": "2", "name": "Duplicate"},
+			}})
+		case r.Method == http.MethodPost && r.URL.Path == prefix+"/issueLink":
+			var body struct {
+				Type         struct{ ID string }  `json:"type"`
+				InwardIssue  struct{ Key string } `json:"inwardIssue"`
+				OutwardIssue struct{ Key string } `json:"outwardIssue"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			calls = append(calls, fmt.Sprintf("link %s %s->%s", body.Type.ID, body.InwardIssue.Key, body.OutwardIssue.Key))
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, prefix+"/issueLink/"):
+			calls = append(calls, "unlink "+strings.TrimPrefix(r.URL.Path, prefix+"/issueLink/"))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	issue := issueRecord{Key: "PROJ-1", Links: []linkRecord{
+		{Type: "blocks", Direction: "outward", Key: "PROJ-2"},
+		{Type: "Duplicate", Direction: "inward", Key: "PROJ-4"},
+	}}
+
+	if err := pushLinks(context.Background(), client, cfg, "PROJ-1", issue); err != nil {
+		t.Fatalf("pushLinks: %v", err)
+	}
+	if got, want := strings.Join(calls, ", "), "types, link 2 PROJ-4->PROJ-1"; got != want {
+		t.Fatalf("calls = %s, want %s", got, want)
+	}
+
+	calls = nil
+	cfg.PruneLinks = true
+	issue.Links = issue.Links[:1]
+	if err := pushLinks(context.Background(), client, cfg, "PROJ-1", issue); err != nil {
+		t.Fatalf("pushLinks with pruning: %v", err)
+	}
+	if got, want := strings.Join(calls, ", "), "unlink 101"; got != want {
+		t.Fatalf("calls = %s, want %s", got, want)
+	}
+}
+
+func TestRunPullMarkdown(t *testing.T) {
+	var requests []string
+	client, cfg := newTestClient(t, fakeSearch(2, 100, &requests))
+	cfg.OutputFormat = outputMarkdown
+	cfg.MarkdownDir = t.TempDir()
+	stale := filepath.Join(cfg.MarkdownDir, "PROJ-9.md")
+	draft := filepath.Join(cfg.MarkdownDir, "new-idea.md")
+	for path, content := range map[string]string{stale: "---\nkey: PROJ-9\nsummary: Gone\n---\n", draft: "---\nsummary: Idea\n---\n\nWrite it down.\n"} {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := runPull(context.Background(), client, cfg); err != nil {
+		t.Fatalf("runPull: %v", err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Fatalf("stale file still exists: %v", err)
+	}
+	data, err := readIssues(cfg)
+	if err != nil {
+		t.Fatalf("readIssues: %v", err)
+	}
+	var keys []string
+	for _, issue := range data.Issues {
+		keys = append(keys, issue.Key+"="+issue.Summary)
+	}
+	if got, want := strings.Join(keys, ","), "PROJ-1=Issue 1,PROJ-2=Issue 2,=Idea"; got != want {
+		t.Fatalf("issues = %s, want %s", got, want)
+	}
+	if data.Issues[2].Description != "Write it down." {
+		t.Fatalf("draft description = %q, want the Markdown body", data.Issues[2].Description)
+	}
+}
+
+func TestMarkdownIssueRoundTrip(t *testing.T) {
+	record := issueRecord{Key: "PROJ-1", Summary: "s", Description: "First line\n\n- item", Labels: []string{"a"}}
+	content, err := formatMarkdownIssue(record)
+	if err != nil {
+		t.Fatalf("formatMarkdownIssue: %v", err)
+	}
+	if !strings.HasSuffix(string(content), "---\n\nFirst line\n\n- item\n") {
+		t.Fatalf("description is not the body:\n%s", content)
+	}
+	got, err := parseMarkdownIssue(content)
+	if err != nil {
+		t.Fatalf("parseMarkdownIssue: %v", err)
+	}
+	if fmt.Sprint(got) != fmt.Sprint(record) {
+		t.Fatalf("round trip = %+v, want %+v", got, record)
+	}
+	if _, err := parseMarkdownIssue([]byte("---\nsummary: s\nbogus: 1\n---\n")); err == nil {
+		t.Fatal("parseMarkdownIssue accepted an unknown front matter field")
+	}
+}
+
+func TestRunPushSkipsConflicts(t *testing.T) {
+	updates := 0
+	client, cfg := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == apiPrefix(defaultAPIVersion)+"/issue/PROJ-1":
+			writeJSON(w, http.StatusOK, map[string]interface{}{"fields": map[string]string{"updated": "2024-05-02T10:00:00.000+0000"}})
+		case r.Method == http.MethodGet && r.URL.Path == apiPrefix(defaultAPIVersion)+"/issue/PROJ-2":
+			writeJSON(w, http.StatusOK, map[string]interface{}{"fields": map[string]string{"updated": "2024-05-01T12:00:00.000+0200"}})
+		case r.Method == http.MethodPut:
+			updates++
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	yamlDoc := `issues:
+    - key: PROJ-1
+      summary: edited locally
+      updated: "2024-05-01T10:00:00.000+0000"
+    - key: PROJ-2
+      summary: unchanged in Jira
+      updated: "2024-05-01T10:00:00.000+0000"
+`
+	if err := os.WriteFile(cfg.YAMLPath, []byte(yamlDoc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runPush(context.Background(), client, cfg); err == nil || !strings.Contains(err.Error(), "skipped 1 issue") {
+		t.Fatalf("runPush error = %v, want one conflict reported", err)
+	}
+	if updates != 1 {
+		t.Fatalf("made %d updates, want only the unchanged issue", updates)
+	}
+
+	updates = 0
+	cfg.Force = true
+	if err := runPush(context.Background(), client, cfg); err != nil {
+		t.Fatalf("runPush with force: %v", err)
+	}
+	if updates != 2 {
+		t.Fatalf("made %d updates with force, want 2", updates)
+	}
+}
+
+func TestAttachmentSync(t *testing.T) {
+	var uploads []string
+	var srvURL string
+	client, cfg := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefix := apiPrefix(defaultAPIVersion)
+		switch {
+		case r.URL.Path == prefix+"/search":
+			writeJSON(w, http.StatusOK, map[string]interface{}{
+				"startAt": 0, "maxResults": 2, "total": 1,
+				"issues": []map[string]interface{}{{
+					"key": "PROJ-1",
+					"fields": map[string]interface{}{"summary": "s", "attachment": []map[string]interface{}{
+						{"id": "5", "filename": "notes.txt", "size": 5, "content": srvURL + prefix + "/attachment/content/5"},
+						{"id": "6", "filename": "huge.bin", "size": 2 << 20, "content": srvURL + prefix + "/attachment/content/6"},
+						{"id": "7", "filename": "elsewhere.txt", "size": 1, "content": "https://evil.example/7"},
+					}},
+				}},
+			})
+		case r.URL.Path == prefix+"/attachment/content/5":
+			w.Write([]byte("hello"))
+		case r.Method == http.MethodGet && r.URL.Path == prefix+"/issue/PROJ-1":
+			writeJSON(w, http.StatusOK, map[string]interface{}{"fields": map[string]interface{}{"attachment": []map[string]string{{"filename": "notes.txt"}, {"filename": "remote.txt"}}}})
+		case r.Method == http.MethodPost && r.URL.Path == prefix+"/issue/PROJ-1/attachments":
+			if r.Header.Get("X-Atlassian-Token") != "no-check" {
+				http.Error(w, "XSRF check failed", http.StatusForbidden)
+				return
+			}
+			file, header, err := r.FormFile("file")
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			content, _ := io.ReadAll(file)
+			uploads = append(uploads, header.Filename+"="+string(content))
+			writeJSON(w, http.StatusOK, []interface{}{})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	srvURL = cfg.BaseURL
+	cfg.DownloadAttachments = true
+
+	if err := runPull(context.Background(), client, cfg); err != nil {
+		t.Fatalf("runPull: %v", err)
+	}
+	dir := filepath.Join(cfg.AttachmentsDir, "PROJ-1")
+	if content, err := os.ReadFile(filepath.Join(dir, "notes.txt")); err != nil || string(content) != "hello" {
+		t.Fatalf("notes.txt = %q, %v; want the downloaded content", content, err)
+	}
+	for _, skipped := range []string{"huge.bin", "elsewhere.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, skipped)); !os.IsNotExist(err) {
+			t.Fatalf("%s was downloaded: %v", skipped, err)
+		}
+	}
+
+	for name, content := range map[string]string{"remote.txt": "x", "plan.md": "# Plan"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	issue := issueRecord{Key: "PROJ-1", Attachments: []string{"notes.txt", "huge.bin", "elsewhere.txt"}}
+	if err := pushAttachments(context.Background(), client, cfg, "PROJ-1", issue); err != nil {
+		t.Fatalf("pushAttachments: %v", err)
+	}
+	if got := strings.Join(uploads, ","); got != "plan.md=# Plan" {
+		t.Fatalf("uploads = %s, want only the new local file", got)
+	}
+}
+
+func TestRunPullNested(t *testing.T) {
+	issue := func(key, issueType string, subtask bool, parent string) map[string]interface{} {
+		fields := map[string]interface{}{"summary": key, "issuetype": map[string]interface{}{"name": issueType, "subtask": subtask}}
+		if parent != "" {
+			fields["parent"] = map[string]string{"key": parent}
+		}
+		return map[string]interface{}{"key": key, "fields": fields}
+	}
+	client, cfg := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"startAt": 0, "maxResults": 10, "total": 4,
+			"issues": []map[string]interface{}{
+				issue("PROJ-2", "Sub-task", true, "PROJ-1"),
+				issue("PROJ-1", "Task", false, ""),
+				issue("PROJ-3", "Sub-task", true, "PROJ-99"),
+				issue("PROJ-4", "Story", false, "PROJ-1"),
+			},
+		})
+	}))
+	cfg.Nested = true
+
+	if err := runPull(context.Background(), client, cfg); err != nil {
+		t.Fatalf("runPull: %v", err)
+	}
+	data, err := readIssueFile(cfg.YAMLPath)
+	if err != nil {
+		t.Fatalf("readIssueFile: %v", err)
+	}
+	var layout []string
+	for _, item := range flattenIssues(data.Issues) {
+		layout = append(layout, fmt.Sprintf("%v:%s<%s", item.path, item.record.Key, item.record.ParentKey))
+	}
+	if got, want := strings.Join(layout, " "), "[0]:PROJ-1< [0 0]:PROJ-2<PROJ-1 [1]:PROJ-3<PROJ-99 [2]:PROJ-4<PROJ-1"; got != want {
+		t.Fatalf("layout = %s, want %s", got, want)
+	}
+	if data.Issues[0].Subtasks[0].ParentKey != "" {
+		t.Fatalf("nested subtask kept its parent key: %+v", data.Issues[0].Subtasks[0])
+	}
+}
+
+func TestRunPushNestedSubtasks(t *testing.T) {
+	var created []string
+	var deleted []string
+	client, cfg := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefix := apiPrefix(defaultAPIVersion)
+		switch {
+		case r.URL.Path == prefix+"/issue/createmeta":
+			writeJSON(w, http.StatusOK, map[string]interface{}{"projects": []interface{}{}})
+		case r.URL.Path == prefix+"/issuetype":
+			writeJSON(w, http.StatusOK, []interface{}{})
+		case r.Method == http.MethodPost && r.URL.Path == prefix+"/issue":
+			var body struct {
+				Fields struct {
+					Summary string            `json:"summary"`
+					Parent  map[string]string `json:"parent"`
+				} `json:"fields"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			key := fmt.Sprintf("PROJ-%d", 10+len(created))
+			created = append(created, body.Fields.Summary+"<"+body.Fields.Parent["key"])
+			writeJSON(w, http.StatusCreated, map[string]string{"key": key})
+		case r.Method == http.MethodPut:
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodDelete:
+			deleted = append(deleted, strings.TrimPrefix(r.URL.Path, prefix+"/issue/"))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	yamlDoc := `issues:
+    - summary: Epic work
+      subtasks:
+        - summary: First step
+    - key: PROJ-1
+      summary: Existing
+      subtasks:
+        - key: PROJ-2
+          summary: Obsolete
+          delete: true
+        - key: PROJ-3
+          summary: Kept
+`
+	if err := os.WriteFile(cfg.YAMLPath, []byte(yamlDoc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runPush(context.Background(), client, cfg); err != nil {
+		t.Fatalf("runPush: %v", err)
+	}
+	if got, want := strings.Join(created, ","), "Epic work<,First step<PROJ-10"; got != want {
+		t.Fatalf("created = %s, want %s", got, want)
+	}
+	if got := strings.Join(deleted, ","); got != "PROJ-2" {
+		t.Fatalf("deleted = %s, want PROJ-2", got)
+	}
+	data, err := readIssueFile(cfg.YAMLPath)
+	if err != nil {
+		t.Fatalf("readIssueFile: %v", err)
+	}
+	var layout []string
+	for _, item := range flattenIssues(data.Issues) {
+		layout = append(layout, fmt.Sprintf("%v:%s", item.path, item.record.Key))
+	}
+	if got, want := strings.Join(layout, " "), "[0]:PROJ-10 [0 0]:PROJ-11 [1]:PROJ-1 [1 0]:PROJ-3"; got != want {
+		t.Fatalf("recorded layout = %s, want %s", got, want)
+	}
+}
+
+func TestCustomFieldsRoundTrip(t *testing.T) {
+	var sent map[string]interface{}
+	client, cfg := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == apiPrefix(defaultAPIVersion)+"/search":
+			if fields := r.URL.Query().Get("fields"); !strings.Contains(fields, "customfield_10016") {
+				t.Errorf("search fields = %s, want the aliased custom field", fields)
+			}
+			writeJSON(w, http.StatusOK, map[string]interface{}{
+				"startAt": 0, "maxResults": 2, "total": 1,
+				"issues": []map[string]interface{}{{
+					"key": "PROJ-1",
+					"fields": map[string]interface{}{
+						"summary":           "s",
+						"customfield_10016": 5,
+						"customfield_10020": map[string]interface{}{"value": "Blue", "child": map[string]interface{}{"value": "Navy"}},
+						"customfield_10030": nil,
+					},
+				}},
+			})
+		case r.Method == http.MethodPut:
+			var body struct {
+				Fields map[string]interface{} `json:"fields"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			sent = body.Fields
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	cfg.CustomFieldAliases = map[string]string{"storyPoints": "customfield_10016"}
+	cfg.Fields = append(append([]string(nil), baseSearchFields...), "customfield_10016")
+	client.searchFields = strings.Join(cfg.Fields, ",")
+
+	if err := runPull(context.Background(), client, cfg); err != nil {
+		t.Fatalf("runPull: %v", err)
+	}
+	data, err := readIssueFile(cfg.YAMLPath)
+	if err != nil {
+		t.Fatalf("readIssueFile: %v", err)
+	}
+	custom := data.Issues[0].CustomFields
+	if _, ok := custom["customfield_10030"]; ok || len(custom) != 2 || custom["storyPoints"] != 5 {
+		t.Fatalf("customFields = %v, want storyPoints and the unaliased option", custom)
+	}
+
+	if err := updateIssue(context.Background(), client, cfg, data.Issues[0]); err != nil {
+		t.Fatalf("updateIssue: %v", err)
+	}
+	if got, _ := json.Marshal(sent["customfield_10020"]); string(got) != `{"child":{"value":"Navy"},"value":"Blue"}` {
+		t.Fatalf("customfield_10020 sent = %s, want it unchanged", got)
+	}
+	if sent["customfield_10016"] != float64(5) {
+		t.Fatalf("customfield_10016 sent = %v, want 5", sent["customfield_10016"])
+	}
+
+	data.Issues[0].CustomFields = map[string]interface{}{"points": 3}
+	if err := updateIssue(context.Background(), client, cfg, data.Issues[0]); err == nil {
+		t.Fatal("updateIssue accepted an unknown custom field alias")
+	}
+}
+
+func TestLoadConfigReportsEveryProblem(t *testing.T) {
+	for _, name := range []string{"JIRA_BASE_URL", "JIRA_EMAIL", "JIRA_API_TOKEN", "JIRA_PROJECT_KEY", "JIRA_MAX_RESULTS", "JIRA_TIMEOUT", "JIRA_API_VERSION"} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("JIRA_MAX_RESULTS", "many")
+	t.Setenv("JIRA_TIMEOUT", "-1s")
+	t.Setenv("JIRA_API_VERSION", "4")
+
+	_, err := loadConfig(fileConfig{YAMLPath: filepath.Join(t.TempDir(), "tasks.yaml")})
+	if err == nil {
+		t.Fatal("loadConfig() succeeded without required settings")
+	}
+	for _, name := range []string{"JIRA_BASE_URL", "JIRA_EMAIL", "JIRA_API_TOKEN", "JIRA_PROJECT_KEY", "JIRA_MAX_RESULTS", "JIRA_TIMEOUT", "JIRA_API_VERSION"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("error does not mention %s:\n%v", name, err)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats for JIRA_OUTPUT_FORMAT.
+const (
+	outputYAML     = "yaml"
+	outputMarkdown = "markdown"
+)
+
+const frontMatterDelimiter = "---"
+
+// readIssues reads the issues from the configured YAML file or Markdown
+// directory.
+func readIssues(cfg config) (issueFile, error) {
+	if cfg.OutputFormat != outputMarkdown {
+		return readIssueFile(cfg.YAMLPath)
+	}
+	files, err := readMarkdownDir(cfg.MarkdownDir)
+	if err != nil {
+		return issueFile{}, err
+	}
+	data := issueFile{Issues: make([]issueRecord, len(files))}
+	for i, file := range files {
+		data.Issues[i] = file.record
+	}
+	return data, nil
+}
+
+type markdownFile struct {
+	path   string
+	record issueRecord
+}
+
+// readMarkdownDir parses every .md file in dir, ordered by name. A missing
+// directory holds no issues.
+func readMarkdownDir(dir string) ([]markdownFile, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read markdown directory: %w", err)
+	}
+	var files []markdownFile
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".md" {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", path, err)
+		}
+		record, err := parseMarkdownIssue(content)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+		if len(record.Subtasks) > 0 {
+			return nil, fmt.Errorf("parse %s: subtasks can only be nested in the YAML file; give each its own file with a parent", path)
+		}
+		files = append(files, markdownFile{path: path, record: record})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].path < files[j].path })
+	return files, nil
+}
+
+// parseMarkdownIssue reads an issue written by formatMarkdownIssue: the
+// fields as YAML front matter between "---" lines, then the description.
+func parseMarkdownIssue(content []byte) (issueRecord, error) {
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+	rest, ok := strings.CutPrefix(text, frontMatterDelimiter+"\n")
+	if !ok {
+		return issueRecord{}, errors.New("missing front matter")
+	}
+	var frontMatter, body string
+	if strings.HasPrefix(rest, frontMatterDelimiter+"\n") || rest == frontMatterDelimiter {
+		body = strings.TrimPrefix(rest, frontMatterDelimiter)
+	} else {
+		frontMatter, body, ok = strings.Cut(rest, "\n"+frontMatterDelimiter+"\n")
+		if !ok {
+			frontMatter, ok = strings.CutSuffix(rest, "\n"+frontMatterDelimiter)
+		}
+		if !ok {
+			return issueRecord{}, errors.New("unterminated front matter")
+		}
+	}
+
+	var record issueRecord
+	dec := yaml.NewDecoder(strings.NewReader(frontMatter))
+	dec.KnownFields(true)
+	if err := dec.Decode(&record); err != nil && !errors.Is(err, io.EOF) {
+		return issueRecord{}, fmt.Errorf("parse front matter: %w", err)
+	}
+	if body = strings.TrimSpace(body); body != "" {
+		record.Description = body
+	}
+	return record, nil
+}
+
+// formatMarkdownIssue renders record as front matter followed by its
+// description.
+func formatMarkdownIssue(record issueRecord) ([]byte, error) {
+	description := record.Description
+	record.Description = ""
+
+	var buf bytes.Buffer
+	buf.WriteString(frontMatterDelimiter + "\n")
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(4)
+	if err := enc.Encode(record); err != nil {
+		return nil, fmt.Errorf("marshal front matter: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("marshal front matter: %w", err)
+	}
+	buf.WriteString(frontMatterDelimiter + "\n")
+	if description != "" {
+		buf.WriteString("\n" + description + "\n")
+	}
+	return buf.Bytes(), nil
+}
+
+func writeMarkdownIssue(path string, record issueRecord) error {
+	content, err := formatMarkdownIssue(record)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(path, content); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return nil
+}
+
+// runPullMarkdown writes each issue matching the JQL to <key>.md in the
+// Markdown directory. Files of issues Jira no longer returns are removed once
+// every page has arrived; files without a key are new issues and stay.
+func runPullMarkdown(ctx context.Context, client *jiraClient, cfg config) error {
+	existing, err := readMarkdownDir(cfg.MarkdownDir)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Fetching issues from Jira...")
+	written := make(map[string]bool)
+	err = forEachIssuePage(ctx, client, cfg.JQL, cfg.MaxResults, func(issues []jiraIssue, total int) error {
+		for _, issue := range issues {
+			record, err := issueToRecord(issue, cfg)
+			if err != nil {
+				return err
+			}
+			if cfg.DownloadAttachments {
+				if err := downloadAttachments(ctx, client, cfg, issue); err != nil {
+					return err
+				}
+			}
+			path := filepath.Join(cfg.MarkdownDir, record.Key+".md")
+			if err := writeMarkdownIssue(path, record); err != nil {
+				return err
+			}
+			written[path] = true
+		}
+		fmt.Printf("Fetched %d / %d\n", len(written), total)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, file := range existing {
+		if file.record.Key != "" && !written[file.path] {
+			if err := os.Remove(file.path); err != nil {
+				return fmt.Errorf("remove %s: %w", file.path, err)
+			}
+		}
+	}
+	fmt.Printf("Wrote %d issue(s) to %s\n", len(written), cfg.MarkdownDir)
+	return nil
+}
+
+// recordMarkdownPushResults is recordPushResults for the Markdown directory:
+// deleted issues lose their file and the others get their new keys and
+// worklog IDs written into the front matter.
+func recordMarkdownPushResults(dir string, results pushResults) error {
+	files, err := readMarkdownDir(dir)
+	if err != nil {
+		return err
+	}
+	for idx, path := range results.paths {
+		// Markdown files never nest subtasks, so each path is one index.
+		if path[0] >= len(files) {
+			continue
+		}
+		file := files[path[0]]
+		if !results.kept[idx] {
+			if err := os.Remove(file.path); err != nil {
+				return fmt.Errorf("remove %s: %w", file.path, err)
+			}
+			continue
+		}
+		changed := false
+		if results.createdKeys[idx] != "" {
+			file.record.Key = results.createdKeys[idx]
+			changed = true
+		}
+		for n, id := range results.worklogIDs[idx] {
+			if id != "" && n < len(file.record.Worklogs) {
+				file.record.Worklogs[n].ID = id
+				changed = true
+			}
+		}
+		if changed {
+			if err := writeMarkdownIssue(file.path, file.record); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
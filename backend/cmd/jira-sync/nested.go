@@ -0,0 +1,60 @@
+package main
+
+// flatIssue is an issue from the local file with any subtasks nested under
+// it pulled out into a list of their own.
+type flatIssue struct {
+	record issueRecord
+	path   []int // index in the issues list, then in each enclosing subtasks list
+	parent int   // index of the enclosing issue in the flat list; -1 at the top level
+}
+
+// flattenIssues lists every issue, each parent followed by its subtasks. A
+// nested subtask without a parent of its own gets the enclosing issue's key.
+func flattenIssues(issues []issueRecord) []flatIssue {
+	var flat []flatIssue
+	var walk func(issues []issueRecord, prefix []int, parent int)
+	walk = func(issues []issueRecord, prefix []int, parent int) {
+		for i, issue := range issues {
+			subtasks := issue.Subtasks
+			issue.Subtasks = nil
+			if parent >= 0 && issue.ParentKey == "" {
+				issue.ParentKey = flat[parent].record.Key
+			}
+			path := append(append([]int(nil), prefix...), i)
+			flat = append(flat, flatIssue{record: issue, path: path, parent: parent})
+			walk(subtasks, path, len(flat)-1)
+		}
+	}
+	walk(issues, nil, -1)
+	return flat
+}
+
+// nestSubtasks moves each subtask under its parent's record, dropping the
+// now implied parent key. Subtasks whose parent is not among records stay at
+// the top level.
+func nestSubtasks(records []issueRecord, subtasks map[string]bool) []issueRecord {
+	byKey := make(map[string]int, len(records))
+	for i, record := range records {
+		if !subtasks[record.Key] {
+			byKey[record.Key] = i
+		}
+	}
+	children := make(map[int][]issueRecord)
+	var top []int
+	for i, record := range records {
+		if parent, ok := byKey[record.ParentKey]; ok && subtasks[record.Key] {
+			record.ParentKey = ""
+			children[parent] = append(children[parent], record)
+			continue
+		}
+		top = append(top, i)
+	}
+
+	nested := make([]issueRecord, 0, len(top))
+	for _, i := range top {
+		record := records[i]
+		record.Subtasks = children[i]
+		nested = append(nested, record)
+	}
+	return nested
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// worklogRecord is one time-tracking entry in the YAML. Entries with an ID
+// mirror Jira and are never pushed; entries without one are added on push.
+type worklogRecord struct {
+	ID               string `yaml:"id,omitempty"`
+	Author           string `yaml:"author,omitempty"`
+	TimeSpentSeconds int    `yaml:"timeSpentSeconds"`
+	Started          string `yaml:"started,omitempty"`
+	Comment          string `yaml:"comment,omitempty"`
+}
+
+// jiraWorklogPage is both the worklog field embedded in search results,
+// which Jira truncates to the first 20 entries, and the worklog endpoint's
+// response.
+type jiraWorklogPage struct {
+	StartAt    int           `json:"startAt"`
+	MaxResults int           `json:"maxResults"`
+	Total      int           `json:"total"`
+	Worklogs   []jiraWorklog `json:"worklogs"`
+}
+
+type jiraWorklog struct {
+	ID     string `json:"id"`
+	Author *struct {
+		DisplayName string `json:"displayName"`
+	} `json:"author"`
+	TimeSpentSeconds int             `json:"timeSpentSeconds"`
+	Started          string          `json:"started"`
+	Comment          json.RawMessage `json:"comment"`
+}
+
+// completeWorklogs replaces the truncated worklog list search returned for
+// issue with the full one.
+func (c *jiraClient) completeWorklogs(ctx context.Context, issue *jiraIssue) error {
+	page := issue.Fields.Worklog
+	if page == nil || len(page.Worklogs) >= page.Total {
+		return nil
+	}
+	worklogs, err := c.listWorklogs(ctx, issue.Key)
+	if err != nil {
+		return fmt.Errorf("list worklogs for %s: %w", issue.Key, err)
+	}
+	issue.Fields.Worklog = &jiraWorklogPage{Total: len(worklogs), Worklogs: worklogs}
+	return nil
+}
+
+func (c *jiraClient) listWorklogs(ctx context.Context, key string) ([]jiraWorklog, error) {
+	var worklogs []jiraWorklog
+	for {
+		query := url.Values{}
+		query.Set("startAt", strconv.Itoa(len(worklogs)))
+		req, err := c.newRequest(ctx, http.MethodGet, c.apiPrefix+"/issue/"+key+"/worklog", query, nil)
+		if err != nil {
+			return nil, err
+		}
+		var page jiraWorklogPage
+		if err := c.do(req, &page); err != nil {
+			return nil, err
+		}
+		worklogs = append(worklogs, page.Worklogs...)
+		if len(page.Worklogs) == 0 || len(worklogs) >= page.Total {
+			return worklogs, nil
+		}
+	}
+}
+
+func (c *jiraClient) addWorklog(ctx context.Context, key string, body map[string]interface{}) (string, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, c.apiPrefix+"/issue/"+key+"/worklog", nil, body)
+	if err != nil {
+		return "", err
+	}
+	var resp struct {
+		ID string `json:"id"`
+	}
+	if err := c.do(req, &resp); err != nil {
+		return "", err
+	}
+	if resp.ID == "" {
+		return "", errors.New("jira did not return a worklog id")
+	}
+	return resp.ID, nil
+}
+
+func worklogRecords(page *jiraWorklogPage, apiVersion int) ([]worklogRecord, error) {
+	if page == nil || len(page.Worklogs) == 0 {
+		return nil, nil
+	}
+	records := make([]worklogRecord, len(page.Worklogs))
+	for i, worklog := range page.Worklogs {
+		comment, err := richText(worklog.Comment, apiVersion)
+		if err != nil {
+			return nil, fmt.Errorf("parse worklog %s comment: %w", worklog.ID, err)
+		}
+		records[i] = worklogRecord{
+			ID:               worklog.ID,
+			TimeSpentSeconds: worklog.TimeSpentSeconds,
+			Started:          worklog.Started,
+			Comment:          comment,
+		}
+		if worklog.Author != nil {
+			records[i].Author = worklog.Author.DisplayName
+		}
+	}
+	return records, nil
+}
+
+// pushWorklogs adds the worklogs of issue that have no ID yet to the Jira
+// issue key, storing each new ID in ids (indexed like issue.Worklogs) as soon
+// as Jira assigns it so an interrupted push does not log the time twice.
+func pushWorklogs(ctx context.Context, client *jiraClient, cfg config, key string, issue issueRecord, ids []string) error {
+	for i, worklog := range issue.Worklogs {
+		if worklog.ID != "" {
+			continue
+		}
+		if worklog.TimeSpentSeconds <= 0 {
+			return fmt.Errorf("worklog %d: timeSpentSeconds must be positive", i+1)
+		}
+		body := map[string]interface{}{"timeSpentSeconds": worklog.TimeSpentSeconds}
+		if worklog.Started != "" {
+			body["started"] = worklog.Started
+		}
+		if worklog.Comment != "" {
+			body["comment"] = richTextField(worklog.Comment, cfg.APIVersion)
+		}
+		id, err := client.addWorklog(ctx, key, body)
+		if err != nil {
+			return fmt.Errorf("add worklog %d: %w", i+1, err)
+		}
+		ids[i] = id
+	}
+	return nil
+}
+
+// newWorklogs counts the worklogs push would add.
+func newWorklogs(issue issueRecord) int {
+	count := 0
+	for _, worklog := range issue.Worklogs {
+		if worklog.ID == "" {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// issueDocument is the issue file kept as a yaml.Node tree, so that writing
+// it back preserves hand-written comments and the order of issues and keys.
+type issueDocument struct {
+	root   *yaml.Node
+	issues *yaml.Node // the sequence under "issues"
+
+	byKey   map[string]*yaml.Node
+	touched map[*yaml.Node]bool
+	added   []*yaml.Node
+}
+
+// loadIssueDocument parses path, or starts an empty document when the file
+// does not exist yet.
+func loadIssueDocument(path string) (*issueDocument, error) {
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("read yaml: %w", err)
+	}
+
+	root := &yaml.Node{}
+	if len(bytes.TrimSpace(content)) > 0 {
+		if err := yaml.Unmarshal(content, root); err != nil {
+			return nil, fmt.Errorf("parse yaml: %w", err)
+		}
+	}
+	if root.Kind == 0 {
+		root = &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if root.Kind != yaml.DocumentNode || len(root.Content) != 1 || root.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("parse yaml: top level must be a mapping with an issues list")
+	}
+
+	top := root.Content[0]
+	issues := mappingValue(top, "issues")
+	if issues == nil {
+		issues = &yaml.Node{}
+		top.Content = append(top.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "issues"}, issues)
+	}
+	switch issues.Kind {
+	case yaml.SequenceNode:
+	case 0, yaml.ScalarNode:
+		if issues.Kind == yaml.ScalarNode && issues.Tag != "!!null" {
+			return nil, errors.New("parse yaml: issues must be a list")
+		}
+		*issues = yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", HeadComment: issues.HeadComment, LineComment: issues.LineComment}
+	default:
+		return nil, errors.New("parse yaml: issues must be a list")
+	}
+
+	doc := &issueDocument{
+		root:    root,
+		issues:  issues,
+		byKey:   make(map[string]*yaml.Node),
+		touched: make(map[*yaml.Node]bool),
+	}
+	for _, item := range issues.Content {
+		if item.Kind != yaml.MappingNode {
+			continue
+		}
+		if key := mappingValue(item, "key"); key != nil && key.Value != "" {
+			doc.byKey[key.Value] = item
+		}
+	}
+	return doc, nil
+}
+
+// merge folds a record fetched from Jira into the document. An existing entry
+// with the same key keeps its position, its comments and the order of its
+// fields; fields the record no longer has are dropped and new ones appended.
+// Records for unknown keys are queued to be appended after existing issues.
+func (d *issueDocument) merge(record issueRecord) error {
+	var fresh yaml.Node
+	if err := fresh.Encode(record); err != nil {
+		return fmt.Errorf("marshal yaml: %w", err)
+	}
+
+	existing, ok := d.byKey[record.Key]
+	if !ok || d.touched[existing] {
+		d.added = append(d.added, &fresh)
+		return nil
+	}
+	d.touched[existing] = true
+
+	freshValues := make(map[string]*yaml.Node, len(fresh.Content)/2)
+	for i := 0; i+1 < len(fresh.Content); i += 2 {
+		freshValues[fresh.Content[i].Value] = fresh.Content[i+1]
+	}
+
+	content := make([]*yaml.Node, 0, len(fresh.Content))
+	kept := make(map[string]bool, len(freshValues))
+	for i := 0; i+1 < len(existing.Content); i += 2 {
+		keyNode, oldValue := existing.Content[i], existing.Content[i+1]
+		newValue, ok := freshValues[keyNode.Value]
+		if !ok {
+			continue
+		}
+		newValue.HeadComment = oldValue.HeadComment
+		newValue.LineComment = oldValue.LineComment
+		newValue.FootComment = oldValue.FootComment
+		content = append(content, keyNode, newValue)
+		kept[keyNode.Value] = true
+	}
+	for i := 0; i+1 < len(fresh.Content); i += 2 {
+		if !kept[fresh.Content[i].Value] {
+			content = append(content, fresh.Content[i], fresh.Content[i+1])
+		}
+	}
+	existing.Content = content
+	return nil
+}
+
+// keepOnlyMerged drops every issue that merge did not see, so the document
+// mirrors what Jira returned, and appends the new issues.
+func (d *issueDocument) keepOnlyMerged() {
+	content := make([]*yaml.Node, 0, len(d.touched)+len(d.added))
+	for _, item := range d.issues.Content {
+		if d.touched[item] {
+			content = append(content, item)
+		}
+	}
+	d.issues.Content = append(content, d.added...)
+}
+
+// issueAt returns the entry at path, an index in the issues list followed by
+// indexes into nested subtasks lists, or nil when there is none.
+func (d *issueDocument) issueAt(path []int) *yaml.Node {
+	list := d.issues
+	var item *yaml.Node
+	for _, idx := range path {
+		if list == nil || list.Kind != yaml.SequenceNode || idx < 0 || idx >= len(list.Content) || list.Content[idx].Kind != yaml.MappingNode {
+			return nil
+		}
+		item = list.Content[idx]
+		list = mappingValue(item, "subtasks")
+	}
+	return item
+}
+
+// dropIssues removes the entries at paths, which are taken from the same
+// file before anything is removed.
+func (d *issueDocument) dropIssues(paths [][]int) {
+	drop := make(map[*yaml.Node]bool, len(paths))
+	for _, path := range paths {
+		if item := d.issueAt(path); item != nil {
+			drop[item] = true
+		}
+	}
+	if len(drop) == 0 {
+		return
+	}
+	var prune func(list *yaml.Node)
+	prune = func(list *yaml.Node) {
+		content := make([]*yaml.Node, 0, len(list.Content))
+		for _, item := range list.Content {
+			if drop[item] {
+				continue
+			}
+			if subtasks := mappingValue(item, "subtasks"); subtasks != nil && subtasks.Kind == yaml.SequenceNode {
+				prune(subtasks)
+			}
+			content = append(content, item)
+		}
+		list.Content = content
+	}
+	prune(d.issues)
+}
+
+// setKey sets the key of the issue at path, adding the field first in the
+// entry when it is missing.
+func (d *issueDocument) setKey(path []int, key string) {
+	if item := d.issueAt(path); item != nil {
+		setScalarFirst(item, "key", key)
+	}
+}
+
+// setWorklogID sets the id of worklog n of the issue at path. n indexes the
+// issue's Worklogs.
+func (d *issueDocument) setWorklogID(path []int, n int, id string) {
+	item := d.issueAt(path)
+	if item == nil {
+		return
+	}
+	worklogs := mappingValue(item, "worklogs")
+	if worklogs == nil || worklogs.Kind != yaml.SequenceNode || n >= len(worklogs.Content) || worklogs.Content[n].Kind != yaml.MappingNode {
+		return
+	}
+	setScalarFirst(worklogs.Content[n], "id", id)
+}
+
+// setScalarFirst sets field of a mapping to the string value, adding it as
+// the first field when it is missing.
+func setScalarFirst(mapping *yaml.Node, field, value string) {
+	if node := mappingValue(mapping, field); node != nil {
+		node.Kind, node.Tag, node.Style, node.Value = yaml.ScalarNode, "!!str", 0, value
+		return
+	}
+	mapping.Content = append([]*yaml.Node{
+		{Kind: yaml.ScalarNode, Tag: "!!str", Value: field},
+		{Kind: yaml.ScalarNode, Tag: "!!str", Value: value},
+	}, mapping.Content...)
+}
+
+// save writes the document to path atomically.
+func (d *issueDocument) save(path string) error {
+	if len(d.issues.Content) == 0 {
+		d.issues.Style = yaml.FlowStyle
+	} else {
+		d.issues.Style = 0
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(4)
+	if err := enc.Encode(d.root); err != nil {
+		return fmt.Errorf("marshal yaml: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("marshal yaml: %w", err)
+	}
+	if err := writeFileAtomic(path, buf.Bytes()); err != nil {
+		return fmt.Errorf("write yaml: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes content to a temporary file next to path and renames
+// it into place, so a failed write leaves the previous file intact.
+func writeFileAtomic(path string, content []byte) error {
+	dir := filepath.Dir(path)
+	if dir != "." && dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("create directory %s: %w", dir, err)
+		}
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// mappingValue returns the value stored under key in a mapping node.
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
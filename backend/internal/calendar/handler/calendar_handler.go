@@ -13,6 +13,7 @@ import (
 	"messenger/backend/api/generated"
 	"messenger/backend/internal/calendar/entity"
 	"messenger/backend/internal/calendar/usecase"
+	"messenger/backend/pkg/apierror"
 	"messenger/backend/pkg/middleware"
 
 	"github.com/google/uuid"
@@ -36,7 +37,7 @@ func sendJSONResponse(w http.ResponseWriter, statusCode int, payload interface{}
 }
 
 func sendErrorResponse(w http.ResponseWriter, statusCode int, message string) {
-	sendJSONResponse(w, statusCode, map[string]string{"message": message})
+	apierror.Write(w, statusCode, message)
 }
 
 func currentUserID(r *http.Request) (string, bool) {
@@ -63,6 +64,10 @@ func (h *CalendarHandler) ImportCalendarSource(
 	}
 
 	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		if tooLarge := middleware.BodyTooLarge(err); tooLarge != nil {
+			middleware.WriteBodyTooLarge(w, tooLarge)
+			return
+		}
 		sendErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Invalid multipart form: %v", err))
 		return
 	}
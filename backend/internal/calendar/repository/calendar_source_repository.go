@@ -10,34 +10,10 @@ import (
 	"gorm.io/gorm"
 )
 
-const defaultCalendarCategory = "My Calendars"
-
 type calendarSourceRepository struct {
 	db *gorm.DB
 }
 
-func EnsureCalendarSourceCategoryConstraint(db *gorm.DB) error {
-	statements := []string{
-		`UPDATE calendar_sources
-		 SET category = ?
-		 WHERE category IS NULL OR btrim(category) = ''`,
-		`ALTER TABLE calendar_sources
-		 ALTER COLUMN category SET DEFAULT 'My Calendars'`,
-		`ALTER TABLE calendar_sources
-		 ALTER COLUMN category SET NOT NULL`,
-	}
-
-	if err := db.Exec(statements[0], defaultCalendarCategory).Error; err != nil {
-		return fmt.Errorf("failed to backfill calendar source categories: %w", err)
-	}
-	for _, statement := range statements[1:] {
-		if err := db.Exec(statement).Error; err != nil {
-			return fmt.Errorf("failed to enforce calendar source category constraint: %w", err)
-		}
-	}
-	return nil
-}
-
 func NewCalendarSourceRepository(db *gorm.DB) CalendarSourceRepository {
 	return &calendarSourceRepository{db: db}
 }
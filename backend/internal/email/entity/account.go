@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrNotFound = errors.New("not found")
+
+// Account is an IMAP login a user registered so requests can name it by ID
+// instead of sending the credentials each time.
+type Account struct {
+	ID       string `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID   string `gorm:"type:uuid;not null;index" json:"user_id"`
+	Host     string `gorm:"type:text;not null" json:"host"`
+	Port     int32  `gorm:"not null" json:"port"`
+	Email    string `gorm:"type:text;not null" json:"email"`
+	Security string `gorm:"type:text;not null" json:"security"`
+	// SealedPassword is the app password sealed with EMAIL_ACCOUNT_KEY.
+	SealedPassword string `gorm:"type:text;not null" json:"-"`
+	// DefaultMailbox is used by requests that name no mailbox.
+	DefaultMailbox string    `gorm:"type:text;not null;default:'INBOX'" json:"default_mailbox"`
+	CreatedAt      time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (Account) TableName() string { return "email_accounts" }
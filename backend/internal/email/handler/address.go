@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"strings"
+
+	"github.com/emersion/go-imap"
+)
+
+// formatAddress renders an envelope address as "Name <mailbox@host>",
+// leaving out whatever parts are missing: without a host the mailbox stands
+// alone, and without a usable mailbox only the personal name is left. Group
+// markers and malformed headers can leave nothing at all, in which case the
+// result is "".
+func formatAddress(addr *imap.Address) string {
+	spec := ""
+	switch {
+	case addr.MailboxName != "" && addr.HostName != "":
+		spec = addr.MailboxName + "@" + addr.HostName
+	case addr.MailboxName != "":
+		spec = addr.MailboxName
+	}
+	name := strings.TrimSpace(addr.PersonalName)
+	switch {
+	case spec == "":
+		return name
+	case name == "":
+		return spec
+	}
+	return name + " <" + spec + ">"
+}
+
+// firstAddress formats the first address of addrs that renders to something,
+// or returns nil if there is none.
+func firstAddress(addrs []*imap.Address) *string {
+	for _, addr := range addrs {
+		if addr == nil {
+			continue
+		}
+		if formatted := formatAddress(addr); formatted != "" {
+			return &formatted
+		}
+	}
+	return nil
+}
+
+// addressList formats every address of a To, Cc or Reply-To field, returning
+// nil for an empty field so the JSON property is omitted.
+func addressList(addrs []*imap.Address) *[]string {
+	out := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		if addr == nil {
+			continue
+		}
+		if formatted := formatAddress(addr); formatted != "" {
+			out = append(out, formatted)
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return &out
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/emersion/go-imap"
+)
+
+func TestFormatAddress(t *testing.T) {
+	tests := []struct {
+		name string
+		addr imap.Address
+		want string
+	}{
+		{"full", imap.Address{PersonalName: "Ann", MailboxName: "ann", HostName: "example.com"}, "Ann <ann@example.com>"},
+		{"no name", imap.Address{MailboxName: "ann", HostName: "example.com"}, "ann@example.com"},
+		{"no host", imap.Address{PersonalName: "Ann", MailboxName: "ann"}, "Ann <ann>"},
+		{"mailbox only", imap.Address{MailboxName: "undisclosed-recipients"}, "undisclosed-recipients"},
+		{"name only", imap.Address{PersonalName: "Ann"}, "Ann"},
+		{"host without mailbox", imap.Address{PersonalName: "Ann", HostName: "example.com"}, "Ann"},
+		{"blank name", imap.Address{PersonalName: "  ", MailboxName: "ann", HostName: "example.com"}, "ann@example.com"},
+		{"group end marker", imap.Address{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatAddress(&tt.addr); got != tt.want {
+				t.Fatalf("formatAddress(%+v) = %q, want %q", tt.addr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddressList(t *testing.T) {
+	got := addressList([]*imap.Address{
+		{PersonalName: "Ann", MailboxName: "ann", HostName: "example.com"},
+		nil,
+		{},
+		{MailboxName: "bob", HostName: "example.com"},
+	})
+	want := []string{"Ann <ann@example.com>", "bob@example.com"}
+	if got == nil || !slices.Equal(*got, want) {
+		t.Fatalf("addressList() = %v, want %q", got, want)
+	}
+	for _, empty := range [][]*imap.Address{nil, {{}}} {
+		if got := addressList(empty); got != nil {
+			t.Fatalf("addressList(%v) = %q, want nil", empty, *got)
+		}
+	}
+	if got := firstAddress([]*imap.Address{nil, {}, {MailboxName: "c", HostName: "x.org"}}); got == nil || *got != "c@x.org" {
+		t.Fatalf("firstAddress() = %v, want c@x.org", got)
+	}
+}
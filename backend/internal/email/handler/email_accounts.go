@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+
+	"messenger/backend/api/generated"
+	"messenger/backend/internal/email/entity"
+	"messenger/backend/pkg/apierror"
+	"messenger/backend/pkg/httpjson"
+	"messenger/backend/pkg/middleware"
+)
+
+// AccountStore keeps the email accounts users registered. Every lookup is
+// scoped to the user, and another user's account is entity.ErrNotFound.
+type AccountStore interface {
+	CreateAccount(ctx context.Context, account *entity.Account) error
+	ListAccounts(ctx context.Context, userID string) ([]entity.Account, error)
+	GetAccount(ctx context.Context, userID, id string) (*entity.Account, error)
+	DeleteAccount(ctx context.Context, userID, id string) error
+}
+
+var (
+	errAccountsDisabled  = errors.New("email accounts are disabled on this server")
+	errAccountNotFound   = errors.New("email account not found")
+	errAccountWithLogin  = errors.New("accountId cannot be combined with host, port, email, appPassword or security")
+	errAccountUnreadable = errors.New("stored credentials of the email account cannot be decrypted; register it again")
+)
+
+func (h *EmailHandler) accountsEnabled() bool {
+	return h.accounts != nil && h.credentials != nil
+}
+
+// resolveLogin replaces a request naming a registered account with that
+// account's login, and returns the mailbox to use when the request names
+// none: the account's default, or INBOX for requests carrying credentials.
+func (h *EmailHandler) resolveLogin(ctx context.Context, req generated.EmailLoginRequest) (generated.EmailLoginRequest, string, error) {
+	if req.AccountId == nil {
+		return req, "INBOX", nil
+	}
+	if req.Host != "" || req.Port != 0 || req.Email != "" || req.AppPassword != "" || req.Security != nil {
+		return req, "", errAccountWithLogin
+	}
+	if !h.accountsEnabled() {
+		return req, "", errAccountsDisabled
+	}
+	userID, _ := ctx.Value(middleware.ContextKeyUserID).(string)
+	account, err := h.accounts.GetAccount(ctx, userID, req.AccountId.String())
+	if errors.Is(err, entity.ErrNotFound) {
+		return req, "", errAccountNotFound
+	}
+	if err != nil {
+		return req, "", err
+	}
+	password, err := h.credentials.Open(account.SealedPassword)
+	if err != nil {
+		return req, "", errAccountUnreadable
+	}
+	security := generated.EmailSecurity(account.Security)
+	return generated.EmailLoginRequest{
+		Host:        account.Host,
+		Port:        account.Port,
+		Email:       openapi_types.Email(account.Email),
+		AppPassword: password,
+		Security:    &security,
+	}, account.DefaultMailbox, nil
+}
+
+// ListEmailAccounts handles GET /email/accounts requests.
+func (h *EmailHandler) ListEmailAccounts(w http.ResponseWriter, r *http.Request) {
+	if !h.accountsEnabled() {
+		apierror.Write(w, http.StatusNotImplemented, errAccountsDisabled.Error())
+		return
+	}
+	userID, _ := r.Context().Value(middleware.ContextKeyUserID).(string)
+	accounts, err := h.accounts.ListAccounts(r.Context(), userID)
+	if err != nil {
+		apierror.Write(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp := generated.EmailAccountsResponse{Accounts: make([]generated.EmailAccount, len(accounts))}
+	for i := range accounts {
+		resp.Accounts[i] = toEmailAccount(&accounts[i])
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// CreateEmailAccount handles POST /email/accounts requests. The login is
+// tried against the IMAP server first so that only working credentials are
+// stored.
+func (h *EmailHandler) CreateEmailAccount(w http.ResponseWriter, r *http.Request) {
+	req, err := httpjson.Decode[generated.EmailAccountRequest](r)
+	if err != nil {
+		apierror.Write(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.AccountId != nil {
+		apierror.Write(w, http.StatusBadRequest, "accountId cannot be given when registering an account")
+		return
+	}
+	if !h.accountsEnabled() {
+		apierror.Write(w, http.StatusNotImplemented, errAccountsDisabled.Error())
+		return
+	}
+	defaultMailbox := "INBOX"
+	if req.DefaultMailbox != nil {
+		if trimmed := strings.TrimSpace(*req.DefaultMailbox); trimmed != "" {
+			defaultMailbox = trimmed
+		}
+	}
+
+	login := generated.EmailLoginRequest{
+		Host:        req.Host,
+		Port:        req.Port,
+		Email:       req.Email,
+		AppPassword: req.AppPassword,
+		Security:    req.Security,
+	}
+	ctx, cancel := h.requestContext(r)
+	defer cancel()
+
+	_, release, err := h.dialAndLogin(ctx, login)
+	if err != nil {
+		writeIMAPError(w, ctx, err)
+		return
+	}
+	release()
+
+	sealed, err := h.credentials.Seal(login.AppPassword)
+	if err != nil {
+		apierror.Write(w, http.StatusInternalServerError, "failed to encrypt app password: "+err.Error())
+		return
+	}
+	security := generated.Tls
+	if login.Security != nil {
+		security = *login.Security
+	}
+	userID, _ := r.Context().Value(middleware.ContextKeyUserID).(string)
+	account := &entity.Account{
+		ID:             uuid.NewString(),
+		UserID:         userID,
+		Host:           strings.TrimSpace(login.Host),
+		Port:           login.Port,
+		Email:          string(login.Email),
+		Security:       string(security),
+		SealedPassword: sealed,
+		DefaultMailbox: defaultMailbox,
+	}
+	if err := h.accounts.CreateAccount(r.Context(), account); err != nil {
+		apierror.Write(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(toEmailAccount(account))
+}
+
+// DeleteEmailAccount handles DELETE /email/accounts/{accountId} requests.
+func (h *EmailHandler) DeleteEmailAccount(w http.ResponseWriter, r *http.Request, accountId openapi_types.UUID) {
+	if !h.accountsEnabled() {
+		apierror.Write(w, http.StatusNotImplemented, errAccountsDisabled.Error())
+		return
+	}
+	userID, _ := r.Context().Value(middleware.ContextKeyUserID).(string)
+	err := h.accounts.DeleteAccount(r.Context(), userID, accountId.String())
+	if errors.Is(err, entity.ErrNotFound) {
+		apierror.Write(w, http.StatusNotFound, errAccountNotFound.Error())
+		return
+	}
+	if err != nil {
+		apierror.Write(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func toEmailAccount(account *entity.Account) generated.EmailAccount {
+	return generated.EmailAccount{
+		Id:             uuid.MustParse(account.ID),
+		Host:           account.Host,
+		Port:           account.Port,
+		Email:          openapi_types.Email(account.Email),
+		Security:       generated.EmailSecurity(account.Security),
+		DefaultMailbox: account.DefaultMailbox,
+		CreatedAt:      account.CreatedAt,
+	}
+}
@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"messenger/backend/api/generated"
+	"messenger/backend/internal/email/entity"
+	"messenger/backend/pkg/middleware"
+	"messenger/backend/pkg/secretbox"
+)
+
+// memoryAccountStore is an AccountStore backed by a map.
+type memoryAccountStore map[string]entity.Account
+
+func (s memoryAccountStore) CreateAccount(ctx context.Context, account *entity.Account) error {
+	s[account.ID] = *account
+	return nil
+}
+
+func (s memoryAccountStore) ListAccounts(ctx context.Context, userID string) ([]entity.Account, error) {
+	var accounts []entity.Account
+	for _, account := range s {
+		if account.UserID == userID {
+			accounts = append(accounts, account)
+		}
+	}
+	return accounts, nil
+}
+
+func (s memoryAccountStore) GetAccount(ctx context.Context, userID, id string) (*entity.Account, error) {
+	account, ok := s[id]
+	if !ok || account.UserID != userID {
+		return nil, entity.ErrNotFound
+	}
+	return &account, nil
+}
+
+func (s memoryAccountStore) DeleteAccount(ctx context.Context, userID, id string) error {
+	if _, err := s.GetAccount(ctx, userID, id); err != nil {
+		return err
+	}
+	delete(s, id)
+	return nil
+}
+
+func newTestBox(t *testing.T, fill byte) *secretbox.Box {
+	t.Helper()
+	box, err := secretbox.New([]byte(strings.Repeat(string(fill), secretbox.KeySize)))
+	if err != nil {
+		t.Fatalf("secretbox.New() error = %v", err)
+	}
+	return box
+}
+
+func TestResolveLogin(t *testing.T) {
+	box := newTestBox(t, 'k')
+	sealed, err := box.Seal("app-secret")
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	staleSealed, err := newTestBox(t, 'o').Seal("app-secret")
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	accountID, staleID := uuid.New(), uuid.New()
+	store := memoryAccountStore{
+		accountID.String(): {ID: accountID.String(), UserID: "u1", Host: "imap.example.com", Port: 993, Email: "me@example.com", Security: "starttls", SealedPassword: sealed, DefaultMailbox: "Archive"},
+		staleID.String():   {ID: staleID.String(), UserID: "u1", Host: "imap.example.com", Port: 993, Email: "me@example.com", Security: "tls", SealedPassword: staleSealed, DefaultMailbox: "INBOX"},
+	}
+	h := NewEmailHandler(Options{Accounts: store, Credentials: box})
+	ctx := context.WithValue(context.Background(), middleware.ContextKeyUserID, "u1")
+
+	login, mailbox, err := h.resolveLogin(ctx, generated.EmailLoginRequest{AccountId: &accountID})
+	if err != nil {
+		t.Fatalf("resolveLogin() error = %v", err)
+	}
+	if login.Host != "imap.example.com" || login.Port != 993 || login.Email != "me@example.com" || login.AppPassword != "app-secret" ||
+		login.Security == nil || *login.Security != generated.Starttls || login.AccountId != nil || mailbox != "Archive" {
+		t.Fatalf("resolveLogin() = %+v, %q", login, mailbox)
+	}
+
+	plain := generated.EmailLoginRequest{Host: "imap.example.com", Port: 993, Email: "me@example.com", AppPassword: "pw"}
+	if login, mailbox, err := h.resolveLogin(ctx, plain); err != nil || login != plain || mailbox != "INBOX" {
+		t.Fatalf("resolveLogin(credentials) = %+v, %q, %v, want them unchanged with INBOX", login, mailbox, err)
+	}
+
+	tests := []struct {
+		name string
+		h    *EmailHandler
+		ctx  context.Context
+		req  generated.EmailLoginRequest
+		want error
+	}{
+		{name: "with credentials", h: h, ctx: ctx, req: generated.EmailLoginRequest{AccountId: &accountID, AppPassword: "pw"}, want: errAccountWithLogin},
+		{name: "other user", h: h, ctx: context.WithValue(context.Background(), middleware.ContextKeyUserID, "u2"), req: generated.EmailLoginRequest{AccountId: &accountID}, want: errAccountNotFound},
+		{name: "rotated key", h: h, ctx: ctx, req: generated.EmailLoginRequest{AccountId: &staleID}, want: errAccountUnreadable},
+		{name: "disabled", h: NewEmailHandler(Options{Accounts: store}), ctx: ctx, req: generated.EmailLoginRequest{AccountId: &accountID}, want: errAccountsDisabled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := tt.h.resolveLogin(tt.ctx, tt.req); !errors.Is(err, tt.want) {
+				t.Fatalf("resolveLogin() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateEmailAccountStoresSealedPassword(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	defer ln.Close()
+	go servePlainIMAP(ln, true, true)
+
+	box := newTestBox(t, 'k')
+	store := memoryAccountStore{}
+	h := NewEmailHandler(Options{
+		Timeout:              5 * time.Second,
+		AllowedHosts:         []string{"127.0.0.1"},
+		AllowPrivateNetworks: true,
+		AllowPlaintext:       true,
+		Accounts:             store,
+		Credentials:          box,
+	})
+
+	port := ln.Addr().(*net.TCPAddr).Port
+	body := fmt.Sprintf(`{"host":"127.0.0.1","port":%d,"email":"me@example.com","appPassword":"app-secret","security":"none","defaultMailbox":"Archive"}`, port)
+	req := httptest.NewRequest(http.MethodPost, "/email/accounts", strings.NewReader(body))
+	req = req.WithContext(context.WithValue(req.Context(), middleware.ContextKeyUserID, "u1"))
+	rec := httptest.NewRecorder()
+	h.CreateEmailAccount(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201; body %s", rec.Code, rec.Body)
+	}
+	var got generated.EmailAccount
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if strings.Contains(rec.Body.String(), "app-secret") || got.Security != generated.None || got.DefaultMailbox != "Archive" {
+		t.Fatalf("response = %s", rec.Body)
+	}
+
+	stored, ok := store[got.Id.String()]
+	if !ok || stored.UserID != "u1" {
+		t.Fatalf("stored account = %+v, want one for u1", stored)
+	}
+	if stored.SealedPassword == "app-secret" {
+		t.Fatal("app password was stored in the clear")
+	}
+	if password, err := box.Open(stored.SealedPassword); err != nil || password != "app-secret" {
+		t.Fatalf("Open(sealed) = %q, %v, want the app password", password, err)
+	}
+}
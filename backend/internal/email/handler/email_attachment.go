@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/emersion/go-imap"
+
+	"messenger/backend/api/generated"
+	"messenger/backend/pkg/apierror"
+	"messenger/backend/pkg/httpjson"
+)
+
+// maxAttachmentSize caps the encoded size of a part EmailAttachment is willing
+// to download from the IMAP server.
+const maxAttachmentSize = 25 << 20
+
+// EmailAttachment handles POST /email/attachment requests. It locates a single
+// MIME part of the message, either by IMAP section or by filename, and streams
+// it back decoded from its transfer encoding.
+func (h *EmailHandler) EmailAttachment(w http.ResponseWriter, r *http.Request) {
+	req, err := httpjson.Decode[generated.EmailAttachmentRequest](r)
+	if err != nil {
+		apierror.Write(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Uid <= 0 || req.Uid > int64(^uint32(0)) {
+		apierror.Write(w, http.StatusBadRequest, "uid must be a positive 32-bit integer")
+		return
+	}
+
+	var path []int
+	if req.Part != nil && strings.TrimSpace(*req.Part) != "" {
+		parsed, err := parsePartPath(*req.Part)
+		if err != nil {
+			apierror.Write(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		path = parsed
+	} else if req.Filename == nil || strings.TrimSpace(*req.Filename) == "" {
+		apierror.Write(w, http.StatusBadRequest, "either part or filename is required")
+		return
+	}
+
+	login, mailbox, err := h.resolveLogin(r.Context(), generated.EmailLoginRequest{
+		AccountId:   req.AccountId,
+		Host:        req.Host,
+		Port:        req.Port,
+		Email:       req.Email,
+		AppPassword: req.AppPassword,
+		Security:    req.Security,
+	})
+	if err != nil {
+		writeIMAPError(w, r.Context(), err)
+		return
+	}
+	if req.Mailbox != nil {
+		if trimmed := strings.TrimSpace(*req.Mailbox); trimmed != "" {
+			mailbox = trimmed
+		}
+	}
+	ctx, cancel := h.requestContext(r)
+	defer cancel()
+
+	c, release, err := h.dialAndLogin(ctx, login)
+	if err != nil {
+		writeIMAPError(w, ctx, err)
+		return
+	}
+	defer release()
+
+	if _, err := c.Select(mailbox, true); err != nil {
+		if ctx.Err() != nil {
+			writeIMAPError(w, ctx, err)
+			return
+		}
+		apierror.Write(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	seqset := new(imap.SeqSet)
+	seqset.AddNum(uint32(req.Uid))
+
+	structure, err := fetchOne(func(ch chan *imap.Message) error {
+		return c.UidFetch(seqset, []imap.FetchItem{imap.FetchBodyStructure}, ch)
+	})
+	if err != nil {
+		writeIMAPError(w, ctx, err)
+		return
+	}
+	if structure == nil || structure.BodyStructure == nil {
+		apierror.Write(w, http.StatusNotFound, "message not found")
+		return
+	}
+
+	var filename string
+	if req.Filename != nil {
+		filename = strings.TrimSpace(*req.Filename)
+	}
+	path, part := findPart(structure.BodyStructure, path, filename)
+	if part == nil {
+		apierror.Write(w, http.StatusNotFound, "attachment not found")
+		return
+	}
+	if part.Size > maxAttachmentSize {
+		apierror.Write(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("attachment exceeds %d bytes", maxAttachmentSize))
+		return
+	}
+
+	section := &imap.BodySectionName{BodyPartName: imap.BodyPartName{Path: path}, Peek: true}
+	msg, err := fetchOne(func(ch chan *imap.Message) error {
+		return c.UidFetch(seqset, []imap.FetchItem{section.FetchItem()}, ch)
+	})
+	if err != nil {
+		writeIMAPError(w, ctx, err)
+		return
+	}
+	var body io.Reader
+	if msg != nil {
+		body = msg.GetBody(section)
+	}
+	if body == nil {
+		apierror.Write(w, http.StatusNotFound, "attachment not found")
+		return
+	}
+
+	if name, _ := part.Filename(); name != "" {
+		filename = name
+	}
+	if filename == "" {
+		filename = "attachment"
+	}
+	contentType := "application/octet-stream"
+	if part.MIMEType != "" && part.MIMESubType != "" {
+		contentType = strings.ToLower(part.MIMEType + "/" + part.MIMESubType)
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	_, _ = io.Copy(w, io.LimitReader(decodeTransfer(body, part.Encoding), maxAttachmentSize))
+}
+
+// fetchOne runs fetch and returns the first message it produces, or nil when
+// the server returned none. Any further messages are drained and discarded.
+func fetchOne(fetch func(ch chan *imap.Message) error) (*imap.Message, error) {
+	messages := make(chan *imap.Message, 1)
+	done := make(chan error, 1)
+	go func() { done <- fetch(messages) }()
+
+	var first *imap.Message
+	for msg := range messages {
+		if first == nil {
+			first = msg
+		}
+	}
+	if err := <-done; err != nil {
+		return nil, err
+	}
+	return first, nil
+}
+
+// findPart returns the part at path, or the first part named filename when
+// path is empty, together with its path.
+func findPart(bs *imap.BodyStructure, path []int, filename string) ([]int, *imap.BodyStructure) {
+	var found *imap.BodyStructure
+	var foundPath []int
+	bs.Walk(func(p []int, part *imap.BodyStructure) bool {
+		if found != nil {
+			return false
+		}
+		if len(path) > 0 {
+			if slices.Equal(p, path) {
+				found, foundPath = part, p
+			}
+			return true
+		}
+		if len(part.Parts) == 0 {
+			if name, _ := part.Filename(); name != "" && name == filename {
+				found, foundPath = part, p
+			}
+		}
+		return true
+	})
+	return foundPath, found
+}
+
+// parsePartPath parses an IMAP section such as "1.2" into its part indexes.
+func parsePartPath(section string) ([]int, error) {
+	fields := strings.Split(strings.TrimSpace(section), ".")
+	path := make([]int, 0, len(fields))
+	for _, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid part %q", section)
+		}
+		path = append(path, n)
+	}
+	return path, nil
+}
+
+// decodeTransfer undoes the Content-Transfer-Encoding of a part body.
+// Unknown encodings (7bit, 8bit, binary) are passed through untouched.
+func decodeTransfer(r io.Reader, encoding string) io.Reader {
+	switch strings.ToLower(encoding) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, r)
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	default:
+		return r
+	}
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/emersion/go-imap"
+)
+
+func TestFindPartByPathAndFilename(t *testing.T) {
+	bs := &imap.BodyStructure{
+		MIMEType:    "multipart",
+		MIMESubType: "mixed",
+		Parts: []*imap.BodyStructure{
+			{MIMEType: "text", MIMESubType: "plain"},
+			{
+				MIMEType:          "application",
+				MIMESubType:       "pdf",
+				Encoding:          "base64",
+				DispositionParams: map[string]string{"filename": "invoice.pdf"},
+			},
+		},
+	}
+
+	path, part := findPart(bs, []int{2}, "")
+	if part == nil || part.MIMESubType != "pdf" || !slices.Equal(path, []int{2}) {
+		t.Fatalf("findPart by path = %v, %+v", path, part)
+	}
+	path, part = findPart(bs, nil, "invoice.pdf")
+	if part == nil || !slices.Equal(path, []int{2}) {
+		t.Fatalf("findPart by filename = %v, %+v", path, part)
+	}
+	if _, part := findPart(bs, []int{3}, ""); part != nil {
+		t.Fatalf("findPart(3) = %+v, want nil", part)
+	}
+	if _, part := findPart(bs, nil, "missing.pdf"); part != nil {
+		t.Fatalf("findPart(missing.pdf) = %+v, want nil", part)
+	}
+}
+
+func TestParsePartPath(t *testing.T) {
+	path, err := parsePartPath("1.2")
+	if err != nil || !slices.Equal(path, []int{1, 2}) {
+		t.Fatalf("parsePartPath(1.2) = %v, %v", path, err)
+	}
+	for _, bad := range []string{"", "0", "1..2", "a"} {
+		if _, err := parsePartPath(bad); err == nil {
+			t.Fatalf("parsePartPath(%q) error = nil, want error", bad)
+		}
+	}
+}
+
+func TestDecodeTransfer(t *testing.T) {
+	for encoding, encoded := range map[string]string{
+		"base64":           "aGVsbG8g\r\nd29ybGQ=\r\n",
+		"QUOTED-PRINTABLE": "hello=20=\r\nworld",
+		"7bit":             "hello world",
+	} {
+		got, err := io.ReadAll(decodeTransfer(strings.NewReader(encoded), encoding))
+		if err != nil {
+			t.Fatalf("%s: ReadAll() error = %v", encoding, err)
+		}
+		if string(got) != "hello world" {
+			t.Fatalf("%s: decoded = %q, want %q", encoding, got, "hello world")
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package handler
+
+import (
+	"container/list"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/emersion/go-imap"
+	imapclient "github.com/emersion/go-imap/client"
+	"golang.org/x/net/html/charset"
+
+	"messenger/backend/api/generated"
+	"messenger/backend/pkg/apierror"
+	"messenger/backend/pkg/httpjson"
+)
+
+// maxBodyPartSize caps the encoded size of a text or HTML part EmailBody is
+// willing to download from the IMAP server.
+const maxBodyPartSize = 5 << 20
+
+// defaultBodyCacheBytes bounds the memory held by cached message bodies.
+const defaultBodyCacheBytes = 32 << 20
+
+var (
+	errMessageNotFound = errors.New("message not found")
+	errBodyTooLarge    = fmt.Errorf("message body exceeds %d bytes", maxBodyPartSize)
+)
+
+// EmailBody handles POST /email/body requests. It returns the message's HTML
+// part sanitized for direct rendering, with remote images stripped unless the
+// caller opts in, and a plain-text body. Parsed bodies are cached per message;
+// the credentials are still checked against the IMAP server on every request.
+func (h *EmailHandler) EmailBody(w http.ResponseWriter, r *http.Request) {
+	req, err := httpjson.Decode[generated.EmailBodyRequest](r)
+	if err != nil {
+		apierror.Write(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Uid <= 0 || req.Uid > int64(^uint32(0)) {
+		apierror.Write(w, http.StatusBadRequest, "uid must be a positive 32-bit integer")
+		return
+	}
+
+	login, mailbox, err := h.resolveLogin(r.Context(), generated.EmailLoginRequest{
+		AccountId:   req.AccountId,
+		Host:        req.Host,
+		Port:        req.Port,
+		Email:       req.Email,
+		AppPassword: req.AppPassword,
+		Security:    req.Security,
+	})
+	if err != nil {
+		writeIMAPError(w, r.Context(), err)
+		return
+	}
+	if req.Mailbox != nil {
+		if trimmed := strings.TrimSpace(*req.Mailbox); trimmed != "" {
+			mailbox = trimmed
+		}
+	}
+	ctx, cancel := h.requestContext(r)
+	defer cancel()
+
+	c, release, err := h.dialAndLogin(ctx, login)
+	if err != nil {
+		writeIMAPError(w, ctx, err)
+		return
+	}
+	defer release()
+
+	mbox, err := c.Select(mailbox, true)
+	if err != nil {
+		if ctx.Err() != nil {
+			writeIMAPError(w, ctx, err)
+			return
+		}
+		apierror.Write(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	// UIDVALIDITY changes when the server renumbers the mailbox, which makes
+	// every cached UID in it stale.
+	key := bodyCacheKey{
+		host:        strings.ToLower(login.Host),
+		account:     strings.ToLower(string(login.Email)),
+		mailbox:     mailbox,
+		uidValidity: mbox.UidValidity,
+		uid:         uint32(req.Uid),
+	}
+	body, ok := h.bodies.get(key)
+	if !ok {
+		body, err = fetchMessageBody(c, uint32(req.Uid))
+		switch {
+		case errors.Is(err, errMessageNotFound):
+			apierror.Write(w, http.StatusNotFound, err.Error())
+			return
+		case errors.Is(err, errBodyTooLarge):
+			apierror.Write(w, http.StatusRequestEntityTooLarge, err.Error())
+			return
+		case err != nil:
+			writeIMAPError(w, ctx, err)
+			return
+		}
+		h.bodies.put(key, body)
+	}
+
+	allowRemote := req.AllowRemoteContent != nil && *req.AllowRemoteContent
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(renderMessageBody(body, allowRemote))
+}
+
+// messageBody holds the decoded text parts of a message as the server sent
+// them; sanitizing happens per request because it depends on the caller's
+// remote-content choice.
+type messageBody struct {
+	html    string
+	text    string
+	hasHTML bool
+	hasText bool
+}
+
+func (b messageBody) size() int { return len(b.html) + len(b.text) }
+
+// renderMessageBody sanitizes the HTML part and falls back to text derived
+// from it when the message has no plain-text part.
+func renderMessageBody(body messageBody, allowRemote bool) generated.EmailBodyResponse {
+	resp := generated.EmailBodyResponse{Text: body.text}
+	if body.hasHTML {
+		clean, blocked := sanitizeHTML(body.html, allowRemote)
+		resp.Html = &clean
+		resp.RemoteContentBlocked = blocked
+		if !body.hasText {
+			resp.Text = htmlToText(clean)
+		}
+	}
+	return resp
+}
+
+// fetchMessageBody downloads and decodes the first inline text/plain and
+// text/html parts of the message with the given UID.
+func fetchMessageBody(c *imapclient.Client, uid uint32) (messageBody, error) {
+	seqset := new(imap.SeqSet)
+	seqset.AddNum(uid)
+
+	structure, err := fetchOne(func(ch chan *imap.Message) error {
+		return c.UidFetch(seqset, []imap.FetchItem{imap.FetchBodyStructure}, ch)
+	})
+	if err != nil {
+		return messageBody{}, err
+	}
+	if structure == nil || structure.BodyStructure == nil {
+		return messageBody{}, errMessageNotFound
+	}
+
+	htmlPath, htmlPart, textPath, textPart := findTextParts(structure.BodyStructure)
+	var body messageBody
+	for _, p := range []struct {
+		path  []int
+		part  *imap.BodyStructure
+		dst   *string
+		found *bool
+	}{
+		{htmlPath, htmlPart, &body.html, &body.hasHTML},
+		{textPath, textPart, &body.text, &body.hasText},
+	} {
+		if p.part == nil {
+			continue
+		}
+		if p.part.Size > maxBodyPartSize {
+			return messageBody{}, errBodyTooLarge
+		}
+		section := &imap.BodySectionName{BodyPartName: imap.BodyPartName{Path: p.path}, Peek: true}
+		msg, err := fetchOne(func(ch chan *imap.Message) error {
+			return c.UidFetch(seqset, []imap.FetchItem{section.FetchItem()}, ch)
+		})
+		if err != nil {
+			return messageBody{}, err
+		}
+		var raw io.Reader
+		if msg != nil {
+			raw = msg.GetBody(section)
+		}
+		if raw == nil {
+			continue
+		}
+		text, err := decodeTextPart(raw, p.part)
+		if err != nil {
+			return messageBody{}, err
+		}
+		*p.dst, *p.found = text, true
+	}
+	return body, nil
+}
+
+// findTextParts returns the first text/html and text/plain parts that are not
+// attachments, with their paths.
+func findTextParts(bs *imap.BodyStructure) (htmlPath []int, htmlPart *imap.BodyStructure, textPath []int, textPart *imap.BodyStructure) {
+	bs.Walk(func(path []int, part *imap.BodyStructure) bool {
+		if len(part.Parts) > 0 || !strings.EqualFold(part.MIMEType, "text") || strings.EqualFold(part.Disposition, "attachment") {
+			return true
+		}
+		switch strings.ToLower(part.MIMESubType) {
+		case "html":
+			if htmlPart == nil {
+				htmlPath, htmlPart = path, part
+			}
+		case "plain":
+			if textPart == nil {
+				textPath, textPart = path, part
+			}
+		}
+		return true
+	})
+	return htmlPath, htmlPart, textPath, textPart
+}
+
+// decodeTextPart undoes the transfer encoding of a text part and converts it
+// from its declared charset to UTF-8. Unknown charsets are passed through.
+func decodeTextPart(r io.Reader, part *imap.BodyStructure) (string, error) {
+	decoded := decodeTransfer(io.LimitReader(r, maxBodyPartSize), part.Encoding)
+	if label := part.Params["charset"]; label != "" {
+		if converted, err := charset.NewReaderLabel(label, decoded); err == nil {
+			decoded = converted
+		}
+	}
+	b, err := io.ReadAll(decoded)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// bodyCacheKey identifies a message across requests. The account is part of
+// the key so one user's bodies are never served to another.
+type bodyCacheKey struct {
+	host        string
+	account     string
+	mailbox     string
+	uidValidity uint32
+	uid         uint32
+}
+
+type bodyCacheEntry struct {
+	key  bodyCacheKey
+	body messageBody
+}
+
+// bodyCache is a least-recently-used cache of parsed message bodies bounded
+// by their total size.
+type bodyCache struct {
+	mu       sync.Mutex
+	maxBytes int
+	bytes    int
+	order    *list.List // front is most recently used
+	entries  map[bodyCacheKey]*list.Element
+}
+
+func newBodyCache(maxBytes int) *bodyCache {
+	return &bodyCache{
+		maxBytes: maxBytes,
+		order:    list.New(),
+		entries:  make(map[bodyCacheKey]*list.Element),
+	}
+}
+
+func (c *bodyCache) get(key bodyCacheKey) (messageBody, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return messageBody{}, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*bodyCacheEntry).body, true
+}
+
+// put stores body under key, evicting the least recently used entries to
+// stay within maxBytes. Bodies larger than a quarter of the budget are not
+// cached so a single message cannot flush everything else.
+func (c *bodyCache) put(key bodyCacheKey, body messageBody) {
+	size := body.size()
+	if size > c.maxBytes/4 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		c.bytes -= el.Value.(*bodyCacheEntry).body.size()
+		c.order.Remove(el)
+		delete(c.entries, key)
+	}
+	c.entries[key] = c.order.PushFront(&bodyCacheEntry{key: key, body: body})
+	c.bytes += size
+	for c.bytes > c.maxBytes {
+		oldest := c.order.Back()
+		entry := oldest.Value.(*bodyCacheEntry)
+		c.order.Remove(oldest)
+		delete(c.entries, entry.key)
+		c.bytes -= entry.body.size()
+	}
+}
@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/emersion/go-imap"
+)
+
+func TestSanitizeHTML(t *testing.T) {
+	tests := []struct {
+		name        string
+		raw         string
+		allowRemote bool
+		contains    []string
+		excludes    []string
+		wantBlocked bool
+	}{
+		{
+			name:     "scripts and handlers removed",
+			raw:      `<p onclick="steal()">Hi<script>alert(1)</script></p><a href="javascript:alert(1)">x</a>`,
+			contains: []string{"<p>Hi</p>"},
+			excludes: []string{"script", "onclick", "javascript:"},
+		},
+		{
+			name:        "remote image blocked by default",
+			raw:         `<p>Hello</p><img src="https://tracker.example/pixel.gif" alt="logo" width="1">`,
+			contains:    []string{"<p>Hello</p>", `alt="logo"`},
+			excludes:    []string{"tracker.example"},
+			wantBlocked: true,
+		},
+		{
+			name:        "remote image kept on request",
+			raw:         `<img src="https://cdn.example/logo.png">`,
+			allowRemote: true,
+			contains:    []string{"https://cdn.example/logo.png"},
+		},
+		{
+			name:     "inline data image kept",
+			raw:      `<img src="data:image/png;base64,iVBORw0KGgo//8A=">`,
+			contains: []string{"data:image/png;base64,iVBORw0KGgo//8A="},
+		},
+		{
+			name:     "links open safely",
+			raw:      `<a href="https://example.com">site</a>`,
+			contains: []string{`href="https://example.com"`, "noreferrer", `target="_blank"`},
+		},
+		{
+			name:     "styles and forms removed",
+			raw:      `<style>body{background:url(https://t.example/x)}</style><form action="https://evil"><input name="p"></form><div style="background:url(https://t.example/y)">text</div>`,
+			contains: []string{"text"},
+			excludes: []string{"t.example", "<form", "<input", "style"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, blocked := sanitizeHTML(tt.raw, tt.allowRemote)
+			for _, want := range tt.contains {
+				if !strings.Contains(got, want) {
+					t.Errorf("sanitized %q is missing %q", got, want)
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if strings.Contains(got, unwanted) {
+					t.Errorf("sanitized %q still contains %q", got, unwanted)
+				}
+			}
+			if blocked != tt.wantBlocked {
+				t.Errorf("blocked = %t, want %t", blocked, tt.wantBlocked)
+			}
+		})
+	}
+}
+
+func TestHTMLToText(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{`<p>Hello   <b>world</b></p><p>Second</p>`, "Hello world\n\nSecond"},
+		{`line one<br>line two`, "line one\nline two"},
+		{`<ul><li>a</li><li>b</li></ul>`, "- a\n- b"},
+		{`<head><title>T</title><style>p{}</style></head><body><script>x()</script>Body</body>`, "Body"},
+		{`<table><tr><td>a</td><td>b</td></tr></table>`, "a b"},
+	}
+	for _, tt := range tests {
+		if got := htmlToText(tt.raw); got != tt.want {
+			t.Errorf("htmlToText(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestRenderMessageBodyDerivesText(t *testing.T) {
+	resp := renderMessageBody(messageBody{html: `<p>Hi <img src="http://t.example/p.gif"></p>`, hasHTML: true}, false)
+	if resp.Html == nil || strings.Contains(*resp.Html, "t.example") {
+		t.Fatalf("html = %v, want sanitized html without the remote image", resp.Html)
+	}
+	if !resp.RemoteContentBlocked || resp.Text != "Hi" {
+		t.Fatalf("resp = %+v, want blocked remote content and derived text", resp)
+	}
+
+	resp = renderMessageBody(messageBody{text: "plain", hasText: true}, false)
+	if resp.Html != nil || resp.Text != "plain" || resp.RemoteContentBlocked {
+		t.Fatalf("resp = %+v, want the text part only", resp)
+	}
+}
+
+func TestFindTextParts(t *testing.T) {
+	bs := &imap.BodyStructure{
+		MIMEType:    "multipart",
+		MIMESubType: "mixed",
+		Parts: []*imap.BodyStructure{
+			{
+				MIMEType:    "multipart",
+				MIMESubType: "alternative",
+				Parts: []*imap.BodyStructure{
+					{MIMEType: "text", MIMESubType: "plain"},
+					{MIMEType: "text", MIMESubType: "html"},
+				},
+			},
+			{MIMEType: "text", MIMESubType: "html", Disposition: "attachment"},
+		},
+	}
+
+	htmlPath, htmlPart, textPath, textPart := findTextParts(bs)
+	if htmlPart == nil || !slices.Equal(htmlPath, []int{1, 2}) {
+		t.Fatalf("html part = %v, %+v", htmlPath, htmlPart)
+	}
+	if textPart == nil || !slices.Equal(textPath, []int{1, 1}) {
+		t.Fatalf("text part = %v, %+v", textPath, textPart)
+	}
+
+	single := &imap.BodyStructure{MIMEType: "text", MIMESubType: "plain"}
+	_, htmlPart, textPath, _ = findTextParts(single)
+	if htmlPart != nil || !slices.Equal(textPath, []int{1}) {
+		t.Fatalf("single part: html = %+v, text path = %v", htmlPart, textPath)
+	}
+}
+
+func TestDecodeTextPartCharset(t *testing.T) {
+	part := &imap.BodyStructure{Encoding: "quoted-printable", Params: map[string]string{"charset": "iso-8859-1"}}
+	got, err := decodeTextPart(strings.NewReader("caf=E9"), part)
+	if err != nil || got != "café" {
+		t.Fatalf("decodeTextPart = %q, %v; want café", got, err)
+	}
+}
+
+func TestBodyCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newBodyCache(40)
+	key := func(uid uint32) bodyCacheKey {
+		return bodyCacheKey{host: "imap.example.com", account: "a@example.com", mailbox: "INBOX", uid: uid}
+	}
+	body := messageBody{text: strings.Repeat("x", 10), hasText: true}
+
+	cache.put(key(1), body)
+	cache.put(key(2), body)
+	cache.put(key(3), body)
+	cache.get(key(1)) // 2 is now the least recently used
+	cache.put(key(4), body)
+	cache.put(key(5), body)
+
+	if _, ok := cache.get(key(2)); ok {
+		t.Fatal("uid 2 should have been evicted")
+	}
+	for _, uid := range []uint32{1, 3, 4, 5} {
+		if _, ok := cache.get(key(uid)); !ok {
+			t.Fatalf("uid %d should still be cached", uid)
+		}
+	}
+
+	other := key(1)
+	other.account = "b@example.com"
+	if _, ok := cache.get(other); ok {
+		t.Fatal("a body cached for one account must not be served to another")
+	}
+
+	cache.put(key(6), messageBody{text: strings.Repeat("x", 11), hasText: true})
+	if _, ok := cache.get(key(6)); ok {
+		t.Fatal("bodies over a quarter of the budget should not be cached")
+	}
+}
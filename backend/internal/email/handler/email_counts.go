@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/emersion/go-imap"
+
+	"messenger/backend/api/generated"
+	"messenger/backend/pkg/apierror"
+	"messenger/backend/pkg/httpjson"
+)
+
+// EmailCounts handles POST /email/counts requests, answering the total and
+// unread counts of each mailbox from STATUS alone, without selecting the
+// mailboxes or fetching any message.
+func (h *EmailHandler) EmailCounts(w http.ResponseWriter, r *http.Request) {
+	req, err := httpjson.Decode[generated.EmailCountsRequest](r)
+	if err != nil {
+		apierror.Write(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var requested []string
+	if req.Mailboxes != nil {
+		seen := make(map[string]bool, len(*req.Mailboxes))
+		for _, name := range *req.Mailboxes {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				apierror.Write(w, http.StatusBadRequest, "mailbox names must not be blank")
+				return
+			}
+			if !seen[name] {
+				seen[name] = true
+				requested = append(requested, name)
+			}
+		}
+	}
+
+	login, _, err := h.resolveLogin(r.Context(), generated.EmailLoginRequest{
+		AccountId:   req.AccountId,
+		Host:        req.Host,
+		Port:        req.Port,
+		Email:       req.Email,
+		AppPassword: req.AppPassword,
+		Security:    req.Security,
+	})
+	if err != nil {
+		writeIMAPError(w, r.Context(), err)
+		return
+	}
+	ctx, cancel := h.requestContext(r)
+	defer cancel()
+
+	c, release, err := h.dialAndLogin(ctx, login)
+	if err != nil {
+		writeIMAPError(w, ctx, err)
+		return
+	}
+	defer release()
+
+	if req.Mailboxes == nil {
+		listed, err := listMailboxes(c)
+		if err != nil {
+			writeIMAPError(w, ctx, err)
+			return
+		}
+		for _, mailbox := range toMailboxes(listed) {
+			if selectable(mailbox.Attributes) {
+				requested = append(requested, mailbox.Name)
+			}
+		}
+	}
+
+	counts := make([]generated.EmailMailboxCount, 0, len(requested))
+	for _, name := range requested {
+		status, err := c.Status(name, []imap.StatusItem{imap.StatusMessages, imap.StatusUnseen})
+		if err != nil {
+			if ctx.Err() != nil {
+				writeIMAPError(w, ctx, err)
+				return
+			}
+			apierror.Write(w, http.StatusNotFound, fmt.Sprintf("mailbox %q: %v", name, err))
+			return
+		}
+		counts = append(counts, generated.EmailMailboxCount{
+			Mailbox: name,
+			Total:   int64(status.Messages),
+			Unread:  int64(status.Unseen),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(generated.EmailCountsResponse{Counts: counts})
+}
+
+// selectable reports whether a mailbox with attributes can hold messages;
+// STATUS fails on \Noselect and \NonExistent ones.
+func selectable(attributes []string) bool {
+	for _, attr := range attributes {
+		if strings.EqualFold(attr, imap.NoSelectAttr) || strings.EqualFold(attr, `\NonExistent`) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"messenger/backend/api/generated"
+)
+
+// serveCountsIMAP answers one unencrypted session whose mailboxes have the
+// message counts in counts ("total unread"), plus a \Noselect parent. Any
+// SELECT or FETCH fails the session, since counting must not need them.
+func serveCountsIMAP(ln net.Listener, counts map[string]string) {
+	conn, err := ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(format string, args ...interface{}) { fmt.Fprintf(conn, format+"\r\n", args...) }
+	reply("* OK [CAPABILITY IMAP4rev1] ready")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		tag, command, _ := strings.Cut(strings.TrimSpace(line), " ")
+		verb, args, _ := strings.Cut(command, " ")
+		switch strings.ToUpper(verb) {
+		case "LIST":
+			reply(`* LIST (\Noselect \HasChildren) "/" "Projects"`)
+			for name := range counts {
+				reply(`* LIST (\HasNoChildren) "/" %q`, name)
+			}
+			reply("%s OK LIST completed", tag)
+		case "STATUS":
+			name := strings.Trim(args[:strings.LastIndex(args, " (")], `"`)
+			count, ok := counts[name]
+			if !ok {
+				reply("%s NO [NONEXISTENT] no such mailbox", tag)
+				continue
+			}
+			var total, unread int
+			fmt.Sscan(count, &total, &unread)
+			reply(`* STATUS %q (MESSAGES %d UNSEEN %d)`, name, total, unread)
+			reply("%s OK STATUS completed", tag)
+		case "SELECT", "EXAMINE", "FETCH", "UID":
+			reply("%s BAD not expected while counting", tag)
+			return
+		case "LOGOUT":
+			reply("* BYE")
+			reply("%s OK LOGOUT completed", tag)
+			return
+		default:
+			reply("%s OK done", tag)
+		}
+	}
+}
+
+func TestEmailCounts(t *testing.T) {
+	counts := map[string]string{"INBOX": "12 3", "Projects/Alpha": "4 0"}
+	tests := []struct {
+		name      string
+		mailboxes string
+		want      []generated.EmailMailboxCount
+		wantCode  int
+	}{
+		{
+			name:     "discovered",
+			want:     []generated.EmailMailboxCount{{Mailbox: "INBOX", Total: 12, Unread: 3}, {Mailbox: "Projects/Alpha", Total: 4, Unread: 0}},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:      "requested",
+			mailboxes: `,"mailboxes":["Projects/Alpha"," INBOX","INBOX"]`,
+			want:      []generated.EmailMailboxCount{{Mailbox: "Projects/Alpha", Total: 4, Unread: 0}, {Mailbox: "INBOX", Total: 12, Unread: 3}},
+			wantCode:  http.StatusOK,
+		},
+		{
+			name:      "missing",
+			mailboxes: `,"mailboxes":["INBOX","Archive"]`,
+			wantCode:  http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("net.Listen() error = %v", err)
+			}
+			defer ln.Close()
+			go serveCountsIMAP(ln, counts)
+
+			port := ln.Addr().(*net.TCPAddr).Port
+			body := fmt.Sprintf(`{"host":"127.0.0.1","port":%d,"email":"me@example.com","appPassword":"secret","security":"none"%s}`, port, tt.mailboxes)
+			req := httptest.NewRequest(http.MethodPost, "/email/counts", strings.NewReader(body))
+			rec := httptest.NewRecorder()
+			NewEmailHandler(Options{
+				Timeout:              5 * time.Second,
+				AllowedHosts:         []string{"127.0.0.1"},
+				AllowPrivateNetworks: true,
+				AllowPlaintext:       true,
+			}).EmailCounts(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d; body %s", rec.Code, tt.wantCode, rec.Body)
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			var got generated.EmailCountsResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			if fmt.Sprint(got.Counts) != fmt.Sprint(tt.want) {
+				t.Fatalf("counts = %+v, want %+v", got.Counts, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-imap"
+	"github.com/emersion/go-imap/commands"
+	"github.com/google/uuid"
+
+	"messenger/backend/api/generated"
+	"messenger/backend/pkg/apierror"
+	"messenger/backend/pkg/httpjson"
+)
+
+// codeAppendUID is the UIDPLUS response code (RFC 4315) naming the
+// UIDVALIDITY and UID an APPEND was given.
+const codeAppendUID imap.StatusRespCode = "APPENDUID"
+
+// draftMessage is the content of a draft to be turned into a MIME message.
+type draftMessage struct {
+	from    string
+	to      []string
+	cc      []string
+	subject string
+	body    string
+	date    time.Time
+}
+
+// EmailDraft handles POST /email/draft requests. The message is appended to
+// the account's Drafts mailbox with the \Draft flag, and the response names
+// its UID when the server reports it, so a later send can delete the draft.
+func (h *EmailHandler) EmailDraft(w http.ResponseWriter, r *http.Request) {
+	req, err := httpjson.Decode[generated.EmailDraftRequest](r)
+	if err != nil {
+		apierror.Write(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	login, _, err := h.resolveLogin(r.Context(), generated.EmailLoginRequest{
+		AccountId:   req.AccountId,
+		Host:        req.Host,
+		Port:        req.Port,
+		Email:       req.Email,
+		AppPassword: req.AppPassword,
+		Security:    req.Security,
+	})
+	if err != nil {
+		writeIMAPError(w, r.Context(), err)
+		return
+	}
+
+	draft := draftMessage{from: string(login.Email), date: time.Now()}
+	if req.To != nil {
+		draft.to = *req.To
+	}
+	if req.Cc != nil {
+		draft.cc = *req.Cc
+	}
+	if req.Subject != nil {
+		draft.subject = *req.Subject
+	}
+	if req.Body != nil {
+		draft.body = *req.Body
+	}
+	message, err := buildDraftMessage(draft)
+	if err != nil {
+		apierror.Write(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := h.requestContext(r)
+	defer cancel()
+
+	c, release, err := h.dialAndLogin(ctx, login)
+	if err != nil {
+		writeIMAPError(w, ctx, err)
+		return
+	}
+	defer release()
+
+	listed, err := listMailboxes(c)
+	if err != nil {
+		writeIMAPError(w, ctx, err)
+		return
+	}
+	mailbox := draftsMailbox(listed)
+	if mailbox == "" {
+		apierror.Write(w, http.StatusNotFound, "account has no Drafts mailbox")
+		return
+	}
+
+	status, err := c.Execute(&commands.Append{
+		Mailbox: mailbox,
+		Flags:   []string{imap.DraftFlag},
+		Date:    draft.date,
+		Message: bytes.NewBuffer(message),
+	}, nil)
+	if err == nil {
+		err = status.Err()
+	}
+	if err != nil {
+		writeIMAPError(w, ctx, err)
+		return
+	}
+
+	resp := generated.EmailDraftResponse{Mailbox: mailbox}
+	if uidValidity, uid, ok := appendUID(status); ok {
+		v, u := int64(uidValidity), int64(uid)
+		resp.UidValidity, resp.Uid = &v, &u
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// draftsMailbox picks the mailbox drafts are saved to: the one with the
+// special-use \Drafts attribute (RFC 6154), or else one whose last name
+// segment is "Drafts". It returns "" when there is neither.
+func draftsMailbox(infos []*imap.MailboxInfo) string {
+	for _, info := range infos {
+		for _, attr := range info.Attributes {
+			if strings.EqualFold(attr, imap.DraftsAttr) {
+				return info.Name
+			}
+		}
+	}
+	for _, info := range infos {
+		leaf := info.Name
+		if info.Delimiter != "" {
+			if i := strings.LastIndex(leaf, info.Delimiter); i >= 0 {
+				leaf = leaf[i+len(info.Delimiter):]
+			}
+		}
+		if strings.EqualFold(leaf, "Drafts") {
+			return info.Name
+		}
+	}
+	return ""
+}
+
+// appendUID reads the APPENDUID code of a tagged APPEND response. ok is
+// false when the server does not support UIDPLUS.
+func appendUID(status *imap.StatusResp) (uidValidity, uid uint32, ok bool) {
+	if status == nil || status.Code != codeAppendUID || len(status.Arguments) < 2 {
+		return 0, 0, false
+	}
+	uidValidity, err := imap.ParseNumber(status.Arguments[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	uid, err = imap.ParseNumber(status.Arguments[1])
+	if err != nil {
+		return 0, 0, false
+	}
+	return uidValidity, uid, true
+}
+
+// buildDraftMessage renders d as an RFC 5322 message with a single
+// quoted-printable UTF-8 text part. Recipients that do not parse as
+// addresses are an error.
+func buildDraftMessage(d draftMessage) ([]byte, error) {
+	from, err := mail.ParseAddress(d.from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid email %q: %v", d.from, err)
+	}
+	to, err := parseRecipients("to", d.to)
+	if err != nil {
+		return nil, err
+	}
+	cc, err := parseRecipients("cc", d.cc)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	header := func(name, value string) {
+		buf.WriteString(name + ": " + value + "\r\n")
+	}
+	header("From", from.String())
+	if to != "" {
+		header("To", to)
+	}
+	if cc != "" {
+		header("Cc", cc)
+	}
+	header("Subject", mime.QEncoding.Encode("utf-8", d.subject))
+	header("Date", d.date.Format(time.RFC1123Z))
+	header("Message-ID", "<"+uuid.NewString()+"@"+from.Address[strings.LastIndex(from.Address, "@")+1:]+">")
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "quoted-printable")
+	buf.WriteString("\r\n")
+
+	qp := quotedprintable.NewWriter(&buf)
+	if _, err := qp.Write([]byte(d.body)); err != nil {
+		return nil, err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// parseRecipients formats the addresses of a To or Cc field as a header
+// value, naming field when one of them is invalid.
+func parseRecipients(field string, addrs []string) (string, error) {
+	formatted := make([]string, 0, len(addrs))
+	for _, raw := range addrs {
+		addr, err := mail.ParseAddress(raw)
+		if err != nil {
+			return "", fmt.Errorf("invalid %s address %q: %v", field, raw, err)
+		}
+		formatted = append(formatted, addr.String())
+	}
+	return strings.Join(formatted, ", "), nil
+}
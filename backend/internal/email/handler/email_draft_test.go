@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emersion/go-imap"
+
+	"messenger/backend/api/generated"
+)
+
+func TestBuildDraftMessage(t *testing.T) {
+	raw, err := buildDraftMessage(draftMessage{
+		from:    "me@example.com",
+		to:      []string{"Ann <ann@example.com>", "bob@example.com"},
+		subject: "Grüße",
+		body:    "first line\nsecond = line",
+		date:    time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("buildDraftMessage() error = %v", err)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	if err != nil {
+		t.Fatalf("mail.ReadMessage() error = %v", err)
+	}
+	to, err := msg.Header.AddressList("To")
+	if err != nil || len(to) != 2 || to[0].Name != "Ann" || to[1].Address != "bob@example.com" {
+		t.Fatalf("To = %v (%v), want Ann and bob", to, err)
+	}
+	if got := msg.Header.Get("Cc"); got != "" {
+		t.Fatalf("Cc = %q, want no header", got)
+	}
+	if subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject")); err != nil || subject != "Grüße" {
+		t.Fatalf("Subject = %q (%v), want Grüße", subject, err)
+	}
+	if got := msg.Header.Get("Date"); got != "Wed, 01 May 2024 09:30:00 +0000" {
+		t.Fatalf("Date = %q", got)
+	}
+	if got := msg.Header.Get("Message-ID"); !strings.HasSuffix(got, "@example.com>") {
+		t.Fatalf("Message-ID = %q, want one at example.com", got)
+	}
+	body, _ := io.ReadAll(msg.Body)
+	if got := string(body); got != "first line\r\nsecond =3D line" {
+		t.Fatalf("body = %q", got)
+	}
+}
+
+func TestBuildDraftMessageRejectsBadRecipient(t *testing.T) {
+	_, err := buildDraftMessage(draftMessage{from: "me@example.com", cc: []string{"not an address"}})
+	if err == nil || !strings.Contains(err.Error(), "cc") {
+		t.Fatalf("buildDraftMessage() error = %v, want an invalid cc address", err)
+	}
+}
+
+func TestDraftsMailbox(t *testing.T) {
+	tests := []struct {
+		name  string
+		infos []*imap.MailboxInfo
+		want  string
+	}{
+		{
+			name: "special-use attribute",
+			infos: []*imap.MailboxInfo{
+				{Name: "Drafts", Delimiter: "/"},
+				{Name: "[Gmail]/Entwürfe", Delimiter: "/", Attributes: []string{imap.DraftsAttr}},
+			},
+			want: "[Gmail]/Entwürfe",
+		},
+		{
+			name:  "named Drafts",
+			infos: []*imap.MailboxInfo{{Name: "INBOX", Delimiter: "."}, {Name: "INBOX.Drafts", Delimiter: "."}},
+			want:  "INBOX.Drafts",
+		},
+		{
+			name:  "none",
+			infos: []*imap.MailboxInfo{{Name: "INBOX", Delimiter: "/"}},
+			want:  "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := draftsMailbox(tt.infos); got != tt.want {
+				t.Fatalf("draftsMailbox() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// serveDraftIMAP answers one unencrypted session with a Drafts mailbox,
+// acknowledging APPEND with appendCode and sending the appended flags and
+// message to appended.
+func serveDraftIMAP(ln net.Listener, appendCode string, appended chan<- string) {
+	conn, err := ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(format string, args ...interface{}) { fmt.Fprintf(conn, format+"\r\n", args...) }
+	reply("* OK [CAPABILITY IMAP4rev1 UIDPLUS] ready")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		tag, command, _ := strings.Cut(strings.TrimSpace(line), " ")
+		verb, _, _ := strings.Cut(command, " ")
+		switch strings.ToUpper(verb) {
+		case "LIST":
+			reply(`* LIST (\HasNoChildren) "/" "INBOX"`)
+			reply(`* LIST (\HasNoChildren \Drafts) "/" "Saved"`)
+			reply("%s OK LIST completed", tag)
+		case "APPEND":
+			open := strings.LastIndex(command, "{")
+			size, _ := strconv.Atoi(strings.TrimSuffix(command[open+1:], "}"))
+			reply("+ Ready")
+			message := make([]byte, size)
+			if _, err := io.ReadFull(r, message); err != nil {
+				return
+			}
+			_, _ = r.ReadString('\n')
+			appended <- command[:open] + string(message)
+			reply("%s OK %sAPPEND completed", tag, appendCode)
+		case "LOGOUT":
+			reply("* BYE")
+			reply("%s OK LOGOUT completed", tag)
+			return
+		default:
+			reply("%s OK done", tag)
+		}
+	}
+}
+
+func TestEmailDraftAppendsToDrafts(t *testing.T) {
+	tests := []struct {
+		name       string
+		appendCode string
+		wantUID    bool
+	}{
+		{name: "UIDPLUS", appendCode: "[APPENDUID 38505 3955] ", wantUID: true},
+		{name: "no UIDPLUS", appendCode: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("net.Listen() error = %v", err)
+			}
+			defer ln.Close()
+			appended := make(chan string, 1)
+			go serveDraftIMAP(ln, tt.appendCode, appended)
+
+			port := ln.Addr().(*net.TCPAddr).Port
+			body := fmt.Sprintf(`{"host":"127.0.0.1","port":%d,"email":"me@example.com","appPassword":"secret","security":"none","to":["ann@example.com"],"subject":"Hi","body":"Hello"}`, port)
+			req := httptest.NewRequest(http.MethodPost, "/email/draft", strings.NewReader(body))
+			rec := httptest.NewRecorder()
+			NewEmailHandler(Options{
+				Timeout:              5 * time.Second,
+				AllowedHosts:         []string{"127.0.0.1"},
+				AllowPrivateNetworks: true,
+				AllowPlaintext:       true,
+			}).EmailDraft(rec, req)
+
+			if rec.Code != http.StatusCreated {
+				t.Fatalf("status = %d, want 201; body %s", rec.Code, rec.Body)
+			}
+			var got generated.EmailDraftResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			if got.Mailbox != "Saved" {
+				t.Fatalf("mailbox = %q, want Saved", got.Mailbox)
+			}
+			if tt.wantUID {
+				if got.Uid == nil || *got.Uid != 3955 || got.UidValidity == nil || *got.UidValidity != 38505 {
+					t.Fatalf("response = %+v, want uid 3955 of uidValidity 38505", got)
+				}
+			} else if got.Uid != nil || got.UidValidity != nil {
+				t.Fatalf("response = %+v, want no uid", got)
+			}
+
+			command := <-appended
+			if !strings.Contains(command, `"Saved" (\Draft)`) || !strings.Contains(command, "To: <ann@example.com>") {
+				t.Fatalf("APPEND = %q, want the message in Saved with \\Draft", command)
+			}
+		})
+	}
+}
@@ -2,12 +2,17 @@ package handler
 
 import (
 	"bufio"
+	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"net/textproto"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -15,203 +20,737 @@ import (
 	imapclient "github.com/emersion/go-imap/client"
 
 	"messenger/backend/api/generated"
+	"messenger/backend/pkg/apierror"
+	"messenger/backend/pkg/httpjson"
+	"messenger/backend/pkg/metrics"
+	"messenger/backend/pkg/middleware"
+	"messenger/backend/pkg/secretbox"
 )
 
+// defaultHeaderMailboxes are read by EmailHeaders when the request names none.
+var defaultHeaderMailboxes = []string{"INBOX", "[Gmail]/All Mail", "[Gmail]/Sent Mail", "Sent", "Sent Items"}
+
+// Per-mailbox message limits of EmailHeaders.
+const (
+	defaultHeadersPerMailbox = 1000
+	maxHeadersPerMailbox     = 5000
+)
+
+// DefaultIMAPTimeout bounds how long a single email request may spend talking
+// to the IMAP server when no other timeout is configured.
+const DefaultIMAPTimeout = 30 * time.Second
+
+// Options configures an EmailHandler.
+type Options struct {
+	// Timeout bounds the IMAP round-trips of a single request; a non-positive
+	// value uses DefaultIMAPTimeout.
+	Timeout time.Duration
+	// AllowedHosts lists the IMAP servers clients may connect to, either as
+	// hostnames or as ".example.com" domains. Empty means
+	// DefaultAllowedIMAPHosts.
+	AllowedHosts []string
+	// AllowPrivateNetworks permits allowed hosts that resolve to loopback or
+	// private addresses. Only meant for local development.
+	AllowPrivateNetworks bool
+	// AllowPlaintext permits logins with security "none", which send the
+	// password unencrypted.
+	AllowPlaintext bool
+	// HeaderCache keeps envelopes between EmailHeaders calls. Nil means an
+	// in-memory cache of DefaultHeaderCacheEntries.
+	HeaderCache HeaderCache
+	// Accounts stores the accounts registered with POST /email/accounts, and
+	// Credentials seals their app passwords. Registered accounts are
+	// disabled unless both are set.
+	Accounts    AccountStore
+	Credentials *secretbox.Box
+}
+
 // EmailHandler provides email related endpoints.
-type EmailHandler struct{}
+type EmailHandler struct {
+	timeout        time.Duration
+	hosts          *hostGuard
+	allowPlaintext bool
+	bodies         *bodyCache
+	headers        HeaderCache
+	accounts       AccountStore
+	credentials    *secretbox.Box
+	// rootCAs verifies IMAP servers' certificates; nil means the system pool.
+	rootCAs *x509.CertPool
+}
+
+var (
+	errPlaintextNotAllowed = errors.New(`security "none" is disabled on this server; use tls or starttls`)
+	errNoStartTLS          = errors.New("mail server does not offer STARTTLS")
+	errAuthFailed          = errors.New("authentication failed")
+)
+
+// Error codes telling the account-setup UI which step of reaching a mailbox
+// failed.
+const (
+	codeIMAPConnectFailed = "IMAP_CONNECT_FAILED"
+	codeIMAPTLSFailed     = "IMAP_TLS_FAILED"
+	codeIMAPAuthFailed    = "IMAP_AUTH_FAILED"
+	codeIMAPMailboxFailed = "IMAP_MAILBOX_FAILED"
+)
+
+// imapStepError wraps err with the code of the step it happened in.
+type imapStepError struct {
+	code string
+	err  error
+}
+
+func (e *imapStepError) Error() string {
+	switch e.code {
+	case codeIMAPConnectFailed:
+		return "could not connect to mail server: " + e.err.Error()
+	case codeIMAPTLSFailed:
+		return "secure connection to mail server failed: " + e.err.Error()
+	case codeIMAPMailboxFailed:
+		return "could not open mailbox: " + e.err.Error()
+	}
+	return e.err.Error()
+}
+
+func (e *imapStepError) Unwrap() error { return e.err }
+
+// stepFailed returns nil for a nil err and err tagged with code otherwise.
+func stepFailed(code string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &imapStepError{code: code, err: err}
+}
+
+// loginValidationError lists the EmailLoginRequest fields that cannot work,
+// so clients get a 400 naming them instead of an opaque dial error.
+type loginValidationError []generated.FieldError
+
+func (e loginValidationError) Error() string {
+	problems := make([]string, len(e))
+	for i, fe := range e {
+		problems[i] = strings.TrimPrefix(fe.Field, "/") + " " + fe.Message
+	}
+	return "invalid email login: " + strings.Join(problems, "; ")
+}
+
+// validateLogin checks the connection fields of req. Fields are named by JSON
+// pointer like the spec validator's details.
+func validateLogin(req generated.EmailLoginRequest) error {
+	var problems loginValidationError
+	if strings.TrimSpace(req.Host) == "" {
+		problems = append(problems, generated.FieldError{Field: "/host", Message: "must not be empty"})
+	}
+	if req.Port < 1 || req.Port > 65535 {
+		problems = append(problems, generated.FieldError{Field: "/port", Message: "must be between 1 and 65535"})
+	}
+	if strings.TrimSpace(string(req.Email)) == "" {
+		problems = append(problems, generated.FieldError{Field: "/email", Message: "must not be empty"})
+	}
+	if req.AppPassword == "" {
+		problems = append(problems, generated.FieldError{Field: "/appPassword", Message: "must not be empty"})
+	}
+	if len(problems) > 0 {
+		return problems
+	}
+	return nil
+}
 
 // NewEmailHandler creates a new EmailHandler.
-func NewEmailHandler() *EmailHandler {
-	return &EmailHandler{}
+func NewEmailHandler(opts Options) *EmailHandler {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultIMAPTimeout
+	}
+	if len(opts.AllowedHosts) == 0 {
+		opts.AllowedHosts = DefaultAllowedIMAPHosts
+	}
+	if opts.HeaderCache == nil {
+		opts.HeaderCache = NewMemoryHeaderCache(DefaultHeaderCacheEntries, nil)
+	}
+	return &EmailHandler{
+		timeout:        opts.Timeout,
+		hosts:          newHostGuard(opts.AllowedHosts, opts.AllowPrivateNetworks),
+		allowPlaintext: opts.AllowPlaintext,
+		bodies:         newBodyCache(defaultBodyCacheBytes),
+		headers:        opts.HeaderCache,
+		accounts:       opts.Accounts,
+		credentials:    opts.Credentials,
+	}
 }
 
-// fetchHeaders is a small helper that signs in to the requested mailbox and
-// returns the latest envelopes plus the server-reported unread count. It keeps
-// the backend focused on transport and leaves any higher-level logic to the
-// client.
-func fetchHeaders(req generated.EmailLoginRequest, mailbox string, criteria *imap.SearchCriteria) ([]generated.EmailMessageHeader, uint32, error) {
-	addr := fmt.Sprintf("%s:%d", req.Host, req.Port)
-	c, err := imapclient.DialTLS(addr, &tls.Config{})
+// requestContext derives the context that bounds the IMAP work of r. It is
+// cancelled when the client goes away or the configured timeout passes.
+func (h *EmailHandler) requestContext(r *http.Request) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(r.Context(), h.timeout)
+}
+
+// uidCursor asks fetchHeaders for the messages after UID since instead of
+// the latest ones. uidValidity is the value the client stored with since; 0
+// means it has none to compare.
+type uidCursor struct {
+	since       uint32
+	uidValidity uint32
+}
+
+// headerPage is the result of fetchHeaders.
+type headerPage struct {
+	headers     []generated.EmailMessageHeader
+	unread      uint32
+	uidValidity uint32
+	// resync is set when the cursor's UIDVALIDITY no longer matches, so its
+	// UIDs are meaningless and headers is empty.
+	resync bool
+	// hasMore is set when more messages follow the cursor than were returned.
+	hasMore bool
+}
+
+// respondWithSearch is respondWithHeaders for several mailboxes: the
+// criteria are run in each over one connection and the pages merged as
+// described for mergeMessageHeaders. A mailbox that cannot be selected (it
+// does not exist, or the server refuses it) is skipped so the others still
+// answer; unreadCount and uidValidity are per-mailbox values and are left out.
+func (h *EmailHandler) respondWithSearch(
+	w http.ResponseWriter,
+	r *http.Request,
+	req generated.EmailLoginRequest,
+	mailboxes []string,
+	withFlags []string,
+) {
+	ctx, cancel := h.requestContext(r)
+	defer cancel()
+
+	c, release, err := h.dialAndLogin(ctx, req)
 	if err != nil {
-		return nil, 0, err
+		writeIMAPError(w, ctx, err)
+		return
 	}
-	defer c.Logout()
+	defer release()
 
-	if err := c.Login(string(req.Email), req.AppPassword); err != nil {
-		return nil, 0, fmt.Errorf("authentication failed")
+	batches := make([][]generated.EmailMessageHeader, 0, len(mailboxes))
+	for _, mailbox := range mailboxes {
+		var criteria *imap.SearchCriteria
+		if len(withFlags) > 0 {
+			criteria = imap.NewSearchCriteria()
+			criteria.WithFlags = append(criteria.WithFlags, withFlags...)
+		}
+		page, err := mailboxPage(c, mailbox, criteria, nil)
+		var step *imapStepError
+		switch {
+		case ctx.Err() != nil:
+			writeIMAPError(w, ctx, ctx.Err())
+			return
+		case errors.As(err, &step) && step.code == codeIMAPMailboxFailed:
+			middleware.Logf(ctx, "email search skipped mailbox %q: %v", mailbox, err)
+			continue
+		case err != nil:
+			writeIMAPError(w, ctx, err)
+			return
+		}
+		for i := range page.headers {
+			page.headers[i].Mailbox = &mailbox
+		}
+		batches = append(batches, page.headers)
 	}
 
+	messages := mergeMessageHeaders(batches, headerPageLimit)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(generated.EmailMessagesResponse{Messages: &messages})
+}
+
+// headerPageLimit is how many envelopes fetchHeaders returns at most.
+const headerPageLimit = 25
+
+// fetchHeaders is a small helper that signs in to the requested mailbox and
+// returns the latest envelopes plus the server-reported unread count. With a
+// cursor it returns the oldest envelopes after the cursor's UID instead, so
+// clients can page forward through new mail. It keeps the backend focused on
+// transport and leaves any higher-level logic to the client.
+func (h *EmailHandler) fetchHeaders(ctx context.Context, req generated.EmailLoginRequest, mailbox string, criteria *imap.SearchCriteria, cursor *uidCursor) (headerPage, error) {
+	c, release, err := h.dialAndLogin(ctx, req)
+	if err != nil {
+		return headerPage{}, err
+	}
+	defer release()
+
+	return mailboxPage(c, mailbox, criteria, cursor)
+}
+
+// mailboxPage selects mailbox on an already signed-in client and returns its
+// page of envelopes, as described for fetchHeaders.
+func mailboxPage(c *imapclient.Client, mailbox string, criteria *imap.SearchCriteria, cursor *uidCursor) (headerPage, error) {
 	mbox, err := c.Select(mailbox, true)
 	if err != nil {
-		return nil, 0, err
+		return headerPage{}, stepFailed(codeIMAPMailboxFailed, err)
 	}
 
-	const limit uint32 = 25
+	page := headerPage{headers: []generated.EmailMessageHeader{}, unread: mbox.Unseen, uidValidity: mbox.UidValidity}
+	if cursor != nil && cursor.uidValidity != 0 && cursor.uidValidity != mbox.UidValidity {
+		page.resync = true
+		return page, nil
+	}
+	// An empty mailbox has nothing to search or fetch, and some servers
+	// reject ranges such as 1:* when there are no messages.
+	if mbox.Messages == 0 {
+		return page, nil
+	}
 	seqset := new(imap.SeqSet)
+	byUID := false
 
-	if criteria != nil {
+	switch {
+	case cursor != nil:
+		if criteria == nil {
+			criteria = imap.NewSearchCriteria()
+		}
+		criteria.Uid = new(imap.SeqSet)
+		criteria.Uid.AddRange(cursor.since+1, 0)
+		uids, err := c.UidSearch(criteria)
+		if err != nil {
+			return headerPage{}, err
+		}
+		uids, page.hasMore = uidsAfter(uids, cursor.since, headerPageLimit)
+		if len(uids) == 0 {
+			return page, nil
+		}
+		seqset.AddNum(uids...)
+		byUID = true
+	case criteria != nil:
 		ids, err := c.Search(criteria)
 		if err != nil {
-			return nil, 0, err
+			return headerPage{}, err
 		}
 		if len(ids) == 0 {
-			return []generated.EmailMessageHeader{}, mbox.Unseen, nil
+			return page, nil
 		}
 		start := 0
-		if len(ids) > int(limit) {
-			start = len(ids) - int(limit)
-		}
-		for _, id := range ids[start:] {
-			seqset.AddNum(id)
+		if len(ids) > headerPageLimit {
+			start = len(ids) - headerPageLimit
 		}
-	} else {
+		seqset.AddNum(ids[start:]...)
+	default:
 		from := uint32(1)
-		if mbox.Messages > limit {
-			from = mbox.Messages - limit + 1
+		if mbox.Messages > headerPageLimit {
+			from = mbox.Messages - headerPageLimit + 1
 		}
 		seqset.AddRange(from, mbox.Messages)
 	}
 
-	messages := make(chan *imap.Message, limit)
+	messages := make(chan *imap.Message, headerPageLimit)
 	done := make(chan error, 1)
+	items := []imap.FetchItem{imap.FetchUid, imap.FetchEnvelope, imap.FetchFlags}
 	go func() {
-		done <- c.Fetch(seqset, []imap.FetchItem{imap.FetchEnvelope, imap.FetchFlags}, messages)
+		if byUID {
+			done <- c.UidFetch(seqset, items, messages)
+		} else {
+			done <- c.Fetch(seqset, items, messages)
+		}
 	}()
 
-	headers := make([]generated.EmailMessageHeader, 0, limit)
 	for msg := range messages {
 		env := msg.Envelope
 		if env == nil {
 			continue
 		}
-		var fromPtr *string
-		if len(env.From) > 0 {
-			addr := env.From[0]
-			formatted := fmt.Sprintf("%s@%s", addr.MailboxName, addr.HostName)
-			if addr.PersonalName != "" {
-				formatted = fmt.Sprintf("%s <%s>", addr.PersonalName, formatted)
-			}
-			fromPtr = &formatted
-		}
 		subject := env.Subject
 		subjectPtr := &subject
 		date := env.Date
-		headers = append(headers, generated.EmailMessageHeader{
-			From:    fromPtr,
+		uid := int64(msg.Uid)
+		header := generated.EmailMessageHeader{
+			Uid:     &uid,
+			From:    firstAddress(env.From),
+			To:      addressList(env.To),
+			Cc:      addressList(env.Cc),
+			ReplyTo: addressList(env.ReplyTo),
 			Subject: subjectPtr,
 			Date:    &date,
-		})
+		}
+		if env.MessageId != "" {
+			messageID := env.MessageId
+			header.MessageId = &messageID
+		}
+		page.headers = append(page.headers, header)
 	}
 	if err := <-done; err != nil {
-		return nil, 0, err
+		return headerPage{}, err
+	}
+	if byUID {
+		sort.Slice(page.headers, func(i, j int) bool { return *page.headers[i].Uid < *page.headers[j].Uid })
 	}
 
-	return headers, mbox.Unseen, nil
+	return page, nil
+}
+
+// uidsAfter returns, in ascending order, the first limit UIDs above since and
+// whether more remain. The search for "since+1:*" also matches the newest
+// message when nothing is newer, because "*" stands for the highest UID in use,
+// so that one is filtered out here.
+func uidsAfter(uids []uint32, since uint32, limit int) ([]uint32, bool) {
+	newer := make([]uint32, 0, len(uids))
+	for _, uid := range uids {
+		if uid > since {
+			newer = append(newer, uid)
+		}
+	}
+	sort.Slice(newer, func(i, j int) bool { return newer[i] < newer[j] })
+	if len(newer) > limit {
+		return newer[:limit], true
+	}
+	return newer, false
+}
+
+// dialAndLogin connects to the requested IMAP server, secured as the request
+// asks (implicit TLS unless it says otherwise), and signs in. Failures are
+// tagged with the step that failed (connect, TLS, login) so writeIMAPError can
+// report it. The connection is closed as soon as ctx is done, which makes any
+// command blocked on it (and the goroutine running it) return; callers must
+// invoke release once they are finished with the client. Hosts outside the
+// allow-list are refused with errHostNotAllowed before anything is dialed.
+// Every attempt is counted in the imap_connections_total metric.
+func (h *EmailHandler) dialAndLogin(ctx context.Context, req generated.EmailLoginRequest) (*imapclient.Client, func(), error) {
+	c, release, err := h.connect(ctx, req)
+	metrics.ObserveIMAPConnection(connectionOutcome(ctx, err))
+	return c, release, err
+}
+
+// connectionOutcome is the metric label for the result of dialAndLogin.
+func connectionOutcome(ctx context.Context, err error) string {
+	var step *imapStepError
+	switch {
+	case err == nil:
+		return "ok"
+	case ctx.Err() != nil:
+		return "timeout"
+	case errors.As(err, &step):
+		return strings.ToLower(strings.TrimPrefix(step.code, "IMAP_"))
+	case errors.Is(err, errHostNotAllowed), errors.Is(err, errPlaintextNotAllowed), errors.As(err, new(loginValidationError)):
+		return "rejected"
+	}
+	return "error"
+}
+
+func (h *EmailHandler) connect(ctx context.Context, req generated.EmailLoginRequest) (*imapclient.Client, func(), error) {
+	if err := validateLogin(req); err != nil {
+		return nil, nil, err
+	}
+	security := generated.Tls
+	if req.Security != nil {
+		security = *req.Security
+	}
+	if security == generated.None && !h.allowPlaintext {
+		return nil, nil, errPlaintextNotAllowed
+	}
+
+	addrs, err := h.hosts.resolve(ctx, req.Host)
+	if errors.Is(err, errHostNotAllowed) {
+		return nil, nil, err
+	}
+	if err != nil {
+		return nil, nil, stepFailed(codeIMAPConnectFailed, err)
+	}
+
+	var dialer net.Dialer
+	var conn net.Conn
+	for _, addr := range addrs {
+		conn, err = dialer.DialContext(ctx, "tcp", net.JoinHostPort(addr.String(), strconv.Itoa(int(req.Port))))
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, nil, stepFailed(codeIMAPConnectFailed, err)
+	}
+	// Watch the raw connection rather than the client so that a server that
+	// stalls during the TLS handshake or greeting is cut off too.
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+
+	tlsConfig := &tls.Config{ServerName: req.Host, RootCAs: h.rootCAs}
+	var c *imapclient.Client
+	switch security {
+	case generated.Starttls:
+		c, err = imapclient.New(conn)
+		if err != nil {
+			err = stepFailed(codeIMAPConnectFailed, err)
+		} else {
+			err = stepFailed(codeIMAPTLSFailed, startTLS(c, tlsConfig))
+		}
+	case generated.None:
+		c, err = imapclient.New(conn)
+		err = stepFailed(codeIMAPConnectFailed, err)
+	default:
+		// Handshake before reading the greeting so certificate problems are
+		// told apart from servers that do not speak IMAP.
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err = tlsConn.HandshakeContext(ctx); err != nil {
+			err = stepFailed(codeIMAPTLSFailed, err)
+		} else {
+			c, err = imapclient.New(tlsConn)
+			err = stepFailed(codeIMAPConnectFailed, err)
+		}
+	}
+	if err != nil {
+		stop()
+		_ = conn.Close()
+		return nil, nil, err
+	}
+	release := func() {
+		if stop() {
+			_ = c.Logout()
+		}
+	}
+
+	if err := c.Login(string(req.Email), req.AppPassword); err != nil {
+		release()
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+		return nil, nil, stepFailed(codeIMAPAuthFailed, errAuthFailed)
+	}
+	return c, release, nil
+}
+
+// startTLS upgrades a plain connection, refusing servers that do not offer
+// STARTTLS rather than logging in unencrypted.
+func startTLS(c *imapclient.Client, tlsConfig *tls.Config) error {
+	ok, err := c.SupportStartTLS()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errNoStartTLS
+	}
+	return c.StartTLS(tlsConfig)
+}
+
+// writeIMAPError maps err to a status code: 400 for invalid login fields, a
+// refused host or plaintext login, 404, 409 or 501 when the registered
+// account a request names is missing, unreadable or disabled, 504 when ctx
+// ran out before the IMAP server answered, 401 for rejected credentials, 502
+// with an IMAP_* code when the server could not be reached, secured or its
+// mailbox opened, and 500 otherwise.
+func writeIMAPError(w http.ResponseWriter, ctx context.Context, err error) {
+	var invalid loginValidationError
+	if errors.As(err, &invalid) {
+		apierror.WriteCode(w, http.StatusBadRequest, apierror.CodeValidation, err.Error(), invalid...)
+		return
+	}
+	var step *imapStepError
+	switch {
+	case errors.Is(err, errHostNotAllowed), errors.Is(err, errPlaintextNotAllowed), errors.Is(err, errAccountWithLogin):
+		apierror.Write(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, errAccountNotFound):
+		apierror.Write(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, errAccountUnreadable):
+		apierror.Write(w, http.StatusConflict, err.Error())
+	case errors.Is(err, errAccountsDisabled), errors.Is(err, errMoveUnsupported):
+		apierror.Write(w, http.StatusNotImplemented, err.Error())
+	case ctx.Err() != nil:
+		apierror.Write(w, http.StatusGatewayTimeout, fmt.Sprintf("mail server did not respond in time: %v", ctx.Err()))
+	case errors.As(err, &step) && step.code == codeIMAPAuthFailed:
+		apierror.WriteCode(w, http.StatusUnauthorized, step.code, err.Error())
+	case errors.As(err, &step):
+		apierror.WriteCode(w, http.StatusBadGateway, step.code, err.Error())
+	default:
+		apierror.Write(w, http.StatusInternalServerError, err.Error())
+	}
 }
 
 // EmailLoginTest handles POST /email/login-test requests.
 func (h *EmailHandler) EmailLoginTest(w http.ResponseWriter, r *http.Request) {
-	var req generated.EmailLoginRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	req, err := httpjson.Decode[generated.EmailLoginRequest](r)
+	if err != nil {
+		apierror.Write(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	headers, unread, err := fetchHeaders(req, "INBOX", nil)
+	req, mailbox, err := h.resolveLogin(r.Context(), req)
 	if err != nil {
-		status := http.StatusInternalServerError
-		if err.Error() == "authentication failed" {
-			status = http.StatusUnauthorized
-		}
-		http.Error(w, err.Error(), status)
+		writeIMAPError(w, r.Context(), err)
 		return
 	}
+	ctx, cancel := h.requestContext(r)
+	defer cancel()
 
-	unreadCount := int32(unread)
-	resp := generated.EmailMessagesResponse{Messages: &headers, UnreadCount: &unreadCount}
+	page, err := h.fetchHeaders(ctx, req, mailbox, nil, nil)
+	if err != nil {
+		writeIMAPError(w, ctx, err)
+		return
+	}
+
+	unreadCount := int32(page.unread)
+	uidValidity := int64(page.uidValidity)
+	resp := generated.EmailMessagesResponse{Messages: &page.headers, UnreadCount: &unreadCount, UidValidity: &uidValidity}
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(resp)
 }
 
 // EmailInbox handles POST /email/inbox requests.
 func (h *EmailHandler) EmailInbox(w http.ResponseWriter, r *http.Request) {
-	var req generated.EmailLoginRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	req, err := httpjson.Decode[generated.EmailLoginRequest](r)
+	if err != nil {
+		apierror.Write(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	h.respondWithHeaders(w, req, "INBOX", nil)
+	req, _, err = h.resolveLogin(r.Context(), req)
+	if err != nil {
+		writeIMAPError(w, r.Context(), err)
+		return
+	}
+	h.respondWithHeaders(w, r, req, "INBOX", nil, nil)
 }
 
 // EmailImportant now signals deprecation in favor of /api/v1/email/list.
 func (h *EmailHandler) EmailImportant(w http.ResponseWriter, r *http.Request) {
-	http.Error(w, "deprecated: use /api/v1/email/list", http.StatusGone)
+	apierror.Write(w, http.StatusGone, "deprecated: use /api/v1/email/list")
 }
 
 // EmailList handles POST /email/list requests for arbitrary mailbox/flag queries.
 func (h *EmailHandler) EmailList(w http.ResponseWriter, r *http.Request) {
-	var req generated.EmailListRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	req, err := httpjson.Decode[generated.EmailListRequest](r)
+	if err != nil {
+		apierror.Write(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	mailbox := "INBOX"
+	// EmailListRequest is an allOf of EmailLoginRequest + extra fields.
+	// The generated type flattens fields, so construct the login request explicitly.
+	login, mailbox, err := h.resolveLogin(r.Context(), generated.EmailLoginRequest{
+		AccountId:   req.AccountId,
+		Host:        req.Host,
+		Port:        req.Port,
+		Email:       req.Email,
+		AppPassword: req.AppPassword,
+		Security:    req.Security,
+	})
+	if err != nil {
+		writeIMAPError(w, r.Context(), err)
+		return
+	}
 	if req.Mailbox != nil {
 		if trimmed := strings.TrimSpace(*req.Mailbox); trimmed != "" {
 			mailbox = trimmed
 		}
 	}
-
-    // EmailListRequest is an allOf of EmailLoginRequest + extra fields.
-    // The generated type flattens fields, so construct the login request explicitly.
-    login := generated.EmailLoginRequest{
-        Host:        req.Host,
-        Port:        req.Port,
-        Email:       req.Email,
-        AppPassword: req.AppPassword,
-    }
-    var flags []string
-    if req.SearchFlags != nil { flags = *req.SearchFlags }
-    h.respondWithHeaders(w, login, mailbox, flags)
+	var flags []string
+	if req.SearchFlags != nil {
+		flags = *req.SearchFlags
+	}
+	if req.Mailboxes != nil {
+		mailboxes := requestedMailboxes(*req.Mailboxes)
+		switch {
+		case len(mailboxes) == 0:
+			apierror.Write(w, http.StatusBadRequest, "mailboxes must name at least one mailbox")
+		case req.Mailbox != nil || req.SinceUid != nil:
+			apierror.Write(w, http.StatusBadRequest, "mailboxes cannot be combined with mailbox or sinceUid")
+		default:
+			h.respondWithSearch(w, r, login, mailboxes, flags)
+		}
+		return
+	}
+	var cursor *uidCursor
+	if req.SinceUid != nil {
+		cursor = &uidCursor{since: uint32(*req.SinceUid)}
+		if req.UidValidity != nil {
+			cursor.uidValidity = uint32(*req.UidValidity)
+		}
+	}
+	h.respondWithHeaders(w, r, login, mailbox, flags, cursor)
 }
 
 // EmailThreads is kept for backwards compatibility with the OpenAPI definition
 // but the frontend now threads client-side. Return 410 to signal the move.
 func (h *EmailHandler) EmailThreads(w http.ResponseWriter, r *http.Request) {
-	http.Error(w, "deprecated: use /api/v1/email/headers for raw headers", http.StatusGone)
+	apierror.Write(w, http.StatusGone, "deprecated: use /api/v1/email/headers for raw headers")
 }
 
 // EmailHeaders proxies envelopes plus threading identifiers so the client can
-// perform grouping locally.
-func (h *EmailHandler) EmailHeaders(w http.ResponseWriter, r *http.Request) {
-	var req generated.EmailLoginRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+// perform grouping locally. With thread=true the server groups them instead.
+// A mailbox named in the request that cannot be opened is answered with 404,
+// so a misspelt folder is not mistaken for an empty one; of the default
+// mailboxes, those the server lacks are skipped.
+func (h *EmailHandler) EmailHeaders(w http.ResponseWriter, r *http.Request, params generated.EmailHeadersParams) {
+	req, err := httpjson.Decode[generated.EmailHeadersRequest](r)
+	if err != nil {
+		apierror.Write(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	addr := fmt.Sprintf("%s:%d", req.Host, req.Port)
-	c, err := imapclient.DialTLS(addr, &tls.Config{})
+	perBoxLimit := uint32(defaultHeadersPerMailbox)
+	if req.Limit != nil {
+		if *req.Limit < 1 || *req.Limit > maxHeadersPerMailbox {
+			apierror.Write(w, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxHeadersPerMailbox))
+			return
+		}
+		perBoxLimit = uint32(*req.Limit)
+	}
+	mailboxes := defaultHeaderMailboxes
+	if req.Mailboxes != nil {
+		mailboxes = requestedMailboxes(*req.Mailboxes)
+		if len(mailboxes) == 0 {
+			apierror.Write(w, http.StatusBadRequest, "mailboxes must name at least one mailbox")
+			return
+		}
+	}
+	var previous syncToken
+	if req.SyncToken != nil && *req.SyncToken != "" {
+		var err error
+		if previous, err = decodeSyncToken(*req.SyncToken); err != nil {
+			apierror.Write(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	login, _, err := h.resolveLogin(r.Context(), generated.EmailLoginRequest{
+		AccountId:   req.AccountId,
+		Host:        req.Host,
+		Port:        req.Port,
+		Email:       req.Email,
+		AppPassword: req.AppPassword,
+		Security:    req.Security,
+	})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeIMAPError(w, r.Context(), err)
 		return
 	}
-	defer c.Logout()
+	ctx, cancel := h.requestContext(r)
+	defer cancel()
 
-	if err := c.Login(string(req.Email), req.AppPassword); err != nil {
-		http.Error(w, "authentication failed", http.StatusUnauthorized)
+	c, release, err := h.dialAndLogin(ctx, login)
+	if err != nil {
+		writeIMAPError(w, ctx, err)
 		return
 	}
+	defer release()
 
-	mailboxes := []string{"INBOX", "[Gmail]/All Mail", "[Gmail]/Sent Mail", "Sent", "Sent Items"}
-	const perBoxLimit uint32 = 1000
-	out := make([]generated.EmailRichHeader, 0, 2*perBoxLimit)
+	account := headerCacheAccount(login)
+	batches := make([][]generated.EmailRichHeader, 0, len(mailboxes))
+	current := make(syncToken, len(mailboxes))
+	var unchanged []string
 
 	for _, mboxName := range mailboxes {
+		if ctx.Err() != nil {
+			break
+		}
 		mbox, err := c.Select(mboxName, true)
 		if err != nil {
+			if ctx.Err() != nil || c.State() == imap.LogoutState {
+				writeIMAPError(w, ctx, stepFailed(codeIMAPMailboxFailed, err))
+				return
+			}
+			// The defaults are guesses at where mail lives on common
+			// servers; a mailbox the caller named must exist.
+			if req.Mailboxes == nil {
+				continue
+			}
+			apierror.Write(w, http.StatusNotFound, fmt.Sprintf("mailbox %q does not exist or cannot be opened: %v", mboxName, err))
+			return
+		}
+		state := syncStateOf(mbox)
+		if previous.unchanged(mboxName, state) {
+			current[mboxName] = state
+			unchanged = append(unchanged, mboxName)
+			continue
+		}
+		if mbox.Messages == 0 {
+			current[mboxName] = state
 			continue
 		}
 		seqset := new(imap.SeqSet)
@@ -221,74 +760,205 @@ func (h *EmailHandler) EmailHeaders(w http.ResponseWriter, r *http.Request) {
 		}
 		seqset.AddRange(from, mbox.Messages)
 
-		fetchItems := []imap.FetchItem{imap.FetchEnvelope, imap.FetchItem("BODY.PEEK[HEADER.FIELDS (Message-ID In-Reply-To References)]")}
-		messages := make(chan *imap.Message, 200)
-		done := make(chan error, 1)
-		go func() { done <- c.Fetch(seqset, fetchItems, messages) }()
-
-		for msg := range messages {
-			env := msg.Envelope
-			if env == nil {
-				continue
-			}
-			var fromPtr *string
-			if len(env.From) > 0 {
-				a := env.From[0]
-				formatted := fmt.Sprintf("%s@%s", a.MailboxName, a.HostName)
-				if a.PersonalName != "" {
-					formatted = fmt.Sprintf("%s <%s>", a.PersonalName, formatted)
-				}
-				fromPtr = &formatted
-			}
-			subj := env.Subject
-			subjPtr := &subj
-			date := env.Date
-
-			messageID := strings.Trim(env.MessageId, "<>")
-			var messageIDPtr *string
-			if messageID != "" {
-				messageIDPtr = &messageID
-			}
+		// A mailbox that failed part way still contributes what arrived, but
+		// stays out of the sync token so the next call reads it again.
+		batch, err := h.mailboxHeaders(ctx, c, account, mboxName, mbox.UidValidity, seqset)
+		if err != nil {
+			middleware.Logf(ctx, "reading headers of mailbox %q failed: %v", mboxName, err)
+		} else {
+			current[mboxName] = state
+		}
+		batches = append(batches, batch)
+	}
 
-			inReply := strings.Trim(env.InReplyTo, "<>")
-			var inReplyPtr *string
-			if inReply != "" {
-				inReplyPtr = &inReply
-			}
+	if ctx.Err() != nil {
+		writeIMAPError(w, ctx, ctx.Err())
+		return
+	}
 
-			refs := readRefsFromBody(msg)
-			var refsPtr *[]string
-			if len(refs) > 0 {
-				refsPtr = &refs
-			}
+	out := mergeMailboxHeaders(batches)
 
-			out = append(out, generated.EmailRichHeader{
-				From:       fromPtr,
-				Subject:    subjPtr,
-				Date:       &date,
-				MessageId:  messageIDPtr,
-				InReplyTo:  inReplyPtr,
-				References: refsPtr,
-			})
+	resp := generated.EmailRichHeadersResponse{Messages: out}
+	if params.Thread != nil && *params.Thread {
+		threads := threadHeaders(out)
+		resp = generated.EmailRichHeadersResponse{Messages: []generated.EmailRichHeader{}, Threads: &threads}
+	}
+	token := current.encode()
+	resp.SyncToken = &token
+	if previous != nil {
+		if unchanged == nil {
+			unchanged = []string{}
 		}
-		if err := <-done; err != nil {
-			// ignore partial mailbox errors so other boxes can still contribute
+		resp.UnchangedMailboxes = &unchanged
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// mailboxHeaders returns the rich headers of the messages in seqset of the
+// selected mailbox. Only UIDs are listed for the whole range; envelopes are
+// served from the header cache where possible and fetched for the rest.
+func (h *EmailHandler) mailboxHeaders(ctx context.Context, c *imapclient.Client, account, mboxName string, uidValidity uint32, seqset *imap.SeqSet) ([]generated.EmailRichHeader, error) {
+	uidMessages := make(chan *imap.Message, 200)
+	done := make(chan error, 1)
+	go func() { done <- c.Fetch(seqset, []imap.FetchItem{imap.FetchUid}, uidMessages) }()
+	var uids []uint32
+	for msg := range uidMessages {
+		uids = append(uids, msg.Uid)
+	}
+	if err := <-done; err != nil {
+		return nil, err
+	}
+
+	cached, err := h.headers.GetHeaders(ctx, account, mboxName, uidValidity, uids)
+	if err != nil {
+		// A cache outage only costs a full fetch.
+		middleware.Logf(ctx, "email header cache read failed: %v", err)
+	}
+	batch := make([]generated.EmailRichHeader, 0, len(uids))
+	missing := new(imap.SeqSet)
+	for _, uid := range uids {
+		if header, ok := cached[uid]; ok {
+			batch = append(batch, header)
+		} else {
+			missing.AddNum(uid)
 		}
 	}
+	metrics.ObserveHeaderCacheLookups(len(batch), len(uids)-len(batch))
+	if missing.Empty() {
+		return batch, nil
+	}
+
+	fetchItems := []imap.FetchItem{imap.FetchUid, imap.FetchEnvelope, imap.FetchItem("BODY.PEEK[HEADER.FIELDS (Message-ID In-Reply-To References)]")}
+	messages := make(chan *imap.Message, 200)
+	go func() { done <- c.UidFetch(missing, fetchItems, messages) }()
 
-	sort.Slice(out, func(i, j int) bool {
-		var di, dj time.Time
-		if out[i].Date != nil {
-			di = *out[i].Date
+	fetched := make([]generated.EmailRichHeader, 0, len(uids)-len(batch))
+	for msg := range messages {
+		env := msg.Envelope
+		if env == nil {
+			continue
 		}
-		if out[j].Date != nil {
-			dj = *out[j].Date
+		subj := env.Subject
+		subjPtr := &subj
+		date := env.Date
+
+		messageID := strings.Trim(env.MessageId, "<>")
+		var messageIDPtr *string
+		if messageID != "" {
+			messageIDPtr = &messageID
 		}
-		return di.After(dj)
+
+		inReply := strings.Trim(env.InReplyTo, "<>")
+		var inReplyPtr *string
+		if inReply != "" {
+			inReplyPtr = &inReply
+		}
+
+		uid := int64(msg.Uid)
+		mailbox := mboxName
+
+		refs := readRefsFromBody(msg)
+		var refsPtr *[]string
+		if len(refs) > 0 {
+			refsPtr = &refs
+		}
+
+		fetched = append(fetched, generated.EmailRichHeader{
+			Uid:        &uid,
+			Mailbox:    &mailbox,
+			From:       firstAddress(env.From),
+			To:         addressList(env.To),
+			Cc:         addressList(env.Cc),
+			ReplyTo:    addressList(env.ReplyTo),
+			Subject:    subjPtr,
+			Date:       &date,
+			MessageId:  messageIDPtr,
+			InReplyTo:  inReplyPtr,
+			References: refsPtr,
+		})
+	}
+	fetchErr := <-done
+	// Whatever arrived is complete per message, so it can be cached and
+	// returned even when the fetch broke off part way.
+	if err := h.headers.PutHeaders(ctx, account, mboxName, uidValidity, fetched); err != nil {
+		middleware.Logf(ctx, "email header cache write failed: %v", err)
+	}
+	return append(batch, fetched...), fetchErr
+}
+
+// requestedMailboxes trims the mailbox names of a request and drops blanks
+// and repeats, keeping the caller's order.
+func requestedMailboxes(names []string) []string {
+	out := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		out = append(out, name)
+	}
+	return out
+}
+
+// mergeMailboxHeaders flattens the per-mailbox results into a single list
+// sorted newest first. A message filed in several mailboxes (e.g. INBOX and
+// Gmail's All Mail) is kept once, from the first mailbox it was seen in, so
+// batches should be passed in order of preference. Headers without a
+// Message-ID cannot be matched up and are all kept.
+func mergeMailboxHeaders(batches [][]generated.EmailRichHeader) []generated.EmailRichHeader {
+	return mergeByMessageID(batches, func(h generated.EmailRichHeader) (*string, *time.Time) {
+		return h.MessageId, h.Date
 	})
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(generated.EmailRichHeadersResponse{Messages: out})
+// mergeMessageHeaders merges the /email/list pages of several mailboxes the
+// way mergeMailboxHeaders does, keeping only the newest limit messages.
+func mergeMessageHeaders(batches [][]generated.EmailMessageHeader, limit int) []generated.EmailMessageHeader {
+	out := mergeByMessageID(batches, func(h generated.EmailMessageHeader) (*string, *time.Time) {
+		return h.MessageId, h.Date
+	})
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// mergeByMessageID implements mergeMailboxHeaders for any header type; fields
+// returns a header's Message-ID and date.
+func mergeByMessageID[H any](batches [][]H, fields func(H) (*string, *time.Time)) []H {
+	total := 0
+	for _, batch := range batches {
+		total += len(batch)
+	}
+
+	out := make([]H, 0, total)
+	seen := make(map[string]struct{}, total)
+	for _, batch := range batches {
+		for _, header := range batch {
+			if id, _ := fields(header); id != nil && *id != "" {
+				if _, dup := seen[*id]; dup {
+					continue
+				}
+				seen[*id] = struct{}{}
+			}
+			out = append(out, header)
+		}
+	}
+
+	dateOf := func(h H) time.Time {
+		if _, date := fields(h); date != nil {
+			return *date
+		}
+		return time.Time{}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return dateOf(out[i]).After(dateOf(out[j]))
+	})
+	return out
 }
 
 // readRefsFromBody extracts the References header from any literal body parts
@@ -339,9 +1009,11 @@ func extractMessageIDs(s string) []string {
 
 func (h *EmailHandler) respondWithHeaders(
 	w http.ResponseWriter,
+	r *http.Request,
 	req generated.EmailLoginRequest,
 	mailbox string,
 	withFlags []string,
+	cursor *uidCursor,
 ) {
 	var criteria *imap.SearchCriteria
 	if len(withFlags) > 0 {
@@ -349,18 +1021,26 @@ func (h *EmailHandler) respondWithHeaders(
 		criteria.WithFlags = append(criteria.WithFlags, withFlags...)
 	}
 
-	headers, unread, err := fetchHeaders(req, mailbox, criteria)
+	ctx, cancel := h.requestContext(r)
+	defer cancel()
+
+	page, err := h.fetchHeaders(ctx, req, mailbox, criteria, cursor)
 	if err != nil {
-		status := http.StatusInternalServerError
-		if err.Error() == "authentication failed" {
-			status = http.StatusUnauthorized
-		}
-		http.Error(w, err.Error(), status)
+		writeIMAPError(w, ctx, err)
 		return
 	}
 
-	unreadCount := int32(unread)
-	resp := generated.EmailMessagesResponse{Messages: &headers, UnreadCount: &unreadCount}
+	unreadCount := int32(page.unread)
+	uidValidity := int64(page.uidValidity)
+	resp := generated.EmailMessagesResponse{
+		Messages:    &page.headers,
+		UnreadCount: &unreadCount,
+		UidValidity: &uidValidity,
+	}
+	if cursor != nil {
+		resp.FullResyncRequired = &page.resync
+		resp.HasMore = &page.hasMore
+	}
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(resp)
 }
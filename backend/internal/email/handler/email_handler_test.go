@@ -0,0 +1,761 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"messenger/backend/api/generated"
+	"messenger/backend/pkg/apierror"
+)
+
+func TestMergeMailboxHeadersDeduplicatesByMessageID(t *testing.T) {
+	inbox, allMail := "INBOX", "[Gmail]/All Mail"
+	withMailbox := func(h generated.EmailRichHeader, mailbox *string) generated.EmailRichHeader {
+		h.Mailbox = mailbox
+		return h
+	}
+
+	batches := [][]generated.EmailRichHeader{
+		{
+			withMailbox(richHeader("shared@x", "", "Lunch", 2), &inbox),
+			withMailbox(richHeader("", "", "No id", 1), &inbox),
+		},
+		{
+			withMailbox(richHeader("shared@x", "", "Lunch", 2), &allMail),
+			withMailbox(richHeader("archived@x", "", "Old", 3), &allMail),
+			withMailbox(richHeader("", "", "No id", 1), &allMail),
+		},
+	}
+
+	out := mergeMailboxHeaders(batches)
+	if len(out) != 4 {
+		t.Fatalf("len(out) = %d, want 4", len(out))
+	}
+
+	shared := 0
+	for _, h := range out {
+		if h.MessageId != nil && *h.MessageId == "shared@x" {
+			shared++
+			if *h.Mailbox != inbox {
+				t.Fatalf("kept shared message from %q, want %q", *h.Mailbox, inbox)
+			}
+		}
+	}
+	if shared != 1 {
+		t.Fatalf("shared message appears %d times, want 1", shared)
+	}
+	if *out[0].MessageId != "archived@x" {
+		t.Fatalf("out[0] = %v, want newest message first", *out[0].MessageId)
+	}
+}
+
+func TestMergeMessageHeadersKeepsNewestUnique(t *testing.T) {
+	header := func(messageID string, day int) generated.EmailMessageHeader {
+		date := time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC)
+		h := generated.EmailMessageHeader{Date: &date}
+		if messageID != "" {
+			h.MessageId = &messageID
+		}
+		return h
+	}
+	batches := [][]generated.EmailMessageHeader{
+		{header("a@x", 1), header("b@x", 3)},
+		{header("b@x", 3), header("", 4), header("c@x", 2)},
+	}
+
+	out := mergeMessageHeaders(batches, 3)
+	days := make([]int, len(out))
+	for i, h := range out {
+		days[i] = h.Date.Day()
+	}
+	if !slices.Equal(days, []int{4, 3, 2}) {
+		t.Fatalf("days = %v, want the three newest unique messages [4 3 2]", days)
+	}
+}
+
+func TestEmailListAcrossMailboxes(t *testing.T) {
+	tests := []struct {
+		name       string
+		extra      string
+		wantStatus int
+	}{
+		{name: "unselectable mailboxes are skipped", extra: `"mailboxes":["INBOX","Missing"]`, wantStatus: http.StatusOK},
+		{name: "blank names only", extra: `"mailboxes":[" "]`, wantStatus: http.StatusBadRequest},
+		{name: "with a single mailbox", extra: `"mailboxes":["INBOX"],"mailbox":"Sent"`, wantStatus: http.StatusBadRequest},
+		{name: "with a cursor", extra: `"mailboxes":["INBOX"],"sinceUid":4`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("net.Listen() error = %v", err)
+			}
+			defer ln.Close()
+			port := ln.Addr().(*net.TCPAddr).Port
+			go servePlainIMAP(ln, true, false)
+
+			body := fmt.Sprintf(`{"host":"127.0.0.1","port":%d,"email":"me@example.com","appPassword":"secret","security":"none",%s}`, port, tt.extra)
+			req := httptest.NewRequest(http.MethodPost, "/email/list", strings.NewReader(body))
+			rec := httptest.NewRecorder()
+			NewEmailHandler(Options{
+				Timeout:              5 * time.Second,
+				AllowedHosts:         []string{"127.0.0.1"},
+				AllowPrivateNetworks: true,
+				AllowPlaintext:       true,
+			}).EmailList(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d (%s), want %d", rec.Code, rec.Body.String(), tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var got generated.EmailMessagesResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			if got.Messages == nil || len(*got.Messages) != 0 || got.UnreadCount != nil {
+				t.Fatalf("response = %s, want an empty message list without unreadCount", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestUIDsAfter(t *testing.T) {
+	tests := []struct {
+		name     string
+		uids     []uint32
+		since    uint32
+		want     []uint32
+		wantMore bool
+	}{
+		{name: "newer only, ascending", uids: []uint32{12, 10, 11}, since: 10, want: []uint32{11, 12}},
+		{name: "star matched the newest old message", uids: []uint32{9}, since: 10, want: []uint32{}},
+		{name: "first page of many", uids: []uint32{5, 4, 3, 2}, since: 1, want: []uint32{2, 3}, wantMore: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, more := uidsAfter(tt.uids, tt.since, 2)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) || more != tt.wantMore {
+				t.Fatalf("uidsAfter() = %v, %t; want %v, %t", got, more, tt.want, tt.wantMore)
+			}
+		})
+	}
+}
+
+func TestEmailLoginTestTimesOutOnStalledServer(t *testing.T) {
+	// Accept connections but never answer, not even the TLS handshake.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	defer ln.Close()
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		if conn, err := ln.Accept(); err == nil {
+			accepted <- conn
+		}
+	}()
+	defer func() {
+		select {
+		case conn := <-accepted:
+			conn.Close()
+		default:
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	body := fmt.Sprintf(`{"host":"127.0.0.1","port":%d,"email":"me@example.com","appPassword":"secret"}`, addr.Port)
+	req := httptest.NewRequest(http.MethodPost, "/email/login-test", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	start := time.Now()
+	handler := NewEmailHandler(Options{
+		Timeout:              50 * time.Millisecond,
+		AllowedHosts:         []string{"127.0.0.1"},
+		AllowPrivateNetworks: true,
+	})
+	handler.EmailLoginTest(rec, req)
+
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("status = %d, want %d (body %q)", rec.Code, http.StatusGatewayTimeout, rec.Body.String())
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("handler took %s, want it cut off near the timeout", elapsed)
+	}
+}
+
+func TestEmailLoginTestRejectsInvalidLoginBeforeDialing(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantFields []string
+	}{
+		{
+			name:       "missing host and zero port",
+			body:       `{"host":" ","port":0,"email":"me@example.com","appPassword":"secret"}`,
+			wantFields: []string{"/host", "/port"},
+		},
+		{
+			name:       "port out of range",
+			body:       `{"host":"imap.gmail.com","port":70000,"email":"me@example.com","appPassword":"secret"}`,
+			wantFields: []string{"/port"},
+		},
+		{
+			name:       "empty app password",
+			body:       `{"host":"imap.gmail.com","port":993,"email":"me@example.com","appPassword":""}`,
+			wantFields: []string{"/appPassword"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/email/login-test", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			NewEmailHandler(Options{}).EmailLoginTest(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d (body %q)", rec.Code, http.StatusBadRequest, rec.Body.String())
+			}
+			var body generated.Error
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			if body.Code != apierror.CodeValidation || body.Details == nil {
+				t.Fatalf("body = %+v, want VALIDATION_ERROR with details", body)
+			}
+			var fields []string
+			for _, detail := range *body.Details {
+				fields = append(fields, detail.Field)
+			}
+			if !slices.Equal(fields, tt.wantFields) {
+				t.Fatalf("detail fields = %v, want %v", fields, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestSyncTokenRoundTrip(t *testing.T) {
+	state := mailboxSyncState{UIDValidity: 7, UIDNext: 120, Messages: 98}
+	token := syncToken{"INBOX": state}
+
+	decoded, err := decodeSyncToken(token.encode())
+	if err != nil {
+		t.Fatalf("decodeSyncToken() error = %v", err)
+	}
+	if !decoded.unchanged("INBOX", state) {
+		t.Fatal("INBOX should be unchanged")
+	}
+	for _, changed := range []mailboxSyncState{
+		{UIDValidity: 8, UIDNext: 120, Messages: 98}, // renumbered
+		{UIDValidity: 7, UIDNext: 121, Messages: 99}, // new mail
+		{UIDValidity: 7, UIDNext: 120, Messages: 97}, // expunged
+	} {
+		if decoded.unchanged("INBOX", changed) {
+			t.Fatalf("state %+v should count as changed", changed)
+		}
+	}
+	if decoded.unchanged("Sent", state) {
+		t.Fatal("a mailbox missing from the token should count as changed")
+	}
+	if (syncToken{"INBOX": {UIDValidity: 7}}).unchanged("INBOX", mailboxSyncState{UIDValidity: 7}) {
+		t.Fatal("without UIDNEXT a mailbox should always count as changed")
+	}
+
+	for _, bad := range []string{"%%%", "bm90IGpzb24"} {
+		if _, err := decodeSyncToken(bad); err == nil {
+			t.Fatalf("decodeSyncToken(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestRequestedMailboxes(t *testing.T) {
+	got := requestedMailboxes([]string{" INBOX ", "", "Sent", "INBOX"})
+	if strings.Join(got, ",") != "INBOX,Sent" {
+		t.Fatalf("requestedMailboxes() = %q, want [INBOX Sent]", got)
+	}
+}
+
+// selfSignedCert returns a certificate for 127.0.0.1 and a pool trusting it.
+func selfSignedCert(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "imap test"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate() error = %v", err)
+	}
+	leaf, _ := x509.ParseCertificate(der)
+	pool := x509.NewCertPool()
+	pool.AddCert(leaf)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, pool
+}
+
+// serveSTARTTLS plays a port-143 IMAP server that refuses LOGIN
+// until the client has upgraded with STARTTLS, and reports on logins whether
+// they arrived encrypted.
+func serveSTARTTLS(t *testing.T, ln net.Listener, cert tls.Certificate, offerSTARTTLS bool, logins chan<- bool) {
+	conn, err := ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	caps := "IMAP4rev1 LOGINDISABLED"
+	if offerSTARTTLS {
+		caps += " STARTTLS"
+	}
+	encrypted := false
+	rw := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+	reply := func(format string, args ...interface{}) {
+		fmt.Fprintf(rw, format+"\r\n", args...)
+		rw.Flush()
+	}
+	reply("* OK IMAP test server ready")
+	for {
+		line, err := rw.ReadString('\n')
+		if err != nil {
+			return
+		}
+		tag, command, _ := strings.Cut(strings.TrimSpace(line), " ")
+		verb, _, _ := strings.Cut(command, " ")
+		switch strings.ToUpper(verb) {
+		case "CAPABILITY":
+			reply("* CAPABILITY %s", caps)
+			reply("%s OK CAPABILITY completed", tag)
+		case "STARTTLS":
+			reply("%s OK Begin TLS negotiation now", tag)
+			tlsConn := tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{cert}})
+			if err := tlsConn.Handshake(); err != nil {
+				t.Errorf("server TLS handshake error = %v", err)
+				return
+			}
+			encrypted = true
+			caps = "IMAP4rev1 AUTH=PLAIN"
+			rw = bufio.NewReadWriter(bufio.NewReader(tlsConn), bufio.NewWriter(tlsConn))
+		case "LOGIN":
+			logins <- encrypted
+			if !encrypted {
+				reply("%s NO LOGIN disabled", tag)
+				continue
+			}
+			reply("%s OK LOGIN completed", tag)
+		case "LOGOUT":
+			reply("* BYE")
+			reply("%s OK LOGOUT completed", tag)
+			return
+		default:
+			reply("%s BAD unknown command", tag)
+		}
+	}
+}
+
+func TestDialAndLoginSTARTTLS(t *testing.T) {
+	cert, pool := selfSignedCert(t)
+	starttls := generated.Starttls
+
+	for _, tt := range []struct {
+		name    string
+		offer   bool
+		wantErr error
+	}{
+		{name: "upgrades before login", offer: true},
+		{name: "server without STARTTLS", offer: false, wantErr: errNoStartTLS},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("net.Listen() error = %v", err)
+			}
+			defer ln.Close()
+			logins := make(chan bool, 1)
+			go serveSTARTTLS(t, ln, cert, tt.offer, logins)
+
+			h := NewEmailHandler(Options{AllowedHosts: []string{"127.0.0.1"}, AllowPrivateNetworks: true})
+			h.rootCAs = pool
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			_, release, err := h.dialAndLogin(ctx, generated.EmailLoginRequest{
+				Host:        "127.0.0.1",
+				Port:        int32(ln.Addr().(*net.TCPAddr).Port),
+				Email:       "me@example.com",
+				AppPassword: "secret",
+				Security:    &starttls,
+			})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("dialAndLogin() error = %v, want %v", err, tt.wantErr)
+				}
+				select {
+				case <-logins:
+					t.Fatal("credentials were sent although the connection was never encrypted")
+				default:
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("dialAndLogin() error = %v", err)
+			}
+			release()
+			if encrypted := <-logins; !encrypted {
+				t.Fatal("LOGIN was sent before the connection was upgraded")
+			}
+		})
+	}
+}
+
+func TestDialAndLoginRefusesPlaintextUnlessAllowed(t *testing.T) {
+	none := generated.None
+	h := NewEmailHandler(Options{AllowedHosts: []string{"127.0.0.1"}, AllowPrivateNetworks: true})
+	_, _, err := h.dialAndLogin(context.Background(), generated.EmailLoginRequest{
+		Host: "127.0.0.1", Port: 143, Email: "me@example.com", AppPassword: "secret", Security: &none,
+	})
+	if !errors.Is(err, errPlaintextNotAllowed) {
+		t.Fatalf("dialAndLogin() error = %v, want errPlaintextNotAllowed", err)
+	}
+}
+
+// servePlainIMAP answers one unencrypted IMAP session, accepting LOGIN and
+// SELECT only when told to.
+func servePlainIMAP(ln net.Listener, loginOK, selectOK bool) {
+	conn, err := ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(format string, args ...interface{}) { fmt.Fprintf(conn, format+"\r\n", args...) }
+	reply("* OK [CAPABILITY IMAP4rev1] ready")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		tag, command, _ := strings.Cut(strings.TrimSpace(line), " ")
+		verb, _, _ := strings.Cut(command, " ")
+		verb = strings.ToUpper(verb)
+		switch {
+		case verb == "LOGIN" && !loginOK, (verb == "SELECT" || verb == "EXAMINE") && !selectOK:
+			reply("%s NO refused", tag)
+		case verb == "LOGOUT":
+			reply("* BYE")
+			reply("%s OK LOGOUT completed", tag)
+			return
+		default:
+			reply("%s OK done", tag)
+		}
+	}
+}
+
+func TestEmailLoginTestReportsFailedStep(t *testing.T) {
+	tests := []struct {
+		name       string
+		security   string
+		serve      func(net.Listener)
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:       "nothing listening",
+			security:   "tls",
+			serve:      func(ln net.Listener) { ln.Close() },
+			wantStatus: http.StatusBadGateway,
+			wantCode:   codeIMAPConnectFailed,
+		},
+		{
+			name:       "server does not speak TLS",
+			security:   "tls",
+			serve:      func(ln net.Listener) { servePlainIMAP(ln, true, true) },
+			wantStatus: http.StatusBadGateway,
+			wantCode:   codeIMAPTLSFailed,
+		},
+		{
+			name:       "credentials rejected",
+			security:   "none",
+			serve:      func(ln net.Listener) { servePlainIMAP(ln, false, true) },
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   codeIMAPAuthFailed,
+		},
+		{
+			name:       "inbox cannot be opened",
+			security:   "none",
+			serve:      func(ln net.Listener) { servePlainIMAP(ln, true, false) },
+			wantStatus: http.StatusBadGateway,
+			wantCode:   codeIMAPMailboxFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("net.Listen() error = %v", err)
+			}
+			defer ln.Close()
+			port := ln.Addr().(*net.TCPAddr).Port
+			go tt.serve(ln)
+
+			body := fmt.Sprintf(`{"host":"127.0.0.1","port":%d,"email":"me@example.com","appPassword":"secret","security":%q}`, port, tt.security)
+			req := httptest.NewRequest(http.MethodPost, "/email/login-test", strings.NewReader(body))
+			rec := httptest.NewRecorder()
+			NewEmailHandler(Options{
+				Timeout:              5 * time.Second,
+				AllowedHosts:         []string{"127.0.0.1"},
+				AllowPrivateNetworks: true,
+				AllowPlaintext:       true,
+			}).EmailLoginTest(rec, req)
+
+			var got generated.Error
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			if rec.Code != tt.wantStatus || got.Code != tt.wantCode {
+				t.Fatalf("response = %d %+v, want %d %s", rec.Code, got, tt.wantStatus, tt.wantCode)
+			}
+		})
+	}
+}
+
+// serveSizedIMAP answers one unencrypted session whose mailboxes hold the
+// number of messages in sizes; opening any other mailbox is refused. SEARCH
+// and FETCH fail the session, so only empty mailboxes can be read.
+func serveSizedIMAP(ln net.Listener, sizes map[string]int) {
+	conn, err := ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(format string, args ...interface{}) { fmt.Fprintf(conn, format+"\r\n", args...) }
+	reply("* OK [CAPABILITY IMAP4rev1] ready")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		tag, command, _ := strings.Cut(strings.TrimSpace(line), " ")
+		verb, args, _ := strings.Cut(command, " ")
+		switch strings.ToUpper(verb) {
+		case "SELECT", "EXAMINE":
+			size, ok := sizes[strings.Trim(args, `"`)]
+			if !ok {
+				reply("%s NO [NONEXISTENT] no such mailbox", tag)
+				continue
+			}
+			reply("* %d EXISTS", size)
+			reply("* OK [UIDVALIDITY 7] UIDs valid")
+			reply("%s OK [READ-ONLY] done", tag)
+		case "SEARCH", "FETCH", "UID":
+			reply("%s BAD not expected for an empty mailbox", tag)
+			return
+		case "LOGOUT":
+			reply("* BYE")
+			reply("%s OK LOGOUT completed", tag)
+			return
+		default:
+			reply("%s OK done", tag)
+		}
+	}
+}
+
+func TestEmailListEmptyMailbox(t *testing.T) {
+	tests := []struct {
+		name  string
+		extra string
+	}{
+		{name: "latest"},
+		{name: "flagged", extra: `,"searchFlags":["\\Flagged"]`},
+		{name: "since uid", extra: `,"sinceUid":41,"uidValidity":7`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("net.Listen() error = %v", err)
+			}
+			defer ln.Close()
+			go serveSizedIMAP(ln, map[string]int{"INBOX": 0})
+
+			port := ln.Addr().(*net.TCPAddr).Port
+			body := fmt.Sprintf(`{"host":"127.0.0.1","port":%d,"email":"me@example.com","appPassword":"secret","security":"none"%s}`, port, tt.extra)
+			req := httptest.NewRequest(http.MethodPost, "/email/list", strings.NewReader(body))
+			rec := httptest.NewRecorder()
+			NewEmailHandler(Options{
+				Timeout:              5 * time.Second,
+				AllowedHosts:         []string{"127.0.0.1"},
+				AllowPrivateNetworks: true,
+				AllowPlaintext:       true,
+			}).EmailList(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200; body %s", rec.Code, rec.Body)
+			}
+			var got generated.EmailMessagesResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			if got.Messages == nil || len(*got.Messages) != 0 || got.UidValidity == nil || *got.UidValidity != 7 {
+				t.Fatalf("response = %s, want no messages of uidValidity 7", rec.Body)
+			}
+		})
+	}
+}
+
+func TestEmailHeadersMissingVersusEmptyMailbox(t *testing.T) {
+	tests := []struct {
+		name      string
+		mailboxes string
+		wantCode  int
+	}{
+		{name: "named empty", mailboxes: `,"mailboxes":["INBOX"]`, wantCode: http.StatusOK},
+		{name: "named missing", mailboxes: `,"mailboxes":["INBOX","Archiv"]`, wantCode: http.StatusNotFound},
+		{name: "missing defaults skipped", wantCode: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("net.Listen() error = %v", err)
+			}
+			defer ln.Close()
+			go serveSizedIMAP(ln, map[string]int{"INBOX": 0})
+
+			port := ln.Addr().(*net.TCPAddr).Port
+			body := fmt.Sprintf(`{"host":"127.0.0.1","port":%d,"email":"me@example.com","appPassword":"secret","security":"none"%s}`, port, tt.mailboxes)
+			req := httptest.NewRequest(http.MethodPost, "/email/headers", strings.NewReader(body))
+			rec := httptest.NewRecorder()
+			NewEmailHandler(Options{
+				Timeout:              5 * time.Second,
+				AllowedHosts:         []string{"127.0.0.1"},
+				AllowPrivateNetworks: true,
+				AllowPlaintext:       true,
+			}).EmailHeaders(rec, req, generated.EmailHeadersParams{})
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d; body %s", rec.Code, tt.wantCode, rec.Body)
+			}
+			if tt.wantCode != http.StatusOK {
+				if !strings.Contains(rec.Body.String(), "Archiv") {
+					t.Fatalf("body = %s, want it to name the missing mailbox", rec.Body)
+				}
+				return
+			}
+			var got generated.EmailRichHeadersResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			if len(got.Messages) != 0 {
+				t.Fatalf("messages = %+v, want none", got.Messages)
+			}
+		})
+	}
+}
+
+// serveBrokenFetchIMAP answers one unencrypted session with an empty Archive
+// and an INBOX of two messages whose FETCH breaks off after the first.
+func serveBrokenFetchIMAP(ln net.Listener) {
+	conn, err := ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(format string, args ...interface{}) { fmt.Fprintf(conn, format+"\r\n", args...) }
+	reply("* OK [CAPABILITY IMAP4rev1] ready")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		tag, command, _ := strings.Cut(strings.TrimSpace(line), " ")
+		verb, args, _ := strings.Cut(command, " ")
+		switch strings.ToUpper(verb) {
+		case "SELECT", "EXAMINE":
+			messages := 0
+			if strings.Trim(args, `"`) == "INBOX" {
+				messages = 2
+			}
+			reply("* %d EXISTS", messages)
+			reply("* OK [UIDVALIDITY 7] UIDs valid")
+			reply("* OK [UIDNEXT 13] next UID")
+			reply("%s OK [READ-ONLY] done", tag)
+		case "FETCH":
+			reply("* 1 FETCH (UID 11)")
+			reply("%s NO [SERVERBUG] fetch broke off", tag)
+		case "LOGOUT":
+			reply("* BYE")
+			reply("%s OK LOGOUT completed", tag)
+			return
+		default:
+			reply("%s OK done", tag)
+		}
+	}
+}
+
+func TestEmailHeadersLeavesFailedMailboxOutOfSyncToken(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	defer ln.Close()
+	go serveBrokenFetchIMAP(ln)
+
+	port := ln.Addr().(*net.TCPAddr).Port
+	body := fmt.Sprintf(`{"host":"127.0.0.1","port":%d,"email":"me@example.com","appPassword":"secret","security":"none","mailboxes":["INBOX","Archive"]}`, port)
+	req := httptest.NewRequest(http.MethodPost, "/email/headers", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	NewEmailHandler(Options{
+		Timeout:              5 * time.Second,
+		AllowedHosts:         []string{"127.0.0.1"},
+		AllowPrivateNetworks: true,
+		AllowPlaintext:       true,
+	}).EmailHeaders(rec, req, generated.EmailHeadersParams{})
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body %s", rec.Code, rec.Body)
+	}
+	var got generated.EmailRichHeadersResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if got.SyncToken == nil {
+		t.Fatal("response has no syncToken")
+	}
+	token, err := decodeSyncToken(*got.SyncToken)
+	if err != nil {
+		t.Fatalf("decodeSyncToken() error = %v", err)
+	}
+	if _, ok := token["INBOX"]; ok {
+		t.Fatalf("syncToken = %+v, must not mark the half-read INBOX as synced", token)
+	}
+	if _, ok := token["Archive"]; !ok {
+		t.Fatalf("syncToken = %+v, want the empty Archive recorded", token)
+	}
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/emersion/go-imap"
+	imapclient "github.com/emersion/go-imap/client"
+
+	"messenger/backend/api/generated"
+	"messenger/backend/pkg/apierror"
+	"messenger/backend/pkg/httpjson"
+)
+
+// EmailMailboxes handles POST /email/mailboxes requests, listing every
+// mailbox of the account so the client can offer a folder tree instead of
+// asking for names like "[Gmail]/All Mail".
+func (h *EmailHandler) EmailMailboxes(w http.ResponseWriter, r *http.Request) {
+	req, err := httpjson.Decode[generated.EmailLoginRequest](r)
+	if err != nil {
+		apierror.Write(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	req, _, err = h.resolveLogin(r.Context(), req)
+	if err != nil {
+		writeIMAPError(w, r.Context(), err)
+		return
+	}
+	ctx, cancel := h.requestContext(r)
+	defer cancel()
+
+	c, release, err := h.dialAndLogin(ctx, req)
+	if err != nil {
+		writeIMAPError(w, ctx, err)
+		return
+	}
+	defer release()
+
+	listed, err := listMailboxes(c)
+	if err != nil {
+		writeIMAPError(w, ctx, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(generated.EmailMailboxesResponse{Mailboxes: toMailboxes(listed)})
+}
+
+// listMailboxes returns every mailbox of the account (LIST "" "*").
+func listMailboxes(c *imapclient.Client) ([]*imap.MailboxInfo, error) {
+	infos := make(chan *imap.MailboxInfo, 16)
+	done := make(chan error, 1)
+	go func() {
+		done <- c.List("", "*", infos)
+	}()
+	var listed []*imap.MailboxInfo
+	for info := range infos {
+		listed = append(listed, info)
+	}
+	return listed, <-done
+}
+
+// toMailboxes converts LIST responses to the API shape, sorted by name so
+// parents come before their children. Attributes are passed through as the
+// server sent them, special-use ones (\Sent, \Drafts, \Trash, ...) included.
+func toMailboxes(infos []*imap.MailboxInfo) []generated.EmailMailbox {
+	out := make([]generated.EmailMailbox, 0, len(infos))
+	for _, info := range infos {
+		attributes := info.Attributes
+		if attributes == nil {
+			attributes = []string{}
+		}
+		out = append(out, generated.EmailMailbox{
+			Name:       info.Name,
+			Delimiter:  info.Delimiter,
+			Attributes: attributes,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/emersion/go-imap"
+)
+
+func TestToMailboxes(t *testing.T) {
+	got := toMailboxes([]*imap.MailboxInfo{
+		{Name: "[Gmail]/Sent Mail", Delimiter: "/", Attributes: []string{imap.SentAttr, "\\HasNoChildren"}},
+		{Name: "INBOX", Delimiter: "/"},
+		{Name: "[Gmail]", Delimiter: "/", Attributes: []string{imap.NoSelectAttr, "\\HasChildren"}},
+	})
+
+	names := make([]string, len(got))
+	for i, mbox := range got {
+		names[i] = mbox.Name
+	}
+	if want := []string{"INBOX", "[Gmail]", "[Gmail]/Sent Mail"}; !slices.Equal(names, want) {
+		t.Fatalf("names = %v, want %v", names, want)
+	}
+	if got[0].Attributes == nil || len(got[0].Attributes) != 0 {
+		t.Fatalf("INBOX attributes = %#v, want an empty list", got[0].Attributes)
+	}
+	if got[2].Delimiter != "/" || !slices.Contains(got[2].Attributes, "\\Sent") {
+		t.Fatalf("sent mailbox = %+v, want delimiter and \\Sent attribute", got[2])
+	}
+}
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/emersion/go-imap"
+	imapclient "github.com/emersion/go-imap/client"
+	"github.com/emersion/go-imap/commands"
+
+	"messenger/backend/api/generated"
+	"messenger/backend/pkg/apierror"
+	"messenger/backend/pkg/httpjson"
+)
+
+// EmailMove handles POST /email/move requests. UIDs that no longer exist in
+// the source mailbox are skipped; the response lists the ones actually moved.
+// Servers without MOVE or UIDPLUS get 501 and keep their messages in place.
+func (h *EmailHandler) EmailMove(w http.ResponseWriter, r *http.Request) {
+	req, err := httpjson.Decode[generated.EmailMoveRequest](r)
+	if err != nil {
+		apierror.Write(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	source := strings.TrimSpace(req.Mailbox)
+	destination := strings.TrimSpace(req.Destination)
+	if source == "" || destination == "" {
+		apierror.Write(w, http.StatusBadRequest, "mailbox and destination are required")
+		return
+	}
+	if source == destination {
+		apierror.Write(w, http.StatusBadRequest, "destination must differ from mailbox")
+		return
+	}
+	if len(req.Uids) == 0 {
+		apierror.Write(w, http.StatusBadRequest, "uids must not be empty")
+		return
+	}
+
+	requested := new(imap.SeqSet)
+	for _, uid := range req.Uids {
+		if uid <= 0 || uid > int64(^uint32(0)) {
+			apierror.Write(w, http.StatusBadRequest, "uids must be positive 32-bit integers")
+			return
+		}
+		requested.AddNum(uint32(uid))
+	}
+
+	login, _, err := h.resolveLogin(r.Context(), generated.EmailLoginRequest{
+		AccountId:   req.AccountId,
+		Host:        req.Host,
+		Port:        req.Port,
+		Email:       req.Email,
+		AppPassword: req.AppPassword,
+		Security:    req.Security,
+	})
+	if err != nil {
+		writeIMAPError(w, r.Context(), err)
+		return
+	}
+	ctx, cancel := h.requestContext(r)
+	defer cancel()
+
+	c, release, err := h.dialAndLogin(ctx, login)
+	if err != nil {
+		writeIMAPError(w, ctx, err)
+		return
+	}
+	defer release()
+
+	if _, err := c.Select(source, false); err != nil {
+		if ctx.Err() != nil {
+			writeIMAPError(w, ctx, err)
+			return
+		}
+		apierror.Write(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	criteria := imap.NewSearchCriteria()
+	criteria.Uid = requested
+	found, err := c.UidSearch(criteria)
+	if err != nil {
+		writeIMAPError(w, ctx, err)
+		return
+	}
+
+	moved := make([]int64, 0, len(found))
+	if len(found) > 0 {
+		seqset := new(imap.SeqSet)
+		seqset.AddNum(found...)
+		if err := moveMessages(c, seqset, destination); err != nil {
+			writeIMAPError(w, ctx, err)
+			return
+		}
+		for _, uid := range found {
+			moved = append(moved, int64(uid))
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(generated.EmailMoveResponse{Moved: moved})
+}
+
+// errMoveUnsupported is returned for servers that offer neither MOVE nor
+// UIDPLUS: a plain EXPUNGE would also delete every other message the user
+// already flagged \Deleted in the source mailbox.
+var errMoveUnsupported = errors.New("mail server supports neither MOVE nor UIDPLUS; moving would expunge other deleted messages")
+
+// moveMessages moves the UIDs in seqset out of the selected mailbox. Servers
+// without the MOVE extension get COPY, a \Deleted flag and a UID EXPUNGE
+// (RFC 4315) limited to seqset; without UIDPLUS nothing is touched and
+// errMoveUnsupported is returned.
+func moveMessages(c *imapclient.Client, seqset *imap.SeqSet, destination string) error {
+	supported, err := c.Support("MOVE")
+	if err != nil {
+		return err
+	}
+	if supported {
+		return c.UidMove(seqset, destination)
+	}
+	supported, err = c.Support("UIDPLUS")
+	if err != nil {
+		return err
+	}
+	if !supported {
+		return errMoveUnsupported
+	}
+
+	if err := c.UidCopy(seqset, destination); err != nil {
+		return err
+	}
+	item := imap.FormatFlagsOp(imap.AddFlags, true)
+	if err := c.UidStore(seqset, item, []interface{}{imap.DeletedFlag}, nil); err != nil {
+		return err
+	}
+	status, err := c.Execute(&commands.Uid{Cmd: &uidExpunge{seqset: seqset}}, nil)
+	if err != nil {
+		return err
+	}
+	return status.Err()
+}
+
+// uidExpunge is the argument part of UID EXPUNGE, which go-imap v1 lacks;
+// wrap it in commands.Uid to send it.
+type uidExpunge struct {
+	seqset *imap.SeqSet
+}
+
+func (cmd *uidExpunge) Command() *imap.Command {
+	return &imap.Command{Name: "EXPUNGE", Arguments: []interface{}{cmd.seqset}}
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"messenger/backend/api/generated"
+)
+
+// serveMoveIMAP answers as a server with the given capabilities whose source
+// mailbox holds UIDs 3 and 5, sending every UID command it receives to
+// commands.
+func serveMoveIMAP(ln net.Listener, capabilities string, commands chan<- string) {
+	defer close(commands)
+	conn, err := ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(format string, args ...interface{}) { fmt.Fprintf(conn, format+"\r\n", args...) }
+	reply("* OK ready")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		tag, command, _ := strings.Cut(strings.TrimSpace(line), " ")
+		verb, _, _ := strings.Cut(command, " ")
+		switch strings.ToUpper(verb) {
+		case "CAPABILITY":
+			reply("* CAPABILITY %s", capabilities)
+			reply("%s OK CAPABILITY completed", tag)
+		case "SELECT":
+			reply("* 2 EXISTS")
+			reply("%s OK [READ-WRITE] SELECT completed", tag)
+		case "UID":
+			if strings.HasPrefix(strings.ToUpper(command), "UID SEARCH") {
+				reply("* SEARCH 3 5")
+			} else {
+				commands <- command
+			}
+			reply("%s OK UID completed", tag)
+		case "EXPUNGE":
+			commands <- command
+			reply("%s OK EXPUNGE completed", tag)
+		case "LOGOUT":
+			reply("* BYE")
+			reply("%s OK LOGOUT completed", tag)
+			return
+		default:
+			reply("%s OK done", tag)
+		}
+	}
+}
+
+func moveRequest(t *testing.T, capabilities string) (*httptest.ResponseRecorder, []string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	defer ln.Close()
+	commands := make(chan string, 10)
+	go serveMoveIMAP(ln, capabilities, commands)
+
+	port := ln.Addr().(*net.TCPAddr).Port
+	body := fmt.Sprintf(`{"host":"127.0.0.1","port":%d,"email":"me@example.com","appPassword":"secret","security":"none","mailbox":"INBOX","destination":"Archive","uids":[3,5,9]}`, port)
+	req := httptest.NewRequest(http.MethodPost, "/email/move", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	NewEmailHandler(Options{
+		Timeout:              5 * time.Second,
+		AllowedHosts:         []string{"127.0.0.1"},
+		AllowPrivateNetworks: true,
+		AllowPlaintext:       true,
+	}).EmailMove(rec, req)
+
+	var sent []string
+	for command := range commands {
+		sent = append(sent, command)
+	}
+	return rec, sent
+}
+
+func TestEmailMoveExpungesOnlyMovedUIDs(t *testing.T) {
+	tests := []struct {
+		name         string
+		capabilities string
+		want         []string
+	}{
+		{name: "MOVE", capabilities: "IMAP4rev1 MOVE UIDPLUS", want: []string{`UID MOVE 3,5 "Archive"`}},
+		{name: "UIDPLUS", capabilities: "IMAP4rev1 UIDPLUS", want: []string{
+			`UID COPY 3,5 "Archive"`,
+			`UID STORE 3,5 +FLAGS.SILENT (\Deleted)`,
+			"UID EXPUNGE 3,5",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, sent := moveRequest(t, tt.capabilities)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200; body %s", rec.Code, rec.Body)
+			}
+			var got generated.EmailMoveResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			if fmt.Sprint(got.Moved) != "[3 5]" {
+				t.Fatalf("moved = %v, want [3 5]", got.Moved)
+			}
+			if fmt.Sprint(sent) != fmt.Sprint(tt.want) {
+				t.Fatalf("commands = %q, want %q", sent, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmailMoveRefusesWithoutUIDPlus(t *testing.T) {
+	rec, sent := moveRequest(t, "IMAP4rev1")
+	if rec.Code != http.StatusNotImplemented {
+		t.Fatalf("status = %d, want 501; body %s", rec.Code, rec.Body)
+	}
+	if len(sent) != 0 {
+		t.Fatalf("commands = %q, want none", sent)
+	}
+}
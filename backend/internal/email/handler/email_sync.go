@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/emersion/go-imap"
+)
+
+var errInvalidSyncToken = errors.New("invalid syncToken")
+
+// mailboxSyncState captures what EmailHeaders needs to tell whether a mailbox
+// changed. The IMAP client has no CONDSTORE support, so HIGHESTMODSEQ is not
+// available; envelopes never change in place, though, and any arrival or
+// expunge moves UIDNEXT or the message count.
+type mailboxSyncState struct {
+	UIDValidity uint32 `json:"v"`
+	UIDNext     uint32 `json:"n"`
+	Messages    uint32 `json:"m"`
+}
+
+func syncStateOf(mbox *imap.MailboxStatus) mailboxSyncState {
+	return mailboxSyncState{UIDValidity: mbox.UidValidity, UIDNext: mbox.UidNext, Messages: mbox.Messages}
+}
+
+// syncToken maps mailbox names to their state when headers were last read.
+type syncToken map[string]mailboxSyncState
+
+func (t syncToken) encode() string {
+	raw, _ := json.Marshal(t)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeSyncToken(s string) (syncToken, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errInvalidSyncToken
+	}
+	var t syncToken
+	if err := json.Unmarshal(raw, &t); err != nil {
+		return nil, errInvalidSyncToken
+	}
+	return t, nil
+}
+
+// unchanged reports whether mailbox still has the state recorded in t. A
+// server that does not report UIDNEXT makes every mailbox look changed.
+func (t syncToken) unchanged(mailbox string, state mailboxSyncState) bool {
+	prev, ok := t[mailbox]
+	return ok && state.UIDNext != 0 && prev == state
+}
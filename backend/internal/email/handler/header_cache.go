@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+
+	"messenger/backend/api/generated"
+	"messenger/backend/pkg/metrics"
+)
+
+// HeaderCache stores the envelopes EmailHeaders fetched, so refreshes only
+// download messages that arrived since. account identifies the IMAP login
+// (see headerCacheAccount); uidValidity is the mailbox generation the UIDs
+// belong to, and entries stored under another generation must not be
+// returned.
+type HeaderCache interface {
+	GetHeaders(ctx context.Context, account, mailbox string, uidValidity uint32, uids []uint32) (map[uint32]generated.EmailRichHeader, error)
+	PutHeaders(ctx context.Context, account, mailbox string, uidValidity uint32, headers []generated.EmailRichHeader) error
+}
+
+// DefaultHeaderCacheEntries bounds the in-memory header cache.
+const DefaultHeaderCacheEntries = 50000
+
+// headerCacheAccount keys cached headers by server and address, so one
+// login's headers are never served to another.
+func headerCacheAccount(req generated.EmailLoginRequest) string {
+	return strings.ToLower(req.Host) + ":" + strings.ToLower(string(req.Email))
+}
+
+type headerCacheKey struct {
+	account string
+	mailbox string
+	uid     uint32
+}
+
+type headerCacheEntry struct {
+	key         headerCacheKey
+	uidValidity uint32
+	header      generated.EmailRichHeader
+}
+
+// memoryHeaderCache is a least-recently-used HeaderCache bounded by entry
+// count, optionally in front of a slower, persistent one.
+type memoryHeaderCache struct {
+	mu      sync.Mutex
+	max     int
+	order   *list.List // front is most recently used
+	entries map[headerCacheKey]*list.Element
+	backing HeaderCache
+}
+
+// NewMemoryHeaderCache returns an in-memory HeaderCache holding up to
+// maxEntries headers. Misses fall through to backing, if given, and writes go
+// to both.
+func NewMemoryHeaderCache(maxEntries int, backing HeaderCache) HeaderCache {
+	return &memoryHeaderCache{
+		max:     maxEntries,
+		order:   list.New(),
+		entries: make(map[headerCacheKey]*list.Element),
+		backing: backing,
+	}
+}
+
+func (c *memoryHeaderCache) GetHeaders(ctx context.Context, account, mailbox string, uidValidity uint32, uids []uint32) (map[uint32]generated.EmailRichHeader, error) {
+	found := make(map[uint32]generated.EmailRichHeader, len(uids))
+	var missing []uint32
+
+	c.mu.Lock()
+	for _, uid := range uids {
+		key := headerCacheKey{account: account, mailbox: mailbox, uid: uid}
+		el, ok := c.entries[key]
+		if !ok {
+			missing = append(missing, uid)
+			continue
+		}
+		entry := el.Value.(*headerCacheEntry)
+		if entry.uidValidity != uidValidity {
+			// The mailbox was renumbered; the UID now names another message.
+			c.order.Remove(el)
+			delete(c.entries, key)
+			metrics.ObserveHeaderCacheInvalidation()
+			missing = append(missing, uid)
+			continue
+		}
+		c.order.MoveToFront(el)
+		found[uid] = entry.header
+	}
+	c.mu.Unlock()
+
+	if c.backing != nil && len(missing) > 0 {
+		stored, err := c.backing.GetHeaders(ctx, account, mailbox, uidValidity, missing)
+		if err != nil {
+			return found, err
+		}
+		restored := make([]generated.EmailRichHeader, 0, len(stored))
+		for uid, header := range stored {
+			found[uid] = header
+			restored = append(restored, header)
+		}
+		c.store(account, mailbox, uidValidity, restored)
+	}
+	return found, nil
+}
+
+func (c *memoryHeaderCache) PutHeaders(ctx context.Context, account, mailbox string, uidValidity uint32, headers []generated.EmailRichHeader) error {
+	c.store(account, mailbox, uidValidity, headers)
+	if c.backing != nil {
+		return c.backing.PutHeaders(ctx, account, mailbox, uidValidity, headers)
+	}
+	return nil
+}
+
+func (c *memoryHeaderCache) store(account, mailbox string, uidValidity uint32, headers []generated.EmailRichHeader) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, header := range headers {
+		if header.Uid == nil {
+			continue
+		}
+		key := headerCacheKey{account: account, mailbox: mailbox, uid: uint32(*header.Uid)}
+		if el, ok := c.entries[key]; ok {
+			c.order.Remove(el)
+		}
+		c.entries[key] = c.order.PushFront(&headerCacheEntry{key: key, uidValidity: uidValidity, header: header})
+	}
+	for c.order.Len() > c.max {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*headerCacheEntry).key)
+	}
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"messenger/backend/api/generated"
+)
+
+func cachedHeader(uid int64, subject string) generated.EmailRichHeader {
+	return generated.EmailRichHeader{Uid: &uid, Subject: &subject}
+}
+
+func TestMemoryHeaderCache(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryHeaderCache(3, nil)
+	const account = "imap.example.com:a@example.com"
+
+	if err := cache.PutHeaders(ctx, account, "INBOX", 7, []generated.EmailRichHeader{
+		cachedHeader(1, "one"), cachedHeader(2, "two"), cachedHeader(3, "three"),
+	}); err != nil {
+		t.Fatalf("PutHeaders() error = %v", err)
+	}
+
+	got, _ := cache.GetHeaders(ctx, account, "INBOX", 7, []uint32{1, 2, 4})
+	if len(got) != 2 || *got[1].Subject != "one" || *got[2].Subject != "two" {
+		t.Fatalf("GetHeaders() = %v, want uids 1 and 2", got)
+	}
+	if got, _ := cache.GetHeaders(ctx, "imap.example.com:b@example.com", "INBOX", 7, []uint32{1}); len(got) != 0 {
+		t.Fatal("headers cached for one account must not be served to another")
+	}
+
+	// 3 is now the least recently used and makes room for 4.
+	cache.PutHeaders(ctx, account, "INBOX", 7, []generated.EmailRichHeader{cachedHeader(4, "four")})
+	if got, _ := cache.GetHeaders(ctx, account, "INBOX", 7, []uint32{3, 4}); len(got) != 1 || got[4].Subject == nil {
+		t.Fatalf("GetHeaders() after eviction = %v, want only uid 4", got)
+	}
+
+	if got, _ := cache.GetHeaders(ctx, account, "INBOX", 8, []uint32{1, 2, 4}); len(got) != 0 {
+		t.Fatalf("GetHeaders() after a UIDVALIDITY change = %v, want nothing", got)
+	}
+	if got, _ := cache.GetHeaders(ctx, account, "INBOX", 7, []uint32{1}); len(got) != 0 {
+		t.Fatal("entries of the old UIDVALIDITY should have been dropped")
+	}
+}
+
+func TestMemoryHeaderCacheFallsThroughToBacking(t *testing.T) {
+	ctx := context.Background()
+	backing := NewMemoryHeaderCache(10, nil)
+	backing.PutHeaders(ctx, "acct", "INBOX", 1, []generated.EmailRichHeader{cachedHeader(5, "stored")})
+
+	cache := NewMemoryHeaderCache(10, backing)
+	if got, _ := cache.GetHeaders(ctx, "acct", "INBOX", 1, []uint32{5}); len(got) != 1 {
+		t.Fatalf("GetHeaders() = %v, want the backing store's header", got)
+	}
+
+	cache.PutHeaders(ctx, "acct", "INBOX", 1, []generated.EmailRichHeader{cachedHeader(6, "new")})
+	if got, _ := backing.GetHeaders(ctx, "acct", "INBOX", 1, []uint32{6}); len(got) != 1 {
+		t.Fatal("PutHeaders() should write through to the backing store")
+	}
+}
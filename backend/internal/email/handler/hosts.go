@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+)
+
+// DefaultAllowedIMAPHosts are the mail providers users may connect to when no
+// allow-list is configured.
+var DefaultAllowedIMAPHosts = []string{
+	"imap.gmail.com",
+	"outlook.office365.com",
+	"imap-mail.outlook.com",
+	"imap.mail.yahoo.com",
+	"imap.mail.me.com",
+	"imap.fastmail.com",
+	"imap.zoho.com",
+}
+
+// errHostNotAllowed is returned when the requested IMAP server is outside the
+// allow-list or resolves to an internal address.
+var errHostNotAllowed = errors.New("imap host is not allowed")
+
+// hostGuard decides which IMAP servers the handler may dial. Because the host
+// comes straight from the request body, dialing it unchecked would let callers
+// probe the backend's internal network.
+type hostGuard struct {
+	// exact holds allowed hostnames; suffixes holds domains (stored with a
+	// leading dot) whose subdomains are allowed.
+	exact    map[string]struct{}
+	suffixes []string
+	// allowPrivate disables the private/loopback address check, for local
+	// development against a mail server on the same machine or network.
+	allowPrivate bool
+	resolver     *net.Resolver
+}
+
+// newHostGuard builds a guard from allow-list entries. An entry is either a
+// hostname ("imap.gmail.com") or a domain prefixed with "." or "*." that
+// admits any of its subdomains (".example.com").
+func newHostGuard(allowed []string, allowPrivate bool) *hostGuard {
+	g := &hostGuard{
+		exact:        make(map[string]struct{}, len(allowed)),
+		allowPrivate: allowPrivate,
+		resolver:     net.DefaultResolver,
+	}
+	for _, entry := range allowed {
+		entry = normalizeHost(entry)
+		switch {
+		case entry == "":
+		case strings.HasPrefix(entry, "*."):
+			g.suffixes = append(g.suffixes, entry[1:])
+		case strings.HasPrefix(entry, "."):
+			g.suffixes = append(g.suffixes, entry)
+		default:
+			g.exact[entry] = struct{}{}
+		}
+	}
+	return g
+}
+
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
+
+func (g *hostGuard) allowed(host string) bool {
+	host = normalizeHost(host)
+	if _, ok := g.exact[host]; ok {
+		return true
+	}
+	for _, suffix := range g.suffixes {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve checks host against the allow-list and returns the addresses it may
+// be dialed on. Every resolved address must be public, so a name that also
+// points inside the network is rejected outright; dialing the vetted
+// addresses directly keeps a second lookup from answering differently.
+func (g *hostGuard) resolve(ctx context.Context, host string) ([]netip.Addr, error) {
+	if !g.allowed(host) {
+		return nil, fmt.Errorf("%w: %s", errHostNotAllowed, host)
+	}
+
+	var addrs []netip.Addr
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addrs = []netip.Addr{addr}
+	} else {
+		resolved, err := g.resolver.LookupNetIP(ctx, "ip", host)
+		if err != nil {
+			return nil, err
+		}
+		addrs = resolved
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no addresses found for %s", host)
+	}
+
+	if !g.allowPrivate {
+		for _, addr := range addrs {
+			if isInternalAddr(addr) {
+				return nil, fmt.Errorf("%w: %s resolves to internal address %s", errHostNotAllowed, host, addr)
+			}
+		}
+	}
+	return addrs, nil
+}
+
+// isInternalAddr reports whether addr is not routable on the public internet.
+func isInternalAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsLoopback() ||
+		addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() ||
+		addr.IsUnspecified() ||
+		sharedAddressSpace.Contains(addr)
+}
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598), which
+// netip.Addr.IsPrivate does not cover.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+func TestHostGuardAllowList(t *testing.T) {
+	guard := newHostGuard([]string{"imap.gmail.com", ".example.com", "*.corp.test"}, false)
+
+	for host, want := range map[string]bool{
+		"imap.gmail.com":           true,
+		"IMAP.Gmail.com.":          true,
+		"mail.example.com":         true,
+		"example.com":              false,
+		"badexample.com":           false,
+		"imap.eu.corp.test":        true,
+		"gmail.com":                false,
+		"imap.gmail.com.evil":      false,
+		"metadata.google.internal": false,
+	} {
+		if got := guard.allowed(host); got != want {
+			t.Errorf("allowed(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
+
+func TestHostGuardRejectsInternalAddresses(t *testing.T) {
+	guard := newHostGuard([]string{"127.0.0.1", "10.1.2.3", "::1", "169.254.169.254", "8.8.8.8"}, false)
+
+	for _, host := range []string{"127.0.0.1", "10.1.2.3", "::1", "169.254.169.254"} {
+		if _, err := guard.resolve(context.Background(), host); !errors.Is(err, errHostNotAllowed) {
+			t.Errorf("resolve(%q) error = %v, want errHostNotAllowed", host, err)
+		}
+	}
+	if _, err := guard.resolve(context.Background(), "192.0.2.1"); !errors.Is(err, errHostNotAllowed) {
+		t.Errorf("resolve of unlisted host error = %v, want errHostNotAllowed", err)
+	}
+	addrs, err := guard.resolve(context.Background(), "8.8.8.8")
+	if err != nil || len(addrs) != 1 || addrs[0] != netip.MustParseAddr("8.8.8.8") {
+		t.Fatalf("resolve(8.8.8.8) = %v, %v", addrs, err)
+	}
+
+	permissive := newHostGuard([]string{"127.0.0.1"}, true)
+	if _, err := permissive.resolve(context.Background(), "127.0.0.1"); err != nil {
+		t.Fatalf("resolve with private networks allowed error = %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// bodyPolicy is the allow-list message HTML is reduced to: bluemonday's UGC
+// policy (no scripts, event handlers, styles, forms or frames) plus inline
+// data: images, with links opened in a new tab without a referrer.
+var bodyPolicy = newBodyPolicy()
+
+func newBodyPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowDataURIImages()
+	p.RequireNoReferrerOnLinks(true)
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+	return p
+}
+
+// remoteURLAttrs are the attributes through which sanitized HTML can still
+// make the client fetch something.
+var remoteURLAttrs = map[string]bool{"src": true, "srcset": true, "background": true, "poster": true}
+
+// sanitizeHTML runs raw through bodyPolicy. Unless allowRemote is set it also
+// drops remote image sources, which senders use as read-tracking pixels, and
+// reports whether it did.
+func sanitizeHTML(raw string, allowRemote bool) (string, bool) {
+	clean := bodyPolicy.Sanitize(raw)
+	if allowRemote {
+		return clean, false
+	}
+
+	body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(clean), body)
+	if err != nil {
+		// The input came out of the sanitizer, so this does not happen; fail
+		// closed rather than return markup that may load remote content.
+		return html.EscapeString(htmlToText(clean)), true
+	}
+
+	blocked := false
+	var strip func(n *html.Node)
+	strip = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			kept := n.Attr[:0]
+			for _, attr := range n.Attr {
+				if remoteURLAttrs[strings.ToLower(attr.Key)] && isRemoteURL(attr.Val) {
+					blocked = true
+					continue
+				}
+				kept = append(kept, attr)
+			}
+			n.Attr = kept
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			strip(child)
+		}
+	}
+	for _, n := range nodes {
+		strip(n)
+	}
+	if !blocked {
+		return clean, false
+	}
+
+	var sb strings.Builder
+	for _, n := range nodes {
+		if err := html.Render(&sb, n); err != nil {
+			return html.EscapeString(htmlToText(clean)), true
+		}
+	}
+	return sb.String(), true
+}
+
+// isRemoteURL reports whether value (a URL or srcset) makes the client fetch
+// something, i.e. is anything but inline data or a reference to a MIME part.
+func isRemoteURL(value string) bool {
+	v := strings.ToLower(strings.TrimSpace(value))
+	return v != "" && !strings.HasPrefix(v, "data:") && !strings.HasPrefix(v, "cid:")
+}
+
+var (
+	blankLines = regexp.MustCompile(`\n{3,}`)
+	spaceRun   = regexp.MustCompile(`[ \t\r\n\f]+`)
+)
+
+// htmlBlockElements end the current line of derived text.
+var htmlBlockElements = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Br: true, atom.Li: true, atom.Tr: true,
+	atom.Table: true, atom.Ul: true, atom.Ol: true, atom.Blockquote: true,
+	atom.Pre: true, atom.Hr: true, atom.H1: true, atom.H2: true, atom.H3: true,
+	atom.H4: true, atom.H5: true, atom.H6: true,
+}
+
+// htmlToText derives a readable plain-text version of an HTML body for
+// messages that do not carry a text/plain part.
+func htmlToText(raw string) string {
+	doc, err := html.Parse(strings.NewReader(raw))
+	if err != nil {
+		return ""
+	}
+
+	var sb strings.Builder
+	var walk func(n *html.Node, pre bool)
+	walk = func(n *html.Node, pre bool) {
+		switch n.Type {
+		case html.TextNode:
+			if pre {
+				sb.WriteString(n.Data)
+			} else {
+				sb.WriteString(spaceRun.ReplaceAllString(n.Data, " "))
+			}
+			return
+		case html.ElementNode:
+			switch n.DataAtom {
+			case atom.Script, atom.Style, atom.Head, atom.Title:
+				return
+			case atom.Li:
+				if out := sb.String(); out != "" && !strings.HasSuffix(out, "\n") {
+					sb.WriteString("\n")
+				}
+				sb.WriteString("- ")
+			case atom.Td, atom.Th:
+				sb.WriteString(" ")
+			}
+			if n.DataAtom == atom.Pre {
+				pre = true
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child, pre)
+		}
+		if n.Type == html.ElementNode && htmlBlockElements[n.DataAtom] {
+			sb.WriteString("\n")
+			if n.DataAtom == atom.P || n.DataAtom == atom.Blockquote || n.DataAtom == atom.Table {
+				sb.WriteString("\n")
+			}
+		}
+	}
+	walk(doc, false)
+
+	lines := strings.Split(sb.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	text := blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.TrimSpace(text)
+}
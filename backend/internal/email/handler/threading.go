@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"messenger/backend/api/generated"
+)
+
+// replyPrefix matches the reply/forward markers mail clients stack in front of
+// a subject, e.g. "Re: Fwd: RE[2]:".
+var replyPrefix = regexp.MustCompile(`(?i)^\s*((re|fwd?|aw|sv)(\[\d+\])?\s*:\s*)+`)
+
+// normalizeSubject strips reply/forward prefixes so replies that lost their
+// reference headers can still be matched to their thread by subject.
+func normalizeSubject(subject string) string {
+	return strings.ToLower(strings.TrimSpace(replyPrefix.ReplaceAllString(subject, "")))
+}
+
+// unionFind is a disjoint-set over arbitrary string keys.
+type unionFind map[string]string
+
+func (u unionFind) find(key string) string {
+	parent, ok := u[key]
+	if !ok {
+		u[key] = key
+		return key
+	}
+	if parent == key {
+		return key
+	}
+	root := u.find(parent)
+	u[key] = root
+	return root
+}
+
+func (u unionFind) union(a, b string) {
+	ra, rb := u.find(a), u.find(b)
+	if ra != rb {
+		u[ra] = rb
+	}
+}
+
+// threadHeaders groups headers into conversations. Messages are linked through
+// their Message-ID, In-Reply-To and References headers; messages carrying
+// neither In-Reply-To nor References fall back to matching on the normalized
+// subject. Each thread's root is its oldest message and the replies follow in
+// date order. Threads are returned most recently active first.
+func threadHeaders(headers []generated.EmailRichHeader) []generated.EmailThread {
+	sets := make(unionFind)
+	keys := make([]string, len(headers))
+	for i, h := range headers {
+		key := "idx:" + strconv.Itoa(i)
+		if h.MessageId != nil && *h.MessageId != "" {
+			key = "id:" + *h.MessageId
+		}
+		keys[i] = key
+		sets.find(key)
+
+		hasRefs := false
+		if h.InReplyTo != nil && *h.InReplyTo != "" {
+			sets.union(key, "id:"+*h.InReplyTo)
+			hasRefs = true
+		}
+		if h.References != nil {
+			for _, ref := range *h.References {
+				if ref == "" {
+					continue
+				}
+				sets.union(key, "id:"+ref)
+				hasRefs = true
+			}
+		}
+		if !hasRefs && h.Subject != nil {
+			if subject := normalizeSubject(*h.Subject); subject != "" {
+				sets.union(key, "subject:"+subject)
+			}
+		}
+	}
+
+	groups := make(map[string][]generated.EmailRichHeader)
+	var order []string
+	for i, h := range headers {
+		root := sets.find(keys[i])
+		if _, ok := groups[root]; !ok {
+			order = append(order, root)
+		}
+		groups[root] = append(groups[root], h)
+	}
+
+	threads := make([]generated.EmailThread, 0, len(order))
+	for _, root := range order {
+		members := groups[root]
+		sort.SliceStable(members, func(i, j int) bool {
+			return headerDate(members[i]).Before(headerDate(members[j]))
+		})
+		threads = append(threads, generated.EmailThread{
+			Root:    members[0],
+			Replies: members[1:],
+		})
+	}
+	sort.SliceStable(threads, func(i, j int) bool {
+		return lastActivity(threads[i]).After(lastActivity(threads[j]))
+	})
+	return threads
+}
+
+func lastActivity(t generated.EmailThread) time.Time {
+	if len(t.Replies) == 0 {
+		return headerDate(t.Root)
+	}
+	return headerDate(t.Replies[len(t.Replies)-1])
+}
+
+func headerDate(h generated.EmailRichHeader) time.Time {
+	if h.Date == nil {
+		return time.Time{}
+	}
+	return *h.Date
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"messenger/backend/api/generated"
+)
+
+func richHeader(id, inReplyTo, subject string, day int, refs ...string) generated.EmailRichHeader {
+	date := time.Date(2024, time.May, day, 9, 0, 0, 0, time.UTC)
+	h := generated.EmailRichHeader{Subject: &subject, Date: &date}
+	if id != "" {
+		h.MessageId = &id
+	}
+	if inReplyTo != "" {
+		h.InReplyTo = &inReplyTo
+	}
+	if len(refs) > 0 {
+		h.References = &refs
+	}
+	return h
+}
+
+func TestThreadHeadersGroupsByReferencesAndSubject(t *testing.T) {
+	headers := []generated.EmailRichHeader{
+		richHeader("c@x", "b@x", "Re: Lunch", 3, "a@x", "b@x"),
+		richHeader("other@x", "", "Invoice", 4),
+		richHeader("a@x", "", "Lunch", 1),
+		// Reply whose parent was not fetched still joins via References.
+		richHeader("d@x", "missing@x", "Re: Lunch", 5, "a@x", "missing@x"),
+		// Reply stripped of reference headers falls back to the subject.
+		richHeader("", "", "RE: Fwd: invoice", 6),
+	}
+
+	threads := threadHeaders(headers)
+	if len(threads) != 2 {
+		t.Fatalf("len(threads) = %d, want 2", len(threads))
+	}
+
+	invoice, lunch := threads[0], threads[1]
+	if *invoice.Root.MessageId != "other@x" || len(invoice.Replies) != 1 {
+		t.Fatalf("invoice thread = root %v with %d replies", *invoice.Root.MessageId, len(invoice.Replies))
+	}
+	if *lunch.Root.MessageId != "a@x" || len(lunch.Replies) != 2 {
+		t.Fatalf("lunch thread = root %v with %d replies", *lunch.Root.MessageId, len(lunch.Replies))
+	}
+	if *lunch.Replies[0].MessageId != "c@x" || *lunch.Replies[1].MessageId != "d@x" {
+		t.Fatalf("lunch replies out of date order: %v, %v", *lunch.Replies[0].MessageId, *lunch.Replies[1].MessageId)
+	}
+}
+
+func TestNormalizeSubject(t *testing.T) {
+	for in, want := range map[string]string{
+		"Re: Fwd: Lunch":  "lunch",
+		"RE[2]: FW: Plan": "plan",
+		"Lunch":           "lunch",
+		"Re:":             "",
+	} {
+		if got := normalizeSubject(in); got != want {
+			t.Fatalf("normalizeSubject(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
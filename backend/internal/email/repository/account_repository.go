@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"messenger/backend/internal/email/entity"
+)
+
+// AccountRepository stores registered email accounts. It satisfies the email
+// handler's AccountStore.
+type AccountRepository struct {
+	db *gorm.DB
+}
+
+// NewAccountRepository creates an AccountRepository.
+func NewAccountRepository(db *gorm.DB) *AccountRepository {
+	return &AccountRepository{db: db}
+}
+
+// CreateAccount inserts account.
+func (r *AccountRepository) CreateAccount(ctx context.Context, account *entity.Account) error {
+	if err := r.db.WithContext(ctx).Create(account).Error; err != nil {
+		return fmt.Errorf("failed to create email account: %w", err)
+	}
+	return nil
+}
+
+// ListAccounts returns userID's accounts, oldest first.
+func (r *AccountRepository) ListAccounts(ctx context.Context, userID string) ([]entity.Account, error) {
+	var accounts []entity.Account
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at, id").
+		Find(&accounts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list email accounts: %w", err)
+	}
+	return accounts, nil
+}
+
+// GetAccount returns the account id of userID, or entity.ErrNotFound when
+// there is none, including when it belongs to someone else.
+func (r *AccountRepository) GetAccount(ctx context.Context, userID, id string) (*entity.Account, error) {
+	var accounts []entity.Account
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", id, userID).
+		Limit(1).
+		Find(&accounts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get email account: %w", err)
+	}
+	if len(accounts) == 0 {
+		return nil, entity.ErrNotFound
+	}
+	return &accounts[0], nil
+}
+
+// DeleteAccount removes the account id of userID, returning
+// entity.ErrNotFound when there is none.
+func (r *AccountRepository) DeleteAccount(ctx context.Context, userID, id string) error {
+	result := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&entity.Account{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete email account: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrNotFound
+	}
+	return nil
+}
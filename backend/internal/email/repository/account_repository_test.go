@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"messenger/backend/internal/email/entity"
+)
+
+func TestAccountRepository(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	if err := db.Exec(`CREATE TABLE email_accounts (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		host TEXT NOT NULL,
+		port INTEGER NOT NULL,
+		email TEXT NOT NULL,
+		security TEXT NOT NULL,
+		sealed_password TEXT NOT NULL,
+		default_mailbox TEXT NOT NULL DEFAULT 'INBOX',
+		created_at DATETIME
+	)`).Error; err != nil {
+		t.Fatalf("create table error = %v", err)
+	}
+	repo := NewAccountRepository(db)
+	ctx := context.Background()
+
+	account := &entity.Account{
+		ID:             "a1",
+		UserID:         "u1",
+		Host:           "imap.example.com",
+		Port:           993,
+		Email:          "me@example.com",
+		Security:       "tls",
+		SealedPassword: "sealed",
+		DefaultMailbox: "Archive",
+	}
+	if err := repo.CreateAccount(ctx, account); err != nil {
+		t.Fatalf("CreateAccount() error = %v", err)
+	}
+
+	got, err := repo.GetAccount(ctx, "u1", "a1")
+	if err != nil {
+		t.Fatalf("GetAccount() error = %v", err)
+	}
+	if got.Host != "imap.example.com" || got.SealedPassword != "sealed" || got.DefaultMailbox != "Archive" {
+		t.Fatalf("GetAccount() = %+v", got)
+	}
+	if _, err := repo.GetAccount(ctx, "u2", "a1"); !errors.Is(err, entity.ErrNotFound) {
+		t.Fatalf("GetAccount(other user) error = %v, want ErrNotFound", err)
+	}
+	if accounts, err := repo.ListAccounts(ctx, "u1"); err != nil || len(accounts) != 1 {
+		t.Fatalf("ListAccounts() = %v, %v, want the account", accounts, err)
+	}
+
+	if err := repo.DeleteAccount(ctx, "u2", "a1"); !errors.Is(err, entity.ErrNotFound) {
+		t.Fatalf("DeleteAccount(other user) error = %v, want ErrNotFound", err)
+	}
+	if err := repo.DeleteAccount(ctx, "u1", "a1"); err != nil {
+		t.Fatalf("DeleteAccount() error = %v", err)
+	}
+	if accounts, _ := repo.ListAccounts(ctx, "u1"); len(accounts) != 0 {
+		t.Fatalf("ListAccounts() after delete = %v, want none", accounts)
+	}
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"messenger/backend/api/generated"
+)
+
+// headerCacheRow is a cached envelope in email_header_cache.
+type headerCacheRow struct {
+	Account     string `gorm:"primaryKey"`
+	Mailbox     string `gorm:"primaryKey"`
+	UID         uint32 `gorm:"column:uid;primaryKey"`
+	UIDValidity uint32 `gorm:"column:uid_validity"`
+	Header      []byte `gorm:"type:jsonb"`
+}
+
+func (headerCacheRow) TableName() string { return "email_header_cache" }
+
+// HeaderCacheRepository persists fetched email headers in Postgres so they
+// survive restarts. It satisfies the email handler's HeaderCache.
+type HeaderCacheRepository struct {
+	db *gorm.DB
+}
+
+// NewHeaderCacheRepository creates a HeaderCacheRepository.
+func NewHeaderCacheRepository(db *gorm.DB) *HeaderCacheRepository {
+	return &HeaderCacheRepository{db: db}
+}
+
+// GetHeaders returns the cached headers among uids that were stored under
+// uidValidity.
+func (r *HeaderCacheRepository) GetHeaders(ctx context.Context, account, mailbox string, uidValidity uint32, uids []uint32) (map[uint32]generated.EmailRichHeader, error) {
+	var rows []headerCacheRow
+	err := r.db.WithContext(ctx).
+		Where("account = ? AND mailbox = ? AND uid_validity = ? AND uid IN ?", account, mailbox, uidValidity, uids).
+		Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cached email headers: %w", err)
+	}
+	headers := make(map[uint32]generated.EmailRichHeader, len(rows))
+	for _, row := range rows {
+		var header generated.EmailRichHeader
+		if err := json.Unmarshal(row.Header, &header); err != nil {
+			continue
+		}
+		headers[row.UID] = header
+	}
+	return headers, nil
+}
+
+// PutHeaders stores headers and drops whatever the mailbox held under an
+// older UIDVALIDITY, whose UIDs no longer mean anything.
+func (r *HeaderCacheRepository) PutHeaders(ctx context.Context, account, mailbox string, uidValidity uint32, headers []generated.EmailRichHeader) error {
+	rows := make([]headerCacheRow, 0, len(headers))
+	for _, header := range headers {
+		if header.Uid == nil {
+			continue
+		}
+		raw, err := json.Marshal(header)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, headerCacheRow{Account: account, Mailbox: mailbox, UID: uint32(*header.Uid), UIDValidity: uidValidity, Header: raw})
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("account = ? AND mailbox = ? AND uid_validity <> ?", account, mailbox, uidValidity).
+			Delete(&headerCacheRow{}).Error; err != nil {
+			return fmt.Errorf("failed to invalidate cached email headers: %w", err)
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		err := tx.Clauses(clause.OnConflict{UpdateAll: true}).CreateInBatches(rows, 500).Error
+		if err != nil {
+			return fmt.Errorf("failed to cache email headers: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"messenger/backend/api/generated"
+)
+
+func TestHeaderCacheRepository(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	if err := db.Exec(`CREATE TABLE email_header_cache (
+		account TEXT NOT NULL,
+		mailbox TEXT NOT NULL,
+		uid INTEGER NOT NULL,
+		uid_validity INTEGER NOT NULL,
+		header BLOB NOT NULL,
+		PRIMARY KEY (account, mailbox, uid)
+	)`).Error; err != nil {
+		t.Fatalf("create table error = %v", err)
+	}
+	repo := NewHeaderCacheRepository(db)
+	ctx := context.Background()
+	header := func(uid int64, subject string) generated.EmailRichHeader {
+		return generated.EmailRichHeader{Uid: &uid, Subject: &subject}
+	}
+
+	if err := repo.PutHeaders(ctx, "acct", "INBOX", 1, []generated.EmailRichHeader{header(1, "one"), header(2, "two")}); err != nil {
+		t.Fatalf("PutHeaders() error = %v", err)
+	}
+	if err := repo.PutHeaders(ctx, "acct", "INBOX", 1, []generated.EmailRichHeader{header(2, "two again")}); err != nil {
+		t.Fatalf("PutHeaders(overwrite) error = %v", err)
+	}
+	got, err := repo.GetHeaders(ctx, "acct", "INBOX", 1, []uint32{1, 2, 3})
+	if err != nil {
+		t.Fatalf("GetHeaders() error = %v", err)
+	}
+	if len(got) != 2 || *got[1].Subject != "one" || *got[2].Subject != "two again" {
+		t.Fatalf("GetHeaders() = %v", got)
+	}
+
+	// A new UIDVALIDITY drops the mailbox's old generation.
+	if err := repo.PutHeaders(ctx, "acct", "INBOX", 2, []generated.EmailRichHeader{header(1, "renumbered")}); err != nil {
+		t.Fatalf("PutHeaders(new validity) error = %v", err)
+	}
+	var rows int64
+	db.Table("email_header_cache").Count(&rows)
+	if rows != 1 {
+		t.Fatalf("rows = %d, want only the new generation", rows)
+	}
+	if got, _ := repo.GetHeaders(ctx, "acct", "INBOX", 1, []uint32{1, 2}); len(got) != 0 {
+		t.Fatalf("GetHeaders(old validity) = %v, want nothing", got)
+	}
+}
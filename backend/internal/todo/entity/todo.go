@@ -4,9 +4,39 @@ import (
 	"errors"
 	userentity "messenger/backend/internal/user/entity"
 	"time"
+
+	"gorm.io/gorm"
+)
+
+// Sentinel errors returned (usually wrapped) by the todo repositories and
+// usecases. Callers should match them with errors.Is.
+var (
+	ErrNotFound  = errors.New("not found")
+	ErrForbidden = errors.New("forbidden")
+	ErrConflict  = errors.New("conflict")
+	ErrInvalid   = errors.New("invalid input")
+)
+
+// Todo item priorities, from least to most urgent.
+const (
+	PriorityLow    = "low"
+	PriorityMedium = "medium"
+	PriorityHigh   = "high"
 )
 
-var ErrNotFound = errors.New("not found")
+// PriorityRank orders priorities from low (1) to high (3); anything else
+// ranks 0.
+func PriorityRank(priority string) int {
+	switch priority {
+	case PriorityLow:
+		return 1
+	case PriorityMedium:
+		return 2
+	case PriorityHigh:
+		return 3
+	}
+	return 0
+}
 
 // TodoList represents a todo list.
 type TodoList struct {
@@ -18,6 +48,12 @@ type TodoList struct {
 	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 }
 
+// TodoListStats counts the items of a list that are not in the trash.
+type TodoListStats struct {
+	Total     int64
+	Completed int64
+}
+
 // TodoItem represents a todo item within a list.
 type TodoItem struct {
 	ID          string     `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
@@ -29,9 +65,22 @@ type TodoItem struct {
 	
 	Deadline    *time.Time `gorm:"type:timestamp with time zone" json:"due_date,omitempty"` // Optional
 	Completed   bool       `gorm:"type:boolean;default:false" json:"completed"`
+	Priority    string     `gorm:"type:varchar(8);not null;default:medium" json:"priority"` // low, medium or high
+	Version     int64      `gorm:"not null;default:1" json:"version"`                        // Bumped on every update, for optimistic concurrency
+	
+	CreatedBy         *string `gorm:"type:uuid" json:"created_by,omitempty"`              // ID of the user who created the item; nil for legacy rows
+	CreatedByUsername string  `gorm:"->;-:migration" json:"created_by_username,omitempty"` // Read-only, joined from users by the repository
+	Tags              []string `gorm:"-" json:"tags"`                                     // Stored in todo_item_tags; nil on input means unchanged
 	
 	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt   time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"` // Set when the item is moved to the trash
+}
+
+// TodoItemTag is one normalized tag on a todo item.
+type TodoItemTag struct {
+	ItemID string `gorm:"type:uuid;primaryKey" json:"item_id"`
+	Tag    string `gorm:"type:varchar(64);primaryKey" json:"tag"`
 }
 
 // TodoListCollaborator represents a many-to-many relationship between TodoList and User.
@@ -42,8 +91,42 @@ type TodoListCollaborator struct {
 	UpdatedAt      time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 }
 
+// TodoListInvite lets whoever holds its token join a todo list as a
+// collaborator. Only the token's SHA-256 is stored.
+type TodoListInvite struct {
+	TokenHash  string     `gorm:"type:varchar(64);primaryKey" json:"-"`
+	TodoListID string     `gorm:"type:uuid;not null" json:"todo_list_id"`
+	CreatedBy  string     `gorm:"type:uuid;not null" json:"created_by"`
+	SingleUse  bool       `gorm:"not null" json:"single_use"`
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt     *time.Time `json:"used_at,omitempty"` // Set once a single-use invite is accepted
+	UsedBy     *string    `gorm:"type:uuid" json:"used_by,omitempty"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// SharedTodoList is a todo list seen from a collaborator's side, together with
+// the time it was shared with them.
+type SharedTodoList struct {
+	TodoList `gorm:"embedded"`
+	SharedAt time.Time `json:"shared_at"`
+}
+
 // TodoListCollaboratorDetail combines TodoListCollaborator with User details.
 type TodoListCollaboratorDetail struct {
 	TodoListCollaborator
 	userentity.User
 }
+
+// ItemCursor marks where a page of a list's items ended: the position and ID
+// of its last item. The next page starts right after it.
+type ItemCursor struct {
+	Position string `json:"p"`
+	ID       string `json:"i"`
+}
+
+// ListCursor marks where a page of todo lists ended: the creation time and ID
+// of its last list. Lists are paged newest first.
+type ListCursor struct {
+	CreatedAt time.Time `json:"c"`
+	ID        string    `json:"i"`
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"messenger/backend/internal/todo/entity"
 	userentity "messenger/backend/internal/user/entity"
@@ -12,10 +13,22 @@ import (
 // TodoItemRepository defines the interface for todo item data operations.
 type TodoItemRepository interface {
 	CreateTodoItem(ctx context.Context, todoItem *entity.TodoItem) error
+	CreateTodoItems(ctx context.Context, todoItems []entity.TodoItem) error
 	GetTodoItemByID(ctx context.Context, id string) (*entity.TodoItem, error)
 	GetTodoItemsByListID(ctx context.Context, listID string) ([]entity.TodoItem, error)
+	GetTodoItemsPageByListID(ctx context.Context, listID string, after *entity.ItemCursor, limit int) ([]entity.TodoItem, error)
+	CountTodoItemsByListID(ctx context.Context, listID string) (int64, error)
+	StreamTodoItemsByListID(ctx context.Context, listID string, fn func(*entity.TodoItem) error) error
 	UpdateTodoItem(ctx context.Context, todoItem *entity.TodoItem) error
+	SetTodoItemsCompleted(ctx context.Context, listID string, completed bool, tag string) (int64, error)
 	DeleteTodoItem(ctx context.Context, id string) error
+	GetDeletedTodoItemByID(ctx context.Context, id string) (*entity.TodoItem, error)
+	RestoreTodoItem(ctx context.Context, id string) error
+	PurgeDeletedTodoItems(ctx context.Context, deletedBefore time.Time) (int64, error)
+	SetTodoItemTags(ctx context.Context, itemID string, tags []string) error
+	GetTodoItemsByTag(ctx context.Context, userID string, tag string) ([]entity.TodoItem, error)
+	GetTodoItemsWithDeadline(ctx context.Context, userID string) ([]entity.TodoItem, error)
+	WithTx(tx *gorm.DB) TodoItemRepository
 }
 
 // TodoListCollaboratorRepository defines the interface for todo list collaborator data operations.
@@ -23,29 +36,30 @@ type TodoListCollaboratorRepository interface {
 	AddCollaborator(ctx context.Context, collaborator *entity.TodoListCollaborator) error
 	RemoveCollaborator(ctx context.Context, todoListID, userID string) error
 	IsCollaborator(ctx context.Context, todoListID, userID string) (bool, error)
+	CountCollaborators(ctx context.Context, todoListID string) (int64, error)
 	GetCollaboratorsByTodoListID(ctx context.Context, todoListID string) ([]userentity.User, error)
 	GetTodoListsByCollaboratorID(ctx context.Context, userID string) ([]entity.TodoList, error)
+	GetSharedTodoLists(ctx context.Context, userID string) ([]entity.SharedTodoList, error)
 	GetCollaboratorIDsByTodoListID(ctx context.Context, todoListID string) ([]string, error)
+	WithTx(tx *gorm.DB) TodoListCollaboratorRepository
 }
 
-// Repository combines all specific repository interfaces.
-type Repository interface {
-	TodoListRepository
-	TodoItemRepository
-	TodoListCollaboratorRepository
+// Transactor runs fn inside a single database transaction. Repositories
+// obtained through their WithTx methods with the given tx take part in it; the
+// transaction is rolled back if fn returns an error.
+type Transactor interface {
+	Transaction(ctx context.Context, fn func(tx *gorm.DB) error) error
 }
 
-type repository struct {
-	TodoListRepository
-	TodoItemRepository
-	TodoListCollaboratorRepository
+type transactor struct {
+	db *gorm.DB
 }
 
-// NewRepository creates a new repository.
-func NewRepository(db *gorm.DB) Repository {
-	return &repository{
-		TodoListRepository:             NewTodoListRepository(db),
-		TodoItemRepository:             NewTodoItemRepository(db),
-		TodoListCollaboratorRepository: NewTodoListCollaboratorRepository(db),
-	}
+// NewTransactor creates a new Transactor.
+func NewTransactor(db *gorm.DB) Transactor {
+	return &transactor{db: db}
+}
+
+func (t *transactor) Transaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return t.db.WithContext(ctx).Transaction(fn)
 }
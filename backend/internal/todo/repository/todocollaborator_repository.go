@@ -17,6 +17,10 @@ func NewTodoListCollaboratorRepository(db *gorm.DB) TodoListCollaboratorReposito
 	return &todoListCollaboratorRepository{db: db}
 }
 
+func (r *todoListCollaboratorRepository) WithTx(tx *gorm.DB) TodoListCollaboratorRepository {
+	return &todoListCollaboratorRepository{db: tx}
+}
+
 func (r *todoListCollaboratorRepository) AddCollaborator(ctx context.Context, collaborator *entity.TodoListCollaborator) error {
 	err := r.db.WithContext(ctx).Create(collaborator).Error
 	if err != nil {
@@ -33,6 +37,15 @@ func (r *todoListCollaboratorRepository) RemoveCollaborator(ctx context.Context,
 	return nil
 }
 
+func (r *todoListCollaboratorRepository) CountCollaborators(ctx context.Context, todoListID string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&entity.TodoListCollaborator{}).Where("todo_list_id = ?", todoListID).Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count collaborators: %w", err)
+	}
+	return count, nil
+}
+
 func (r *todoListCollaboratorRepository) IsCollaborator(ctx context.Context, todoListID, userID string) (bool, error) {
 	var count int64
 	err := r.db.WithContext(ctx).Model(&entity.TodoListCollaborator{}).Where("todo_list_id = ? AND collaborator_id = ?", todoListID, userID).Count(&count).Error
@@ -68,6 +81,23 @@ func (r *todoListCollaboratorRepository) GetTodoListsByCollaboratorID(ctx contex
 	return todoLists, nil
 }
 
+// GetSharedTodoLists returns the lists userID collaborates on without owning,
+// most recently shared first.
+func (r *todoListCollaboratorRepository) GetSharedTodoLists(ctx context.Context, userID string) ([]entity.SharedTodoList, error) {
+	var sharedLists []entity.SharedTodoList
+	err := r.db.WithContext(ctx).
+		Table("todo_lists").
+		Select("todo_lists.*, tlc.created_at AS shared_at").
+		Joins("JOIN todo_list_collaborators tlc ON todo_lists.id = tlc.todo_list_id").
+		Where("tlc.collaborator_id = ? AND todo_lists.owner_id <> ?", userID, userID).
+		Order("tlc.created_at DESC").
+		Scan(&sharedLists).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shared todo lists: %w", err)
+	}
+	return sharedLists, nil
+}
+
 func (r *todoListCollaboratorRepository) GetCollaboratorIDsByTodoListID(ctx context.Context, todoListID string) ([]string, error) {
 	var userIDs []string
 	err := r.db.WithContext(ctx).Model(&entity.TodoListCollaborator{}).Where("todo_list_id = ?", todoListID).Select("collaborator_id").Find(&userIDs).Error
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"messenger/backend/internal/todo/entity"
+	userentity "messenger/backend/internal/user/entity"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+const (
+	testOwnerID        = "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa"
+	testCollaboratorID = "bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb"
+	testListID         = "11111111-1111-1111-1111-111111111111"
+)
+
+// newTestDB creates the collaborator tables with the column names used by the
+// schema migrations, so a query against the wrong column fails here.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("db.DB() error = %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	for _, statement := range []string{
+		`CREATE TABLE users (
+			id TEXT PRIMARY KEY,
+			username TEXT NOT NULL,
+			matrix_id TEXT UNIQUE,
+			email TEXT NOT NULL UNIQUE,
+			password_hash TEXT NOT NULL,
+			matrix_access_token TEXT NOT NULL DEFAULT '',
+			timezone TEXT NOT NULL DEFAULT '',
+			todo_feed_token_hash TEXT NOT NULL DEFAULT '',
+			role TEXT NOT NULL DEFAULT 'user',
+			created_at DATETIME,
+			updated_at DATETIME
+		)`,
+		`CREATE TABLE todo_lists (
+			id TEXT PRIMARY KEY,
+			title TEXT NOT NULL,
+			description TEXT NOT NULL,
+			owner_id TEXT NOT NULL,
+			created_at DATETIME,
+			updated_at DATETIME
+		)`,
+		`CREATE TABLE todo_list_collaborators (
+			todo_list_id TEXT NOT NULL,
+			collaborator_id TEXT NOT NULL,
+			created_at DATETIME,
+			updated_at DATETIME,
+			PRIMARY KEY (todo_list_id, collaborator_id)
+		)`,
+	} {
+		if err := db.Exec(statement).Error; err != nil {
+			t.Fatalf("Exec(%q) error = %v", statement, err)
+		}
+	}
+
+	users := []userentity.User{
+		{ID: uuid.MustParse(testOwnerID), Username: "owner", MatrixID: "@owner:example.org", Email: "owner@example.org"},
+		{ID: uuid.MustParse(testCollaboratorID), Username: "collab", MatrixID: "@collab:example.org", Email: "collab@example.org"},
+	}
+	if err := db.Create(&users).Error; err != nil {
+		t.Fatalf("Create(users) error = %v", err)
+	}
+	list := entity.TodoList{ID: testListID, Title: "Groceries", OwnerID: testOwnerID}
+	if err := db.Create(&list).Error; err != nil {
+		t.Fatalf("Create(list) error = %v", err)
+	}
+	return db
+}
+
+func TestCollaboratorRepositoryAddsAndQueriesCollaborators(t *testing.T) {
+	repo := NewTodoListCollaboratorRepository(newTestDB(t))
+	ctx := context.Background()
+
+	err := repo.AddCollaborator(ctx, &entity.TodoListCollaborator{TodoListID: testListID, CollaboratorID: testCollaboratorID})
+	if err != nil {
+		t.Fatalf("AddCollaborator() error = %v", err)
+	}
+
+	isCollab, err := repo.IsCollaborator(ctx, testListID, testCollaboratorID)
+	if err != nil || !isCollab {
+		t.Fatalf("IsCollaborator() = %t, %v, want true", isCollab, err)
+	}
+
+	users, err := repo.GetCollaboratorsByTodoListID(ctx, testListID)
+	if err != nil {
+		t.Fatalf("GetCollaboratorsByTodoListID() error = %v", err)
+	}
+	if len(users) != 1 || users[0].ID.String() != testCollaboratorID || users[0].Username != "collab" {
+		t.Fatalf("GetCollaboratorsByTodoListID() = %+v, want only %s", users, testCollaboratorID)
+	}
+
+	ids, err := repo.GetCollaboratorIDsByTodoListID(ctx, testListID)
+	if err != nil || len(ids) != 1 || ids[0] != testCollaboratorID {
+		t.Fatalf("GetCollaboratorIDsByTodoListID() = %v, %v, want [%s]", ids, err, testCollaboratorID)
+	}
+
+	lists, err := repo.GetTodoListsByCollaboratorID(ctx, testCollaboratorID)
+	if err != nil || len(lists) != 1 || lists[0].ID != testListID {
+		t.Fatalf("GetTodoListsByCollaboratorID() = %+v, %v, want [%s]", lists, err, testListID)
+	}
+
+	if err := repo.RemoveCollaborator(ctx, testListID, testCollaboratorID); err != nil {
+		t.Fatalf("RemoveCollaborator() error = %v", err)
+	}
+	isCollab, err = repo.IsCollaborator(ctx, testListID, testCollaboratorID)
+	if err != nil || isCollab {
+		t.Fatalf("IsCollaborator() after removal = %t, %v, want false", isCollab, err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"messenger/backend/internal/todo/entity"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -16,6 +17,18 @@ func NewTodoItemRepository(db *gorm.DB) TodoItemRepository {
 	return &todoItemRepository{db: db}
 }
 
+func (r *todoItemRepository) WithTx(tx *gorm.DB) TodoItemRepository {
+	return &todoItemRepository{db: tx}
+}
+
+// withCreator selects items together with their creator's username, which
+// fills the read-only TodoItem.CreatedByUsername. Conditions on item columns
+// must be qualified with the todo_items table.
+func withCreator(db *gorm.DB) *gorm.DB {
+	return db.Select("todo_items.*, COALESCE(users.username, '') AS created_by_username").
+		Joins("LEFT JOIN users ON users.id = todo_items.created_by")
+}
+
 func (r *todoItemRepository) CreateTodoItem(ctx context.Context, todoItem *entity.TodoItem) error {
 	err := r.db.WithContext(ctx).Create(todoItem).Error
 	if err != nil {
@@ -24,27 +37,133 @@ func (r *todoItemRepository) CreateTodoItem(ctx context.Context, todoItem *entit
 	return nil
 }
 
+// CreateTodoItems inserts all items in a single transaction, so either every
+// item is created or none are.
+func (r *todoItemRepository) CreateTodoItems(ctx context.Context, todoItems []entity.TodoItem) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i := range todoItems {
+			if err := tx.Create(&todoItems[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create todo items: %w", err)
+	}
+	return nil
+}
+
 func (r *todoItemRepository) GetTodoItemByID(ctx context.Context, id string) (*entity.TodoItem, error) {
 	var todoItem entity.TodoItem
-	err := r.db.WithContext(ctx).First(&todoItem, "id = ?", id).Error
+	err := r.db.WithContext(ctx).Scopes(withCreator).First(&todoItem, "todo_items.id = ?", id).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, entity.ErrNotFound
 		}
 		return nil, fmt.Errorf("failed to get todo item by ID: %w", err)
 	}
+	if err := r.attachTags(ctx, []*entity.TodoItem{&todoItem}); err != nil {
+		return nil, err
+	}
 	return &todoItem, nil
 }
 
 func (r *todoItemRepository) GetTodoItemsByListID(ctx context.Context, listID string) ([]entity.TodoItem, error) {
 	var todoItems []entity.TodoItem
-	err := r.db.WithContext(ctx).Where("list_id = ?", listID).Find(&todoItems).Error
+	err := r.db.WithContext(ctx).Scopes(withCreator).
+		Where("todo_items.list_id = ?", listID).
+		Order("todo_items.position, todo_items.id").
+		Find(&todoItems).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed to get todo items by list ID: %w", err)
 	}
+	if err := r.attachTags(ctx, itemPointers(todoItems)); err != nil {
+		return nil, err
+	}
 	return todoItems, nil
 }
 
+// GetTodoItemsPageByListID returns up to limit items of the list in position
+// order, starting after the cursor when one is given. Seeking past
+// (position, id) rather than skipping rows keeps pages stable while items are
+// added, moved or deleted.
+func (r *todoItemRepository) GetTodoItemsPageByListID(ctx context.Context, listID string, after *entity.ItemCursor, limit int) ([]entity.TodoItem, error) {
+	var todoItems []entity.TodoItem
+	db := r.db.WithContext(ctx).Scopes(withCreator).
+		Where("todo_items.list_id = ?", listID)
+	if after != nil {
+		db = db.Where("todo_items.position > ? OR (todo_items.position = ? AND todo_items.id > ?)", after.Position, after.Position, after.ID)
+	}
+	err := db.Order("todo_items.position, todo_items.id").
+		Limit(limit).
+		Find(&todoItems).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get page of todo items by list ID: %w", err)
+	}
+	if err := r.attachTags(ctx, itemPointers(todoItems)); err != nil {
+		return nil, err
+	}
+	return todoItems, nil
+}
+
+// CountTodoItemsByListID counts the items of the list, leaving out those in
+// the trash.
+func (r *todoItemRepository) CountTodoItemsByListID(ctx context.Context, listID string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&entity.TodoItem{}).Where("list_id = ?", listID).Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count todo items by list ID: %w", err)
+	}
+	return count, nil
+}
+
+// StreamTodoItemsByListID calls fn for each item of the list in position
+// order, reading them from a cursor rather than loading the whole list. The
+// list's tags are loaded up front so no other query runs while the cursor is
+// open. An error from fn stops the iteration and is returned as is.
+func (r *todoItemRepository) StreamTodoItemsByListID(ctx context.Context, listID string, fn func(*entity.TodoItem) error) error {
+	var tagRows []entity.TodoItemTag
+	err := r.db.WithContext(ctx).
+		Joins("JOIN todo_items ON todo_items.id = todo_item_tags.item_id").
+		Where("todo_items.list_id = ? AND todo_items.deleted_at IS NULL", listID).
+		Order("todo_item_tags.tag").
+		Find(&tagRows).Error
+	if err != nil {
+		return fmt.Errorf("failed to get todo item tags: %w", err)
+	}
+	tags := make(map[string][]string)
+	for _, row := range tagRows {
+		tags[row.ItemID] = append(tags[row.ItemID], row.Tag)
+	}
+
+	db := r.db.WithContext(ctx).Scopes(withCreator).Model(&entity.TodoItem{}).
+		Where("todo_items.list_id = ?", listID).
+		Order("todo_items.position, todo_items.id")
+	rows, err := db.Rows()
+	if err != nil {
+		return fmt.Errorf("failed to get todo items by list ID: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var item entity.TodoItem
+		if err := db.ScanRows(rows, &item); err != nil {
+			return fmt.Errorf("failed to scan todo item: %w", err)
+		}
+		item.Tags = tags[item.ID]
+		if item.Tags == nil {
+			item.Tags = []string{}
+		}
+		if err := fn(&item); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to get todo items by list ID: %w", err)
+	}
+	return nil
+}
+
 func (r *todoItemRepository) UpdateTodoItem(ctx context.Context, todoItem *entity.TodoItem) error {
 	err := r.db.WithContext(ctx).Save(todoItem).Error
 	if err != nil {
@@ -53,6 +172,25 @@ func (r *todoItemRepository) UpdateTodoItem(ctx context.Context, todoItem *entit
 	return nil
 }
 
+// SetTodoItemsCompleted sets completed on the list's items in one UPDATE,
+// bumping the version of each item it changes, and returns how many that was.
+// A non-empty tag, already normalized, limits it to items carrying the tag.
+func (r *todoItemRepository) SetTodoItemsCompleted(ctx context.Context, listID string, completed bool, tag string) (int64, error) {
+	query := r.db.WithContext(ctx).Model(&entity.TodoItem{}).
+		Where("list_id = ? AND completed <> ?", listID, completed)
+	if tag != "" {
+		query = query.Where("id IN (SELECT item_id FROM todo_item_tags WHERE tag = ?)", tag)
+	}
+	result := query.Updates(map[string]interface{}{
+		"completed": completed,
+		"version":   gorm.Expr("version + 1"),
+	})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to set todo items completed: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
 func (r *todoItemRepository) DeleteTodoItem(ctx context.Context, id string) error {
 	err := r.db.WithContext(ctx).Delete(&entity.TodoItem{}, "id = ?", id).Error
 	if err != nil {
@@ -60,3 +198,140 @@ func (r *todoItemRepository) DeleteTodoItem(ctx context.Context, id string) erro
 	}
 	return nil
 }
+
+// GetDeletedTodoItemByID returns an item that has been moved to the trash.
+// Items that are not deleted are reported as not found.
+func (r *todoItemRepository) GetDeletedTodoItemByID(ctx context.Context, id string) (*entity.TodoItem, error) {
+	var todoItem entity.TodoItem
+	err := r.db.WithContext(ctx).Unscoped().Scopes(withCreator).
+		Where("todo_items.deleted_at IS NOT NULL").
+		First(&todoItem, "todo_items.id = ?", id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, entity.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get deleted todo item by ID: %w", err)
+	}
+	if err := r.attachTags(ctx, []*entity.TodoItem{&todoItem}); err != nil {
+		return nil, err
+	}
+	return &todoItem, nil
+}
+
+func (r *todoItemRepository) RestoreTodoItem(ctx context.Context, id string) error {
+	err := r.db.WithContext(ctx).Unscoped().
+		Model(&entity.TodoItem{}).
+		Where("id = ?", id).
+		Update("deleted_at", nil).Error
+	if err != nil {
+		return fmt.Errorf("failed to restore todo item: %w", err)
+	}
+	return nil
+}
+
+// PurgeDeletedTodoItems permanently removes items that were deleted before
+// deletedBefore and returns how many rows were removed.
+func (r *todoItemRepository) PurgeDeletedTodoItems(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore).
+		Delete(&entity.TodoItem{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to purge deleted todo items: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// SetTodoItemTags replaces the tags of an item. tags must already be
+// normalized; an empty slice clears them.
+func (r *todoItemRepository) SetTodoItemTags(ctx context.Context, itemID string, tags []string) error {
+	db := r.db.WithContext(ctx)
+	if err := db.Where("item_id = ?", itemID).Delete(&entity.TodoItemTag{}).Error; err != nil {
+		return fmt.Errorf("failed to clear todo item tags: %w", err)
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	rows := make([]entity.TodoItemTag, len(tags))
+	for i, tag := range tags {
+		rows[i] = entity.TodoItemTag{ItemID: itemID, Tag: tag}
+	}
+	if err := db.Create(&rows).Error; err != nil {
+		return fmt.Errorf("failed to set todo item tags: %w", err)
+	}
+	return nil
+}
+
+// GetTodoItemsByTag returns the items tagged with tag in every list userID
+// owns or collaborates on, ordered by list and position.
+func (r *todoItemRepository) GetTodoItemsByTag(ctx context.Context, userID string, tag string) ([]entity.TodoItem, error) {
+	var todoItems []entity.TodoItem
+	err := r.db.WithContext(ctx).Scopes(withCreator).
+		Joins("JOIN todo_item_tags ON todo_item_tags.item_id = todo_items.id").
+		Joins("JOIN todo_lists ON todo_lists.id = todo_items.list_id").
+		Where("todo_item_tags.tag = ?", tag).
+		Where("todo_lists.owner_id = ? OR EXISTS (SELECT 1 FROM todo_list_collaborators WHERE todo_list_collaborators.todo_list_id = todo_lists.id AND todo_list_collaborators.collaborator_id = ?)", userID, userID).
+		Order("todo_items.list_id, todo_items.position, todo_items.id").
+		Find(&todoItems).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get todo items by tag: %w", err)
+	}
+	if err := r.attachTags(ctx, itemPointers(todoItems)); err != nil {
+		return nil, err
+	}
+	return todoItems, nil
+}
+
+// GetTodoItemsWithDeadline returns the items that have a deadline in every
+// list userID owns or collaborates on, earliest deadline first.
+func (r *todoItemRepository) GetTodoItemsWithDeadline(ctx context.Context, userID string) ([]entity.TodoItem, error) {
+	var todoItems []entity.TodoItem
+	err := r.db.WithContext(ctx).Scopes(withCreator).
+		Joins("JOIN todo_lists ON todo_lists.id = todo_items.list_id").
+		Where("todo_items.deadline IS NOT NULL").
+		Where("todo_lists.owner_id = ? OR EXISTS (SELECT 1 FROM todo_list_collaborators WHERE todo_list_collaborators.todo_list_id = todo_lists.id AND todo_list_collaborators.collaborator_id = ?)", userID, userID).
+		Order("todo_items.deadline, todo_items.id").
+		Find(&todoItems).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get todo items with deadline: %w", err)
+	}
+	if err := r.attachTags(ctx, itemPointers(todoItems)); err != nil {
+		return nil, err
+	}
+	return todoItems, nil
+}
+
+// attachTags loads the tags of items in one query. Items without tags get an
+// empty, non-nil slice.
+func (r *todoItemRepository) attachTags(ctx context.Context, items []*entity.TodoItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	var rows []entity.TodoItemTag
+	err := r.db.WithContext(ctx).Where("item_id IN ?", ids).Order("tag").Find(&rows).Error
+	if err != nil {
+		return fmt.Errorf("failed to get todo item tags: %w", err)
+	}
+	byItem := make(map[string][]string, len(items))
+	for _, row := range rows {
+		byItem[row.ItemID] = append(byItem[row.ItemID], row.Tag)
+	}
+	for _, item := range items {
+		item.Tags = byItem[item.ID]
+		if item.Tags == nil {
+			item.Tags = []string{}
+		}
+	}
+	return nil
+}
+
+func itemPointers(items []entity.TodoItem) []*entity.TodoItem {
+	ptrs := make([]*entity.TodoItem, len(items))
+	for i := range items {
+		ptrs[i] = &items[i]
+	}
+	return ptrs
+}
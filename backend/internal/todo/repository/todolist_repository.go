@@ -7,16 +7,22 @@ import (
 	"messenger/backend/internal/todo/entity"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TodoListRepository interface {
 	CreateTodoList(ctx context.Context, todoList *entity.TodoList) error
 	GetTodoListByID(ctx context.Context, id string) (*entity.TodoList, error)
+	GetTodoListByIDForUpdate(ctx context.Context, id string) (*entity.TodoList, error)
 	GetTodoListsByOwnerID(ctx context.Context, ownerID string) ([]entity.TodoList, error)
 	GetTodoListsByUserID(ctx context.Context, userID string) ([]entity.TodoList, error)
+	GetTodoListsPageByUserID(ctx context.Context, userID string, after *entity.ListCursor, limit int) ([]entity.TodoList, error)
+	CountTodoListsByUserID(ctx context.Context, userID string) (int64, error)
 	UpdateTodoList(ctx context.Context, todoList *entity.TodoList) error
 	DeleteTodoList(ctx context.Context, id string) error
 	GetCollaboratorDetails(ctx context.Context, listID string) ([]entity.TodoListCollaboratorDetail, error)
+	GetListStats(ctx context.Context, listID string) (entity.TodoListStats, error)
+	WithTx(tx *gorm.DB) TodoListRepository
 }
 
 type todoListRepository struct {
@@ -27,6 +33,10 @@ func NewTodoListRepository(db *gorm.DB) TodoListRepository {
 	return &todoListRepository{db: db}
 }
 
+func (r *todoListRepository) WithTx(tx *gorm.DB) TodoListRepository {
+	return &todoListRepository{db: tx}
+}
+
 func (r *todoListRepository) GetTodoListsByUserID(ctx context.Context, userID string) ([]entity.TodoList, error) {
 	var todoLists []entity.TodoList
 	err := r.db.WithContext(ctx).
@@ -41,6 +51,42 @@ func (r *todoListRepository) GetTodoListsByUserID(ctx context.Context, userID st
 	return todoLists, nil
 }
 
+// GetTodoListsPageByUserID returns up to limit of the lists userID owns or
+// collaborates on, newest first, starting after the cursor when one is given.
+// Seeking past (created_at, id) rather than skipping rows keeps pages stable
+// while lists are created or deleted.
+func (r *todoListRepository) GetTodoListsPageByUserID(ctx context.Context, userID string, after *entity.ListCursor, limit int) ([]entity.TodoList, error) {
+	var todoLists []entity.TodoList
+	db := r.db.WithContext(ctx).
+		Joins("LEFT JOIN todo_list_collaborators tlc ON todo_lists.id = tlc.todo_list_id").
+		Where("todo_lists.owner_id = ? OR tlc.collaborator_id = ?", userID, userID)
+	if after != nil {
+		db = db.Where("todo_lists.created_at < ? OR (todo_lists.created_at = ? AND todo_lists.id < ?)", after.CreatedAt, after.CreatedAt, after.ID)
+	}
+	err := db.Group("todo_lists.id").
+		Order("todo_lists.created_at DESC, todo_lists.id DESC").
+		Limit(limit).
+		Find(&todoLists).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get page of todo lists by user ID: %w", err)
+	}
+	return todoLists, nil
+}
+
+// CountTodoListsByUserID counts the lists userID owns or collaborates on.
+func (r *todoListRepository) CountTodoListsByUserID(ctx context.Context, userID string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&entity.TodoList{}).
+		Joins("LEFT JOIN todo_list_collaborators tlc ON todo_lists.id = tlc.todo_list_id").
+		Where("todo_lists.owner_id = ? OR tlc.collaborator_id = ?", userID, userID).
+		Distinct("todo_lists.id").
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count todo lists by user ID: %w", err)
+	}
+	return count, nil
+}
+
 func (r *todoListRepository) CreateTodoList(ctx context.Context, todoList *entity.TodoList) error {
 	err := r.db.WithContext(ctx).Create(todoList).Error
 	if err != nil {
@@ -61,6 +107,23 @@ func (r *todoListRepository) GetTodoListByID(ctx context.Context, id string) (*e
 	return &todoList, nil
 }
 
+// GetTodoListByIDForUpdate reads the list and locks its row until the
+// surrounding transaction ends, so it cannot be deleted or changed by a
+// concurrent request in between.
+func (r *todoListRepository) GetTodoListByIDForUpdate(ctx context.Context, id string) (*entity.TodoList, error) {
+	var todoList entity.TodoList
+	err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&todoList, "id = ?", id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, entity.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get todo list by ID: %w", err)
+	}
+	return &todoList, nil
+}
+
 func (r *todoListRepository) GetTodoListsByOwnerID(ctx context.Context, ownerID string) ([]entity.TodoList, error) {
 	var todoLists []entity.TodoList
 	err := r.db.WithContext(ctx).Where("owner_id = ?", ownerID).Find(&todoLists).Error
@@ -99,3 +162,18 @@ func (r *todoListRepository) GetCollaboratorDetails(ctx context.Context, listID
 	}
 	return collaborators, nil
 }
+
+// GetListStats counts the list's items and how many of them are completed in
+// one aggregate query. Trashed items are not counted.
+func (r *todoListRepository) GetListStats(ctx context.Context, listID string) (entity.TodoListStats, error) {
+	var stats entity.TodoListStats
+	err := r.db.WithContext(ctx).
+		Model(&entity.TodoItem{}).
+		Select("COUNT(*) AS total, COUNT(CASE WHEN completed THEN 1 END) AS completed").
+		Where("list_id = ?", listID).
+		Scan(&stats).Error
+	if err != nil {
+		return entity.TodoListStats{}, fmt.Errorf("failed to get stats for todo list %s: %w", listID, err)
+	}
+	return stats, nil
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"messenger/backend/internal/todo/entity"
+
+	"gorm.io/gorm"
+)
+
+func TestTodoListRepositoryGetCollaboratorDetailsJoinsUsers(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	collaborators := NewTodoListCollaboratorRepository(db)
+	if err := collaborators.AddCollaborator(ctx, &entity.TodoListCollaborator{TodoListID: testListID, CollaboratorID: testCollaboratorID}); err != nil {
+		t.Fatalf("AddCollaborator() error = %v", err)
+	}
+
+	details, err := NewTodoListRepository(db).GetCollaboratorDetails(ctx, testListID)
+	if err != nil {
+		t.Fatalf("GetCollaboratorDetails() error = %v", err)
+	}
+	if len(details) != 1 {
+		t.Fatalf("GetCollaboratorDetails() = %+v, want one collaborator", details)
+	}
+	got := details[0]
+	if got.TodoListID != testListID || got.CollaboratorID != testCollaboratorID {
+		t.Fatalf("membership = %+v, want %s in %s", got.TodoListCollaborator, testCollaboratorID, testListID)
+	}
+	if got.User.ID.String() != testCollaboratorID || got.User.Username != "collab" || got.User.MatrixID != "@collab:example.org" {
+		t.Fatalf("user = %+v, want the collaborator's profile", got.User)
+	}
+}
+
+func TestTodoListRepositoryGetListStats(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	if err := db.Exec(`CREATE TABLE todo_items (
+		id TEXT PRIMARY KEY,
+		list_id TEXT NOT NULL,
+		position TEXT NOT NULL,
+		title TEXT NOT NULL,
+		description TEXT NOT NULL,
+		deadline DATETIME,
+		completed BOOLEAN,
+		priority TEXT NOT NULL DEFAULT 'medium',
+		version INTEGER NOT NULL DEFAULT 1,
+		created_by TEXT,
+		created_at DATETIME,
+		updated_at DATETIME,
+		deleted_at DATETIME
+	)`).Error; err != nil {
+		t.Fatalf("create todo_items error = %v", err)
+	}
+
+	otherListID := "22222222-2222-2222-2222-222222222222"
+	items := []entity.TodoItem{
+		{ListID: testListID, Completed: true},
+		{ListID: testListID, Completed: true},
+		{ListID: testListID, Completed: false},
+		{ListID: testListID, Completed: false},
+		{ListID: testListID, Completed: false},
+		// Trashed and other lists' items are not counted.
+		{ListID: testListID, Completed: true, DeletedAt: gorm.DeletedAt{Time: time.Now(), Valid: true}},
+		{ListID: otherListID, Completed: true},
+	}
+	for i := range items {
+		items[i].ID = fmt.Sprintf("item-%d", i)
+		items[i].Position = fmt.Sprintf("a%d", i)
+		items[i].Priority = entity.PriorityMedium
+	}
+	if err := db.Create(&items).Error; err != nil {
+		t.Fatalf("Create(items) error = %v", err)
+	}
+
+	repo := NewTodoListRepository(db)
+	stats, err := repo.GetListStats(ctx, testListID)
+	if err != nil {
+		t.Fatalf("GetListStats() error = %v", err)
+	}
+	if want := (entity.TodoListStats{Total: 5, Completed: 2}); stats != want {
+		t.Fatalf("GetListStats() = %+v, want %+v", stats, want)
+	}
+
+	empty, err := repo.GetListStats(ctx, "33333333-3333-3333-3333-333333333333")
+	if err != nil || empty != (entity.TodoListStats{}) {
+		t.Fatalf("GetListStats(empty list) = %+v, %v, want zero counts", empty, err)
+	}
+}
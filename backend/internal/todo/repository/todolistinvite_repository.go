@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"messenger/backend/internal/todo/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TodoListInviteRepository defines the interface for todo list invite data operations.
+type TodoListInviteRepository interface {
+	CreateInvite(ctx context.Context, invite *entity.TodoListInvite) error
+	GetInviteByTokenHashForUpdate(ctx context.Context, tokenHash string) (*entity.TodoListInvite, error)
+	MarkInviteUsed(ctx context.Context, tokenHash, userID string, usedAt time.Time) error
+	WithTx(tx *gorm.DB) TodoListInviteRepository
+}
+
+type todoListInviteRepository struct {
+	db *gorm.DB
+}
+
+func NewTodoListInviteRepository(db *gorm.DB) TodoListInviteRepository {
+	return &todoListInviteRepository{db: db}
+}
+
+func (r *todoListInviteRepository) WithTx(tx *gorm.DB) TodoListInviteRepository {
+	return &todoListInviteRepository{db: tx}
+}
+
+func (r *todoListInviteRepository) CreateInvite(ctx context.Context, invite *entity.TodoListInvite) error {
+	err := r.db.WithContext(ctx).Create(invite).Error
+	if err != nil {
+		return fmt.Errorf("failed to create todo list invite: %w", err)
+	}
+	return nil
+}
+
+// GetInviteByTokenHashForUpdate reads the invite and locks its row until the
+// surrounding transaction ends, so two users cannot both redeem a single-use
+// invite.
+func (r *todoListInviteRepository) GetInviteByTokenHashForUpdate(ctx context.Context, tokenHash string) (*entity.TodoListInvite, error) {
+	var invite entity.TodoListInvite
+	err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&invite, "token_hash = ?", tokenHash).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get todo list invite: %w", err)
+	}
+	return &invite, nil
+}
+
+func (r *todoListInviteRepository) MarkInviteUsed(ctx context.Context, tokenHash, userID string, usedAt time.Time) error {
+	err := r.db.WithContext(ctx).
+		Model(&entity.TodoListInvite{}).
+		Where("token_hash = ?", tokenHash).
+		Updates(map[string]interface{}{"used_at": usedAt, "used_by": userID}).Error
+	if err != nil {
+		return fmt.Errorf("failed to mark todo list invite used: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package todohandler
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"messenger/backend/internal/todo/entity"
+	"messenger/backend/pkg/middleware"
+
+	ical "github.com/emersion/go-ical"
+)
+
+// calendarUIDSuffix makes feed event UIDs globally unique while keeping them
+// a pure function of the item ID, so a refreshed feed updates events in place.
+const calendarUIDSuffix = "@todo.messie"
+
+// GetTodoItemsCalendar handles GET /todo-items.ics, the calendar feed of the
+// caller's todo deadlines. The caller is authenticated either by bearer token
+// or by the feed token in the URL (see middleware.FeedTokenAuth).
+func (h *TodoHandler) GetTodoItemsCalendar(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.ContextKeyUserID).(string)
+	if !ok || userID == "" {
+		sendErrorResponse(w, http.StatusUnauthorized, "User ID not found in context")
+		return
+	}
+
+	items, listTitles, err := h.Usecases.GetDeadlineCalendar(r.Context(), userID)
+	if err != nil {
+		sendErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get todo deadlines: %v", err))
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := ical.NewEncoder(&buf).Encode(deadlineCalendar(items, listTitles)); err != nil {
+		sendErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to encode calendar: %v", err))
+		return
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// deadlineCalendar builds a VCALENDAR with one VEVENT at the deadline of each
+// item. Completed items stay in the feed, marked with a check in the summary,
+// so past deadlines do not silently disappear from the calendar.
+func deadlineCalendar(items []entity.TodoItem, listTitles map[string]string) *ical.Calendar {
+	cal := ical.NewCalendar()
+	cal.Props.SetText(ical.PropVersion, "2.0")
+	cal.Props.SetText(ical.PropProductID, "-//Messie//Todo Deadlines//EN")
+	cal.Props.SetText(ical.PropName, "Todo deadlines")
+
+	for _, item := range items {
+		if item.Deadline == nil {
+			continue
+		}
+		summary := item.Title
+		if summary == "" {
+			summary = item.Description
+		}
+		if item.Completed {
+			summary = "✓ " + summary
+		}
+
+		event := ical.NewEvent()
+		event.Props.SetText(ical.PropUID, item.ID+calendarUIDSuffix)
+		event.Props.SetDateTime(ical.PropDateTimeStamp, item.UpdatedAt.UTC())
+		event.Props.SetDateTime(ical.PropLastModified, item.UpdatedAt.UTC())
+		event.Props.SetDateTime(ical.PropDateTimeStart, item.Deadline.UTC())
+		event.Props.SetText(ical.PropSummary, summary)
+		if item.Title != "" && item.Description != "" {
+			event.Props.SetText(ical.PropDescription, item.Description)
+		}
+		if title := listTitles[item.ListID]; title != "" {
+			event.Props.SetText(ical.PropCategories, title)
+		}
+		if item.Version > 0 {
+			sequence := ical.NewProp(ical.PropSequence)
+			sequence.Value = strconv.FormatInt(item.Version-1, 10)
+			event.Props.Set(sequence)
+		}
+		if priority, ok := calendarPriority[item.Priority]; ok {
+			prop := ical.NewProp(ical.PropPriority)
+			prop.Value = priority
+			event.Props.Set(prop)
+		}
+		cal.Children = append(cal.Children, event.Component)
+	}
+	return cal
+}
+
+// calendarPriority maps item priorities onto the RFC 5545 PRIORITY scale,
+// where 1 is highest and 9 lowest.
+var calendarPriority = map[string]string{
+	"high":   "1",
+	"medium": "5",
+	"low":    "9",
+}
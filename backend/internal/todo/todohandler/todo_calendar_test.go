@@ -0,0 +1,74 @@
+package todohandler
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"messenger/backend/internal/todo/entity"
+
+	ical "github.com/emersion/go-ical"
+)
+
+func TestDeadlineCalendar(t *testing.T) {
+	deadline := time.Date(2025, 3, 1, 9, 30, 0, 0, time.FixedZone("CET", 3600))
+	updated := time.Date(2025, 2, 20, 12, 0, 0, 0, time.UTC)
+	items := []entity.TodoItem{
+		{ID: "item-1", ListID: "list-1", Title: "File taxes", Description: "forms, receipts", Deadline: &deadline, Priority: "high", Version: 3, UpdatedAt: updated},
+		{ID: "item-2", ListID: "list-1", Description: "untitled", Deadline: &deadline, Completed: true, Version: 1, UpdatedAt: updated},
+		{ID: "item-3", ListID: "list-1", Title: "No deadline", Version: 1, UpdatedAt: updated},
+	}
+
+	var buf bytes.Buffer
+	if err := ical.NewEncoder(&buf).Encode(deadlineCalendar(items, map[string]string{"list-1": "Home, admin"})); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	cal, err := ical.NewDecoder(&buf).Decode()
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	events := cal.Events()
+	if len(events) != 2 {
+		t.Fatalf("events = %d, want 2 (items without a deadline are skipped)", len(events))
+	}
+
+	text := func(event ical.Event, name string) string {
+		t.Helper()
+		prop := event.Props.Get(name)
+		if prop == nil {
+			return ""
+		}
+		value, err := prop.Text()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		return value
+	}
+
+	first := events[0]
+	if uid := text(first, ical.PropUID); uid != "item-1"+calendarUIDSuffix {
+		t.Errorf("UID = %q, want it derived from the item ID", uid)
+	}
+	if start, err := first.DateTimeStart(nil); err != nil || !start.Equal(deadline) {
+		t.Errorf("DTSTART = %v, %v; want %v", start, err, deadline)
+	}
+	if got := text(first, ical.PropSummary); got != "File taxes" {
+		t.Errorf("SUMMARY = %q", got)
+	}
+	if got := text(first, ical.PropDescription); got != "forms, receipts" {
+		t.Errorf("DESCRIPTION = %q", got)
+	}
+	if got := text(first, ical.PropCategories); got != "Home, admin" {
+		t.Errorf("CATEGORIES = %q, want the list title", got)
+	}
+	if got := first.Props.Get(ical.PropSequence).Value; got != "2" {
+		t.Errorf("SEQUENCE = %q, want 2 for version 3", got)
+	}
+	if got := first.Props.Get(ical.PropPriority).Value; got != "1" {
+		t.Errorf("PRIORITY = %q, want 1 for high", got)
+	}
+
+	if got := text(events[1], ical.PropSummary); got != "✓ untitled" {
+		t.Errorf("completed item SUMMARY = %q, want the description marked done", got)
+	}
+}
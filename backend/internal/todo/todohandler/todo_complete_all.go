@@ -0,0 +1,50 @@
+package todohandler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"messenger/backend/api/generated"
+	"messenger/backend/internal/todo/entity"
+	"messenger/backend/pkg/middleware"
+
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// CompleteAllTodoItems handles POST /todolists/{listId}/items/complete-all.
+func (h *TodoHandler) CompleteAllTodoItems(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID, params generated.CompleteAllTodoItemsParams) {
+	h.setAllTodoItemsCompleted(w, r, listId, true, params.Tag)
+}
+
+// UncompleteAllTodoItems handles POST /todolists/{listId}/items/uncomplete-all.
+func (h *TodoHandler) UncompleteAllTodoItems(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID, params generated.UncompleteAllTodoItemsParams) {
+	h.setAllTodoItemsCompleted(w, r, listId, false, params.Tag)
+}
+
+func (h *TodoHandler) setAllTodoItemsCompleted(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID, completed bool, tag *string) {
+	// User ID is expected to be in the context after authentication middleware
+	userID, ok := r.Context().Value(middleware.ContextKeyUserID).(string)
+	if !ok || userID == "" {
+		sendErrorResponse(w, http.StatusUnauthorized, "User ID not found in context")
+		return
+	}
+
+	var filter string
+	if tag != nil {
+		filter = *tag
+	}
+	updated, err := h.Usecases.SetAllTodoItemsCompleted(r.Context(), listId.String(), userID, completed, filter)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			sendErrorResponse(w, http.StatusNotFound, fmt.Sprintf("Todo list not found: %v", err))
+		} else if errors.Is(err, entity.ErrForbidden) {
+			sendErrorResponse(w, http.StatusForbidden, fmt.Sprintf("Forbidden: %v", err))
+		} else {
+			sendErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to update todo items: %v", err))
+		}
+		return
+	}
+
+	sendJSONResponse(w, http.StatusOK, generated.TodoItemsUpdated{Updated: updated})
+}
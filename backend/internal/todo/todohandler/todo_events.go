@@ -0,0 +1,140 @@
+package todohandler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"messenger/backend/api/generated"
+	"messenger/backend/internal/todo/entity"
+	"messenger/backend/internal/todo/usecase"
+	"messenger/backend/pkg/middleware"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+const (
+	eventsPingInterval = 30 * time.Second
+	eventsPongWait     = 2 * eventsPingInterval
+	eventsWriteWait    = 10 * time.Second
+)
+
+var eventsUpgrader = websocket.Upgrader{
+	// Echoed back when the client authenticated through the subprotocol.
+	Subprotocols: []string{"bearer"},
+	// The socket is authorized by a bearer token, never by cookies, so a page
+	// on another origin cannot open one on a user's behalf.
+	CheckOrigin: func(*http.Request) bool { return true },
+}
+
+// GetTodoListEvents streams changes to a todo list over a WebSocket until the
+// client disconnects, the list is deleted or the caller loses access to it.
+func (h *TodoHandler) GetTodoListEvents(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID) {
+	// User ID is expected to be in the context after authentication middleware
+	userID, ok := r.Context().Value(middleware.ContextKeyUserID).(string)
+	if !ok || userID == "" {
+		sendErrorResponse(w, http.StatusUnauthorized, "User ID not found in context")
+		return
+	}
+	if !websocket.IsWebSocketUpgrade(r) {
+		sendErrorResponse(w, http.StatusBadRequest, "Expected a WebSocket upgrade request")
+		return
+	}
+
+	listID := listId.String()
+	events, unsubscribe, err := h.Usecases.SubscribeTodoListEvents(r.Context(), listID, userID)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			sendErrorResponse(w, http.StatusNotFound, fmt.Sprintf("Todo list not found: %v", err))
+		} else if errors.Is(err, entity.ErrForbidden) {
+			sendErrorResponse(w, http.StatusForbidden, fmt.Sprintf("Forbidden: %v", err))
+		} else {
+			sendErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to subscribe to todo list events: %v", err))
+		}
+		return
+	}
+	defer unsubscribe()
+
+	conn, err := eventsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already answered the request.
+		return
+	}
+	defer conn.Close()
+
+	// Clients never send anything meaningful; reading only keeps pongs flowing
+	// and tells us when the client goes away.
+	disconnected := make(chan struct{})
+	conn.SetReadLimit(512)
+	conn.SetReadDeadline(time.Now().Add(eventsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(eventsPongWait))
+	})
+	go func() {
+		defer close(disconnected)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(eventsPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-disconnected:
+			return
+		case ev, ok := <-events:
+			if !ok {
+				closeEvents(conn, websocket.CloseTryAgainLater, "too far behind; reconnect and refetch")
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(eventsWriteWait))
+			if err := conn.WriteJSON(toTodoListEvent(ev)); err != nil {
+				return
+			}
+			if ev.Type == usecase.EventListDeleted {
+				closeEvents(conn, websocket.CloseNormalClosure, "todo list deleted")
+				return
+			}
+		case <-ticker.C:
+			// Re-check access so a removed collaborator stops receiving changes.
+			_, err := h.Usecases.GetTodoListByID(r.Context(), listID, userID)
+			if errors.Is(err, entity.ErrForbidden) || errors.Is(err, entity.ErrNotFound) {
+				closeEvents(conn, websocket.ClosePolicyViolation, "access to the todo list was revoked")
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(eventsWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func closeEvents(conn *websocket.Conn, code int, reason string) {
+	msg := websocket.FormatCloseMessage(code, reason)
+	conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(eventsWriteWait))
+}
+
+func toTodoListEvent(ev usecase.Event) generated.TodoListEvent {
+	msg := generated.TodoListEvent{
+		Type:    generated.TodoListEventType(ev.Type),
+		ListId:  openapi_types.UUID(uuid.MustParse(ev.ListID)),
+		ActorId: openapi_types.UUID(uuid.MustParse(ev.ActorID)),
+	}
+	if ev.ItemID != "" {
+		itemID := openapi_types.UUID(uuid.MustParse(ev.ItemID))
+		msg.ItemId = &itemID
+	}
+	if item := ev.Item; item != nil {
+		res := toTodoItemResponse(item)
+		msg.Item = &res
+	}
+	return msg
+}
@@ -0,0 +1,126 @@
+package todohandler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"messenger/backend/api/generated"
+	"messenger/backend/internal/todo/entity"
+	"messenger/backend/pkg/middleware"
+
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// exportCSVHeader names the columns written by writeItemsCSV.
+var exportCSVHeader = []string{"title", "description", "completed", "due_date", "position", "priority", "tags"}
+
+// ExportTodoList handles GET /todolists/{listId}/export, sending the list as
+// a CSV of its items or as a JSON document of the list and its items. Either
+// way the response is a download named after the list.
+func (h *TodoHandler) ExportTodoList(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID, params generated.ExportTodoListParams) {
+	// User ID is expected to be in the context after authentication middleware
+	userID, ok := r.Context().Value(middleware.ContextKeyUserID).(string)
+	if !ok || userID == "" {
+		sendErrorResponse(w, http.StatusUnauthorized, "User ID not found in context")
+		return
+	}
+
+	format := generated.Csv
+	if params.Format != nil {
+		format = *params.Format
+	}
+
+	todoList, items, err := h.Usecases.ExportTodoList(r.Context(), listId.String(), userID)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			sendErrorResponse(w, http.StatusNotFound, fmt.Sprintf("Todo list not found: %v", err))
+		} else if errors.Is(err, entity.ErrForbidden) {
+			sendErrorResponse(w, http.StatusForbidden, fmt.Sprintf("Forbidden: %v", err))
+		} else {
+			sendErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to export todo list: %v", err))
+		}
+		return
+	}
+
+	switch format {
+	case generated.Json:
+		w.Header().Set("Content-Type", "application/json")
+		setAttachment(w, exportFilename(todoList.Title, "json"))
+		if err := json.NewEncoder(w).Encode(toTodoListWithItems(todoList, items)); err != nil {
+			middleware.Logf(r.Context(), "Failed to write JSON export of list %s: %v", todoList.ID, err)
+		}
+	default:
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		setAttachment(w, exportFilename(todoList.Title, "csv"))
+		if err := writeItemsCSV(w, items); err != nil {
+			middleware.Logf(r.Context(), "Failed to write CSV export of list %s: %v", todoList.ID, err)
+		}
+	}
+}
+
+// writeItemsCSV streams items as CSV rows, one per item, flushing every row
+// straight to w rather than building the document first. Headers have been
+// sent by the time a row fails, so errors can only be logged.
+func writeItemsCSV(w http.ResponseWriter, items []entity.TodoItem) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(exportCSVHeader); err != nil {
+		return err
+	}
+	for _, item := range items {
+		dueDate := ""
+		if item.Deadline != nil {
+			dueDate = item.Deadline.UTC().Format(time.RFC3339)
+		}
+		row := []string{
+			csvSafe(item.Title),
+			csvSafe(item.Description),
+			strconv.FormatBool(item.Completed),
+			dueDate,
+			item.Position,
+			item.Priority,
+			csvSafe(strings.Join(item.Tags, ";")),
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvSafe defuses user text that spreadsheets would evaluate as a formula
+// by prefixing it with a quote.
+func csvSafe(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// exportFilename derives a download name from the list title, keeping letters,
+// digits, '-' and '_' and replacing everything else with '-'.
+func exportFilename(title, ext string) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+			return r
+		}
+		return '-'
+	}, strings.TrimSpace(title))
+	name = strings.Trim(name, "-")
+	if name == "" {
+		name = "todo-list"
+	}
+	return name + "." + ext
+}
+
+func setAttachment(w http.ResponseWriter, filename string) {
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+}
@@ -0,0 +1,63 @@
+package todohandler
+
+import (
+	"encoding/csv"
+	"mime"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"messenger/backend/internal/todo/entity"
+)
+
+func TestWriteItemsCSV(t *testing.T) {
+	deadline := time.Date(2025, 3, 1, 9, 30, 0, 0, time.FixedZone("CET", 3600))
+	items := []entity.TodoItem{
+		{Title: "Buy milk", Description: "two, \"fresh\"\nlitres", Completed: true, Deadline: &deadline, Position: "a0", Priority: "high", Tags: []string{"home", "shop"}},
+		{Title: "=HYPERLINK(\"x\")", Position: "a1", Priority: "low"},
+	}
+
+	rec := httptest.NewRecorder()
+	if err := writeItemsCSV(rec, items); err != nil {
+		t.Fatalf("writeItemsCSV() error = %v", err)
+	}
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV back: %v", err)
+	}
+	want := [][]string{
+		exportCSVHeader,
+		{"Buy milk", "two, \"fresh\"\nlitres", "true", "2025-03-01T08:30:00Z", "a0", "high", "home;shop"},
+		{"'=HYPERLINK(\"x\")", "", "false", "", "a1", "low", ""},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("rows = %q, want %q", rows, want)
+	}
+	for i := range want {
+		for j := range want[i] {
+			if rows[i][j] != want[i][j] {
+				t.Errorf("row %d column %s = %q, want %q", i, exportCSVHeader[j], rows[i][j], want[i][j])
+			}
+		}
+	}
+}
+
+func TestExportAttachmentHeader(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Groceries", "Groceries.csv"},
+		{" Trip: Köln / Bonn ", "Trip--Köln---Bonn.csv"},
+		{"\"; rm -rf", "rm--rf.csv"},
+		{"???", "todo-list.csv"},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		setAttachment(rec, exportFilename(tt.title, "csv"))
+		disposition, params, err := mime.ParseMediaType(rec.Header().Get("Content-Disposition"))
+		if err != nil || disposition != "attachment" || params["filename"] != tt.want {
+			t.Errorf("title %q: Content-Disposition = %q (%v), want attachment filename %q", tt.title, rec.Header().Get("Content-Disposition"), err, tt.want)
+		}
+	}
+}
@@ -1,7 +1,10 @@
 package todohandler
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -9,12 +12,75 @@ import (
 	"messenger/backend/api/generated"
 	"messenger/backend/internal/todo/entity"
 	"messenger/backend/internal/todo/usecase"
+	"messenger/backend/pkg/apierror"
+	"messenger/backend/pkg/httpjson"
 	"messenger/backend/pkg/middleware"
 
 	"github.com/google/uuid"
 	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
+// toTodoListResponse converts a todo list entity into its API representation.
+func toTodoListResponse(list *entity.TodoList) generated.TodoList {
+	return generated.TodoList{
+		Id:          openapi_types.UUID(uuid.MustParse(list.ID)),
+		OwnerId:     openapi_types.UUID(uuid.MustParse(list.OwnerID)),
+		Title:       list.Title,
+		Description: list.Description,
+		CreatedAt:   &list.CreatedAt,
+		UpdatedAt:   &list.UpdatedAt,
+	}
+}
+
+// toTodoItemResponse converts a todo item entity into its API representation.
+func toTodoItemResponse(item *entity.TodoItem) generated.TodoItem {
+	res := generated.TodoItem{
+		Id:          openapi_types.UUID(uuid.MustParse(item.ID)),
+		ListId:      openapi_types.UUID(uuid.MustParse(item.ListID)),
+		Position:    item.Position,
+		Title:       item.Title,
+		Description: item.Description,
+		Completed:   item.Completed,
+		DueDate:     item.Deadline,
+		CreatedAt:   &item.CreatedAt,
+		UpdatedAt:   &item.UpdatedAt,
+		Version:     item.Version,
+	}
+	if item.CreatedBy != nil {
+		createdBy := openapi_types.UUID(uuid.MustParse(*item.CreatedBy))
+		res.CreatedBy = &createdBy
+	}
+	if item.CreatedByUsername != "" {
+		res.CreatedByUsername = &item.CreatedByUsername
+	}
+	if item.Tags != nil {
+		res.Tags = &item.Tags
+	}
+	if item.Priority != "" {
+		priority := generated.TodoItemPriority(item.Priority)
+		res.Priority = &priority
+	}
+	return res
+}
+
+// tagsFromRequest returns the tags of a create or update body, or nil when
+// the field was omitted.
+func tagsFromRequest(tags *generated.TodoItemTags) []string {
+	if tags == nil {
+		return nil
+	}
+	return *tags
+}
+
+// priorityFromRequest returns the priority of a create or update body, or ""
+// when the field was omitted.
+func priorityFromRequest(priority *generated.TodoItemPriority) string {
+	if priority == nil {
+		return ""
+	}
+	return string(*priority)
+}
+
 type TodoHandler struct {
 	Usecases *usecase.Usecase
 }
@@ -33,9 +99,49 @@ func sendJSONResponse(w http.ResponseWriter, statusCode int, payload interface{}
 	}
 }
 
+// sendCacheableJSONResponse writes payload with an ETag derived from its
+// encoded body. When the request's If-None-Match already names that ETag the
+// body is omitted and 304 Not Modified is returned instead.
+func sendCacheableJSONResponse(w http.ResponseWriter, r *http.Request, statusCode int, payload interface{}) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		sendErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to encode response: %v", err))
+		return
+	}
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(body)
+	w.Write([]byte("\n"))
+}
+
+// etagMatches reports whether an If-None-Match header value matches etag,
+// using the weak comparison RFC 9110 prescribes for If-None-Match.
+func etagMatches(ifNoneMatch string, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
 // Helper function to send error responses
 func sendErrorResponse(w http.ResponseWriter, statusCode int, message string) {
-	sendJSONResponse(w, statusCode, map[string]string{"error": message})
+	apierror.Write(w, statusCode, message)
 }
 
 func (h *TodoHandler) CreateTodoList(w http.ResponseWriter, r *http.Request) {
@@ -46,8 +152,8 @@ func (h *TodoHandler) CreateTodoList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var newTodoList generated.NewTodoList
-	if err := json.NewDecoder(r.Body).Decode(&newTodoList); err != nil {
+	newTodoList, err := httpjson.Decode[generated.NewTodoList](r)
+	if err != nil {
 		sendErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
 		return
 	}
@@ -81,11 +187,11 @@ func (h *TodoHandler) GetTodoListById(w http.ResponseWriter, r *http.Request, li
 		return
 	}
 
-	todoList, err := h.Usecases.GetTodoListByID(r.Context(), listId.String(), userID)
+	todoList, stats, err := h.Usecases.GetTodoListWithStats(r.Context(), listId.String(), userID)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
+		if errors.Is(err, entity.ErrNotFound) {
 			sendErrorResponse(w, http.StatusNotFound, fmt.Sprintf("Todo list not found: %v", err))
-		} else if strings.Contains(err.Error(), "not authorized") {
+		} else if errors.Is(err, entity.ErrForbidden) {
 			sendErrorResponse(w, http.StatusForbidden, fmt.Sprintf("Forbidden: %v", err))
 		} else {
 			sendErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get todo list: %v", err))
@@ -94,15 +200,17 @@ func (h *TodoHandler) GetTodoListById(w http.ResponseWriter, r *http.Request, li
 	}
 
 	responseTodoList := generated.TodoList{
-		Id:          openapi_types.UUID(uuid.MustParse(todoList.ID)),
-		OwnerId:     openapi_types.UUID(uuid.MustParse(todoList.OwnerID)),
-		Title:       todoList.Title,
-		Description: todoList.Description,
-		CreatedAt:   &todoList.CreatedAt,
-		UpdatedAt:   &todoList.UpdatedAt,
+		Id:             openapi_types.UUID(uuid.MustParse(todoList.ID)),
+		OwnerId:        openapi_types.UUID(uuid.MustParse(todoList.OwnerID)),
+		Title:          todoList.Title,
+		Description:    todoList.Description,
+		CreatedAt:      &todoList.CreatedAt,
+		UpdatedAt:      &todoList.UpdatedAt,
+		ItemCount:      &stats.Total,
+		CompletedCount: &stats.Completed,
 	}
 
-	sendJSONResponse(w, http.StatusOK, responseTodoList)
+	sendCacheableJSONResponse(w, r, http.StatusOK, responseTodoList)
 }
 
 func (h *TodoHandler) GetTodoListsByUserId(w http.ResponseWriter, r *http.Request, params generated.GetTodoListsByUserIdParams) {
@@ -115,7 +223,11 @@ func (h *TodoHandler) GetTodoListsByUserId(w http.ResponseWriter, r *http.Reques
 
 	ownerID := params.UserId.String()
 	if ownerID != userID {
-		sendErrorResponse(w, http.StatusForbidden, "Forbidden: Cannot view todo lists of another user directly. Use collaborators endpoint for shared lists.")
+		sendErrorResponse(w, http.StatusForbidden, "Forbidden: Cannot view todo lists of another user directly. Use /todolists/shared for lists shared with you.")
+		return
+	}
+	if params.Limit != nil || params.After != nil || paginated(params.Paginated) {
+		h.getTodoListsPage(w, r, ownerID, params)
 		return
 	}
 
@@ -137,7 +249,38 @@ func (h *TodoHandler) GetTodoListsByUserId(w http.ResponseWriter, r *http.Reques
 		}
 	}
 
-	sendJSONResponse(w, http.StatusOK, responseTodoLists)
+	sendCacheableJSONResponse(w, r, http.StatusOK, responseTodoLists)
+}
+
+func (h *TodoHandler) GetSharedTodoLists(w http.ResponseWriter, r *http.Request) {
+	// User ID is expected to be in the context after authentication middleware
+	userID, ok := r.Context().Value(middleware.ContextKeyUserID).(string)
+	if !ok || userID == "" {
+		sendErrorResponse(w, http.StatusUnauthorized, "User ID not found in context")
+		return
+	}
+
+	sharedLists, err := h.Usecases.GetSharedTodoLists(r.Context(), userID)
+	if err != nil {
+		sendErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get shared todo lists: %v", err))
+		return
+	}
+
+	responseTodoLists := make([]generated.SharedTodoList, len(sharedLists))
+	for i, sl := range sharedLists {
+		responseTodoLists[i] = generated.SharedTodoList{
+			Id:          openapi_types.UUID(uuid.MustParse(sl.ID)),
+			OwnerId:     openapi_types.UUID(uuid.MustParse(sl.OwnerID)),
+			Title:       sl.Title,
+			Description: sl.Description,
+			CreatedAt:   &sl.CreatedAt,
+			UpdatedAt:   &sl.UpdatedAt,
+			Shared:      true,
+			SharedAt:    sl.SharedAt,
+		}
+	}
+
+	sendCacheableJSONResponse(w, r, http.StatusOK, responseTodoLists)
 }
 
 func (h *TodoHandler) UpdateTodoList(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID) {
@@ -148,8 +291,8 @@ func (h *TodoHandler) UpdateTodoList(w http.ResponseWriter, r *http.Request, lis
 		return
 	}
 
-	var updateTodoList generated.UpdateTodoList
-	if err := json.NewDecoder(r.Body).Decode(&updateTodoList); err != nil {
+	updateTodoList, err := httpjson.Decode[generated.UpdateTodoList](r)
+	if err != nil {
 		sendErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
 		return
 	}
@@ -159,9 +302,9 @@ func (h *TodoHandler) UpdateTodoList(w http.ResponseWriter, r *http.Request, lis
 
 	todoList, err := h.Usecases.UpdateTodoList(r.Context(), listId.String(), title, description, userID)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
+		if errors.Is(err, entity.ErrNotFound) {
 			sendErrorResponse(w, http.StatusNotFound, fmt.Sprintf("Todo list not found: %v", err))
-		} else if strings.Contains(err.Error(), "not authorized") {
+		} else if errors.Is(err, entity.ErrForbidden) {
 			sendErrorResponse(w, http.StatusForbidden, fmt.Sprintf("Forbidden: %v", err))
 		} else {
 			sendErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to update todo list: %v", err))
@@ -191,9 +334,9 @@ func (h *TodoHandler) DeleteTodoList(w http.ResponseWriter, r *http.Request, lis
 
 	err := h.Usecases.DeleteTodoList(r.Context(), listId.String(), userID)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
+		if errors.Is(err, entity.ErrNotFound) {
 			sendErrorResponse(w, http.StatusNotFound, fmt.Sprintf("Todo list not found: %v", err))
-		} else if strings.Contains(err.Error(), "not authorized") {
+		} else if errors.Is(err, entity.ErrForbidden) {
 			sendErrorResponse(w, http.StatusForbidden, fmt.Sprintf("Forbidden: %v", err))
 		} else {
 			sendErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to delete todo list: %v", err))
@@ -211,19 +354,19 @@ func (h *TodoHandler) AddCollaborator(w http.ResponseWriter, r *http.Request, li
 		return
 	}
 
-	var newCollaborator generated.NewCollaborator
-	if err := json.NewDecoder(r.Body).Decode(&newCollaborator); err != nil {
+	newCollaborator, err := httpjson.Decode[generated.NewCollaborator](r)
+	if err != nil {
 		sendErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
 		return
 	}
 
-	err := h.Usecases.AddCollaborator(r.Context(), listId.String(), newCollaborator.UserId.String(), userID)
+	err = h.Usecases.AddCollaborator(r.Context(), listId.String(), newCollaborator.UserId.String(), userID)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
+		if errors.Is(err, entity.ErrNotFound) {
 			sendErrorResponse(w, http.StatusNotFound, fmt.Sprintf("Todo list or user not found: %v", err))
-		} else if strings.Contains(err.Error(), "not authorized") {
+		} else if errors.Is(err, entity.ErrForbidden) {
 			sendErrorResponse(w, http.StatusForbidden, fmt.Sprintf("Forbidden: %v", err))
-		} else if strings.Contains(err.Error(), "already a collaborator") {
+		} else if errors.Is(err, entity.ErrConflict) {
 			sendErrorResponse(w, http.StatusConflict, fmt.Sprintf("Conflict: %v", err))
 		} else {
 			sendErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to add collaborator: %v", err))
@@ -233,6 +376,75 @@ func (h *TodoHandler) AddCollaborator(w http.ResponseWriter, r *http.Request, li
 	w.WriteHeader(http.StatusCreated)
 }
 
+func (h *TodoHandler) TransferTodoList(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID) {
+	// User ID is expected to be in the context after authentication middleware
+	userID, ok := r.Context().Value(middleware.ContextKeyUserID).(string)
+	if !ok || userID == "" {
+		sendErrorResponse(w, http.StatusUnauthorized, "User ID not found in context")
+		return
+	}
+
+	transfer, err := httpjson.Decode[generated.TransferTodoList](r)
+	if err != nil {
+		sendErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+		return
+	}
+	keepAsCollaborator := true
+	if transfer.KeepAsCollaborator != nil {
+		keepAsCollaborator = *transfer.KeepAsCollaborator
+	}
+
+	todoList, err := h.Usecases.TransferTodoList(r.Context(), listId.String(), transfer.NewOwnerId.String(), userID, keepAsCollaborator)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			sendErrorResponse(w, http.StatusNotFound, fmt.Sprintf("Todo list not found: %v", err))
+		} else if errors.Is(err, entity.ErrForbidden) {
+			sendErrorResponse(w, http.StatusForbidden, fmt.Sprintf("Forbidden: %v", err))
+		} else if errors.Is(err, entity.ErrInvalid) {
+			sendErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Invalid transfer: %v", err))
+		} else {
+			sendErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to transfer todo list: %v", err))
+		}
+		return
+	}
+
+	sendJSONResponse(w, http.StatusOK, toTodoListResponse(todoList))
+}
+
+// CloneTodoList handles POST /todolists/{listId}/clone, copying a list the
+// caller can read into a new list they own.
+func (h *TodoHandler) CloneTodoList(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID) {
+	// User ID is expected to be in the context after authentication middleware
+	userID, ok := r.Context().Value(middleware.ContextKeyUserID).(string)
+	if !ok || userID == "" {
+		sendErrorResponse(w, http.StatusUnauthorized, "User ID not found in context")
+		return
+	}
+
+	todoList, items, err := h.Usecases.CloneTodoList(r.Context(), listId.String(), userID)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			sendErrorResponse(w, http.StatusNotFound, fmt.Sprintf("Todo list not found: %v", err))
+		} else if errors.Is(err, entity.ErrForbidden) {
+			sendErrorResponse(w, http.StatusForbidden, fmt.Sprintf("Forbidden: %v", err))
+		} else {
+			sendErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to clone todo list: %v", err))
+		}
+		return
+	}
+
+	sendJSONResponse(w, http.StatusCreated, toTodoListWithItems(todoList, items))
+}
+
+// toTodoListWithItems converts a list and its items into one API document.
+func toTodoListWithItems(list *entity.TodoList, items []entity.TodoItem) generated.TodoListWithItems {
+	doc := generated.TodoListWithItems{List: toTodoListResponse(list), Items: make([]generated.TodoItem, len(items))}
+	for i := range items {
+		doc.Items[i] = toTodoItemResponse(&items[i])
+	}
+	return doc
+}
+
 func (h *TodoHandler) RemoveCollaborator(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID, userId openapi_types.UUID) {
 	// User ID is expected to be in the context after authentication middleware
 	userID, ok := r.Context().Value(middleware.ContextKeyUserID).(string)
@@ -243,10 +455,12 @@ func (h *TodoHandler) RemoveCollaborator(w http.ResponseWriter, r *http.Request,
 
 	err := h.Usecases.RemoveCollaborator(r.Context(), listId.String(), userId.String(), userID)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
+		if errors.Is(err, entity.ErrNotFound) {
 			sendErrorResponse(w, http.StatusNotFound, fmt.Sprintf("Todo list or collaborator not found: %v", err))
-		} else if strings.Contains(err.Error(), "not authorized") {
+		} else if errors.Is(err, entity.ErrForbidden) {
 			sendErrorResponse(w, http.StatusForbidden, fmt.Sprintf("Forbidden: %v", err))
+		} else if errors.Is(err, entity.ErrConflict) {
+			sendErrorResponse(w, http.StatusConflict, err.Error())
 		} else {
 			sendErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to remove collaborator: %v", err))
 		}
@@ -255,6 +469,31 @@ func (h *TodoHandler) RemoveCollaborator(w http.ResponseWriter, r *http.Request,
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// LeaveTodoList removes the caller from a list shared with them.
+func (h *TodoHandler) LeaveTodoList(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID) {
+	// User ID is expected to be in the context after authentication middleware
+	userID, ok := r.Context().Value(middleware.ContextKeyUserID).(string)
+	if !ok || userID == "" {
+		sendErrorResponse(w, http.StatusUnauthorized, "User ID not found in context")
+		return
+	}
+
+	err := h.Usecases.RemoveCollaborator(r.Context(), listId.String(), userID, userID)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			sendErrorResponse(w, http.StatusNotFound, fmt.Sprintf("Todo list not found: %v", err))
+		} else if errors.Is(err, entity.ErrForbidden) {
+			sendErrorResponse(w, http.StatusForbidden, fmt.Sprintf("Forbidden: %v", err))
+		} else if errors.Is(err, entity.ErrConflict) {
+			sendErrorResponse(w, http.StatusConflict, err.Error())
+		} else {
+			sendErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to leave todo list: %v", err))
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (h *TodoHandler) CreateTodoItem(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID) {
 	// User ID is expected to be in the context after authentication middleware
 	userID, ok := r.Context().Value(middleware.ContextKeyUserID).(string)
@@ -263,8 +502,8 @@ func (h *TodoHandler) CreateTodoItem(w http.ResponseWriter, r *http.Request, lis
 		return
 	}
 
-	var newTodoItem generated.NewTodoItem
-	if err := json.NewDecoder(r.Body).Decode(&newTodoItem); err != nil {
+	newTodoItem, err := httpjson.Decode[generated.NewTodoItem](r)
+	if err != nil {
 		sendErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
 		return
 	}
@@ -276,34 +515,107 @@ func (h *TodoHandler) CreateTodoItem(w http.ResponseWriter, r *http.Request, lis
 		Description: newTodoItem.Description,
 		Deadline:    newTodoItem.DueDate,
 		Position:    newTodoItem.Position,
+		Tags:        tagsFromRequest(newTodoItem.Tags),
+		Priority:    priorityFromRequest(newTodoItem.Priority),
 	})
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
+		if errors.Is(err, entity.ErrNotFound) {
 			sendErrorResponse(w, http.StatusNotFound, fmt.Sprintf("Todo list not found: %v", err))
-		} else if strings.Contains(err.Error(), "not authorized") {
+		} else if errors.Is(err, entity.ErrForbidden) {
 			sendErrorResponse(w, http.StatusForbidden, fmt.Sprintf("Forbidden: %v", err))
+		} else if errors.Is(err, entity.ErrInvalid) {
+			sendErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
 		} else {
 			sendErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to create todo item: %v", err))
 		}
 		return
 	}
 
-	responseTodoItem := generated.TodoItem{
-		Id:          openapi_types.UUID(uuid.MustParse(todoItem.ID)),
-		ListId:      openapi_types.UUID(uuid.MustParse(todoItem.ListID)),
-		Title:       todoItem.Title,
-		Description: todoItem.Description,
-		Completed:   todoItem.Completed,
-		DueDate:     todoItem.Deadline,
-		Position:    todoItem.Position,
-		CreatedAt:   &todoItem.CreatedAt,
-		UpdatedAt:   &todoItem.UpdatedAt,
-	}
+	responseTodoItem := toTodoItemResponse(todoItem)
 
 	sendJSONResponse(w, http.StatusCreated, responseTodoItem)
 }
 
-func (h *TodoHandler) GetTodoItemsByListId(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID) {
+func (h *TodoHandler) CreateTodoItemsBatch(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID) {
+	// User ID is expected to be in the context after authentication middleware
+	userID, ok := r.Context().Value(middleware.ContextKeyUserID).(string)
+	if !ok || userID == "" {
+		sendErrorResponse(w, http.StatusUnauthorized, "User ID not found in context")
+		return
+	}
+
+	newTodoItems, err := httpjson.Decode[generated.CreateTodoItemsBatchJSONRequestBody](r)
+	if err != nil {
+		sendErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+		return
+	}
+	if len(newTodoItems) == 0 {
+		sendErrorResponse(w, http.StatusBadRequest, "Invalid request body: at least one item is required")
+		return
+	}
+
+	items := make([]entity.TodoItem, len(newTodoItems))
+	for i, newTodoItem := range newTodoItems {
+		if strings.TrimSpace(newTodoItem.Title) == "" {
+			sendErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: item %d has an empty title", i))
+			return
+		}
+		items[i] = entity.TodoItem{
+			Completed:   newTodoItem.Completed,
+			Title:       newTodoItem.Title,
+			Description: newTodoItem.Description,
+			Deadline:    newTodoItem.DueDate,
+			Tags:        tagsFromRequest(newTodoItem.Tags),
+			Priority:    priorityFromRequest(newTodoItem.Priority),
+		}
+	}
+
+	todoItems, err := h.Usecases.CreateTodoItems(r.Context(), userID, listId.String(), items)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			sendErrorResponse(w, http.StatusNotFound, fmt.Sprintf("Todo list not found: %v", err))
+		} else if errors.Is(err, entity.ErrForbidden) {
+			sendErrorResponse(w, http.StatusForbidden, fmt.Sprintf("Forbidden: %v", err))
+		} else if errors.Is(err, entity.ErrInvalid) {
+			sendErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+		} else {
+			sendErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to create todo items: %v", err))
+		}
+		return
+	}
+
+	responseTodoItems := make([]generated.TodoItem, len(todoItems))
+	for i := range todoItems {
+		responseTodoItems[i] = toTodoItemResponse(&todoItems[i])
+	}
+
+	sendJSONResponse(w, http.StatusCreated, responseTodoItems)
+}
+
+// GetTodoItemsByTag handles GET /todo-items?tag=, searching every list the
+// caller can access.
+func (h *TodoHandler) GetTodoItemsByTag(w http.ResponseWriter, r *http.Request, params generated.GetTodoItemsByTagParams) {
+	userID, ok := r.Context().Value(middleware.ContextKeyUserID).(string)
+	if !ok || userID == "" {
+		sendErrorResponse(w, http.StatusUnauthorized, "User ID not found in context")
+		return
+	}
+
+	todoItems, err := h.Usecases.GetTodoItemsByTag(r.Context(), userID, params.Tag)
+	if err != nil {
+		sendErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get todo items: %v", err))
+		return
+	}
+
+	responseTodoItems := make([]generated.TodoItem, len(todoItems))
+	for i := range todoItems {
+		responseTodoItems[i] = toTodoItemResponse(&todoItems[i])
+	}
+
+	sendJSONResponse(w, http.StatusOK, responseTodoItems)
+}
+
+func (h *TodoHandler) GetTodoItemsByListId(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID, params generated.GetTodoItemsByListIdParams) {
 	// User ID is expected to be in the context after authentication middleware
 	userID, ok := r.Context().Value(middleware.ContextKeyUserID).(string)
 	if !ok || userID == "" {
@@ -311,35 +623,50 @@ func (h *TodoHandler) GetTodoItemsByListId(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	// The representation depends on Accept, so caches must key on it.
+	w.Header().Add("Vary", "Accept")
+	if params.Limit != nil || params.After != nil || paginated(params.Paginated) {
+		h.getTodoItemsPage(w, r, listId.String(), userID, params)
+		return
+	}
+	ndjson := wantsNDJSON(r)
+	if ndjson && params.Due == nil && (params.Sort == nil || *params.Sort != generated.Priority) {
+		h.streamTodoItemsNDJSON(w, r, listId.String(), userID)
+		return
+	}
+
 	todoItems, err := h.Usecases.GetTodoItemsByList(r.Context(), listId.String(), userID)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
+		if errors.Is(err, entity.ErrNotFound) {
 			sendErrorResponse(w, http.StatusNotFound, fmt.Sprintf("Todo list not found: %v", err))
-		} else if strings.Contains(err.Error(), "not authorized") {
+		} else if errors.Is(err, entity.ErrForbidden) {
 			sendErrorResponse(w, http.StatusForbidden, fmt.Sprintf("Forbidden: %v", err))
 		} else {
 			sendErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get todo items: %v", err))
 		}
 		return
 	}
+	if params.Due != nil {
+		todoItems, err = h.Usecases.FilterTodoItemsDue(r.Context(), userID, todoItems, string(*params.Due))
+		if err != nil {
+			sendErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to filter todo items: %v", err))
+			return
+		}
+	}
+	if params.Sort != nil && *params.Sort == generated.Priority {
+		usecase.SortTodoItemsByPriority(todoItems)
+	}
+	if ndjson {
+		writeItemsNDJSON(w, r, todoItems)
+		return
+	}
 
 	responseTodoItems := make([]generated.TodoItem, len(todoItems))
-	for i, item := range todoItems {
-		responseTodoItems[i] = generated.TodoItem{
-			Id:          openapi_types.UUID(uuid.MustParse(item.ID)),
-			ListId:      openapi_types.UUID(uuid.MustParse(item.ListID)),
-			Position:    item.Position,
-			Title:       item.Title,
-			Description: item.Description,
-			Completed:   item.Completed,
-			DueDate:     item.Deadline,
-			CreatedAt:   &item.CreatedAt,
-			UpdatedAt:   &item.UpdatedAt,
-		}
-		responseTodoItems[i].Position = item.Position
+	for i := range todoItems {
+		responseTodoItems[i] = toTodoItemResponse(&todoItems[i])
 	}
 
-	sendJSONResponse(w, http.StatusOK, responseTodoItems)
+	sendCacheableJSONResponse(w, r, http.StatusOK, responseTodoItems)
 }
 
 func (h *TodoHandler) GetTodoItemById(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID, itemId openapi_types.UUID) {
@@ -352,9 +679,9 @@ func (h *TodoHandler) GetTodoItemById(w http.ResponseWriter, r *http.Request, li
 
 	todoItem, err := h.Usecases.GetTodoItemByID(r.Context(), itemId.String(), listId.String(), userID)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
+		if errors.Is(err, entity.ErrNotFound) {
 			sendErrorResponse(w, http.StatusNotFound, fmt.Sprintf("Todo item or list not found: %v", err))
-		} else if strings.Contains(err.Error(), "not authorized") {
+		} else if errors.Is(err, entity.ErrForbidden) {
 			sendErrorResponse(w, http.StatusForbidden, fmt.Sprintf("Forbidden: %v", err))
 		} else {
 			sendErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get todo item: %v", err))
@@ -362,17 +689,7 @@ func (h *TodoHandler) GetTodoItemById(w http.ResponseWriter, r *http.Request, li
 		return
 	}
 
-	responseTodoItem := generated.TodoItem{
-		Id:          openapi_types.UUID(uuid.MustParse(todoItem.ID)),
-		ListId:      openapi_types.UUID(uuid.MustParse(todoItem.ListID)),
-		Position:    todoItem.Position,
-		Title:       todoItem.Title,
-		Description: todoItem.Description, // entity.TodoItem.Description is string, generated.TodoItem.Description is *string
-		Completed:   todoItem.Completed,
-		DueDate:     todoItem.Deadline,
-		CreatedAt:   &todoItem.CreatedAt,
-		UpdatedAt:   &todoItem.UpdatedAt,
-	}
+	responseTodoItem := toTodoItemResponse(todoItem)
 
 	sendJSONResponse(w, http.StatusOK, responseTodoItem)
 }
@@ -385,8 +702,8 @@ func (h *TodoHandler) UpdateTodoItem(w http.ResponseWriter, r *http.Request, lis
 		return
 	}
 
-	var updateTodoItem generated.UpdateTodoItem
-	if err := json.NewDecoder(r.Body).Decode(&updateTodoItem); err != nil {
+	updateTodoItem, err := httpjson.Decode[generated.UpdateTodoItem](r)
+	if err != nil {
 		sendErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
 		return
 	}
@@ -397,29 +714,26 @@ func (h *TodoHandler) UpdateTodoItem(w http.ResponseWriter, r *http.Request, lis
 		Description: updateTodoItem.Description,
 		Deadline:    updateTodoItem.DueDate,
 		Completed:   updateTodoItem.Completed,
+		Tags:        tagsFromRequest(updateTodoItem.Tags),
+		Priority:    priorityFromRequest(updateTodoItem.Priority),
+		Version:     updateTodoItem.Version,
 	})
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
+		if errors.Is(err, entity.ErrNotFound) {
 			sendErrorResponse(w, http.StatusNotFound, fmt.Sprintf("Todo item or list not found: %v", err))
-		} else if strings.Contains(err.Error(), "not authorized") {
+		} else if errors.Is(err, entity.ErrForbidden) {
 			sendErrorResponse(w, http.StatusForbidden, fmt.Sprintf("Forbidden: %v", err))
+		} else if errors.Is(err, entity.ErrConflict) {
+			sendErrorResponse(w, http.StatusConflict, fmt.Sprintf("Todo item was changed by someone else: %v", err))
+		} else if errors.Is(err, entity.ErrInvalid) {
+			sendErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
 		} else {
 			sendErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to update todo item: %v", err))
 		}
 		return
 	}
 
-	responseTodoItem := generated.TodoItem{
-		Id:          openapi_types.UUID(uuid.MustParse(todoItem.ID)),
-		ListId:      openapi_types.UUID(uuid.MustParse(todoItem.ListID)),
-		Title:       todoItem.Title,
-		Position:    todoItem.Position,
-		Description: todoItem.Description,
-		Completed:   todoItem.Completed,
-		DueDate:     todoItem.Deadline,
-		CreatedAt:   &todoItem.CreatedAt,
-		UpdatedAt:   &todoItem.UpdatedAt,
-	}
+	responseTodoItem := toTodoItemResponse(todoItem)
 
 	sendJSONResponse(w, http.StatusOK, responseTodoItem)
 }
@@ -434,9 +748,9 @@ func (h *TodoHandler) DeleteTodoItem(w http.ResponseWriter, r *http.Request, lis
 
 	err := h.Usecases.DeleteTodoItem(r.Context(), itemId.String(), listId.String(), userID)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
+		if errors.Is(err, entity.ErrNotFound) {
 			sendErrorResponse(w, http.StatusNotFound, fmt.Sprintf("Todo item or list not found: %v", err))
-		} else if strings.Contains(err.Error(), "not authorized") {
+		} else if errors.Is(err, entity.ErrForbidden) {
 			sendErrorResponse(w, http.StatusForbidden, fmt.Sprintf("Forbidden: %v", err))
 		} else {
 			sendErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to delete todo item: %v", err))
@@ -446,6 +760,31 @@ func (h *TodoHandler) DeleteTodoItem(w http.ResponseWriter, r *http.Request, lis
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (h *TodoHandler) RestoreTodoItem(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID, itemId openapi_types.UUID) {
+	// User ID is expected to be in the context after authentication middleware
+	userID, ok := r.Context().Value(middleware.ContextKeyUserID).(string)
+	if !ok || userID == "" {
+		sendErrorResponse(w, http.StatusUnauthorized, "User ID not found in context")
+		return
+	}
+
+	todoItem, err := h.Usecases.RestoreTodoItem(r.Context(), itemId.String(), listId.String(), userID)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			sendErrorResponse(w, http.StatusNotFound, fmt.Sprintf("Deleted todo item not found: %v", err))
+		} else if errors.Is(err, entity.ErrForbidden) {
+			sendErrorResponse(w, http.StatusForbidden, fmt.Sprintf("Forbidden: %v", err))
+		} else {
+			sendErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to restore todo item: %v", err))
+		}
+		return
+	}
+
+	responseTodoItem := toTodoItemResponse(todoItem)
+
+	sendJSONResponse(w, http.StatusOK, responseTodoItem)
+}
+
 func (h *TodoHandler) GetCollaborators(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID) {
 	// User ID is expected to be in the context after authentication middleware
 	userID, ok := r.Context().Value(middleware.ContextKeyUserID).(string)
@@ -456,9 +795,9 @@ func (h *TodoHandler) GetCollaborators(w http.ResponseWriter, r *http.Request, l
 
 	collaborators, err := h.Usecases.GetCollaboratorDetails(r.Context(), listId.String(), userID)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
+		if errors.Is(err, entity.ErrNotFound) {
 			sendErrorResponse(w, http.StatusNotFound, fmt.Sprintf("Todo list not found: %v", err))
-		} else if strings.Contains(err.Error(), "not authorized") {
+		} else if errors.Is(err, entity.ErrForbidden) {
 			sendErrorResponse(w, http.StatusForbidden, fmt.Sprintf("Forbidden: %v", err))
 		} else {
 			sendErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get collaborators: %v", err))
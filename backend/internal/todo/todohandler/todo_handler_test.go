@@ -0,0 +1,43 @@
+package todohandler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSendCacheableJSONResponseHonorsIfNoneMatch(t *testing.T) {
+	payload := map[string]string{"title": "Groceries"}
+
+	first := httptest.NewRecorder()
+	sendCacheableJSONResponse(first, httptest.NewRequest(http.MethodGet, "/todolists", nil), http.StatusOK, payload)
+	if first.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", first.Code, http.StatusOK)
+	}
+	etag := first.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("ETag header is empty")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/todolists", nil)
+	req.Header.Set("If-None-Match", `"stale", W/`+etag)
+	second := httptest.NewRecorder()
+	sendCacheableJSONResponse(second, req, http.StatusOK, payload)
+	if second.Code != http.StatusNotModified {
+		t.Fatalf("status = %d, want %d", second.Code, http.StatusNotModified)
+	}
+	if second.Body.Len() != 0 {
+		t.Fatalf("body = %q, want empty", second.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/todolists", nil)
+	req.Header.Set("If-None-Match", etag)
+	third := httptest.NewRecorder()
+	sendCacheableJSONResponse(third, req, http.StatusOK, map[string]string{"title": "Chores"})
+	if third.Code != http.StatusOK {
+		t.Fatalf("status after change = %d, want %d", third.Code, http.StatusOK)
+	}
+	if third.Header().Get("ETag") == etag {
+		t.Fatal("ETag did not change with the payload")
+	}
+}
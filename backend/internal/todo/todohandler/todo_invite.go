@@ -0,0 +1,88 @@
+package todohandler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+
+	"messenger/backend/api/generated"
+	"messenger/backend/internal/todo/entity"
+	"messenger/backend/internal/todo/usecase"
+	"messenger/backend/pkg/httpjson"
+	"messenger/backend/pkg/middleware"
+)
+
+// CreateTodoListInvite handles POST /todolists/{listId}/invites, minting an
+// invite token the owner can share instead of collaborator user IDs.
+func (h *TodoHandler) CreateTodoListInvite(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID) {
+	// User ID is expected to be in the context after authentication middleware
+	userID, ok := r.Context().Value(middleware.ContextKeyUserID).(string)
+	if !ok || userID == "" {
+		sendErrorResponse(w, http.StatusUnauthorized, "User ID not found in context")
+		return
+	}
+
+	req, err := httpjson.Decode[generated.NewTodoListInvite](r)
+	if err != nil {
+		sendErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+		return
+	}
+	singleUse := true
+	if req.SingleUse != nil {
+		singleUse = *req.SingleUse
+	}
+	ttl := usecase.DefaultInviteTTL
+	if req.ExpiresInHours != nil {
+		ttl = time.Duration(*req.ExpiresInHours) * time.Hour
+	}
+
+	token, invite, err := h.Usecases.CreateInvite(r.Context(), listId.String(), userID, singleUse, ttl)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			sendErrorResponse(w, http.StatusNotFound, fmt.Sprintf("Todo list not found: %v", err))
+		} else if errors.Is(err, entity.ErrForbidden) {
+			sendErrorResponse(w, http.StatusForbidden, fmt.Sprintf("Forbidden: %v", err))
+		} else if errors.Is(err, entity.ErrInvalid) {
+			sendErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Invalid invite: %v", err))
+		} else {
+			sendErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to create invite: %v", err))
+		}
+		return
+	}
+
+	sendJSONResponse(w, http.StatusCreated, generated.TodoListInvite{
+		Token:      token,
+		TodoListId: openapi_types.UUID(uuid.MustParse(invite.TodoListID)),
+		SingleUse:  invite.SingleUse,
+		ExpiresAt:  invite.ExpiresAt,
+	})
+}
+
+// AcceptTodoListInvite handles POST /todolists/invites/{token}/accept, adding
+// the caller to the invited list as a collaborator.
+func (h *TodoHandler) AcceptTodoListInvite(w http.ResponseWriter, r *http.Request, token string) {
+	// User ID is expected to be in the context after authentication middleware
+	userID, ok := r.Context().Value(middleware.ContextKeyUserID).(string)
+	if !ok || userID == "" {
+		sendErrorResponse(w, http.StatusUnauthorized, "User ID not found in context")
+		return
+	}
+
+	todoList, err := h.Usecases.AcceptInvite(r.Context(), token, userID)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			sendErrorResponse(w, http.StatusNotFound, "Invite not found, expired or already used")
+		} else if errors.Is(err, entity.ErrConflict) {
+			sendErrorResponse(w, http.StatusConflict, fmt.Sprintf("Cannot join: %v", err))
+		} else {
+			sendErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to accept invite: %v", err))
+		}
+		return
+	}
+
+	sendJSONResponse(w, http.StatusOK, toTodoListResponse(todoList))
+}
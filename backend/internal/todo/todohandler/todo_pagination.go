@@ -0,0 +1,124 @@
+package todohandler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"messenger/backend/api/generated"
+	"messenger/backend/internal/todo/entity"
+)
+
+// NextCursorHeader carries the cursor of the following page of a paged
+// collection; clients send it back as the after parameter.
+const NextCursorHeader = "Next-Cursor"
+
+// defaultPageSize applies when a client sends after without limit.
+const defaultPageSize = 100
+
+// page is the body of paginated=true responses: one page of a collection,
+// the size of the whole collection and the cursor of the next page, null on
+// the last one. TodoListPage and TodoItemPage in the spec are its instances.
+type page[T any] struct {
+	Items      []T     `json:"items"`
+	Total      int     `json:"total"`
+	NextCursor *string `json:"nextCursor"`
+}
+
+func newPage[T any](items []T, total int, next string) page[T] {
+	p := page[T]{Items: items, Total: total}
+	if next != "" {
+		p.NextCursor = &next
+	}
+	return p
+}
+
+func paginated(param *bool) bool {
+	return param != nil && *param
+}
+
+func pageSize(limit *int) int {
+	if limit == nil {
+		return defaultPageSize
+	}
+	return *limit
+}
+
+func pageCursor(after *string) string {
+	if after == nil {
+		return ""
+	}
+	return *after
+}
+
+// getTodoListsPage answers GET /todolists when limit, after or paginated is
+// set.
+func (h *TodoHandler) getTodoListsPage(w http.ResponseWriter, r *http.Request, userID string, params generated.GetTodoListsByUserIdParams) {
+	todoLists, next, err := h.Usecases.GetTodoListsPage(r.Context(), userID, pageCursor(params.After), pageSize(params.Limit))
+	total := 0
+	if err == nil && paginated(params.Paginated) {
+		total, err = h.Usecases.CountTodoLists(r.Context(), userID)
+	}
+	if err != nil {
+		if errors.Is(err, entity.ErrInvalid) {
+			sendErrorResponse(w, http.StatusBadRequest, err.Error())
+		} else {
+			sendErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get todo lists: %v", err))
+		}
+		return
+	}
+
+	responseTodoLists := make([]generated.TodoList, len(todoLists))
+	for i := range todoLists {
+		responseTodoLists[i] = toTodoListResponse(&todoLists[i])
+	}
+	if next != "" {
+		w.Header().Set(NextCursorHeader, next)
+	}
+	if paginated(params.Paginated) {
+		sendCacheableJSONResponse(w, r, http.StatusOK, newPage(responseTodoLists, total, next))
+		return
+	}
+	sendCacheableJSONResponse(w, r, http.StatusOK, responseTodoLists)
+}
+
+// getTodoItemsPage answers GET /todolists/{listId}/items when limit, after or
+// paginated is set. Pages follow position order, so neither sort=priority nor a due
+// filter can be combined with them.
+func (h *TodoHandler) getTodoItemsPage(w http.ResponseWriter, r *http.Request, listID string, userID string, params generated.GetTodoItemsByListIdParams) {
+	if params.Due != nil || (params.Sort != nil && *params.Sort != generated.Position) {
+		sendErrorResponse(w, http.StatusBadRequest, "Paging is only supported in position order without a due filter")
+		return
+	}
+
+	todoItems, next, err := h.Usecases.GetTodoItemsPage(r.Context(), listID, userID, pageCursor(params.After), pageSize(params.Limit))
+	total := 0
+	if err == nil && paginated(params.Paginated) {
+		total, err = h.Usecases.CountTodoItems(r.Context(), listID, userID)
+	}
+	if err != nil {
+		if errors.Is(err, entity.ErrInvalid) {
+			sendErrorResponse(w, http.StatusBadRequest, err.Error())
+		} else if errors.Is(err, entity.ErrNotFound) {
+			sendErrorResponse(w, http.StatusNotFound, fmt.Sprintf("Todo list not found: %v", err))
+		} else if errors.Is(err, entity.ErrForbidden) {
+			sendErrorResponse(w, http.StatusForbidden, fmt.Sprintf("Forbidden: %v", err))
+		} else {
+			sendErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get todo items: %v", err))
+		}
+		return
+	}
+
+	responseTodoItems := make([]generated.TodoItem, len(todoItems))
+	for i := range todoItems {
+		responseTodoItems[i] = toTodoItemResponse(&todoItems[i])
+	}
+	if next != "" {
+		w.Header().Set(NextCursorHeader, next)
+	}
+	if paginated(params.Paginated) {
+		sendCacheableJSONResponse(w, r, http.StatusOK, newPage(responseTodoItems, total, next))
+		return
+	}
+	sendCacheableJSONResponse(w, r, http.StatusOK, responseTodoItems)
+}
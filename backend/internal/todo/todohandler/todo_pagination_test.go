@@ -0,0 +1,28 @@
+package todohandler
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPageEnvelope(t *testing.T) {
+	tests := []struct {
+		name string
+		page page[string]
+		want string
+	}{
+		{name: "last page", page: newPage([]string{}, 0, ""), want: `{"items":[],"total":0,"nextCursor":null}`},
+		{name: "more to come", page: newPage([]string{"a"}, 3, "abc"), want: `{"items":["a"],"total":3,"nextCursor":"abc"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.page)
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Fatalf("json.Marshal() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package todohandler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"messenger/backend/api/generated"
+	"messenger/backend/internal/todo/entity"
+	"messenger/backend/pkg/httpjson"
+	"messenger/backend/pkg/middleware"
+
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// ReorderTodoItems handles PUT /todolists/{listId}/items/order, committing a
+// full reorder of the list's items at once.
+func (h *TodoHandler) ReorderTodoItems(w http.ResponseWriter, r *http.Request, listId openapi_types.UUID) {
+	// User ID is expected to be in the context after authentication middleware
+	userID, ok := r.Context().Value(middleware.ContextKeyUserID).(string)
+	if !ok || userID == "" {
+		sendErrorResponse(w, http.StatusUnauthorized, "User ID not found in context")
+		return
+	}
+
+	body, err := httpjson.Decode[generated.ReorderTodoItemsJSONRequestBody](r)
+	if err != nil {
+		sendErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+		return
+	}
+	itemIDs := make([]string, len(body))
+	for i, id := range body {
+		itemIDs[i] = id.String()
+	}
+
+	todoItems, err := h.Usecases.ReorderTodoItems(r.Context(), listId.String(), userID, itemIDs)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			sendErrorResponse(w, http.StatusNotFound, fmt.Sprintf("Todo list not found: %v", err))
+		} else if errors.Is(err, entity.ErrForbidden) {
+			sendErrorResponse(w, http.StatusForbidden, fmt.Sprintf("Forbidden: %v", err))
+		} else if errors.Is(err, entity.ErrInvalid) {
+			sendErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+		} else if errors.Is(err, entity.ErrConflict) {
+			sendErrorResponse(w, http.StatusConflict, fmt.Sprintf("Conflict: %v", err))
+		} else {
+			sendErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to reorder todo items: %v", err))
+		}
+		return
+	}
+
+	responseTodoItems := make([]generated.TodoItem, len(todoItems))
+	for i := range todoItems {
+		responseTodoItems[i] = toTodoItemResponse(&todoItems[i])
+	}
+	sendJSONResponse(w, http.StatusOK, responseTodoItems)
+}
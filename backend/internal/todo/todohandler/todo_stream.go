@@ -0,0 +1,107 @@
+package todohandler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"messenger/backend/internal/todo/entity"
+	"messenger/backend/pkg/middleware"
+)
+
+const (
+	ndjsonContentType = "application/x-ndjson"
+	// ndjsonFlushEvery is how many items are written between flushes.
+	ndjsonFlushEvery = 100
+)
+
+// wantsNDJSON reports whether the Accept header asks for newline-delimited
+// JSON. Anything else, including no Accept header, gets the JSON array.
+func wantsNDJSON(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err == nil && mediaType == ndjsonContentType && params["q"] != "0" {
+			return true
+		}
+	}
+	return false
+}
+
+// ndjsonWriter writes items one JSON object per line. The status line goes
+// out with the first item, so until then the response can still become an
+// error.
+type ndjsonWriter struct {
+	w       http.ResponseWriter
+	enc     *json.Encoder
+	written int
+}
+
+func newNDJSONWriter(w http.ResponseWriter) *ndjsonWriter {
+	return &ndjsonWriter{w: w, enc: json.NewEncoder(w)}
+}
+
+func (nw *ndjsonWriter) start() {
+	if nw.written == 0 {
+		nw.w.Header().Set("Content-Type", ndjsonContentType)
+		nw.w.WriteHeader(http.StatusOK)
+	}
+}
+
+func (nw *ndjsonWriter) write(item *entity.TodoItem) error {
+	nw.start()
+	if err := nw.enc.Encode(toTodoItemResponse(item)); err != nil {
+		return err
+	}
+	nw.written++
+	if nw.written%ndjsonFlushEvery == 0 {
+		nw.flush()
+	}
+	return nil
+}
+
+// finish sends the status line of an empty response and flushes the rest.
+func (nw *ndjsonWriter) finish() {
+	nw.start()
+	nw.flush()
+}
+
+func (nw *ndjsonWriter) flush() {
+	// Writers that cannot flush send everything when the handler returns.
+	_ = http.NewResponseController(nw.w).Flush()
+}
+
+// streamTodoItemsNDJSON answers GET /todolists/{listId}/items with
+// Accept: application/x-ndjson, writing each item as it is read from the
+// database. Errors after the first item can only be logged.
+func (h *TodoHandler) streamTodoItemsNDJSON(w http.ResponseWriter, r *http.Request, listID string, userID string) {
+	nw := newNDJSONWriter(w)
+	err := h.Usecases.StreamTodoItemsByList(r.Context(), listID, userID, nw.write)
+	switch {
+	case err == nil:
+		nw.finish()
+	case nw.written > 0:
+		middleware.Logf(r.Context(), "Failed to stream items of list %s: %v", listID, err)
+	case errors.Is(err, entity.ErrNotFound):
+		sendErrorResponse(w, http.StatusNotFound, fmt.Sprintf("Todo list not found: %v", err))
+	case errors.Is(err, entity.ErrForbidden):
+		sendErrorResponse(w, http.StatusForbidden, fmt.Sprintf("Forbidden: %v", err))
+	default:
+		sendErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get todo items: %v", err))
+	}
+}
+
+// writeItemsNDJSON sends items that are already loaded as NDJSON, for
+// filtered or re-sorted requests that need the whole list first.
+func writeItemsNDJSON(w http.ResponseWriter, r *http.Request, items []entity.TodoItem) {
+	nw := newNDJSONWriter(w)
+	for i := range items {
+		if err := nw.write(&items[i]); err != nil {
+			middleware.Logf(r.Context(), "Failed to write NDJSON items: %v", err)
+			return
+		}
+	}
+	nw.finish()
+}
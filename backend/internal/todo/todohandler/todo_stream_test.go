@@ -0,0 +1,72 @@
+package todohandler
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"messenger/backend/api/generated"
+	"messenger/backend/internal/todo/entity"
+)
+
+func TestWantsNDJSON(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   bool
+	}{
+		{"", false},
+		{"application/json", false},
+		{"application/x-ndjson", true},
+		{"application/json;q=0.5, application/x-ndjson", true},
+		{"application/x-ndjson;q=0", false},
+		{"*/*", false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/todolists/x/items", nil)
+		if tt.accept != "" {
+			req.Header.Set("Accept", tt.accept)
+		}
+		if got := wantsNDJSON(req); got != tt.want {
+			t.Errorf("wantsNDJSON(Accept: %q) = %v, want %v", tt.accept, got, tt.want)
+		}
+	}
+}
+
+func TestWriteItemsNDJSON(t *testing.T) {
+	items := make([]entity.TodoItem, ndjsonFlushEvery+1)
+	for i := range items {
+		items[i] = entity.TodoItem{ID: "33333333-3333-3333-3333-333333333333", ListID: "11111111-1111-1111-1111-111111111111", Title: "Item", Position: "a0", Priority: "medium"}
+	}
+
+	rec := httptest.NewRecorder()
+	writeItemsNDJSON(rec, httptest.NewRequest(http.MethodGet, "/todolists/x/items", nil), items)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if got := rec.Header().Get("Content-Type"); got != ndjsonContentType {
+		t.Fatalf("Content-Type = %q, want %q", got, ndjsonContentType)
+	}
+	if !rec.Flushed {
+		t.Fatal("response was never flushed")
+	}
+	lines := 0
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		var item generated.TodoItem
+		if err := json.Unmarshal(scanner.Bytes(), &item); err != nil {
+			t.Fatalf("line %d = %q: %v", lines+1, scanner.Text(), err)
+		}
+		lines++
+	}
+	if lines != len(items) {
+		t.Fatalf("lines = %d, want %d", lines, len(items))
+	}
+
+	empty := httptest.NewRecorder()
+	writeItemsNDJSON(empty, httptest.NewRequest(http.MethodGet, "/todolists/x/items", nil), nil)
+	if empty.Code != http.StatusOK || empty.Body.Len() != 0 {
+		t.Fatalf("empty list = %d %q, want 200 with no body", empty.Code, empty.Body.String())
+	}
+}
@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"messenger/backend/internal/todo/entity"
+)
+
+// SetAllTodoItemsCompleted marks every item of listID as completed or not,
+// or only those carrying tag when it is non-empty, and returns how many items
+// changed. The items are updated by a single statement rather than one by
+// one, and subscribers get one EventItemsUpdated instead of an event per
+// item.
+func (uc *Usecase) SetAllTodoItemsCompleted(ctx context.Context, listID string, userID string, completed bool, tag string) (int64, error) {
+	tag = normalizeTag(tag)
+
+	var updated int64
+	err := uc.inTx(ctx, func(repos txRepos) error {
+		todoList, err := repos.lists.GetTodoListByIDForUpdate(ctx, listID)
+		if err != nil {
+			return fmt.Errorf("failed to get todo list by ID: %w", err)
+		}
+
+		if todoList.OwnerID != userID {
+			isCollab, err := repos.collabs.IsCollaborator(ctx, listID, userID)
+			if err != nil {
+				return fmt.Errorf("failed to check collaborator status: %w", err)
+			}
+			if !isCollab {
+				return fmt.Errorf("%w: user is not authorized to update items in this todo list", entity.ErrForbidden)
+			}
+		}
+
+		updated, err = repos.items.SetTodoItemsCompleted(ctx, listID, completed, tag)
+		if err != nil {
+			return fmt.Errorf("failed to update todo items in repository: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if updated > 0 {
+		uc.Events.Publish(Event{Type: EventItemsUpdated, ListID: listID, ActorID: userID})
+	}
+	return updated, nil
+}
@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"messenger/backend/internal/todo/entity"
+)
+
+func TestSetAllTodoItemsCompleted(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	ctx := context.Background()
+
+	tagged, err := uc.CreateTodoItem(ctx, testOwnerID, entity.TodoItem{ListID: testListIDTwo, Title: "Laundry", Tags: []string{"home"}})
+	if err != nil {
+		t.Fatalf("CreateTodoItem() error = %v", err)
+	}
+	// The list now holds Dishes and Laundry, neither completed.
+
+	if _, err := uc.SetAllTodoItemsCompleted(ctx, testListIDTwo, testOtherID, true, ""); !errors.Is(err, entity.ErrForbidden) {
+		t.Fatalf("SetAllTodoItemsCompleted() by a stranger error = %v, want ErrForbidden", err)
+	}
+
+	events, unsubscribe, err := uc.SubscribeTodoListEvents(ctx, testListIDTwo, testOwnerID)
+	if err != nil {
+		t.Fatalf("SubscribeTodoListEvents() error = %v", err)
+	}
+	defer unsubscribe()
+
+	if updated, err := uc.SetAllTodoItemsCompleted(ctx, testListIDTwo, testOwnerID, true, " HOME "); err != nil || updated != 1 {
+		t.Fatalf("SetAllTodoItemsCompleted(tag) = %d, %v, want 1 item", updated, err)
+	}
+	if ev := <-events; ev.Type != EventItemsUpdated || ev.ActorID != testOwnerID || ev.Item != nil {
+		t.Fatalf("event = %+v, want items.updated by the owner", ev)
+	}
+	if updated, err := uc.SetAllTodoItemsCompleted(ctx, testListIDTwo, testOwnerID, true, ""); err != nil || updated != 1 {
+		t.Fatalf("SetAllTodoItemsCompleted() = %d, %v, want only the incomplete item", updated, err)
+	}
+
+	items, err := uc.GetTodoItemsByList(ctx, testListIDTwo, testOwnerID)
+	if err != nil {
+		t.Fatalf("GetTodoItemsByList() error = %v", err)
+	}
+	for _, item := range items {
+		if !item.Completed || item.Version != 2 {
+			t.Fatalf("item %s = completed %v at version %d, want completed at version 2", item.Title, item.Completed, item.Version)
+		}
+	}
+
+	if updated, err := uc.SetAllTodoItemsCompleted(ctx, testListIDTwo, testOwnerID, false, ""); err != nil || updated != 2 {
+		t.Fatalf("SetAllTodoItemsCompleted(false) = %d, %v, want 2 items", updated, err)
+	}
+	item, err := uc.GetTodoItemByID(ctx, tagged.ID, testListIDTwo, testOwnerID)
+	if err != nil {
+		t.Fatalf("GetTodoItemByID() error = %v", err)
+	}
+	if item.Completed || item.Version != 3 {
+		t.Fatalf("item after uncompleting = completed %v at version %d, want incomplete at version 3", item.Completed, item.Version)
+	}
+}
@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"messenger/backend/internal/todo/entity"
+)
+
+// Due windows accepted by FilterTodoItemsDue.
+const (
+	DueOverdue  = "overdue"
+	DueToday    = "today"
+	DueTomorrow = "tomorrow"
+)
+
+// dueRange returns the half-open deadline range [start, end) of window at
+// now. Days start at midnight in loc, so "today" follows the user's calendar
+// rather than UTC's, including on days with a DST change.
+func dueRange(window string, now time.Time, loc *time.Location) (start, end time.Time, err error) {
+	y, m, d := now.In(loc).Date()
+	switch window {
+	case DueOverdue:
+		return time.Time{}, now, nil
+	case DueToday:
+		return time.Date(y, m, d, 0, 0, 0, 0, loc), time.Date(y, m, d+1, 0, 0, 0, 0, loc), nil
+	case DueTomorrow:
+		return time.Date(y, m, d+1, 0, 0, 0, 0, loc), time.Date(y, m, d+2, 0, 0, 0, 0, loc), nil
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("%w: unknown due window %q", entity.ErrInvalid, window)
+}
+
+// FilterTodoItemsDue keeps the items whose deadline falls in window, judged in
+// userID's timezone. Deadlines stay in UTC; only the day boundaries move.
+// Overdue items are the incomplete ones whose deadline has passed.
+func (uc *Usecase) FilterTodoItemsDue(ctx context.Context, userID string, items []entity.TodoItem, window string) ([]entity.TodoItem, error) {
+	loc := time.UTC
+	if uc.UserLocation != nil {
+		var err error
+		if loc, err = uc.UserLocation(ctx, userID); err != nil {
+			return nil, fmt.Errorf("failed to get user timezone: %w", err)
+		}
+	}
+	start, end, err := dueRange(window, uc.Now(), loc)
+	if err != nil {
+		return nil, err
+	}
+
+	due := make([]entity.TodoItem, 0, len(items))
+	for _, item := range items {
+		if item.Deadline == nil || item.Deadline.Before(start) || !item.Deadline.Before(end) {
+			continue
+		}
+		if window == DueOverdue && item.Completed {
+			continue
+		}
+		due = append(due, item)
+	}
+	return due, nil
+}
@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"messenger/backend/internal/todo/entity"
+)
+
+func TestFilterTodoItemsDueUsesUserTimezone(t *testing.T) {
+	losAngeles, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatalf("LoadLocation() error = %v", err)
+	}
+	// 03:00 UTC on May 2 is still the evening of May 1 in Los Angeles.
+	now := time.Date(2026, 5, 2, 3, 0, 0, 0, time.UTC)
+	at := func(s string) *time.Time {
+		ts, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatalf("time.Parse(%q) error = %v", s, err)
+		}
+		return &ts
+	}
+	items := []entity.TodoItem{
+		{Title: "passed", Deadline: at("2026-05-02T01:00:00Z")},
+		{Title: "passed but done", Deadline: at("2026-05-02T01:00:00Z"), Completed: true},
+		{Title: "tonight", Deadline: at("2026-05-02T05:00:00Z")},
+		{Title: "tomorrow morning", Deadline: at("2026-05-02T16:00:00Z")},
+		{Title: "day after", Deadline: at("2026-05-04T08:00:00Z")},
+		{Title: "no deadline"},
+	}
+
+	tests := []struct {
+		name     string
+		location func(ctx context.Context, userID string) (*time.Location, error)
+		window   string
+		want     []string
+	}{
+		{name: "today in Los Angeles", location: fixedLocation(losAngeles), window: DueToday, want: []string{"passed", "passed but done", "tonight"}},
+		{name: "tomorrow in Los Angeles", location: fixedLocation(losAngeles), window: DueTomorrow, want: []string{"tomorrow morning"}},
+		{name: "today in UTC without a timezone", window: DueToday, want: []string{"passed", "passed but done", "tonight", "tomorrow morning"}},
+		{name: "overdue skips completed items", location: fixedLocation(losAngeles), window: DueOverdue, want: []string{"passed"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &Usecase{Now: func() time.Time { return now }, UserLocation: tt.location}
+			due, err := uc.FilterTodoItemsDue(context.Background(), testOwnerID, items, tt.window)
+			if err != nil {
+				t.Fatalf("FilterTodoItemsDue() error = %v", err)
+			}
+			var titles []string
+			for _, item := range due {
+				titles = append(titles, item.Title)
+			}
+			if !slices.Equal(titles, tt.want) {
+				t.Fatalf("due items = %q, want %q", titles, tt.want)
+			}
+		})
+	}
+
+	uc := &Usecase{Now: func() time.Time { return now }}
+	if _, err := uc.FilterTodoItemsDue(context.Background(), testOwnerID, items, "someday"); !errors.Is(err, entity.ErrInvalid) {
+		t.Fatalf("FilterTodoItemsDue(someday) error = %v, want ErrInvalid", err)
+	}
+}
+
+func TestDueRangeAcrossDSTChange(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("LoadLocation() error = %v", err)
+	}
+	// Clocks in Berlin jump from 02:00 to 03:00 on March 29, 2026.
+	start, end, err := dueRange(DueToday, time.Date(2026, 3, 29, 12, 0, 0, 0, berlin), berlin)
+	if err != nil {
+		t.Fatalf("dueRange() error = %v", err)
+	}
+	if got := end.Sub(start); got != 23*time.Hour {
+		t.Fatalf("today spans %s, want 23h on the DST change", got)
+	}
+}
+
+func fixedLocation(loc *time.Location) func(context.Context, string) (*time.Location, error) {
+	return func(context.Context, string) (*time.Location, error) { return loc, nil }
+}
@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"sync"
+
+	"messenger/backend/internal/todo/entity"
+)
+
+// EventType names a change to a todo list.
+type EventType string
+
+const (
+	EventItemCreated EventType = "item.created"
+	EventItemUpdated EventType = "item.updated"
+	EventItemDeleted EventType = "item.deleted"
+	// EventItemsUpdated reports a bulk change to several items of the list,
+	// which subscribers should refetch.
+	EventItemsUpdated EventType = "items.updated"
+	EventListDeleted  EventType = "list.deleted"
+)
+
+// Event describes a committed change to a todo list. Item is set for created
+// and updated items; deletions only carry ItemID, and bulk changes neither.
+type Event struct {
+	Type    EventType
+	ListID  string
+	ActorID string
+	ItemID  string
+	Item    *entity.TodoItem
+}
+
+// subscriberBuffer is how many events a subscriber may fall behind before it
+// is dropped.
+const subscriberBuffer = 32
+
+// EventHub fans events out to in-process subscribers keyed by list ID.
+type EventHub struct {
+	mu   sync.Mutex
+	subs map[string]map[chan Event]struct{}
+}
+
+// NewEventHub creates an empty EventHub.
+func NewEventHub() *EventHub {
+	return &EventHub{subs: make(map[string]map[chan Event]struct{})}
+}
+
+// Subscribe registers for events on listID. The returned channel is closed by
+// unsubscribe, or earlier if the subscriber stops keeping up; in that case it
+// has missed events and should refetch the list. unsubscribe may be called
+// more than once.
+func (h *EventHub) Subscribe(listID string) (events <-chan Event, unsubscribe func()) {
+	ch := make(chan Event, subscriberBuffer)
+
+	h.mu.Lock()
+	if h.subs[listID] == nil {
+		h.subs[listID] = make(map[chan Event]struct{})
+	}
+	h.subs[listID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.remove(listID, ch)
+	}
+}
+
+// Publish delivers ev to every subscriber of ev.ListID without blocking.
+func (h *EventHub) Publish(ev Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subs[ev.ListID] {
+		select {
+		case ch <- ev:
+		default:
+			h.remove(ev.ListID, ch)
+		}
+	}
+}
+
+// remove closes ch and forgets it. The caller must hold h.mu.
+func (h *EventHub) remove(listID string, ch chan Event) {
+	subs, ok := h.subs[listID]
+	if !ok {
+		return
+	}
+	if _, ok := subs[ch]; !ok {
+		return
+	}
+	delete(subs, ch)
+	close(ch)
+	if len(subs) == 0 {
+		delete(h.subs, listID)
+	}
+}
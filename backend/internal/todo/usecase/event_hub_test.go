@@ -0,0 +1,33 @@
+package usecase
+
+import "testing"
+
+func TestEventHubDropsSubscribersThatFallBehind(t *testing.T) {
+	hub := NewEventHub()
+	slow, unsubscribeSlow := hub.Subscribe(testListID)
+	defer unsubscribeSlow()
+
+	for i := 0; i <= subscriberBuffer; i++ {
+		hub.Publish(Event{Type: EventItemUpdated, ListID: testListID})
+	}
+
+	received := 0
+	for range slow {
+		received++
+	}
+	if received != subscriberBuffer {
+		t.Fatalf("received %d events before close, want %d", received, subscriberBuffer)
+	}
+
+	// A fresh subscriber is unaffected and unsubscribing twice is harmless.
+	events, unsubscribe := hub.Subscribe(testListID)
+	hub.Publish(Event{Type: EventItemDeleted, ListID: testListID})
+	if ev := <-events; ev.Type != EventItemDeleted {
+		t.Fatalf("event = %+v, want item.deleted", ev)
+	}
+	unsubscribe()
+	unsubscribe()
+	if _, ok := <-events; ok {
+		t.Fatal("channel still open after unsubscribe")
+	}
+}
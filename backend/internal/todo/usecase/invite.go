@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"messenger/backend/internal/todo/entity"
+)
+
+// Bounds on how long an invite stays valid.
+const (
+	DefaultInviteTTL = 7 * 24 * time.Hour
+	MaxInviteTTL     = 30 * 24 * time.Hour
+)
+
+// CreateInvite mints an invite to the list for its owner to share. Whoever
+// accepts it before ttl has passed joins as a collaborator; a single-use
+// invite is spent by the first acceptance. The returned token is shown once:
+// only its hash is stored.
+func (uc *Usecase) CreateInvite(ctx context.Context, todoListID, requestingUserID string, singleUse bool, ttl time.Duration) (string, *entity.TodoListInvite, error) {
+	if ttl <= 0 || ttl > MaxInviteTTL {
+		return "", nil, fmt.Errorf("%w: invite lifetime must be positive and at most %s", entity.ErrInvalid, MaxInviteTTL)
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, fmt.Errorf("failed to generate invite token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	var invite *entity.TodoListInvite
+	err := uc.inTx(ctx, func(repos txRepos) error {
+		todoList, err := repos.lists.GetTodoListByID(ctx, todoListID)
+		if err != nil {
+			return fmt.Errorf("failed to get todo list by ID: %w", err)
+		}
+		if todoList.OwnerID != requestingUserID {
+			return fmt.Errorf("%w: user is not authorized to invite collaborators to this todo list", entity.ErrForbidden)
+		}
+
+		invite = &entity.TodoListInvite{
+			TokenHash:  hashInviteToken(token),
+			TodoListID: todoListID,
+			CreatedBy:  requestingUserID,
+			SingleUse:  singleUse,
+			ExpiresAt:  uc.Now().Add(ttl).UTC(),
+		}
+		return repos.invites.CreateInvite(ctx, invite)
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return token, invite, nil
+}
+
+// AcceptInvite adds userID as a collaborator on the list token invites to and
+// returns the list. Unknown, expired and already used invites are reported as
+// entity.ErrNotFound alike; members of the list, and anyone once the list is
+// full, get entity.ErrConflict and do not use the invite up.
+func (uc *Usecase) AcceptInvite(ctx context.Context, token, userID string) (*entity.TodoList, error) {
+	var todoList *entity.TodoList
+	err := uc.inTx(ctx, func(repos txRepos) error {
+		invite, err := repos.invites.GetInviteByTokenHashForUpdate(ctx, hashInviteToken(token))
+		if err != nil {
+			return fmt.Errorf("failed to get invite: %w", err)
+		}
+		now := uc.Now()
+		if !now.Before(invite.ExpiresAt) || (invite.SingleUse && invite.UsedAt != nil) {
+			return fmt.Errorf("%w: invite has expired or was already used", entity.ErrNotFound)
+		}
+
+		todoList, err = repos.lists.GetTodoListByIDForUpdate(ctx, invite.TodoListID)
+		if err != nil {
+			return fmt.Errorf("failed to get todo list by ID: %w", err)
+		}
+		if todoList.OwnerID == userID {
+			return fmt.Errorf("%w: user owns this todo list", entity.ErrConflict)
+		}
+		isCollab, err := repos.collabs.IsCollaborator(ctx, todoList.ID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to check if user is already a collaborator: %w", err)
+		}
+		if isCollab {
+			return fmt.Errorf("%w: user is already a collaborator", entity.ErrConflict)
+		}
+		if err := uc.checkCollaboratorLimit(ctx, repos, todoList.ID); err != nil {
+			return err
+		}
+
+		if err := repos.collabs.AddCollaborator(ctx, &entity.TodoListCollaborator{
+			TodoListID:     todoList.ID,
+			CollaboratorID: userID,
+		}); err != nil {
+			return fmt.Errorf("failed to add collaborator to repository: %w", err)
+		}
+		if invite.SingleUse {
+			return repos.invites.MarkInviteUsed(ctx, invite.TokenHash, userID, now)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return todoList, nil
+}
+
+func hashInviteToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"messenger/backend/internal/todo/entity"
+)
+
+func TestInviteAcceptance(t *testing.T) {
+	uc, db := newTestUsecase(t)
+	ctx := context.Background()
+	const thirdID = "cccccccc-cccc-cccc-cccc-cccccccccccc"
+
+	if _, _, err := uc.CreateInvite(ctx, testListID, testOtherID, true, time.Hour); !errors.Is(err, entity.ErrForbidden) {
+		t.Fatalf("CreateInvite() by non-owner error = %v, want ErrForbidden", err)
+	}
+	if _, _, err := uc.CreateInvite(ctx, testListID, testOwnerID, true, MaxInviteTTL+time.Hour); !errors.Is(err, entity.ErrInvalid) {
+		t.Fatalf("CreateInvite() with too long a lifetime error = %v, want ErrInvalid", err)
+	}
+
+	token, invite, err := uc.CreateInvite(ctx, testListID, testOwnerID, true, time.Hour)
+	if err != nil {
+		t.Fatalf("CreateInvite() error = %v", err)
+	}
+	if token == "" || invite.TokenHash == token || invite.TodoListID != testListID {
+		t.Fatalf("invite = %+v, token %q; want a hashed token for the list", invite, token)
+	}
+
+	if _, err := uc.AcceptInvite(ctx, token, testOwnerID); !errors.Is(err, entity.ErrConflict) {
+		t.Fatalf("AcceptInvite() by the owner error = %v, want ErrConflict", err)
+	}
+	list, err := uc.AcceptInvite(ctx, token, testOtherID)
+	if err != nil {
+		t.Fatalf("AcceptInvite() error = %v", err)
+	}
+	if list.ID != testListID {
+		t.Fatalf("accepted list = %s, want %s", list.ID, testListID)
+	}
+	if isCollab, err := uc.TodoListCollabRepo.IsCollaborator(ctx, testListID, testOtherID); err != nil || !isCollab {
+		t.Fatalf("IsCollaborator() = %t, %v; want true", isCollab, err)
+	}
+	if _, err := uc.AcceptInvite(ctx, token, thirdID); !errors.Is(err, entity.ErrNotFound) {
+		t.Fatalf("AcceptInvite() of a used single-use invite error = %v, want ErrNotFound", err)
+	}
+	if _, err := uc.AcceptInvite(ctx, "made-up", thirdID); !errors.Is(err, entity.ErrNotFound) {
+		t.Fatalf("AcceptInvite() of an unknown token error = %v, want ErrNotFound", err)
+	}
+
+	reusable, _, err := uc.CreateInvite(ctx, testListIDTwo, testOwnerID, false, time.Hour)
+	if err != nil {
+		t.Fatalf("CreateInvite() error = %v", err)
+	}
+	for _, userID := range []string{testOtherID, thirdID} {
+		if _, err := uc.AcceptInvite(ctx, reusable, userID); err != nil {
+			t.Fatalf("AcceptInvite(%s) of a reusable invite error = %v", userID, err)
+		}
+	}
+	if _, err := uc.AcceptInvite(ctx, reusable, thirdID); !errors.Is(err, entity.ErrConflict) {
+		t.Fatalf("AcceptInvite() by a member error = %v, want ErrConflict", err)
+	}
+
+	uc.Now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if err := db.Exec("DELETE FROM todo_list_collaborators").Error; err != nil {
+		t.Fatalf("clear collaborators: %v", err)
+	}
+	if _, err := uc.AcceptInvite(ctx, reusable, thirdID); !errors.Is(err, entity.ErrNotFound) {
+		t.Fatalf("AcceptInvite() of an expired invite error = %v, want ErrNotFound", err)
+	}
+}
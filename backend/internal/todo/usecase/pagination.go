@@ -0,0 +1,122 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"messenger/backend/internal/todo/entity"
+)
+
+// MaxPageSize caps how many lists or items one page may hold.
+const MaxPageSize = 500
+
+// encodeCursor turns a cursor into the opaque token clients send back as
+// after. Clients must not rely on what is inside.
+func encodeCursor(cursor interface{}) string {
+	raw, err := json.Marshal(cursor)
+	if err != nil {
+		panic(fmt.Sprintf("encode page cursor: %v", err))
+	}
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeCursor reads a token made by encodeCursor into cursor. Anything else
+// is ErrInvalid.
+func decodeCursor(token string, cursor interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err == nil {
+		err = json.Unmarshal(raw, cursor)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: malformed page cursor", entity.ErrInvalid)
+	}
+	return nil
+}
+
+func checkPageSize(limit int) error {
+	if limit < 1 || limit > MaxPageSize {
+		return fmt.Errorf("%w: page size must be between 1 and %d", entity.ErrInvalid, MaxPageSize)
+	}
+	return nil
+}
+
+// GetTodoItemsPage returns up to limit items of the list in position order,
+// starting after the item the after token points at ("" for the first page),
+// and the token of the next page, which is "" on the last one.
+func (uc *Usecase) GetTodoItemsPage(ctx context.Context, listID string, userID string, after string, limit int) ([]entity.TodoItem, string, error) {
+	if err := checkPageSize(limit); err != nil {
+		return nil, "", err
+	}
+	var cursor *entity.ItemCursor
+	if after != "" {
+		cursor = &entity.ItemCursor{}
+		if err := decodeCursor(after, cursor); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := uc.checkItemAccess(ctx, listID, userID); err != nil {
+		return nil, "", err
+	}
+
+	// One extra row tells whether there is a next page.
+	todoItems, err := uc.TodoItemRepo.GetTodoItemsPageByListID(ctx, listID, cursor, limit+1)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get page of todo items from repository: %w", err)
+	}
+	if len(todoItems) <= limit {
+		return todoItems, "", nil
+	}
+	todoItems = todoItems[:limit]
+	last := todoItems[limit-1]
+	return todoItems, encodeCursor(entity.ItemCursor{Position: last.Position, ID: last.ID}), nil
+}
+
+// CountTodoItems returns how many items the list holds, for page envelopes.
+func (uc *Usecase) CountTodoItems(ctx context.Context, listID string, userID string) (int, error) {
+	if err := uc.checkItemAccess(ctx, listID, userID); err != nil {
+		return 0, err
+	}
+	count, err := uc.TodoItemRepo.CountTodoItemsByListID(ctx, listID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count todo items in repository: %w", err)
+	}
+	return int(count), nil
+}
+
+// GetTodoListsPage returns up to limit of the lists userID owns or
+// collaborates on, newest first, starting after the list the after token
+// points at, and the token of the next page, which is "" on the last one.
+func (uc *Usecase) GetTodoListsPage(ctx context.Context, userID string, after string, limit int) ([]entity.TodoList, string, error) {
+	if err := checkPageSize(limit); err != nil {
+		return nil, "", err
+	}
+	var cursor *entity.ListCursor
+	if after != "" {
+		cursor = &entity.ListCursor{}
+		if err := decodeCursor(after, cursor); err != nil {
+			return nil, "", err
+		}
+	}
+
+	todoLists, err := uc.TodoListRepo.GetTodoListsPageByUserID(ctx, userID, cursor, limit+1)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get page of todo lists from repository: %w", err)
+	}
+	if len(todoLists) <= limit {
+		return todoLists, "", nil
+	}
+	todoLists = todoLists[:limit]
+	last := todoLists[limit-1]
+	return todoLists, encodeCursor(entity.ListCursor{CreatedAt: last.CreatedAt, ID: last.ID}), nil
+}
+
+// CountTodoLists returns how many lists userID owns or collaborates on.
+func (uc *Usecase) CountTodoLists(ctx context.Context, userID string) (int, error) {
+	count, err := uc.TodoListRepo.CountTodoListsByUserID(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count todo lists in repository: %w", err)
+	}
+	return int(count), nil
+}
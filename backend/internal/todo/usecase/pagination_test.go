@@ -0,0 +1,151 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"messenger/backend/internal/todo/entity"
+)
+
+func TestGetTodoItemsPageIsStableUnderInserts(t *testing.T) {
+	uc, db := newTestUsecase(t)
+	ctx := context.Background()
+
+	for i, position := range []string{"b", "d", "f", "h", "j"} {
+		item := entity.TodoItem{ID: fmt.Sprintf("44444444-4444-4444-4444-%012d", i), ListID: testListID, Position: position, Title: position}
+		if err := db.Create(&item).Error; err != nil {
+			t.Fatalf("Create(%s) error = %v", position, err)
+		}
+	}
+
+	var seen []string
+	after := ""
+	for page := 0; ; page++ {
+		items, next, err := uc.GetTodoItemsPage(ctx, testListID, testOwnerID, after, 2)
+		if err != nil {
+			t.Fatalf("GetTodoItemsPage() page %d error = %v", page, err)
+		}
+		for _, item := range items {
+			seen = append(seen, item.Title)
+		}
+		if page == 0 {
+			// Rows added before the cursor must not shift later pages.
+			for _, position := range []string{"a", "c"} {
+				item := entity.TodoItem{ID: "55555555-5555-5555-5555-55555555555" + position, ListID: testListID, Position: position, Title: position}
+				if err := db.Create(&item).Error; err != nil {
+					t.Fatalf("Create(%s) error = %v", position, err)
+				}
+			}
+		}
+		if next == "" {
+			break
+		}
+		after = next
+	}
+	if want := []string{"b", "d", "f", "h", "j"}; !slices.Equal(seen, want) {
+		t.Fatalf("paged titles = %q, want %q", seen, want)
+	}
+
+	if _, _, err := uc.GetTodoItemsPage(ctx, testListID, testOwnerID, "not a cursor!", 2); !errors.Is(err, entity.ErrInvalid) {
+		t.Fatalf("GetTodoItemsPage() with a bad cursor error = %v, want ErrInvalid", err)
+	}
+	if _, _, err := uc.GetTodoItemsPage(ctx, testListID, testOwnerID, "", MaxPageSize+1); !errors.Is(err, entity.ErrInvalid) {
+		t.Fatalf("GetTodoItemsPage() over the page size cap error = %v, want ErrInvalid", err)
+	}
+	if _, _, err := uc.GetTodoItemsPage(ctx, testListID, testOtherID, "", 2); !errors.Is(err, entity.ErrForbidden) {
+		t.Fatalf("GetTodoItemsPage() by a stranger error = %v, want ErrForbidden", err)
+	}
+}
+
+func TestGetTodoListsPageIsStableUnderInserts(t *testing.T) {
+	uc, db := newTestUsecase(t)
+	ctx := context.Background()
+
+	// testListID and testListIDTwo were created just now; these are older.
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		list := entity.TodoList{ID: fmt.Sprintf("66666666-6666-6666-6666-%012d", i), Title: fmt.Sprintf("old %d", i), OwnerID: testOwnerID, CreatedAt: base.Add(time.Duration(i) * time.Hour)}
+		if err := db.Create(&list).Error; err != nil {
+			t.Fatalf("Create(list) error = %v", err)
+		}
+	}
+	// Two lists created in the same instant are told apart by ID.
+	twin := entity.TodoList{ID: "66666666-6666-6666-6666-999999999999", Title: "old 2 twin", OwnerID: testOwnerID, CreatedAt: base.Add(2 * time.Hour)}
+	if err := db.Create(&twin).Error; err != nil {
+		t.Fatalf("Create(twin) error = %v", err)
+	}
+
+	seen := make(map[string]int)
+	var order []string
+	after := ""
+	for page := 0; ; page++ {
+		lists, next, err := uc.GetTodoListsPage(ctx, testOwnerID, after, 2)
+		if err != nil {
+			t.Fatalf("GetTodoListsPage() page %d error = %v", page, err)
+		}
+		for _, list := range lists {
+			seen[list.ID]++
+			order = append(order, list.Title)
+		}
+		if page == 0 {
+			if _, err := uc.CreateTodoList(ctx, "Brand new", "", testOwnerID); err != nil {
+				t.Fatalf("CreateTodoList() error = %v", err)
+			}
+		}
+		if next == "" {
+			break
+		}
+		after = next
+	}
+	if len(order) != 6 {
+		t.Fatalf("paged lists = %q, want the 6 that existed when paging began", order)
+	}
+	for id, n := range seen {
+		if n != 1 {
+			t.Fatalf("list %s returned %d times", id, n)
+		}
+	}
+	if want := []string{"old 2 twin", "old 2", "old 1", "old 0"}; !slices.Equal(order[2:], want) {
+		t.Fatalf("older lists = %q, want %q", order[2:], want)
+	}
+}
+
+func TestCountTodoListsAndItems(t *testing.T) {
+	uc, db := newTestUsecase(t)
+	ctx := context.Background()
+
+	// Collaborating on a list counts it once, however many rows join it.
+	if err := uc.AddCollaborator(ctx, testListID, testOtherID, testOwnerID); err != nil {
+		t.Fatalf("AddCollaborator() error = %v", err)
+	}
+	if err := uc.AddCollaborator(ctx, testListIDTwo, testOtherID, testOwnerID); err != nil {
+		t.Fatalf("AddCollaborator() error = %v", err)
+	}
+	third := entity.TodoListCollaborator{TodoListID: testListID, CollaboratorID: "cccccccc-cccc-cccc-cccc-cccccccccccc"}
+	if err := db.Create(&third).Error; err != nil {
+		t.Fatalf("Create(collaborator) error = %v", err)
+	}
+	for userID, want := range map[string]int{testOwnerID: 2, testOtherID: 2} {
+		if got, err := uc.CountTodoLists(ctx, userID); err != nil || got != want {
+			t.Fatalf("CountTodoLists(%s) = %d, %v, want %d", userID, got, err, want)
+		}
+	}
+
+	trashed := entity.TodoItem{ID: "77777777-7777-7777-7777-777777777777", ListID: testListIDTwo, Position: "z", Title: "Trashed"}
+	if err := db.Create(&trashed).Error; err != nil {
+		t.Fatalf("Create(item) error = %v", err)
+	}
+	if err := db.Delete(&trashed).Error; err != nil {
+		t.Fatalf("Delete(item) error = %v", err)
+	}
+	if got, err := uc.CountTodoItems(ctx, testListIDTwo, testOtherID); err != nil || got != 1 {
+		t.Fatalf("CountTodoItems() = %d, %v, want 1 leaving out the trash", got, err)
+	}
+	if _, err := uc.CountTodoItems(ctx, testListIDTwo, "dddddddd-dddd-dddd-dddd-dddddddddddd"); !errors.Is(err, entity.ErrForbidden) {
+		t.Fatalf("CountTodoItems() by a stranger error = %v, want ErrForbidden", err)
+	}
+}
@@ -0,0 +1,75 @@
+package usecase
+
+import "strings"
+
+// positionAlphabet mirrors the base-62 alphabet used by the frontend's
+// fractional indexing helper so keys generated on either side sort together.
+const positionAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// initialPosition is the key given to the first item of an empty list.
+const initialPosition = "m"
+
+// positionAfter returns a key that sorts strictly after prev. The last
+// character that can still be incremented is bumped and anything after it is
+// dropped; if every character is already the maximum, the midpoint character
+// is appended instead.
+func positionAfter(prev string) string {
+	if prev == "" {
+		return initialPosition
+	}
+	for i := len(prev) - 1; i >= 0; i-- {
+		idx := indexOfPositionChar(prev[i])
+		if idx >= 0 && idx < len(positionAlphabet)-1 {
+			return prev[:i] + string(positionAlphabet[idx+1])
+		}
+	}
+	return prev + initialPosition
+}
+
+// lastPosition returns the greatest position among items, or "" when there
+// are none.
+func lastPosition(positions []string) string {
+	last := ""
+	for _, p := range positions {
+		if p > last {
+			last = p
+		}
+	}
+	return last
+}
+
+// evenlySpacedPositions returns n keys in increasing order, spread evenly
+// over keys of one fixed width with at least len(positionAlphabet) free keys
+// between neighbours, so items can be moved between any two of them many
+// times before keys grow longer. Trailing '0's are dropped, which keeps the
+// order and leaves no key that nothing can be placed before.
+func evenlySpacedPositions(n int) []string {
+	base := int64(len(positionAlphabet))
+	width, space := 1, base
+	for space/int64(n+1) < base {
+		width++
+		space *= base
+	}
+	step := space / int64(n+1)
+
+	positions := make([]string, n)
+	key := make([]byte, width)
+	for i := range positions {
+		value := int64(i+1) * step
+		for j := width - 1; j >= 0; j-- {
+			key[j] = positionAlphabet[value%base]
+			value /= base
+		}
+		positions[i] = strings.TrimRight(string(key), "0")
+	}
+	return positions
+}
+
+func indexOfPositionChar(c byte) int {
+	for i := 0; i < len(positionAlphabet); i++ {
+		if positionAlphabet[i] == c {
+			return i
+		}
+	}
+	return -1
+}
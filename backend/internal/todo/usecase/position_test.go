@@ -0,0 +1,61 @@
+package usecase
+
+import "testing"
+
+func TestPositionAfter(t *testing.T) {
+	tests := []struct {
+		prev string
+		want string
+	}{
+		{prev: "", want: "m"},
+		{prev: "m", want: "n"},
+		{prev: "9", want: "A"},
+		{prev: "Z", want: "a"},
+		{prev: "z", want: "zm"},
+		{prev: "az", want: "b"},
+		{prev: "zz", want: "zzm"},
+	}
+	for _, tt := range tests {
+		got := positionAfter(tt.prev)
+		if got != tt.want {
+			t.Fatalf("positionAfter(%q) = %q, want %q", tt.prev, got, tt.want)
+		}
+		if got <= tt.prev {
+			t.Fatalf("positionAfter(%q) = %q does not sort after input", tt.prev, got)
+		}
+	}
+}
+
+func TestPositionAfterKeepsAppendOrder(t *testing.T) {
+	position := lastPosition([]string{"a", "zy", "m"})
+	if position != "zy" {
+		t.Fatalf("lastPosition() = %q, want %q", position, "zy")
+	}
+	for i := 0; i < 200; i++ {
+		next := positionAfter(position)
+		if next <= position {
+			t.Fatalf("positionAfter(%q) = %q does not sort after input", position, next)
+		}
+		position = next
+	}
+}
+
+func TestEvenlySpacedPositions(t *testing.T) {
+	for _, n := range []int{1, 2, 61, 62, 1000} {
+		positions := evenlySpacedPositions(n)
+		if len(positions) != n {
+			t.Fatalf("evenlySpacedPositions(%d) returned %d keys", n, len(positions))
+		}
+		for i, position := range positions {
+			if position == "" || position[len(position)-1] == '0' {
+				t.Fatalf("evenlySpacedPositions(%d)[%d] = %q, want a non-empty key without a trailing 0", n, i, position)
+			}
+			if i > 0 && position <= positions[i-1] {
+				t.Fatalf("evenlySpacedPositions(%d)[%d] = %q does not sort after %q", n, i, position, positions[i-1])
+			}
+		}
+	}
+	if got := evenlySpacedPositions(2); got[0] != "Kf" || got[1] != "fK" {
+		t.Fatalf("evenlySpacedPositions(2) = %q, want [Kf fK]", got)
+	}
+}
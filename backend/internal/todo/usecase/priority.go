@@ -0,0 +1,28 @@
+package usecase
+
+import (
+	"fmt"
+	"slices"
+
+	"messenger/backend/internal/todo/entity"
+)
+
+// resolvePriority returns priority, or fallback when it is empty. Values
+// other than low, medium and high are rejected with entity.ErrInvalid.
+func resolvePriority(priority, fallback string) (string, error) {
+	if priority == "" {
+		return fallback, nil
+	}
+	if entity.PriorityRank(priority) == 0 {
+		return "", fmt.Errorf("%w: unknown priority %q", entity.ErrInvalid, priority)
+	}
+	return priority, nil
+}
+
+// SortTodoItemsByPriority orders items from high to low priority. The sort is
+// stable, so items of equal priority keep their position order.
+func SortTodoItemsByPriority(items []entity.TodoItem) {
+	slices.SortStableFunc(items, func(a, b entity.TodoItem) int {
+		return entity.PriorityRank(b.Priority) - entity.PriorityRank(a.Priority)
+	})
+}
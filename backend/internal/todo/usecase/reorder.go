@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"messenger/backend/internal/todo/entity"
+)
+
+// ReorderTodoItems puts the items of listID in the order of itemIDs, which
+// must name every item of the list exactly once, and gives them evenly spaced
+// positions. All items are rewritten in one transaction and each one's
+// version is incremented. The items are returned in their new order.
+func (uc *Usecase) ReorderTodoItems(ctx context.Context, listID string, userID string, itemIDs []string) ([]entity.TodoItem, error) {
+	order := make(map[string]int, len(itemIDs))
+	for i, id := range itemIDs {
+		if _, seen := order[id]; seen {
+			return nil, fmt.Errorf("%w: item %s is listed twice", entity.ErrInvalid, id)
+		}
+		order[id] = i
+	}
+
+	reordered := make([]entity.TodoItem, len(itemIDs))
+	err := uc.inTx(ctx, func(repos txRepos) error {
+		todoList, err := repos.lists.GetTodoListByIDForUpdate(ctx, listID)
+		if err != nil {
+			return fmt.Errorf("failed to get todo list by ID: %w", err)
+		}
+
+		if todoList.OwnerID != userID {
+			isCollab, err := repos.collabs.IsCollaborator(ctx, listID, userID)
+			if err != nil {
+				return fmt.Errorf("failed to check collaborator status: %w", err)
+			}
+			if !isCollab {
+				return fmt.Errorf("%w: user is not authorized to reorder items in this todo list", entity.ErrForbidden)
+			}
+		}
+
+		existing, err := repos.items.GetTodoItemsByListID(ctx, listID)
+		if err != nil {
+			return fmt.Errorf("failed to get todo items by list ID from repository: %w", err)
+		}
+		inList := make(map[string]bool, len(existing))
+		for _, item := range existing {
+			inList[item.ID] = true
+		}
+		for _, id := range itemIDs {
+			if !inList[id] {
+				return fmt.Errorf("%w: item %s does not belong to the list", entity.ErrInvalid, id)
+			}
+		}
+		for _, item := range existing {
+			i, ok := order[item.ID]
+			if !ok {
+				return fmt.Errorf("%w: item %s of the list is missing from the new order", entity.ErrConflict, item.ID)
+			}
+			reordered[i] = item
+		}
+
+		positions := evenlySpacedPositions(len(reordered))
+		for i := range reordered {
+			reordered[i].Position = positions[i]
+			reordered[i].Version++
+			if err := repos.items.UpdateTodoItem(ctx, &reordered[i]); err != nil {
+				return fmt.Errorf("failed to update todo item in repository: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range reordered {
+		uc.publishItem(EventItemUpdated, userID, item)
+	}
+	return reordered, nil
+}
@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"messenger/backend/internal/todo/entity"
+)
+
+func TestReorderTodoItems(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	ctx := context.Background()
+
+	var ids []string
+	for _, title := range []string{"Laundry", "Sweep"} {
+		item, err := uc.CreateTodoItem(ctx, testOwnerID, entity.TodoItem{ListID: testListIDTwo, Title: title})
+		if err != nil {
+			t.Fatalf("CreateTodoItem(%s) error = %v", title, err)
+		}
+		ids = append(ids, item.ID)
+	}
+	// The list now holds Dishes, Laundry, Sweep.
+	order := []string{ids[1], testItemID, ids[0]}
+
+	if _, err := uc.ReorderTodoItems(ctx, testListIDTwo, testOtherID, order); !errors.Is(err, entity.ErrForbidden) {
+		t.Fatalf("ReorderTodoItems() by a stranger error = %v, want ErrForbidden", err)
+	}
+	if _, err := uc.ReorderTodoItems(ctx, testListIDTwo, testOwnerID, []string{ids[1], testItemID, ids[1]}); !errors.Is(err, entity.ErrInvalid) {
+		t.Fatalf("ReorderTodoItems() with a repeated ID error = %v, want ErrInvalid", err)
+	}
+	if _, err := uc.ReorderTodoItems(ctx, testListIDTwo, testOwnerID, append(slices.Clone(order), "99999999-9999-9999-9999-999999999999")); !errors.Is(err, entity.ErrInvalid) {
+		t.Fatalf("ReorderTodoItems() with a foreign ID error = %v, want ErrInvalid", err)
+	}
+	if _, err := uc.ReorderTodoItems(ctx, testListIDTwo, testOwnerID, order[:2]); !errors.Is(err, entity.ErrConflict) {
+		t.Fatalf("ReorderTodoItems() leaving an item out error = %v, want ErrConflict", err)
+	}
+
+	reordered, err := uc.ReorderTodoItems(ctx, testListIDTwo, testOwnerID, order)
+	if err != nil {
+		t.Fatalf("ReorderTodoItems() error = %v", err)
+	}
+	if len(reordered) != 3 || reordered[0].Version != 2 {
+		t.Fatalf("ReorderTodoItems() = %+v, want 3 items at version 2", reordered)
+	}
+
+	items, err := uc.GetTodoItemsByList(ctx, testListIDTwo, testOwnerID)
+	if err != nil {
+		t.Fatalf("GetTodoItemsByList() error = %v", err)
+	}
+	var got []string
+	for _, item := range items {
+		got = append(got, item.Title)
+	}
+	if !slices.Equal(got, []string{"Sweep", "Dishes", "Laundry"}) {
+		t.Fatalf("items after reorder = %q, want [Sweep Dishes Laundry]", got)
+	}
+}
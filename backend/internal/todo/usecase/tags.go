@@ -0,0 +1,25 @@
+package usecase
+
+import "strings"
+
+// normalizeTag is the stored form of a tag: trimmed and lowercased.
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// normalizeTags normalizes tags, dropping blanks and duplicates while keeping
+// first-seen order. It never returns nil, so the result can replace an item's
+// tags directly.
+func normalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
@@ -0,0 +1,22 @@
+package usecase
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tests := []struct {
+		in   []string
+		want []string
+	}{
+		{in: nil, want: []string{}},
+		{in: []string{" Urgent ", "urgent", "URGENT"}, want: []string{"urgent"}},
+		{in: []string{"home", "  ", "Work", "home"}, want: []string{"home", "work"}},
+	}
+	for _, tt := range tests {
+		if got := normalizeTags(tt.in); !slices.Equal(got, tt.want) || got == nil {
+			t.Fatalf("normalizeTags(%q) = %#v, want %#v", tt.in, got, tt.want)
+		}
+	}
+}
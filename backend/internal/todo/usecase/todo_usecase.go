@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // TodoListUsecase defines the interface for todo list business logic.
@@ -37,21 +38,66 @@ type Usecase struct {
 	TodoListRepo       repository.TodoListRepository
 	TodoItemRepo       repository.TodoItemRepository
 	TodoListCollabRepo repository.TodoListCollaboratorRepository
+	TodoListInviteRepo repository.TodoListInviteRepository
+	Transactor         repository.Transactor
+	Events             *EventHub
+	Now                func() time.Time
+	// UserLocation returns the timezone a user's days are counted in. Nil
+	// means UTC for everyone.
+	UserLocation func(ctx context.Context, userID string) (*time.Location, error)
+	// MaxCollaborators caps how many collaborators a list may have, besides
+	// its owner. Zero means no limit.
+	MaxCollaborators int
 }
 
+// TrashRetention is how long a deleted todo item can still be restored before
+// it is purged for good.
+const TrashRetention = 30 * 24 * time.Hour
+
+// DefaultMaxCollaborators is the MaxCollaborators of a new Usecase.
+const DefaultMaxCollaborators = 50
+
 // NewUsecase creates a new Usecase.
 func NewUsecase(
 	todoListRepo repository.TodoListRepository,
 	todoItemRepo repository.TodoItemRepository,
 	todoListCollabRepo repository.TodoListCollaboratorRepository,
+	todoListInviteRepo repository.TodoListInviteRepository,
+	transactor repository.Transactor,
 ) *Usecase {
 	return &Usecase{
 		TodoListRepo:       todoListRepo,
 		TodoItemRepo:       todoItemRepo,
 		TodoListCollabRepo: todoListCollabRepo,
+		TodoListInviteRepo: todoListInviteRepo,
+		Transactor:         transactor,
+		Events:             NewEventHub(),
+		Now:                time.Now,
+		MaxCollaborators:   DefaultMaxCollaborators,
 	}
 }
 
+// txRepos holds repositories bound to one transaction.
+type txRepos struct {
+	lists   repository.TodoListRepository
+	items   repository.TodoItemRepository
+	collabs repository.TodoListCollaboratorRepository
+	invites repository.TodoListInviteRepository
+}
+
+// inTx runs fn with repositories bound to a single transaction, so the
+// authorization read and the mutation that follows it are applied atomically.
+func (uc *Usecase) inTx(ctx context.Context, fn func(repos txRepos) error) error {
+	return uc.Transactor.Transaction(ctx, func(tx *gorm.DB) error {
+		return fn(txRepos{
+			lists:   uc.TodoListRepo.WithTx(tx),
+			items:   uc.TodoItemRepo.WithTx(tx),
+			collabs: uc.TodoListCollabRepo.WithTx(tx),
+			invites: uc.TodoListInviteRepo.WithTx(tx),
+		})
+	})
+}
+
 // Implementations for TodoListUsecase
 func (uc *Usecase) CreateTodoList(ctx context.Context, title string, description string, userID string) (*entity.TodoList, error) {
 	todoList := &entity.TodoList{
@@ -80,12 +126,39 @@ func (uc *Usecase) GetTodoListByID(ctx context.Context, id string, userID string
 			return nil, fmt.Errorf("failed to check collaborator status: %w", err)
 		}
 		if !isCollab {
-			return nil, fmt.Errorf("user is not authorized to access this todo list")
+			return nil, fmt.Errorf("%w: user is not authorized to access this todo list", entity.ErrForbidden)
 		}
 	}
 	return todoList, nil
 }
 
+// GetTodoListWithStats is GetTodoListByID plus the list's item counts.
+func (uc *Usecase) GetTodoListWithStats(ctx context.Context, id string, userID string) (*entity.TodoList, entity.TodoListStats, error) {
+	todoList, err := uc.GetTodoListByID(ctx, id, userID)
+	if err != nil {
+		return nil, entity.TodoListStats{}, err
+	}
+	stats, err := uc.TodoListRepo.GetListStats(ctx, id)
+	if err != nil {
+		return nil, entity.TodoListStats{}, fmt.Errorf("failed to get todo list stats from repository: %w", err)
+	}
+	return todoList, stats, nil
+}
+
+// ExportTodoList returns a list together with all of its items, for callers
+// allowed to read the list.
+func (uc *Usecase) ExportTodoList(ctx context.Context, id string, userID string) (*entity.TodoList, []entity.TodoItem, error) {
+	todoList, err := uc.GetTodoListByID(ctx, id, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	items, err := uc.TodoItemRepo.GetTodoItemsByListID(ctx, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get todo items by list ID from repository: %w", err)
+	}
+	return todoList, items, nil
+}
+
 func (uc *Usecase) GetTodoListsByUser(ctx context.Context, userID string) ([]entity.TodoList, error) {
 	todoLists, err := uc.TodoListRepo.GetTodoListsByUserID(ctx, userID)
 	if err != nil {
@@ -94,94 +167,295 @@ func (uc *Usecase) GetTodoListsByUser(ctx context.Context, userID string) ([]ent
 	return todoLists, nil
 }
 
-func (uc *Usecase) UpdateTodoList(ctx context.Context, id string, title string, description string, userID string) (*entity.TodoList, error) {
-	todoList, err := uc.TodoListRepo.GetTodoListByID(ctx, id)
+// GetSharedTodoLists returns the lists other users have shared with userID.
+func (uc *Usecase) GetSharedTodoLists(ctx context.Context, userID string) ([]entity.SharedTodoList, error) {
+	sharedLists, err := uc.TodoListCollabRepo.GetSharedTodoLists(ctx, userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get todo list by ID for update: %w", err)
+		return nil, fmt.Errorf("failed to get shared todo lists from repository: %w", err)
 	}
+	return sharedLists, nil
+}
 
-	if todoList.OwnerID != userID {
-		isCollab, err := uc.TodoListCollabRepo.IsCollaborator(ctx, id, userID)
+func (uc *Usecase) UpdateTodoList(ctx context.Context, id string, title string, description string, userID string) (*entity.TodoList, error) {
+	var todoList *entity.TodoList
+	err := uc.inTx(ctx, func(repos txRepos) error {
+		var err error
+		todoList, err = repos.lists.GetTodoListByIDForUpdate(ctx, id)
 		if err != nil {
-			return nil, fmt.Errorf("failed to check collaborator status: %w", err)
+			return fmt.Errorf("failed to get todo list by ID for update: %w", err)
 		}
-		if !isCollab {
-			return nil, fmt.Errorf("user is not authorized to update this todo list")
+
+		if todoList.OwnerID != userID {
+			isCollab, err := repos.collabs.IsCollaborator(ctx, id, userID)
+			if err != nil {
+				return fmt.Errorf("failed to check collaborator status: %w", err)
+			}
+			if !isCollab {
+				return fmt.Errorf("%w: user is not authorized to update this todo list", entity.ErrForbidden)
+			}
 		}
-	}
 
-	todoList.Title = title
-	todoList.Description = description
+		todoList.Title = title
+		todoList.Description = description
 
-	err = uc.TodoListRepo.UpdateTodoList(ctx, todoList)
+		err = repos.lists.UpdateTodoList(ctx, todoList)
+		if err != nil {
+			return fmt.Errorf("failed to update todo list in repository: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to update todo list in repository: %w", err)
+		return nil, err
 	}
 	return todoList, nil
 }
 
 func (uc *Usecase) DeleteTodoList(ctx context.Context, id string, userID string) error {
-	todoList, err := uc.TodoListRepo.GetTodoListByID(ctx, id)
-	if err != nil {
-		return fmt.Errorf("failed to get todo list by ID for deletion: %w", err)
-	}
+	err := uc.inTx(ctx, func(repos txRepos) error {
+		todoList, err := repos.lists.GetTodoListByIDForUpdate(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get todo list by ID for deletion: %w", err)
+		}
 
-	if todoList.OwnerID != userID {
-		return fmt.Errorf("user is not authorized to delete this todo list")
-	}
+		if todoList.OwnerID != userID {
+			return fmt.Errorf("%w: user is not authorized to delete this todo list", entity.ErrForbidden)
+		}
 
-	err = uc.TodoListRepo.DeleteTodoList(ctx, id)
+		err = repos.lists.DeleteTodoList(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to delete todo list from repository: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to delete todo list from repository: %w", err)
+		return err
 	}
+	uc.Events.Publish(Event{Type: EventListDeleted, ListID: id, ActorID: userID})
 	return nil
 }
 
 func (uc *Usecase) AddCollaborator(ctx context.Context, todoListID, collaboratorID, requestingUserID string) error {
-	todoList, err := uc.TodoListRepo.GetTodoListByID(ctx, todoListID)
-	if err != nil {
-		return fmt.Errorf("failed to get todo list by ID: %w", err)
-	}
+	return uc.inTx(ctx, func(repos txRepos) error {
+		todoList, err := repos.lists.GetTodoListByIDForUpdate(ctx, todoListID)
+		if err != nil {
+			return fmt.Errorf("failed to get todo list by ID: %w", err)
+		}
 
-	if todoList.OwnerID != requestingUserID {
-		return fmt.Errorf("user is not authorized to add collaborators to this todo list")
-	}
+		if todoList.OwnerID != requestingUserID {
+			return fmt.Errorf("%w: user is not authorized to add collaborators to this todo list", entity.ErrForbidden)
+		}
 
-	isCollab, err := uc.TodoListCollabRepo.IsCollaborator(ctx, todoListID, collaboratorID)
-	if err != nil {
-		return fmt.Errorf("failed to check if user is already a collaborator: %w", err)
-	}
-	if isCollab {
-		return fmt.Errorf("user is already a collaborator")
-	}
+		isCollab, err := repos.collabs.IsCollaborator(ctx, todoListID, collaboratorID)
+		if err != nil {
+			return fmt.Errorf("failed to check if user is already a collaborator: %w", err)
+		}
+		if isCollab {
+			return fmt.Errorf("%w: user is already a collaborator", entity.ErrConflict)
+		}
+		if err := uc.checkCollaboratorLimit(ctx, repos, todoListID); err != nil {
+			return err
+		}
 
-	collaborator := &entity.TodoListCollaborator{
-		TodoListID:     todoListID,
-		CollaboratorID: collaboratorID,
-	}
+		collaborator := &entity.TodoListCollaborator{
+			TodoListID:     todoListID,
+			CollaboratorID: collaboratorID,
+		}
+
+		err = repos.collabs.AddCollaborator(ctx, collaborator)
+		if err != nil {
+			return fmt.Errorf("failed to add collaborator to repository: %w", err)
+		}
+		return nil
+	})
+}
 
-	err = uc.TodoListCollabRepo.AddCollaborator(ctx, collaborator)
+// checkCollaboratorLimit fails with entity.ErrConflict when the list already
+// has MaxCollaborators collaborators. Callers must hold the list's row lock so
+// that concurrent adds cannot both pass the check.
+func (uc *Usecase) checkCollaboratorLimit(ctx context.Context, repos txRepos, todoListID string) error {
+	if uc.MaxCollaborators <= 0 {
+		return nil
+	}
+	count, err := repos.collabs.CountCollaborators(ctx, todoListID)
 	if err != nil {
-		return fmt.Errorf("failed to add collaborator to repository: %w", err)
+		return fmt.Errorf("failed to count collaborators: %w", err)
+	}
+	if count >= int64(uc.MaxCollaborators) {
+		return fmt.Errorf("%w: todo list already has the maximum of %d collaborators", entity.ErrConflict, uc.MaxCollaborators)
 	}
 	return nil
 }
 
+// RemoveCollaborator takes collaboratorID off the list. The owner may remove
+// anyone but themselves; a collaborator may only remove themselves, which is
+// how they leave a shared list.
 func (uc *Usecase) RemoveCollaborator(ctx context.Context, todoListID, collaboratorID, requestingUserID string) error {
-	todoList, err := uc.TodoListRepo.GetTodoListByID(ctx, todoListID)
+	return uc.inTx(ctx, func(repos txRepos) error {
+		todoList, err := repos.lists.GetTodoListByIDForUpdate(ctx, todoListID)
+		if err != nil {
+			return fmt.Errorf("failed to get todo list by ID: %w", err)
+		}
+
+		if todoList.OwnerID != requestingUserID {
+			// Collaborators may remove only themselves, i.e. leave the list.
+			if collaboratorID != requestingUserID {
+				return fmt.Errorf("%w: user is not authorized to remove collaborators from this todo list", entity.ErrForbidden)
+			}
+			isCollab, err := repos.collabs.IsCollaborator(ctx, todoListID, requestingUserID)
+			if err != nil {
+				return fmt.Errorf("failed to check collaborator status: %w", err)
+			}
+			if !isCollab {
+				return fmt.Errorf("%w: user is not a collaborator on this todo list", entity.ErrForbidden)
+			}
+		}
+		if collaboratorID == todoList.OwnerID {
+			return fmt.Errorf("%w: the owner cannot leave their own todo list; transfer or delete it instead", entity.ErrConflict)
+		}
+
+		err = repos.collabs.RemoveCollaborator(ctx, todoListID, collaboratorID)
+		if err != nil {
+			return fmt.Errorf("failed to remove collaborator from repository: %w", err)
+		}
+		return nil
+	})
+}
+
+// TransferTodoList makes newOwnerID, who must already collaborate on the
+// list, its owner. Only the current owner may do this; with
+// keepAsCollaborator they stay on the list as a collaborator, otherwise they
+// lose access to it.
+func (uc *Usecase) TransferTodoList(ctx context.Context, todoListID, newOwnerID, requestingUserID string, keepAsCollaborator bool) (*entity.TodoList, error) {
+	var todoList *entity.TodoList
+	err := uc.inTx(ctx, func(repos txRepos) error {
+		var err error
+		todoList, err = repos.lists.GetTodoListByIDForUpdate(ctx, todoListID)
+		if err != nil {
+			return fmt.Errorf("failed to get todo list by ID: %w", err)
+		}
+
+		if todoList.OwnerID != requestingUserID {
+			return fmt.Errorf("%w: only the owner can transfer this todo list", entity.ErrForbidden)
+		}
+		if newOwnerID == requestingUserID {
+			return fmt.Errorf("%w: user already owns this todo list", entity.ErrInvalid)
+		}
+
+		isCollab, err := repos.collabs.IsCollaborator(ctx, todoListID, newOwnerID)
+		if err != nil {
+			return fmt.Errorf("failed to check collaborator status: %w", err)
+		}
+		if !isCollab {
+			return fmt.Errorf("%w: the new owner must be a collaborator on this todo list", entity.ErrInvalid)
+		}
+
+		if err := repos.collabs.RemoveCollaborator(ctx, todoListID, newOwnerID); err != nil {
+			return fmt.Errorf("failed to remove new owner from collaborators: %w", err)
+		}
+		if keepAsCollaborator {
+			err = repos.collabs.AddCollaborator(ctx, &entity.TodoListCollaborator{
+				TodoListID:     todoListID,
+				CollaboratorID: requestingUserID,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to add previous owner as collaborator: %w", err)
+			}
+		}
+
+		todoList.OwnerID = newOwnerID
+		if err := repos.lists.UpdateTodoList(ctx, todoList); err != nil {
+			return fmt.Errorf("failed to update todo list owner in repository: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to get todo list by ID: %w", err)
+		return nil, err
 	}
+	return todoList, nil
+}
 
-	if todoList.OwnerID != requestingUserID {
-		return fmt.Errorf("user is not authorized to remove collaborators from this todo list")
-	}
+// cloneTitleSuffix is appended to the title of a cloned list.
+const cloneTitleSuffix = " Copy"
+
+// CloneTodoList copies a list userID can read into a new list owned by
+// userID, titled with a " Copy" suffix. Items keep their text, deadline,
+// priority and tags but start out incomplete, in the source order with fresh
+// positions. Collaborators are not copied.
+func (uc *Usecase) CloneTodoList(ctx context.Context, sourceID string, userID string) (*entity.TodoList, []entity.TodoItem, error) {
+	var clone *entity.TodoList
+	var items []entity.TodoItem
+	err := uc.inTx(ctx, func(repos txRepos) error {
+		source, err := repos.lists.GetTodoListByID(ctx, sourceID)
+		if err != nil {
+			return fmt.Errorf("failed to get todo list by ID: %w", err)
+		}
+		if source.OwnerID != userID {
+			isCollab, err := repos.collabs.IsCollaborator(ctx, sourceID, userID)
+			if err != nil {
+				return fmt.Errorf("failed to check collaborator status: %w", err)
+			}
+			if !isCollab {
+				return fmt.Errorf("%w: user is not authorized to access this todo list", entity.ErrForbidden)
+			}
+		}
 
-	err = uc.TodoListCollabRepo.RemoveCollaborator(ctx, todoListID, collaboratorID)
+		clone = &entity.TodoList{
+			ID:          uuid.New().String(),
+			OwnerID:     userID,
+			Title:       source.Title + cloneTitleSuffix,
+			Description: source.Description,
+		}
+		if err := repos.lists.CreateTodoList(ctx, clone); err != nil {
+			return fmt.Errorf("failed to create todo list in repository: %w", err)
+		}
+
+		sourceItems, err := repos.items.GetTodoItemsByListID(ctx, sourceID)
+		if err != nil {
+			return fmt.Errorf("failed to get todo items by list ID from repository: %w", err)
+		}
+		if len(sourceItems) == 0 {
+			items = []entity.TodoItem{}
+			return nil
+		}
+		items = make([]entity.TodoItem, len(sourceItems))
+		position := ""
+		for i, item := range sourceItems {
+			position = positionAfter(position)
+			items[i] = entity.TodoItem{
+				ID:          uuid.New().String(),
+				ListID:      clone.ID,
+				Position:    position,
+				Title:       item.Title,
+				Description: item.Description,
+				Deadline:    item.Deadline,
+				Priority:    item.Priority,
+				Version:     1,
+				CreatedBy:   &userID,
+				Tags:        item.Tags,
+			}
+		}
+		if err := repos.items.CreateTodoItems(ctx, items); err != nil {
+			return fmt.Errorf("failed to create todo items in repository: %w", err)
+		}
+		for _, item := range items {
+			if len(item.Tags) == 0 {
+				continue
+			}
+			if err := repos.items.SetTodoItemTags(ctx, item.ID, item.Tags); err != nil {
+				return fmt.Errorf("failed to set todo item tags: %w", err)
+			}
+		}
+		// Read the copies back for timestamps and the creator's username.
+		items, err = repos.items.GetTodoItemsByListID(ctx, clone.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get cloned todo items: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to remove collaborator from repository: %w", err)
+		return nil, nil, err
 	}
-	return nil
+	return clone, items, nil
 }
 
 func (uc *Usecase) GetCollaboratorDetails(ctx context.Context, todoListID string, requestingUserID string) ([]entity.TodoListCollaboratorDetail, error) {
@@ -196,7 +470,7 @@ func (uc *Usecase) GetCollaboratorDetails(ctx context.Context, todoListID string
 			return nil, fmt.Errorf("failed to check collaborator status: %w", err)
 		}
 		if !isCollab {
-			return nil, fmt.Errorf("user is not authorized to view collaborators for this todo list")
+			return nil, fmt.Errorf("%w: user is not authorized to view collaborators for this todo list", entity.ErrForbidden)
 		}
 	}
 
@@ -209,30 +483,140 @@ func (uc *Usecase) GetCollaboratorDetails(ctx context.Context, todoListID string
 
 // Implementations for TodoItemUsecase
 func (uc *Usecase) CreateTodoItem(ctx context.Context, userID string, newItem entity.TodoItem) (*entity.TodoItem, error) {
-	todoList, err := uc.TodoListRepo.GetTodoListByID(ctx, newItem.ListID)
+	priority, err := resolvePriority(newItem.Priority, entity.PriorityMedium)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get todo list by ID: %w", err)
+		return nil, err
 	}
+	newItem.Priority = priority
 
-	if todoList.OwnerID != userID {
-		isCollab, err := uc.TodoListCollabRepo.IsCollaborator(ctx, newItem.ListID, userID)
+	err = uc.inTx(ctx, func(repos txRepos) error {
+		todoList, err := repos.lists.GetTodoListByIDForUpdate(ctx, newItem.ListID)
 		if err != nil {
-			return nil, fmt.Errorf("failed to check collaborator status: %w", err)
+			return fmt.Errorf("failed to get todo list by ID: %w", err)
 		}
-		if !isCollab {
-			return nil, fmt.Errorf("user is not authorized to create items in this todo list")
+
+		if todoList.OwnerID != userID {
+			isCollab, err := repos.collabs.IsCollaborator(ctx, newItem.ListID, userID)
+			if err != nil {
+				return fmt.Errorf("failed to check collaborator status: %w", err)
+			}
+			if !isCollab {
+				return fmt.Errorf("%w: user is not authorized to create items in this todo list", entity.ErrForbidden)
+			}
 		}
-	}
 
-	newItem.ID = uuid.New().String()
+		newItem.ID = uuid.New().String()
+		newItem.CreatedBy = &userID
+		newItem.Version = 1
 
-	err = uc.TodoItemRepo.CreateTodoItem(ctx, &newItem)
+		err = repos.items.CreateTodoItem(ctx, &newItem)
+		if err != nil {
+			return fmt.Errorf("failed to create todo item in repository: %w", err)
+		}
+		if tags := normalizeTags(newItem.Tags); len(tags) > 0 {
+			if err := repos.items.SetTodoItemTags(ctx, newItem.ID, tags); err != nil {
+				return fmt.Errorf("failed to set todo item tags: %w", err)
+			}
+		}
+		// Read it back for the creator's username and the stored tags.
+		created, err := repos.items.GetTodoItemByID(ctx, newItem.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get created todo item: %w", err)
+		}
+		newItem = *created
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create todo item in repository: %w", err)
+		return nil, err
 	}
+	uc.publishItem(EventItemCreated, userID, newItem)
 	return &newItem, nil
 }
 
+// CreateTodoItems creates all items in listID at once, appending them after the
+// current last item in the order given. Client-supplied positions are ignored.
+func (uc *Usecase) CreateTodoItems(ctx context.Context, userID string, listID string, newItems []entity.TodoItem) ([]entity.TodoItem, error) {
+	items := make([]entity.TodoItem, len(newItems))
+	priorities := make([]string, len(newItems))
+	for i, newItem := range newItems {
+		priority, err := resolvePriority(newItem.Priority, entity.PriorityMedium)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+		priorities[i] = priority
+	}
+
+	err := uc.inTx(ctx, func(repos txRepos) error {
+		todoList, err := repos.lists.GetTodoListByIDForUpdate(ctx, listID)
+		if err != nil {
+			return fmt.Errorf("failed to get todo list by ID: %w", err)
+		}
+
+		if todoList.OwnerID != userID {
+			isCollab, err := repos.collabs.IsCollaborator(ctx, listID, userID)
+			if err != nil {
+				return fmt.Errorf("failed to check collaborator status: %w", err)
+			}
+			if !isCollab {
+				return fmt.Errorf("%w: user is not authorized to create items in this todo list", entity.ErrForbidden)
+			}
+		}
+
+		existing, err := repos.items.GetTodoItemsByListID(ctx, listID)
+		if err != nil {
+			return fmt.Errorf("failed to get todo items by list ID from repository: %w", err)
+		}
+		positions := make([]string, len(existing))
+		for i, item := range existing {
+			positions[i] = item.Position
+		}
+		position := lastPosition(positions)
+
+		for i, newItem := range newItems {
+			position = positionAfter(position)
+			newItem.ID = uuid.New().String()
+			newItem.ListID = listID
+			newItem.Position = position
+			newItem.CreatedBy = &userID
+			newItem.Tags = normalizeTags(newItem.Tags)
+			newItem.Priority = priorities[i]
+			newItem.Version = 1
+			items[i] = newItem
+		}
+
+		err = repos.items.CreateTodoItems(ctx, items)
+		if err != nil {
+			return fmt.Errorf("failed to create todo items in repository: %w", err)
+		}
+		for _, item := range items {
+			if len(item.Tags) == 0 {
+				continue
+			}
+			if err := repos.items.SetTodoItemTags(ctx, item.ID, item.Tags); err != nil {
+				return fmt.Errorf("failed to set todo item tags: %w", err)
+			}
+		}
+		if len(items) > 0 {
+			// All items share the creator, so one read resolves the username.
+			created, err := repos.items.GetTodoItemByID(ctx, items[0].ID)
+			if err != nil {
+				return fmt.Errorf("failed to get created todo item: %w", err)
+			}
+			for i := range items {
+				items[i].CreatedByUsername = created.CreatedByUsername
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		uc.publishItem(EventItemCreated, userID, item)
+	}
+	return items, nil
+}
+
 func (uc *Usecase) GetTodoItemByID(ctx context.Context, id string, listID string, userID string) (*entity.TodoItem, error) {
 	todoList, err := uc.TodoListRepo.GetTodoListByID(ctx, listID)
 	if err != nil {
@@ -245,7 +629,7 @@ func (uc *Usecase) GetTodoItemByID(ctx context.Context, id string, listID string
 			return nil, fmt.Errorf("failed to check collaborator status: %w", err)
 		}
 		if !isCollab {
-			return nil, fmt.Errorf("user is not authorized to access items in this todo list")
+			return nil, fmt.Errorf("%w: user is not authorized to access items in this todo list", entity.ErrForbidden)
 		}
 	}
 
@@ -253,23 +637,15 @@ func (uc *Usecase) GetTodoItemByID(ctx context.Context, id string, listID string
 	if err != nil {
 		return nil, fmt.Errorf("failed to get todo item by ID from repository: %w", err)
 	}
+	if todoItem.ListID != listID {
+		return nil, fmt.Errorf("%w: todo item does not belong to the specified list", entity.ErrNotFound)
+	}
 	return todoItem, nil
 }
 
 func (uc *Usecase) GetTodoItemsByList(ctx context.Context, listID string, userID string) ([]entity.TodoItem, error) {
-	todoList, err := uc.TodoListRepo.GetTodoListByID(ctx, listID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get todo list by ID: %w", err)
-	}
-
-	if todoList.OwnerID != userID {
-		isCollab, err := uc.TodoListCollabRepo.IsCollaborator(ctx, listID, userID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to check collaborator status: %w", err)
-		}
-		if !isCollab {
-			return nil, fmt.Errorf("user is not authorized to access items in this todo list")
-		}
+	if err := uc.checkItemAccess(ctx, listID, userID); err != nil {
+		return nil, err
 	}
 
 	todoItems, err := uc.TodoItemRepo.GetTodoItemsByListID(ctx, listID)
@@ -279,67 +655,248 @@ func (uc *Usecase) GetTodoItemsByList(ctx context.Context, listID string, userID
 	return todoItems, nil
 }
 
-func (uc *Usecase) UpdateTodoItem(ctx context.Context, id string, listID string, userID string, newItem *entity.TodoItem) (*entity.TodoItem, error) {
+// StreamTodoItemsByList calls fn for each item of the list in position order
+// without loading the whole list into memory. Access is checked before fn is
+// first called, so an ErrNotFound or ErrForbidden always comes before any
+// item.
+func (uc *Usecase) StreamTodoItemsByList(ctx context.Context, listID string, userID string, fn func(*entity.TodoItem) error) error {
+	if err := uc.checkItemAccess(ctx, listID, userID); err != nil {
+		return err
+	}
+	return uc.TodoItemRepo.StreamTodoItemsByListID(ctx, listID, fn)
+}
+
+// checkItemAccess returns ErrForbidden unless userID owns or collaborates on
+// the list.
+func (uc *Usecase) checkItemAccess(ctx context.Context, listID string, userID string) error {
 	todoList, err := uc.TodoListRepo.GetTodoListByID(ctx, listID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get todo list by ID: %w", err)
+		return fmt.Errorf("failed to get todo list by ID: %w", err)
 	}
 
 	if todoList.OwnerID != userID {
 		isCollab, err := uc.TodoListCollabRepo.IsCollaborator(ctx, listID, userID)
 		if err != nil {
-			return nil, fmt.Errorf("failed to check collaborator status: %w", err)
+			return fmt.Errorf("failed to check collaborator status: %w", err)
 		}
 		if !isCollab {
-			return nil, fmt.Errorf("user is not authorized to update items in this todo list")
+			return fmt.Errorf("%w: user is not authorized to access items in this todo list", entity.ErrForbidden)
 		}
 	}
+	return nil
+}
 
-	todoItem, err := uc.TodoItemRepo.GetTodoItemByID(ctx, id)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get todo item by ID for update: %w", err)
+// GetTodoItemsByTag returns the items tagged with tag across every list
+// userID owns or collaborates on.
+func (uc *Usecase) GetTodoItemsByTag(ctx context.Context, userID string, tag string) ([]entity.TodoItem, error) {
+	tag = normalizeTag(tag)
+	if tag == "" {
+		return []entity.TodoItem{}, nil
 	}
-	if todoItem.ListID != listID {
-		return nil, fmt.Errorf("todo item does not belong to the specified list")
+	todoItems, err := uc.TodoItemRepo.GetTodoItemsByTag(ctx, userID, tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get todo items by tag from repository: %w", err)
 	}
+	return todoItems, nil
+}
 
-	todoItem = &entity.TodoItem{
-		ID: 		todoItem.ID,
-		ListID: 	todoItem.ListID,
-		
-		Title: 		newItem.Title,
-		Description: newItem.Description,
-		Deadline: 	newItem.Deadline,
-		Completed: 	newItem.Completed,
-		Position: 	newItem.Position,
+// GetDeadlineCalendar returns the items with a deadline across every list
+// userID owns or collaborates on, together with the titles of those lists
+// keyed by list ID.
+func (uc *Usecase) GetDeadlineCalendar(ctx context.Context, userID string) ([]entity.TodoItem, map[string]string, error) {
+	todoItems, err := uc.TodoItemRepo.GetTodoItemsWithDeadline(ctx, userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get todo items with deadline from repository: %w", err)
+	}
+	todoLists, err := uc.TodoListRepo.GetTodoListsByUserID(ctx, userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get todo lists by user ID from repository: %w", err)
 	}
+	listTitles := make(map[string]string, len(todoLists))
+	for _, list := range todoLists {
+		listTitles[list.ID] = list.Title
+	}
+	return todoItems, listTitles, nil
+}
+
+func (uc *Usecase) UpdateTodoItem(ctx context.Context, id string, listID string, userID string, newItem *entity.TodoItem) (*entity.TodoItem, error) {
+	var todoItem *entity.TodoItem
+	err := uc.inTx(ctx, func(repos txRepos) error {
+		todoList, err := repos.lists.GetTodoListByIDForUpdate(ctx, listID)
+		if err != nil {
+			return fmt.Errorf("failed to get todo list by ID: %w", err)
+		}
+
+		if todoList.OwnerID != userID {
+			isCollab, err := repos.collabs.IsCollaborator(ctx, listID, userID)
+			if err != nil {
+				return fmt.Errorf("failed to check collaborator status: %w", err)
+			}
+			if !isCollab {
+				return fmt.Errorf("%w: user is not authorized to update items in this todo list", entity.ErrForbidden)
+			}
+		}
+
+		todoItem, err = repos.items.GetTodoItemByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get todo item by ID for update: %w", err)
+		}
+		if todoItem.ListID != listID {
+			return fmt.Errorf("%w: todo item does not belong to the specified list", entity.ErrNotFound)
+		}
+		// The list row lock above serializes updates, so comparing here is
+		// enough to turn a lost update into a conflict.
+		if newItem.Version != todoItem.Version {
+			return fmt.Errorf("%w: todo item is at version %d, not %d", entity.ErrConflict, todoItem.Version, newItem.Version)
+		}
 
-	err = uc.TodoItemRepo.UpdateTodoItem(ctx, todoItem)
+		tags := todoItem.Tags
+		priority, err := resolvePriority(newItem.Priority, todoItem.Priority)
+		if err != nil {
+			return err
+		}
+		todoItem = &entity.TodoItem{
+			ID: 		todoItem.ID,
+			ListID: 	todoItem.ListID,
+			CreatedBy: 	todoItem.CreatedBy,
+			CreatedByUsername: todoItem.CreatedByUsername,
+			
+			Title: 		newItem.Title,
+			Description: newItem.Description,
+			Deadline: 	newItem.Deadline,
+			Completed: 	newItem.Completed,
+			Priority: 	priority,
+			Position: 	newItem.Position,
+			Version: 	todoItem.Version + 1,
+		}
+
+		err = repos.items.UpdateTodoItem(ctx, todoItem)
+		if err != nil {
+			return fmt.Errorf("failed to update todo item in repository: %w", err)
+		}
+		// Tags are only replaced when the request carries them.
+		if newItem.Tags != nil {
+			tags = normalizeTags(newItem.Tags)
+			if err := repos.items.SetTodoItemTags(ctx, todoItem.ID, tags); err != nil {
+				return fmt.Errorf("failed to set todo item tags: %w", err)
+			}
+		}
+		todoItem.Tags = tags
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to update todo item in repository: %w", err)
+		return nil, err
 	}
+	uc.publishItem(EventItemUpdated, userID, *todoItem)
 	return todoItem, nil
 }
 
 func (uc *Usecase) DeleteTodoItem(ctx context.Context, id string, listID string, userID string) error {
-	todoList, err := uc.TodoListRepo.GetTodoListByID(ctx, listID)
+	err := uc.inTx(ctx, func(repos txRepos) error {
+		todoList, err := repos.lists.GetTodoListByIDForUpdate(ctx, listID)
+		if err != nil {
+			return fmt.Errorf("failed to get todo list by ID: %w", err)
+		}
+
+		if todoList.OwnerID != userID {
+			isCollab, err := repos.collabs.IsCollaborator(ctx, listID, userID)
+			if err != nil {
+				return fmt.Errorf("failed to check collaborator status: %w", err)
+			}
+			if !isCollab {
+				return fmt.Errorf("%w: user is not authorized to delete items from this todo list", entity.ErrForbidden)
+			}
+		}
+
+		todoItem, err := repos.items.GetTodoItemByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get todo item by ID for delete: %w", err)
+		}
+		if todoItem.ListID != listID {
+			return fmt.Errorf("%w: todo item does not belong to the specified list", entity.ErrNotFound)
+		}
+
+		err = repos.items.DeleteTodoItem(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to delete todo item from repository: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to get todo list by ID: %w", err)
+		return err
 	}
+	uc.Events.Publish(Event{Type: EventItemDeleted, ListID: listID, ActorID: userID, ItemID: id})
+	return nil
+}
 
-	if todoList.OwnerID != userID {
-		isCollab, err := uc.TodoListCollabRepo.IsCollaborator(ctx, listID, userID)
+// RestoreTodoItem moves a deleted item out of the trash, provided it was
+// deleted less than TrashRetention ago.
+func (uc *Usecase) RestoreTodoItem(ctx context.Context, id string, listID string, userID string) (*entity.TodoItem, error) {
+	var todoItem *entity.TodoItem
+	err := uc.inTx(ctx, func(repos txRepos) error {
+		todoList, err := repos.lists.GetTodoListByIDForUpdate(ctx, listID)
 		if err != nil {
-			return fmt.Errorf("failed to check collaborator status: %w", err)
+			return fmt.Errorf("failed to get todo list by ID: %w", err)
 		}
-		if !isCollab {
-			return fmt.Errorf("user is not authorized to delete items from this todo list")
+
+		if todoList.OwnerID != userID {
+			isCollab, err := repos.collabs.IsCollaborator(ctx, listID, userID)
+			if err != nil {
+				return fmt.Errorf("failed to check collaborator status: %w", err)
+			}
+			if !isCollab {
+				return fmt.Errorf("%w: user is not authorized to restore items in this todo list", entity.ErrForbidden)
+			}
+		}
+
+		todoItem, err = repos.items.GetDeletedTodoItemByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get deleted todo item by ID: %w", err)
 		}
+		if todoItem.ListID != listID {
+			return fmt.Errorf("%w: todo item does not belong to the specified list", entity.ErrNotFound)
+		}
+		if todoItem.DeletedAt.Time.Before(uc.Now().Add(-TrashRetention)) {
+			return fmt.Errorf("deleted todo item %w: restore window has expired", entity.ErrNotFound)
+		}
+
+		err = repos.items.RestoreTodoItem(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to restore todo item in repository: %w", err)
+		}
+		todoItem.DeletedAt = gorm.DeletedAt{}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// To other clients a restored item simply reappears.
+	uc.publishItem(EventItemCreated, userID, *todoItem)
+	return todoItem, nil
+}
+
+// SubscribeTodoListEvents checks that userID may read listID and subscribes
+// to its change events. See EventHub.Subscribe for the channel's lifecycle.
+func (uc *Usecase) SubscribeTodoListEvents(ctx context.Context, listID string, userID string) (<-chan Event, func(), error) {
+	if _, err := uc.GetTodoListByID(ctx, listID, userID); err != nil {
+		return nil, nil, err
 	}
+	events, unsubscribe := uc.Events.Subscribe(listID)
+	return events, unsubscribe, nil
+}
 
-	err = uc.TodoItemRepo.DeleteTodoItem(ctx, id)
+// publishItem announces a committed change to item. The item is copied so
+// subscribers never share it with the caller.
+func (uc *Usecase) publishItem(eventType EventType, userID string, item entity.TodoItem) {
+	uc.Events.Publish(Event{Type: eventType, ListID: item.ListID, ActorID: userID, ItemID: item.ID, Item: &item})
+}
+
+// PurgeExpiredTodoItems permanently removes items that have been in the trash
+// for longer than TrashRetention.
+func (uc *Usecase) PurgeExpiredTodoItems(ctx context.Context) (int64, error) {
+	purged, err := uc.TodoItemRepo.PurgeDeletedTodoItems(ctx, uc.Now().Add(-TrashRetention))
 	if err != nil {
-		return fmt.Errorf("failed to delete todo item from repository: %w", err)
+		return 0, fmt.Errorf("failed to purge expired todo items: %w", err)
 	}
-	return nil
+	return purged, nil
 }
@@ -0,0 +1,751 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"messenger/backend/internal/todo/entity"
+	"messenger/backend/internal/todo/repository"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+const (
+	testOwnerID   = "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa"
+	testOtherID   = "bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb"
+	testListID    = "11111111-1111-1111-1111-111111111111"
+	testListIDTwo = "22222222-2222-2222-2222-222222222222"
+	testItemID    = "33333333-3333-3333-3333-333333333333"
+)
+
+func newTestUsecase(t *testing.T) (*Usecase, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("db.DB() error = %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	for _, statement := range []string{
+		`CREATE TABLE todo_lists (
+			id TEXT PRIMARY KEY,
+			title TEXT NOT NULL,
+			description TEXT NOT NULL,
+			owner_id TEXT NOT NULL,
+			created_at DATETIME,
+			updated_at DATETIME
+		)`,
+		`CREATE TABLE todo_items (
+			id TEXT PRIMARY KEY,
+			list_id TEXT NOT NULL,
+			position TEXT NOT NULL,
+			title TEXT NOT NULL,
+			description TEXT NOT NULL,
+			deadline DATETIME,
+			completed BOOLEAN,
+			priority TEXT NOT NULL DEFAULT 'medium',
+			version INTEGER NOT NULL DEFAULT 1,
+			created_by TEXT,
+			created_at DATETIME,
+			updated_at DATETIME,
+			deleted_at DATETIME
+		)`,
+		`CREATE TABLE todo_item_tags (
+			item_id TEXT NOT NULL,
+			tag TEXT NOT NULL,
+			PRIMARY KEY (item_id, tag)
+		)`,
+		`CREATE TABLE users (
+			id TEXT PRIMARY KEY,
+			username TEXT NOT NULL DEFAULT ''
+		)`,
+		`CREATE TABLE todo_list_invites (
+			token_hash TEXT PRIMARY KEY,
+			todo_list_id TEXT NOT NULL,
+			created_by TEXT NOT NULL,
+			single_use BOOLEAN NOT NULL DEFAULT 1,
+			expires_at DATETIME NOT NULL,
+			used_at DATETIME,
+			used_by TEXT,
+			created_at DATETIME
+		)`,
+		`CREATE TABLE todo_list_collaborators (
+			todo_list_id TEXT NOT NULL,
+			collaborator_id TEXT NOT NULL,
+			created_at DATETIME,
+			updated_at DATETIME,
+			PRIMARY KEY (todo_list_id, collaborator_id)
+		)`,
+	} {
+		if err := db.Exec(statement).Error; err != nil {
+			t.Fatalf("Exec(%q) error = %v", statement, err)
+		}
+	}
+
+	lists := []entity.TodoList{
+		{ID: testListID, Title: "Groceries", OwnerID: testOwnerID},
+		{ID: testListIDTwo, Title: "Chores", OwnerID: testOwnerID},
+	}
+	if err := db.Create(&lists).Error; err != nil {
+		t.Fatalf("Create(lists) error = %v", err)
+	}
+	item := entity.TodoItem{ID: testItemID, ListID: testListIDTwo, Position: "m", Title: "Dishes"}
+	if err := db.Create(&item).Error; err != nil {
+		t.Fatalf("Create(item) error = %v", err)
+	}
+
+	uc := NewUsecase(
+		repository.NewTodoListRepository(db),
+		repository.NewTodoItemRepository(db),
+		repository.NewTodoListCollaboratorRepository(db),
+		repository.NewTodoListInviteRepository(db),
+		repository.NewTransactor(db),
+	)
+	return uc, db
+}
+
+func countCollaborators(t *testing.T, db *gorm.DB) int64 {
+	t.Helper()
+
+	var count int64
+	if err := db.Model(&entity.TodoListCollaborator{}).Count(&count).Error; err != nil {
+		t.Fatalf("Count(collaborators) error = %v", err)
+	}
+	return count
+}
+
+func TestAddCollaboratorAuthorizesThenMutatesInTransaction(t *testing.T) {
+	uc, db := newTestUsecase(t)
+	ctx := context.Background()
+
+	err := uc.AddCollaborator(ctx, testListID, testOtherID, testOtherID)
+	if !errors.Is(err, entity.ErrForbidden) {
+		t.Fatalf("AddCollaborator() by non-owner error = %v, want ErrForbidden", err)
+	}
+	if got := countCollaborators(t, db); got != 0 {
+		t.Fatalf("collaborators after rejected add = %d, want 0", got)
+	}
+
+	if err := uc.AddCollaborator(ctx, testListID, testOtherID, testOwnerID); err != nil {
+		t.Fatalf("AddCollaborator() by owner error = %v", err)
+	}
+	if got := countCollaborators(t, db); got != 1 {
+		t.Fatalf("collaborators after add = %d, want 1", got)
+	}
+
+	err = uc.AddCollaborator(ctx, testListID, testOtherID, testOwnerID)
+	if !errors.Is(err, entity.ErrConflict) {
+		t.Fatalf("AddCollaborator() twice error = %v, want ErrConflict", err)
+	}
+}
+
+func TestAddCollaboratorEnforcesMaxCollaborators(t *testing.T) {
+	uc, db := newTestUsecase(t)
+	ctx := context.Background()
+	const thirdID = "cccccccc-cccc-cccc-cccc-cccccccccccc"
+	uc.MaxCollaborators = 1
+
+	if err := uc.AddCollaborator(ctx, testListID, testOtherID, testOwnerID); err != nil {
+		t.Fatalf("AddCollaborator() below the limit error = %v", err)
+	}
+	err := uc.AddCollaborator(ctx, testListID, thirdID, testOwnerID)
+	if !errors.Is(err, entity.ErrConflict) || !strings.Contains(err.Error(), "maximum of 1 collaborators") {
+		t.Fatalf("AddCollaborator() at the limit error = %v, want ErrConflict naming the limit", err)
+	}
+	if got := countCollaborators(t, db); got != 1 {
+		t.Fatalf("collaborators after rejected add = %d, want 1", got)
+	}
+
+	token, _, err := uc.CreateInvite(ctx, testListID, testOwnerID, true, time.Hour)
+	if err != nil {
+		t.Fatalf("CreateInvite() error = %v", err)
+	}
+	if _, err := uc.AcceptInvite(ctx, token, thirdID); !errors.Is(err, entity.ErrConflict) {
+		t.Fatalf("AcceptInvite() at the limit error = %v, want ErrConflict", err)
+	}
+
+	uc.MaxCollaborators = 0
+	if err := uc.AddCollaborator(ctx, testListID, thirdID, testOwnerID); err != nil {
+		t.Fatalf("AddCollaborator() without a limit error = %v", err)
+	}
+}
+
+func TestRemoveCollaboratorLetsCollaboratorsLeave(t *testing.T) {
+	uc, db := newTestUsecase(t)
+	ctx := context.Background()
+	const thirdID = "cccccccc-cccc-cccc-cccc-cccccccccccc"
+
+	for _, id := range []string{testOtherID, thirdID} {
+		if err := uc.AddCollaborator(ctx, testListID, id, testOwnerID); err != nil {
+			t.Fatalf("AddCollaborator(%s) error = %v", id, err)
+		}
+	}
+
+	if err := uc.RemoveCollaborator(ctx, testListID, thirdID, testOtherID); !errors.Is(err, entity.ErrForbidden) {
+		t.Fatalf("RemoveCollaborator() of someone else by a collaborator error = %v, want ErrForbidden", err)
+	}
+	if err := uc.RemoveCollaborator(ctx, testListID, testOwnerID, testOwnerID); !errors.Is(err, entity.ErrConflict) {
+		t.Fatalf("RemoveCollaborator() of the owner by the owner error = %v, want ErrConflict", err)
+	}
+	if got := countCollaborators(t, db); got != 2 {
+		t.Fatalf("collaborators after rejected removals = %d, want 2", got)
+	}
+
+	if err := uc.RemoveCollaborator(ctx, testListID, testOtherID, testOtherID); err != nil {
+		t.Fatalf("RemoveCollaborator() of themselves error = %v", err)
+	}
+	if isCollab, _ := uc.TodoListCollabRepo.IsCollaborator(ctx, testListID, testOtherID); isCollab {
+		t.Fatal("collaborator still on the list after leaving")
+	}
+	if got := countCollaborators(t, db); got != 1 {
+		t.Fatalf("collaborators after leaving = %d, want 1", got)
+	}
+
+	if err := uc.RemoveCollaborator(ctx, testListID, testOtherID, testOtherID); !errors.Is(err, entity.ErrForbidden) {
+		t.Fatalf("RemoveCollaborator() of themselves again error = %v, want ErrForbidden", err)
+	}
+}
+
+func TestCloneTodoList(t *testing.T) {
+	uc, db := newTestUsecase(t)
+	ctx := context.Background()
+
+	deadline := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	second, err := uc.CreateTodoItem(ctx, testOwnerID, entity.TodoItem{ListID: testListIDTwo, Position: "z", Title: "Laundry", Deadline: &deadline, Priority: "high", Tags: []string{"home"}})
+	if err != nil {
+		t.Fatalf("CreateTodoItem() error = %v", err)
+	}
+	if err := db.Model(&entity.TodoItem{}).Where("id = ?", second.ID).Update("completed", true).Error; err != nil {
+		t.Fatalf("complete item: %v", err)
+	}
+	if err := uc.AddCollaborator(ctx, testListIDTwo, testOtherID, testOwnerID); err != nil {
+		t.Fatalf("AddCollaborator() error = %v", err)
+	}
+
+	list, items, err := uc.CloneTodoList(ctx, testListIDTwo, testOtherID)
+	if err != nil {
+		t.Fatalf("CloneTodoList() by a collaborator error = %v", err)
+	}
+	if list.ID == testListIDTwo || list.OwnerID != testOtherID || list.Title != "Chores Copy" {
+		t.Fatalf("clone = %+v, want a new list titled \"Chores Copy\" owned by the caller", list)
+	}
+	if len(items) != 2 || items[0].Title != "Dishes" || items[1].Title != "Laundry" {
+		t.Fatalf("cloned items = %+v, want Dishes then Laundry", items)
+	}
+	for _, item := range items {
+		if item.ListID != list.ID || item.Completed || item.Version != 1 || item.ID == testItemID || item.ID == second.ID {
+			t.Fatalf("cloned item = %+v, want a fresh incomplete copy in the new list", item)
+		}
+	}
+	if items[0].Position >= items[1].Position {
+		t.Fatalf("positions = %q, %q; want them ascending", items[0].Position, items[1].Position)
+	}
+	if items[1].Deadline == nil || !items[1].Deadline.Equal(deadline) || items[1].Priority != "high" || !slices.Equal(items[1].Tags, []string{"home"}) {
+		t.Fatalf("cloned Laundry = %+v, want deadline, priority and tags copied", items[1])
+	}
+	if ids, err := uc.TodoListCollabRepo.GetCollaboratorIDsByTodoListID(ctx, list.ID); err != nil || len(ids) != 0 {
+		t.Fatalf("clone collaborators = %v, %v; want none", ids, err)
+	}
+
+	if _, _, err := uc.CloneTodoList(ctx, testListID, testOtherID); !errors.Is(err, entity.ErrForbidden) {
+		t.Fatalf("CloneTodoList() without access error = %v, want ErrForbidden", err)
+	}
+}
+
+func TestTransferTodoList(t *testing.T) {
+	uc, db := newTestUsecase(t)
+	ctx := context.Background()
+
+	if _, err := uc.TransferTodoList(ctx, testListID, testOtherID, testOwnerID, true); !errors.Is(err, entity.ErrInvalid) {
+		t.Fatalf("TransferTodoList() to a non-collaborator error = %v, want ErrInvalid", err)
+	}
+	if err := uc.AddCollaborator(ctx, testListID, testOtherID, testOwnerID); err != nil {
+		t.Fatalf("AddCollaborator() error = %v", err)
+	}
+	if _, err := uc.TransferTodoList(ctx, testListID, testOwnerID, testOtherID, true); !errors.Is(err, entity.ErrForbidden) {
+		t.Fatalf("TransferTodoList() by a collaborator error = %v, want ErrForbidden", err)
+	}
+
+	list, err := uc.TransferTodoList(ctx, testListID, testOtherID, testOwnerID, true)
+	if err != nil {
+		t.Fatalf("TransferTodoList() error = %v", err)
+	}
+	if list.OwnerID != testOtherID {
+		t.Fatalf("OwnerID = %s, want %s", list.OwnerID, testOtherID)
+	}
+	var stored entity.TodoList
+	if err := db.First(&stored, "id = ?", testListID).Error; err != nil || stored.OwnerID != testOtherID {
+		t.Fatalf("stored owner = %s, %v; want %s", stored.OwnerID, err, testOtherID)
+	}
+	ids, err := uc.TodoListCollabRepo.GetCollaboratorIDsByTodoListID(ctx, testListID)
+	if err != nil || !slices.Equal(ids, []string{testOwnerID}) {
+		t.Fatalf("collaborators after transfer = %v, %v; want only the previous owner", ids, err)
+	}
+
+	// Handing it back without keeping the previous owner removes their access.
+	if _, err := uc.TransferTodoList(ctx, testListID, testOwnerID, testOtherID, false); err != nil {
+		t.Fatalf("TransferTodoList() back error = %v", err)
+	}
+	if got := countCollaborators(t, db); got != 0 {
+		t.Fatalf("collaborators after transfer without keeping = %d, want 0", got)
+	}
+	if _, err := uc.GetTodoListByID(ctx, testListID, testOtherID); !errors.Is(err, entity.ErrForbidden) {
+		t.Fatalf("GetTodoListByID() by the previous owner error = %v, want ErrForbidden", err)
+	}
+}
+
+func TestInTxRollsBackOnError(t *testing.T) {
+	uc, db := newTestUsecase(t)
+	ctx := context.Background()
+	wantErr := errors.New("boom")
+
+	err := uc.inTx(ctx, func(repos txRepos) error {
+		if _, err := repos.lists.GetTodoListByIDForUpdate(ctx, testListID); err != nil {
+			return err
+		}
+		if err := repos.collabs.AddCollaborator(ctx, &entity.TodoListCollaborator{
+			TodoListID:     testListID,
+			CollaboratorID: testOtherID,
+		}); err != nil {
+			return err
+		}
+		return wantErr
+	})
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("inTx() error = %v, want %v", err, wantErr)
+	}
+	if got := countCollaborators(t, db); got != 0 {
+		t.Fatalf("collaborators after rollback = %d, want 0", got)
+	}
+}
+
+func TestUpdateTodoItemRejectsItemFromAnotherList(t *testing.T) {
+	uc, db := newTestUsecase(t)
+	ctx := context.Background()
+
+	_, err := uc.UpdateTodoItem(ctx, testItemID, testListID, testOwnerID, &entity.TodoItem{
+		Title:    "Moved",
+		Position: "n",
+	})
+	if !errors.Is(err, entity.ErrNotFound) {
+		t.Fatalf("UpdateTodoItem() error = %v, want ErrNotFound", err)
+	}
+
+	var item entity.TodoItem
+	if err := db.First(&item, "id = ?", testItemID).Error; err != nil {
+		t.Fatalf("First(item) error = %v", err)
+	}
+	if item.Title != "Dishes" {
+		t.Fatalf("item.Title = %q, want %q", item.Title, "Dishes")
+	}
+}
+
+func TestGetTodoItemByIDRejectsItemFromAnotherList(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	ctx := context.Background()
+
+	if _, err := uc.GetTodoItemByID(ctx, testItemID, testListID, testOwnerID); !errors.Is(err, entity.ErrNotFound) {
+		t.Fatalf("GetTodoItemByID() error = %v, want ErrNotFound", err)
+	}
+	item, err := uc.GetTodoItemByID(ctx, testItemID, testListIDTwo, testOwnerID)
+	if err != nil {
+		t.Fatalf("GetTodoItemByID() on its own list error = %v", err)
+	}
+	if item.Title != "Dishes" {
+		t.Fatalf("item.Title = %q, want %q", item.Title, "Dishes")
+	}
+}
+
+func TestDeleteTodoItemRejectsItemFromAnotherList(t *testing.T) {
+	uc, db := newTestUsecase(t)
+	ctx := context.Background()
+
+	if err := uc.DeleteTodoItem(ctx, testItemID, testListID, testOwnerID); !errors.Is(err, entity.ErrNotFound) {
+		t.Fatalf("DeleteTodoItem() error = %v, want ErrNotFound", err)
+	}
+
+	var item entity.TodoItem
+	if err := db.First(&item, "id = ?", testItemID).Error; err != nil {
+		t.Fatalf("item was deleted through another list: %v", err)
+	}
+}
+
+func TestCreateTodoItemRecordsCreator(t *testing.T) {
+	uc, db := newTestUsecase(t)
+	ctx := context.Background()
+	if err := db.Exec(`INSERT INTO users (id, username) VALUES (?, 'bob')`, testOtherID).Error; err != nil {
+		t.Fatalf("Exec(insert user) error = %v", err)
+	}
+	if err := uc.AddCollaborator(ctx, testListID, testOtherID, testOwnerID); err != nil {
+		t.Fatalf("AddCollaborator() error = %v", err)
+	}
+
+	created, err := uc.CreateTodoItem(ctx, testOtherID, entity.TodoItem{ListID: testListID, Title: "Milk", Position: "m"})
+	if err != nil {
+		t.Fatalf("CreateTodoItem() error = %v", err)
+	}
+	if created.CreatedBy == nil || *created.CreatedBy != testOtherID || created.CreatedByUsername != "bob" {
+		t.Fatalf("created by %v (%q), want %s (bob)", created.CreatedBy, created.CreatedByUsername, testOtherID)
+	}
+
+	updated, err := uc.UpdateTodoItem(ctx, created.ID, testListID, testOwnerID, &entity.TodoItem{Title: "Oat milk", Position: "m", Version: created.Version})
+	if err != nil {
+		t.Fatalf("UpdateTodoItem() error = %v", err)
+	}
+	if updated.CreatedBy == nil || *updated.CreatedBy != testOtherID {
+		t.Fatalf("update by the owner changed the creator to %v", updated.CreatedBy)
+	}
+
+	items, err := uc.GetTodoItemsByList(ctx, testListID, testOwnerID)
+	if err != nil {
+		t.Fatalf("GetTodoItemsByList() error = %v", err)
+	}
+	if len(items) != 1 || items[0].CreatedByUsername != "bob" {
+		t.Fatalf("items = %+v, want one item created by bob", items)
+	}
+
+	batch, err := uc.CreateTodoItems(ctx, testOwnerID, testListID, []entity.TodoItem{{Title: "Eggs"}, {Title: "Bread"}})
+	if err != nil {
+		t.Fatalf("CreateTodoItems() error = %v", err)
+	}
+	for _, item := range batch {
+		if item.CreatedBy == nil || *item.CreatedBy != testOwnerID || item.CreatedByUsername != "" {
+			t.Fatalf("batch item created by %v (%q), want %s without a username", item.CreatedBy, item.CreatedByUsername, testOwnerID)
+		}
+	}
+}
+
+func TestTodoItemTags(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	ctx := context.Background()
+
+	created, err := uc.CreateTodoItem(ctx, testOwnerID, entity.TodoItem{
+		ListID:   testListID,
+		Title:    "Milk",
+		Position: "m",
+		Tags:     []string{" Errands ", "errands", "Dairy"},
+	})
+	if err != nil {
+		t.Fatalf("CreateTodoItem() error = %v", err)
+	}
+	if !slices.Equal(created.Tags, []string{"dairy", "errands"}) {
+		t.Fatalf("created.Tags = %q, want [dairy errands]", created.Tags)
+	}
+
+	// Omitted tags are kept, an empty slice clears them.
+	updated, err := uc.UpdateTodoItem(ctx, testItemID, testListIDTwo, testOwnerID, &entity.TodoItem{Title: "Dishes", Position: "m", Tags: []string{"ERRANDS"}, Version: 1})
+	if err != nil {
+		t.Fatalf("UpdateTodoItem() error = %v", err)
+	}
+	if !slices.Equal(updated.Tags, []string{"errands"}) {
+		t.Fatalf("updated.Tags = %q, want [errands]", updated.Tags)
+	}
+	updated, err = uc.UpdateTodoItem(ctx, testItemID, testListIDTwo, testOwnerID, &entity.TodoItem{Title: "Dishes, again", Position: "m", Version: updated.Version})
+	if err != nil {
+		t.Fatalf("UpdateTodoItem() without tags error = %v", err)
+	}
+	if !slices.Equal(updated.Tags, []string{"errands"}) {
+		t.Fatalf("tags after update without tags = %q, want [errands]", updated.Tags)
+	}
+
+	items, err := uc.GetTodoItemsByTag(ctx, testOwnerID, "  Errands")
+	if err != nil {
+		t.Fatalf("GetTodoItemsByTag() error = %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("len(items) = %d, want 2", len(items))
+	}
+	if items, err := uc.GetTodoItemsByTag(ctx, testOtherID, "errands"); err != nil || len(items) != 0 {
+		t.Fatalf("GetTodoItemsByTag() for a stranger = %d items, %v; want none", len(items), err)
+	}
+
+	if _, err := uc.UpdateTodoItem(ctx, testItemID, testListIDTwo, testOwnerID, &entity.TodoItem{Title: "Dishes", Position: "m", Tags: []string{}, Version: updated.Version}); err != nil {
+		t.Fatalf("UpdateTodoItem() clearing tags error = %v", err)
+	}
+	item, err := uc.GetTodoItemByID(ctx, testItemID, testListIDTwo, testOwnerID)
+	if err != nil {
+		t.Fatalf("GetTodoItemByID() error = %v", err)
+	}
+	if item.Tags == nil || len(item.Tags) != 0 {
+		t.Fatalf("item.Tags after clearing = %#v, want empty", item.Tags)
+	}
+}
+
+func TestStreamTodoItemsByList(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	ctx := context.Background()
+
+	for _, item := range []entity.TodoItem{
+		{ListID: testListIDTwo, Title: "Laundry", Position: "t", Tags: []string{"home"}},
+		{ListID: testListIDTwo, Title: "Sweep", Position: "c"},
+	} {
+		if _, err := uc.CreateTodoItem(ctx, testOwnerID, item); err != nil {
+			t.Fatalf("CreateTodoItem(%s) error = %v", item.Title, err)
+		}
+	}
+
+	var titles []string
+	var laundryTags []string
+	err := uc.StreamTodoItemsByList(ctx, testListIDTwo, testOwnerID, func(item *entity.TodoItem) error {
+		titles = append(titles, item.Title)
+		if item.Title == "Laundry" {
+			laundryTags = item.Tags
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamTodoItemsByList() error = %v", err)
+	}
+	if !slices.Equal(titles, []string{"Sweep", "Dishes", "Laundry"}) {
+		t.Fatalf("streamed titles = %q, want them in position order", titles)
+	}
+	if !slices.Equal(laundryTags, []string{"home"}) {
+		t.Fatalf("Laundry tags = %q, want [home]", laundryTags)
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err = uc.StreamTodoItemsByList(ctx, testListIDTwo, testOwnerID, func(*entity.TodoItem) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Fatalf("StreamTodoItemsByList() stopping early = %d calls, %v; want 1 call, stop", calls, err)
+	}
+
+	err = uc.StreamTodoItemsByList(ctx, testListIDTwo, testOtherID, func(*entity.TodoItem) error {
+		t.Fatal("item streamed to a stranger")
+		return nil
+	})
+	if !errors.Is(err, entity.ErrForbidden) {
+		t.Fatalf("StreamTodoItemsByList() by a stranger error = %v, want ErrForbidden", err)
+	}
+}
+
+func TestTodoItemPriority(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	ctx := context.Background()
+
+	if _, err := uc.CreateTodoItem(ctx, testOwnerID, entity.TodoItem{ListID: testListID, Title: "Bad", Position: "a", Priority: "urgent"}); !errors.Is(err, entity.ErrInvalid) {
+		t.Fatalf("CreateTodoItem(priority urgent) error = %v, want ErrInvalid", err)
+	}
+
+	for _, item := range []entity.TodoItem{
+		{Title: "a-low", Position: "a", Priority: entity.PriorityLow},
+		{Title: "b-default", Position: "b"},
+		{Title: "c-high", Position: "c", Priority: entity.PriorityHigh},
+		{Title: "d-low", Position: "d", Priority: entity.PriorityLow},
+		{Title: "e-high", Position: "e", Priority: entity.PriorityHigh},
+	} {
+		item.ListID = testListID
+		if _, err := uc.CreateTodoItem(ctx, testOwnerID, item); err != nil {
+			t.Fatalf("CreateTodoItem(%s) error = %v", item.Title, err)
+		}
+	}
+
+	items, err := uc.GetTodoItemsByList(ctx, testListID, testOwnerID)
+	if err != nil {
+		t.Fatalf("GetTodoItemsByList() error = %v", err)
+	}
+	if items[1].Priority != entity.PriorityMedium {
+		t.Fatalf("default priority = %q, want medium", items[1].Priority)
+	}
+	SortTodoItemsByPriority(items)
+	var titles []string
+	for _, item := range items {
+		titles = append(titles, item.Title)
+	}
+	want := []string{"c-high", "e-high", "b-default", "a-low", "d-low"}
+	if !slices.Equal(titles, want) {
+		t.Fatalf("sorted titles = %q, want %q", titles, want)
+	}
+
+	// Omitting the priority on update keeps it.
+	updated, err := uc.UpdateTodoItem(ctx, items[0].ID, testListID, testOwnerID, &entity.TodoItem{Title: "c-high", Position: "c", Version: items[0].Version})
+	if err != nil {
+		t.Fatalf("UpdateTodoItem() error = %v", err)
+	}
+	if updated.Priority != entity.PriorityHigh {
+		t.Fatalf("priority after update = %q, want high", updated.Priority)
+	}
+}
+
+func TestUpdateTodoItemRejectsStaleVersion(t *testing.T) {
+	uc, db := newTestUsecase(t)
+	ctx := context.Background()
+
+	// Two collaborators both read version 1 and save at the same time.
+	titles := []string{"Dishes by owner", "Dishes by bob"}
+	errs := make([]error, len(titles))
+	var wg sync.WaitGroup
+	for i, title := range titles {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = uc.UpdateTodoItem(ctx, testItemID, testListIDTwo, testOwnerID, &entity.TodoItem{Title: title, Position: "m", Version: 1})
+		}()
+	}
+	wg.Wait()
+
+	winner := -1
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			if winner != -1 {
+				t.Fatal("both updates based on version 1 succeeded")
+			}
+			winner = i
+		case !errors.Is(err, entity.ErrConflict):
+			t.Fatalf("UpdateTodoItem() error = %v, want nil or ErrConflict", err)
+		}
+	}
+	if winner == -1 {
+		t.Fatalf("no update succeeded: %v", errs)
+	}
+
+	var item entity.TodoItem
+	if err := db.First(&item, "id = ?", testItemID).Error; err != nil {
+		t.Fatalf("First(item) error = %v", err)
+	}
+	if item.Title != titles[winner] || item.Version != 2 {
+		t.Fatalf("stored item = %q at version %d, want %q at version 2", item.Title, item.Version, titles[winner])
+	}
+
+	// The loser can retry on top of the new version.
+	retried, err := uc.UpdateTodoItem(ctx, testItemID, testListIDTwo, testOwnerID, &entity.TodoItem{Title: "Merged", Position: "m", Version: item.Version})
+	if err != nil || retried.Version != 3 {
+		t.Fatalf("UpdateTodoItem(version 2) = %+v, %v; want version 3", retried, err)
+	}
+}
+
+func TestDeleteTodoItemCanBeRestoredWithinRetention(t *testing.T) {
+	uc, db := newTestUsecase(t)
+	ctx := context.Background()
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	uc.Now = func() time.Time { return now }
+
+	if err := uc.DeleteTodoItem(ctx, testItemID, testListIDTwo, testOwnerID); err != nil {
+		t.Fatalf("DeleteTodoItem() error = %v", err)
+	}
+	items, err := uc.GetTodoItemsByList(ctx, testListIDTwo, testOwnerID)
+	if err != nil {
+		t.Fatalf("GetTodoItemsByList() error = %v", err)
+	}
+	if len(items) != 0 {
+		t.Fatalf("len(items) after delete = %d, want 0", len(items))
+	}
+
+	restored, err := uc.RestoreTodoItem(ctx, testItemID, testListIDTwo, testOwnerID)
+	if err != nil {
+		t.Fatalf("RestoreTodoItem() error = %v", err)
+	}
+	if restored.Title != "Dishes" {
+		t.Fatalf("restored.Title = %q, want %q", restored.Title, "Dishes")
+	}
+	items, err = uc.GetTodoItemsByList(ctx, testListIDTwo, testOwnerID)
+	if err != nil {
+		t.Fatalf("GetTodoItemsByList() error = %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("len(items) after restore = %d, want 1", len(items))
+	}
+
+	if _, err := uc.RestoreTodoItem(ctx, testItemID, testListIDTwo, testOwnerID); !errors.Is(err, entity.ErrNotFound) {
+		t.Fatalf("RestoreTodoItem() of live item error = %v, want %v", err, entity.ErrNotFound)
+	}
+
+	expiredAt := now.Add(-TrashRetention - time.Hour)
+	if err := db.Model(&entity.TodoItem{}).Where("id = ?", testItemID).Update("deleted_at", expiredAt).Error; err != nil {
+		t.Fatalf("Update(deleted_at) error = %v", err)
+	}
+	if _, err := uc.RestoreTodoItem(ctx, testItemID, testListIDTwo, testOwnerID); !errors.Is(err, entity.ErrNotFound) {
+		t.Fatalf("RestoreTodoItem() after retention error = %v, want %v", err, entity.ErrNotFound)
+	}
+
+	purged, err := uc.PurgeExpiredTodoItems(ctx)
+	if err != nil {
+		t.Fatalf("PurgeExpiredTodoItems() error = %v", err)
+	}
+	if purged != 1 {
+		t.Fatalf("PurgeExpiredTodoItems() = %d, want 1", purged)
+	}
+}
+
+func TestGetSharedTodoListsReturnsOnlyListsOwnedByOthers(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	ctx := context.Background()
+
+	if err := uc.AddCollaborator(ctx, testListID, testOtherID, testOwnerID); err != nil {
+		t.Fatalf("AddCollaborator() error = %v", err)
+	}
+
+	shared, err := uc.GetSharedTodoLists(ctx, testOtherID)
+	if err != nil {
+		t.Fatalf("GetSharedTodoLists() error = %v", err)
+	}
+	if len(shared) != 1 || shared[0].ID != testListID || shared[0].OwnerID != testOwnerID {
+		t.Fatalf("GetSharedTodoLists() = %+v, want only %s", shared, testListID)
+	}
+	if shared[0].SharedAt.IsZero() {
+		t.Fatal("SharedAt is zero, want the time the collaborator was added")
+	}
+
+	owned, err := uc.GetSharedTodoLists(ctx, testOwnerID)
+	if err != nil {
+		t.Fatalf("GetSharedTodoLists(owner) error = %v", err)
+	}
+	if len(owned) != 0 {
+		t.Fatalf("GetSharedTodoLists(owner) = %+v, want none", owned)
+	}
+}
+
+func TestItemMutationsPublishToListSubscribers(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	ctx := context.Background()
+
+	if _, _, err := uc.SubscribeTodoListEvents(ctx, testListIDTwo, testOtherID); !errors.Is(err, entity.ErrForbidden) {
+		t.Fatalf("SubscribeTodoListEvents() by stranger error = %v, want ErrForbidden", err)
+	}
+
+	events, unsubscribe, err := uc.SubscribeTodoListEvents(ctx, testListIDTwo, testOwnerID)
+	if err != nil {
+		t.Fatalf("SubscribeTodoListEvents() error = %v", err)
+	}
+	defer unsubscribe()
+	other, unsubscribeOther := uc.Events.Subscribe(testListID)
+	defer unsubscribeOther()
+
+	created, err := uc.CreateTodoItem(ctx, testOwnerID, entity.TodoItem{ListID: testListIDTwo, Position: "t", Title: "Laundry"})
+	if err != nil {
+		t.Fatalf("CreateTodoItem() error = %v", err)
+	}
+	if err := uc.DeleteTodoItem(ctx, testItemID, testListIDTwo, testOwnerID); err != nil {
+		t.Fatalf("DeleteTodoItem() error = %v", err)
+	}
+
+	ev := <-events
+	if ev.Type != EventItemCreated || ev.ItemID != created.ID || ev.Item == nil || ev.Item.Title != "Laundry" || ev.ActorID != testOwnerID {
+		t.Fatalf("first event = %+v, want item.created for %s", ev, created.ID)
+	}
+	ev = <-events
+	if ev.Type != EventItemDeleted || ev.ItemID != testItemID {
+		t.Fatalf("second event = %+v, want item.deleted for %s", ev, testItemID)
+	}
+	select {
+	case ev := <-other:
+		t.Fatalf("subscriber of another list received %+v", ev)
+	default:
+	}
+}
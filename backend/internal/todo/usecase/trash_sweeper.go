@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// TrashSweeper periodically purges todo items whose restore window has
+// passed.
+type TrashSweeper struct {
+	Usecase  *Usecase
+	Interval time.Duration
+	Logger   *log.Logger
+}
+
+func (s *TrashSweeper) Start(ctx context.Context) {
+	if s == nil || s.Usecase == nil {
+		return
+	}
+	interval := s.Interval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	go func() {
+		s.runOnce(ctx)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.runOnce(ctx)
+			}
+		}
+	}()
+}
+
+func (s *TrashSweeper) runOnce(ctx context.Context) {
+	purged, err := s.Usecase.PurgeExpiredTodoItems(ctx)
+	if err != nil {
+		if s.Logger != nil {
+			s.Logger.Printf("todo trash sweep failed: %v", err)
+		}
+		return
+	}
+	if purged > 0 && s.Logger != nil {
+		s.Logger.Printf("todo trash sweep purged %d item(s)", purged)
+	}
+}
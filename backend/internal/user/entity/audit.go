@@ -0,0 +1,42 @@
+package userentity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Authentication audit event types.
+const (
+	AuditLogin            = "login"
+	AuditRegister         = "register"
+	AuditFeedTokenIssued  = "feed_token_issued"
+	AuditFeedTokenRevoked = "feed_token_revoked"
+	AuditAccountDeleted   = "account_deleted"
+)
+
+// Audit outcomes.
+const (
+	AuditSuccess = "success"
+	AuditFailure = "failure"
+)
+
+// AuthAuditEvent is one entry of the auth_audit table, kept for operators
+// investigating suspicious activity. It never holds secrets such as tokens.
+type AuthAuditEvent struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	EventType string    `gorm:"type:varchar(32);not null" json:"event_type"`
+	Outcome   string    `gorm:"type:varchar(16);not null" json:"outcome"`
+	// ActorID is the account the event concerns; nil for failed logins that
+	// never got as far as one.
+	ActorID *uuid.UUID `gorm:"type:uuid" json:"actor_id,omitempty"`
+	// Identifier is the Matrix ID the caller signed in as, or tried to.
+	Identifier string `gorm:"type:varchar(255);not null;default:''" json:"identifier"`
+	// Reason says why a failed event failed.
+	Reason    string    `gorm:"type:text;not null;default:''" json:"reason"`
+	IP        string    `gorm:"type:varchar(64);not null;default:''" json:"ip"`
+	UserAgent string    `gorm:"type:varchar(512);not null;default:''" json:"user_agent"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (AuthAuditEvent) TableName() string { return "auth_audit" }
@@ -12,9 +12,27 @@ type User struct {
 	Username     string    `gorm:"type:varchar(255);not null" json:"username"`
 	MatrixID     string    `gorm:"type:varchar(255);unique" json:"matrix_id"`
 	Email        string    `gorm:"type:varchar(255);unique;not null" json:"email"`
-	PasswordHash string    `gorm:"type:varchar(255);not null" json:"-"`
-	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+	PasswordHash string    `gorm:"type:varchar(255);not null" json:"-"` // Always empty: accounts authenticate through Matrix, nothing is hashed
+	// MatrixAccessToken is the user's Matrix client-server token sealed with
+	// MATRIX_TOKEN_KEY, or empty. It lets the backend act in Matrix on their behalf.
+	MatrixAccessToken string `gorm:"type:text;not null;default:''" json:"-"`
+	// Timezone is the IANA name day boundaries are computed in for this user;
+	// empty means UTC.
+	Timezone string `gorm:"type:varchar(64);not null;default:''" json:"timezone"`
+	// TodoFeedTokenHash is the SHA-256 (hex) of the secret that authenticates
+	// the user's todo calendar feed, or empty when none was issued.
+	TodoFeedTokenHash string `gorm:"type:varchar(64);not null;default:''" json:"-"`
+	// Role is put in the user's tokens: "user" for everyone unless promoted
+	// to "admin" in the database.
+	Role      string    `gorm:"type:varchar(32);not null;default:'user'" json:"-"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 }
 
-var ErrNotFound = fmt.Errorf("user not found")
+var (
+	ErrNotFound      = fmt.Errorf("user not found")
+	ErrUsernameTaken = fmt.Errorf("username already taken")
+	// ErrEmailTaken is returned when another account already has the email
+	// address, compared ignoring case.
+	ErrEmailTaken = fmt.Errorf("email already registered")
+)
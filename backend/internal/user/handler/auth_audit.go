@@ -0,0 +1,34 @@
+package userhandler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	userentity "messenger/backend/internal/user/entity"
+	"messenger/backend/pkg/middleware"
+)
+
+// maxAuditUserAgent is the longest user agent stored; clients choose it
+// freely.
+const maxAuditUserAgent = 512
+
+// audit records event with the caller's address and user agent. Failing to
+// record it is logged but never fails the request.
+func (h *AuthHandler) audit(r *http.Request, event userentity.AuthAuditEvent) {
+	event.ID = uuid.New()
+	event.IP = middleware.ClientIP(r)
+	event.UserAgent = r.UserAgent()
+	if len(event.UserAgent) > maxAuditUserAgent {
+		event.UserAgent = strings.ToValidUTF8(event.UserAgent[:maxAuditUserAgent], "")
+	}
+	if err := h.authUsecase.RecordAuthEvent(r.Context(), &event); err != nil {
+		middleware.Logf(r.Context(), "Failed to record %s audit event: %v", event.EventType, err)
+	}
+}
+
+// auditUser records a successful event of userID, the caller.
+func (h *AuthHandler) auditUser(r *http.Request, eventType string, userID uuid.UUID) {
+	h.audit(r, userentity.AuthAuditEvent{EventType: eventType, Outcome: userentity.AuditSuccess, ActorID: &userID})
+}
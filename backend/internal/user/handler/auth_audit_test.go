@@ -0,0 +1,88 @@
+package userhandler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	userentity "messenger/backend/internal/user/entity"
+	userusecase "messenger/backend/internal/user/usecase"
+	"messenger/backend/pkg/middleware"
+)
+
+// auditingUsecase records audit events and signs everyone in as a new user.
+// Calling any other AuthUsecase method panics.
+type auditingUsecase struct {
+	userusecase.AuthUsecase
+	events []userentity.AuthAuditEvent
+}
+
+func (u *auditingUsecase) CreateOrGetMatrixUser(ctx context.Context, mxid string) (*userentity.User, string, bool, error) {
+	return &userentity.User{ID: uuid.New(), MatrixID: mxid}, "jwt", true, nil
+}
+
+func (u *auditingUsecase) RecordAuthEvent(ctx context.Context, event *userentity.AuthAuditEvent) error {
+	u.events = append(u.events, *event)
+	return nil
+}
+
+func TestPostMatrixAuthRecordsAuditEvents(t *testing.T) {
+	homeserver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("access_token") != "good" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"sub":"@alice:hs.localhost"}`))
+	}))
+	defer homeserver.Close()
+	t.Setenv("DEV_MATRIX_FED_BASE", homeserver.URL)
+
+	uc := &auditingUsecase{}
+	h := NewAuthHandler(uc)
+	// Sign-ins arrive through the gateway, which names the client.
+	handler := middleware.TrustedProxies([]netip.Prefix{netip.MustParsePrefix("172.18.0.0/16")})(http.HandlerFunc(h.PostMatrixAuth))
+	signIn := func(token string) int {
+		body := `{"access_token":"` + token + `","matrix_server_name":"hs.localhost"}`
+		req := httptest.NewRequest(http.MethodPost, "/auth/matrix/openid", strings.NewReader(body))
+		req.RemoteAddr = "172.18.0.2:51234"
+		req.Header.Set("X-Forwarded-For", "203.0.113.7")
+		req.Header.Set("User-Agent", strings.Repeat("x", 600))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := signIn("stolen-secret"); code != http.StatusUnauthorized {
+		t.Fatalf("sign-in with a bad token = %d, want 401", code)
+	}
+	if code := signIn("good"); code != http.StatusOK {
+		t.Fatalf("sign-in = %d, want 200", code)
+	}
+
+	if len(uc.events) != 3 {
+		t.Fatalf("events = %+v, want a failed login, a registration and a login", uc.events)
+	}
+	failed, registered, login := uc.events[0], uc.events[1], uc.events[2]
+	if failed.EventType != userentity.AuditLogin || failed.Outcome != userentity.AuditFailure || failed.ActorID != nil {
+		t.Fatalf("first event = %+v, want a failed login without actor", failed)
+	}
+	if registered.EventType != userentity.AuditRegister || registered.Outcome != userentity.AuditSuccess || registered.Identifier != "@alice:hs.localhost" {
+		t.Fatalf("second event = %+v, want alice's registration", registered)
+	}
+	if login.EventType != userentity.AuditLogin || login.Outcome != userentity.AuditSuccess || login.ActorID == nil || *login.ActorID != *registered.ActorID {
+		t.Fatalf("third event = %+v, want alice's login", login)
+	}
+	for _, event := range uc.events {
+		if event.ID == uuid.Nil || event.IP != "203.0.113.7" || len(event.UserAgent) != maxAuditUserAgent {
+			t.Fatalf("event = %+v, want an ID, the client IP and a truncated user agent", event)
+		}
+		if strings.Contains(event.Reason, "stolen-secret") || strings.Contains(event.Identifier, "stolen-secret") {
+			t.Fatalf("event = %+v leaks the access token", event)
+		}
+	}
+}
@@ -2,18 +2,23 @@ package userhandler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"net/mail"
 	"os"
 	"strings"
 
+	"github.com/google/uuid"
 	"github.com/oapi-codegen/runtime/types"
 
 	"messenger/backend/api/generated"
 	userentity "messenger/backend/internal/user/entity"
 	userusecase "messenger/backend/internal/user/usecase"
+	"messenger/backend/pkg/apierror"
+	"messenger/backend/pkg/httpjson"
+	"messenger/backend/pkg/metrics"
+	"messenger/backend/pkg/middleware"
 )
 
 // AuthHandler implements the generated.ServerInterface.
@@ -29,13 +34,22 @@ func NewAuthHandler(authUsecase userusecase.AuthUsecase) *AuthHandler {
 }
 
 func userToResponse(user *userentity.User) generated.User {
-	return generated.User{
+	res := generated.User{
 		Id:        user.ID,
 		Email:     types.Email(sanitizeUserEmail(user.Email, user.MatrixID)),
 		MatrixId:  user.MatrixID,
 		CreatedAt: &user.CreatedAt,
 		UpdatedAt: &user.UpdatedAt,
 	}
+	if user.Username != "" {
+		res.Username = &user.Username
+	}
+	timezone := user.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	res.Timezone = &timezone
+	return res
 }
 
 func sanitizeUserEmail(email, matrixID string) string {
@@ -55,9 +69,9 @@ func sanitizeUserEmail(email, matrixID string) string {
 
 // PostMatrixAuth handles Matrix OpenID token verification and authentication
 func (h *AuthHandler) PostMatrixAuth(w http.ResponseWriter, r *http.Request) {
-	var req generated.MatrixOpenIDRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeJSONError(w, "Invalid request body", http.StatusBadRequest)
+	req, err := httpjson.Decode[generated.MatrixOpenIDRequest](r)
+	if err != nil {
+		writeJSONError(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
 		return
 	}
 
@@ -71,26 +85,75 @@ func (h *AuthHandler) PostMatrixAuth(w http.ResponseWriter, r *http.Request) {
 	// Verify token with Matrix homeserver
 	userInfo, err := verifyMatrixToken(federationBase, req.AccessToken)
 	if err != nil {
-		log.Printf("Failed to verify Matrix token: %v", err)
+		middleware.Logf(r.Context(), "Failed to verify Matrix token: %v", err)
+		metrics.ObserveAuth("matrix_openid", false)
+		h.audit(r, userentity.AuthAuditEvent{EventType: userentity.AuditLogin, Outcome: userentity.AuditFailure,
+			Reason: "token verification failed on " + req.MatrixServerName})
 		writeJSONError(w, "Matrix token verification failed", http.StatusUnauthorized)
 		return
 	}
 
 	// Validate MXID matches server name
 	if !validateMXID(userInfo.Sub, req.MatrixServerName) {
-		log.Printf("MXID %s does not match server name %s", userInfo.Sub, req.MatrixServerName)
+		middleware.Logf(r.Context(), "MXID %s does not match server name %s", userInfo.Sub, req.MatrixServerName)
+		metrics.ObserveAuth("matrix_openid", false)
+		h.audit(r, userentity.AuthAuditEvent{EventType: userentity.AuditLogin, Outcome: userentity.AuditFailure,
+			Identifier: userInfo.Sub, Reason: "MXID does not match server name " + req.MatrixServerName})
 		writeJSONError(w, "MXID homeserver mismatch", http.StatusUnauthorized)
 		return
 	}
 
+	// A client-server token is optional and only kept if it is the same account's
+	clientToken := ""
+	if req.ClientAccessToken != nil {
+		clientToken = strings.TrimSpace(*req.ClientAccessToken)
+	}
+	if clientToken != "" {
+		clientBase, err := resolveClientBase(req.MatrixServerName)
+		if err != nil {
+			writeJSONError(w, "Failed to resolve Matrix homeserver", http.StatusBadRequest)
+			return
+		}
+		owner, err := matrixWhoami(clientBase, clientToken)
+		if err != nil || owner != userInfo.Sub {
+			middleware.Logf(r.Context(), "Matrix access token for %s rejected (owner %q): %v", userInfo.Sub, owner, err)
+			metrics.ObserveAuth("matrix_openid", false)
+			h.audit(r, userentity.AuthAuditEvent{EventType: userentity.AuditLogin, Outcome: userentity.AuditFailure,
+				Identifier: userInfo.Sub, Reason: "client access token belongs to another account"})
+			writeJSONError(w, "Matrix access token does not belong to this account", http.StatusUnauthorized)
+			return
+		}
+	}
+
 	// Create or get existing user
-	user, token, err := h.authUsecase.CreateOrGetMatrixUser(r.Context(), userInfo.Sub)
+	user, token, created, err := h.authUsecase.CreateOrGetMatrixUser(r.Context(), userInfo.Sub)
+	if errors.Is(err, userentity.ErrEmailTaken) {
+		middleware.Logf(r.Context(), "Matrix user %s collides with an existing account's email", userInfo.Sub)
+		h.audit(r, userentity.AuthAuditEvent{EventType: userentity.AuditRegister, Outcome: userentity.AuditFailure,
+			Identifier: userInfo.Sub, Reason: "email already registered"})
+		writeJSONError(w, "An account with this email already exists", http.StatusConflict)
+		return
+	}
 	if err != nil {
-		log.Printf("Failed to create or get Matrix user: %v", err)
+		middleware.Logf(r.Context(), "Failed to create or get Matrix user: %v", err)
 		writeJSONError(w, "Failed to authenticate user", http.StatusInternalServerError)
 		return
 	}
 
+	if clientToken != "" {
+		// Sign-in still succeeds without it; sending just stays unavailable.
+		if err := h.authUsecase.SetMatrixAccessToken(r.Context(), user.ID, clientToken); err != nil {
+			middleware.Logf(r.Context(), "Failed to store Matrix access token for user %s: %v", user.ID, err)
+		}
+	}
+
+	metrics.ObserveAuth("matrix_openid", true)
+	if created {
+		h.audit(r, userentity.AuthAuditEvent{EventType: userentity.AuditRegister, Outcome: userentity.AuditSuccess,
+			ActorID: &user.ID, Identifier: user.MatrixID})
+	}
+	h.audit(r, userentity.AuthAuditEvent{EventType: userentity.AuditLogin, Outcome: userentity.AuditSuccess,
+		ActorID: &user.ID, Identifier: user.MatrixID})
 	response := generated.MatrixAuthResponse{
 		Token:  token,
 		Mxid:   user.MatrixID,
@@ -110,8 +173,8 @@ func (h *AuthHandler) GetUserByMatrixId(
 ) {
 	user, err := h.authUsecase.GetUserByMatrixID(r.Context(), params.MatrixId)
 	if err != nil {
-		log.Printf("Error getting user by Matrix ID: %v", err)
-		http.Error(w, generated.Error{Message: err.Error()}.Message, http.StatusNotFound)
+		middleware.Logf(r.Context(), "Error getting user by Matrix ID: %v", err)
+		writeJSONError(w, err.Error(), http.StatusNotFound)
 		return
 	}
 
@@ -122,6 +185,254 @@ func (h *AuthHandler) GetUserByMatrixId(
 	json.NewEncoder(w).Encode(res)
 }
 
+// GetCurrentUser handles GET /users/me, answering from the account
+// LoadCurrentUser already read for the request.
+func (h *AuthHandler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
+	user, err := UserFromContext(r.Context())
+	switch {
+	case errors.Is(err, ErrNoCurrentUser), errors.Is(err, userentity.ErrNotFound):
+		writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	case err != nil:
+		middleware.Logf(r.Context(), "Failed to load current user: %v", err)
+		writeJSONError(w, "Failed to load profile", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(userToResponse(user))
+}
+
+// UpdateCurrentUser handles PATCH /users/me. The user is always the one in
+// the token, so there is no way to address someone else's profile.
+func (h *AuthHandler) UpdateCurrentUser(w http.ResponseWriter, r *http.Request) {
+	userIDStr, ok := r.Context().Value(middleware.ContextKeyUserID).(string)
+	if !ok {
+		writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	req, err := httpjson.Decode[generated.UpdateUserRequest](r)
+	if err != nil {
+		writeJSONError(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.authUsecase.UpdateProfile(r.Context(), userID, userusecase.ProfileUpdate{
+		Username: req.Username,
+		Timezone: req.Timezone,
+	})
+	switch {
+	case errors.Is(err, userusecase.ErrInvalidUsername), errors.Is(err, userusecase.ErrInvalidTimezone):
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, userentity.ErrUsernameTaken):
+		writeJSONError(w, err.Error(), http.StatusConflict)
+		return
+	case errors.Is(err, userentity.ErrNotFound):
+		writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	case err != nil:
+		middleware.Logf(r.Context(), "Failed to update profile for user %s: %v", userID, err)
+		writeJSONError(w, "Failed to update profile", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(userToResponse(user))
+}
+
+// DeleteCurrentUser handles DELETE /users/me, removing the caller's account
+// and data.
+func (h *AuthHandler) DeleteCurrentUser(w http.ResponseWriter, r *http.Request) {
+	userIDStr, ok := r.Context().Value(middleware.ContextKeyUserID).(string)
+	if !ok {
+		writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	req, err := httpjson.Decode[generated.DeleteUserRequest](r)
+	if err != nil {
+		writeJSONError(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	err = h.authUsecase.DeleteAccount(r.Context(), userID, req.ConfirmMatrixId)
+	switch {
+	case errors.Is(err, userusecase.ErrConfirmationMismatch):
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, userentity.ErrNotFound):
+		writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	case err != nil:
+		middleware.Logf(r.Context(), "Failed to delete account %s: %v", userID, err)
+		writeJSONError(w, "Failed to delete account", http.StatusInternalServerError)
+		return
+	}
+
+	h.auditUser(r, userentity.AuditAccountDeleted, userID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreateTodoFeedToken handles POST /users/me/todo-feed-token, issuing the
+// secret a calendar app uses to subscribe to the caller's todo deadlines.
+func (h *AuthHandler) CreateTodoFeedToken(w http.ResponseWriter, r *http.Request) {
+	userIDStr, ok := r.Context().Value(middleware.ContextKeyUserID).(string)
+	if !ok {
+		writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	token, err := h.authUsecase.RotateTodoFeedToken(r.Context(), userID)
+	switch {
+	case errors.Is(err, userentity.ErrNotFound):
+		writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	case err != nil:
+		middleware.Logf(r.Context(), "Failed to issue todo feed token for user %s: %v", userID, err)
+		writeJSONError(w, "Failed to issue feed token", http.StatusInternalServerError)
+		return
+	}
+	h.auditUser(r, userentity.AuditFeedTokenIssued, userID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(generated.TodoFeedToken{Token: token})
+}
+
+// DeleteTodoFeedToken handles DELETE /users/me/todo-feed-token, so a leaked
+// feed URL stops working.
+func (h *AuthHandler) DeleteTodoFeedToken(w http.ResponseWriter, r *http.Request) {
+	userIDStr, ok := r.Context().Value(middleware.ContextKeyUserID).(string)
+	if !ok {
+		writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	err = h.authUsecase.RevokeTodoFeedToken(r.Context(), userID)
+	switch {
+	case errors.Is(err, userentity.ErrNotFound):
+		writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	case err != nil:
+		middleware.Logf(r.Context(), "Failed to revoke todo feed token for user %s: %v", userID, err)
+		writeJSONError(w, "Failed to revoke feed token", http.StatusInternalServerError)
+		return
+	}
+	h.auditUser(r, userentity.AuditFeedTokenRevoked, userID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Error codes of POST /matrix/send that tell the client to sign in again with
+// a fresh client_access_token.
+const (
+	codeMatrixTokenMissing = "MATRIX_TOKEN_MISSING"
+	codeMatrixTokenExpired = "MATRIX_TOKEN_EXPIRED"
+)
+
+// SendMatrixMessage handles POST /matrix/send, sending a text message to a
+// room with the caller's stored Matrix access token.
+func (h *AuthHandler) SendMatrixMessage(w http.ResponseWriter, r *http.Request) {
+	userIDStr, ok := r.Context().Value(middleware.ContextKeyUserID).(string)
+	if !ok {
+		writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	req, err := httpjson.Decode[generated.MatrixSendRequest](r)
+	if err != nil {
+		writeJSONError(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	roomID := strings.TrimSpace(req.RoomId)
+	if !strings.HasPrefix(roomID, "!") || strings.TrimSpace(req.Body) == "" {
+		writeJSONError(w, "room_id must be a room ID (!...) and body must not be empty", http.StatusBadRequest)
+		return
+	}
+	txnID := uuid.NewString()
+	if req.TxnId != nil && *req.TxnId != "" {
+		txnID = *req.TxnId
+	}
+
+	user, accessToken, err := h.authUsecase.MatrixAccessToken(r.Context(), userID)
+	switch {
+	case errors.Is(err, userusecase.ErrMatrixTokensDisabled):
+		writeJSONError(w, err.Error(), http.StatusNotImplemented)
+		return
+	case errors.Is(err, userusecase.ErrNoMatrixToken):
+		apierror.WriteCode(w, http.StatusConflict, codeMatrixTokenMissing, "Sign in again with a Matrix client_access_token to send messages")
+		return
+	case errors.Is(err, userentity.ErrNotFound):
+		writeJSONError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	case err != nil:
+		middleware.Logf(r.Context(), "Failed to load Matrix access token for user %s: %v", userID, err)
+		writeJSONError(w, "Failed to send message", http.StatusInternalServerError)
+		return
+	}
+
+	clientBase, err := resolveClientBase(matrixServerName(user.MatrixID))
+	if err != nil {
+		middleware.Logf(r.Context(), "Failed to resolve homeserver of %s: %v", user.MatrixID, err)
+		writeJSONError(w, "Failed to resolve Matrix homeserver", http.StatusBadGateway)
+		return
+	}
+
+	eventID, err := sendRoomMessage(clientBase, accessToken, roomID, txnID, req.Body)
+	var merr *matrixError
+	switch {
+	case errors.Is(err, errMatrixTokenExpired):
+		if err := h.authUsecase.ClearMatrixAccessToken(r.Context(), userID); err != nil {
+			middleware.Logf(r.Context(), "Failed to clear expired Matrix access token of user %s: %v", userID, err)
+		}
+		apierror.WriteCode(w, http.StatusConflict, codeMatrixTokenExpired, "The Matrix access token has expired; sign in again")
+		return
+	case errors.As(err, &merr) && merr.Status == http.StatusForbidden:
+		writeJSONError(w, merr.Message, http.StatusForbidden)
+		return
+	case errors.As(err, &merr) && merr.Status >= 400 && merr.Status < 500 && merr.Status != http.StatusTooManyRequests:
+		writeJSONError(w, merr.Message, http.StatusBadRequest)
+		return
+	case err != nil:
+		middleware.Logf(r.Context(), "Failed to send Matrix message for user %s: %v", userID, err)
+		writeJSONError(w, "Homeserver failed to send the message", http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(generated.MatrixSendResponse{EventId: eventID})
+}
+
 // resolveFederationBase determines the federation base URL for a Matrix homeserver
 func resolveFederationBase(serverName string) (string, error) {
 	// Dev override: allow targeting a known homeserver inside docker-compose
@@ -197,7 +508,5 @@ type matrixUserInfo struct {
 
 // Helper function to write JSON errors
 func writeJSONError(w http.ResponseWriter, message string, statusCode int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(generated.Error{Message: message})
+	apierror.Write(w, statusCode, message)
 }
@@ -0,0 +1,81 @@
+package userhandler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/google/uuid"
+
+	userentity "messenger/backend/internal/user/entity"
+	"messenger/backend/pkg/middleware"
+)
+
+// ErrNoCurrentUser is returned by UserFromContext for requests that are not
+// authenticated or did not pass through LoadCurrentUser.
+var ErrNoCurrentUser = errors.New("request has no authenticated user")
+
+type currentUserKey struct{}
+
+// currentUser loads the authenticated user's row at most once per request.
+type currentUser struct {
+	once sync.Once
+	load func() (*userentity.User, error)
+	user *userentity.User
+	err  error
+}
+
+func (c *currentUser) get() (*userentity.User, error) {
+	c.once.Do(func() { c.user, c.err = c.load() })
+	return c.user, c.err
+}
+
+// LoadCurrentUser lets handlers get the authenticated user's account through
+// UserFromContext instead of fetching it again by ID. The row is read on the
+// first call and shared by the rest of the request, so routes that never ask
+// for it cost no query. It must run after the middleware that sets the user
+// ID; unauthenticated requests pass through untouched.
+func LoadCurrentUser(get func(ctx context.Context, userID uuid.UUID) (*userentity.User, error)) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userIDStr, ok := r.Context().Value(middleware.ContextKeyUserID).(string)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ctx := r.Context()
+			current := &currentUser{load: func() (*userentity.User, error) {
+				userID, err := uuid.Parse(userIDStr)
+				if err != nil {
+					return nil, userentity.ErrNotFound
+				}
+				return get(ctx, userID)
+			}}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(ctx, currentUserKey{}, current)))
+		})
+	}
+}
+
+// UserFromContext returns the authenticated user of the request ctx belongs
+// to. It returns userentity.ErrNotFound when the account no longer exists.
+func UserFromContext(ctx context.Context) (*userentity.User, error) {
+	current, ok := ctx.Value(currentUserKey{}).(*currentUser)
+	if !ok {
+		return nil, ErrNoCurrentUser
+	}
+	return current.get()
+}
+
+// CurrentUserExists adapts UserFromContext to middleware.RequireActiveUser,
+// so the revocation check loads the row handlers then reuse.
+func CurrentUserExists(ctx context.Context, userID string) (bool, error) {
+	_, err := UserFromContext(ctx)
+	switch {
+	case errors.Is(err, userentity.ErrNotFound):
+		return false, nil
+	case err != nil:
+		return false, err
+	}
+	return true, nil
+}
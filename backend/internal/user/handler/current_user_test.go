@@ -0,0 +1,66 @@
+package userhandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"messenger/backend/api/generated"
+	userentity "messenger/backend/internal/user/entity"
+	"messenger/backend/pkg/middleware"
+)
+
+func TestLoadCurrentUser(t *testing.T) {
+	alice := &userentity.User{ID: uuid.New(), MatrixID: "@alice:example.org", Username: "alice"}
+	lookups := 0
+	get := func(ctx context.Context, userID uuid.UUID) (*userentity.User, error) {
+		lookups++
+		if userID == alice.ID {
+			return alice, nil
+		}
+		return nil, userentity.ErrNotFound
+	}
+	chain := func(h http.Handler) http.Handler {
+		return LoadCurrentUser(get)(middleware.RequireActiveUser(CurrentUserExists)(h))
+	}
+	serve := func(userID string, h http.Handler) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/users/me", nil)
+		if userID != "" {
+			req = req.WithContext(context.WithValue(req.Context(), middleware.ContextKeyUserID, userID))
+		}
+		rec := httptest.NewRecorder()
+		chain(h).ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve(alice.ID.String(), http.HandlerFunc((&AuthHandler{}).GetCurrentUser))
+	var got generated.User
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("GET /users/me = %d %s, want 200", rec.Code, rec.Body.String())
+	}
+	if got.Id != alice.ID || got.Username == nil || *got.Username != "alice" {
+		t.Fatalf("user = %+v, want alice", got)
+	}
+	if lookups != 1 {
+		t.Fatalf("lookups = %d, want the active-user check and the handler to share one", lookups)
+	}
+
+	if rec := serve(uuid.NewString(), http.HandlerFunc((&AuthHandler{}).GetCurrentUser)); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("deleted user status = %d, want 401", rec.Code)
+	}
+
+	lookups = 0
+	rec = serve("", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := UserFromContext(r.Context()); !errors.Is(err, ErrNoCurrentUser) {
+			t.Errorf("UserFromContext() error = %v, want ErrNoCurrentUser", err)
+		}
+	}))
+	if rec.Code != http.StatusOK || lookups != 0 {
+		t.Fatalf("unauthenticated request = %d with %d lookups, want 200 and none", rec.Code, lookups)
+	}
+}
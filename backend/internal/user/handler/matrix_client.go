@@ -0,0 +1,150 @@
+package userhandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// matrixHTTPClient talks to users' homeservers on their behalf.
+var matrixHTTPClient = &http.Client{Timeout: 15 * time.Second}
+
+// errMatrixTokenExpired is returned when the homeserver no longer accepts the
+// stored access token (logged out, expired or soft-logged-out).
+var errMatrixTokenExpired = errors.New("matrix access token expired")
+
+// matrixError is any other error answer of the client-server API.
+type matrixError struct {
+	Status  int    `json:"-"`
+	ErrCode string `json:"errcode"`
+	Message string `json:"error"`
+}
+
+func (e *matrixError) Error() string {
+	return fmt.Sprintf("matrix: %d %s: %s", e.Status, e.ErrCode, e.Message)
+}
+
+// resolveClientBase determines the client-server API base URL of a Matrix
+// homeserver from /.well-known/matrix/client, the way clients discover it.
+// The dev overrides mirror resolveFederationBase.
+func resolveClientBase(serverName string) (string, error) {
+	devBase := func() string {
+		if base := os.Getenv("DEV_MATRIX_CLIENT_BASE"); base != "" {
+			return base
+		}
+		if base := os.Getenv("DEV_MATRIX_FED_BASE"); base != "" {
+			return base
+		}
+		return "http://matrix:8008"
+	}
+	if devSrv := os.Getenv("DEV_MATRIX_SERVER_NAME"); devSrv != "" && serverName == devSrv {
+		return devBase(), nil
+	}
+	if strings.HasSuffix(serverName, ".localhost") {
+		return devBase(), nil
+	}
+
+	resp, err := matrixHTTPClient.Get(fmt.Sprintf("https://%s/.well-known/matrix/client", serverName))
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch .well-known: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Sprintf("https://%s", serverName), nil
+	}
+
+	var result struct {
+		Homeserver struct {
+			BaseURL string `json:"base_url"`
+		} `json:"m.homeserver"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode .well-known response: %w", err)
+	}
+	if result.Homeserver.BaseURL == "" {
+		return "", fmt.Errorf("empty m.homeserver base_url in .well-known")
+	}
+	return strings.TrimRight(result.Homeserver.BaseURL, "/"), nil
+}
+
+// matrixServerName returns the homeserver part of a Matrix ID.
+func matrixServerName(mxid string) string {
+	if _, server, ok := strings.Cut(mxid, ":"); ok {
+		return server
+	}
+	return ""
+}
+
+// matrixWhoami returns the Matrix ID an access token belongs to.
+func matrixWhoami(clientBase, accessToken string) (string, error) {
+	var result struct {
+		UserID string `json:"user_id"`
+	}
+	if err := matrixRequest(http.MethodGet, clientBase+"/_matrix/client/v3/account/whoami", accessToken, nil, &result); err != nil {
+		return "", err
+	}
+	return result.UserID, nil
+}
+
+// sendRoomMessage sends body as an m.text message to roomID and returns the
+// new event ID. Reusing txnID makes the homeserver treat it as a retry.
+func sendRoomMessage(clientBase, accessToken, roomID, txnID, body string) (string, error) {
+	endpoint := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		clientBase, url.PathEscape(roomID), url.PathEscape(txnID))
+	content := map[string]string{"msgtype": "m.text", "body": body}
+	var result struct {
+		EventID string `json:"event_id"`
+	}
+	if err := matrixRequest(http.MethodPut, endpoint, accessToken, content, &result); err != nil {
+		return "", err
+	}
+	return result.EventID, nil
+}
+
+// matrixRequest performs an authenticated client-server API call, decoding a
+// 200 answer into out and turning error answers into errMatrixTokenExpired or
+// *matrixError.
+func matrixRequest(method, endpoint, accessToken string, in, out interface{}) error {
+	var payload []byte
+	if in != nil {
+		var err error
+		if payload, err = json.Marshal(in); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := matrixHTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach homeserver: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		merr := &matrixError{Status: resp.StatusCode}
+		_ = json.NewDecoder(resp.Body).Decode(merr)
+		if resp.StatusCode == http.StatusUnauthorized &&
+			(merr.ErrCode == "M_UNKNOWN_TOKEN" || merr.ErrCode == "M_MISSING_TOKEN") {
+			return errMatrixTokenExpired
+		}
+		return merr
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode homeserver response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package userhandler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSendRoomMessage(t *testing.T) {
+	var gotPath, gotAuth string
+	var gotBody map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotAuth = r.URL.EscapedPath(), r.Header.Get("Authorization")
+		switch r.Header.Get("Authorization") {
+		case "Bearer expired":
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"errcode":"M_UNKNOWN_TOKEN","error":"Unknown token","soft_logout":true}`))
+			return
+		case "Bearer outsider":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errcode":"M_FORBIDDEN","error":"not in room"}`))
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Write([]byte(`{"event_id":"$ev1"}`))
+	}))
+	defer srv.Close()
+
+	eventID, err := sendRoomMessage(srv.URL, "good", "!room:example.org", "txn/1", "hi")
+	if err != nil || eventID != "$ev1" {
+		t.Fatalf("sendRoomMessage() = %q, %v", eventID, err)
+	}
+	if want := "/_matrix/client/v3/rooms/%21room:example.org/send/m.room.message/txn%2F1"; gotPath != want {
+		t.Fatalf("path = %q, want %q", gotPath, want)
+	}
+	if gotAuth != "Bearer good" || gotBody["msgtype"] != "m.text" || gotBody["body"] != "hi" {
+		t.Fatalf("auth = %q, body = %v", gotAuth, gotBody)
+	}
+
+	if _, err := sendRoomMessage(srv.URL, "expired", "!room:example.org", "t", "hi"); !errors.Is(err, errMatrixTokenExpired) {
+		t.Fatalf("expired token error = %v, want errMatrixTokenExpired", err)
+	}
+	var merr *matrixError
+	if _, err := sendRoomMessage(srv.URL, "outsider", "!room:example.org", "t", "hi"); !errors.As(err, &merr) || merr.Status != http.StatusForbidden || merr.ErrCode != "M_FORBIDDEN" {
+		t.Fatalf("forbidden error = %v, want a 403 matrixError", err)
+	}
+}
+
+func TestMatrixServerName(t *testing.T) {
+	for mxid, want := range map[string]string{
+		"@alice:example.org":     "example.org",
+		"@bob:matrix.local:8448": "matrix.local:8448",
+		"not-a-matrix-id":        "",
+	} {
+		if got := matrixServerName(mxid); got != want {
+			t.Errorf("matrixServerName(%q) = %q, want %q", mxid, got, want)
+		}
+	}
+}
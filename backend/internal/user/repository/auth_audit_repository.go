@@ -0,0 +1,33 @@
+package userrepository
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	userentity "messenger/backend/internal/user/entity"
+)
+
+// AuthAuditRepository appends authentication events to the auth_audit
+// table. Entries are only ever inserted; nothing in the API reads them.
+type AuthAuditRepository interface {
+	RecordAuthEvent(ctx context.Context, event *userentity.AuthAuditEvent) error
+}
+
+type postgresAuthAuditRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresAuthAuditRepository creates an AuthAuditRepository.
+func NewPostgresAuthAuditRepository(db *gorm.DB) AuthAuditRepository {
+	return &postgresAuthAuditRepository{db: db}
+}
+
+// RecordAuthEvent inserts event.
+func (r *postgresAuthAuditRepository) RecordAuthEvent(ctx context.Context, event *userentity.AuthAuditEvent) error {
+	if err := r.db.WithContext(ctx).Create(event).Error; err != nil {
+		return fmt.Errorf("failed to record auth audit event: %w", err)
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -16,8 +17,14 @@ type UserRepository interface {
 	CreateUser(ctx context.Context, user *userentity.User) error
 	GetUserByID(ctx context.Context, id uuid.UUID) (*userentity.User, error)
 	GetUserByMatrixID(ctx context.Context, mxid string) (*userentity.User, error)
+	GetUserByUsername(ctx context.Context, username string) (*userentity.User, error)
+	GetUserByEmail(ctx context.Context, email string) (*userentity.User, error)
 	UpdateUser(ctx context.Context, user *userentity.User) error
+	SetMatrixAccessToken(ctx context.Context, id uuid.UUID, sealed string) error
+	SetTodoFeedTokenHash(ctx context.Context, id uuid.UUID, hash string) error
+	GetUserByTodoFeedTokenHash(ctx context.Context, hash string) (*userentity.User, error)
 	DeleteUser(ctx context.Context, id uuid.UUID) error
+	DeleteUserAndData(ctx context.Context, id uuid.UUID) error
 }
 
 // postgresUserRepository implements UserRepository using PostgreSQL and GORM.
@@ -43,8 +50,35 @@ func (r *postgresUserRepository) GetUserByMatrixID(ctx context.Context, mxid str
 	return &user, nil
 }
 
+// GetUserByUsername looks a user up by username, ignoring case.
+func (r *postgresUserRepository) GetUserByUsername(ctx context.Context, username string) (*userentity.User, error) {
+	var user userentity.User
+	err := r.db.WithContext(ctx).Where("lower(username) = lower(?)", username).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, userentity.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get user by username: %w", err)
+	}
+	return &user, nil
+}
+
+// GetUserByEmail looks a user up by email address, ignoring case.
+func (r *postgresUserRepository) GetUserByEmail(ctx context.Context, email string) (*userentity.User, error) {
+	var user userentity.User
+	err := r.db.WithContext(ctx).Where("lower(email) = lower(?)", email).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, userentity.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get user by email: %w", err)
+	}
+	return &user, nil
+}
+
 // CreateUser inserts a new user into the database.
 func (r *postgresUserRepository) CreateUser(ctx context.Context, user *userentity.User) error {
+	user.Email = strings.ToLower(user.Email)
 	err := r.db.WithContext(ctx).Create(user).Error
 	if err != nil {
 		return fmt.Errorf("failed to create user: %w", err)
@@ -74,6 +108,49 @@ func (r *postgresUserRepository) UpdateUser(ctx context.Context, user *userentit
 	return nil
 }
 
+// SetMatrixAccessToken overwrites only the stored (encrypted) Matrix access
+// token, so it cannot race with profile updates.
+func (r *postgresUserRepository) SetMatrixAccessToken(ctx context.Context, id uuid.UUID, sealed string) error {
+	result := r.db.WithContext(ctx).Model(&userentity.User{}).Where("id = ?", id).Update("matrix_access_token", sealed)
+	if result.Error != nil {
+		return fmt.Errorf("failed to store Matrix access token: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return userentity.ErrNotFound
+	}
+	return nil
+}
+
+// SetTodoFeedTokenHash overwrites only the stored calendar feed token hash;
+// an empty hash revokes the feed.
+func (r *postgresUserRepository) SetTodoFeedTokenHash(ctx context.Context, id uuid.UUID, hash string) error {
+	result := r.db.WithContext(ctx).Model(&userentity.User{}).Where("id = ?", id).Update("todo_feed_token_hash", hash)
+	if result.Error != nil {
+		return fmt.Errorf("failed to store todo feed token: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return userentity.ErrNotFound
+	}
+	return nil
+}
+
+// GetUserByTodoFeedTokenHash looks up the user a calendar feed token was
+// issued to.
+func (r *postgresUserRepository) GetUserByTodoFeedTokenHash(ctx context.Context, hash string) (*userentity.User, error) {
+	if hash == "" {
+		return nil, userentity.ErrNotFound
+	}
+	var user userentity.User
+	err := r.db.WithContext(ctx).Where("todo_feed_token_hash = ?", hash).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, userentity.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get user by todo feed token: %w", err)
+	}
+	return &user, nil
+}
+
 // DeleteUser deletes a user from the database by ID.
 func (r *postgresUserRepository) DeleteUser(ctx context.Context, id uuid.UUID) error {
 	err := r.db.WithContext(ctx).Delete(&userentity.User{}, id).Error
@@ -82,3 +159,42 @@ func (r *postgresUserRepository) DeleteUser(ctx context.Context, id uuid.UUID) e
 	}
 	return nil
 }
+
+// userDataDeletes remove everything a user owns or belongs to, in dependency
+// order. Each statement takes the user ID once per placeholder.
+var userDataDeletes = []string{
+	`DELETE FROM todo_items WHERE list_id IN (SELECT id FROM todo_lists WHERE owner_id = ?)`,
+	`DELETE FROM todo_list_collaborators WHERE todo_list_id IN (SELECT id FROM todo_lists WHERE owner_id = ?)`,
+	`DELETE FROM todo_list_collaborators WHERE collaborator_id = ?`,
+	`DELETE FROM todo_lists WHERE owner_id = ?`,
+	`DELETE FROM calendar_events WHERE source_id IN (SELECT id FROM calendar_sources WHERE user_id = ?)`,
+	`DELETE FROM calendar_sources WHERE user_id = ?`,
+	`DELETE FROM idempotency_keys WHERE user_id = ?`,
+	`DELETE FROM email_accounts WHERE user_id = ?`,
+	`DELETE FROM bridge_pairings WHERE user_id = ?`,
+	`DELETE FROM user_bridge_accounts WHERE user_id = ?`,
+	`DELETE FROM user_plan_overrides WHERE user_id = ?`,
+	`DELETE FROM usage_counters WHERE user_id = ?`,
+	`DELETE FROM user_plans WHERE user_id = ?`,
+}
+
+// DeleteUserAndData deletes the user and all data tied to them in a single
+// transaction. Items of owned lists are removed outright rather than moved to
+// the trash.
+func (r *postgresUserRepository) DeleteUserAndData(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, statement := range userDataDeletes {
+			if err := tx.Exec(statement, id).Error; err != nil {
+				return fmt.Errorf("failed to delete user data: %w", err)
+			}
+		}
+		result := tx.Exec(`DELETE FROM users WHERE id = ?`, id)
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete user: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return userentity.ErrNotFound
+		}
+		return nil
+	})
+}
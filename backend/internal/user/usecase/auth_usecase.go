@@ -2,6 +2,10 @@ package userusecase
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"strings"
@@ -12,20 +16,58 @@ import (
 	userentity "messenger/backend/internal/user/entity"
 	userrepository "messenger/backend/internal/user/repository"
 	"messenger/backend/pkg/auth"
+	"messenger/backend/pkg/secretbox"
 )
 
 type AuthUsecase interface {
 	GetUserByMatrixID(ctx context.Context, mxid string) (*userentity.User, error)
-	CreateOrGetMatrixUser(ctx context.Context, mxid string) (*userentity.User, string, error)
+	GetUserByID(ctx context.Context, userID uuid.UUID) (*userentity.User, error)
+	CreateOrGetMatrixUser(ctx context.Context, mxid string) (*userentity.User, string, bool, error)
+	UpdateProfile(ctx context.Context, userID uuid.UUID, update ProfileUpdate) (*userentity.User, error)
+	Location(ctx context.Context, userID string) (*time.Location, error)
+	DeleteAccount(ctx context.Context, userID uuid.UUID, confirmMatrixID string) error
+	UserExists(ctx context.Context, userID string) (bool, error)
+	SetMatrixAccessToken(ctx context.Context, userID uuid.UUID, token string) error
+	MatrixAccessToken(ctx context.Context, userID uuid.UUID) (*userentity.User, string, error)
+	ClearMatrixAccessToken(ctx context.Context, userID uuid.UUID) error
+	RotateTodoFeedToken(ctx context.Context, userID uuid.UUID) (string, error)
+	RevokeTodoFeedToken(ctx context.Context, userID uuid.UUID) error
+	UserIDForTodoFeedToken(ctx context.Context, token string) (string, error)
+	RecordAuthEvent(ctx context.Context, event *userentity.AuthAuditEvent) error
 }
 
+// ErrInvalidUsername is returned by UpdateProfile for names outside
+// 3-32 letters, digits, '.', '_' or '-'.
+var ErrInvalidUsername = errors.New("username must be 3-32 letters, digits, '.', '_' or '-'")
+
+// ErrInvalidTimezone is returned by UpdateProfile for names time.LoadLocation
+// does not know.
+var ErrInvalidTimezone = errors.New("timezone must be an IANA name such as Europe/Berlin")
+
+// ErrConfirmationMismatch is returned by DeleteAccount when the confirmation
+// is not the account's Matrix ID.
+var ErrConfirmationMismatch = errors.New("confirm_matrix_id does not match the account")
+
+// ErrNoMatrixToken is returned by MatrixAccessToken when the user has not
+// granted a Matrix access token, or it can no longer be decrypted.
+var ErrNoMatrixToken = errors.New("no Matrix access token stored")
+
+// ErrMatrixTokensDisabled is returned by the Matrix token methods when no
+// MATRIX_TOKEN_KEY is configured to encrypt tokens with.
+var ErrMatrixTokensDisabled = errors.New("storing Matrix access tokens is not configured")
+
 type authUsecase struct {
 	userRepo   userrepository.UserRepository
 	jwtService auth.JWTService
+	tokenBox   *secretbox.Box
+	auditRepo  userrepository.AuthAuditRepository
 }
 
-func NewAuthUsecase(userRepo userrepository.UserRepository, jwtService auth.JWTService) AuthUsecase {
-	return &authUsecase{userRepo: userRepo, jwtService: jwtService}
+// NewAuthUsecase creates the auth usecase. tokenBox encrypts stored Matrix
+// access tokens; with a nil box no tokens are stored. auditRepo receives
+// authentication events; with a nil one they are dropped.
+func NewAuthUsecase(userRepo userrepository.UserRepository, jwtService auth.JWTService, tokenBox *secretbox.Box, auditRepo userrepository.AuthAuditRepository) AuthUsecase {
+	return &authUsecase{userRepo: userRepo, jwtService: jwtService, tokenBox: tokenBox, auditRepo: auditRepo}
 }
 
 func (uc *authUsecase) GetUserByMatrixID(ctx context.Context, mxid string) (*userentity.User, error) {
@@ -39,43 +81,273 @@ func (uc *authUsecase) GetUserByMatrixID(ctx context.Context, mxid string) (*use
 	return user, nil
 }
 
-func (uc *authUsecase) CreateOrGetMatrixUser(ctx context.Context, mxid string) (*userentity.User, string, error) {
+// GetUserByID returns userID's account, or userentity.ErrNotFound.
+func (uc *authUsecase) GetUserByID(ctx context.Context, userID uuid.UUID) (*userentity.User, error) {
+	return uc.userRepo.GetUserByID(ctx, userID)
+}
+
+// CreateOrGetMatrixUser returns the account of mxid with a fresh token,
+// creating the account first if there is none; created tells which happened.
+func (uc *authUsecase) CreateOrGetMatrixUser(ctx context.Context, mxid string) (*userentity.User, string, bool, error) {
 	// Check for existing Matrix user
 	user, err := uc.userRepo.GetUserByMatrixID(ctx, mxid)
 	if err != nil && !errors.Is(err, userentity.ErrNotFound) {
-		return nil, "", fmt.Errorf("failed to check for existing Matrix user: %w", err)
+		return nil, "", false, fmt.Errorf("failed to check for existing Matrix user: %w", err)
 	}
 
 	// Return existing user with new token
 	if user != nil {
-		token, err := uc.jwtService.GenerateToken(user.ID.String())
+		token, err := uc.jwtService.GenerateToken(user.ID.String(), user.Role)
 		if err != nil {
-			return nil, "", fmt.Errorf("failed to generate token: %w", err)
+			return nil, "", false, fmt.Errorf("failed to generate token: %w", err)
 		}
-		return user, token, nil
+		return user, token, false, nil
 	}
 
-	// Create new Matrix user
+	// Create new Matrix user. Emails are unique ignoring case, and Matrix
+	// server names are case-insensitive, so a differently cased MXID of an
+	// existing account must not get a second one.
+	email := matrixUserEmail(mxid)
+	if _, err := uc.userRepo.GetUserByEmail(ctx, email); err == nil {
+		return nil, "", false, userentity.ErrEmailTaken
+	} else if !errors.Is(err, userentity.ErrNotFound) {
+		return nil, "", false, fmt.Errorf("failed to check for existing email: %w", err)
+	}
 	newUser := &userentity.User{
 		ID:        uuid.New(),
 		MatrixID:  mxid,
-		Email:     matrixUserEmail(mxid),
+		Email:     email,
+		Role:      auth.RoleUser,
 		CreatedAt: time.Now().UTC(),
 		UpdatedAt: time.Now().UTC(),
 	}
 
 	if err := uc.userRepo.CreateUser(ctx, newUser); err != nil {
-		return nil, "", fmt.Errorf("failed to create Matrix user: %w", err)
+		return nil, "", false, fmt.Errorf("failed to create Matrix user: %w", err)
+	}
+
+	token, err := uc.jwtService.GenerateToken(newUser.ID.String(), newUser.Role)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	return newUser, token, true, nil
+}
+
+// ProfileUpdate lists the profile fields to change; nil fields are kept.
+type ProfileUpdate struct {
+	Username *string
+	// Timezone is an IANA name; "" resets it to UTC.
+	Timezone *string
+}
+
+// UpdateProfile changes the username and/or timezone of userID. Usernames are
+// unique ignoring case; renaming to a different casing of one's own name is
+// allowed. Email and password are not editable: Matrix accounts have neither
+// a password nor a real address here.
+func (uc *authUsecase) UpdateProfile(ctx context.Context, userID uuid.UUID, update ProfileUpdate) (*userentity.User, error) {
+	var username, timezone string
+	if update.Username != nil {
+		username = strings.TrimSpace(*update.Username)
+		if !validUsername(username) {
+			return nil, ErrInvalidUsername
+		}
+	}
+	if update.Timezone != nil {
+		timezone = strings.TrimSpace(*update.Timezone)
+		if _, err := loadTimezone(timezone); err != nil {
+			return nil, ErrInvalidTimezone
+		}
+	}
+
+	user, err := uc.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Username != nil {
+		existing, err := uc.userRepo.GetUserByUsername(ctx, username)
+		if err != nil && !errors.Is(err, userentity.ErrNotFound) {
+			return nil, err
+		}
+		if existing != nil && existing.ID != user.ID {
+			return nil, userentity.ErrUsernameTaken
+		}
+		user.Username = username
+	}
+	if update.Timezone != nil {
+		user.Timezone = timezone
+	}
+	user.UpdatedAt = time.Now().UTC()
+	if err := uc.userRepo.UpdateUser(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Location returns the timezone userID set on their profile, or UTC when
+// they have not set one.
+func (uc *authUsecase) Location(ctx context.Context, userID string) (*time.Location, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID %q: %w", userID, err)
+	}
+	user, err := uc.userRepo.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := loadTimezone(user.Timezone)
+	if err != nil {
+		// Only validated names are stored; a name the tz database no
+		// longer knows falls back to UTC rather than failing the request.
+		return time.UTC, nil
+	}
+	return loc, nil
+}
+
+// loadTimezone resolves an IANA name, treating "" as UTC. time.LoadLocation
+// also accepts "Local", which would mean the server's zone, so it is refused.
+func loadTimezone(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	if name == "Local" {
+		return nil, ErrInvalidTimezone
 	}
+	return time.LoadLocation(name)
+}
+
+// DeleteAccount permanently removes userID and everything it owns. Accounts
+// have no password, so the caller confirms by repeating their Matrix ID.
+func (uc *authUsecase) DeleteAccount(ctx context.Context, userID uuid.UUID, confirmMatrixID string) error {
+	user, err := uc.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.MatrixID == "" || strings.TrimSpace(confirmMatrixID) != user.MatrixID {
+		return ErrConfirmationMismatch
+	}
+	return uc.userRepo.DeleteUserAndData(ctx, userID)
+}
+
+// RecordAuthEvent appends event to the authentication audit trail.
+func (uc *authUsecase) RecordAuthEvent(ctx context.Context, event *userentity.AuthAuditEvent) error {
+	if uc.auditRepo == nil {
+		return nil
+	}
+	return uc.auditRepo.RecordAuthEvent(ctx, event)
+}
+
+// UserExists reports whether userID still has an account. Tokens outlive
+// deleted accounts, so the auth middleware checks this on every request.
+func (uc *authUsecase) UserExists(ctx context.Context, userID string) (bool, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return false, nil
+	}
+	_, err = uc.userRepo.GetUserByID(ctx, id)
+	if errors.Is(err, userentity.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// SetMatrixAccessToken stores token, encrypted, as userID's Matrix
+// client-server access token. The caller must have checked that the token
+// belongs to the user's Matrix ID.
+func (uc *authUsecase) SetMatrixAccessToken(ctx context.Context, userID uuid.UUID, token string) error {
+	if uc.tokenBox == nil {
+		return ErrMatrixTokensDisabled
+	}
+	sealed, err := uc.tokenBox.Seal(token)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt Matrix access token: %w", err)
+	}
+	return uc.userRepo.SetMatrixAccessToken(ctx, userID, sealed)
+}
+
+// MatrixAccessToken returns userID together with their decrypted Matrix access
+// token. A token sealed under a since-rotated key counts as missing.
+func (uc *authUsecase) MatrixAccessToken(ctx context.Context, userID uuid.UUID) (*userentity.User, string, error) {
+	if uc.tokenBox == nil {
+		return nil, "", ErrMatrixTokensDisabled
+	}
+	user, err := uc.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if user.MatrixAccessToken == "" {
+		return nil, "", ErrNoMatrixToken
+	}
+	token, err := uc.tokenBox.Open(user.MatrixAccessToken)
+	if err != nil {
+		return nil, "", ErrNoMatrixToken
+	}
+	return user, token, nil
+}
 
-	token, err := uc.jwtService.GenerateToken(newUser.ID.String())
+// ClearMatrixAccessToken forgets userID's Matrix access token, e.g. after the
+// homeserver reported it expired.
+func (uc *authUsecase) ClearMatrixAccessToken(ctx context.Context, userID uuid.UUID) error {
+	return uc.userRepo.SetMatrixAccessToken(ctx, userID, "")
+}
+
+// RotateTodoFeedToken issues a new secret for userID's todo calendar feed,
+// invalidating the previous one. Only its hash is stored, so the token can be
+// shown once and not recovered later.
+func (uc *authUsecase) RotateTodoFeedToken(ctx context.Context, userID uuid.UUID) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate todo feed token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	if err := uc.userRepo.SetTodoFeedTokenHash(ctx, userID, hashFeedToken(token)); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// RevokeTodoFeedToken disables userID's todo calendar feed until a new token
+// is issued.
+func (uc *authUsecase) RevokeTodoFeedToken(ctx context.Context, userID uuid.UUID) error {
+	return uc.userRepo.SetTodoFeedTokenHash(ctx, userID, "")
+}
+
+// UserIDForTodoFeedToken returns the user a calendar feed token belongs to,
+// or userentity.ErrNotFound for unknown and revoked tokens.
+func (uc *authUsecase) UserIDForTodoFeedToken(ctx context.Context, token string) (string, error) {
+	if token == "" {
+		return "", userentity.ErrNotFound
+	}
+	user, err := uc.userRepo.GetUserByTodoFeedTokenHash(ctx, hashFeedToken(token))
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to generate token: %w", err)
+		return "", err
 	}
+	return user.ID.String(), nil
+}
 
-	return newUser, token, nil
+func hashFeedToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func validUsername(username string) bool {
+	if len(username) < 3 || len(username) > 32 {
+		return false
+	}
+	for _, r := range username {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+		default:
+			return false
+		}
+	}
+	return true
 }
 
+// matrixUserEmail derives the placeholder address stored for Matrix users. It
+// only satisfies the unique email column and is never mailed, so there is
+// nothing to verify: the homeserver already vouched for the MXID.
 func matrixUserEmail(mxid string) string {
 	local := strings.TrimPrefix(mxid, "@")
 	local = strings.ReplaceAll(local, ":", ".")
@@ -84,5 +356,5 @@ func matrixUserEmail(mxid string) string {
 	if local == "" {
 		local = "matrix-user"
 	}
-	return local + "@matrix.local"
+	return strings.ToLower(local) + "@matrix.local"
 }
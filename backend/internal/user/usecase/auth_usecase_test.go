@@ -0,0 +1,333 @@
+package userusecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	userentity "messenger/backend/internal/user/entity"
+	userrepository "messenger/backend/internal/user/repository"
+	"messenger/backend/pkg/auth"
+	"messenger/backend/pkg/secretbox"
+)
+
+func newTestAuthUsecase(t *testing.T) (AuthUsecase, userrepository.UserRepository, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("db.DB() error = %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	for _, statement := range []string{
+		`CREATE TABLE users (
+			id TEXT PRIMARY KEY,
+			username TEXT NOT NULL DEFAULT '',
+			matrix_id TEXT UNIQUE,
+			email TEXT NOT NULL UNIQUE,
+			password_hash TEXT NOT NULL DEFAULT '',
+			matrix_access_token TEXT NOT NULL DEFAULT '',
+			timezone TEXT NOT NULL DEFAULT '',
+			todo_feed_token_hash TEXT NOT NULL DEFAULT '',
+			role TEXT NOT NULL DEFAULT 'user',
+			created_at DATETIME,
+			updated_at DATETIME
+		)`,
+		`CREATE UNIQUE INDEX idx_users_email_lower ON users (lower(email))`,
+		`CREATE TABLE todo_lists (id TEXT PRIMARY KEY, owner_id TEXT NOT NULL)`,
+		`CREATE TABLE todo_items (id TEXT PRIMARY KEY, list_id TEXT NOT NULL)`,
+		`CREATE TABLE todo_list_collaborators (todo_list_id TEXT NOT NULL, collaborator_id TEXT NOT NULL)`,
+		`CREATE TABLE calendar_sources (id TEXT PRIMARY KEY, user_id TEXT NOT NULL)`,
+		`CREATE TABLE calendar_events (id TEXT PRIMARY KEY, source_id TEXT NOT NULL)`,
+		`CREATE TABLE idempotency_keys (user_id TEXT NOT NULL, key TEXT NOT NULL)`,
+		`CREATE TABLE email_accounts (id TEXT PRIMARY KEY, user_id TEXT NOT NULL)`,
+		`CREATE TABLE bridge_pairings (id TEXT PRIMARY KEY, user_id TEXT NOT NULL)`,
+		`CREATE TABLE user_bridge_accounts (id TEXT PRIMARY KEY, user_id TEXT NOT NULL)`,
+		`CREATE TABLE user_plan_overrides (id TEXT PRIMARY KEY, user_id TEXT NOT NULL)`,
+		`CREATE TABLE usage_counters (id TEXT PRIMARY KEY, user_id TEXT NOT NULL)`,
+		`CREATE TABLE user_plans (id TEXT PRIMARY KEY, user_id TEXT NOT NULL)`,
+	} {
+		if err := db.Exec(statement).Error; err != nil {
+			t.Fatalf("Exec(%q) error = %v", statement, err)
+		}
+	}
+
+	repo := userrepository.NewPostgresUserRepository(db)
+	return NewAuthUsecase(repo, nil, nil, nil), repo, db
+}
+
+func createTestUser(t *testing.T, repo userrepository.UserRepository, mxid, username string) *userentity.User {
+	t.Helper()
+	user := &userentity.User{ID: uuid.New(), MatrixID: mxid, Email: matrixUserEmail(mxid), Username: username}
+	if err := repo.CreateUser(context.Background(), user); err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	return user
+}
+
+func TestCreateOrGetMatrixUserRejectsEmailInAnotherCase(t *testing.T) {
+	ctx := context.Background()
+	_, repo, _ := newTestAuthUsecase(t)
+	uc := NewAuthUsecase(repo, auth.NewJWTService(auth.JWTOptions{Secret: "test-secret", TokenTTL: time.Hour}), nil, nil)
+
+	first, _, created, err := uc.CreateOrGetMatrixUser(ctx, "@alice:Example.org")
+	if err != nil || !created {
+		t.Fatalf("CreateOrGetMatrixUser() = created %v, %v; want a new account", created, err)
+	}
+	if first.Email != "alice.example.org@matrix.local" {
+		t.Fatalf("email = %q, want it stored in lower case", first.Email)
+	}
+	if found, err := repo.GetUserByEmail(ctx, "ALICE.example.org@Matrix.Local"); err != nil || found.ID != first.ID {
+		t.Fatalf("GetUserByEmail() = %v, %v; want the account regardless of case", found, err)
+	}
+
+	if _, _, _, err := uc.CreateOrGetMatrixUser(ctx, "@alice:example.org"); !errors.Is(err, userentity.ErrEmailTaken) {
+		t.Fatalf("CreateOrGetMatrixUser() in another case error = %v, want ErrEmailTaken", err)
+	}
+	again, _, created, err := uc.CreateOrGetMatrixUser(ctx, "@alice:Example.org")
+	if err != nil || again.ID != first.ID || created {
+		t.Fatalf("CreateOrGetMatrixUser() for the same MXID = %v, %v; want the existing account", again, err)
+	}
+
+	duplicate := &userentity.User{ID: uuid.New(), MatrixID: "@other:example.org", Email: "Alice.Example.org@matrix.local"}
+	if err := repo.CreateUser(ctx, duplicate); err == nil {
+		t.Fatal("CreateUser() with an email differing only in case succeeded, want a unique violation")
+	}
+}
+
+func TestUpdateProfile(t *testing.T) {
+	ctx := context.Background()
+	uc, repo, _ := newTestAuthUsecase(t)
+	alice := createTestUser(t, repo, "@alice:example.org", "")
+	createTestUser(t, repo, "@bob:example.org", "Bob")
+
+	tests := []struct {
+		name     string
+		username string
+		wantErr  error
+		want     string
+	}{
+		{name: "sets username", username: "  alice  ", want: "alice"},
+		{name: "own name in another case", username: "Alice", want: "Alice"},
+		{name: "taken ignoring case", username: "bob", wantErr: userentity.ErrUsernameTaken},
+		{name: "too short", username: "al", wantErr: ErrInvalidUsername},
+		{name: "invalid characters", username: "alice smith", wantErr: ErrInvalidUsername},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, err := uc.UpdateProfile(ctx, alice.ID, ProfileUpdate{Username: &tt.username})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("UpdateProfile() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdateProfile() error = %v", err)
+			}
+			stored, err := repo.GetUserByID(ctx, alice.ID)
+			if err != nil {
+				t.Fatalf("GetUserByID() error = %v", err)
+			}
+			if user.Username != tt.want || stored.Username != tt.want {
+				t.Fatalf("username = %q (stored %q), want %q", user.Username, stored.Username, tt.want)
+			}
+		})
+	}
+
+	ghost := "ghost"
+	if _, err := uc.UpdateProfile(ctx, uuid.New(), ProfileUpdate{Username: &ghost}); !errors.Is(err, userentity.ErrNotFound) {
+		t.Fatalf("UpdateProfile(unknown user) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestUpdateProfileTimezone(t *testing.T) {
+	ctx := context.Background()
+	uc, repo, _ := newTestAuthUsecase(t)
+	alice := createTestUser(t, repo, "@alice:example.org", "alice")
+
+	if loc, err := uc.Location(ctx, alice.ID.String()); err != nil || loc != time.UTC {
+		t.Fatalf("Location() before setting = %v, %v; want UTC", loc, err)
+	}
+
+	for _, bad := range []string{"Mars/Olympus_Mons", "Local"} {
+		if _, err := uc.UpdateProfile(ctx, alice.ID, ProfileUpdate{Timezone: &bad}); !errors.Is(err, ErrInvalidTimezone) {
+			t.Fatalf("UpdateProfile(timezone %q) error = %v, want ErrInvalidTimezone", bad, err)
+		}
+	}
+
+	berlin := " Europe/Berlin "
+	user, err := uc.UpdateProfile(ctx, alice.ID, ProfileUpdate{Timezone: &berlin})
+	if err != nil {
+		t.Fatalf("UpdateProfile(timezone) error = %v", err)
+	}
+	if user.Timezone != "Europe/Berlin" || user.Username != "alice" {
+		t.Fatalf("user = %q in %q, want alice's name kept and Europe/Berlin", user.Username, user.Timezone)
+	}
+	loc, err := uc.Location(ctx, alice.ID.String())
+	if err != nil || loc.String() != "Europe/Berlin" {
+		t.Fatalf("Location() = %v, %v; want Europe/Berlin", loc, err)
+	}
+
+	reset := ""
+	if _, err := uc.UpdateProfile(ctx, alice.ID, ProfileUpdate{Timezone: &reset}); err != nil {
+		t.Fatalf("UpdateProfile(reset timezone) error = %v", err)
+	}
+	if loc, err := uc.Location(ctx, alice.ID.String()); err != nil || loc != time.UTC {
+		t.Fatalf("Location() after reset = %v, %v; want UTC", loc, err)
+	}
+}
+
+func TestDeleteAccountRemovesOwnedData(t *testing.T) {
+	ctx := context.Background()
+	uc, repo, db := newTestAuthUsecase(t)
+	alice := createTestUser(t, repo, "@alice:example.org", "")
+	bob := createTestUser(t, repo, "@bob:example.org", "")
+
+	for _, statement := range []struct {
+		sql  string
+		args []interface{}
+	}{
+		{`INSERT INTO todo_lists VALUES ('alice-list', ?), ('bob-list', ?)`, []interface{}{alice.ID, bob.ID}},
+		{`INSERT INTO todo_items VALUES ('alice-item', 'alice-list'), ('bob-item', 'bob-list')`, nil},
+		{`INSERT INTO todo_list_collaborators VALUES ('alice-list', ?), ('bob-list', ?)`, []interface{}{bob.ID, alice.ID}},
+		{`INSERT INTO calendar_sources VALUES ('alice-source', ?)`, []interface{}{alice.ID}},
+		{`INSERT INTO calendar_events VALUES ('alice-event', 'alice-source')`, nil},
+		{`INSERT INTO idempotency_keys VALUES (?, 'k')`, []interface{}{alice.ID}},
+		{`INSERT INTO email_accounts VALUES ('alice-account', ?), ('bob-account', ?)`, []interface{}{alice.ID, bob.ID}},
+	} {
+		if err := db.Exec(statement.sql, statement.args...).Error; err != nil {
+			t.Fatalf("Exec(%q) error = %v", statement.sql, err)
+		}
+	}
+
+	if err := uc.DeleteAccount(ctx, alice.ID, "@bob:example.org"); !errors.Is(err, ErrConfirmationMismatch) {
+		t.Fatalf("DeleteAccount(wrong confirmation) error = %v, want ErrConfirmationMismatch", err)
+	}
+	if err := uc.DeleteAccount(ctx, alice.ID, alice.MatrixID); err != nil {
+		t.Fatalf("DeleteAccount() error = %v", err)
+	}
+
+	if exists, err := uc.UserExists(ctx, alice.ID.String()); err != nil || exists {
+		t.Fatalf("UserExists(alice) = %t, %v; want false", exists, err)
+	}
+	if exists, err := uc.UserExists(ctx, bob.ID.String()); err != nil || !exists {
+		t.Fatalf("UserExists(bob) = %t, %v; want true", exists, err)
+	}
+
+	for table, want := range map[string]int64{
+		"todo_lists":              1,
+		"todo_items":              1,
+		"todo_list_collaborators": 0,
+		"calendar_sources":        0,
+		"calendar_events":         0,
+		"idempotency_keys":        0,
+		"email_accounts":          1,
+	} {
+		var count int64
+		if err := db.Table(table).Count(&count).Error; err != nil {
+			t.Fatalf("count %s error = %v", table, err)
+		}
+		if count != want {
+			t.Errorf("%s rows = %d, want %d", table, count, want)
+		}
+	}
+
+	if err := uc.DeleteAccount(ctx, alice.ID, alice.MatrixID); !errors.Is(err, userentity.ErrNotFound) {
+		t.Fatalf("DeleteAccount(deleted user) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMatrixAccessTokenIsStoredEncrypted(t *testing.T) {
+	ctx := context.Background()
+	disabled, repo, _ := newTestAuthUsecase(t)
+	alice := createTestUser(t, repo, "@alice:example.org", "")
+
+	if err := disabled.SetMatrixAccessToken(ctx, alice.ID, "syt_token"); !errors.Is(err, ErrMatrixTokensDisabled) {
+		t.Fatalf("SetMatrixAccessToken() without a key error = %v, want ErrMatrixTokensDisabled", err)
+	}
+
+	box, err := secretbox.New(bytes.Repeat([]byte{7}, secretbox.KeySize))
+	if err != nil {
+		t.Fatalf("secretbox.New() error = %v", err)
+	}
+	uc := NewAuthUsecase(repo, nil, box, nil)
+
+	if _, _, err := uc.MatrixAccessToken(ctx, alice.ID); !errors.Is(err, ErrNoMatrixToken) {
+		t.Fatalf("MatrixAccessToken() before granting error = %v, want ErrNoMatrixToken", err)
+	}
+	if err := uc.SetMatrixAccessToken(ctx, alice.ID, "syt_token"); err != nil {
+		t.Fatalf("SetMatrixAccessToken() error = %v", err)
+	}
+
+	stored, err := repo.GetUserByID(ctx, alice.ID)
+	if err != nil {
+		t.Fatalf("GetUserByID() error = %v", err)
+	}
+	if stored.MatrixAccessToken == "" || stored.MatrixAccessToken == "syt_token" {
+		t.Fatalf("stored token = %q, want it encrypted", stored.MatrixAccessToken)
+	}
+	user, token, err := uc.MatrixAccessToken(ctx, alice.ID)
+	if err != nil || token != "syt_token" || user.MatrixID != alice.MatrixID {
+		t.Fatalf("MatrixAccessToken() = %v, %q, %v", user, token, err)
+	}
+
+	if err := uc.ClearMatrixAccessToken(ctx, alice.ID); err != nil {
+		t.Fatalf("ClearMatrixAccessToken() error = %v", err)
+	}
+	if _, _, err := uc.MatrixAccessToken(ctx, alice.ID); !errors.Is(err, ErrNoMatrixToken) {
+		t.Fatalf("MatrixAccessToken() after clearing error = %v, want ErrNoMatrixToken", err)
+	}
+}
+
+func TestTodoFeedToken(t *testing.T) {
+	ctx := context.Background()
+	uc, repo, _ := newTestAuthUsecase(t)
+	alice := createTestUser(t, repo, "@alice:example.org", "")
+
+	if _, err := uc.UserIDForTodoFeedToken(ctx, ""); !errors.Is(err, userentity.ErrNotFound) {
+		t.Fatalf("UserIDForTodoFeedToken(\"\") error = %v, want ErrNotFound", err)
+	}
+
+	first, err := uc.RotateTodoFeedToken(ctx, alice.ID)
+	if err != nil {
+		t.Fatalf("RotateTodoFeedToken() error = %v", err)
+	}
+	stored, err := repo.GetUserByID(ctx, alice.ID)
+	if err != nil {
+		t.Fatalf("GetUserByID() error = %v", err)
+	}
+	if stored.TodoFeedTokenHash == "" || stored.TodoFeedTokenHash == first {
+		t.Fatalf("stored hash = %q, want a hash of the token", stored.TodoFeedTokenHash)
+	}
+	if userID, err := uc.UserIDForTodoFeedToken(ctx, first); err != nil || userID != alice.ID.String() {
+		t.Fatalf("UserIDForTodoFeedToken() = %q, %v; want alice", userID, err)
+	}
+
+	second, err := uc.RotateTodoFeedToken(ctx, alice.ID)
+	if err != nil || second == first {
+		t.Fatalf("RotateTodoFeedToken() again = %q, %v; want a new token", second, err)
+	}
+	if _, err := uc.UserIDForTodoFeedToken(ctx, first); !errors.Is(err, userentity.ErrNotFound) {
+		t.Fatalf("rotated-out token error = %v, want ErrNotFound", err)
+	}
+
+	if err := uc.RevokeTodoFeedToken(ctx, alice.ID); err != nil {
+		t.Fatalf("RevokeTodoFeedToken() error = %v", err)
+	}
+	if _, err := uc.UserIDForTodoFeedToken(ctx, second); !errors.Is(err, userentity.ErrNotFound) {
+		t.Fatalf("revoked token error = %v, want ErrNotFound", err)
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"log"
 	"net/http"
 
 	generated "messenger/backend/api/generated"
@@ -12,6 +11,7 @@ import (
 	userrepo "messenger/backend/internal/user/repository"
 	waprovider "messenger/backend/internal/wa/provider"
 	roommap "messenger/backend/internal/wa/roommap"
+	"messenger/backend/pkg/apierror"
 	middleware "messenger/backend/pkg/middleware"
 
 	"github.com/google/uuid"
@@ -58,20 +58,32 @@ func writeBridgeError(w http.ResponseWriter, err error) {
 		if status < 400 || status > 599 {
 			status = http.StatusBadGateway
 		}
-		w.WriteHeader(status)
-		if len(bridgeErr.Body) == 0 {
-			_ = json.NewEncoder(w).Encode(map[string]string{"message": "bridge error"})
-			return
-		}
-		if json.Valid(bridgeErr.Body) {
-			_, _ = w.Write(bridgeErr.Body)
-			return
-		}
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": string(bridgeErr.Body)})
+		apierror.Write(w, status, bridgeErrorMessage(bridgeErr.Body))
 		return
 	}
-	w.WriteHeader(http.StatusBadGateway)
-	_ = json.NewEncoder(w).Encode(map[string]string{"message": "bridge error"})
+	apierror.Write(w, http.StatusBadGateway, "bridge error")
+}
+
+// bridgeErrorMessage extracts a readable message from a bridge error body,
+// which is usually Matrix-style JSON carrying "error" or "message".
+func bridgeErrorMessage(body []byte) string {
+	if len(body) == 0 {
+		return "bridge error"
+	}
+	var payload struct {
+		Message string `json:"message"`
+		Error   string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return string(body)
+	}
+	if payload.Message != "" {
+		return payload.Message
+	}
+	if payload.Error != "" {
+		return payload.Error
+	}
+	return "bridge error"
 }
 
 // getConnections → GetConnections
@@ -79,16 +91,14 @@ func (h *WAHandler) GetConnections(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	uid, ok := userIDFromCtx(r.Context())
 	if !ok {
-		w.WriteHeader(http.StatusUnauthorized)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "unauthorized"})
+		apierror.Write(w, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 	// Resolve user; surface repo errors instead of failing silently
 	u, err := h.users.GetUserByID(r.Context(), uid)
 	if err != nil {
-		log.Printf("[connections] failed to load user id=%s: %v", uid.String(), err)
-		w.WriteHeader(http.StatusInternalServerError)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "failed to load user"})
+		middleware.Logf(r.Context(), "[connections] failed to load user id=%s: %v", uid.String(), err)
+		apierror.Write(w, http.StatusInternalServerError, "failed to load user")
 		return
 	}
 	mxid := ""
@@ -105,7 +115,7 @@ func (h *WAHandler) GetConnections(w http.ResponseWriter, r *http.Request) {
 	// include effective limit for transparency
 	maxAcc, err := h.repo.GetEffectiveLimit(r.Context(), uid, &h.waProviderID, "max_accounts", 1)
 	if err != nil {
-		log.Printf("[connections] failed to read limit: %v", err)
+		middleware.Logf(r.Context(), "[connections] failed to read limit: %v", err)
 	}
 	limits := map[string]any{"max_accounts": maxAcc}
 	resp := []generated.BridgeConnection{{
@@ -123,21 +133,18 @@ func (h *WAHandler) BridgeGetLoginFlows(w http.ResponseWriter, r *http.Request,
 	// Validate auth and provider
 	_, ok := userIDFromCtx(r.Context())
 	if !ok {
-		w.WriteHeader(http.StatusUnauthorized)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "unauthorized"})
+		apierror.Write(w, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 	if params.Provider != "whatsapp" {
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "unsupported provider"})
+		apierror.Write(w, http.StatusBadRequest, "unsupported provider")
 		return
 	}
 	// Use current user mxid
 	uid, _ := userIDFromCtx(r.Context())
 	u, err := h.users.GetUserByID(r.Context(), uid)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "failed to load user"})
+		apierror.Write(w, http.StatusInternalServerError, "failed to load user")
 		return
 	}
 	mxid := ""
@@ -146,7 +153,7 @@ func (h *WAHandler) BridgeGetLoginFlows(w http.ResponseWriter, r *http.Request,
 	}
 	out, err := h.provider.GetLoginFlows(r.Context(), mxid)
 	if err != nil {
-		log.Printf("[provision flows] mxid=%s provider=%s error=%v", mxid, params.Provider, err)
+		middleware.Logf(r.Context(), "[provision flows] mxid=%s provider=%s error=%v", mxid, params.Provider, err)
 		writeBridgeError(w, err)
 		return
 	}
@@ -158,21 +165,18 @@ func (h *WAHandler) BridgeStartLogin(w http.ResponseWriter, r *http.Request, flo
 	w.Header().Set("Content-Type", "application/json")
 	_, ok := userIDFromCtx(r.Context())
 	if !ok {
-		w.WriteHeader(http.StatusUnauthorized)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "unauthorized"})
+		apierror.Write(w, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 	if params.Provider != "whatsapp" {
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "unsupported provider"})
+		apierror.Write(w, http.StatusBadRequest, "unsupported provider")
 		return
 	}
 	// Resolve mxid
 	uid, _ := userIDFromCtx(r.Context())
 	u, err := h.users.GetUserByID(r.Context(), uid)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "failed to load user"})
+		apierror.Write(w, http.StatusInternalServerError, "failed to load user")
 		return
 	}
 	mxid := ""
@@ -182,18 +186,17 @@ func (h *WAHandler) BridgeStartLogin(w http.ResponseWriter, r *http.Request, flo
 	// Enforce quota: count existing logins from bridge vs effective limit
 	limit, err := h.repo.GetEffectiveLimit(r.Context(), uid, &h.waProviderID, "max_accounts", 1)
 	if err != nil {
-		log.Printf("[provision start] failed to read limit: %v", err)
+		middleware.Logf(r.Context(), "[provision start] failed to read limit: %v", err)
 	}
 	if mxid != "" {
 		if ids, err := h.provider.ListLogins(r.Context(), mxid); err == nil && int64(len(ids)) >= limit {
-			w.WriteHeader(http.StatusForbidden)
-			_ = json.NewEncoder(w).Encode(map[string]string{"message": "quota exceeded: max_accounts"})
+			apierror.Write(w, http.StatusForbidden, "quota exceeded: max_accounts")
 			return
 		}
 	}
 	out, err := h.provider.StartLoginStep(r.Context(), mxid, flow)
 	if err != nil {
-		log.Printf("[provision start] mxid=%s provider=%s flow=%s error=%v", mxid, params.Provider, flow, err)
+		middleware.Logf(r.Context(), "[provision start] mxid=%s provider=%s flow=%s error=%v", mxid, params.Provider, flow, err)
 		writeBridgeError(w, err)
 		return
 	}
@@ -205,13 +208,11 @@ func (h *WAHandler) BridgeSubmitLoginStep(w http.ResponseWriter, r *http.Request
 	w.Header().Set("Content-Type", "application/json")
 	_, ok := userIDFromCtx(r.Context())
 	if !ok {
-		w.WriteHeader(http.StatusUnauthorized)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "unauthorized"})
+		apierror.Write(w, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 	if params.Provider != "whatsapp" {
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "unsupported provider"})
+		apierror.Write(w, http.StatusBadRequest, "unsupported provider")
 		return
 	}
 	var body generated.BridgeSubmitLoginStepJSONBody
@@ -220,8 +221,7 @@ func (h *WAHandler) BridgeSubmitLoginStep(w http.ResponseWriter, r *http.Request
 	uid, _ := userIDFromCtx(r.Context())
 	u, err := h.users.GetUserByID(r.Context(), uid)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "failed to load user"})
+		apierror.Write(w, http.StatusInternalServerError, "failed to load user")
 		return
 	}
 	mxid := ""
@@ -230,7 +230,7 @@ func (h *WAHandler) BridgeSubmitLoginStep(w http.ResponseWriter, r *http.Request
 	}
 	out, err := h.provider.SubmitLoginStep(r.Context(), mxid, processID, stepID, string(action), map[string]any(body))
 	if err != nil {
-		log.Printf("[provision step] mxid=%s provider=%s process=%s step=%s action=%s error=%v", mxid, params.Provider, processID, stepID, string(action), err)
+		middleware.Logf(r.Context(), "[provision step] mxid=%s provider=%s process=%s step=%s action=%s error=%v", mxid, params.Provider, processID, stepID, string(action), err)
 		writeBridgeError(w, err)
 		return
 	}
@@ -242,21 +242,18 @@ func (h *WAHandler) BridgeWhoami(w http.ResponseWriter, r *http.Request, params
 	w.Header().Set("Content-Type", "application/json")
 	_, ok := userIDFromCtx(r.Context())
 	if !ok {
-		w.WriteHeader(http.StatusUnauthorized)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "unauthorized"})
+		apierror.Write(w, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 	if params.Provider != "whatsapp" {
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "unsupported provider"})
+		apierror.Write(w, http.StatusBadRequest, "unsupported provider")
 		return
 	}
 	// Resolve mxid
 	uid, _ := userIDFromCtx(r.Context())
 	u, err := h.users.GetUserByID(r.Context(), uid)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "failed to load user"})
+		apierror.Write(w, http.StatusInternalServerError, "failed to load user")
 		return
 	}
 	mxid := ""
@@ -265,7 +262,7 @@ func (h *WAHandler) BridgeWhoami(w http.ResponseWriter, r *http.Request, params
 	}
 	out, err := h.provider.Whoami(r.Context(), mxid)
 	if err != nil {
-		log.Printf("[provision whoami] mxid=%s provider=%s error=%v", mxid, params.Provider, err)
+		middleware.Logf(r.Context(), "[provision whoami] mxid=%s provider=%s error=%v", mxid, params.Provider, err)
 		writeBridgeError(w, err)
 		return
 	}
@@ -280,26 +277,22 @@ func (h *WAHandler) GetBridgeRoomMappings(
 ) {
 	w.Header().Set("Content-Type", "application/json")
 	if _, ok := userIDFromCtx(r.Context()); !ok {
-		w.WriteHeader(http.StatusUnauthorized)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "unauthorized"})
+		apierror.Write(w, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 	if params.Provider != "whatsapp" {
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "unsupported provider"})
+		apierror.Write(w, http.StatusBadRequest, "unsupported provider")
 		return
 	}
 	if h.roomMapRepo == nil {
-		w.WriteHeader(http.StatusNotImplemented)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "bridge room mapping unavailable"})
+		apierror.Write(w, http.StatusNotImplemented, "bridge room mapping unavailable")
 		return
 	}
 
 	uid, _ := userIDFromCtx(r.Context())
 	u, err := h.users.GetUserByID(r.Context(), uid)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "failed to load user"})
+		apierror.Write(w, http.StatusInternalServerError, "failed to load user")
 		return
 	}
 	mxid := ""
@@ -308,9 +301,8 @@ func (h *WAHandler) GetBridgeRoomMappings(
 	}
 	mappings, err := h.roomMapRepo.ListRoomMappings(r.Context(), mxid, params.Provider)
 	if err != nil {
-		log.Printf("[bridge room mappings] mxid=%s provider=%s error=%v", mxid, params.Provider, err)
-		w.WriteHeader(http.StatusBadGateway)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "failed to load bridge room mappings"})
+		middleware.Logf(r.Context(), "[bridge room mappings] mxid=%s provider=%s error=%v", mxid, params.Provider, err)
+		apierror.Write(w, http.StatusBadGateway, "failed to load bridge room mappings")
 		return
 	}
 
@@ -334,11 +326,11 @@ func (h *WAHandler) BridgeLogout(w http.ResponseWriter, r *http.Request, loginID
 	// Accept either /logout/all or /logout/{id}
 	_, ok := userIDFromCtx(r.Context())
 	if !ok {
-		w.WriteHeader(http.StatusUnauthorized)
+		apierror.Write(w, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 	if params.Provider != "whatsapp" {
-		w.WriteHeader(http.StatusBadRequest)
+		apierror.Write(w, http.StatusBadRequest, "unsupported provider")
 		return
 	}
 	// Resolve mxid
@@ -350,11 +342,11 @@ func (h *WAHandler) BridgeLogout(w http.ResponseWriter, r *http.Request, loginID
 	}
 	if loginID == "all" {
 		if err := h.provider.Logout(r.Context(), mxid); err != nil {
-			log.Printf("[provision logout all] mxid=%s provider=%s error=%v", mxid, params.Provider, err)
+			middleware.Logf(r.Context(), "[provision logout all] mxid=%s provider=%s error=%v", mxid, params.Provider, err)
 		}
 	} else {
 		if err := h.provider.LogoutLogin(r.Context(), mxid, loginID); err != nil {
-			log.Printf("[provision logout] mxid=%s provider=%s login=%s error=%v", mxid, params.Provider, loginID, err)
+			middleware.Logf(r.Context(), "[provision logout] mxid=%s provider=%s login=%s error=%v", mxid, params.Provider, loginID, err)
 		}
 	}
 	w.WriteHeader(http.StatusNoContent)
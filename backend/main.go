@@ -4,21 +4,25 @@ import (
 	"context"
 	"log"
 	"net/http"
-	"os"
 	"time"
 
 	"messenger/backend/api/generated"
-	calendarEntity "messenger/backend/internal/calendar/entity"
 	calendarHandler "messenger/backend/internal/calendar/handler"
 	calendarRepo "messenger/backend/internal/calendar/repository"
 	calendarUsecase "messenger/backend/internal/calendar/usecase"
+	"messenger/backend/pkg/apierror"
 	"messenger/backend/pkg/auth"
+	"messenger/backend/pkg/config"
+	"messenger/backend/pkg/database"
+	"messenger/backend/pkg/health"
+	"messenger/backend/pkg/idempotency"
+	"messenger/backend/pkg/metrics"
 	middlewarePkg "messenger/backend/pkg/middleware"
+	"messenger/backend/pkg/secretbox"
 
+	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
-	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 
@@ -27,11 +31,10 @@ import (
 	bridgeEntity "messenger/backend/internal/bridge/entity"
 	bridgeRepo "messenger/backend/internal/bridge/repository"
 	emailHandler "messenger/backend/internal/email/handler"
-	todoEntity "messenger/backend/internal/todo/entity"
+	emailRepo "messenger/backend/internal/email/repository"
 	"messenger/backend/internal/todo/repository"
 	"messenger/backend/internal/todo/todohandler"
 	"messenger/backend/internal/todo/usecase"
-	userEntity "messenger/backend/internal/user/entity"
 	authHandler "messenger/backend/internal/user/handler"
 	userRepo "messenger/backend/internal/user/repository"
 	authUsecase "messenger/backend/internal/user/usecase"
@@ -43,43 +46,61 @@ import (
 func main() {
 	log.Printf("Starting backend service initialization...")
 
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Listen right away so probes get answers during migrations; the gate
+	// keeps reporting not-ready until the router is handed over below.
+	gate := &health.Gate{}
+	go func() {
+		log.Printf("Todo Service listening on port %s", cfg.Port)
+		if err := http.ListenAndServe(":"+cfg.Port, gate); err != nil {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
 	// Initialize GORM database connection
 	log.Printf("Initializing GORM database connection...")
-	dsn := os.Getenv("DATABASE_URL")
-	if dsn == "" {
-		log.Fatal("DATABASE_URL environment variable not set")
-	}
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(cfg.DatabaseURL), &gorm.Config{})
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 	log.Printf("GORM database connection initialized successfully.")
+	if err := metrics.InstrumentGORM(db); err != nil {
+		log.Fatalf("Failed to instrument database metrics: %v", err)
+	}
+	if err := database.LimitQueryTime(db); err != nil {
+		log.Fatalf("Failed to set up database query timeouts: %v", err)
+	}
 
-	// AutoMigrate GORM models
-	log.Printf("Auto-migrating GORM models...")
-	err = db.AutoMigrate(
-		&todoEntity.TodoList{},
-		&todoEntity.TodoItem{},
-		&todoEntity.TodoListCollaborator{},
-		&userEntity.User{},
-		&calendarEntity.CalendarSource{},
-		&calendarEntity.CalendarEvent{},
-	)
+	sqlDB, err := db.DB()
 	if err != nil {
-		log.Fatalf("Failed to auto-migrate GORM models: %v", err)
+		log.Fatalf("Failed to get database handle: %v", err)
 	}
-	if err := calendarRepo.EnsureCalendarSourceCategoryConstraint(db); err != nil {
-		log.Fatalf("Failed to enforce calendar source category constraint: %v", err)
+
+	// Apply versioned schema migrations from pkg/database/migrations
+	log.Printf("Applying database migrations...")
+	if err := database.Migrate(context.Background(), sqlDB); err != nil {
+		log.Fatalf("Failed to apply database migrations: %v", err)
 	}
-	log.Printf("GORM models auto-migrated successfully.")
+	log.Printf("Database migrations applied successfully.")
 
 	// Initialize JWT Service
 	log.Printf("Initializing JWT Service...")
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		log.Fatal("JWT_SECRET environment variable not set")
+	jwtService := auth.NewJWTService(auth.JWTOptions{
+		Secret:     cfg.JWTSecret,
+		PrivateKey: cfg.JWTPrivateKey,
+		PublicKey:  cfg.JWTPublicKey,
+		TokenTTL:   cfg.JWTTTL,
+		Issuer:     cfg.JWTIssuer,
+		Audience:   cfg.JWTAudience,
+	})
+	log.Printf("JWT tokens use %s and expire after %s (issuer %q, audience %q).", cfg.JWTAlgorithm, cfg.JWTTTL, cfg.JWTIssuer, cfg.JWTAudience)
+	if cfg.JWTAlgorithm == "RS256" && cfg.JWTPrivateKey == nil {
+		log.Printf("No JWT_PRIVATE_KEY_FILE: tokens are validated only, sign-in cannot issue new ones.")
 	}
-	jwtService := auth.NewJWTService(jwtSecret) // Token valid for 24 hours
 	log.Printf("JWT Service initialized.")
 
 	// Initialize User Repository
@@ -89,7 +110,18 @@ func main() {
 
 	// Initialize Auth Usecase
 	log.Printf("Initializing Auth Usecase...")
-	authUsecase := authUsecase.NewAuthUsecase(userRepository, jwtService)
+	// MATRIX_TOKEN_KEY (base64, 32 bytes) encrypts the Matrix access tokens
+	// users grant for POST /matrix/send; without it sending is disabled.
+	var matrixTokenBox *secretbox.Box
+	if cfg.MatrixTokenKey != nil {
+		matrixTokenBox, err = secretbox.New(cfg.MatrixTokenKey)
+		if err != nil {
+			log.Fatalf("Invalid MATRIX_TOKEN_KEY: %v", err)
+		}
+	} else {
+		log.Printf("MATRIX_TOKEN_KEY is not set; Matrix message sending is disabled.")
+	}
+	authUsecase := authUsecase.NewAuthUsecase(userRepository, jwtService, matrixTokenBox, userRepo.NewPostgresAuthAuditRepository(db))
 	log.Printf("Auth Usecase initialized.")
 
 	// Initialize Auth Handler
@@ -110,9 +142,26 @@ func main() {
 		todoListRepository,
 		todoItemRepository,
 		todoListCollaboratorRepository,
+		repository.NewTodoListInviteRepository(db),
+		repository.NewTransactor(db),
 	)
+	todoUsecase.UserLocation = authUsecase.Location
+	todoUsecase.MaxCollaborators = cfg.MaxCollaborators
 	log.Printf("Todo Usecase initialized.")
 
+	todoTrashSweeper := &usecase.TrashSweeper{
+		Usecase:  todoUsecase,
+		Interval: time.Hour,
+		Logger:   log.Default(),
+	}
+
+	idempotencyStore := idempotency.NewStore(db, idempotency.DefaultTTL)
+	idempotencySweeper := &idempotency.Sweeper{
+		Store:    idempotencyStore,
+		Interval: time.Hour,
+		Logger:   log.Default(),
+	}
+
 	// Initialize handler for todo service
 	log.Printf("Initializing Todo Handler...")
 	todoH := todohandler.NewHandler(todoUsecase)
@@ -144,15 +193,32 @@ func main() {
 
 	// Initialize Email Handler
 	log.Printf("Initializing Email Handler...")
-	emailH := emailHandler.NewEmailHandler()
-	log.Printf("Email Handler initialized.")
-
-	// AutoMigrate bridge-related models
-	log.Printf("Auto-migrating bridge models...")
-	if err := db.AutoMigrate(&bridgeEntity.Provider{}, &bridgeEntity.UserBridgeAccount{}, &bridgeEntity.BridgePairing{}, &bridgeEntity.Plan{}, &bridgeEntity.PlanLimit{}, &bridgeEntity.UserPlanOverride{}, &bridgeEntity.UsageCounter{}, &bridgeEntity.UserPlan{}); err != nil {
-		log.Fatalf("Failed to auto-migrate bridge models: %v", err)
+	var headerCacheBacking emailHandler.HeaderCache
+	if cfg.EmailHeaderCache == "postgres" {
+		headerCacheBacking = emailRepo.NewHeaderCacheRepository(db)
+	}
+	// EMAIL_ACCOUNT_KEY (base64, 32 bytes) encrypts the app passwords of
+	// accounts registered with POST /email/accounts; without it the email
+	// endpoints only take credentials in each request.
+	var emailAccountBox *secretbox.Box
+	if cfg.EmailAccountKey != nil {
+		emailAccountBox, err = secretbox.New(cfg.EmailAccountKey)
+		if err != nil {
+			log.Fatalf("Invalid EMAIL_ACCOUNT_KEY: %v", err)
+		}
+	} else {
+		log.Printf("EMAIL_ACCOUNT_KEY is not set; registered email accounts are disabled.")
 	}
-	log.Printf("Bridge models migrated.")
+	emailH := emailHandler.NewEmailHandler(emailHandler.Options{
+		Timeout:              cfg.IMAPTimeout,
+		AllowedHosts:         cfg.IMAPAllowedHosts,
+		AllowPrivateNetworks: cfg.IMAPAllowPrivateNetworks,
+		AllowPlaintext:       cfg.IMAPAllowPlaintext,
+		HeaderCache:          emailHandler.NewMemoryHeaderCache(emailHandler.DefaultHeaderCacheEntries, headerCacheBacking),
+		Accounts:             emailRepo.NewAccountRepository(db),
+		Credentials:          emailAccountBox,
+	})
+	log.Printf("Email Handler initialized.")
 
 	// Ensure WA provider exists
 	brepo := bridgeRepo.NewRepo(db)
@@ -167,20 +233,12 @@ func main() {
 	}
 
 	// Configure WA provider adapter from env (with sane defaults for dev)
-	waBaseURL := os.Getenv("WA_BRIDGE_BASE_URL")
-	if waBaseURL == "" {
-		waBaseURL = "http://mautrix-whatsapp:29319"
-	}
-	waSecret := os.Getenv("WA_BRIDGE_SHARED_SECRET") // must match bridge provisioning.shared_secret
-	if waSecret == "" {
+	// WA_BRIDGE_SHARED_SECRET must match the bridge's provisioning.shared_secret
+	if cfg.WABridgeSharedSecret == "" {
 		log.Printf("WARNING: WA_BRIDGE_SHARED_SECRET is empty; WA adapter will not authenticate against provisioning API.")
 	}
-	waAdapter := waProvider.New(waBaseURL, waSecret)
-	waBridgeDBPath := os.Getenv("WA_BRIDGE_DB_PATH")
-	if waBridgeDBPath == "" {
-		waBridgeDBPath = "/bridge-data/mautrix-whatsapp.db"
-	}
-	waRoomMapRepo := waRoomMap.NewRepository(waBridgeDBPath)
+	waAdapter := waProvider.New(cfg.WABridgeBaseURL, cfg.WABridgeSharedSecret)
+	waRoomMapRepo := waRoomMap.NewRepository(cfg.WABridgeDBPath)
 
 	handlers := struct {
 		*authHandler.AuthHandler
@@ -199,16 +257,73 @@ func main() {
 	// Setup Chi router
 	log.Printf("Setting up Chi router...")
 	r := chi.NewRouter()
-	r.Use(middleware.Logger, middleware.Recoverer)
+	// The request ID is echoed as X-Request-Id and into error bodies so a
+	// user's report can be matched to the log lines of that request.
+	// Metrics sit outside Recoverer so panics are counted as the 500s they become.
+	r.Use(middleware.RequestID, middlewarePkg.EchoRequestID, middleware.Logger, metrics.Middleware, middleware.Recoverer)
+	// Behind the gateway every peer is nginx; its forwarding headers name the
+	// client that rate limiting and the auth audit log see.
+	r.Use(middlewarePkg.TrustedProxies(cfg.TrustedProxies))
+	r.Use(middlewarePkg.CORS(middlewarePkg.CORSOptions{
+		AllowedOrigins: cfg.CORSAllowedOrigins,
+		AllowedMethods: []string{
+			http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+		},
+		AllowedHeaders:   []string{"Authorization", "Content-Type", "If-None-Match", idempotency.HeaderKey},
+		ExposedHeaders:   []string{"ETag", "Retry-After", todohandler.NextCursorHeader, idempotency.HeaderReplayed, middlewarePkg.RequestIDHeader},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}))
+	// Uploads get their own, larger cap; everything else is small JSON.
+	r.Use(middlewarePkg.BodyLimit(cfg.MaxRequestBody, map[string]int64{
+		"/api/v1/calendar/sources/import": cfg.MaxUploadBody,
+	}))
+	// Each database statement gets DB_QUERY_TIMEOUT; a request whose statement
+	// timed out answers 503 instead of the handler's 500.
+	r.Use(middlewarePkg.QueryTimeout(cfg.DBQueryTimeout))
 	log.Printf("Chi router setup complete.")
 
 	log.Printf("Registering API routes...")
+	spec, err := generated.GetSwagger()
+	if err != nil {
+		log.Fatalf("Failed to load embedded OpenAPI spec: %v", err)
+	}
+	// The spec's server URL points at a dev host; validate on the mount path only.
+	spec.Servers = openapi3.Servers{{URL: "/api/v1"}}
+	// importCalendarSource takes a multipart ICS upload that its handler parses itself.
+	requestValidator, err := middlewarePkg.RequestValidator(spec, "importCalendarSource")
+	if err != nil {
+		log.Fatalf("Failed to initialize request validator: %v", err)
+	}
+	// Only creates replay a stored response; other POSTs such as
+	// /email/body would otherwise have message contents stored per key.
+	idempotencyMiddleware, err := idempotency.Middleware(idempotencyStore, spec,
+		"createTodoList", "createTodoItem", "createTodoItemsBatch")
+	if err != nil {
+		log.Fatalf("Failed to initialize idempotency middleware: %v", err)
+	}
+
 	// ALL APIs must be generated from the OpenAPI spec
 	h := generated.HandlerWithOptions(handlers, generated.ChiServerOptions{
 		BaseRouter: r,
+		// Middlewares run last-to-first: authenticate (by JWT, or by feed token
+		// on the calendar feed), throttle per user (per IP when anonymous)
+		// before touching the database, load the caller's account once for
+		// handlers to reuse, reject tokens of deleted accounts, validate, then
+		// dedupe retried creates by Idempotency-Key.
 		Middlewares: []generated.MiddlewareFunc{
+			idempotencyMiddleware,
+			requestValidator,
+			middlewarePkg.RequireActiveUser(authHandler.CurrentUserExists),
+			authHandler.LoadCurrentUser(authUsecase.GetUserByID),
+			middlewarePkg.RateLimit(cfg.RateLimit, cfg.RateLimitBurst),
+			middlewarePkg.FeedTokenAuth(authUsecase.UserIDForTodoFeedToken),
 			middlewarePkg.AuthMiddleware(jwtService),
 		},
+		// Parameter binding failures would otherwise be answered in plain text.
+		ErrorHandlerFunc: func(w http.ResponseWriter, r *http.Request, err error) {
+			apierror.Write(w, http.StatusBadRequest, err.Error())
+		},
 	})
 
 	r.Mount("/api/v1", h)
@@ -217,26 +332,26 @@ func main() {
 	// Provisioning endpoints are defined in docs/openapi.yaml.
 	// Ensure 'make gen-be' is run to mount them through the generated router.
 
-	// Start HTTP server
-	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte("ok"))
-	})
+	readyHandler := health.ReadyHandler(sqlDB, 2*time.Second)
+	// /health is the liveness probe; /health/ready also checks the database.
+	r.Get("/health", health.LiveHandler)
+	r.Get("/health/ready", readyHandler)
 	// Convenience: expose health under /api/v1 for mobile clients using the API base path
-	r.Get("/api/v1/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte("ok"))
-	})
+	r.Get("/api/v1/health", health.LiveHandler)
+	r.Get("/api/v1/health/ready", readyHandler)
+	// Build commit, time and Go version, linked in by Dockerfile.prod.
+	r.Get("/version", health.VersionHandler)
+	r.Get("/api/v1/version", health.VersionHandler)
+	// Prometheus metrics: request rates and latencies by route, DB statement
+	// timings, auth and IMAP outcomes, email header cache hits and misses.
+	r.Handle("/metrics", metrics.Handler())
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080" // Default port
-	}
 	calendarSyncCoordinator.Start(context.Background())
-	log.Printf("Todo Service starting on port %s", port)
-	if err := http.ListenAndServe(":"+port, r); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
-	}
+	todoTrashSweeper.Start(context.Background())
+	idempotencySweeper.Start(context.Background())
+	gate.Open(r)
+	log.Printf("Todo Service ready on port %s", cfg.Port)
+	select {}
 }
 
 // seedDefaultPlans ensures a default "free" plan exists with WA max_accounts=1
@@ -0,0 +1,328 @@
+// Package adf converts between Atlassian Document Format, the rich text
+// representation Jira uses for descriptions and comments, and plain text.
+package adf
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type node struct {
+	Type    string    `json:"type"`
+	Text    string    `json:"text,omitempty"`
+	Attrs   nodeAttrs `json:"attrs"`
+	Content []node    `json:"content,omitempty"`
+}
+
+// nodeAttrs holds the attributes of heading and codeBlock nodes.
+type nodeAttrs struct {
+	Level    int    `json:"level"`
+	Language string `json:"language"`
+}
+
+// maxHeadingLevel is the deepest heading ADF and Markdown both support.
+const maxHeadingLevel = 6
+
+// codeFence opens and closes a code block in plain text.
+const codeFence = "```"
+
+// ToPlainText renders an ADF document as plain text:
+//
+//   - each paragraph ends in a newline and a hard break starts a new line;
+//   - list items are prefixed with "- " or "N. ", numbering restarts at 1 for
+//     every ordered list, and lines that continue an item (hard breaks, later
+//     paragraphs, nested lists) are indented to the column of the item's text;
+//   - blockquotes are prefixed with "> " and continued the same way;
+//   - headings are prefixed with one "#" per level and a space, as in
+//     Markdown;
+//   - code blocks are fenced with ``` lines, the opening one naming the
+//     language if the block has one, and their text is kept verbatim.
+//
+// For example an ordered list whose first item holds a bullet list renders as
+//
+//  1. parent
+//     - child
+//  2. next
+//
+// An empty or null document yields "".
+func ToPlainText(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil
+	}
+	var doc node
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	state := &textState{}
+	appendNode(&sb, doc, state)
+	text := strings.TrimRight(sb.String(), "\n")
+	return text, nil
+}
+
+// textState tracks where the renderer is within nested blocks.
+type textState struct {
+	// lists holds the enclosing lists, innermost last.
+	lists []listState
+	// indent is the column continuation lines of the current block start at.
+	indent string
+	// pendingPrefix is written before the next text on the current line: a
+	// list marker or quote marker not yet emitted, or indent after a break.
+	pendingPrefix string
+}
+
+type listState struct {
+	ordered bool
+	counter int
+}
+
+// marker returns the prefix for the next item of the innermost list.
+func (s *textState) marker() string {
+	if len(s.lists) == 0 {
+		return ""
+	}
+	list := &s.lists[len(s.lists)-1]
+	if !list.ordered {
+		return "- "
+	}
+	list.counter++
+	return fmt.Sprintf("%d. ", list.counter)
+}
+
+// openBlock starts a block whose first line begins with marker and whose
+// further lines are indented to match. A marker still pending from the parent
+// (a list item or quote that opens with this block) is written first so both
+// stay on the same line. It returns the indent to restore afterwards.
+func (s *textState) openBlock(sb *strings.Builder, marker string) string {
+	outer := s.indent
+	lead, column := s.indent, s.indent
+	if strings.TrimSpace(s.pendingPrefix) != "" {
+		sb.WriteString(s.pendingPrefix)
+		lead, column = "", strings.Repeat(" ", len(s.pendingPrefix))
+	}
+	s.pendingPrefix = lead + marker
+	s.indent = column + strings.Repeat(" ", len(marker))
+	return outer
+}
+
+// closeBlock ends the current line, if any text is on it, and restores the
+// enclosing indent.
+func (s *textState) closeBlock(sb *strings.Builder, outer string) {
+	if strings.TrimSpace(s.pendingPrefix) != "" {
+		// Nothing was written for this block; keep its marker visible.
+		sb.WriteString(strings.TrimRight(s.pendingPrefix, " "))
+		sb.WriteString("\n")
+	} else if out := sb.String(); out != "" && !strings.HasSuffix(out, "\n") {
+		sb.WriteString("\n")
+	}
+	s.pendingPrefix = ""
+	s.indent = outer
+}
+
+func (s *textState) writeText(sb *strings.Builder, text string) {
+	if s.pendingPrefix != "" {
+		sb.WriteString(s.pendingPrefix)
+		s.pendingPrefix = ""
+	}
+	sb.WriteString(text)
+}
+
+func (s *textState) lineBreak(sb *strings.Builder) {
+	if strings.TrimSpace(s.pendingPrefix) != "" {
+		sb.WriteString(strings.TrimRight(s.pendingPrefix, " "))
+	}
+	sb.WriteString("\n")
+	s.pendingPrefix = s.indent
+}
+
+func appendNode(sb *strings.Builder, n node, s *textState) {
+	switch n.Type {
+	case "paragraph", "heading":
+		if s.pendingPrefix == "" {
+			s.pendingPrefix = s.indent
+		}
+		if n.Type == "heading" {
+			level := min(max(n.Attrs.Level, 1), maxHeadingLevel)
+			s.writeText(sb, strings.Repeat("#", level)+" ")
+		}
+		for _, child := range n.Content {
+			appendNode(sb, child, s)
+		}
+		if strings.TrimSpace(s.pendingPrefix) != "" {
+			sb.WriteString(strings.TrimRight(s.pendingPrefix, " "))
+		}
+		sb.WriteString("\n")
+		s.pendingPrefix = ""
+	case "codeBlock":
+		if s.pendingPrefix == "" {
+			s.pendingPrefix = s.indent
+		}
+		var code strings.Builder
+		for _, child := range n.Content {
+			code.WriteString(child.Text)
+		}
+		s.writeText(sb, codeFence+n.Attrs.Language)
+		if code.Len() > 0 {
+			for _, line := range strings.Split(code.String(), "\n") {
+				s.lineBreak(sb)
+				if line != "" {
+					s.writeText(sb, line)
+				}
+			}
+		}
+		s.lineBreak(sb)
+		s.writeText(sb, codeFence)
+		sb.WriteString("\n")
+		s.pendingPrefix = ""
+	case "text":
+		s.writeText(sb, n.Text)
+	case "hardBreak":
+		s.lineBreak(sb)
+	case "bulletList", "orderedList":
+		s.lists = append(s.lists, listState{ordered: n.Type == "orderedList"})
+		for _, child := range n.Content {
+			appendNode(sb, child, s)
+		}
+		s.lists = s.lists[:len(s.lists)-1]
+	case "listItem":
+		outer := s.openBlock(sb, s.marker())
+		for _, child := range n.Content {
+			appendNode(sb, child, s)
+		}
+		s.closeBlock(sb, outer)
+	case "blockquote":
+		outer := s.openBlock(sb, "> ")
+		for _, child := range n.Content {
+			appendNode(sb, child, s)
+		}
+		s.closeBlock(sb, outer)
+	default:
+		for _, child := range n.Content {
+			appendNode(sb, child, s)
+		}
+	}
+}
+
+// FromPlainText builds an ADF document from plain text. Blank lines separate
+// paragraphs and single newlines become hard breaks. Two Markdown constructs
+// are recognised so that text rendered by ToPlainText keeps its structure:
+// a line of one to six "#" followed by a space starts a heading of that
+// level, and a line starting with ``` opens a code block, optionally naming
+// its language, that runs verbatim up to a line of just ``` or the end of the
+// text. Both must start at the beginning of the line.
+func FromPlainText(input string) map[string]interface{} {
+	normalized := strings.ReplaceAll(input, "\r\n", "\n")
+	lines := strings.Split(normalized, "\n")
+	var content []map[string]interface{}
+	var pending []string
+	flush := func() {
+		content = append(content, paragraphs(pending)...)
+		pending = nil
+	}
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if language, ok := strings.CutPrefix(line, codeFence); ok {
+			flush()
+			var code []string
+			for i++; i < len(lines) && strings.TrimRight(lines[i], " ") != codeFence; i++ {
+				code = append(code, lines[i])
+			}
+			content = append(content, codeBlockNode(strings.TrimSpace(language), strings.Join(code, "\n")))
+			continue
+		}
+		if level, title, ok := parseHeading(line); ok {
+			flush()
+			content = append(content, headingNode(level, title))
+			continue
+		}
+		pending = append(pending, line)
+	}
+	flush()
+	if len(content) == 0 {
+		content = append(content, map[string]interface{}{"type": "paragraph"})
+	}
+	return map[string]interface{}{
+		"type":    "doc",
+		"version": 1,
+		"content": content,
+	}
+}
+
+// paragraphs turns a run of plain lines into paragraph nodes. Blank lines at
+// either end, left over from the blocks around the run, are dropped; an
+// all-blank run yields no paragraphs.
+func paragraphs(lines []string) []map[string]interface{} {
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	sections := strings.Split(strings.Join(lines, "\n"), "\n\n")
+	content := make([]map[string]interface{}, 0, len(sections))
+	for _, section := range sections {
+		lines := strings.Split(section, "\n")
+		var nodes []map[string]interface{}
+		for i, line := range lines {
+			trimmed := strings.TrimRight(line, " ")
+			if trimmed != "" {
+				nodes = append(nodes, map[string]interface{}{
+					"type": "text",
+					"text": trimmed,
+				})
+			}
+			if i < len(lines)-1 {
+				nodes = append(nodes, map[string]interface{}{"type": "hardBreak"})
+			}
+		}
+		paragraph := map[string]interface{}{"type": "paragraph"}
+		if len(nodes) > 0 {
+			paragraph["content"] = nodes
+		}
+		content = append(content, paragraph)
+	}
+	return content
+}
+
+// parseHeading reports whether line is a Markdown ATX heading such as
+// "## Title", returning its level and trimmed title.
+func parseHeading(line string) (int, string, bool) {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > maxHeadingLevel {
+		return 0, "", false
+	}
+	rest := line[level:]
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return 0, "", false
+	}
+	return level, strings.TrimSpace(rest), true
+}
+
+func headingNode(level int, title string) map[string]interface{} {
+	heading := map[string]interface{}{
+		"type":  "heading",
+		"attrs": map[string]interface{}{"level": level},
+	}
+	if title != "" {
+		heading["content"] = []map[string]interface{}{{"type": "text", "text": title}}
+	}
+	return heading
+}
+
+func codeBlockNode(language, code string) map[string]interface{} {
+	block := map[string]interface{}{"type": "codeBlock"}
+	if language != "" {
+		block["attrs"] = map[string]interface{}{"language": language}
+	}
+	if code != "" {
+		block["content"] = []map[string]interface{}{{"type": "text", "text": code}}
+	}
+	return block
+}
@@ -0,0 +1,239 @@
+package adf
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type n = map[string]interface{}
+
+func doc(content ...n) n    { return n{"type": "doc", "version": 1, "content": content} }
+func para(content ...n) n   { return n{"type": "paragraph", "content": content} }
+func text(s string) n       { return n{"type": "text", "text": s} }
+func hardBreak() n          { return n{"type": "hardBreak"} }
+func bullets(items ...n) n  { return n{"type": "bulletList", "content": items} }
+func numbered(items ...n) n { return n{"type": "orderedList", "content": items} }
+func item(content ...n) n   { return n{"type": "listItem", "content": content} }
+func quote(content ...n) n  { return n{"type": "blockquote", "content": content} }
+func textItem(s string) n   { return item(para(text(s))) }
+func heading(level int, content ...n) n {
+	return n{"type": "heading", "attrs": n{"level": level}, "content": content}
+}
+func codeBlock(language, code string) n {
+	return n{"type": "codeBlock", "attrs": n{"language": language}, "content": []n{text(code)}}
+}
+func mustJSON(t *testing.T, v n) json.RawMessage {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+// corpus is rendered by ToPlainText and must survive a FromPlainText round
+// trip unchanged, since pull writes the text that push later sends back.
+var corpus = []struct {
+	name string
+	doc  n
+	want string
+}{
+	{
+		name: "paragraphs",
+		doc:  doc(para(text("First")), para(text("Second"))),
+		want: "First\nSecond",
+	},
+	{
+		name: "hard breaks",
+		doc:  doc(para(text("line one"), hardBreak(), text("line two"))),
+		want: "line one\nline two",
+	},
+	{
+		name: "bullet list",
+		doc:  doc(bullets(textItem("a"), textItem("b"))),
+		want: "- a\n- b",
+	},
+	{
+		name: "ordered list",
+		doc:  doc(numbered(textItem("a"), textItem("b"), textItem("c"))),
+		want: "1. a\n2. b\n3. c",
+	},
+	{
+		name: "nested bullet in ordered",
+		doc: doc(numbered(
+			item(para(text("parent")), bullets(textItem("child one"), textItem("child two"))),
+			textItem("next"),
+		)),
+		want: "1. parent\n   - child one\n   - child two\n2. next",
+	},
+	{
+		name: "nested ordered restarts and parent resumes",
+		doc: doc(numbered(
+			item(para(text("one")), numbered(textItem("x"), textItem("y"))),
+			item(para(text("two")), numbered(textItem("z"))),
+		)),
+		want: "1. one\n   1. x\n   2. y\n2. two\n   1. z",
+	},
+	{
+		name: "sibling ordered lists restart",
+		doc:  doc(numbered(textItem("a"), textItem("b")), para(text("between")), numbered(textItem("c"))),
+		want: "1. a\n2. b\nbetween\n1. c",
+	},
+	{
+		name: "bullet and ordered lists side by side in an item",
+		doc: doc(bullets(
+			item(para(text("parent")), bullets(textItem("a")), numbered(textItem("b"), textItem("c"))),
+		)),
+		want: "- parent\n  - a\n  1. b\n  2. c",
+	},
+	{
+		name: "three levels",
+		doc: doc(bullets(
+			item(para(text("a")), bullets(item(para(text("b")), numbered(textItem("c"))))),
+		)),
+		want: "- a\n  - b\n    1. c",
+	},
+	{
+		name: "wide numbers indent to the text",
+		doc: doc(numbered(
+			textItem("1"), textItem("2"), textItem("3"), textItem("4"), textItem("5"),
+			textItem("6"), textItem("7"), textItem("8"), textItem("9"),
+			item(para(text("10")), bullets(textItem("child"))),
+		)),
+		want: "1. 1\n2. 2\n3. 3\n4. 4\n5. 5\n6. 6\n7. 7\n8. 8\n9. 9\n10. 10\n    - child",
+	},
+	{
+		name: "item with two paragraphs",
+		doc:  doc(bullets(item(para(text("first")), para(text("second"))), textItem("next"))),
+		want: "- first\n  second\n- next",
+	},
+	{
+		name: "item opening with a nested list",
+		doc:  doc(bullets(item(bullets(textItem("inner"))))),
+		want: "- - inner",
+	},
+	{
+		name: "empty item keeps its marker",
+		doc:  doc(bullets(item(), textItem("b"))),
+		want: "-\n- b",
+	},
+	{
+		name: "list in a blockquote",
+		doc:  doc(quote(bullets(textItem("a"), textItem("b")))),
+		want: "> - a\n  - b",
+	},
+	{
+		name: "list item with hard break",
+		doc:  doc(bullets(item(para(text("first"), hardBreak(), text("wrapped"))))),
+		want: "- first\n  wrapped",
+	},
+	{
+		name: "blockquote",
+		doc:  doc(quote(para(text("quoted")))),
+		want: "> quoted",
+	},
+	{
+		name: "blockquote with hard break",
+		doc:  doc(quote(para(text("one"), hardBreak(), text("two")))),
+		want: "> one\n  two",
+	},
+	{
+		name: "headings",
+		doc:  doc(heading(1, text("Title")), para(text("Intro")), heading(3, text("Details"))),
+		want: "# Title\nIntro\n### Details",
+	},
+	{
+		name: "code block",
+		doc:  doc(para(text("Run:")), codeBlock("sh", "make test\n\n# all green"), para(text("Done"))),
+		want: "Run:\n```sh\nmake test\n\n# all green\n```\nDone",
+	},
+	{
+		name: "code block in list item",
+		doc:  doc(bullets(item(para(text("step")), codeBlock("", "go vet")))),
+		want: "- step\n  ```\n  go vet\n  ```",
+	},
+	{
+		name: "empty",
+		doc:  doc(),
+		want: "",
+	},
+}
+
+func TestToPlainText(t *testing.T) {
+	for _, tc := range corpus {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ToPlainText(mustJSON(t, tc.doc))
+			if err != nil {
+				t.Fatalf("ToPlainText: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("ToPlainText =\n%q\nwant\n%q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRoundTripIsStable(t *testing.T) {
+	for _, tc := range corpus {
+		t.Run(tc.name, func(t *testing.T) {
+			first, err := ToPlainText(mustJSON(t, tc.doc))
+			if err != nil {
+				t.Fatalf("ToPlainText: %v", err)
+			}
+			second, err := ToPlainText(mustJSON(t, FromPlainText(first)))
+			if err != nil {
+				t.Fatalf("ToPlainText after FromPlainText: %v", err)
+			}
+			if second != first {
+				t.Fatalf("round trip changed the text:\n%q\nbecame\n%q", first, second)
+			}
+		})
+	}
+}
+
+func TestToPlainTextNull(t *testing.T) {
+	for _, raw := range []string{"", "null"} {
+		got, err := ToPlainText(json.RawMessage(raw))
+		if err != nil || got != "" {
+			t.Fatalf("ToPlainText(%q) = %q, %v; want empty", raw, got, err)
+		}
+	}
+	if _, err := ToPlainText(json.RawMessage("{")); err == nil {
+		t.Fatal("expected an error for malformed JSON")
+	}
+}
+
+func TestFromPlainText(t *testing.T) {
+	got := FromPlainText("a\r\nb\n\nc  ")
+	want := doc(
+		para(text("a"), hardBreak(), text("b")),
+		para(text("c")),
+	)
+	gotJSON, _ := json.Marshal(got)
+	wantJSON, _ := json.Marshal(want)
+	if string(gotJSON) != string(wantJSON) {
+		t.Fatalf("FromPlainText =\n%s\nwant\n%s", gotJSON, wantJSON)
+	}
+
+	empty, _ := json.Marshal(FromPlainText(""))
+	if string(empty) != `{"content":[{"type":"paragraph"}],"type":"doc","version":1}` {
+		t.Fatalf("FromPlainText(\"\") = %s", empty)
+	}
+}
+
+func TestFromPlainTextHeadingsAndCodeBlocks(t *testing.T) {
+	got := FromPlainText("# Title\n\nIntro\n#hashtag\n\n```go\nx := 1\n\n  # not a heading\n```\n###### Deep  \n####### Too deep\n```\nunclosed")
+	want := doc(
+		heading(1, text("Title")),
+		para(text("Intro"), hardBreak(), text("#hashtag")),
+		codeBlock("go", "x := 1\n\n  # not a heading"),
+		heading(6, text("Deep")),
+		para(text("####### Too deep")),
+		n{"type": "codeBlock", "content": []n{text("unclosed")}},
+	)
+	gotJSON, _ := json.Marshal(got)
+	wantJSON, _ := json.Marshal(want)
+	if string(gotJSON) != string(wantJSON) {
+		t.Fatalf("FromPlainText =\n%s\nwant\n%s", gotJSON, wantJSON)
+	}
+}
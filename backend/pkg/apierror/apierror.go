@@ -0,0 +1,86 @@
+// Package apierror writes the JSON error envelope shared by every API handler:
+//
+//	{"code": "NOT_FOUND", "message": "Todo list not found", "requestId": "...", "details": [...]}
+//
+// code is stable and meant for clients to branch on; message is for humans.
+// requestId is copied from the X-Request-Id response header when the request
+// went through the request ID middleware.
+package apierror
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"messenger/backend/api/generated"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Error codes. Handlers normally let Write derive the code from the status;
+// the constants are exported for callers that need to pick one explicitly.
+const (
+	CodeValidation         = "VALIDATION_ERROR"
+	CodeUnauthorized       = "UNAUTHORIZED"
+	CodeForbidden          = "FORBIDDEN"
+	CodeNotFound           = "NOT_FOUND"
+	CodeConflict           = "CONFLICT"
+	CodeGone               = "GONE"
+	CodePreconditionFailed = "PRECONDITION_FAILED"
+	CodePayloadTooLarge    = "PAYLOAD_TOO_LARGE"
+	CodeTooManyRequests    = "TOO_MANY_REQUESTS"
+	CodeInternal           = "INTERNAL_ERROR"
+	CodeNotImplemented     = "NOT_IMPLEMENTED"
+	CodeBadGateway         = "BAD_GATEWAY"
+	CodeUnavailable        = "SERVICE_UNAVAILABLE"
+	CodeGatewayTimeout     = "GATEWAY_TIMEOUT"
+)
+
+var statusCodes = map[int]string{
+	http.StatusBadRequest:            CodeValidation,
+	http.StatusUnauthorized:          CodeUnauthorized,
+	http.StatusForbidden:             CodeForbidden,
+	http.StatusNotFound:              CodeNotFound,
+	http.StatusConflict:              CodeConflict,
+	http.StatusGone:                  CodeGone,
+	http.StatusPreconditionFailed:    CodePreconditionFailed,
+	http.StatusRequestEntityTooLarge: CodePayloadTooLarge,
+	http.StatusTooManyRequests:       CodeTooManyRequests,
+	http.StatusInternalServerError:   CodeInternal,
+	http.StatusNotImplemented:        CodeNotImplemented,
+	http.StatusBadGateway:            CodeBadGateway,
+	http.StatusServiceUnavailable:    CodeUnavailable,
+	http.StatusGatewayTimeout:        CodeGatewayTimeout,
+}
+
+// CodeForStatus returns the error code conventionally paired with an HTTP
+// status. Statuses without a dedicated code fall back to VALIDATION_ERROR for
+// 4xx and INTERNAL_ERROR otherwise.
+func CodeForStatus(status int) string {
+	if code, ok := statusCodes[status]; ok {
+		return code
+	}
+	if status >= 400 && status < 500 {
+		return CodeValidation
+	}
+	return CodeInternal
+}
+
+// Write sends message with the code derived from status.
+func Write(w http.ResponseWriter, status int, message string) {
+	WriteCode(w, status, CodeForStatus(status), message)
+}
+
+// WriteCode sends the envelope with an explicit code and optional per-field
+// details.
+func WriteCode(w http.ResponseWriter, status int, code, message string, details ...generated.FieldError) {
+	body := generated.Error{Code: code, Message: message}
+	if len(details) > 0 {
+		body.Details = &details
+	}
+	if id := w.Header().Get(middleware.RequestIDHeader); id != "" {
+		body.RequestId = &id
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
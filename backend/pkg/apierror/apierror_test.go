@@ -0,0 +1,64 @@
+package apierror
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"messenger/backend/api/generated"
+)
+
+func TestWriteDerivesCodeFromStatus(t *testing.T) {
+	for status, want := range map[int]string{
+		http.StatusBadRequest:          CodeValidation,
+		http.StatusNotFound:            CodeNotFound,
+		http.StatusUnprocessableEntity: CodeValidation,
+		http.StatusInternalServerError: CodeInternal,
+		http.StatusGatewayTimeout:      CodeGatewayTimeout,
+	} {
+		rec := httptest.NewRecorder()
+		Write(rec, status, "boom")
+
+		if rec.Code != status {
+			t.Fatalf("status = %d, want %d", rec.Code, status)
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+			t.Fatalf("Content-Type = %q, want application/json", ct)
+		}
+		var body generated.Error
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("json.Unmarshal() error = %v", err)
+		}
+		if body.Code != want || body.Message != "boom" || body.Details != nil {
+			t.Fatalf("body for %d = %+v, want code %q", status, body, want)
+		}
+	}
+}
+
+func TestWriteCodeIncludesDetails(t *testing.T) {
+	rec := httptest.NewRecorder()
+	WriteCode(rec, http.StatusBadRequest, CodeValidation, "invalid", generated.FieldError{Field: "/title", Message: "required"})
+
+	var body generated.Error
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if body.Details == nil || len(*body.Details) != 1 || (*body.Details)[0].Field != "/title" {
+		t.Fatalf("Details = %+v, want the /title entry", body.Details)
+	}
+}
+
+func TestWriteIncludesRequestID(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rec.Header().Set("X-Request-Id", "host/abc-000001")
+	Write(rec, http.StatusInternalServerError, "boom")
+
+	var body generated.Error
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if body.RequestId == nil || *body.RequestId != "host/abc-000001" {
+		t.Fatalf("RequestId = %v, want host/abc-000001", body.RequestId)
+	}
+}
@@ -1,38 +1,148 @@
 package auth
 
 import (
+	"crypto/rsa"
+	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// ErrMissingUserID is returned by ValidateToken for tokens that do not carry a
+// non-empty user_id claim.
+var ErrMissingUserID = errors.New("token has no user_id claim")
+
+// ErrSigningDisabled is returned by GenerateToken when the service was given
+// only an RSA public key and can therefore validate tokens but not mint them.
+var ErrSigningDisabled = errors.New("jwt service has no private key to sign tokens with")
+
+// Roles a token can carry in its role claim. Tokens issued before roles
+// existed have none and count as RoleUser.
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 type JWTService interface {
-	GenerateToken(userID string) (string, error)
+	GenerateToken(userID string, role string) (string, error)
 	ValidateToken(tokenString string) (*jwt.Token, error)
 }
 
+// JWTOptions configures NewJWTService.
+type JWTOptions struct {
+	// Secret is the HS256 signing key, used unless an RSA key is given.
+	Secret string
+	// PrivateKey and PublicKey switch the service to RS256: tokens are signed
+	// with PrivateKey and validated with PublicKey (PrivateKey's public half
+	// when nil). A service holding only PublicKey can validate tokens without
+	// being able to mint them.
+	PrivateKey *rsa.PrivateKey
+	PublicKey  *rsa.PublicKey
+	// TokenTTL is how long a token stays valid after it is issued.
+	TokenTTL time.Duration
+	// Issuer and Audience, when set, are written to the iss and aud claims
+	// of new tokens and required on every validated one, so tokens minted by
+	// another deployment (say staging) sharing the secret are refused.
+	Issuer   string
+	Audience string
+}
+
 type jwtService struct {
-	secretKey []byte
+	method jwt.SigningMethod
+	// signKey is nil when tokens cannot be minted (RS256 without a private
+	// key).
+	signKey   interface{}
+	verifyKey interface{}
+	tokenTTL  time.Duration
+	issuer    string
+	audience  string
 }
 
-func NewJWTService(secret string) JWTService {
-	return &jwtService{secretKey: []byte(secret)}
+// NewJWTService creates a JWTService from opts, signing with HS256 unless an
+// RSA key is configured.
+func NewJWTService(opts JWTOptions) JWTService {
+	s := &jwtService{
+		method:    jwt.SigningMethodHS256,
+		signKey:   []byte(opts.Secret),
+		verifyKey: []byte(opts.Secret),
+		tokenTTL:  opts.TokenTTL,
+		issuer:    opts.Issuer,
+		audience:  opts.Audience,
+	}
+	if opts.PrivateKey != nil || opts.PublicKey != nil {
+		s.method = jwt.SigningMethodRS256
+		s.signKey = nil
+		s.verifyKey = opts.PublicKey
+		if opts.PrivateKey != nil {
+			s.signKey = opts.PrivateKey
+			if opts.PublicKey == nil {
+				s.verifyKey = &opts.PrivateKey.PublicKey
+			}
+		}
+	}
+	return s
 }
 
-func (s *jwtService) GenerateToken(userID string) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+// GenerateToken issues a token for userID with the given role claim; an
+// empty role is issued as RoleUser.
+func (s *jwtService) GenerateToken(userID string, role string) (string, error) {
+	if role == "" {
+		role = RoleUser
+	}
+	claims := jwt.MapClaims{
 		"user_id": userID,
-		"exp":     time.Now().Add(time.Hour * 72).Unix(),
-	})
+		"role":    role,
+		"exp":     time.Now().Add(s.tokenTTL).Unix(),
+	}
+	if s.issuer != "" {
+		claims["iss"] = s.issuer
+	}
+	if s.audience != "" {
+		claims["aud"] = s.audience
+	}
+	if s.signKey == nil {
+		return "", ErrSigningDisabled
+	}
+	token := jwt.NewWithClaims(s.method, claims)
 
-	return token.SignedString(s.secretKey)
+	return token.SignedString(s.signKey)
 }
 
+// ValidateToken parses tokenString and checks its signature, expiry and, when
+// configured, issuer and audience. Only the configured algorithm is accepted,
+// so an RS256 service cannot be handed an HS256 token signed with its public
+// key. Tokens without an exp claim, or without a non-empty user_id claim, are
+// rejected, so callers can rely on both being present.
 func (s *jwtService) ValidateToken(tokenString string) (*jwt.Token, error) {
-	return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, jwt.ErrSignatureInvalid
-		}
-		return s.secretKey, nil
-	})
+	parserOpts := []jwt.ParserOption{jwt.WithExpirationRequired(), jwt.WithValidMethods([]string{s.method.Alg()})}
+	if s.issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(s.issuer))
+	}
+	if s.audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(s.audience))
+	}
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return s.verifyKey, nil
+	}, parserOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, ErrMissingUserID
+	}
+	if userID, ok := claims["user_id"].(string); !ok || userID == "" {
+		return nil, ErrMissingUserID
+	}
+	return token, nil
+}
+
+// RoleFromClaims returns the role claim of a validated token, RoleUser when
+// it has none.
+func RoleFromClaims(claims jwt.MapClaims) string {
+	if role, ok := claims["role"].(string); ok && role != "" {
+		return role
+	}
+	return RoleUser
 }
@@ -0,0 +1,217 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testSecret = "test-secret"
+
+func signTestToken(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("SignedString() error = %v", err)
+	}
+	return token
+}
+
+func TestValidateTokenAcceptsGeneratedToken(t *testing.T) {
+	service := NewJWTService(JWTOptions{Secret: testSecret, TokenTTL: time.Hour})
+
+	tokenString, err := service.GenerateToken("user-1", RoleUser)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	token, err := service.ValidateToken(tokenString)
+	if err != nil {
+		t.Fatalf("ValidateToken() error = %v", err)
+	}
+	if got := token.Claims.(jwt.MapClaims)["user_id"]; got != "user-1" {
+		t.Fatalf("user_id = %v, want %q", got, "user-1")
+	}
+}
+
+func TestTokenRoleClaim(t *testing.T) {
+	service := NewJWTService(JWTOptions{Secret: testSecret, TokenTTL: time.Hour})
+
+	tokenString, err := service.GenerateToken("user-1", RoleAdmin)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	token, err := service.ValidateToken(tokenString)
+	if err != nil {
+		t.Fatalf("ValidateToken() error = %v", err)
+	}
+	if got := RoleFromClaims(token.Claims.(jwt.MapClaims)); got != RoleAdmin {
+		t.Fatalf("RoleFromClaims() = %q, want %q", got, RoleAdmin)
+	}
+
+	// Tokens issued before roles existed are regular users.
+	legacy, err := service.ValidateToken(signTestToken(t, jwt.MapClaims{"user_id": "user-1", "exp": time.Now().Add(time.Hour).Unix()}))
+	if err != nil {
+		t.Fatalf("ValidateToken() of a token without role error = %v", err)
+	}
+	if got := RoleFromClaims(legacy.Claims.(jwt.MapClaims)); got != RoleUser {
+		t.Fatalf("RoleFromClaims() without a role claim = %q, want %q", got, RoleUser)
+	}
+}
+
+func TestValidateTokenRejectsInvalidClaims(t *testing.T) {
+	tests := []struct {
+		name    string
+		claims  jwt.MapClaims
+		wantErr error
+	}{
+		{
+			name:    "expired",
+			claims:  jwt.MapClaims{"user_id": "user-1", "exp": time.Now().Add(-time.Minute).Unix()},
+			wantErr: jwt.ErrTokenExpired,
+		},
+		{
+			name:    "no exp",
+			claims:  jwt.MapClaims{"user_id": "user-1"},
+			wantErr: jwt.ErrTokenRequiredClaimMissing,
+		},
+		{
+			name:    "missing user_id",
+			claims:  jwt.MapClaims{"exp": time.Now().Add(time.Hour).Unix()},
+			wantErr: ErrMissingUserID,
+		},
+		{
+			name:    "empty user_id",
+			claims:  jwt.MapClaims{"user_id": "", "exp": time.Now().Add(time.Hour).Unix()},
+			wantErr: ErrMissingUserID,
+		},
+		{
+			name:    "non-string user_id",
+			claims:  jwt.MapClaims{"user_id": 42, "exp": time.Now().Add(time.Hour).Unix()},
+			wantErr: ErrMissingUserID,
+		},
+	}
+	service := NewJWTService(JWTOptions{Secret: testSecret, TokenTTL: time.Hour})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.ValidateToken(signTestToken(t, tt.claims))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ValidateToken() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateTokenRejectsWrongSecret(t *testing.T) {
+	tokenString, err := NewJWTService(JWTOptions{Secret: "other-secret", TokenTTL: time.Hour}).GenerateToken("user-1", RoleUser)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	if _, err := NewJWTService(JWTOptions{Secret: testSecret, TokenTTL: time.Hour}).ValidateToken(tokenString); !errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+		t.Fatalf("ValidateToken() error = %v, want %v", err, jwt.ErrTokenSignatureInvalid)
+	}
+}
+
+func TestValidateTokenChecksIssuerAndAudience(t *testing.T) {
+	production := NewJWTService(JWTOptions{Secret: testSecret, TokenTTL: time.Hour, Issuer: "messie", Audience: "messie-api"})
+	exp := time.Now().Add(time.Hour).Unix()
+
+	tokenString, err := production.GenerateToken("user-1", RoleUser)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	token, err := production.ValidateToken(tokenString)
+	if err != nil {
+		t.Fatalf("ValidateToken() error = %v", err)
+	}
+	if claims := token.Claims.(jwt.MapClaims); claims["iss"] != "messie" || claims["aud"] != "messie-api" {
+		t.Fatalf("claims = %v, want iss and aud set", claims)
+	}
+
+	tests := []struct {
+		name    string
+		claims  jwt.MapClaims
+		wantErr error
+	}{
+		{
+			name:    "staging issuer",
+			claims:  jwt.MapClaims{"user_id": "user-1", "exp": exp, "iss": "messie-staging", "aud": "messie-api"},
+			wantErr: jwt.ErrTokenInvalidIssuer,
+		},
+		{
+			name:    "other audience",
+			claims:  jwt.MapClaims{"user_id": "user-1", "exp": exp, "iss": "messie", "aud": "billing"},
+			wantErr: jwt.ErrTokenInvalidAudience,
+		},
+		{
+			name:    "no issuer",
+			claims:  jwt.MapClaims{"user_id": "user-1", "exp": exp, "aud": "messie-api"},
+			wantErr: jwt.ErrTokenRequiredClaimMissing,
+		},
+		{
+			name:    "no audience",
+			claims:  jwt.MapClaims{"user_id": "user-1", "exp": exp, "iss": "messie"},
+			wantErr: jwt.ErrTokenRequiredClaimMissing,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := production.ValidateToken(signTestToken(t, tt.claims))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ValidateToken() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRS256Tokens(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey() error = %v", err)
+	}
+	issuer := NewJWTService(JWTOptions{PrivateKey: key, TokenTTL: time.Hour})
+	verifier := NewJWTService(JWTOptions{PublicKey: &key.PublicKey, TokenTTL: time.Hour})
+
+	tokenString, err := issuer.GenerateToken("user-1", RoleUser)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	token, err := verifier.ValidateToken(tokenString)
+	if err != nil {
+		t.Fatalf("ValidateToken() with the public key error = %v", err)
+	}
+	if token.Method.Alg() != "RS256" {
+		t.Fatalf("alg = %s, want RS256", token.Method.Alg())
+	}
+	if _, err := issuer.ValidateToken(tokenString); err != nil {
+		t.Fatalf("ValidateToken() with the private key error = %v", err)
+	}
+
+	if _, err := verifier.GenerateToken("user-1", RoleUser); !errors.Is(err, ErrSigningDisabled) {
+		t.Fatalf("GenerateToken() without a private key error = %v, want ErrSigningDisabled", err)
+	}
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey() error = %v", err)
+	}
+	forged, err := NewJWTService(JWTOptions{PrivateKey: otherKey, TokenTTL: time.Hour}).GenerateToken("user-1", RoleUser)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	if _, err := verifier.ValidateToken(forged); !errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+		t.Fatalf("ValidateToken() of another key's token error = %v, want %v", err, jwt.ErrTokenSignatureInvalid)
+	}
+
+	hs256, err := NewJWTService(JWTOptions{Secret: testSecret, TokenTTL: time.Hour}).GenerateToken("user-1", RoleUser)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	if _, err := verifier.ValidateToken(hs256); !errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+		t.Fatalf("ValidateToken() of an HS256 token error = %v, want %v", err, jwt.ErrTokenSignatureInvalid)
+	}
+}
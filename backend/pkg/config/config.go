@@ -0,0 +1,345 @@
+// Package config reads the API server's settings from the environment.
+//
+// Load checks every variable before returning, so a misconfigured deployment
+// learns about all of its problems in one startup attempt rather than one
+// per restart.
+package config
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"messenger/backend/pkg/secretbox"
+)
+
+// Config holds the API server's settings.
+type Config struct {
+	DatabaseURL string
+	// DBQueryTimeout bounds each database statement an API request runs
+	// (DB_QUERY_TIMEOUT, default 10s).
+	DBQueryTimeout time.Duration
+	// JWTAlgorithm is "HS256" (JWT_ALGORITHM, the default), signing with
+	// JWTSecret (JWT_SECRET), or "RS256", signing with JWTPrivateKey and
+	// validating with JWTPublicKey, read from the PEM files named by
+	// JWT_PRIVATE_KEY_FILE and JWT_PUBLIC_KEY_FILE. Either file may be left
+	// out: the public key is derived from the private one, and without a
+	// private key tokens can be validated but not issued.
+	JWTAlgorithm  string
+	JWTSecret     string
+	JWTPrivateKey *rsa.PrivateKey
+	JWTPublicKey  *rsa.PublicKey
+	// JWTTTL is how long issued tokens stay valid (JWT_TTL, default 72h).
+	JWTTTL time.Duration
+	// JWTIssuer and JWTAudience are the iss and aud claims tokens are issued
+	// with and must carry (JWT_ISSUER, default "messie"; JWT_AUDIENCE,
+	// default "messie-api"). Give each environment its own values.
+	JWTIssuer   string
+	JWTAudience string
+	Port        string
+	// CORSAllowedOrigins are the browser origins allowed to call the API
+	// (CORS_ALLOWED_ORIGINS, default the local Vite dev server).
+	CORSAllowedOrigins []string
+	// IMAPTimeout bounds each email request (IMAP_TIMEOUT); zero leaves the
+	// email handler's default in place.
+	IMAPTimeout time.Duration
+	// IMAPAllowedHosts may be empty, meaning the email handler's defaults.
+	IMAPAllowedHosts         []string
+	IMAPAllowPrivateNetworks bool
+	// IMAPAllowPlaintext permits unencrypted IMAP logins (security "none").
+	IMAPAllowPlaintext bool
+	// EmailHeaderCache is where /email/headers caches envelopes: "memory"
+	// (the default) or "postgres", which keeps the memory cache in front.
+	EmailHeaderCache string
+	// MaxRequestBody caps request bodies in bytes (MAX_REQUEST_BODY_BYTES,
+	// default 1 MiB); MaxUploadBody applies to calendar file uploads instead
+	// (MAX_UPLOAD_BODY_BYTES, default 32 MiB).
+	MaxRequestBody int64
+	MaxUploadBody  int64
+	// RateLimit is how many API requests a second each user, or each client
+	// IP when unauthenticated, may make on average (RATE_LIMIT_PER_SECOND,
+	// default 20; 0 turns limiting off), with bursts of up to RateLimitBurst
+	// (RATE_LIMIT_BURST, default 40).
+	RateLimit      float64
+	RateLimitBurst int
+	// TrustedProxies are the addresses of reverse proxies whose
+	// X-Forwarded-For and X-Real-IP headers name the client (TRUSTED_PROXIES,
+	// comma-separated IPs or CIDRs, default none).
+	TrustedProxies []netip.Prefix
+	// MaxCollaborators caps the collaborators of one todo list
+	// (TODO_MAX_COLLABORATORS, default 50).
+	MaxCollaborators int
+	// MatrixTokenKey is the decoded MATRIX_TOKEN_KEY, or nil when unset.
+	MatrixTokenKey []byte
+	// EmailAccountKey is the decoded EMAIL_ACCOUNT_KEY, which encrypts the
+	// app passwords of registered email accounts, or nil when unset.
+	EmailAccountKey []byte
+
+	WABridgeBaseURL      string
+	WABridgeSharedSecret string
+	WABridgeDBPath       string
+}
+
+// Error lists every missing or invalid variable found by Load.
+type Error struct {
+	Problems []string
+}
+
+func (e *Error) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// Load reads the configuration from the process environment.
+func Load() (*Config, error) {
+	return FromLookup(os.LookupEnv)
+}
+
+// FromLookup reads the configuration through lookup, which has the signature
+// of os.LookupEnv. It returns an *Error naming every problem at once.
+func FromLookup(lookup func(string) (string, bool)) (*Config, error) {
+	env := &reader{lookup: lookup}
+	cfg := &Config{
+		DatabaseURL:              env.required("DATABASE_URL"),
+		DBQueryTimeout:           env.duration("DB_QUERY_TIMEOUT", 10*time.Second),
+		JWTAlgorithm:             strings.ToUpper(env.oneOf("JWT_ALGORITHM", "hs256", "rs256")),
+		JWTTTL:                   env.duration("JWT_TTL", 72*time.Hour),
+		JWTIssuer:                env.optional("JWT_ISSUER", "messie"),
+		JWTAudience:              env.optional("JWT_AUDIENCE", "messie-api"),
+		Port:                     env.port("PORT", "8080"),
+		CORSAllowedOrigins:       env.list("CORS_ALLOWED_ORIGINS", []string{"http://localhost:5173"}),
+		IMAPTimeout:              env.duration("IMAP_TIMEOUT", 0),
+		IMAPAllowedHosts:         env.list("IMAP_ALLOWED_HOSTS", nil),
+		IMAPAllowPrivateNetworks: env.boolean("IMAP_ALLOW_PRIVATE_NETWORKS"),
+		IMAPAllowPlaintext:       env.boolean("IMAP_ALLOW_PLAINTEXT"),
+		EmailHeaderCache:         env.oneOf("EMAIL_HEADER_CACHE", "memory", "postgres"),
+		MaxRequestBody:           env.bytes("MAX_REQUEST_BODY_BYTES", 1<<20),
+		MaxUploadBody:            env.bytes("MAX_UPLOAD_BODY_BYTES", 32<<20),
+		RateLimit:                env.rate("RATE_LIMIT_PER_SECOND", 20),
+		RateLimitBurst:           env.count("RATE_LIMIT_BURST", 40),
+		TrustedProxies:           env.prefixes("TRUSTED_PROXIES"),
+		MaxCollaborators:         env.count("TODO_MAX_COLLABORATORS", 50),
+		MatrixTokenKey:           env.key("MATRIX_TOKEN_KEY", secretbox.KeySize),
+		EmailAccountKey:          env.key("EMAIL_ACCOUNT_KEY", secretbox.KeySize),
+		WABridgeBaseURL:          env.optional("WA_BRIDGE_BASE_URL", "http://mautrix-whatsapp:29319"),
+		WABridgeSharedSecret:     env.optional("WA_BRIDGE_SHARED_SECRET", ""),
+		WABridgeDBPath:           env.optional("WA_BRIDGE_DB_PATH", "/bridge-data/mautrix-whatsapp.db"),
+	}
+	if cfg.JWTAlgorithm == "RS256" {
+		cfg.JWTPrivateKey, cfg.JWTPublicKey = env.rsaKeys("JWT_PRIVATE_KEY_FILE", "JWT_PUBLIC_KEY_FILE")
+	} else {
+		cfg.JWTSecret = env.required("JWT_SECRET")
+	}
+	if len(env.problems) > 0 {
+		return nil, &Error{Problems: env.problems}
+	}
+	return cfg, nil
+}
+
+// reader collects problems instead of stopping at the first one.
+type reader struct {
+	lookup   func(string) (string, bool)
+	problems []string
+}
+
+func (r *reader) get(name string) string {
+	value, _ := r.lookup(name)
+	return strings.TrimSpace(value)
+}
+
+func (r *reader) fail(format string, args ...interface{}) {
+	r.problems = append(r.problems, fmt.Sprintf(format, args...))
+}
+
+func (r *reader) required(name string) string {
+	value := r.get(name)
+	if value == "" {
+		r.fail("%s is required", name)
+	}
+	return value
+}
+
+func (r *reader) optional(name, fallback string) string {
+	if value := r.get(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// duration parses a positive Go duration such as "30s" or "24h".
+func (r *reader) duration(name string, fallback time.Duration) time.Duration {
+	raw := r.get(name)
+	if raw == "" {
+		return fallback
+	}
+	value, err := time.ParseDuration(raw)
+	if err != nil || value <= 0 {
+		r.fail("%s must be a positive duration such as 30s or 24h, got %q", name, raw)
+		return fallback
+	}
+	return value
+}
+
+// bytes parses a positive byte count.
+func (r *reader) bytes(name string, fallback int64) int64 {
+	raw := r.get(name)
+	if raw == "" {
+		return fallback
+	}
+	value, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || value <= 0 {
+		r.fail("%s must be a positive number of bytes, got %q", name, raw)
+		return fallback
+	}
+	return value
+}
+
+// rate parses a non-negative number such as "2" or "0.5".
+func (r *reader) rate(name string, fallback float64) float64 {
+	raw := r.get(name)
+	if raw == "" {
+		return fallback
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil || value < 0 || math.IsInf(value, 0) || math.IsNaN(value) {
+		r.fail("%s must be a non-negative number, got %q", name, raw)
+		return fallback
+	}
+	return value
+}
+
+// count parses a positive whole number.
+func (r *reader) count(name string, fallback int) int {
+	raw := r.get(name)
+	if raw == "" {
+		return fallback
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value <= 0 {
+		r.fail("%s must be a positive whole number, got %q", name, raw)
+		return fallback
+	}
+	return value
+}
+
+func (r *reader) boolean(name string) bool {
+	raw := r.get(name)
+	if raw == "" {
+		return false
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		r.fail("%s must be true or false, got %q", name, raw)
+	}
+	return value
+}
+
+// oneOf accepts one of choices, the first being the default.
+func (r *reader) oneOf(name string, choices ...string) string {
+	raw := strings.ToLower(r.get(name))
+	if raw == "" {
+		return choices[0]
+	}
+	for _, choice := range choices {
+		if raw == choice {
+			return raw
+		}
+	}
+	r.fail("%s must be one of %s, got %q", name, strings.Join(choices, ", "), raw)
+	return choices[0]
+}
+
+func (r *reader) port(name, fallback string) string {
+	raw := r.optional(name, fallback)
+	if n, err := strconv.Atoi(raw); err != nil || n < 1 || n > 65535 {
+		r.fail("%s must be a TCP port number, got %q", name, raw)
+	}
+	return raw
+}
+
+// list splits a comma-separated value, dropping blanks.
+func (r *reader) list(name string, fallback []string) []string {
+	var values []string
+	for _, value := range strings.Split(r.get(name), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		return fallback
+	}
+	return values
+}
+
+// prefixes parses a comma-separated list of IP addresses and CIDR ranges; a
+// bare address is a range of one.
+func (r *reader) prefixes(name string) []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, raw := range r.list(name, nil) {
+		if prefix, err := netip.ParsePrefix(raw); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+		} else if addr, err := netip.ParseAddr(raw); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+		} else {
+			r.fail("%s must list IP addresses or CIDR ranges, got %q", name, raw)
+		}
+	}
+	return prefixes
+}
+
+// key decodes an optional base64 key of exactly size bytes.
+func (r *reader) key(name string, size int) []byte {
+	raw := r.get(name)
+	if raw == "" {
+		return nil
+	}
+	value, err := base64.StdEncoding.DecodeString(raw)
+	if err != nil || len(value) != size {
+		r.fail("%s must be %d bytes encoded as base64", name, size)
+		return nil
+	}
+	return value
+}
+
+// rsaKeys reads a PEM private and/or public key from the files named by the
+// two variables. At least one must be set, and when both are they must be
+// halves of the same key pair.
+func (r *reader) rsaKeys(privateName, publicName string) (*rsa.PrivateKey, *rsa.PublicKey) {
+	privatePath, publicPath := r.get(privateName), r.get(publicName)
+	if privatePath == "" && publicPath == "" {
+		r.fail("%s or %s is required for RS256", privateName, publicName)
+		return nil, nil
+	}
+
+	var private *rsa.PrivateKey
+	var public *rsa.PublicKey
+	if privatePath != "" {
+		pem, err := os.ReadFile(privatePath)
+		if err == nil {
+			private, err = jwt.ParseRSAPrivateKeyFromPEM(pem)
+		}
+		if err != nil {
+			r.fail("%s must name a PEM RSA private key: %v", privateName, err)
+		}
+	}
+	if publicPath != "" {
+		pem, err := os.ReadFile(publicPath)
+		if err == nil {
+			public, err = jwt.ParseRSAPublicKeyFromPEM(pem)
+		}
+		if err != nil {
+			r.fail("%s must name a PEM RSA public key: %v", publicName, err)
+		}
+	}
+	if private != nil && public != nil && !private.PublicKey.Equal(public) {
+		r.fail("%s and %s are not the same key pair", privateName, publicName)
+	}
+	return private, public
+}
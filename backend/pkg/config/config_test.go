@@ -0,0 +1,169 @@
+package config
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func lookupFrom(env map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+}
+
+func TestFromLookupDefaults(t *testing.T) {
+	cfg, err := FromLookup(lookupFrom(map[string]string{
+		"DATABASE_URL": "postgres://db",
+		"JWT_SECRET":   "secret",
+	}))
+	if err != nil {
+		t.Fatalf("FromLookup() error = %v", err)
+	}
+	if cfg.JWTTTL != 72*time.Hour || cfg.Port != "8080" || cfg.IMAPTimeout != 0 || cfg.MatrixTokenKey != nil || cfg.EmailAccountKey != nil || cfg.EmailHeaderCache != "memory" ||
+		cfg.MaxRequestBody != 1<<20 || cfg.MaxUploadBody != 32<<20 || cfg.RateLimit != 20 || cfg.RateLimitBurst != 40 ||
+		cfg.MaxCollaborators != 50 || cfg.DBQueryTimeout != 10*time.Second || cfg.TrustedProxies != nil {
+		t.Fatalf("cfg = %+v, want defaults", cfg)
+	}
+	if cfg.JWTIssuer != "messie" || cfg.JWTAudience != "messie-api" {
+		t.Fatalf("JWT issuer/audience = %q/%q, want messie/messie-api", cfg.JWTIssuer, cfg.JWTAudience)
+	}
+	if len(cfg.CORSAllowedOrigins) != 1 || cfg.CORSAllowedOrigins[0] != "http://localhost:5173" {
+		t.Fatalf("CORSAllowedOrigins = %v, want the Vite dev server", cfg.CORSAllowedOrigins)
+	}
+}
+
+func TestFromLookupParsesValues(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString(make([]byte, 32))
+	cfg, err := FromLookup(lookupFrom(map[string]string{
+		"DATABASE_URL":                "postgres://db",
+		"JWT_SECRET":                  "secret",
+		"JWT_TTL":                     "24h",
+		"PORT":                        "9000",
+		"CORS_ALLOWED_ORIGINS":        " https://a.example , ,https://b.example",
+		"IMAP_TIMEOUT":                "5s",
+		"IMAP_ALLOW_PRIVATE_NETWORKS": "true",
+		"MATRIX_TOKEN_KEY":            key,
+		"EMAIL_ACCOUNT_KEY":           key,
+		"RATE_LIMIT_PER_SECOND":       "0.5",
+		"RATE_LIMIT_BURST":            "3",
+		"TODO_MAX_COLLABORATORS":      "5",
+		"DB_QUERY_TIMEOUT":            "2s",
+		"TRUSTED_PROXIES":             "10.0.0.0/8, 192.0.2.7",
+	}))
+	if err != nil {
+		t.Fatalf("FromLookup() error = %v", err)
+	}
+	if cfg.JWTTTL != 24*time.Hour || cfg.Port != "9000" || cfg.IMAPTimeout != 5*time.Second || !cfg.IMAPAllowPrivateNetworks ||
+		cfg.RateLimit != 0.5 || cfg.RateLimitBurst != 3 || cfg.MaxCollaborators != 5 ||
+		cfg.DBQueryTimeout != 2*time.Second {
+		t.Fatalf("cfg = %+v", cfg)
+	}
+	if fmt.Sprint(cfg.TrustedProxies) != "[10.0.0.0/8 192.0.2.7/32]" {
+		t.Fatalf("TrustedProxies = %v", cfg.TrustedProxies)
+	}
+	if strings.Join(cfg.CORSAllowedOrigins, ",") != "https://a.example,https://b.example" {
+		t.Fatalf("CORSAllowedOrigins = %v", cfg.CORSAllowedOrigins)
+	}
+	if len(cfg.MatrixTokenKey) != 32 || len(cfg.EmailAccountKey) != 32 {
+		t.Fatalf("MatrixTokenKey/EmailAccountKey have %d/%d bytes, want 32", len(cfg.MatrixTokenKey), len(cfg.EmailAccountKey))
+	}
+}
+
+func TestFromLookupReportsEveryProblem(t *testing.T) {
+	_, err := FromLookup(lookupFrom(map[string]string{
+		"JWT_TTL":                     "-1h",
+		"PORT":                        "http",
+		"IMAP_TIMEOUT":                "soon",
+		"IMAP_ALLOW_PRIVATE_NETWORKS": "sometimes",
+		"MATRIX_TOKEN_KEY":            "c2hvcnQ=",
+		"EMAIL_HEADER_CACHE":          "redis",
+		"MAX_REQUEST_BODY_BYTES":      "1MB",
+		"RATE_LIMIT_PER_SECOND":       "-1",
+		"RATE_LIMIT_BURST":            "0",
+		"TODO_MAX_COLLABORATORS":      "none",
+		"DB_QUERY_TIMEOUT":            "0s",
+		"TRUSTED_PROXIES":             "nginx",
+	}))
+	var cfgErr *Error
+	if !errors.As(err, &cfgErr) {
+		t.Fatalf("FromLookup() error = %v, want *Error", err)
+	}
+	for _, name := range []string{"DATABASE_URL", "JWT_SECRET", "JWT_TTL", "PORT", "IMAP_TIMEOUT", "IMAP_ALLOW_PRIVATE_NETWORKS", "MATRIX_TOKEN_KEY", "EMAIL_HEADER_CACHE", "MAX_REQUEST_BODY_BYTES", "RATE_LIMIT_PER_SECOND", "RATE_LIMIT_BURST", "TODO_MAX_COLLABORATORS", "DB_QUERY_TIMEOUT", "TRUSTED_PROXIES"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("error does not mention %s:\n%v", name, err)
+		}
+	}
+	if len(cfgErr.Problems) != 14 {
+		t.Fatalf("Problems = %q, want 14 entries", cfgErr.Problems)
+	}
+}
+
+func TestFromLookupRS256Keys(t *testing.T) {
+	writeKey := func(name, blockType string, der []byte) string {
+		path := filepath.Join(t.TempDir(), name)
+		if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+		return path
+	}
+	newKey := func() *rsa.PrivateKey {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatalf("rsa.GenerateKey() error = %v", err)
+		}
+		return key
+	}
+	publicPEM := func(key *rsa.PrivateKey) string {
+		der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		if err != nil {
+			t.Fatalf("MarshalPKIXPublicKey() error = %v", err)
+		}
+		return writeKey("public.pem", "PUBLIC KEY", der)
+	}
+	key := newKey()
+	privatePath := writeKey("private.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key))
+
+	cfg, err := FromLookup(lookupFrom(map[string]string{
+		"DATABASE_URL":         "postgres://db",
+		"JWT_ALGORITHM":        "RS256",
+		"JWT_PRIVATE_KEY_FILE": privatePath,
+		"JWT_PUBLIC_KEY_FILE":  publicPEM(key),
+	}))
+	if err != nil {
+		t.Fatalf("FromLookup() error = %v", err)
+	}
+	if cfg.JWTAlgorithm != "RS256" || cfg.JWTSecret != "" || !cfg.JWTPrivateKey.Equal(key) || !cfg.JWTPublicKey.Equal(&key.PublicKey) {
+		t.Fatalf("cfg = %+v, want the RS256 key pair and no secret", cfg)
+	}
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{name: "no key files", env: map[string]string{}, want: "JWT_PRIVATE_KEY_FILE or JWT_PUBLIC_KEY_FILE is required"},
+		{name: "unreadable file", env: map[string]string{"JWT_PUBLIC_KEY_FILE": filepath.Join(t.TempDir(), "missing.pem")}, want: "JWT_PUBLIC_KEY_FILE must name a PEM RSA public key"},
+		{name: "mismatched pair", env: map[string]string{"JWT_PRIVATE_KEY_FILE": privatePath, "JWT_PUBLIC_KEY_FILE": publicPEM(newKey())}, want: "not the same key pair"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.env["DATABASE_URL"] = "postgres://db"
+			tt.env["JWT_ALGORITHM"] = "rs256"
+			_, err := FromLookup(lookupFrom(tt.env))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("FromLookup() error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package database
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+func TestEmbeddedMigrationsHaveUpAndDown(t *testing.T) {
+	source, err := iofs.New(migrationFiles, "migrations")
+	if err != nil {
+		t.Fatalf("iofs.New() error = %v", err)
+	}
+	defer source.Close()
+
+	version, err := source.First()
+	if err != nil {
+		t.Fatalf("First() error = %v", err)
+	}
+	for {
+		up, _, err := source.ReadUp(version)
+		if err != nil {
+			t.Fatalf("ReadUp(%d) error = %v", version, err)
+		}
+		up.Close()
+		down, _, err := source.ReadDown(version)
+		if err != nil {
+			t.Fatalf("ReadDown(%d) error = %v", version, err)
+		}
+		down.Close()
+
+		version, err = source.Next(version)
+		if errors.Is(err, fs.ErrNotExist) {
+			return
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+DROP TABLE IF EXISTS user_plans;
+DROP TABLE IF EXISTS usage_counters;
+DROP TABLE IF EXISTS user_plan_overrides;
+DROP TABLE IF EXISTS plan_limits;
+DROP TABLE IF EXISTS plans;
+DROP TABLE IF EXISTS bridge_pairings;
+DROP TABLE IF EXISTS user_bridge_accounts;
+DROP TABLE IF EXISTS providers;
+DROP TABLE IF EXISTS idempotency_keys;
+DROP TABLE IF EXISTS calendar_events;
+DROP TABLE IF EXISTS calendar_sources;
+DROP TABLE IF EXISTS todo_list_collaborators;
+DROP TABLE IF EXISTS todo_items;
+DROP TABLE IF EXISTS todo_lists;
+DROP TABLE IF EXISTS users;
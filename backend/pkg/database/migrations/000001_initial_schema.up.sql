@@ -0,0 +1,198 @@
+-- Baseline schema. Every statement is IF NOT EXISTS (and index names follow
+-- GORM's conventions) so databases previously set up by AutoMigrate adopt
+-- this migration without changes.
+
+CREATE TABLE IF NOT EXISTS users (
+    id            uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    username      varchar(255) NOT NULL,
+    matrix_id     varchar(255) CONSTRAINT uni_users_matrix_id UNIQUE,
+    email         varchar(255) NOT NULL CONSTRAINT uni_users_email UNIQUE,
+    password_hash varchar(255) NOT NULL,
+    created_at    timestamptz,
+    updated_at    timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS todo_lists (
+    id          uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    title       text NOT NULL,
+    description text NOT NULL,
+    owner_id    uuid NOT NULL,
+    created_at  timestamptz,
+    updated_at  timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS todo_items (
+    id          uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    list_id     uuid NOT NULL,
+    position    text NOT NULL,
+    title       text NOT NULL,
+    description text NOT NULL,
+    deadline    timestamptz,
+    completed   boolean DEFAULT false,
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    deleted_at  timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_todo_items_deleted_at ON todo_items (deleted_at);
+
+CREATE TABLE IF NOT EXISTS todo_list_collaborators (
+    todo_list_id    uuid NOT NULL,
+    collaborator_id uuid NOT NULL,
+    created_at      timestamptz,
+    updated_at      timestamptz,
+    PRIMARY KEY (todo_list_id, collaborator_id)
+);
+
+CREATE TABLE IF NOT EXISTS calendar_sources (
+    id                      uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id                 uuid NOT NULL,
+    kind                    text NOT NULL,
+    display_name            text NOT NULL,
+    category                text NOT NULL DEFAULT 'My Calendars',
+    import_mode             text NOT NULL,
+    source_url              text,
+    refresh_state           text NOT NULL,
+    last_synced_at          timestamptz,
+    last_refresh_attempt_at timestamptz,
+    last_refresh_error      text,
+    e_tag                   text,
+    last_modified           timestamptz,
+    next_refresh_at         timestamptz,
+    created_at              timestamptz,
+    updated_at              timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_calendar_sources_user_id ON calendar_sources (user_id);
+CREATE INDEX IF NOT EXISTS idx_calendar_sources_next_refresh_at ON calendar_sources (next_refresh_at);
+
+-- Older databases were created before category was mandatory.
+UPDATE calendar_sources SET category = 'My Calendars' WHERE category IS NULL OR btrim(category) = '';
+ALTER TABLE calendar_sources ALTER COLUMN category SET DEFAULT 'My Calendars';
+ALTER TABLE calendar_sources ALTER COLUMN category SET NOT NULL;
+
+CREATE TABLE IF NOT EXISTS calendar_events (
+    id             uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    source_id      uuid NOT NULL CONSTRAINT fk_calendar_sources_events
+                       REFERENCES calendar_sources (id) ON DELETE CASCADE,
+    external_uid   text NOT NULL,
+    title          text NOT NULL,
+    description    text NOT NULL,
+    location       text NOT NULL,
+    starts_at      timestamptz NOT NULL,
+    ends_at        timestamptz NOT NULL,
+    all_day        boolean DEFAULT false,
+    status         text NOT NULL,
+    timezone       text NOT NULL,
+    recurrence_raw text,
+    raw_ics_blob   text,
+    created_at     timestamptz,
+    updated_at     timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_calendar_events_source_id ON calendar_events (source_id);
+CREATE INDEX IF NOT EXISTS idx_calendar_events_external_uid ON calendar_events (external_uid);
+CREATE INDEX IF NOT EXISTS idx_calendar_events_starts_at ON calendar_events (starts_at);
+CREATE INDEX IF NOT EXISTS idx_calendar_events_ends_at ON calendar_events (ends_at);
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    user_id      uuid NOT NULL,
+    key          text NOT NULL,
+    request_hash text NOT NULL,
+    status_code  bigint NOT NULL DEFAULT 0,
+    content_type text,
+    body         bytea,
+    created_at   timestamptz NOT NULL,
+    PRIMARY KEY (user_id, key)
+);
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created_at ON idempotency_keys (created_at);
+
+CREATE TABLE IF NOT EXISTS providers (
+    id           uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    key          varchar(64) NOT NULL,
+    display_name varchar(128) NOT NULL,
+    status       varchar(32) NOT NULL DEFAULT 'active',
+    capabilities jsonb,
+    created_at   timestamptz,
+    updated_at   timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_providers_key ON providers (key);
+
+CREATE TABLE IF NOT EXISTS user_bridge_accounts (
+    id          uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id     uuid NOT NULL,
+    provider_id uuid NOT NULL,
+    external_id varchar(255) NOT NULL,
+    status      varchar(32) NOT NULL DEFAULT 'connected',
+    metadata    jsonb,
+    created_at  timestamptz,
+    updated_at  timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_user_bridge_accounts_user_id ON user_bridge_accounts (user_id);
+CREATE INDEX IF NOT EXISTS idx_user_bridge_accounts_provider_id ON user_bridge_accounts (provider_id);
+
+CREATE TABLE IF NOT EXISTS bridge_pairings (
+    id          uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id     uuid NOT NULL,
+    provider_id uuid NOT NULL,
+    pairing_id  varchar(128) NOT NULL,
+    state       varchar(32) NOT NULL DEFAULT 'pending',
+    payload     jsonb,
+    expires_at  timestamptz,
+    created_at  timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_bridge_pairings_user_id ON bridge_pairings (user_id);
+CREATE INDEX IF NOT EXISTS idx_bridge_pairings_provider_id ON bridge_pairings (provider_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bridge_pairings_pairing_id ON bridge_pairings (pairing_id);
+CREATE INDEX IF NOT EXISTS idx_bridge_pairings_expires_at ON bridge_pairings (expires_at);
+
+CREATE TABLE IF NOT EXISTS plans (
+    id         uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    key        varchar(64) NOT NULL,
+    name       varchar(128) NOT NULL,
+    created_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_plans_key ON plans (key);
+
+CREATE TABLE IF NOT EXISTS plan_limits (
+    id          uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    plan_id     uuid NOT NULL,
+    provider_id uuid,
+    limit_key   varchar(64) NOT NULL,
+    value       bigint NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_plan_limits_plan_id ON plan_limits (plan_id);
+CREATE INDEX IF NOT EXISTS idx_plan_limits_provider_id ON plan_limits (provider_id);
+CREATE INDEX IF NOT EXISTS idx_plan_limits_limit_key ON plan_limits (limit_key);
+
+CREATE TABLE IF NOT EXISTS user_plan_overrides (
+    id          uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id     uuid NOT NULL,
+    provider_id uuid,
+    limit_key   varchar(64) NOT NULL,
+    value       bigint NOT NULL,
+    updated_at  timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_user_plan_overrides_user_id ON user_plan_overrides (user_id);
+CREATE INDEX IF NOT EXISTS idx_user_plan_overrides_provider_id ON user_plan_overrides (provider_id);
+CREATE INDEX IF NOT EXISTS idx_user_plan_overrides_limit_key ON user_plan_overrides (limit_key);
+
+CREATE TABLE IF NOT EXISTS usage_counters (
+    id           uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id      uuid NOT NULL,
+    provider_id  uuid NOT NULL,
+    key          varchar(64) NOT NULL,
+    value        bigint NOT NULL,
+    window_start timestamptz NOT NULL,
+    window_end   timestamptz NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_usage_counters_user_id ON usage_counters (user_id);
+CREATE INDEX IF NOT EXISTS idx_usage_counters_provider_id ON usage_counters (provider_id);
+CREATE INDEX IF NOT EXISTS idx_usage_counters_key ON usage_counters (key);
+CREATE INDEX IF NOT EXISTS idx_usage_counters_window_start ON usage_counters (window_start);
+CREATE INDEX IF NOT EXISTS idx_usage_counters_window_end ON usage_counters (window_end);
+
+CREATE TABLE IF NOT EXISTS user_plans (
+    id         uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id    uuid NOT NULL,
+    plan_id    uuid NOT NULL,
+    created_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_plans_user_id ON user_plans (user_id);
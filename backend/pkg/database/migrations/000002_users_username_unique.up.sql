@@ -0,0 +1,5 @@
+-- Usernames are optional (empty until chosen) but unique ignoring case once
+-- set, so PATCH /users/me cannot race two users onto the same name.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username_lower
+    ON users (lower(username))
+    WHERE username <> '';
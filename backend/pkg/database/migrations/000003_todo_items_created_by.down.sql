@@ -0,0 +1 @@
+ALTER TABLE todo_items DROP COLUMN IF EXISTS created_by;
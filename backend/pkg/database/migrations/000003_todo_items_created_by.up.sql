@@ -0,0 +1,8 @@
+-- Items created before the column existed are attributed to the list owner.
+ALTER TABLE todo_items ADD COLUMN IF NOT EXISTS created_by uuid;
+
+UPDATE todo_items
+SET created_by = todo_lists.owner_id
+FROM todo_lists
+WHERE todo_lists.id = todo_items.list_id
+  AND todo_items.created_by IS NULL;
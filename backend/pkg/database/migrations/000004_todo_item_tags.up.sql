@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS todo_item_tags (
+    item_id uuid NOT NULL REFERENCES todo_items (id) ON DELETE CASCADE,
+    tag     varchar(64) NOT NULL,
+    PRIMARY KEY (item_id, tag)
+);
+CREATE INDEX IF NOT EXISTS idx_todo_item_tags_tag ON todo_item_tags (tag);
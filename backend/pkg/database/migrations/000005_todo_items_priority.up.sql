@@ -0,0 +1,3 @@
+ALTER TABLE todo_items
+    ADD COLUMN IF NOT EXISTS priority varchar(8) NOT NULL DEFAULT 'medium'
+    CONSTRAINT chk_todo_items_priority CHECK (priority IN ('low', 'medium', 'high'));
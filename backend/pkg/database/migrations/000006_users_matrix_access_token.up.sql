@@ -0,0 +1,3 @@
+-- Matrix client-server access token, encrypted with MATRIX_TOKEN_KEY; empty
+-- when the user has not granted one.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS matrix_access_token text NOT NULL DEFAULT '';
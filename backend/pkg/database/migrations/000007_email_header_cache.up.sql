@@ -0,0 +1,10 @@
+-- Envelopes fetched by /email/headers when EMAIL_HEADER_CACHE=postgres.
+-- account is "<imap host>:<address>", both lowercased.
+CREATE TABLE IF NOT EXISTS email_header_cache (
+    account      text   NOT NULL,
+    mailbox      text   NOT NULL,
+    uid          bigint NOT NULL,
+    uid_validity bigint NOT NULL,
+    header       jsonb  NOT NULL,
+    PRIMARY KEY (account, mailbox, uid)
+);
@@ -0,0 +1,3 @@
+-- Incremented on every update; clients send the version they read so
+-- concurrent edits are rejected instead of overwriting each other.
+ALTER TABLE todo_items ADD COLUMN IF NOT EXISTS version bigint NOT NULL DEFAULT 1;
@@ -0,0 +1,3 @@
+-- IANA timezone used to compute "today"/"tomorrow" for the user; empty means
+-- UTC.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone varchar(64) NOT NULL DEFAULT '';
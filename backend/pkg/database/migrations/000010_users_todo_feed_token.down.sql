@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS users_todo_feed_token_hash_key;
+ALTER TABLE users DROP COLUMN IF EXISTS todo_feed_token_hash;
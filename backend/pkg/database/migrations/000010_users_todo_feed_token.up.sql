@@ -0,0 +1,4 @@
+-- SHA-256 of the secret in the user's todo deadline calendar feed URL; empty
+-- when no feed token has been issued.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS todo_feed_token_hash varchar(64) NOT NULL DEFAULT '';
+CREATE UNIQUE INDEX IF NOT EXISTS users_todo_feed_token_hash_key ON users (todo_feed_token_hash) WHERE todo_feed_token_hash <> '';
@@ -0,0 +1,13 @@
+-- Invite links to join a todo list as a collaborator. Only the SHA-256 of the
+-- token is stored; single-use invites are spent once accepted.
+CREATE TABLE IF NOT EXISTS todo_list_invites (
+    token_hash   varchar(64) PRIMARY KEY,
+    todo_list_id uuid NOT NULL REFERENCES todo_lists (id) ON DELETE CASCADE,
+    created_by   uuid NOT NULL,
+    single_use   boolean NOT NULL DEFAULT true,
+    expires_at   timestamptz NOT NULL,
+    used_at      timestamptz,
+    used_by      uuid,
+    created_at   timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_todo_list_invites_todo_list_id ON todo_list_invites (todo_list_id);
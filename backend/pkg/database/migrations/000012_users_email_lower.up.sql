@@ -0,0 +1,5 @@
+-- Email addresses are unique ignoring case. Existing addresses are folded to
+-- lower case first; this fails, rather than merging accounts, if two of them
+-- differ only in case.
+UPDATE users SET email = lower(email) WHERE email <> lower(email);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_lower ON users (lower(email));
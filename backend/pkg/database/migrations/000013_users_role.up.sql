@@ -0,0 +1,4 @@
+-- Role written into the user's JWTs. Everyone is a regular user; promote
+-- admins by hand with UPDATE users SET role = 'admin'.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role varchar(32) NOT NULL DEFAULT 'user';
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('user', 'admin'));
@@ -0,0 +1,14 @@
+-- IMAP logins registered through POST /email/accounts. The app password is
+-- sealed with EMAIL_ACCOUNT_KEY and never stored in the clear.
+CREATE TABLE IF NOT EXISTS email_accounts (
+    id              uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id         uuid NOT NULL,
+    host            text NOT NULL,
+    port            integer NOT NULL,
+    email           text NOT NULL,
+    security        text NOT NULL,
+    sealed_password text NOT NULL,
+    default_mailbox text NOT NULL DEFAULT 'INBOX',
+    created_at      timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_email_accounts_user_id ON email_accounts (user_id);
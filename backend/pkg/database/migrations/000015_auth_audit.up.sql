@@ -0,0 +1,17 @@
+-- Authentication events (logins, registrations, feed token changes, account
+-- deletions) for operators to investigate suspicious activity. actor_id has
+-- no foreign key so entries outlive deleted accounts.
+CREATE TABLE IF NOT EXISTS auth_audit (
+    id         uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_type varchar(32) NOT NULL,
+    outcome    varchar(16) NOT NULL,
+    actor_id   uuid,
+    identifier varchar(255) NOT NULL DEFAULT '',
+    reason     text NOT NULL DEFAULT '',
+    ip         varchar(64) NOT NULL DEFAULT '',
+    user_agent varchar(512) NOT NULL DEFAULT '',
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_auth_audit_created_at ON auth_audit (created_at);
+CREATE INDEX IF NOT EXISTS idx_auth_audit_actor_id ON auth_audit (actor_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_auth_audit_identifier ON auth_audit (identifier, created_at);
@@ -0,0 +1,88 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// queryBudget is what WithQueryTimeout attaches to a request's context.
+type queryBudget struct {
+	timeout  time.Duration
+	timedOut atomic.Bool
+}
+
+type queryBudgetKey struct{}
+
+const queryCancelKey = "database:cancel_query"
+
+// WithQueryTimeout returns a context under which each statement run through
+// a DB set up with LimitQueryTime is cancelled once it has taken longer than
+// timeout. The rest of the request is not bounded, so slow IMAP calls or long
+// streams are unaffected.
+func WithQueryTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, queryBudgetKey{}, &queryBudget{timeout: timeout})
+}
+
+// QueryTimedOut reports whether a statement run under ctx was cancelled by the
+// limit WithQueryTimeout set.
+func QueryTimedOut(ctx context.Context) bool {
+	budget, _ := ctx.Value(queryBudgetKey{}).(*queryBudget)
+	return budget != nil && budget.timedOut.Load()
+}
+
+// LimitQueryTime makes db honour WithQueryTimeout for creates, queries,
+// updates, deletes and raw statements. Row and Rows results are read after
+// their callbacks return, so those statements are left unbounded; that keeps
+// streamed results working. A statement cut off by the limit fails with an
+// error wrapping context.DeadlineExceeded.
+func LimitQueryTime(db *gorm.DB) error {
+	cb := db.Callback()
+	for _, p := range []struct {
+		operation     string
+		before, after func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", cb.Create().Before("*").Register, cb.Create().After("*").Register},
+		{"query", cb.Query().Before("*").Register, cb.Query().After("*").Register},
+		{"update", cb.Update().Before("*").Register, cb.Update().After("*").Register},
+		{"delete", cb.Delete().Before("*").Register, cb.Delete().After("*").Register},
+		{"raw", cb.Raw().Before("*").Register, cb.Raw().After("*").Register},
+	} {
+		if err := p.before("database:start_timeout_"+p.operation, startQueryTimeout); err != nil {
+			return err
+		}
+		if err := p.after("database:stop_timeout_"+p.operation, stopQueryTimeout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func startQueryTimeout(db *gorm.DB) {
+	budget, _ := db.Statement.Context.Value(queryBudgetKey{}).(*queryBudget)
+	if budget == nil || budget.timeout <= 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(db.Statement.Context, budget.timeout)
+	db.Statement.Context = ctx
+	db.InstanceSet(queryCancelKey, cancel)
+}
+
+func stopQueryTimeout(db *gorm.DB) {
+	v, ok := db.InstanceGet(queryCancelKey)
+	if !ok {
+		return
+	}
+	cancel, _ := v.(context.CancelFunc)
+	timedOut := db.Statement.Context.Err() == context.DeadlineExceeded && db.Error != nil
+	cancel()
+	if !timedOut {
+		return
+	}
+	budget := db.Statement.Context.Value(queryBudgetKey{}).(*queryBudget)
+	budget.timedOut.Store(true)
+	db.Error = fmt.Errorf("database statement exceeded %s: %w (%v)", budget.timeout, context.DeadlineExceeded, db.Error)
+}
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// slowQuery counts to a hundred million, which takes sqlite several seconds.
+const slowQuery = `WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c WHERE x < 100000000) SELECT count(*) FROM c`
+
+func newTimeoutTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	if err := LimitQueryTime(db); err != nil {
+		t.Fatalf("LimitQueryTime() error = %v", err)
+	}
+	return db
+}
+
+func TestLimitQueryTimeCancelsSlowQuery(t *testing.T) {
+	db := newTimeoutTestDB(t)
+	ctx := WithQueryTimeout(context.Background(), 50*time.Millisecond)
+
+	var fast int64
+	if err := db.WithContext(ctx).Raw("SELECT 1").Find(&fast).Error; err != nil || fast != 1 {
+		t.Fatalf("fast query = %d, %v, want 1", fast, err)
+	}
+	if QueryTimedOut(ctx) {
+		t.Fatal("QueryTimedOut() after a fast query = true")
+	}
+
+	start := time.Now()
+	var count int64
+	err := db.WithContext(ctx).Raw(slowQuery).Find(&count).Error
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("slow query error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("slow query returned after %s, want it cancelled", elapsed)
+	}
+	if !QueryTimedOut(ctx) {
+		t.Fatal("QueryTimedOut() after the slow query = false")
+	}
+}
+
+func TestLimitQueryTimeNeedsWithQueryTimeout(t *testing.T) {
+	db := newTimeoutTestDB(t)
+
+	var one int64
+	if err := db.WithContext(context.Background()).Raw("SELECT 1").Find(&one).Error; err != nil || one != 1 {
+		t.Fatalf("query without a timeout = %d, %v, want 1", one, err)
+	}
+	if QueryTimedOut(context.Background()) {
+		t.Fatal("QueryTimedOut() without WithQueryTimeout = true")
+	}
+}
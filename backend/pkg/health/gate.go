@@ -0,0 +1,53 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"messenger/backend/pkg/apierror"
+)
+
+// Gate lets the server listen before initialization is done. Until Open is
+// called it answers the liveness probe and the version endpoint, reports
+// "starting" with 503 on the readiness probe and turns every other request
+// away with 503, so orchestrators neither kill the starting process nor route
+// traffic to it while migrations run. Afterwards it hands every request to
+// the real router.
+type Gate struct {
+	handler atomic.Pointer[http.Handler]
+}
+
+// Open marks initialization as finished and starts serving through h.
+func (g *Gate) Open(h http.Handler) {
+	g.handler.Store(&h)
+}
+
+// Ready reports whether Open has been called.
+func (g *Gate) Ready() bool {
+	return g.handler.Load() != nil
+}
+
+func (g *Gate) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h := g.handler.Load(); h != nil {
+		(*h).ServeHTTP(w, r)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1")
+	switch path {
+	case "/health":
+		LiveHandler(w, r)
+	case "/version":
+		VersionHandler(w, r)
+	case "/health/ready":
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(ReadyResponse{Status: "starting", Database: CheckResult{Status: "pending"}})
+	default:
+		w.Header().Set("Retry-After", "5")
+		apierror.Write(w, http.StatusServiceUnavailable, "server is starting")
+	}
+}
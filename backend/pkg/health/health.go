@@ -0,0 +1,71 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"messenger/backend/pkg/middleware"
+)
+
+// Pinger is implemented by *sql.DB.
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// ReadyResponse is the body returned by the readiness probe.
+type ReadyResponse struct {
+	Status   string      `json:"status"`
+	Database CheckResult `json:"database"`
+}
+
+// CheckResult describes the outcome of a single dependency check.
+type CheckResult struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// LiveHandler reports that the process is up. It never touches dependencies,
+// so an unhealthy database does not get the instance restarted.
+func LiveHandler(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("ok"))
+}
+
+// ReadyHandler pings the database within timeout and answers 503 when it is
+// unreachable, so load balancers stop routing traffic to the instance. The
+// probe is public, so the driver error, which can name the database host and
+// user, is only logged.
+func ReadyHandler(db Pinger, timeout time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		start := time.Now()
+		err := db.PingContext(ctx)
+		latency := time.Since(start)
+
+		resp := ReadyResponse{
+			Status: "ok",
+			Database: CheckResult{
+				Status:    "ok",
+				LatencyMs: float64(latency.Microseconds()) / 1000,
+			},
+		}
+		statusCode := http.StatusOK
+		if err != nil {
+			resp.Status = "unavailable"
+			resp.Database.Status = "unavailable"
+			resp.Database.Error = "database unavailable"
+			middleware.Logf(r.Context(), "readiness check: database ping failed: %v", err)
+			statusCode = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(statusCode)
+		json.NewEncoder(w).Encode(resp)
+	}
+}
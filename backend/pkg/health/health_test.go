@@ -0,0 +1,109 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type pingerFunc func(ctx context.Context) error
+
+func (f pingerFunc) PingContext(ctx context.Context) error { return f(ctx) }
+
+func TestReadyHandlerReportsDatabaseStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		ping       pingerFunc
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "reachable",
+			ping:       func(ctx context.Context) error { return nil },
+			wantStatus: http.StatusOK,
+			wantBody:   "ok",
+		},
+		{
+			name:       "down",
+			ping:       func(ctx context.Context) error { return errors.New("dial tcp db.internal:5432: connection refused") },
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   "unavailable",
+		},
+		{
+			name: "slow",
+			ping: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   "unavailable",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			ReadyHandler(tt.ping, 10*time.Millisecond)(rec, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			var body ReadyResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			if body.Status != tt.wantBody || body.Database.Status != tt.wantBody {
+				t.Fatalf("body = %+v, want status %q", body, tt.wantBody)
+			}
+			if strings.Contains(rec.Body.String(), "db.internal") {
+				t.Fatalf("body = %s, must not reveal the driver error", rec.Body)
+			}
+		})
+	}
+}
+
+func TestGateServesProbesUntilOpened(t *testing.T) {
+	var gate Gate
+	serve := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		gate.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	for path, want := range map[string]int{
+		"/health":              http.StatusOK,
+		"/api/v1/health":       http.StatusOK,
+		"/version":             http.StatusOK,
+		"/api/v1/version":      http.StatusOK,
+		"/health/ready":        http.StatusServiceUnavailable,
+		"/api/v1/health/ready": http.StatusServiceUnavailable,
+		"/api/v1/todolists":    http.StatusServiceUnavailable,
+	} {
+		if rec := serve(path); rec.Code != want {
+			t.Fatalf("%s before Open: status = %d, want %d", path, rec.Code, want)
+		}
+	}
+	var body ReadyResponse
+	if err := json.Unmarshal(serve("/health/ready").Body.Bytes(), &body); err != nil || body.Status != "starting" {
+		t.Fatalf("readiness body = %+v, %v; want status starting", body, err)
+	}
+	if gate.Ready() {
+		t.Fatal("Ready() = true before Open")
+	}
+
+	gate.Open(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	if !gate.Ready() {
+		t.Fatal("Ready() = false after Open")
+	}
+	for _, path := range []string{"/health/ready", "/api/v1/todolists"} {
+		if rec := serve(path); rec.Code != http.StatusTeapot {
+			t.Fatalf("%s after Open: status = %d, want the router's answer", path, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+)
+
+// Build information, set at link time, e.g.
+//
+//	go build -ldflags "-X messenger/backend/pkg/health.commit=$(git rev-parse HEAD)"
+//
+// A build without them falls back to the VCS details the go tool embeds, if
+// any.
+var (
+	version   = "dev"
+	commit    = ""
+	buildTime = ""
+)
+
+// VersionResponse is the body returned by the version endpoint.
+type VersionResponse struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+// CurrentVersion describes the running build. Unknown fields are "unknown".
+func CurrentVersion() VersionResponse {
+	resp := VersionResponse{Version: version, Commit: commit, BuildTime: buildTime, GoVersion: runtime.Version()}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && resp.Commit == "":
+				resp.Commit = setting.Value
+			case setting.Key == "vcs.time" && resp.BuildTime == "":
+				resp.BuildTime = setting.Value
+			}
+		}
+	}
+	if resp.Commit == "" {
+		resp.Commit = "unknown"
+	}
+	if resp.BuildTime == "" {
+		resp.BuildTime = "unknown"
+	}
+	return resp
+}
+
+// VersionHandler reports which build is running, so a deploy can be
+// confirmed and bug reports can name the exact version.
+func VersionHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(CurrentVersion())
+}
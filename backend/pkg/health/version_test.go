@@ -0,0 +1,39 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+)
+
+func TestVersionHandlerReportsLinkedBuildInfo(t *testing.T) {
+	defer func(v, c, b string) { version, commit, buildTime = v, c, b }(version, commit, buildTime)
+	version, commit, buildTime = "1.2.3", "0123abcd", "2026-01-02T03:04:05Z"
+
+	rec := httptest.NewRecorder()
+	VersionHandler(rec, httptest.NewRequest(http.MethodGet, "/version", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	var body VersionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	want := VersionResponse{Version: "1.2.3", Commit: "0123abcd", BuildTime: "2026-01-02T03:04:05Z", GoVersion: runtime.Version()}
+	if body != want {
+		t.Fatalf("body = %+v, want %+v", body, want)
+	}
+}
+
+func TestCurrentVersionFillsUnknownFields(t *testing.T) {
+	defer func(c, b string) { commit, buildTime = c, b }(commit, buildTime)
+	commit, buildTime = "", ""
+
+	got := CurrentVersion()
+	if got.Commit == "" || got.BuildTime == "" || got.GoVersion != runtime.Version() {
+		t.Fatalf("CurrentVersion() = %+v, want every field set", got)
+	}
+}
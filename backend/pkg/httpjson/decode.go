@@ -0,0 +1,104 @@
+// Package httpjson decodes JSON request bodies strictly and turns decoding
+// failures into messages a client can act on.
+package httpjson
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// Error describes why a request body was rejected. Field is the JSON path of
+// the offending member, empty when the problem is the body as a whole.
+type Error struct {
+	Field   string
+	Message string
+	err     error
+}
+
+func (e *Error) Error() string { return e.Message }
+
+// Unwrap exposes the underlying decoder error, e.g. *http.MaxBytesError.
+func (e *Error) Unwrap() error { return e.err }
+
+// Decode reads r's body as exactly one JSON value of type T. Unknown object
+// members and trailing data after the value are rejected; every failure is
+// returned as an *Error.
+func Decode[T any](r *http.Request) (T, error) {
+	var v T
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&v); err != nil {
+		return v, describe(err)
+	}
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		return v, &Error{Message: "request body must contain a single JSON value", err: err}
+	}
+	return v, nil
+}
+
+func describe(err error) *Error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.Is(err, io.EOF):
+		return &Error{Message: "request body is empty", err: err}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return &Error{Message: "request body is truncated JSON", err: err}
+	case errors.As(err, &syntaxErr):
+		return &Error{Message: fmt.Sprintf("request body is not valid JSON (at byte %d)", syntaxErr.Offset), err: err}
+	case errors.As(err, &typeErr):
+		if typeErr.Field == "" {
+			return &Error{Message: fmt.Sprintf("request body must be %s", jsonType(typeErr.Type)), err: err}
+		}
+		return &Error{
+			Field:   typeErr.Field,
+			Message: fmt.Sprintf("field %q must be %s", typeErr.Field, jsonType(typeErr.Type)),
+			err:     err,
+		}
+	case errors.As(err, &tooLarge):
+		return &Error{Message: fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit), err: err}
+	}
+	// encoding/json reports unknown members only as `json: unknown field "x"`.
+	if name, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		field := strings.Trim(name, `"`)
+		return &Error{Field: field, Message: fmt.Sprintf("unknown field %q", field), err: err}
+	}
+	return &Error{Message: strings.TrimPrefix(err.Error(), "json: "), err: err}
+}
+
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+// jsonType names the JSON type a Go value of type t is decoded from.
+func jsonType(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		// UUIDs, timestamps and the like travel as strings.
+		return "a string"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "an integer"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "a non-negative integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	}
+	return "a " + t.String()
+}
@@ -0,0 +1,80 @@
+package httpjson
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type payload struct {
+	Title    string     `json:"title"`
+	Done     *bool      `json:"done,omitempty"`
+	Position int        `json:"position"`
+	Owner    uuid.UUID  `json:"owner"`
+	Due      *time.Time `json:"due,omitempty"`
+	Items    []struct {
+		Name string `json:"name"`
+	} `json:"items"`
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		wantField string
+		wantMsg   string
+	}{
+		{name: "valid", body: `{"title":"a","position":2,"items":[{"name":"x"}]}`},
+		{name: "empty", body: ``, wantMsg: "request body is empty"},
+		{name: "truncated", body: `{"title":`, wantMsg: "request body is truncated JSON"},
+		{name: "syntax", body: `{"title" "a"}`, wantMsg: "request body is not valid JSON (at byte 10)"},
+		{name: "trailing value", body: `{"title":"a"} {"title":"b"}`, wantMsg: "request body must contain a single JSON value"},
+		{name: "trailing garbage", body: `{"title":"a"}]`, wantMsg: "request body must contain a single JSON value"},
+		{name: "unknown field", body: `{"title":"a","colour":"red"}`, wantField: "colour", wantMsg: `unknown field "colour"`},
+		{name: "string for bool", body: `{"done":"yes"}`, wantField: "done", wantMsg: `field "done" must be a boolean`},
+		{name: "number for string", body: `{"title":5}`, wantField: "title", wantMsg: `field "title" must be a string`},
+		{name: "fraction for int", body: `{"position":1.5}`, wantField: "position", wantMsg: `field "position" must be an integer`},
+		{name: "number for uuid", body: `{"owner":7}`, wantField: "owner", wantMsg: `field "owner" must be a string`},
+		{name: "nested field", body: `{"items":[{"name":false}]}`, wantField: "items.0.name", wantMsg: `field "items.0.name" must be a string`},
+		{name: "array for object", body: `[]`, wantMsg: "request body must be an object"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			got, err := Decode[payload](req)
+			if tt.wantMsg == "" {
+				if err != nil {
+					t.Fatalf("Decode() error = %v", err)
+				}
+				if got.Title != "a" || got.Position != 2 || len(got.Items) != 1 {
+					t.Fatalf("Decode() = %+v", got)
+				}
+				return
+			}
+			var decodeErr *Error
+			if !errors.As(err, &decodeErr) {
+				t.Fatalf("Decode() error = %v, want *Error", err)
+			}
+			if decodeErr.Message != tt.wantMsg || decodeErr.Field != tt.wantField {
+				t.Fatalf("Decode() error = %q at %q, want %q at %q", decodeErr.Message, decodeErr.Field, tt.wantMsg, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestDecodeKeepsBodyLimitError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"title":"`+strings.Repeat("x", 64)+`"}`))
+	req.Body = http.MaxBytesReader(rec, req.Body, 16)
+
+	_, err := Decode[payload](req)
+	var tooLarge *http.MaxBytesError
+	if !errors.As(err, &tooLarge) {
+		t.Fatalf("Decode() error = %v, want it to wrap *http.MaxBytesError", err)
+	}
+}
@@ -0,0 +1,267 @@
+// Package idempotency lets clients safely retry create requests by sending
+// an Idempotency-Key header. The first successful response for a key is stored
+// and replayed for any repeat of the same request by the same user, so a retry
+// after a dropped connection does not create the resource twice.
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"messenger/backend/pkg/apierror"
+	"messenger/backend/pkg/middleware"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// HeaderKey is the request header carrying the client-chosen key.
+const HeaderKey = "Idempotency-Key"
+
+// HeaderReplayed is set on responses served from a stored record.
+const HeaderReplayed = "Idempotent-Replayed"
+
+// DefaultTTL is how long a key is remembered.
+const DefaultTTL = 24 * time.Hour
+
+const maxKeyLength = 255
+
+// Record is the stored outcome of a request made with an idempotency key.
+// StatusCode is zero while the original request is still being handled.
+type Record struct {
+	UserID      string    `gorm:"type:uuid;primaryKey"`
+	Key         string    `gorm:"type:text;primaryKey"`
+	RequestHash string    `gorm:"type:text;not null"`
+	StatusCode  int       `gorm:"not null;default:0"`
+	ContentType string    `gorm:"type:text"`
+	Body        []byte    `gorm:"type:bytea"`
+	CreatedAt   time.Time `gorm:"index;not null"`
+}
+
+// TableName keeps the table name stable regardless of the struct name.
+func (Record) TableName() string {
+	return "idempotency_keys"
+}
+
+// Store persists idempotency records.
+type Store struct {
+	db  *gorm.DB
+	ttl time.Duration
+	now func() time.Time
+}
+
+// NewStore creates a Store whose keys expire after ttl; a non-positive ttl
+// uses DefaultTTL.
+func NewStore(db *gorm.DB, ttl time.Duration) *Store {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Store{db: db, ttl: ttl, now: time.Now}
+}
+
+// reserve claims (userID, key) for a new request. When the key is already
+// taken by a live record, that record is returned instead and nothing is
+// stored.
+func (s *Store) reserve(ctx context.Context, userID, key, requestHash string) (*Record, error) {
+	record := &Record{UserID: userID, Key: key, RequestHash: requestHash, CreatedAt: s.now()}
+
+	// An expired record for the same key is dropped first so the key can be
+	// reused once its window has passed.
+	err := s.db.WithContext(ctx).
+		Where("user_id = ? AND key = ? AND created_at < ?", userID, key, s.now().Add(-s.ttl)).
+		Delete(&Record{}).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to expire idempotency key: %w", err)
+	}
+
+	result := s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to reserve idempotency key: %w", result.Error)
+	}
+	if result.RowsAffected == 1 {
+		return nil, nil
+	}
+
+	var existing Record
+	err = s.db.WithContext(ctx).Where("user_id = ? AND key = ?", userID, key).First(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Released between our insert attempt and the lookup; let the caller
+		// treat it as in flight rather than racing again.
+		return &Record{UserID: userID, Key: key, RequestHash: requestHash}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load idempotency key: %w", err)
+	}
+	return &existing, nil
+}
+
+func (s *Store) complete(ctx context.Context, userID, key string, status int, contentType string, body []byte) error {
+	err := s.db.WithContext(ctx).Model(&Record{}).
+		Where("user_id = ? AND key = ?", userID, key).
+		Updates(map[string]interface{}{"status_code": status, "content_type": contentType, "body": body}).Error
+	if err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) release(ctx context.Context, userID, key string) error {
+	err := s.db.WithContext(ctx).Where("user_id = ? AND key = ?", userID, key).Delete(&Record{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// PurgeExpired deletes records older than the store's TTL.
+func (s *Store) PurgeExpired(ctx context.Context) (int64, error) {
+	result := s.db.WithContext(ctx).Where("created_at < ?", s.now().Add(-s.ttl)).Delete(&Record{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to purge idempotency keys: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// Middleware makes authenticated POST requests carrying an Idempotency-Key
+// header idempotent per user, for the operations of spec whose operationId
+// is listed in operationIDs (compared case-insensitively, like
+// RequestValidator's). Other requests pass through untouched and their
+// responses are never stored, since only creates need it and other POSTs
+// answer with message bodies or attachments. It must run after
+// AuthMiddleware. Only 2xx responses are remembered; after any other
+// outcome, a panic included, the key is freed so the client can retry.
+// Reusing a key for a different request is rejected with 422, and a repeat
+// that arrives while the original is still running gets 409.
+func Middleware(store *Store, spec *openapi3.T, operationIDs ...string) (func(next http.Handler) http.Handler, error) {
+	router, err := legacy.NewRouter(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build OpenAPI router: %w", err)
+	}
+	allowed := make(map[string]struct{}, len(operationIDs))
+	for _, id := range operationIDs {
+		allowed[strings.ToLower(id)] = struct{}{}
+	}
+	covered := func(r *http.Request) bool {
+		route, _, err := router.FindRoute(r)
+		if err != nil {
+			return false
+		}
+		_, ok := allowed[strings.ToLower(route.Operation.OperationID)]
+		return ok
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(HeaderKey)
+			userID, _ := r.Context().Value(middleware.ContextKeyUserID).(string)
+			if r.Method != http.MethodPost || key == "" || userID == "" || !covered(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxKeyLength {
+				apierror.Write(w, http.StatusBadRequest, fmt.Sprintf("%s must be at most %d characters", HeaderKey, maxKeyLength))
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				apierror.Write(w, http.StatusBadRequest, fmt.Sprintf("Failed to read request body: %v", err))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			requestHash := hashRequest(r, body)
+
+			existing, err := store.reserve(r.Context(), userID, key, requestHash)
+			if err != nil {
+				apierror.Write(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			if existing != nil {
+				replay(w, existing, requestHash)
+				return
+			}
+
+			rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+			finished := false
+			// Deferred so that a panicking handler frees the key too instead
+			// of leaving it "in progress" until it expires.
+			defer func() {
+				// The client may be gone by now; finish the bookkeeping regardless.
+				ctx := context.WithoutCancel(r.Context())
+				var err error
+				if finished && rec.status >= 200 && rec.status < 300 {
+					err = store.complete(ctx, userID, key, rec.status, rec.Header().Get("Content-Type"), rec.body.Bytes())
+				} else {
+					err = store.release(ctx, userID, key)
+				}
+				if err != nil {
+					// The response has already been sent; at worst a retry
+					// runs the request again.
+					middleware.Logf(ctx, "idempotency key %q for user %s: %v", key, userID, err)
+				}
+			}()
+			next.ServeHTTP(rec, r)
+			finished = true
+		})
+	}, nil
+}
+
+func replay(w http.ResponseWriter, record *Record, requestHash string) {
+	switch {
+	case record.RequestHash != requestHash:
+		apierror.Write(w, http.StatusUnprocessableEntity, fmt.Sprintf("%s was already used for a different request", HeaderKey))
+	case record.StatusCode == 0:
+		apierror.Write(w, http.StatusConflict, fmt.Sprintf("A request with this %s is still in progress", HeaderKey))
+	default:
+		if record.ContentType != "" {
+			w.Header().Set("Content-Type", record.ContentType)
+		}
+		w.Header().Set(HeaderReplayed, "true")
+		w.WriteHeader(record.StatusCode)
+		_, _ = w.Write(record.Body)
+	}
+}
+
+// hashRequest fingerprints the parts of a request that must match for a
+// replay to be valid.
+func hashRequest(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method)
+	h.Write([]byte{0})
+	io.WriteString(h, r.URL.Path)
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recorder passes the response through while keeping a copy of it.
+type recorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,220 @@
+package idempotency
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"messenger/backend/pkg/middleware"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+const (
+	testUserID  = "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa"
+	testOtherID = "bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("db.DB() error = %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.AutoMigrate(&Record{}); err != nil {
+		t.Fatalf("AutoMigrate() error = %v", err)
+	}
+	return NewStore(db, time.Hour)
+}
+
+// testSpec has one create operation and one POST that only reads.
+const testSpec = `
+openapi: 3.0.3
+info: {title: test, version: "1"}
+paths:
+  /todolists:
+    post:
+      operationId: createTodoList
+      responses: {"201": {description: created}}
+  /email/body:
+    post:
+      operationId: emailBody
+      responses: {"200": {description: ok}}
+`
+
+// newTestMiddleware covers createTodoList only.
+func newTestMiddleware(t *testing.T, store *Store) func(http.Handler) http.Handler {
+	t.Helper()
+	spec, err := openapi3.NewLoader().LoadFromData([]byte(testSpec))
+	if err != nil {
+		t.Fatalf("LoadFromData() error = %v", err)
+	}
+	mw, err := Middleware(store, spec, "CreateTodoList")
+	if err != nil {
+		t.Fatalf("Middleware() error = %v", err)
+	}
+	return mw
+}
+
+// countingHandler creates a new resource per call and fails when the body
+// asks it to.
+func countingHandler(calls *int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		body, _ := io.ReadAll(r.Body)
+		if string(body) == "fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"id":%d}`, *calls)
+	})
+}
+
+func post(handler http.Handler, userID, key, body string) *httptest.ResponseRecorder {
+	return postTo(handler, "/todolists", userID, key, body)
+}
+
+func postTo(handler http.Handler, path, userID, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set(HeaderKey, key)
+	req = req.WithContext(context.WithValue(req.Context(), middleware.ContextKeyUserID, userID))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMiddlewareReplaysFirstResponse(t *testing.T) {
+	calls := 0
+	handler := newTestMiddleware(t, newTestStore(t))(countingHandler(&calls))
+
+	first := post(handler, testUserID, "key-1", "groceries")
+	second := post(handler, testUserID, "key-1", "groceries")
+
+	if calls != 1 {
+		t.Fatalf("handler calls = %d, want 1", calls)
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Fatalf("replay = %d %q, want %d %q", second.Code, second.Body.String(), first.Code, first.Body.String())
+	}
+	if second.Header().Get(HeaderReplayed) != "true" {
+		t.Fatalf("%s header missing on replay", HeaderReplayed)
+	}
+
+	// Keys are scoped per user.
+	if other := post(handler, testOtherID, "key-1", "groceries"); other.Header().Get(HeaderReplayed) != "" {
+		t.Fatal("another user's request was served from the first user's key")
+	}
+	if calls != 2 {
+		t.Fatalf("handler calls = %d, want 2", calls)
+	}
+}
+
+func TestMiddlewareRejectsKeyReuseWithDifferentBody(t *testing.T) {
+	calls := 0
+	handler := newTestMiddleware(t, newTestStore(t))(countingHandler(&calls))
+
+	post(handler, testUserID, "key-1", "groceries")
+	rec := post(handler, testUserID, "key-1", "chores")
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+	if calls != 1 {
+		t.Fatalf("handler calls = %d, want 1", calls)
+	}
+}
+
+func TestMiddlewareFreesKeyAfterFailure(t *testing.T) {
+	calls := 0
+	handler := newTestMiddleware(t, newTestStore(t))(countingHandler(&calls))
+
+	if rec := post(handler, testUserID, "key-1", "fail"); rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if rec := post(handler, testUserID, "key-1", "fail"); rec.Header().Get(HeaderReplayed) != "" {
+		t.Fatal("failed response was replayed")
+	}
+	if calls != 2 {
+		t.Fatalf("handler calls = %d, want 2", calls)
+	}
+}
+
+func TestMiddlewareFreesKeyAfterPanic(t *testing.T) {
+	calls := 0
+	handler := newTestMiddleware(t, newTestStore(t))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			panic("boom")
+		}
+		countingHandler(&calls).ServeHTTP(w, r)
+	}))
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("panic was swallowed")
+			}
+		}()
+		post(handler, testUserID, "key-1", "groceries")
+	}()
+	if rec := post(handler, testUserID, "key-1", "groceries"); rec.Code != http.StatusCreated {
+		t.Fatalf("retry after panic: status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+}
+
+func TestMiddlewareIgnoresOtherOperations(t *testing.T) {
+	store := newTestStore(t)
+	calls := 0
+	handler := newTestMiddleware(t, store)(countingHandler(&calls))
+
+	postTo(handler, "/email/body", testUserID, "key-1", "message")
+	if rec := postTo(handler, "/email/body", testUserID, "key-1", "message"); rec.Header().Get(HeaderReplayed) != "" {
+		t.Fatal("a non-create response was replayed")
+	}
+	if calls != 2 {
+		t.Fatalf("handler calls = %d, want 2", calls)
+	}
+	var stored int64
+	if err := store.db.Model(&Record{}).Count(&stored).Error; err != nil || stored != 0 {
+		t.Fatalf("stored records = %d (%v), want none", stored, err)
+	}
+}
+
+func TestStoreExpiresKeys(t *testing.T) {
+	store := newTestStore(t)
+	calls := 0
+	handler := newTestMiddleware(t, store)(countingHandler(&calls))
+
+	post(handler, testUserID, "key-1", "groceries")
+	store.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+
+	if rec := post(handler, testUserID, "key-1", "groceries"); rec.Header().Get(HeaderReplayed) != "" {
+		t.Fatal("expired key was replayed")
+	}
+	if calls != 2 {
+		t.Fatalf("handler calls = %d, want 2", calls)
+	}
+
+	store.now = func() time.Time { return time.Now().Add(4 * time.Hour) }
+	purged, err := store.PurgeExpired(context.Background())
+	if err != nil || purged != 1 {
+		t.Fatalf("PurgeExpired() = %d, %v, want 1", purged, err)
+	}
+}
@@ -0,0 +1,53 @@
+package idempotency
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Sweeper periodically deletes expired idempotency records.
+type Sweeper struct {
+	Store    *Store
+	Interval time.Duration
+	Logger   *log.Logger
+}
+
+func (s *Sweeper) Start(ctx context.Context) {
+	if s == nil || s.Store == nil {
+		return
+	}
+	interval := s.Interval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	go func() {
+		s.runOnce(ctx)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.runOnce(ctx)
+			}
+		}
+	}()
+}
+
+func (s *Sweeper) runOnce(ctx context.Context) {
+	purged, err := s.Store.PurgeExpired(ctx)
+	if err != nil {
+		if s.Logger != nil {
+			s.Logger.Printf("idempotency key sweep failed: %v", err)
+		}
+		return
+	}
+	if purged > 0 && s.Logger != nil {
+		s.Logger.Printf("idempotency key sweep purged %d key(s)", purged)
+	}
+}
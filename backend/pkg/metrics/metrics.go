@@ -0,0 +1,179 @@
+// Package metrics exposes Prometheus metrics for HTTP requests, database
+// queries, authentication, IMAP connections and the email header cache on
+// /metrics.
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"gorm.io/gorm"
+)
+
+const namespace = "messie"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route pattern and status code.",
+	}, []string{"method", "route", "status"})
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method and route pattern.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+	authAttempts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "auth_attempts_total",
+		Help:      "Authentication attempts by scheme (jwt, feed_token, matrix_openid) and result.",
+	}, []string{"scheme", "result"})
+	imapConnections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "imap_connections_total",
+		Help:      "IMAP connect-and-login attempts by outcome.",
+	}, []string{"outcome"})
+	headerCacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "email_header_cache_lookups_total",
+		Help:      "Email header cache lookups by result (hit, miss).",
+	}, []string{"result"})
+	headerCacheInvalidations = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "email_header_cache_invalidations_total",
+		Help:      "Cached email headers dropped because their mailbox's UIDVALIDITY changed.",
+	})
+	dbDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Database statement latency by GORM operation.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation"})
+	dbErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_query_errors_total",
+		Help:      "Failed database statements by GORM operation; record-not-found is not counted.",
+	}, []string{"operation"})
+)
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// unmatchedRoute labels requests no route matched, so probing random paths
+// cannot grow the number of series.
+const unmatchedRoute = "unmatched"
+
+// Middleware records the count and latency of every request, labelled with
+// the chi route pattern (e.g. /api/v1/todolists/{listId}) rather than the raw
+// path to keep label cardinality bounded. It must be installed on the
+// top-level chi router.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		route := unmatchedRoute
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		method := methodLabel(r.Method)
+		httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+		httpDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	})
+}
+
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		return method
+	}
+	return "OTHER"
+}
+
+// ObserveAuth counts one authentication attempt with scheme.
+func ObserveAuth(scheme string, ok bool) {
+	result := "failure"
+	if ok {
+		result = "success"
+	}
+	authAttempts.WithLabelValues(scheme, result).Inc()
+}
+
+// ObserveIMAPConnection counts one IMAP connection attempt; outcome is "ok" or
+// a short failure reason such as "auth_failed".
+func ObserveIMAPConnection(outcome string) {
+	imapConnections.WithLabelValues(outcome).Inc()
+}
+
+// ObserveHeaderCacheLookups counts the cached and uncached headers of one
+// email header cache lookup.
+func ObserveHeaderCacheLookups(hits, misses int) {
+	headerCacheLookups.WithLabelValues("hit").Add(float64(hits))
+	headerCacheLookups.WithLabelValues("miss").Add(float64(misses))
+}
+
+// ObserveHeaderCacheInvalidation counts one cached header dropped after its
+// mailbox was renumbered.
+func ObserveHeaderCacheInvalidation() {
+	headerCacheInvalidations.Inc()
+}
+
+// InstrumentGORM times every statement db runs, by operation (create, query,
+// update, delete, row, raw).
+func InstrumentGORM(db *gorm.DB) error {
+	cb := db.Callback()
+	for _, p := range []struct {
+		operation     string
+		before, after func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", cb.Create().Before("*").Register, cb.Create().After("*").Register},
+		{"query", cb.Query().Before("*").Register, cb.Query().After("*").Register},
+		{"update", cb.Update().Before("*").Register, cb.Update().After("*").Register},
+		{"delete", cb.Delete().Before("*").Register, cb.Delete().After("*").Register},
+		{"row", cb.Row().Before("*").Register, cb.Row().After("*").Register},
+		{"raw", cb.Raw().Before("*").Register, cb.Raw().After("*").Register},
+	} {
+		if err := p.before("metrics:start_"+p.operation, startTimer); err != nil {
+			return err
+		}
+		if err := p.after("metrics:observe_"+p.operation, observeStatement(p.operation)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const startedAtKey = "metrics:started_at"
+
+func startTimer(db *gorm.DB) {
+	db.InstanceSet(startedAtKey, time.Now())
+}
+
+func observeStatement(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		if v, ok := db.InstanceGet(startedAtKey); ok {
+			if start, ok := v.(time.Time); ok {
+				dbDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+			}
+		}
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			dbErrors.WithLabelValues(operation).Inc()
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func scrape(t *testing.T) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	return rec.Body.String()
+}
+
+func TestMiddlewareLabelsByRoutePattern(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(Middleware)
+	api := chi.NewRouter()
+	api.Get("/lists/{listId}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	r.Mount("/api/v1", api)
+
+	for _, path := range []string{"/api/v1/lists/1", "/api/v1/lists/2", "/nope/123"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("BREW", "/api/v1/lists/3", nil))
+
+	body := scrape(t)
+	for _, want := range []string{
+		`messie_http_requests_total{method="GET",route="/api/v1/lists/{listId}",status="418"} 2`,
+		`messie_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`messie_http_requests_total{method="OTHER",`,
+		`messie_http_request_duration_seconds_count{method="GET",route="/api/v1/lists/{listId}"} 2`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics output is missing %s", want)
+		}
+	}
+	if strings.Contains(body, "/api/v1/lists/1") {
+		t.Error("raw paths must not become label values")
+	}
+}
+
+func TestObserveHeaderCache(t *testing.T) {
+	ObserveHeaderCacheLookups(3, 2)
+	ObserveHeaderCacheLookups(1, 0)
+	ObserveHeaderCacheInvalidation()
+
+	body := scrape(t)
+	for _, want := range []string{
+		`messie_email_header_cache_lookups_total{result="hit"} 4`,
+		`messie_email_header_cache_lookups_total{result="miss"} 2`,
+		`messie_email_header_cache_invalidations_total 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics output is missing %s", want)
+		}
+	}
+}
+
+func TestInstrumentGORM(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	if err := InstrumentGORM(db); err != nil {
+		t.Fatalf("InstrumentGORM() error = %v", err)
+	}
+	if err := db.Exec(`CREATE TABLE notes (id INTEGER PRIMARY KEY, body TEXT)`).Error; err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	var count int64
+	if err := db.Table("notes").Count(&count).Error; err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	_ = db.Table("missing").Count(&count).Error
+
+	body := scrape(t)
+	for _, want := range []string{
+		`messie_db_query_duration_seconds_count{operation="raw"} 1`,
+		`messie_db_query_duration_seconds_count{operation="query"} 2`,
+		`messie_db_query_errors_total{operation="query"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics output is missing %s", want)
+		}
+	}
+}
@@ -2,10 +2,13 @@ package middleware
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"messenger/backend/api/generated"
+	"messenger/backend/pkg/apierror"
+	"messenger/backend/pkg/auth"
+	"messenger/backend/pkg/metrics"
 	"net/http"
+	"strings"
 
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -14,6 +17,9 @@ type contextKey string
 
 const (
 	ContextKeyUserID contextKey = "userID"
+	// ContextKeyUserRole holds the role claim of the caller's JWT. Requests
+	// authenticated otherwise, such as by feed token, have none.
+	ContextKeyUserRole contextKey = "userRole"
 )
 
 // JWTService defines the interface for JWT token validation.
@@ -32,6 +38,15 @@ func AuthMiddleware(jwtService JWTService) func(next http.Handler) http.Handler
 
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" {
+				authHeader = webSocketBearer(r)
+			}
+			if authHeader == "" && acceptsFeedToken(r) {
+				// FeedTokenAuth authenticates the request instead.
+				next.ServeHTTP(w, r)
+				return
+			}
+			if authHeader == "" {
+				metrics.ObserveAuth("jwt", false)
 				writeJSONError(w, "Authorization header required", http.StatusUnauthorized)
 				return
 			}
@@ -40,12 +55,14 @@ func AuthMiddleware(jwtService JWTService) func(next http.Handler) http.Handler
 			if len(authHeader) > 7 && authHeader[:7] == "Bearer " {
 				tokenString = authHeader[7:]
 			} else {
+				metrics.ObserveAuth("jwt", false)
 				writeJSONError(w, "Invalid Authorization header format", http.StatusUnauthorized)
 				return
 			}
 
 			claims, err := jwtService.ValidateToken(tokenString)
 			if err != nil {
+				metrics.ObserveAuth("jwt", false)
 				writeJSONError(w, fmt.Sprintf("Invalid or expired token: %v", err), http.StatusUnauthorized)
 				return
 			}
@@ -53,23 +70,117 @@ func AuthMiddleware(jwtService JWTService) func(next http.Handler) http.Handler
 			// Add UserID to context
 			claimsMap, ok := claims.Claims.(jwt.MapClaims)
 			if !ok {
+				metrics.ObserveAuth("jwt", false)
 				writeJSONError(w, "Invalid token claims", http.StatusUnauthorized)
 				return
 			}
-			userID, ok := claimsMap["user_id"].(string)
+			metrics.ObserveAuth("jwt", true)
+			// ValidateToken guarantees a non-empty user_id claim.
+			userID, _ := claimsMap["user_id"].(string)
+			ctx := context.WithValue(r.Context(), ContextKeyUserID, userID)
+			ctx = context.WithValue(ctx, ContextKeyUserRole, auth.RoleFromClaims(claimsMap))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireRole lets through only requests whose JWT carries role, answering
+// 401 without an authenticated user and 403 for callers with another role.
+// Wrap admin-only routes with it after AuthMiddleware.
+func RequireRole(role string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if userID, _ := r.Context().Value(ContextKeyUserID).(string); userID == "" {
+				writeJSONError(w, "Authentication required", http.StatusUnauthorized)
+				return
+			}
+			if got, _ := r.Context().Value(ContextKeyUserRole).(string); got != role {
+				writeJSONError(w, "Forbidden: requires the "+role+" role", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireActiveUser rejects authenticated requests whose user no longer
+// exists. Tokens are stateless and stay valid until they expire, so this is
+// what revokes them once an account is deleted. It must run after
+// AuthMiddleware; requests without a user ID pass through.
+func RequireActiveUser(exists func(ctx context.Context, userID string) (bool, error)) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := r.Context().Value(ContextKeyUserID).(string)
 			if !ok {
-				writeJSONError(w, "User ID not found in token claims", http.StatusUnauthorized)
+				next.ServeHTTP(w, r)
 				return
 			}
+			found, err := exists(r.Context(), userID)
+			if err != nil {
+				writeJSONError(w, "Failed to verify account", http.StatusInternalServerError)
+				return
+			}
+			if !found {
+				writeJSONError(w, "Account no longer exists", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// FeedTokenAuth authenticates requests to operations that accept the feedToken
+// scheme and carry no bearer token by resolving their "token" query parameter
+// to a user ID. Calendar apps subscribing to a feed cannot send headers, so
+// the secret travels in the URL instead. It must run after AuthMiddleware.
+func FeedTokenAuth(resolve func(ctx context.Context, token string) (string, error)) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, authenticated := r.Context().Value(ContextKeyUserID).(string); authenticated || !acceptsFeedToken(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			userID, err := resolve(r.Context(), r.URL.Query().Get("token"))
+			if err != nil || userID == "" {
+				metrics.ObserveAuth("feed_token", false)
+				writeJSONError(w, "Invalid or revoked feed token", http.StatusUnauthorized)
+				return
+			}
+			metrics.ObserveAuth("feed_token", true)
 			ctx := context.WithValue(r.Context(), ContextKeyUserID, userID)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
+func acceptsFeedToken(r *http.Request) bool {
+	_, ok := r.Context().Value(generated.FeedTokenScopes).([]string)
+	return ok && r.URL.Query().Has("token")
+}
+
+// webSocketBearer returns an Authorization value built from a token offered as
+// the WebSocket subprotocol pair "bearer", "<token>". Browsers cannot attach
+// headers to a WebSocket handshake, and unlike a query parameter the
+// subprotocol header stays out of access logs.
+func webSocketBearer(r *http.Request) string {
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		return ""
+	}
+	var protocols []string
+	for _, value := range r.Header.Values("Sec-WebSocket-Protocol") {
+		for _, protocol := range strings.Split(value, ",") {
+			protocols = append(protocols, strings.TrimSpace(protocol))
+		}
+	}
+	for i := 0; i+1 < len(protocols); i++ {
+		if protocols[i] == "bearer" {
+			return "Bearer " + protocols[i+1]
+		}
+	}
+	return ""
+}
+
 // Helper function to write JSON errors
 func writeJSONError(w http.ResponseWriter, message string, statusCode int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(generated.Error{Message: message})
+	apierror.Write(w, statusCode, message)
 }
@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"messenger/backend/api/generated"
+	"messenger/backend/pkg/auth"
+)
+
+func TestRequireActiveUser(t *testing.T) {
+	exists := func(ctx context.Context, userID string) (bool, error) {
+		switch userID {
+		case "active":
+			return true, nil
+		case "broken":
+			return false, errors.New("db down")
+		}
+		return false, nil
+	}
+	handler := RequireActiveUser(exists)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name   string
+		userID string
+		want   int
+	}{
+		{name: "unauthenticated route", want: http.StatusOK},
+		{name: "active user", userID: "active", want: http.StatusOK},
+		{name: "deleted user", userID: "deleted", want: http.StatusUnauthorized},
+		{name: "lookup failure", userID: "broken", want: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/todolists", nil)
+			if tt.userID != "" {
+				req = req.WithContext(context.WithValue(req.Context(), ContextKeyUserID, tt.userID))
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	jwtService := auth.NewJWTService(auth.JWTOptions{Secret: "test-secret", TokenTTL: time.Hour})
+	handler := AuthMiddleware(jwtService)(RequireRole(auth.RoleAdmin)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})))
+
+	tests := []struct {
+		name string
+		role string
+		want int
+	}{
+		{name: "admin", role: auth.RoleAdmin, want: http.StatusOK},
+		{name: "regular user", role: auth.RoleUser, want: http.StatusForbidden},
+		{name: "no token", want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/admin", nil)
+			req = req.WithContext(context.WithValue(req.Context(), generated.BearerAuthScopes, []string{}))
+			if tt.role != "" {
+				token, err := jwtService.GenerateToken("user-1", tt.role)
+				if err != nil {
+					t.Fatalf("GenerateToken() error = %v", err)
+				}
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+
+	// Routes that skip JWT authentication never reach an admin handler.
+	rec := httptest.NewRecorder()
+	RequireRole(auth.RoleAdmin)(http.NotFoundHandler()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("unauthenticated status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestFeedTokenAuth(t *testing.T) {
+	resolve := func(ctx context.Context, token string) (string, error) {
+		if token == "secret" {
+			return "alice", nil
+		}
+		return "", errors.New("unknown token")
+	}
+	handler := FeedTokenAuth(resolve)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, _ := r.Context().Value(ContextKeyUserID).(string)
+		w.Write([]byte(userID))
+	}))
+
+	tests := []struct {
+		name       string
+		target     string
+		feed       bool
+		userID     string
+		wantStatus int
+		wantUser   string
+	}{
+		{name: "valid token", target: "/todo-items.ics?token=secret", feed: true, wantStatus: http.StatusOK, wantUser: "alice"},
+		{name: "unknown token", target: "/todo-items.ics?token=guess", feed: true, wantStatus: http.StatusUnauthorized},
+		{name: "bearer token wins", target: "/todo-items.ics?token=guess", feed: true, userID: "bob", wantStatus: http.StatusOK, wantUser: "bob"},
+		{name: "operation without feed tokens", target: "/todolists?token=secret", wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			ctx := req.Context()
+			if tt.feed {
+				ctx = context.WithValue(ctx, generated.FeedTokenScopes, []string{})
+			}
+			if tt.userID != "" {
+				ctx = context.WithValue(ctx, ContextKeyUserID, tt.userID)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req.WithContext(ctx))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK && rec.Body.String() != tt.wantUser {
+				t.Fatalf("user = %q, want %q", rec.Body.String(), tt.wantUser)
+			}
+		})
+	}
+}
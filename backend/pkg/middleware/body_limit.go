@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"messenger/backend/pkg/apierror"
+)
+
+// BodyLimit caps request bodies at limit bytes, or at overrides[path] for
+// requests to one of the paths listed there (such as file uploads). Bodies
+// that declare a larger Content-Length are refused with 413 up front; longer
+// bodies without one fail with *http.MaxBytesError once read past the limit,
+// which readers report with WriteBodyTooLarge.
+func BodyLimit(limit int64, overrides map[string]int64) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			max := limit
+			if override, ok := overrides[r.URL.Path]; ok {
+				max = override
+			}
+			if r.ContentLength > max {
+				WriteBodyTooLarge(w, &http.MaxBytesError{Limit: max})
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, max)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// BodyTooLarge returns the error BodyLimit's reader failed with if err wraps
+// it, or nil.
+func BodyTooLarge(err error) *http.MaxBytesError {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return tooLarge
+	}
+	return nil
+}
+
+// WriteBodyTooLarge answers 413 for a body that exceeded its limit.
+func WriteBodyTooLarge(w http.ResponseWriter, err *http.MaxBytesError) {
+	apierror.WriteCode(w, http.StatusRequestEntityTooLarge, apierror.CodePayloadTooLarge,
+		fmt.Sprintf("Request body exceeds %d bytes", err.Limit))
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"messenger/backend/api/generated"
+	"messenger/backend/pkg/apierror"
+)
+
+func TestBodyLimit(t *testing.T) {
+	read := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			if tooLarge := BodyTooLarge(err); tooLarge != nil {
+				WriteBodyTooLarge(w, tooLarge)
+				return
+			}
+			t.Errorf("ReadAll() error = %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	handler := BodyLimit(16, map[string]int64{"/upload": 64})(read)
+
+	tests := []struct {
+		name    string
+		path    string
+		body    string
+		chunked bool
+		want    int
+	}{
+		{name: "within limit", path: "/todolists", body: `{"title":"x"}`, want: http.StatusNoContent},
+		{name: "declared length over limit", path: "/todolists", body: strings.Repeat("x", 17), want: http.StatusRequestEntityTooLarge},
+		{name: "unannounced body over limit", path: "/todolists", body: strings.Repeat("x", 17), chunked: true, want: http.StatusRequestEntityTooLarge},
+		{name: "upload override", path: "/upload", body: strings.Repeat("x", 64), want: http.StatusNoContent},
+		{name: "upload over its limit", path: "/upload", body: strings.Repeat("x", 65), chunked: true, want: http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if tt.want == http.StatusRequestEntityTooLarge {
+				var body generated.Error
+				if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body.Code != apierror.CodePayloadTooLarge {
+					t.Fatalf("body = %s (%v), want a %s error", rec.Body.String(), err, apierror.CodePayloadTooLarge)
+				}
+			}
+		})
+	}
+}
+
+func TestRequestValidatorReportsOversizedBody(t *testing.T) {
+	handler := BodyLimit(8, nil)(newTestRequestValidator(t))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/todolists", strings.NewReader(`{"title":"far too long for the limit"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.ContentLength = -1
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusRequestEntityTooLarge, rec.Body.String())
+	}
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// ContextKeyClientIP holds the client address TrustedProxies resolved.
+const ContextKeyClientIP contextKey = "clientIP"
+
+// TrustedProxies resolves the address of the client behind the reverse
+// proxies in trusted, for ClientIP to return. Forwarding headers are only
+// believed when the request came from one of them, since anyone else can
+// send them: X-Forwarded-For is read from the right, skipping the trusted
+// hops, and X-Real-IP is the fallback. Requests from other peers keep their
+// own address.
+func TrustedProxies(trusted []netip.Prefix) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := resolveClientIP(r, trusted)
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), ContextKeyClientIP, ip)))
+		})
+	}
+}
+
+// ClientIP is the address of the request's client: the one TrustedProxies
+// resolved, or else the peer address without its port.
+func ClientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(ContextKeyClientIP).(string); ok && ip != "" {
+		return ip
+	}
+	return peerIP(r)
+}
+
+func peerIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func resolveClientIP(r *http.Request, trusted []netip.Prefix) string {
+	peer := peerIP(r)
+	if !isTrusted(peer, trusted) {
+		return peer
+	}
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if _, err := netip.ParseAddr(hop); err != nil {
+			break
+		}
+		if !isTrusted(hop, trusted) {
+			return hop
+		}
+	}
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); realIP != "" {
+		if _, err := netip.ParseAddr(realIP); err == nil {
+			return realIP
+		}
+	}
+	return peer
+}
+
+func isTrusted(ip string, trusted []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
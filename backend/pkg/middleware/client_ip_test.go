@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestTrustedProxiesResolvesClientIP(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		realIP     string
+		want       string
+	}{
+		{name: "direct", remoteAddr: "192.0.2.1:1000", want: "192.0.2.1"},
+		{name: "untrusted peer cannot spoof", remoteAddr: "192.0.2.1:1000", forwarded: "198.51.100.9", realIP: "198.51.100.9", want: "192.0.2.1"},
+		{name: "behind proxy", remoteAddr: "10.0.0.5:1000", forwarded: "198.51.100.9", want: "198.51.100.9"},
+		{name: "client-sent hops are skipped", remoteAddr: "10.0.0.5:1000", forwarded: "203.0.113.66, 198.51.100.9, 10.0.0.3", want: "198.51.100.9"},
+		{name: "real ip fallback", remoteAddr: "10.0.0.5:1000", realIP: "198.51.100.9", want: "198.51.100.9"},
+		{name: "garbage headers", remoteAddr: "10.0.0.5:1000", forwarded: "unknown", realIP: "nope", want: "10.0.0.5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if tt.realIP != "" {
+				req.Header.Set("X-Real-IP", tt.realIP)
+			}
+			var got string
+			TrustedProxies(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = ClientIP(r)
+			})).ServeHTTP(httptest.NewRecorder(), req)
+			if got != tt.want {
+				t.Fatalf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
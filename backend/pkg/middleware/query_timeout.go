@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"time"
+
+	"messenger/backend/pkg/apierror"
+	"messenger/backend/pkg/database"
+)
+
+// QueryTimeout bounds every database statement a request runs to timeout
+// (see database.WithQueryTimeout) so a runaway query fails instead of holding
+// a connection indefinitely. Handlers report such failures as they would any
+// other, typically with a 500; when a statement of the request did time out,
+// that response is replaced by a 503 asking the client to retry. A
+// non-positive timeout turns the middleware off.
+func QueryTimeout(timeout time.Duration) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if timeout <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r = r.WithContext(database.WithQueryTimeout(r.Context(), timeout))
+			next.ServeHTTP(&queryTimeoutWriter{ResponseWriter: w, r: r}, r)
+		})
+	}
+}
+
+// queryTimeoutWriter swaps a server error for a 503 when the request's
+// database statement timed out, dropping the body the handler writes after
+// it.
+type queryTimeoutWriter struct {
+	http.ResponseWriter
+	r        *http.Request
+	replaced bool
+}
+
+func (w *queryTimeoutWriter) WriteHeader(status int) {
+	if status >= http.StatusInternalServerError && database.QueryTimedOut(w.r.Context()) {
+		w.replaced = true
+		apierror.Write(w.ResponseWriter, http.StatusServiceUnavailable, "The database took too long to answer; please retry")
+		return
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *queryTimeoutWriter) Write(p []byte) (int, error) {
+	if w.replaced {
+		return len(p), nil
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, e.g. to
+// flush streamed responses.
+func (w *queryTimeoutWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Hijack is needed by WebSocket upgrades, which type-assert http.Hijacker.
+func (w *queryTimeoutWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
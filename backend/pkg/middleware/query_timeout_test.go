@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"messenger/backend/api/generated"
+	"messenger/backend/pkg/apierror"
+	"messenger/backend/pkg/database"
+)
+
+func TestQueryTimeoutAnswers503ForSlowQuery(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	if err := database.LimitQueryTime(db); err != nil {
+		t.Fatalf("LimitQueryTime() error = %v", err)
+	}
+	// Handlers answer database errors with a plain 500, as the real ones do.
+	query := func(sql string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var n int64
+			if err := db.WithContext(r.Context()).Raw(sql).Find(&n).Error; err != nil {
+				apierror.Write(w, http.StatusInternalServerError, "Failed to count: "+err.Error())
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+
+	tests := []struct {
+		name string
+		sql  string
+		want int
+	}{
+		{name: "fast", sql: "SELECT 1", want: http.StatusNoContent},
+		{name: "slow", sql: `WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c WHERE x < 100000000) SELECT count(*) FROM c`, want: http.StatusServiceUnavailable},
+		{name: "other failure", sql: "SELECT count(*) FROM missing", want: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			QueryTimeout(50*time.Millisecond)(query(tt.sql)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/todolists", nil))
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d; body %s", rec.Code, tt.want, rec.Body)
+			}
+			if tt.want != http.StatusServiceUnavailable {
+				return
+			}
+			var body generated.Error
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("body %q is not one error envelope: %v", rec.Body, err)
+			}
+			if body.Code != apierror.CodeUnavailable {
+				t.Fatalf("code = %q, want %q", body.Code, apierror.CodeUnavailable)
+			}
+		})
+	}
+}
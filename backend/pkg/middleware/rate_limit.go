@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"messenger/backend/pkg/apierror"
+)
+
+// rateLimitSweepInterval is how often buckets that have refilled are dropped.
+const rateLimitSweepInterval = time.Minute
+
+// RateLimit throttles requests with a token bucket per caller: rate requests
+// a second on average with bursts of up to burst. Authenticated requests are
+// keyed by the user ID AuthMiddleware put in the context, so it must run
+// after authentication; the rest are keyed by the client IP (see
+// TrustedProxies). Requests over
+// the limit get 429 with a Retry-After header. A rate of zero disables
+// limiting.
+func RateLimit(rate float64, burst int) func(next http.Handler) http.Handler {
+	return newRateLimiter(rate, burst, time.Now).middleware
+}
+
+type rateBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type rateLimiter struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*rateBucket
+	lastSweep time.Time
+}
+
+func newRateLimiter(rate float64, burst int, now func() time.Time) *rateLimiter {
+	return &rateLimiter{
+		rate:      rate,
+		burst:     float64(burst),
+		now:       now,
+		buckets:   make(map[string]*rateBucket),
+		lastSweep: now(),
+	}
+}
+
+func (l *rateLimiter) middleware(next http.Handler) http.Handler {
+	if l.rate <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ok, wait := l.allow(rateLimitKey(r)); !ok {
+			seconds := int(math.Ceil(wait.Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(max(seconds, 1)))
+			apierror.WriteCode(w, http.StatusTooManyRequests, apierror.CodeTooManyRequests, "Too many requests, slow down")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// rateLimitKey names the bucket of the request's caller.
+func rateLimitKey(r *http.Request) string {
+	if userID, ok := r.Context().Value(ContextKeyUserID).(string); ok && userID != "" {
+		return "user:" + userID
+	}
+	return "ip:" + ClientIP(r)
+}
+
+// allow takes a token from key's bucket. When it is empty, allow reports how
+// long until the next token arrives instead.
+func (l *rateLimiter) allow(key string) (bool, time.Duration) {
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) >= rateLimitSweepInterval {
+		l.sweep(now)
+	}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &rateBucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// sweep forgets buckets that would be full by now, which a new bucket is
+// anyway, so idle callers do not pile up in memory.
+func (l *rateLimiter) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := newRateLimiter(1, 2, func() time.Time { return now })
+	handler := limiter.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	request := func(userID, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/todolists", nil)
+		req.RemoteAddr = remoteAddr
+		if userID != "" {
+			req = req.WithContext(context.WithValue(req.Context(), ContextKeyUserID, userID))
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i := 0; i < 2; i++ {
+		if rec := request("alice", "192.0.2.1:1000"); rec.Code != http.StatusNoContent {
+			t.Fatalf("request %d within burst: status = %d, want %d", i+1, rec.Code, http.StatusNoContent)
+		}
+	}
+	rec := request("alice", "192.0.2.2:1000")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("request over burst: status = %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "1" {
+		t.Fatalf("Retry-After = %q, want 1", got)
+	}
+
+	// Other users and anonymous clients have buckets of their own, even
+	// from the same address.
+	if rec := request("bob", "192.0.2.1:1000"); rec.Code != http.StatusNoContent {
+		t.Fatalf("another user: status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	for i := 0; i < 2; i++ {
+		request("", "192.0.2.1:2000")
+	}
+	if rec := request("", "192.0.2.1:3000"); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("anonymous over burst from one IP: status = %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+	if rec := request("", "192.0.2.9:2000"); rec.Code != http.StatusNoContent {
+		t.Fatalf("anonymous from another IP: status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+
+	now = now.Add(time.Second)
+	if rec := request("alice", "192.0.2.1:1000"); rec.Code != http.StatusNoContent {
+		t.Fatalf("after refill: status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	if rec := request("alice", "192.0.2.1:1000"); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("refill is one token a second: status = %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+
+	now = now.Add(rateLimitSweepInterval)
+	request("alice", "192.0.2.1:1000")
+	if len(limiter.buckets) != 1 {
+		t.Fatalf("buckets after sweep = %d, want only the one just used", len(limiter.buckets))
+	}
+}
+
+func TestRateLimitKeysProxiedRequestsByClient(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := newRateLimiter(1, 1, func() time.Time { return now })
+	handler := TrustedProxies([]netip.Prefix{netip.MustParsePrefix("172.18.0.2/32")})(limiter.middleware(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })))
+
+	request := func(client string) int {
+		req := httptest.NewRequest(http.MethodPost, "/auth/matrix/openid", nil)
+		req.RemoteAddr = "172.18.0.2:40000"
+		req.Header.Set("X-Real-IP", client)
+		req.Header.Set("X-Forwarded-For", client)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := request("198.51.100.1"); code != http.StatusNoContent {
+		t.Fatalf("first client: status = %d, want %d", code, http.StatusNoContent)
+	}
+	if code := request("198.51.100.1"); code != http.StatusTooManyRequests {
+		t.Fatalf("first client again: status = %d, want %d", code, http.StatusTooManyRequests)
+	}
+	if code := request("198.51.100.2"); code != http.StatusNoContent {
+		t.Fatalf("second client through the same proxy: status = %d, want %d", code, http.StatusNoContent)
+	}
+}
+
+func TestRateLimitZeroDisables(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := RateLimit(0, 1)(next)
+	for i := 0; i < 5; i++ {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/todolists", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d, want %d", i+1, rec.Code, http.StatusOK)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+// RequestIDHeader is the response header carrying the ID that chi's
+// RequestID middleware assigned to the request.
+var RequestIDHeader = chimiddleware.RequestIDHeader
+
+// EchoRequestID copies the request ID into the response headers so clients
+// can quote it in bug reports; apierror also reads it from there to put it in
+// the error envelope. It must run after chi's RequestID middleware.
+func EchoRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := chimiddleware.GetReqID(r.Context()); id != "" {
+			w.Header().Set(RequestIDHeader, id)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Logf logs like log.Printf, prefixed with the request ID from ctx (the same
+// "[id]" form chi's request logger prints) so handler logs can be matched to
+// the access log line and to the ID a client reports.
+func Logf(ctx context.Context, format string, args ...interface{}) {
+	if id := chimiddleware.GetReqID(ctx); id != "" {
+		format = "[" + id + "] " + format
+	}
+	log.Printf(format, args...)
+}
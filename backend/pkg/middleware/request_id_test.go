@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+func TestEchoRequestIDAndLogf(t *testing.T) {
+	var buf bytes.Buffer
+	flags := log.Flags()
+	log.SetOutput(&buf)
+	log.SetFlags(0)
+	t.Cleanup(func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(flags)
+	})
+
+	var seen string
+	handler := chimiddleware.RequestID(EchoRequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = chimiddleware.GetReqID(r.Context())
+		Logf(r.Context(), "failed: %v", "boom")
+	})))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/todolists", nil)
+	req.Header.Set(RequestIDHeader, "client-supplied")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if seen != "client-supplied" || rec.Header().Get(RequestIDHeader) != "client-supplied" {
+		t.Fatalf("request ID = %q, header = %q; want client-supplied", seen, rec.Header().Get(RequestIDHeader))
+	}
+	if got := strings.TrimSpace(buf.String()); got != "[client-supplied] failed: boom" {
+		t.Fatalf("log line = %q", got)
+	}
+}
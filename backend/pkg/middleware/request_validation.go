@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"messenger/backend/api/generated"
+	"messenger/backend/pkg/apierror"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/legacy"
+)
+
+// RequestValidator checks request parameters and bodies against the OpenAPI
+// spec before the handler runs and answers 400 with per-field details when
+// they don't match. Operations whose operationId is listed in
+// skipOperationIDs are passed through untouched, as are requests that don't
+// map to an operation in the spec; routing those is left to the router.
+// operationIds are compared case-insensitively because the spec embedded by
+// oapi-codegen carries them in Go casing.
+//
+// Bodies cut off by BodyLimit are answered with 413 instead.
+//
+// Security requirements are not checked here; AuthMiddleware owns that.
+func RequestValidator(spec *openapi3.T, skipOperationIDs ...string) (func(next http.Handler) http.Handler, error) {
+	router, err := legacy.NewRouter(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build OpenAPI router: %w", err)
+	}
+
+	skip := make(map[string]struct{}, len(skipOperationIDs))
+	for _, id := range skipOperationIDs {
+		skip[strings.ToLower(id)] = struct{}{}
+	}
+
+	// kin-openapi only checks formats it has validators for; uuid is not
+	// among the defaults.
+	openapi3.DefineStringFormat("uuid", `^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+	options := &openapi3filter.Options{
+		MultiError:          true,
+		SkipSettingDefaults: true,
+		AuthenticationFunc:  openapi3filter.NoopAuthenticationFunc,
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route, pathParams, err := router.FindRoute(r)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if _, ok := skip[strings.ToLower(route.Operation.OperationID)]; ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			err = openapi3filter.ValidateRequest(r.Context(), &openapi3filter.RequestValidationInput{
+				Request:    r,
+				PathParams: pathParams,
+				Route:      route,
+				Options:    options,
+			})
+			if tooLarge := BodyTooLarge(err); tooLarge != nil {
+				WriteBodyTooLarge(w, tooLarge)
+				return
+			}
+			if err != nil {
+				apierror.WriteCode(w, http.StatusBadRequest, apierror.CodeValidation, "Request validation failed", fieldErrors(err, "")...)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+// fieldErrors flattens the errors returned by openapi3filter into one entry
+// per offending field. field is the location inherited from an enclosing
+// error, if any.
+func fieldErrors(err error, field string) []generated.FieldError {
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		var out []generated.FieldError
+		for _, inner := range e {
+			out = append(out, fieldErrors(inner, field)...)
+		}
+		return out
+	case *openapi3filter.RequestError:
+		if e.Parameter != nil {
+			// Parameter schema errors point inside the parameter value,
+			// which is rarely useful; report them against the parameter.
+			return []generated.FieldError{{Field: e.Parameter.Name, Message: errorReason(e)}}
+		}
+		if e.Err == nil {
+			return []generated.FieldError{{Field: field, Message: e.Reason}}
+		}
+		return fieldErrors(e.Err, field)
+	case *openapi3.SchemaError:
+		if pointer := e.JSONPointer(); len(pointer) > 0 {
+			field = "/" + strings.Join(pointer, "/")
+		}
+		return []generated.FieldError{{Field: field, Message: e.Reason}}
+	default:
+		return []generated.FieldError{{Field: field, Message: err.Error()}}
+	}
+}
+
+// errorReason returns the innermost human-readable reason of a request error.
+func errorReason(err *openapi3filter.RequestError) string {
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err.Err, &schemaErr) {
+		return schemaErr.Reason
+	}
+	if err.Err != nil {
+		return err.Err.Error()
+	}
+	return err.Reason
+}
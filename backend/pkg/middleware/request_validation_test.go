@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"messenger/backend/api/generated"
+	"messenger/backend/pkg/apierror"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func newTestRequestValidator(t *testing.T, skipOperationIDs ...string) http.Handler {
+	t.Helper()
+
+	spec, err := generated.GetSwagger()
+	if err != nil {
+		t.Fatalf("GetSwagger() error = %v", err)
+	}
+	spec.Servers = openapi3.Servers{{URL: "/api/v1"}}
+	validator, err := RequestValidator(spec, skipOperationIDs...)
+	if err != nil {
+		t.Fatalf("RequestValidator() error = %v", err)
+	}
+	return validator(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+}
+
+func TestRequestValidatorRejectsMissingRequiredField(t *testing.T) {
+	handler := newTestRequestValidator(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/todolists", strings.NewReader(`{"description":"weekly"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	var body generated.Error
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if body.Code != apierror.CodeValidation {
+		t.Fatalf("Code = %q, want %q", body.Code, apierror.CodeValidation)
+	}
+	if body.Details == nil || len(*body.Details) == 0 {
+		t.Fatal("Details is empty, want field-level details")
+	}
+	found := false
+	for _, fieldErr := range *body.Details {
+		if strings.Contains(fieldErr.Message, "title") {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("Details = %+v, want an entry mentioning title", *body.Details)
+	}
+}
+
+func TestRequestValidatorReportsInvalidPathParameter(t *testing.T) {
+	handler := newTestRequestValidator(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/todolists/not-a-uuid", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	var body generated.Error
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if body.Details == nil || len(*body.Details) != 1 || (*body.Details)[0].Field != "listId" {
+		t.Fatalf("Details = %+v, want one error for listId", body.Details)
+	}
+}
+
+func TestRequestValidatorPassesValidAndSkippedRequests(t *testing.T) {
+	handler := newTestRequestValidator(t, "createTodoList")
+
+	for name, req := range map[string]*http.Request{
+		"valid":   httptest.NewRequest(http.MethodPost, "/api/v1/todolists/11111111-1111-1111-1111-111111111111/items/batch", strings.NewReader(`[{"list_id":"11111111-1111-1111-1111-111111111111","title":"Milk","description":"","completed":false,"position":""}]`)),
+		"skipped": httptest.NewRequest(http.MethodPost, "/api/v1/todolists", strings.NewReader(`{}`)),
+		"unknown": httptest.NewRequest(http.MethodGet, "/api/v1/not-in-spec", nil),
+		"static":  httptest.NewRequest(http.MethodGet, "/api/v1/todolists/shared", nil),
+	} {
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("%s: status = %d, want %d (body %s)", name, rec.Code, http.StatusNoContent, rec.Body.String())
+		}
+	}
+}
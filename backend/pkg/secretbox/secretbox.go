@@ -0,0 +1,74 @@
+// Package secretbox encrypts small secrets, such as third-party access
+// tokens, before they are stored in the database.
+package secretbox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// KeySize is the length in bytes of the AES-256 key a Box needs.
+const KeySize = 32
+
+// ErrMalformed is returned by Open for values that were not produced by Seal
+// with the same key.
+var ErrMalformed = errors.New("secretbox: malformed or tampered value")
+
+// Box seals values with AES-256-GCM under a fixed key.
+type Box struct {
+	aead cipher.AEAD
+}
+
+// New returns a Box for a KeySize-byte key.
+func New(key []byte) (*Box, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("secretbox: key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Box{aead: aead}, nil
+}
+
+// NewFromBase64 decodes a standard base64 key, the form keys take in the
+// environment, and returns a Box for it.
+func NewFromBase64(encoded string) (*Box, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("secretbox: key is not base64: %w", err)
+	}
+	return New(key)
+}
+
+// Seal encrypts plaintext under a random nonce and returns it base64 encoded.
+func (b *Box) Seal(plaintext string) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := b.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open reverses Seal.
+func (b *Box) Open(sealed string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(raw) < b.aead.NonceSize() {
+		return "", ErrMalformed
+	}
+	nonce, ciphertext := raw[:b.aead.NonceSize()], raw[b.aead.NonceSize():]
+	plaintext, err := b.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", ErrMalformed
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,50 @@
+package secretbox
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func TestSealOpen(t *testing.T) {
+	box, err := New(bytes.Repeat([]byte{1}, KeySize))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	sealed, err := box.Seal("syt_secret_token")
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	if bytes.Contains([]byte(sealed), []byte("syt_secret_token")) {
+		t.Fatal("sealed value contains the plaintext")
+	}
+	again, _ := box.Seal("syt_secret_token")
+	if again == sealed {
+		t.Fatal("sealing twice produced the same value; nonces must be random")
+	}
+	if got, err := box.Open(sealed); err != nil || got != "syt_secret_token" {
+		t.Fatalf("Open() = %q, %v", got, err)
+	}
+
+	other, _ := New(bytes.Repeat([]byte{2}, KeySize))
+	if _, err := other.Open(sealed); !errors.Is(err, ErrMalformed) {
+		t.Fatalf("Open() with another key error = %v, want ErrMalformed", err)
+	}
+	if _, err := box.Open("not base64!"); !errors.Is(err, ErrMalformed) {
+		t.Fatalf("Open(garbage) error = %v, want ErrMalformed", err)
+	}
+}
+
+func TestNewRejectsBadKeys(t *testing.T) {
+	if _, err := New([]byte("short")); err == nil {
+		t.Fatal("New(short key) succeeded")
+	}
+	if _, err := NewFromBase64("%%%"); err == nil {
+		t.Fatal("NewFromBase64(invalid) succeeded")
+	}
+	if _, err := NewFromBase64(base64.StdEncoding.EncodeToString(make([]byte, KeySize))); err != nil {
+		t.Fatalf("NewFromBase64(valid) error = %v", err)
+	}
+}